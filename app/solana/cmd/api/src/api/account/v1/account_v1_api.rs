@@ -12,11 +12,31 @@ pub struct AccountV1API {
 impl AccountV1API {
     /// Creates a new `AccountV1API` instance with the provided service providers
     pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
-        // Extract the specific dependency (RPC client) from service providers
+        // Extract the dependencies this service needs from service providers
         let rpc_client = service_providers.solana_clients.get_rpc_client();
+        let key_vault = Arc::clone(&service_providers.key_vault);
+        let treasury_key_ref = service_providers.treasury_key_ref().to_string();
+        let rpc_limiter = Arc::clone(&service_providers.rpc_limiter);
+        let rpc_router = service_providers.solana_clients.get_rpc_router();
+        let keystore = Arc::clone(&service_providers.keystore);
+        let websocket_manager = Arc::clone(&service_providers.websocket_manager);
+        let reject_string_amounts = service_providers.funding_rejects_string_amounts();
+        let snapshot_import_directory = service_providers
+            .account_snapshot_import_directory()
+            .to_string();
 
         Self {
-            account_service: Arc::new(AccountServiceImpl::new(rpc_client)),
+            account_service: Arc::new(AccountServiceImpl::new(
+                rpc_client,
+                key_vault,
+                treasury_key_ref,
+                rpc_limiter,
+                rpc_router,
+                keystore,
+                websocket_manager,
+                reject_string_amounts,
+                snapshot_import_directory,
+            )),
         }
     }
 }
@@ -0,0 +1,363 @@
+use solana_client::rpc_client::{GetConfirmedSignaturesForAddress2Config, RpcClient};
+use solana_client::rpc_config::RpcTransactionConfig;
+use solana_sdk::{
+    commitment_config::CommitmentConfig,
+    instruction::CompiledInstruction,
+    message::Message,
+    pubkey::Pubkey,
+    signature::{Signature, Signer},
+    transaction::Transaction as SolanaTransaction,
+};
+use solana_transaction_status::{UiLoadedAddresses, UiTransactionEncoding};
+use spl_token_2022::{
+    extension::{BaseStateWithExtensions, ExtensionType, StateWithExtensions},
+    instruction::TokenInstruction,
+    state::Mint,
+};
+use std::str::FromStr;
+use tracing::{info, warn};
+
+use crate::api::account::v1::derivation::derive_associated_token_address;
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+use crate::api::program::ata::v1::instructions::create as create_associated_account;
+use crate::api::program::token::v1::space::holding_account_len;
+use crate::api::transaction::v1::service_impl::resolved_account_keys;
+use crate::service_providers::ata_watcher::{AtaWatcher, WatchedAccount};
+use crate::service_providers::key_vault::KeyVault;
+use crate::service_providers::sponsorship::{SponsorPool, SponsorshipGrant};
+use crate::service_providers::unix_timestamp;
+
+/// How many of a missing account's most recent signatures are checked for transfers
+const SIGNATURE_LOOKBACK: usize = 10;
+/// `TransferFeeInstruction::TransferCheckedWithFee` tag within the transfer fee extension
+const TRANSFER_CHECKED_WITH_FEE: u8 = 1;
+
+/// Checks every due watched account once, creating missing ones that inbound transfers
+/// target when the tenant's policy allows. Returns how many accounts were created.
+///
+/// A transfer to an associated token account that does not exist fails, but a sender that
+/// skips preflight still lands the failed transaction against the account's address. Only
+/// such failed transfers into the address, newer than anything that succeeded there, count
+/// as pending: signatures left by an earlier account at the address that was closed, or by
+/// other programs, do not.
+pub fn sweep(
+    rpc_client: &RpcClient,
+    key_vault: &KeyVault,
+    sponsorship: &SponsorPool,
+    watcher: &AtaWatcher,
+) -> usize {
+    let mut created = 0;
+    for watch in watcher.due() {
+        let layout = match account_layout(rpc_client, &watch.mint) {
+            Ok(layout) => layout,
+            Err(e) => {
+                warn!(mint = %watch.mint, error = %e, "ATA watch mint check failed");
+                watcher.defer(&watch);
+                continue;
+            }
+        };
+        let (address, _) =
+            derive_associated_token_address(&watch.owner, &watch.mint, &layout.token_program);
+        match inbound_transfer_pending(rpc_client, &layout.token_program, &address) {
+            Ok(None) => {
+                watcher.mark_exists(&watch);
+                continue;
+            }
+            Ok(Some(false)) => continue,
+            Ok(Some(true)) => {}
+            Err(e) => {
+                warn!(address = %address, error = %e, "ATA watch check failed");
+                continue;
+            }
+        }
+
+        if !watch.auto_create {
+            info!(
+                tenant = %watch.tenant,
+                owner = %watch.owner,
+                mint = %watch.mint,
+                "Inbound transfer to a missing associated token account; tenant policy does not create it"
+            );
+            watcher.defer(&watch);
+            continue;
+        }
+
+        match create_account(rpc_client, key_vault, sponsorship, &watch, &layout) {
+            Ok(signature) => {
+                info!(
+                    tenant = %watch.tenant,
+                    owner = %watch.owner,
+                    mint = %watch.mint,
+                    address = %address,
+                    signature = %signature,
+                    "🪣 Created missing associated token account for inbound transfer"
+                );
+                watcher.mark_exists(&watch);
+                created += 1;
+            }
+            Err(e) => {
+                warn!(
+                    tenant = %watch.tenant,
+                    address = %address,
+                    error = %e,
+                    "Failed to create missing associated token account"
+                );
+                watcher.defer(&watch);
+            }
+        }
+    }
+    created
+}
+
+/// Token program owning a watched mint and the extensions its associated token accounts
+/// are created with
+#[derive(Debug, PartialEq, Eq)]
+struct AccountLayout {
+    token_program: Pubkey,
+    extensions: Vec<ExtensionType>,
+}
+
+/// Reads a watched mint to find the program and size of its associated token accounts
+fn account_layout(rpc_client: &RpcClient, mint: &Pubkey) -> Result<AccountLayout, String> {
+    let account = rpc_client
+        .get_account_with_commitment(mint, CommitmentConfig::confirmed())
+        .map_err(|e| format!("Failed to read mint: {e}"))?
+        .value
+        .ok_or_else(|| format!("Mint {mint} not found"))?;
+    layout_of_mint(&account.owner, &account.data)
+}
+
+/// Associated token accounts of SPL Token mints have no extensions. Token-2022 ones always
+/// carry `ImmutableOwner` plus whatever the mint's extensions require of its accounts.
+fn layout_of_mint(owner: &Pubkey, data: &[u8]) -> Result<AccountLayout, String> {
+    if *owner == TOKEN_PROGRAM_ID {
+        return Ok(AccountLayout {
+            token_program: TOKEN_PROGRAM_ID,
+            extensions: Vec::new(),
+        });
+    }
+    if *owner != spl_token_2022::id() {
+        return Err(format!("Mint is owned by {owner}, not a token program"));
+    }
+
+    let mint = StateWithExtensions::<Mint>::unpack(data)
+        .map_err(|e| format!("Failed to parse mint: {e}"))?;
+    let mint_extensions = mint
+        .get_extension_types()
+        .map_err(|e| format!("Failed to read mint extensions: {e}"))?;
+    let mut extensions = ExtensionType::get_required_init_account_extensions(&mint_extensions);
+    if !extensions.contains(&ExtensionType::ImmutableOwner) {
+        extensions.push(ExtensionType::ImmutableOwner);
+    }
+    Ok(AccountLayout {
+        token_program: spl_token_2022::id(),
+        extensions,
+    })
+}
+
+/// Whether a missing account has a failed inbound transfer waiting on it: `None` if the
+/// account exists
+fn inbound_transfer_pending(
+    rpc_client: &RpcClient,
+    token_program: &Pubkey,
+    address: &Pubkey,
+) -> Result<Option<bool>, String> {
+    let commitment = CommitmentConfig::confirmed();
+    let exists = rpc_client
+        .get_account_with_commitment(address, commitment)
+        .map_err(|e| format!("Failed to read account: {e}"))?
+        .value
+        .is_some();
+    if exists {
+        return Ok(None);
+    }
+
+    let signatures = rpc_client
+        .get_signatures_for_address_with_config(
+            address,
+            GetConfirmedSignaturesForAddress2Config {
+                limit: Some(SIGNATURE_LOOKBACK),
+                commitment: Some(commitment),
+                ..GetConfirmedSignaturesForAddress2Config::default()
+            },
+        )
+        .map_err(|e| format!("Failed to read signatures: {e}"))?;
+
+    // Newest first: anything that succeeded at the address, such as closing an earlier
+    // account there, ends the attempts that are still waiting on it
+    for status in signatures.iter().take_while(|status| status.err.is_some()) {
+        let signature = Signature::from_str(&status.signature)
+            .map_err(|e| format!("Invalid signature {}: {e}", status.signature))?;
+        let confirmed = rpc_client
+            .get_transaction_with_config(
+                &signature,
+                RpcTransactionConfig {
+                    encoding: Some(UiTransactionEncoding::Base64),
+                    commitment: Some(commitment),
+                    max_supported_transaction_version: Some(0),
+                },
+            )
+            .map_err(|e| format!("Failed to read transaction {signature}: {e}"))?;
+        let Some(transaction) = confirmed.transaction.transaction.decode() else {
+            continue;
+        };
+        let loaded_addresses = confirmed
+            .transaction
+            .meta
+            .and_then(|meta| Option::<UiLoadedAddresses>::from(meta.loaded_addresses));
+        let account_keys = resolved_account_keys(&transaction, loaded_addresses.as_ref());
+        if transfers_into(&account_keys, transaction.message.instructions(), token_program, address)
+        {
+            return Ok(Some(true));
+        }
+    }
+    Ok(Some(false))
+}
+
+/// Whether any top-level instruction is a token transfer into `address`
+fn transfers_into(
+    account_keys: &[Pubkey],
+    instructions: &[CompiledInstruction],
+    token_program: &Pubkey,
+    address: &Pubkey,
+) -> bool {
+    instructions.iter().any(|instruction| {
+        if account_keys.get(usize::from(instruction.program_id_index)) != Some(token_program) {
+            return false;
+        }
+        let destination_position = match TokenInstruction::unpack(&instruction.data) {
+            #[allow(deprecated)]
+            Ok(TokenInstruction::Transfer { .. }) => 1,
+            Ok(TokenInstruction::TransferChecked { .. }) => 2,
+            Ok(TokenInstruction::TransferFeeExtension)
+                if instruction.data.get(1) == Some(&TRANSFER_CHECKED_WITH_FEE) =>
+            {
+                2
+            }
+            _ => return false,
+        };
+        instruction
+            .accounts
+            .get(destination_position)
+            .and_then(|index| account_keys.get(usize::from(*index)))
+            == Some(address)
+    })
+}
+
+/// Creates a watched account with a sponsored fee payer, charging the fee and the
+/// account's rent to the tenant's sponsorship budget once it lands. The grant is released
+/// uncharged if the transaction is not confirmed.
+fn create_account(
+    rpc_client: &RpcClient,
+    key_vault: &KeyVault,
+    sponsorship: &SponsorPool,
+    watch: &WatchedAccount,
+    layout: &AccountLayout,
+) -> Result<Signature, String> {
+    let fee_payer =
+        sponsorship.select_fee_payer(key_vault, |key| rpc_client.get_balance(key).ok())?;
+    let recent_blockhash = rpc_client
+        .get_latest_blockhash()
+        .map_err(|e| format!("Failed to get latest blockhash: {e}"))?;
+    let message = Message::new_with_blockhash(
+        &[create_associated_account(
+            &fee_payer.pubkey(),
+            &watch.owner,
+            &watch.mint,
+            &layout.token_program,
+            true,
+        )],
+        Some(&fee_payer.pubkey()),
+        &recent_blockhash,
+    );
+
+    let fee = rpc_client
+        .get_fee_for_message(&message)
+        .map_err(|e| format!("Failed to quote fee: {e}"))?;
+    let space = holding_account_len(&layout.extensions)?;
+    let rent = rpc_client
+        .get_minimum_balance_for_rent_exemption(space)
+        .map_err(|e| format!("Failed to get rent exemption: {e}"))?;
+    let message_hash = message.hash().to_string();
+    sponsorship.grant(
+        &watch.tenant,
+        &message_hash,
+        SponsorshipGrant {
+            fee_payer: fee_payer.pubkey(),
+            fee: fee.saturating_add(rent),
+            granted_at: unix_timestamp(),
+        },
+    )?;
+
+    let transaction = SolanaTransaction::new(&[fee_payer.as_ref()], message, recent_blockhash);
+    match rpc_client.send_and_confirm_transaction(&transaction) {
+        Ok(signature) => {
+            sponsorship.redeem(&message_hash);
+            Ok(signature)
+        }
+        Err(e) => {
+            sponsorship.release(&message_hash);
+            Err(format!("Failed to send transaction: {e}"))
+        }
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::program_pack::Pack;
+    use spl_token_2022::instruction::{transfer_checked, transfer_checked_with_fee};
+
+    #[test]
+    fn test_layout_follows_mint_program() {
+        let legacy = layout_of_mint(&TOKEN_PROGRAM_ID, &[]).unwrap();
+        assert_eq!(legacy.token_program, TOKEN_PROGRAM_ID);
+        assert!(legacy.extensions.is_empty());
+
+        let mut data = vec![0; Mint::LEN];
+        Mint {
+            decimals: 6,
+            is_initialized: true,
+            ..Default::default()
+        }
+        .pack_into_slice(&mut data);
+        let token_2022 = layout_of_mint(&spl_token_2022::id(), &data).unwrap();
+        assert_eq!(token_2022.token_program, spl_token_2022::id());
+        assert_eq!(token_2022.extensions, vec![ExtensionType::ImmutableOwner]);
+
+        assert!(layout_of_mint(&Pubkey::new_unique(), &data).is_err());
+    }
+
+    #[test]
+    fn test_only_transfers_into_the_address_count() {
+        let (source, mint, destination, owner) = (
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+        );
+        let compile = |instruction| {
+            let message = Message::new(&[instruction], Some(&owner));
+            (message.account_keys.clone(), message.instructions)
+        };
+        let program = spl_token_2022::id();
+
+        let (keys, instructions) = compile(
+            transfer_checked(&program, &source, &mint, &destination, &owner, &[], 5, 6).unwrap(),
+        );
+        assert!(transfers_into(&keys, &instructions, &program, &destination));
+        assert!(!transfers_into(&keys, &instructions, &program, &source));
+        assert!(!transfers_into(&keys, &instructions, &TOKEN_PROGRAM_ID, &destination));
+
+        let (keys, instructions) = compile(
+            transfer_checked_with_fee(&program, &source, &mint, &destination, &owner, &[], 5, 6, 1)
+                .unwrap(),
+        );
+        assert!(transfers_into(&keys, &instructions, &program, &destination));
+
+        let (keys, instructions) =
+            compile(create_associated_account(&owner, &owner, &mint, &program, true));
+        assert!(!transfers_into(&keys, &instructions, &program, &destination));
+    }
+}
@@ -0,0 +1,168 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{account::Account, commitment_config::CommitmentConfig, pubkey::Pubkey};
+use spl_token_2022::{extension::StateWithExtensions, state::Account as HoldingAccount};
+use tracing::{info, warn};
+
+use crate::api::common::instruction_decoding::{ASSOCIATED_TOKEN_PROGRAM_ID, TOKEN_PROGRAM_ID};
+use crate::service_providers::balance_alerts::{BalanceAlerts, BalanceCrossing, BalanceRule};
+use crate::service_providers::webhooks::WebhookSink;
+
+/// Reads every rule's balance once, returning the threshold crossings to notify.
+/// Balances that cannot be read are logged and skipped until the next check.
+pub fn check(rpc_client: &RpcClient, alerts: &BalanceAlerts) -> Vec<BalanceCrossing> {
+    let mut crossings = Vec::new();
+    for rule in alerts.rules() {
+        let (token_account, balance) = match read_balance(rpc_client, rule) {
+            Ok(read) => read,
+            Err(e) => {
+                warn!(rule = %rule.name, address = %rule.address, error = %e, "Balance check failed");
+                continue;
+            }
+        };
+        if let Some(below) = alerts.observe(rule, balance) {
+            crossings.push(crossing(rule, token_account, balance, below));
+        }
+    }
+    crossings
+}
+
+/// Delivers `crossings` through the webhook sink, acknowledging each one delivered.
+/// Returns how many were delivered.
+pub async fn notify(
+    alerts: &BalanceAlerts,
+    webhooks: &WebhookSink,
+    crossings: Vec<BalanceCrossing>,
+) -> usize {
+    let mut delivered = 0;
+    for crossing in crossings {
+        let Some(rule) = alerts.rule(&crossing.rule) else {
+            continue;
+        };
+        match webhooks.deliver(crossing.event_type(), &crossing).await {
+            Ok(()) => {
+                info!(
+                    rule = %crossing.rule,
+                    balance = %crossing.balance,
+                    threshold = %crossing.threshold,
+                    below = crossing.below,
+                    "🔔 Balance threshold crossing delivered"
+                );
+                alerts.acknowledge(rule, crossing.below);
+                delivered += 1;
+            }
+            Err(e) => {
+                warn!(rule = %crossing.rule, error = %e, "Failed to deliver balance alert");
+            }
+        }
+    }
+    delivered
+}
+
+/// Reads a rule's balance: the lamports of its address, or the amount held in the
+/// address's associated token account under whichever program owns the mint (0 when the
+/// account does not exist). Returns the token account read, if any, with the balance.
+fn read_balance(
+    rpc_client: &RpcClient,
+    rule: &BalanceRule,
+) -> Result<(Option<Pubkey>, u64), String> {
+    let Some(mint) = rule.mint else {
+        let lamports = rpc_client
+            .get_account_with_commitment(&rule.address, CommitmentConfig::confirmed())
+            .map_err(|e| format!("Failed to read account: {e}"))?
+            .value
+            .map_or(0, |account| account.lamports);
+        return Ok((None, lamports));
+    };
+
+    let token_program = read(rpc_client, &mint)?
+        .ok_or_else(|| format!("Mint {mint} not found"))?
+        .owner;
+    if token_program != TOKEN_PROGRAM_ID && token_program != spl_token_2022::id() {
+        return Err(format!("Mint {mint} is not owned by a token program"));
+    }
+    let (token_account, _) = Pubkey::find_program_address(
+        &[rule.address.as_ref(), token_program.as_ref(), mint.as_ref()],
+        &ASSOCIATED_TOKEN_PROGRAM_ID,
+    );
+    let amount = match read(rpc_client, &token_account)? {
+        Some(account) => holding_amount(&account.data)?,
+        None => 0,
+    };
+    Ok((Some(token_account), amount))
+}
+
+fn read(rpc_client: &RpcClient, address: &Pubkey) -> Result<Option<Account>, String> {
+    rpc_client
+        .get_account_with_commitment(address, CommitmentConfig::confirmed())
+        .map(|response| response.value)
+        .map_err(|e| format!("Failed to read {address}: {e}"))
+}
+
+/// Amount held by a legacy or Token-2022 holding account
+fn holding_amount(data: &[u8]) -> Result<u64, String> {
+    StateWithExtensions::<HoldingAccount>::unpack(data)
+        .map(|holding| holding.base.amount)
+        .map_err(|e| format!("Failed to parse token account: {e}"))
+}
+
+fn crossing(
+    rule: &BalanceRule,
+    token_account: Option<Pubkey>,
+    balance: u64,
+    below: bool,
+) -> BalanceCrossing {
+    BalanceCrossing {
+        rule: rule.name.clone(),
+        address: rule.address.to_string(),
+        mint: rule.mint.map(|mint| mint.to_string()).unwrap_or_default(),
+        token_account: token_account
+            .map(|account| account.to_string())
+            .unwrap_or_default(),
+        balance: balance.to_string(),
+        threshold: rule.threshold.to_string(),
+        below,
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::program_pack::Pack;
+    use spl_token_2022::state::AccountState;
+
+    #[test]
+    fn test_holding_amount() {
+        let mut data = vec![0; HoldingAccount::LEN];
+        HoldingAccount {
+            mint: Pubkey::new_unique(),
+            owner: Pubkey::new_unique(),
+            amount: 42_000,
+            state: AccountState::Initialized,
+            ..Default::default()
+        }
+        .pack_into_slice(&mut data);
+
+        assert_eq!(holding_amount(&data).unwrap(), 42_000);
+        assert!(holding_amount(&[0; 10]).is_err());
+    }
+
+    #[test]
+    fn test_crossing_describes_the_rule() {
+        let rule = BalanceRule {
+            name: "usdc-float".to_string(),
+            address: Pubkey::new_unique(),
+            mint: Some(Pubkey::new_unique()),
+            threshold: 25_000_000,
+        };
+        let token_account = Pubkey::new_unique();
+
+        let below = crossing(&rule, Some(token_account), 1_000, true);
+        assert_eq!(below.event_type(), "balance.below_threshold");
+        assert_eq!(below.token_account, token_account.to_string());
+        assert_eq!(below.threshold, "25000000");
+
+        let recovered = crossing(&rule, Some(token_account), 30_000_000, false);
+        assert_eq!(recovered.event_type(), "balance.recovered");
+    }
+}
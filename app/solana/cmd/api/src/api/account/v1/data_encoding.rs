@@ -0,0 +1,185 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use serde_json::json;
+use solana_account_decoder::{UiAccount, UiAccountData, UiAccountEncoding};
+use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::RpcAccountInfoConfig;
+use solana_rpc_client_api::{
+    client_error::{Error as ClientError, ErrorKind as ClientErrorKind},
+    request::RpcRequest,
+    response::Response as RpcResponse,
+};
+use solana_sdk::{commitment_config::CommitmentConfig, pubkey::Pubkey};
+
+use protochain_api::protochain::solana::account::v1::{Account, AccountDataEncoding};
+
+/// Resolves the requested data encoding, defaulting to raw bytes
+pub fn resolve_encoding(encoding: i32) -> Result<AccountDataEncoding, String> {
+    match AccountDataEncoding::try_from(encoding) {
+        Ok(AccountDataEncoding::Unspecified) => Ok(AccountDataEncoding::Base64),
+        Ok(encoding) => Ok(encoding),
+        Err(_) => Err(format!("Unknown account data encoding: {encoding}")),
+    }
+}
+
+/// Converts an account as the node encoded it, keeping that encoding.
+///
+/// Base64 and zstd payloads are decoded to bytes without decompressing; parsed accounts
+/// are re-serialized as JSON. A node that cannot parse an account sends it as base64
+/// instead, so the encoding reported is what the node actually sent.
+pub fn encoded_account_to_proto(address: String, account: &UiAccount) -> Result<Account, String> {
+    let mut space = account.space;
+    let (data, encoding) = match &account.data {
+        UiAccountData::Json(parsed) => {
+            space.get_or_insert(parsed.space);
+            (
+                serde_json::to_vec(parsed)
+                    .map_err(|e| format!("Failed to serialize parsed account data: {e}"))?,
+                AccountDataEncoding::JsonParsed,
+            )
+        }
+        UiAccountData::Binary(encoded, UiAccountEncoding::Base64Zstd) => (
+            STANDARD
+                .decode(encoded)
+                .map_err(|e| format!("Invalid base64+zstd account data: {e}"))?,
+            AccountDataEncoding::Base64Zstd,
+        ),
+        UiAccountData::Binary(encoded, UiAccountEncoding::Base64) => (
+            STANDARD
+                .decode(encoded)
+                .map_err(|e| format!("Invalid base64 account data: {e}"))?,
+            AccountDataEncoding::Base64,
+        ),
+        UiAccountData::LegacyBinary(encoded)
+        | UiAccountData::Binary(encoded, UiAccountEncoding::Base58) => (
+            bs58::decode(encoded)
+                .into_vec()
+                .map_err(|e| format!("Invalid base58 account data: {e}"))?,
+            AccountDataEncoding::Base64,
+        ),
+        UiAccountData::Binary(_, encoding) => {
+            return Err(format!("Unexpected account data encoding: {encoding:?}"))
+        }
+    };
+    // Nodes report the size alongside the data; uncompressed bytes can be measured anyway
+    if encoding == AccountDataEncoding::Base64 {
+        space.get_or_insert(data.len() as u64);
+    }
+    Ok(Account {
+        address,
+        lamports: account.lamports,
+        owner: account.owner.clone(),
+        executable: account.executable,
+        data,
+        rent_epoch: account.rent_epoch,
+        encoding: encoding.into(),
+        space: space.unwrap_or_default(),
+        ..Default::default()
+    })
+}
+
+/// Reads an account with its data in `encoding` (`BASE64_ZSTD` or `JSON_PARSED`),
+/// without decoding it to raw bytes
+pub fn get_encoded_account(
+    rpc_client: &RpcClient,
+    pubkey: &Pubkey,
+    commitment: CommitmentConfig,
+    min_context_slot: Option<u64>,
+    encoding: AccountDataEncoding,
+) -> Result<Option<Account>, ClientError> {
+    let ui_encoding = match encoding {
+        AccountDataEncoding::Base64Zstd => UiAccountEncoding::Base64Zstd,
+        AccountDataEncoding::JsonParsed => UiAccountEncoding::JsonParsed,
+        AccountDataEncoding::Base64 | AccountDataEncoding::Unspecified => UiAccountEncoding::Base64,
+    };
+    let response: RpcResponse<Option<UiAccount>> = rpc_client.send(
+        RpcRequest::GetAccountInfo,
+        json!([
+            pubkey.to_string(),
+            RpcAccountInfoConfig {
+                encoding: Some(ui_encoding),
+                data_slice: None,
+                commitment: Some(commitment),
+                min_context_slot,
+            },
+        ]),
+    )?;
+    response
+        .value
+        .map(|account| encoded_account_to_proto(pubkey.to_string(), &account))
+        .transpose()
+        .map_err(|e| ClientError::from(ClientErrorKind::Custom(e)))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_account_decoder::parse_account_data::ParsedAccount;
+
+    fn ui_account(data: UiAccountData) -> UiAccount {
+        UiAccount {
+            lamports: 42,
+            data,
+            owner: Pubkey::new_unique().to_string(),
+            executable: false,
+            rent_epoch: 7,
+            space: None,
+        }
+    }
+
+    #[test]
+    fn test_resolve_encoding_defaults_to_base64() {
+        assert_eq!(resolve_encoding(0).unwrap(), AccountDataEncoding::Base64);
+        assert_eq!(
+            resolve_encoding(AccountDataEncoding::JsonParsed.into()).unwrap(),
+            AccountDataEncoding::JsonParsed
+        );
+        assert!(resolve_encoding(99).is_err());
+    }
+
+    #[test]
+    fn test_binary_data_keeps_node_encoding() {
+        let account = ui_account(UiAccountData::Binary(
+            STANDARD.encode([1, 2, 3]),
+            UiAccountEncoding::Base64Zstd,
+        ));
+        let proto = encoded_account_to_proto("addr".to_string(), &account).unwrap();
+        assert_eq!(proto.data, vec![1, 2, 3]);
+        assert_eq!(proto.encoding(), AccountDataEncoding::Base64Zstd);
+        assert_eq!(proto.lamports, 42);
+        assert_eq!(proto.rent_epoch, 7);
+    }
+
+    #[test]
+    fn test_unparseable_account_falls_back_to_base64() {
+        let account =
+            ui_account(UiAccountData::Binary(STANDARD.encode([9]), UiAccountEncoding::Base64));
+        let proto = encoded_account_to_proto("addr".to_string(), &account).unwrap();
+        assert_eq!(proto.data, vec![9]);
+        assert_eq!(proto.encoding(), AccountDataEncoding::Base64);
+        assert_eq!(proto.space, 1);
+    }
+
+    #[test]
+    fn test_parsed_account_is_json() {
+        let account = ui_account(UiAccountData::Json(ParsedAccount {
+            program: "spl-token".to_string(),
+            parsed: json!({ "type": "account", "info": { "mint": "m" } }),
+            space: 165,
+        }));
+        let proto = encoded_account_to_proto("addr".to_string(), &account).unwrap();
+        assert_eq!(proto.encoding(), AccountDataEncoding::JsonParsed);
+        assert_eq!(proto.space, 165);
+
+        let value: serde_json::Value = serde_json::from_slice(&proto.data).unwrap();
+        assert_eq!(value["program"], "spl-token");
+        assert_eq!(value["parsed"]["info"]["mint"], "m");
+    }
+
+    #[test]
+    fn test_invalid_data_is_an_error() {
+        let account =
+            ui_account(UiAccountData::Binary("not base64!".to_string(), UiAccountEncoding::Base64));
+        assert!(encoded_account_to_proto("addr".to_string(), &account).is_err());
+    }
+}
@@ -0,0 +1,193 @@
+use solana_sdk::{account::Account as SolanaAccount, hash::Hasher};
+use std::ops::Range;
+use tokio::sync::mpsc;
+use tonic::Status;
+
+use protochain_api::protochain::solana::account::v1::{
+    get_account_data_response::Payload, AccountDataChunk, AccountDataHeader, AccountDataTrailer,
+    GetAccountDataResponse,
+};
+
+/// Default chunk size: 1 MiB
+pub const DEFAULT_CHUNK_SIZE: u32 = 1 << 20;
+/// Smallest chunk size a request may ask for
+const MIN_CHUNK_SIZE: u32 = 1 << 10;
+/// Largest chunk size a request may ask for, leaving headroom under gRPC's 4 MiB default
+const MAX_CHUNK_SIZE: u32 = 3 << 20;
+
+/// Resolves the requested chunk size, where zero selects the default
+pub fn resolve_chunk_size(requested: u32) -> Result<usize, String> {
+    let chunk_size = if requested == 0 {
+        DEFAULT_CHUNK_SIZE
+    } else {
+        requested
+    };
+    if !(MIN_CHUNK_SIZE..=MAX_CHUNK_SIZE).contains(&chunk_size) {
+        return Err(format!(
+            "Chunk size must be between {MIN_CHUNK_SIZE} and {MAX_CHUNK_SIZE} bytes"
+        ));
+    }
+    Ok(chunk_size as usize)
+}
+
+/// Resolves the byte range to stream from `data_length` bytes of account data.
+///
+/// A zero `length` streams from `offset` to the end. Ranges reaching past the end of the
+/// data are rejected rather than clamped, so a client never silently receives less.
+pub fn resolve_range(data_length: usize, offset: u64, length: u64) -> Result<Range<usize>, String> {
+    let start = usize::try_from(offset)
+        .ok()
+        .filter(|start| *start <= data_length)
+        .ok_or_else(|| {
+            format!("Offset {offset} is past the end of the account data ({data_length} bytes)")
+        })?;
+    if length == 0 {
+        return Ok(start..data_length);
+    }
+
+    usize::try_from(length)
+        .ok()
+        .and_then(|length| start.checked_add(length))
+        .filter(|end| *end <= data_length)
+        .map(|end| start..end)
+        .ok_or_else(|| {
+            format!(
+                "Range of {length} bytes from offset {offset} is past the end of the account \
+                 data ({data_length} bytes)"
+            )
+        })
+}
+
+const fn message(payload: Payload) -> GetAccountDataResponse {
+    GetAccountDataResponse {
+        payload: Some(payload),
+    }
+}
+
+/// Streams `range` of the account's data as a header, the chunks in order and a trailer
+/// carrying the SHA-256 of every streamed byte.
+///
+/// Stops early if the client disconnects. The bounded channel applies backpressure, so
+/// only a few chunks are buffered at a time regardless of the account size.
+pub async fn stream_account_data(
+    address: String,
+    account: SolanaAccount,
+    slot: u64,
+    range: Range<usize>,
+    chunk_size: usize,
+    tx: mpsc::Sender<Result<GetAccountDataResponse, Status>>,
+) {
+    let header = AccountDataHeader {
+        address,
+        lamports: account.lamports,
+        owner: account.owner.to_string(),
+        executable: account.executable,
+        rent_epoch: account.rent_epoch,
+        data_length: account.data.len() as u64,
+        offset: range.start as u64,
+        length: range.len() as u64,
+        slot,
+    };
+    if tx.send(Ok(message(Payload::Header(header)))).await.is_err() {
+        return;
+    }
+
+    let mut hasher = Hasher::default();
+    let mut chunk_count = 0u32;
+    let mut offset = range.start;
+    for data in account.data[range.clone()].chunks(chunk_size) {
+        hasher.hash(data);
+        let chunk = AccountDataChunk {
+            offset: offset as u64,
+            data: data.to_vec(),
+        };
+        if tx.send(Ok(message(Payload::Chunk(chunk)))).await.is_err() {
+            return;
+        }
+        offset += data.len();
+        chunk_count += 1;
+    }
+
+    let trailer = AccountDataTrailer {
+        total_bytes: range.len() as u64,
+        chunk_count,
+        sha256: hex::encode(hasher.result().to_bytes()),
+    };
+    // Best effort - the client may already have disconnected
+    let _ = tx.send(Ok(message(Payload::Trailer(trailer)))).await;
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{hash::hash, pubkey::Pubkey};
+
+    #[test]
+    fn test_chunk_size_bounds() {
+        assert_eq!(resolve_chunk_size(0).unwrap(), DEFAULT_CHUNK_SIZE as usize);
+        assert_eq!(resolve_chunk_size(MIN_CHUNK_SIZE).unwrap(), MIN_CHUNK_SIZE as usize);
+        assert!(resolve_chunk_size(MIN_CHUNK_SIZE - 1).is_err());
+        assert!(resolve_chunk_size(MAX_CHUNK_SIZE + 1).is_err());
+    }
+
+    #[test]
+    fn test_resolve_range() {
+        assert_eq!(resolve_range(100, 0, 0).unwrap(), 0..100);
+        assert_eq!(resolve_range(100, 40, 0).unwrap(), 40..100);
+        assert_eq!(resolve_range(100, 40, 60).unwrap(), 40..100);
+        assert_eq!(resolve_range(100, 100, 0).unwrap(), 100..100);
+        assert!(resolve_range(100, 101, 0).is_err());
+        assert!(resolve_range(100, 40, 61).is_err());
+        assert!(resolve_range(100, 1, u64::MAX).is_err());
+    }
+
+    #[tokio::test]
+    async fn test_streams_header_chunks_and_trailer() {
+        let data: Vec<u8> = (0..=255u8).cycle().take(2_500).collect();
+        let account = SolanaAccount {
+            lamports: 1,
+            data: data.clone(),
+            owner: Pubkey::new_unique(),
+            executable: false,
+            rent_epoch: 0,
+        };
+        let (tx, mut rx) = mpsc::channel(4);
+        tokio::spawn(stream_account_data(
+            "addr".to_string(),
+            account,
+            7,
+            resolve_range(data.len(), 100, 0).unwrap(),
+            1_024,
+            tx,
+        ));
+
+        let mut messages = Vec::new();
+        while let Some(response) = rx.recv().await {
+            messages.push(response.unwrap().payload.unwrap());
+        }
+
+        assert_eq!(messages.len(), 5);
+        let Payload::Header(header) = &messages[0] else {
+            panic!("expected a header first");
+        };
+        assert_eq!((header.data_length, header.offset, header.length), (2_500, 100, 2_400));
+
+        let mut streamed = Vec::new();
+        for payload in &messages[1..4] {
+            let Payload::Chunk(chunk) = payload else {
+                panic!("expected a chunk");
+            };
+            assert_eq!(chunk.offset, 100 + streamed.len() as u64);
+            streamed.extend_from_slice(&chunk.data);
+        }
+        assert_eq!(streamed, data[100..]);
+
+        let Payload::Trailer(trailer) = &messages[4] else {
+            panic!("expected a trailer last");
+        };
+        assert_eq!(trailer.total_bytes, 2_400);
+        assert_eq!(trailer.chunk_count, 3);
+        assert_eq!(trailer.sha256, hex::encode(hash(&data[100..]).to_bytes()));
+    }
+}
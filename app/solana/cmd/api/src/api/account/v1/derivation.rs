@@ -0,0 +1,145 @@
+use protochain_api::protochain::solana::account::v1::{
+    program_address_seed::Seed, ProgramAddressSeed,
+};
+use solana_sdk::pubkey::{Pubkey, MAX_SEEDS, MAX_SEED_LEN};
+use spl_token_2022::ID as TOKEN_2022_PROGRAM_ID;
+use std::str::FromStr;
+
+use crate::api::common::instruction_decoding::{ASSOCIATED_TOKEN_PROGRAM_ID, TOKEN_PROGRAM_ID};
+
+/// Decodes the seeds of a `DeriveProgramAddress` request into the bytes
+/// `find_program_address` takes, leaving room for the bump seed it appends
+pub fn decode_seeds(seeds: &[ProgramAddressSeed]) -> Result<Vec<Vec<u8>>, String> {
+    if seeds.len() >= MAX_SEEDS {
+        return Err(format!("At most {} seeds may be given, got {}", MAX_SEEDS - 1, seeds.len()));
+    }
+
+    seeds
+        .iter()
+        .enumerate()
+        .map(|(i, seed)| {
+            let bytes = match &seed.seed {
+                Some(Seed::Raw(raw)) => raw.clone(),
+                Some(Seed::Utf8(text)) => text.as_bytes().to_vec(),
+                Some(Seed::Address(address)) => Pubkey::from_str(address)
+                    .map_err(|e| format!("Seed {i}: invalid address: {e}"))?
+                    .to_bytes()
+                    .to_vec(),
+                Some(Seed::U64Le(value)) => value.to_le_bytes().to_vec(),
+                None => return Err(format!("Seed {i} is empty")),
+            };
+            if bytes.len() > MAX_SEED_LEN {
+                return Err(format!(
+                    "Seed {i} is {} bytes, at most {MAX_SEED_LEN} are allowed",
+                    bytes.len()
+                ));
+            }
+            Ok(bytes)
+        })
+        .collect()
+}
+
+/// Finds the program-derived address of `seeds` under `program_id` and its bump seed
+pub fn derive_program_address(
+    seeds: &[Vec<u8>],
+    program_id: &Pubkey,
+) -> Result<(Pubkey, u8), String> {
+    let seeds: Vec<&[u8]> = seeds.iter().map(Vec::as_slice).collect();
+    Pubkey::try_find_program_address(&seeds, program_id)
+        .ok_or_else(|| "No bump seed yields an address off the curve".to_string())
+}
+
+/// Resolves the token program of a `DeriveAssociatedTokenAddress` request, defaulting to
+/// Token-2022 like the rest of the API
+pub fn resolve_token_program(token_program_id: &str) -> Result<Pubkey, String> {
+    if token_program_id.is_empty() {
+        return Ok(TOKEN_2022_PROGRAM_ID);
+    }
+    let program =
+        Pubkey::from_str(token_program_id).map_err(|e| format!("Invalid token program ID: {e}"))?;
+    if program != TOKEN_PROGRAM_ID && program != TOKEN_2022_PROGRAM_ID {
+        return Err(format!(
+            "Token program must be SPL Token ({TOKEN_PROGRAM_ID}) or Token-2022 ({TOKEN_2022_PROGRAM_ID})"
+        ));
+    }
+    Ok(program)
+}
+
+/// Derives `owner`'s associated token account for `mint` under `token_program` and its
+/// bump seed
+pub fn derive_associated_token_address(
+    owner: &Pubkey,
+    mint: &Pubkey,
+    token_program: &Pubkey,
+) -> (Pubkey, u8) {
+    Pubkey::find_program_address(
+        &[owner.as_ref(), token_program.as_ref(), mint.as_ref()],
+        &ASSOCIATED_TOKEN_PROGRAM_ID,
+    )
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::program::token::v1::metadata::{
+        metaplex_metadata_address, METAPLEX_METADATA_PROGRAM_ID,
+    };
+
+    fn seed(seed: Seed) -> ProgramAddressSeed {
+        ProgramAddressSeed { seed: Some(seed) }
+    }
+
+    #[test]
+    fn test_seed_forms_derive_known_addresses() {
+        let mint = Pubkey::new_unique();
+        let seeds = decode_seeds(&[
+            seed(Seed::Utf8("metadata".to_string())),
+            seed(Seed::Raw(METAPLEX_METADATA_PROGRAM_ID.to_bytes().to_vec())),
+            seed(Seed::Address(mint.to_string())),
+        ])
+        .unwrap();
+        let (address, _) = derive_program_address(&seeds, &METAPLEX_METADATA_PROGRAM_ID).unwrap();
+        assert_eq!(address, metaplex_metadata_address(&mint));
+
+        assert_eq!(
+            decode_seeds(&[seed(Seed::U64Le(1))]).unwrap(),
+            vec![vec![1, 0, 0, 0, 0, 0, 0, 0]]
+        );
+    }
+
+    #[test]
+    fn test_seed_limits() {
+        assert!(decode_seeds(&[seed(Seed::Raw(vec![0; MAX_SEED_LEN + 1]))]).is_err());
+        assert!(decode_seeds(&[ProgramAddressSeed { seed: None }]).is_err());
+        let too_many = vec![seed(Seed::Utf8("a".to_string())); MAX_SEEDS];
+        assert!(decode_seeds(&too_many).is_err());
+        assert!(decode_seeds(&too_many[1..]).is_ok());
+    }
+
+    #[test]
+    fn test_associated_token_address_follows_token_program() {
+        let owner = Pubkey::new_unique();
+        let mint = Pubkey::new_unique();
+        let token_program = resolve_token_program("").unwrap();
+        assert_eq!(token_program, TOKEN_2022_PROGRAM_ID);
+        let (address, bump) = derive_associated_token_address(&owner, &mint, &token_program);
+        assert_eq!(
+            Pubkey::create_program_address(
+                &[
+                    owner.as_ref(),
+                    token_program.as_ref(),
+                    mint.as_ref(),
+                    &[bump]
+                ],
+                &ASSOCIATED_TOKEN_PROGRAM_ID,
+            )
+            .unwrap(),
+            address
+        );
+
+        let legacy = resolve_token_program(&TOKEN_PROGRAM_ID.to_string()).unwrap();
+        assert_ne!(derive_associated_token_address(&owner, &mint, &legacy).0, address);
+        assert!(resolve_token_program(&Pubkey::new_unique().to_string()).is_err());
+    }
+}
@@ -0,0 +1,288 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{commitment_config::CommitmentConfig, hash::Hash, pubkey::Pubkey};
+use std::collections::HashMap;
+use std::str::FromStr;
+use std::time::{Duration, Instant};
+use tonic::{metadata::MetadataValue, Code, Response, Status};
+use tonic_types::{ErrorDetails, StatusExt};
+
+use crate::api::common::amount_parsing::{parse_amount, ERROR_DOMAIN};
+use crate::api::transaction::v1::description::SOL_DECIMALS;
+use protochain_api::protochain::solana::account::v1::{
+    fund_native_request::TypedAmount, Cluster, FundNativeRequest, FundingMode,
+};
+
+/// `ErrorInfo` reason for funding requests the connected cluster cannot serve
+pub const UNSUPPORTED_ON_CLUSTER_REASON: &str = "UNSUPPORTED_ON_CLUSTER";
+/// `ErrorInfo` reason for requests using a field the server no longer accepts
+pub const DEPRECATED_FIELD_REASON: &str = "DEPRECATED_FIELD";
+/// Response header naming a deprecated request field the request relied on
+pub const DEPRECATION_HEADER: &str = "x-protochain-deprecation";
+/// Value of `DEPRECATION_HEADER` for the string `FundNative` amount
+const STRING_AMOUNT_FIELD: &str = "FundNativeRequest.amount";
+
+/// How often `wait_for_balance` re-reads the funded balance
+const BALANCE_POLL_INTERVAL: Duration = Duration::from_millis(250);
+
+/// Genesis hash of mainnet-beta
+const MAINNET_BETA_GENESIS_HASH: &str = "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d";
+/// Genesis hash of devnet
+const DEVNET_GENESIS_HASH: &str = "EtWTRABZaYq6iMfeYKouRu166VU2xqa1wcaWoxPkrZBG";
+/// Genesis hash of testnet
+const TESTNET_GENESIS_HASH: &str = "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3zQawwpjk2NsNY";
+
+/// Identifies the cluster from its genesis hash; any other hash is a local validator
+pub fn cluster_from_genesis_hash(genesis_hash: &Hash) -> Cluster {
+    let known = |hash: &str| Hash::from_str(hash).is_ok_and(|hash| hash == *genesis_hash);
+    if known(MAINNET_BETA_GENESIS_HASH) {
+        Cluster::MainnetBeta
+    } else if known(DEVNET_GENESIS_HASH) {
+        Cluster::Devnet
+    } else if known(TESTNET_GENESIS_HASH) {
+        Cluster::Testnet
+    } else {
+        Cluster::Localnet
+    }
+}
+
+/// Whether the cluster's RPC nodes serve airdrops
+const fn supports_airdrop(cluster: Cluster) -> bool {
+    !matches!(cluster, Cluster::MainnetBeta)
+}
+
+/// Chooses how to fund an account on `cluster`.
+///
+/// Airdrops are used wherever the cluster serves them. Elsewhere the configured treasury
+/// transfers the funds, and without one the request fails with `UNSUPPORTED_ON_CLUSTER`.
+/// A failed airdrop falling back to the treasury is decided by the caller.
+pub fn funding_mode(cluster: Cluster, treasury_configured: bool) -> Result<FundingMode, Status> {
+    if supports_airdrop(cluster) {
+        Ok(FundingMode::Airdrop)
+    } else if treasury_configured {
+        Ok(FundingMode::TreasuryTransfer)
+    } else {
+        Err(unsupported_on_cluster(cluster))
+    }
+}
+
+/// Polls `address`'s balance at `commitment` until it reaches `target`, returning the
+/// balance seen.
+///
+/// A confirmed funding transaction is not always reflected by the next balance read at
+/// the same commitment, since reads may be served by a node that lags the one that
+/// confirmed it. Failed reads are retried; after `timeout` the wait fails with
+/// `DEADLINE_EXCEEDED`.
+#[allow(clippy::result_large_err)]
+pub async fn wait_for_balance(
+    rpc_client: &RpcClient,
+    address: &Pubkey,
+    target: u64,
+    commitment: CommitmentConfig,
+    timeout: Duration,
+) -> Result<u64, Status> {
+    let started = Instant::now();
+    loop {
+        let balance = rpc_client
+            .get_balance_with_commitment(address, commitment)
+            .map(|response| response.value);
+        match balance {
+            Ok(balance) if balance >= target => return Ok(balance),
+            Ok(balance) if started.elapsed() >= timeout => {
+                return Err(Status::deadline_exceeded(format!(
+                    "Funded balance of {address} not visible after {}s: {balance} lamports, \
+                     expected at least {target}",
+                    timeout.as_secs()
+                )))
+            }
+            Err(e) if started.elapsed() >= timeout => {
+                return Err(Status::deadline_exceeded(format!(
+                    "Funded balance of {address} not visible after {}s: {e}",
+                    timeout.as_secs()
+                )))
+            }
+            _ => tokio::time::sleep(BALANCE_POLL_INTERVAL).await,
+        }
+    }
+}
+
+/// `FAILED_PRECONDITION` status carrying `ErrorInfo` with the cluster, so that tests and
+/// tooling can branch on the environment
+fn unsupported_on_cluster(cluster: Cluster) -> Status {
+    let details = ErrorDetails::with_error_info(
+        UNSUPPORTED_ON_CLUSTER_REASON,
+        ERROR_DOMAIN,
+        HashMap::from([("cluster".to_string(), cluster.as_str_name().to_string())]),
+    );
+    Status::with_error_details(
+        Code::FailedPrecondition,
+        format!(
+            "{UNSUPPORTED_ON_CLUSTER_REASON}: {} does not serve airdrops and no funding \
+             treasury is configured",
+            cluster.as_str_name()
+        ),
+        details,
+    )
+}
+
+/// Resolves the lamports a `FundNative` request asks for, returning whether the request
+/// used the deprecated string amount.
+///
+/// A typed amount (`lamports` or `native_amount`) is preferred. The string amount is
+/// parsed as before unless `reject_string_amounts` is set, and a request setting both
+/// forms is rejected.
+#[allow(deprecated, clippy::result_large_err)]
+pub fn requested_lamports(
+    req: &FundNativeRequest,
+    reject_string_amounts: bool,
+) -> Result<(u64, bool), Status> {
+    let Some(typed_amount) = &req.typed_amount else {
+        if !req.amount.is_empty() && reject_string_amounts {
+            return Err(deprecated_field("amount", "set lamports or native_amount instead"));
+        }
+        let lamports =
+            parse_amount(&req.amount, req.decimals).map_err(|e| e.into_status("amount"))?;
+        return Ok((lamports, true));
+    };
+    if !req.amount.is_empty() {
+        return Err(Status::invalid_argument(
+            "Set either a typed amount or the deprecated string amount, not both",
+        ));
+    }
+
+    match typed_amount {
+        TypedAmount::Lamports(lamports) => Ok((*lamports, false)),
+        TypedAmount::NativeAmount(amount) => {
+            if amount.decimals != 0 && amount.decimals != SOL_DECIMALS {
+                return Err(Status::invalid_argument(format!(
+                    "native_amount decimals must be {SOL_DECIMALS} (SOL), got {}",
+                    amount.decimals
+                )));
+            }
+            Ok((amount.value, false))
+        }
+    }
+}
+
+/// Flags a response to a request that used the deprecated string amount
+pub fn mark_string_amount_deprecated<T>(response: &mut Response<T>) {
+    response
+        .metadata_mut()
+        .insert(DEPRECATION_HEADER, MetadataValue::from_static(STRING_AMOUNT_FIELD));
+}
+
+/// `INVALID_ARGUMENT` status carrying `ErrorInfo` with the deprecated field's name
+fn deprecated_field(field: &str, advice: &str) -> Status {
+    let details = ErrorDetails::with_error_info(
+        DEPRECATED_FIELD_REASON,
+        ERROR_DOMAIN,
+        HashMap::from([("field".to_string(), field.to_string())]),
+    );
+    Status::with_error_details(
+        Code::InvalidArgument,
+        format!("{DEPRECATED_FIELD_REASON}: {field} is no longer accepted; {advice}"),
+        details,
+    )
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use protochain_api::protochain::solana::r#type::v1::Amount;
+
+    #[test]
+    fn test_cluster_from_genesis_hash() {
+        let mainnet = Hash::from_str(MAINNET_BETA_GENESIS_HASH).unwrap();
+        let devnet = Hash::from_str(DEVNET_GENESIS_HASH).unwrap();
+        assert_eq!(cluster_from_genesis_hash(&mainnet), Cluster::MainnetBeta);
+        assert_eq!(cluster_from_genesis_hash(&devnet), Cluster::Devnet);
+        assert_eq!(cluster_from_genesis_hash(&Hash::new_unique()), Cluster::Localnet);
+    }
+
+    #[test]
+    fn test_funding_mode() {
+        assert_eq!(funding_mode(Cluster::Localnet, false).unwrap(), FundingMode::Airdrop);
+        assert_eq!(funding_mode(Cluster::Devnet, true).unwrap(), FundingMode::Airdrop);
+        assert_eq!(
+            funding_mode(Cluster::MainnetBeta, true).unwrap(),
+            FundingMode::TreasuryTransfer
+        );
+    }
+
+    #[test]
+    fn test_unsupported_on_cluster_carries_error_info() {
+        let status = funding_mode(Cluster::MainnetBeta, false).unwrap_err();
+        assert_eq!(status.code(), Code::FailedPrecondition);
+
+        let info = status.get_details_error_info().unwrap();
+        assert_eq!(info.reason, UNSUPPORTED_ON_CLUSTER_REASON);
+        assert_eq!(info.metadata.get("cluster").unwrap(), "CLUSTER_MAINNET_BETA");
+    }
+
+    #[allow(deprecated)]
+    fn string_request(amount: &str, decimals: u32) -> FundNativeRequest {
+        FundNativeRequest {
+            amount: amount.to_string(),
+            decimals,
+            ..Default::default()
+        }
+    }
+
+    fn typed_request(typed_amount: TypedAmount) -> FundNativeRequest {
+        FundNativeRequest {
+            typed_amount: Some(typed_amount),
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_typed_amounts() {
+        let lamports = typed_request(TypedAmount::Lamports(2_000_000_000));
+        assert_eq!(requested_lamports(&lamports, true).unwrap(), (2_000_000_000, false));
+
+        let native = typed_request(TypedAmount::NativeAmount(Amount {
+            value: 1_500_000_000,
+            decimals: SOL_DECIMALS,
+        }));
+        assert_eq!(requested_lamports(&native, false).unwrap(), (1_500_000_000, false));
+
+        let wrong_decimals = typed_request(TypedAmount::NativeAmount(Amount {
+            value: 1,
+            decimals: 6,
+        }));
+        assert_eq!(
+            requested_lamports(&wrong_decimals, false)
+                .unwrap_err()
+                .code(),
+            Code::InvalidArgument
+        );
+    }
+
+    #[test]
+    fn test_string_amount_is_deprecated_then_rejected() {
+        let request = string_request("1.5", 9);
+        assert_eq!(requested_lamports(&request, false).unwrap(), (1_500_000_000, true));
+
+        let status = requested_lamports(&request, true).unwrap_err();
+        let info = status.get_details_error_info().unwrap();
+        assert_eq!(info.reason, DEPRECATED_FIELD_REASON);
+        assert_eq!(info.metadata.get("field").unwrap(), "amount");
+    }
+
+    #[test]
+    #[allow(deprecated)]
+    fn test_both_amount_forms_rejected() {
+        let request = FundNativeRequest {
+            amount: "1".to_string(),
+            ..typed_request(TypedAmount::Lamports(1))
+        };
+        assert_eq!(requested_lamports(&request, false).unwrap_err().code(), Code::InvalidArgument);
+    }
+
+    #[test]
+    fn test_deprecation_header() {
+        let mut response = Response::new(());
+        mark_string_amount_deprecated(&mut response);
+        assert_eq!(response.metadata().get(DEPRECATION_HEADER).unwrap(), STRING_AMOUNT_FIELD);
+    }
+}
@@ -0,0 +1,162 @@
+use protochain_api::protochain::solana::account::v1::{
+    import_key_pair_request::SecretKey, MnemonicSecretKey, SecretKeyFormat,
+};
+use solana_sdk::pubkey::Pubkey;
+use solana_sdk::signature::{Keypair, Signer};
+use solana_sdk::signer::keypair::keypair_from_seed;
+use std::str::FromStr;
+
+use crate::api::transaction::v1::mnemonic::derive_keypairs;
+
+/// Length of an encoded keypair: the 32-byte secret key followed by the public key
+const KEYPAIR_LEN: usize = 64;
+
+/// Decodes an imported key, checking it against `expected_public_key` when one is given
+pub fn decode_key(secret_key: &SecretKey, expected_public_key: &str) -> Result<Keypair, String> {
+    let keypair = match secret_key {
+        SecretKey::Base58(encoded) => decode_base58(encoded)?,
+        SecretKey::JsonArray(encoded) => decode_json_array(encoded)?,
+        SecretKey::Mnemonic(mnemonic) => derive_mnemonic(mnemonic)?,
+    };
+
+    if !expected_public_key.is_empty() {
+        let expected = Pubkey::from_str(expected_public_key)
+            .map_err(|e| format!("Invalid expected_public_key: {e}"))?;
+        if keypair.pubkey() != expected {
+            return Err(format!(
+                "Imported key has public key {}, not the expected {expected}",
+                keypair.pubkey()
+            ));
+        }
+    }
+    Ok(keypair)
+}
+
+/// Encodes a keypair in an export format
+pub fn encode_key(keypair: &Keypair, format: SecretKeyFormat) -> String {
+    match format {
+        SecretKeyFormat::JsonArray => {
+            let bytes = keypair.to_bytes();
+            format!(
+                "[{}]",
+                bytes
+                    .iter()
+                    .map(u8::to_string)
+                    .collect::<Vec<_>>()
+                    .join(",")
+            )
+        }
+        SecretKeyFormat::Base58 | SecretKeyFormat::Unspecified => {
+            bs58::encode(keypair.to_bytes()).into_string()
+        }
+    }
+}
+
+fn decode_base58(encoded: &str) -> Result<Keypair, String> {
+    let bytes = bs58::decode(encoded.trim())
+        .into_vec()
+        .map_err(|e| format!("Invalid base58 secret key: {e}"))?;
+    keypair_from_bytes(&bytes)
+}
+
+fn decode_json_array(encoded: &str) -> Result<Keypair, String> {
+    let bytes: Vec<u8> = serde_json::from_str(encoded)
+        .map_err(|e| format!("Secret key must be a JSON array of byte values: {e}"))?;
+    keypair_from_bytes(&bytes)
+}
+
+fn derive_mnemonic(mnemonic: &MnemonicSecretKey) -> Result<Keypair, String> {
+    let paths = if mnemonic.derivation_path.is_empty() {
+        Vec::new()
+    } else {
+        vec![mnemonic.derivation_path.clone()]
+    };
+    derive_keypairs(&mnemonic.mnemonic, &mnemonic.passphrase, &paths)?
+        .pop()
+        .ok_or_else(|| "No key was derived".to_string())
+}
+
+/// Builds a keypair from its 64-byte encoding, rejecting bytes whose public half does not
+/// belong to the secret half
+fn keypair_from_bytes(bytes: &[u8]) -> Result<Keypair, String> {
+    if bytes.len() != KEYPAIR_LEN {
+        return Err(format!("Secret key must be {KEYPAIR_LEN} bytes, got {}", bytes.len()));
+    }
+    // The public half is derived from the secret half rather than trusted, as
+    // `Keypair::from_bytes` does not check that the two belong together
+    let keypair = keypair_from_seed(&bytes[..KEYPAIR_LEN / 2])
+        .map_err(|e| format!("Invalid secret key: {e}"))?;
+    if keypair.pubkey().to_bytes()[..] != bytes[KEYPAIR_LEN / 2..] {
+        return Err("Secret key does not match the public key it was encoded with".to_string());
+    }
+    Ok(keypair)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    const PHRASE: &str = "abandon abandon abandon abandon abandon abandon abandon abandon \
+                          abandon abandon abandon about";
+
+    #[test]
+    fn test_formats_round_trip() {
+        let keypair = Keypair::new();
+        for (format, wrap) in [
+            (SecretKeyFormat::Base58, SecretKey::Base58 as fn(String) -> SecretKey),
+            (SecretKeyFormat::JsonArray, SecretKey::JsonArray),
+        ] {
+            let encoded = encode_key(&keypair, format);
+            let decoded = decode_key(&wrap(encoded), &keypair.pubkey().to_string()).unwrap();
+            assert_eq!(decoded.pubkey(), keypair.pubkey());
+        }
+    }
+
+    #[test]
+    fn test_json_array_matches_solana_cli() {
+        let keypair = Keypair::new();
+        let id_json = serde_json::to_string(&keypair.to_bytes().to_vec()).unwrap();
+        assert_eq!(encode_key(&keypair, SecretKeyFormat::JsonArray), id_json);
+    }
+
+    #[test]
+    fn test_mnemonic_uses_derivation_path() {
+        let mnemonic = |path: &str| {
+            SecretKey::Mnemonic(MnemonicSecretKey {
+                mnemonic: PHRASE.to_string(),
+                passphrase: String::new(),
+                derivation_path: path.to_string(),
+            })
+        };
+        let default = decode_key(&mnemonic(""), "").unwrap();
+        let first = decode_key(&mnemonic("m/44'/501'/0'/0'"), "").unwrap();
+        let second = decode_key(&mnemonic("m/44'/501'/1'/0'"), "").unwrap();
+        assert_eq!(default.pubkey(), first.pubkey());
+        assert_ne!(first.pubkey(), second.pubkey());
+
+        // A wrong path is caught by the expected public key
+        assert!(decode_key(&mnemonic("m/44'/501'/1'/0'"), &first.pubkey().to_string()).is_err());
+        assert!(decode_key(&mnemonic("m/44'/501'/0/0"), "").is_err());
+    }
+
+    #[test]
+    fn test_rejects_malformed_keys() {
+        let keypair = Keypair::new();
+        let mut mismatched = keypair.to_bytes();
+        mismatched[32..].copy_from_slice(&Keypair::new().pubkey().to_bytes());
+
+        assert!(decode_key(&SecretKey::Base58(bs58::encode(mismatched).into_string()), "").is_err());
+        assert!(decode_key(
+            &SecretKey::Base58(bs58::encode(&keypair.to_bytes()[..32]).into_string()),
+            ""
+        )
+        .is_err());
+        assert!(decode_key(&SecretKey::JsonArray("[1,2,300]".to_string()), "").is_err());
+        assert!(decode_key(
+            &SecretKey::Base58(encode_key(&keypair, SecretKeyFormat::Base58)),
+            &Keypair::new().pubkey().to_string()
+        )
+        .is_err());
+    }
+}
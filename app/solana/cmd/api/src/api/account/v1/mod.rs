@@ -5,8 +5,26 @@
 
 /// gRPC service wrapper module for account operations
 pub mod account_v1_api;
+/// Background creation of missing associated token accounts for registered owners
+pub mod ata_watch;
+/// Background checks of balance threshold rules
+pub mod balance_watch;
+/// Data encodings `GetAccount` can return account data in
+pub mod data_encoding;
+/// Chunked streaming of large account data
+pub mod data_stream;
+/// Program-derived and associated token address derivation
+pub mod derivation;
+/// Cluster detection and funding mode selection for `FundNative`
+pub mod funding;
+/// Keypair encodings accepted by `ImportKeyPair` and produced by `ExportKeyPair`
+pub mod key_formats;
+/// Token holdings, mint details and pagination for `GetPortfolio` and `GetTokenBalances`
+pub mod portfolio;
 /// Core business logic implementation module for account operations
 pub mod service_impl;
+/// Account snapshots and the validator account files they are imported as
+pub mod snapshot;
 
 pub use account_v1_api::AccountV1API;
 pub use service_impl::AccountServiceImpl;
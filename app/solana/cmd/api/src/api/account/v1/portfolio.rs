@@ -0,0 +1,245 @@
+use serde_json::json;
+use solana_account_decoder::UiAccountEncoding;
+use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::RpcAccountInfoConfig;
+use solana_rpc_client_api::{
+    client_error::Error as ClientError,
+    request::RpcRequest,
+    response::{Response as RpcResponse, RpcKeyedAccount},
+};
+use solana_sdk::{account::Account, commitment_config::CommitmentConfig, pubkey::Pubkey};
+use spl_token_2022::{
+    extension::StateWithExtensions,
+    state::{Account as TokenAccount, AccountState, Mint},
+};
+use std::collections::HashMap;
+use std::str::FromStr;
+
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+use crate::api::common::min_context_slot::get_multiple_accounts;
+use crate::api::program::token::v1::metadata::{
+    metaplex_metadata_address, parse_metaplex_metadata, token_2022_metadata, OnChainMetadata,
+};
+
+/// Holdings returned per page when a request names no page size
+pub const DEFAULT_PAGE_SIZE: usize = 50;
+/// Most holdings returned per page
+pub const MAX_PAGE_SIZE: usize = 100;
+/// Most accounts read per `getMultipleAccounts` call
+const MULTIPLE_ACCOUNTS_LIMIT: usize = 100;
+
+/// A token account held by the portfolio owner
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Holding {
+    /// Token account address
+    pub address: Pubkey,
+    /// Mint of the tokens held
+    pub mint: Pubkey,
+    /// Token program owning the account
+    pub token_program: Pubkey,
+    /// Raw token amount
+    pub amount: u64,
+    /// Whether the account is frozen
+    pub is_frozen: bool,
+}
+
+/// What is known about a mint a holding is in
+#[derive(Debug, Clone)]
+pub struct MintDetails {
+    /// Mint decimals
+    pub decimals: u8,
+    /// On-chain metadata, when requested and present
+    pub metadata: Option<OnChainMetadata>,
+}
+
+/// Lists the token accounts `owner` holds under `token_program`, parsed from their raw data
+pub fn holdings_by_owner(
+    rpc_client: &RpcClient,
+    owner: &Pubkey,
+    token_program: &Pubkey,
+    commitment: CommitmentConfig,
+) -> Result<Vec<Holding>, String> {
+    // `get_token_accounts_by_owner` asks for jsonParsed data; raw data is parsed here
+    // instead, the same way for both token programs
+    let response: RpcResponse<Vec<RpcKeyedAccount>> = rpc_client
+        .send(
+            RpcRequest::GetTokenAccountsByOwner,
+            json!([
+                owner.to_string(),
+                { "programId": token_program.to_string() },
+                RpcAccountInfoConfig {
+                    encoding: Some(UiAccountEncoding::Base64),
+                    data_slice: None,
+                    commitment: Some(commitment),
+                    min_context_slot: None,
+                },
+            ]),
+        )
+        .map_err(|e: ClientError| format!("Failed to list token accounts: {e}"))?;
+
+    response
+        .value
+        .into_iter()
+        .map(|keyed| {
+            let address = Pubkey::from_str(&keyed.pubkey)
+                .map_err(|e| format!("Invalid token account address: {e}"))?;
+            let account: Account = keyed
+                .account
+                .decode()
+                .ok_or_else(|| format!("Token account {address} could not be decoded"))?;
+            let state = StateWithExtensions::<TokenAccount>::unpack(&account.data)
+                .map_err(|e| format!("Failed to parse token account {address}: {e}"))?;
+            Ok(Holding {
+                address,
+                mint: state.base.mint,
+                token_program: *token_program,
+                amount: state.base.amount,
+                is_frozen: state.base.state == AccountState::Frozen,
+            })
+        })
+        .collect()
+}
+
+/// Lists the token accounts `owner` holds under both token programs
+pub fn all_holdings_by_owner(
+    rpc_client: &RpcClient,
+    owner: &Pubkey,
+    commitment: CommitmentConfig,
+) -> Result<Vec<Holding>, String> {
+    let mut holdings = Vec::new();
+    for token_program in [TOKEN_PROGRAM_ID, spl_token_2022::id()] {
+        holdings.extend(holdings_by_owner(rpc_client, owner, &token_program, commitment)?);
+    }
+    Ok(holdings)
+}
+
+/// Orders holdings by address and returns the page after `page_token` (the address of
+/// the last holding of the previous page), with the token of the page that follows
+pub fn page(
+    mut holdings: Vec<Holding>,
+    page_token: &str,
+    page_size: usize,
+) -> (Vec<Holding>, String) {
+    holdings.sort_by_cached_key(|holding| holding.address.to_string());
+    let start = if page_token.is_empty() {
+        0
+    } else {
+        holdings.partition_point(|holding| holding.address.to_string().as_str() <= page_token)
+    };
+    let end = start.saturating_add(page_size).min(holdings.len());
+    let next_page_token = if end < holdings.len() {
+        holdings[end - 1].address.to_string()
+    } else {
+        String::new()
+    };
+    (holdings.drain(start..end).collect(), next_page_token)
+}
+
+/// Reads the decimals (and, with `include_metadata`, the on-chain metadata) of `mints`
+/// with batched account reads
+pub fn mint_details(
+    rpc_client: &RpcClient,
+    mints: &[Pubkey],
+    commitment: CommitmentConfig,
+    include_metadata: bool,
+) -> Result<HashMap<Pubkey, MintDetails>, String> {
+    let mut details = HashMap::new();
+    let mut metaplex_lookups = Vec::new();
+    for (mint, account) in read_accounts(rpc_client, mints, commitment)? {
+        let Some(account) = account else {
+            continue;
+        };
+        let decimals = StateWithExtensions::<Mint>::unpack(&account.data)
+            .map_err(|e| format!("Failed to parse mint {mint}: {e}"))?
+            .base
+            .decimals;
+        let metadata = if include_metadata && account.owner == spl_token_2022::id() {
+            token_2022_metadata(&mint, &account.data)?
+        } else {
+            None
+        };
+        if include_metadata && metadata.is_none() {
+            metaplex_lookups.push(mint);
+        }
+        details.insert(mint, MintDetails { decimals, metadata });
+    }
+
+    let addresses: Vec<Pubkey> = metaplex_lookups
+        .iter()
+        .map(metaplex_metadata_address)
+        .collect();
+    for (mint, (_, account)) in metaplex_lookups
+        .iter()
+        .zip(read_accounts(rpc_client, &addresses, commitment)?)
+    {
+        if let (Some(account), Some(entry)) = (account, details.get_mut(mint)) {
+            // A malformed metadata account leaves the holding without metadata
+            entry.metadata = parse_metaplex_metadata(mint, &account.data).ok();
+        }
+    }
+    Ok(details)
+}
+
+/// Reads accounts in batches of the `getMultipleAccounts` limit, keeping request order
+fn read_accounts(
+    rpc_client: &RpcClient,
+    pubkeys: &[Pubkey],
+    commitment: CommitmentConfig,
+) -> Result<Vec<(Pubkey, Option<Account>)>, String> {
+    let mut accounts = Vec::with_capacity(pubkeys.len());
+    for batch in pubkeys.chunks(MULTIPLE_ACCOUNTS_LIMIT) {
+        let (_, batch_accounts) = get_multiple_accounts(rpc_client, batch, commitment, None)
+            .map_err(|e| format!("Failed to read accounts: {e}"))?;
+        accounts.extend(batch.iter().copied().zip(batch_accounts));
+    }
+    Ok(accounts)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn holdings(count: usize) -> Vec<Holding> {
+        (0..count)
+            .map(|_| Holding {
+                address: Pubkey::new_unique(),
+                mint: Pubkey::new_unique(),
+                token_program: spl_token_2022::id(),
+                amount: 1,
+                is_frozen: false,
+            })
+            .collect()
+    }
+
+    #[test]
+    fn test_pages_cover_every_holding_once() {
+        let all = holdings(7);
+        let mut seen = Vec::new();
+        let mut token = String::new();
+        loop {
+            let (items, next) = page(all.clone(), &token, 3);
+            assert!(items.len() <= 3);
+            seen.extend(items.into_iter().map(|holding| holding.address.to_string()));
+            if next.is_empty() {
+                break;
+            }
+            token = next;
+        }
+
+        let mut expected: Vec<String> = all.iter().map(|h| h.address.to_string()).collect();
+        expected.sort();
+        assert_eq!(seen, expected);
+    }
+
+    #[test]
+    fn test_exact_final_page_has_no_next_token() {
+        let (first, next) = page(holdings(4), "", 4);
+        assert_eq!(first.len(), 4);
+        assert!(next.is_empty());
+
+        let (empty, next) = page(Vec::new(), "", DEFAULT_PAGE_SIZE);
+        assert!(empty.is_empty());
+        assert!(next.is_empty());
+    }
+}
@@ -1,33 +1,291 @@
+use std::collections::{BTreeMap, BTreeSet};
+use std::path::Path;
 use std::str::FromStr;
 use std::sync::Arc;
+use std::time::Duration;
+use tokio::sync::mpsc;
+use tokio_stream::wrappers::ReceiverStream;
 use tonic::{Request, Response, Status};
+use tracing::{info, warn};
 
 use protochain_api::protochain::solana::account::v1::{
-    service_server::Service as AccountService, Account, FundNativeRequest, FundNativeResponse,
-    GenerateNewKeyPairRequest, GenerateNewKeyPairResponse, GetAccountRequest,
+    service_server::Service as AccountService, Account, AccountDataEncoding, AccountEntry,
+    AccountSnapshot, Cluster, DeriveAssociatedTokenAddressRequest,
+    DeriveAssociatedTokenAddressResponse, DeriveProgramAddressRequest,
+    DeriveProgramAddressResponse, ExportAccountSetRequest, ExportAccountSetResponse,
+    ExportKeyPairRequest, ExportKeyPairResponse, FundNativeRequest, FundNativeResponse,
+    FundingMode, GenerateNewKeyPairRequest, GenerateNewKeyPairResponse, GetAccountDataRequest,
+    GetAccountDataResponse, GetAccountRequest, GetAccountsRequest, GetAccountsResponse,
+    GetBalanceRequest, GetBalanceResponse, GetPortfolioRequest, GetPortfolioResponse,
+    GetTokenBalancesRequest, GetTokenBalancesResponse, ImportAccountSetRequest,
+    ImportAccountSetResponse, ImportKeyPairRequest, ImportKeyPairResponse, MonitorAccountRequest,
+    MonitorAccountResponse, NativeBalance, SecretKeyFormat, TokenHolding, WaitForAccountEvent,
+    WaitForAccountRequest, WaitForAccountResponse,
 };
+use protochain_api::protochain::solana::program::token::v1::OffChainMetadataStatus;
 use protochain_api::protochain::solana::r#type::v1::{CommitmentLevel, KeyPair};
+use protochain_api::protochain::solana::transaction::v1::MonitoringMechanism;
 
+use solana_account_decoder::UiAccountEncoding;
 use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::{RpcAccountInfoConfig, RpcProgramAccountsConfig};
 use solana_sdk::{
     commitment_config::CommitmentConfig,
     pubkey::Pubkey,
-    signature::{Keypair, SeedDerivable, Signer},
+    signature::{Keypair, SeedDerivable, Signature, Signer},
+    system_instruction,
+    transaction::Transaction as SolanaTransaction,
 };
 
+use crate::api::account::v1::data_encoding::{get_encoded_account, resolve_encoding};
+use crate::api::account::v1::data_stream::{
+    resolve_chunk_size, resolve_range, stream_account_data,
+};
+use crate::api::account::v1::derivation::{
+    decode_seeds, derive_associated_token_address, derive_program_address, resolve_token_program,
+};
+use crate::api::account::v1::funding::{
+    cluster_from_genesis_hash, funding_mode, mark_string_amount_deprecated, requested_lamports,
+    wait_for_balance,
+};
+use crate::api::account::v1::key_formats::{decode_key, encode_key};
+use crate::api::account::v1::portfolio::{
+    all_holdings_by_owner, mint_details, page, Holding, MintDetails, DEFAULT_PAGE_SIZE,
+    MAX_PAGE_SIZE,
+};
+use crate::api::account::v1::snapshot::{
+    resolve_max_accounts, validator_account_files, write_account_files, WriteError,
+    SNAPSHOT_BATCH_SIZE,
+};
+use crate::api::common::min_context_slot::{
+    get_account, get_balance, get_multiple_accounts, min_context_slot,
+    min_context_slot_not_reached, read_error_status,
+};
 use crate::api::common::transaction_monitoring::wait_for_transaction_success_by_string;
+use crate::api::program::token::v1::metadata::metadata_to_proto;
+use crate::api::transaction::v1::description::{format_amount, SOL_DECIMALS};
+use crate::service_providers::key_vault::KeyVault;
+use crate::service_providers::keystore::{Keystore, AUDIT_TARGET as KEYSTORE_AUDIT_TARGET};
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter};
+use crate::service_providers::solana_clients::RpcRouter;
+use crate::websocket::{AccountUpdate, PollingSchedule, WebSocketManager};
+
+/// Most addresses a `GetAccounts` request may name, the node's `getMultipleAccounts` limit
+const MAX_GET_ACCOUNTS: usize = 100;
+/// Longest timeout a `MonitorAccount` request may set, in seconds
+const MAX_MONITOR_ACCOUNT_TIMEOUT_SECONDS: u32 = 86_400;
+/// Timeout of a `WaitForAccount` request that does not set one, in seconds
+const DEFAULT_WAIT_FOR_ACCOUNT_TIMEOUT_SECONDS: u32 = 60;
+/// How long `FundNative` waits for a funded balance to become visible
+const FUNDED_BALANCE_TIMEOUT: Duration = Duration::from_secs(30);
 
 #[derive(Clone)]
 /// Core business logic implementation for account management operations
 pub struct AccountServiceImpl {
     /// Solana RPC client for blockchain interactions
     rpc_client: Arc<RpcClient>,
+    /// Key vault holding the funding treasury key
+    key_vault: Arc<KeyVault>,
+    /// Key reference of the funding treasury (empty if none is configured)
+    treasury_key_ref: String,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+    /// Per-commitment routing of account reads
+    rpc_router: Arc<RpcRouter>,
+    /// Encrypted storage for generated keys
+    keystore: Arc<Keystore>,
+    /// WebSocket subscriptions backing account monitoring
+    websocket_manager: Arc<WebSocketManager>,
+    /// Whether `FundNative` refuses the deprecated string amount
+    reject_string_amounts: bool,
+    /// Local validator account directory `ImportAccountSet` writes to (empty disables it)
+    snapshot_import_directory: String,
 }
 
 impl AccountServiceImpl {
-    /// Creates a new `AccountServiceImpl` instance with the provided RPC client
-    pub const fn new(rpc_client: Arc<RpcClient>) -> Self {
-        Self { rpc_client }
+    /// Creates a new `AccountServiceImpl` instance with the provided RPC client, key vault,
+    /// funding treasury key reference, RPC concurrency limiter, read router, keystore,
+    /// WebSocket manager, string amount policy and snapshot import directory
+    #[allow(clippy::too_many_arguments)]
+    pub const fn new(
+        rpc_client: Arc<RpcClient>,
+        key_vault: Arc<KeyVault>,
+        treasury_key_ref: String,
+        rpc_limiter: Arc<RpcLimiter>,
+        rpc_router: Arc<RpcRouter>,
+        keystore: Arc<Keystore>,
+        websocket_manager: Arc<WebSocketManager>,
+        reject_string_amounts: bool,
+        snapshot_import_directory: String,
+    ) -> Self {
+        Self {
+            rpc_client,
+            key_vault,
+            treasury_key_ref,
+            rpc_limiter,
+            rpc_router,
+            keystore,
+            websocket_manager,
+            reject_string_amounts,
+            snapshot_import_directory,
+        }
+    }
+
+    /// Transfers `amount` lamports from the treasury key to `address`
+    #[allow(clippy::result_large_err)]
+    fn treasury_transfer(&self, address: &Pubkey, amount: u64) -> Result<Signature, Status> {
+        let treasury = self
+            .key_vault
+            .resolve(&self.treasury_key_ref)
+            .ok_or_else(|| {
+                Status::failed_precondition(format!(
+                    "Funding treasury key not found in key vault: {}",
+                    self.treasury_key_ref
+                ))
+            })?;
+        let recent_blockhash = self
+            .rpc_client
+            .get_latest_blockhash()
+            .map_err(|e| Status::unavailable(format!("Failed to get latest blockhash: {e}")))?;
+        let transaction = SolanaTransaction::new_signed_with_payer(
+            &[system_instruction::transfer(
+                &treasury.pubkey(),
+                address,
+                amount,
+            )],
+            Some(&treasury.pubkey()),
+            &[treasury.as_ref()],
+            recent_blockhash,
+        );
+        self.rpc_client
+            .send_transaction(&transaction)
+            .map_err(|e| Status::internal(format!("Treasury transfer failed: {e}")))
+    }
+}
+
+/// Relays account subscription updates to a `MonitorAccount` stream until either side
+/// closes; dropping the subscription receiver ends the subscription
+async fn forward_account_updates(
+    address: String,
+    mut updates: mpsc::UnboundedReceiver<AccountUpdate>,
+    grpc_tx: mpsc::Sender<Result<MonitorAccountResponse, Status>>,
+) {
+    while let Some(update) = updates.recv().await {
+        let response = MonitorAccountResponse {
+            address: address.clone(),
+            account: update
+                .account
+                .as_ref()
+                .map(|account| account_to_proto(address.clone(), account)),
+            slot: update.slot,
+            mechanism: update.mechanism.into(),
+            poll_count: update.poll_count,
+        };
+        if grpc_tx.send(Ok(response)).await.is_err() {
+            break; // Client disconnected
+        }
+    }
+}
+
+/// Conditions a `WaitForAccount` stream waits for an account to meet
+struct AccountConditions {
+    /// Least balance the account must hold
+    min_lamports: u64,
+    /// Program that must own the account, if any
+    owner: Option<Pubkey>,
+}
+
+impl AccountConditions {
+    /// Reports whether an account exists and meets the conditions
+    fn met_by(&self, account: Option<&solana_sdk::account::Account>) -> bool {
+        account.is_some_and(|account| {
+            account.lamports >= self.min_lamports
+                && self.owner.is_none_or(|owner| account.owner == owner)
+        })
+    }
+}
+
+/// Relays account subscription updates to a `WaitForAccount` stream until one meets the
+/// conditions, then ends it; if the subscription times out first, a TIMEOUT event ends it.
+/// Returning drops the subscription receiver, which ends the subscription.
+async fn forward_until_account_ready(
+    address: String,
+    conditions: AccountConditions,
+    mut updates: mpsc::UnboundedReceiver<AccountUpdate>,
+    grpc_tx: mpsc::Sender<Result<WaitForAccountResponse, Status>>,
+) {
+    let mut poll_count = 0;
+    while let Some(update) = updates.recv().await {
+        poll_count = update.poll_count;
+        let ready = conditions.met_by(update.account.as_ref());
+        let response = WaitForAccountResponse {
+            event: if ready {
+                WaitForAccountEvent::Ready
+            } else {
+                WaitForAccountEvent::Waiting
+            }
+            .into(),
+            address: address.clone(),
+            account: update
+                .account
+                .as_ref()
+                .map(|account| account_to_proto(address.clone(), account)),
+            slot: update.slot,
+            mechanism: update.mechanism.into(),
+            poll_count,
+        };
+        if grpc_tx.send(Ok(response)).await.is_err() || ready {
+            return; // Client disconnected or the wait is over
+        }
+    }
+
+    let _ = grpc_tx
+        .send(Ok(WaitForAccountResponse {
+            event: WaitForAccountEvent::Timeout.into(),
+            address,
+            account: None,
+            slot: 0,
+            mechanism: MonitoringMechanism::Unspecified.into(),
+            poll_count,
+        }))
+        .await;
+}
+
+/// Converts a Solana account to its proto form
+fn account_to_proto(address: String, account: &solana_sdk::account::Account) -> Account {
+    Account {
+        address,
+        lamports: account.lamports,
+        owner: account.owner.to_string(),
+        executable: account.executable,
+        data: account.data.clone(),
+        rent_epoch: account.rent_epoch,
+        encoding: AccountDataEncoding::Base64.into(),
+        space: account.data.len() as u64,
+        ..Default::default()
+    }
+}
+
+/// Converts a token account and what is known about its mint to its proto form; metadata
+/// is attached as on-chain only
+fn holding_to_proto(holding: &Holding, details: Option<&MintDetails>) -> TokenHolding {
+    let decimals = details.map_or(0, |details| details.decimals);
+    let metadata = details
+        .and_then(|details| details.metadata.clone())
+        .map(|on_chain| {
+            let mut metadata = metadata_to_proto(&holding.mint, on_chain);
+            metadata.off_chain_status = OffChainMetadataStatus::Skipped.into();
+            metadata
+        });
+    TokenHolding {
+        address: holding.address.to_string(),
+        mint: holding.mint.to_string(),
+        token_program: holding.token_program.to_string(),
+        amount: holding.amount.to_string(),
+        decimals: u32::from(decimals),
+        ui_amount: format_amount(holding.amount, u32::from(decimals)),
+        is_frozen: holding.is_frozen,
+        metadata,
     }
 }
 
@@ -47,6 +305,10 @@ fn commitment_level_to_config(commitment_level: i32) -> CommitmentConfig {
 
 #[tonic::async_trait]
 impl AccountService for AccountServiceImpl {
+    type GetAccountDataStream = ReceiverStream<Result<GetAccountDataResponse, Status>>;
+    type MonitorAccountStream = ReceiverStream<Result<MonitorAccountResponse, Status>>;
+    type WaitForAccountStream = ReceiverStream<Result<WaitForAccountResponse, Status>>;
+
     async fn get_account(
         &self,
         request: Request<GetAccountRequest>,
@@ -63,6 +325,7 @@ impl AccountService for AccountServiceImpl {
         // Parse the address
         let pubkey = Pubkey::from_str(&req.address)
             .map_err(|e| Status::invalid_argument(format!("Invalid address format: {e}")))?;
+        let encoding = resolve_encoding(req.encoding).map_err(Status::invalid_argument)?;
 
         // Log account fetch attempt for debugging
         println!("🔍 Attempting to fetch account: {pubkey} via RPC client");
@@ -92,36 +355,57 @@ impl AccountService for AccountServiceImpl {
         let commitment = commitment_level_to_config(req.commitment_level);
 
         // Fetch account from Solana network using our dependency-injected RPC client
-        match self
-            .rpc_client
-            .get_account_with_commitment(&pubkey, commitment)
-        {
+        let _permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        // Raw bytes are decoded from the compressed transfer; other encodings are passed
+        // through as the node sent them
+        let rpc_client = self.rpc_router.for_commitment(commitment);
+        let min_context_slot = min_context_slot(req.min_context_slot);
+        let fetched = match encoding {
+            AccountDataEncoding::Base64Zstd | AccountDataEncoding::JsonParsed => {
+                get_encoded_account(rpc_client, &pubkey, commitment, min_context_slot, encoding)
+            }
+            AccountDataEncoding::Base64 | AccountDataEncoding::Unspecified => {
+                get_account(rpc_client, &pubkey, commitment, min_context_slot).map(|account| {
+                    account.map(|account| account_to_proto(req.address.clone(), &account))
+                })
+            }
+        };
+        match fetched {
             Ok(response) => {
-                if let Some(account) = response.value {
-                    println!("✅ RPC get_account_with_commitment succeeded for: {pubkey}");
-                    println!("💰 Account balance: {} lamports", account.lamports);
-                    // Convert Solana account to our Account type
-                    let account_response = Account {
-                        address: req.address.clone(),
-                        lamports: account.lamports,
-                        owner: account.owner.to_string(),
-                        executable: account.executable,
-                        data: serde_json::to_string(&account.data)
-                            .unwrap_or_else(|_| "Failed to serialize account data".to_string()),
-                        rent_epoch: account.rent_epoch,
-                    };
+                if let Some(mut account_response) = response {
+                    println!("✅ RPC getAccountInfo succeeded for: {pubkey}");
+                    println!("💰 Account balance: {} lamports", account_response.lamports);
+
+                    // Rent exemption is judged against the cluster's current rent
+                    let rent_exempt_minimum = rpc_client
+                        .get_minimum_balance_for_rent_exemption(
+                            usize::try_from(account_response.space).unwrap_or(usize::MAX),
+                        )
+                        .map_err(|e| {
+                            read_error_status(&e, "Failed to fetch rent exemption minimum")
+                        })?;
+                    account_response.rent_exempt_minimum = rent_exempt_minimum;
+                    account_response.is_rent_exempt =
+                        account_response.lamports >= rent_exempt_minimum;
 
                     println!("Successfully fetched account: {}", req.address);
                     Ok(Response::new(account_response))
                 } else {
-                    println!("⚠️ get_account_with_commitment returned None for: {pubkey}");
+                    println!("⚠️ getAccountInfo returned None for: {pubkey}");
                     Err(Status::not_found(format!("Account not found: {}", req.address)))
                 }
             }
             Err(e) => {
                 eprintln!("Error fetching account {}: {}", req.address, e);
                 // Check if it's a not found error
-                if e.to_string().contains("not found") || e.to_string().contains("AccountNotFound")
+                if min_context_slot_not_reached(&e).is_some() {
+                    Err(read_error_status(&e, "Failed to fetch account"))
+                } else if e.to_string().contains("not found")
+                    || e.to_string().contains("AccountNotFound")
                 {
                     Err(Status::not_found(format!("Account not found: {}", req.address)))
                 } else {
@@ -131,6 +415,387 @@ impl AccountService for AccountServiceImpl {
         }
     }
 
+    /// Fetches several accounts in one round trip
+    ///
+    /// All accounts are read with a single `getMultipleAccounts` call, so they reflect the
+    /// same slot. Missing accounts are returned as entries without an account rather than
+    /// failing the request.
+    async fn get_accounts(
+        &self,
+        request: Request<GetAccountsRequest>,
+    ) -> Result<Response<GetAccountsResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.addresses.is_empty() {
+            return Err(Status::invalid_argument("At least one address is required"));
+        }
+        if req.addresses.len() > MAX_GET_ACCOUNTS {
+            return Err(Status::invalid_argument(format!(
+                "At most {MAX_GET_ACCOUNTS} addresses may be requested, got {}",
+                req.addresses.len()
+            )));
+        }
+        let pubkeys = req
+            .addresses
+            .iter()
+            .map(|address| {
+                Pubkey::from_str(address).map_err(|e| {
+                    Status::invalid_argument(format!("Invalid address format {address}: {e}"))
+                })
+            })
+            .collect::<Result<Vec<_>, _>>()?;
+
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let _permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let (slot, accounts) = get_multiple_accounts(
+            self.rpc_router.for_commitment(commitment),
+            &pubkeys,
+            commitment,
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to fetch accounts"))?;
+
+        let accounts = req
+            .addresses
+            .into_iter()
+            .zip(accounts)
+            .map(|(address, account)| AccountEntry {
+                account: account.map(|account| account_to_proto(address.clone(), &account)),
+                address,
+            })
+            .collect();
+
+        Ok(Response::new(GetAccountsResponse { accounts, slot }))
+    }
+
+    /// Summarizes an owner's native and token balances
+    ///
+    /// Token accounts of both token programs are listed with raw account data and parsed
+    /// locally; the mints of the requested page are then read in batches for their decimals
+    /// and, optionally, their on-chain metadata.
+    async fn get_portfolio(
+        &self,
+        request: Request<GetPortfolioRequest>,
+    ) -> Result<Response<GetPortfolioResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.owner.is_empty() {
+            return Err(Status::invalid_argument("Owner address is required"));
+        }
+        let owner = Pubkey::from_str(&req.owner)
+            .map_err(|e| Status::invalid_argument(format!("Invalid owner address: {e}")))?;
+        let page_size = match req.page_size as usize {
+            0 => DEFAULT_PAGE_SIZE,
+            size if size > MAX_PAGE_SIZE => {
+                return Err(Status::invalid_argument(format!(
+                    "page_size must be at most {MAX_PAGE_SIZE}"
+                )))
+            }
+            size => size,
+        };
+
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let rpc_client = self.rpc_router.for_commitment(commitment);
+        let _permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let lamports = rpc_client
+            .get_balance_with_commitment(&owner, commitment)
+            .map_err(|e| Status::internal(format!("Failed to fetch balance: {e}")))?
+            .value;
+        let mut holdings =
+            all_holdings_by_owner(rpc_client, &owner, commitment).map_err(Status::internal)?;
+        if req.hide_zero_balances {
+            holdings.retain(|holding| holding.amount > 0);
+        }
+        let total_holdings = u32::try_from(holdings.len()).unwrap_or(u32::MAX);
+        let (holdings, next_page_token) = page(holdings, &req.page_token, page_size);
+
+        let mut mints: Vec<Pubkey> = holdings.iter().map(|holding| holding.mint).collect();
+        mints.sort_unstable();
+        mints.dedup();
+        let mints = mint_details(rpc_client, &mints, commitment, req.include_metadata)
+            .map_err(Status::internal)?;
+
+        let holdings = holdings
+            .into_iter()
+            .map(|holding| holding_to_proto(&holding, mints.get(&holding.mint)))
+            .collect();
+
+        Ok(Response::new(GetPortfolioResponse {
+            native: Some(NativeBalance {
+                lamports,
+                ui_amount: format_amount(lamports, SOL_DECIMALS),
+            }),
+            holdings,
+            next_page_token,
+            total_holdings,
+        }))
+    }
+
+    /// Returns an address's lamports without reading its data
+    async fn get_balance(
+        &self,
+        request: Request<GetBalanceRequest>,
+    ) -> Result<Response<GetBalanceResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.address.is_empty() {
+            return Err(Status::invalid_argument("Account address is required"));
+        }
+        let pubkey = Pubkey::from_str(&req.address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid address format: {e}")))?;
+
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let _permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let (slot, lamports) = get_balance(
+            self.rpc_router.for_commitment(commitment),
+            &pubkey,
+            commitment,
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to fetch balance"))?;
+
+        Ok(Response::new(GetBalanceResponse {
+            address: req.address,
+            lamports,
+            ui_amount: format_amount(lamports, SOL_DECIMALS),
+            slot,
+        }))
+    }
+
+    /// Lists every token account an owner holds
+    ///
+    /// Both token programs are listed as for `GetPortfolio`, but in one unpaginated response
+    /// and with only the mints' decimals read, so wallet UIs get their token balances in a
+    /// single call.
+    async fn get_token_balances(
+        &self,
+        request: Request<GetTokenBalancesRequest>,
+    ) -> Result<Response<GetTokenBalancesResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.owner.is_empty() {
+            return Err(Status::invalid_argument("Owner address is required"));
+        }
+        let owner = Pubkey::from_str(&req.owner)
+            .map_err(|e| Status::invalid_argument(format!("Invalid owner address: {e}")))?;
+        let mint_filter = if req.mint.is_empty() {
+            None
+        } else {
+            Some(
+                Pubkey::from_str(&req.mint)
+                    .map_err(|e| Status::invalid_argument(format!("Invalid mint address: {e}")))?,
+            )
+        };
+
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let rpc_client = self.rpc_router.for_commitment(commitment);
+        let _permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let mut holdings =
+            all_holdings_by_owner(rpc_client, &owner, commitment).map_err(Status::internal)?;
+        if let Some(mint) = mint_filter {
+            holdings.retain(|holding| holding.mint == mint);
+        }
+        if req.hide_zero_balances {
+            holdings.retain(|holding| holding.amount > 0);
+        }
+        holdings.sort_by_cached_key(|holding| holding.address.to_string());
+
+        let mut mints: Vec<Pubkey> = holdings.iter().map(|holding| holding.mint).collect();
+        mints.sort_unstable();
+        mints.dedup();
+        let mints =
+            mint_details(rpc_client, &mints, commitment, false).map_err(Status::internal)?;
+
+        Ok(Response::new(GetTokenBalancesResponse {
+            balances: holdings
+                .iter()
+                .map(|holding| holding_to_proto(holding, mints.get(&holding.mint)))
+                .collect(),
+        }))
+    }
+
+    /// Streams an account's raw data in chunks
+    ///
+    /// Large accounts (e.g. multi-megabyte program buffers) cannot be returned by
+    /// `GetAccount` without exceeding the gRPC message size limit. The account is read
+    /// once, then the requested byte range is streamed as a header, ordered chunks and a
+    /// trailer with a SHA-256 checksum the client can verify the reassembled bytes against.
+    async fn get_account_data(
+        &self,
+        request: Request<GetAccountDataRequest>,
+    ) -> Result<Response<Self::GetAccountDataStream>, Status> {
+        let req = request.into_inner();
+
+        if req.address.is_empty() {
+            return Err(Status::invalid_argument("Account address is required"));
+        }
+        let pubkey = Pubkey::from_str(&req.address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid address format: {e}")))?;
+        let chunk_size = resolve_chunk_size(req.chunk_size).map_err(Status::invalid_argument)?;
+
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let response = self
+            .rpc_router
+            .for_commitment(commitment)
+            .get_account_with_commitment(&pubkey, commitment)
+            .map_err(|e| Status::internal(format!("Failed to fetch account: {e}")))?;
+        drop(permit);
+        let account = response
+            .value
+            .ok_or_else(|| Status::not_found(format!("Account not found: {}", req.address)))?;
+        let range = resolve_range(account.data.len(), req.offset, req.length)
+            .map_err(Status::out_of_range)?;
+
+        println!(
+            "📦 Streaming {} of {} data bytes for account {pubkey} in chunks of {chunk_size}",
+            range.len(),
+            account.data.len()
+        );
+
+        // A small buffer keeps memory flat: chunks are produced as the client consumes them
+        let (tx, rx) = mpsc::channel(4);
+        tokio::spawn(stream_account_data(
+            req.address,
+            account,
+            response.context.slot,
+            range,
+            chunk_size,
+            tx,
+        ));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+
+    async fn monitor_account(
+        &self,
+        request: Request<MonitorAccountRequest>,
+    ) -> Result<Response<Self::MonitorAccountStream>, Status> {
+        let req = request.into_inner();
+
+        if req.address.is_empty() {
+            return Err(Status::invalid_argument("Account address is required"));
+        }
+        let pubkey = Pubkey::from_str(&req.address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid address format: {e}")))?;
+        if req.timeout_seconds > MAX_MONITOR_ACCOUNT_TIMEOUT_SECONDS {
+            return Err(Status::invalid_argument(format!(
+                "Timeout must be at most {MAX_MONITOR_ACCOUNT_TIMEOUT_SECONDS} seconds"
+            )));
+        }
+        let timeout =
+            (req.timeout_seconds > 0).then(|| Duration::from_secs(u64::from(req.timeout_seconds)));
+        let polling = req
+            .polling
+            .map_or_else(
+                || Ok(PollingSchedule::default()),
+                |polling| {
+                    PollingSchedule::from_request(
+                        polling.initial_interval_ms,
+                        polling.max_interval_ms,
+                        polling.backoff_factor,
+                    )
+                },
+            )
+            .map_err(|e| Status::invalid_argument(format!("Invalid polling config: {e}")))?;
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        println!("👀 Monitoring account {pubkey} at {:?} commitment", commitment.commitment);
+
+        let updates = self
+            .websocket_manager
+            .subscribe_to_account(pubkey, commitment, timeout, polling);
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(forward_account_updates(req.address, updates, tx));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+
+    async fn wait_for_account(
+        &self,
+        request: Request<WaitForAccountRequest>,
+    ) -> Result<Response<Self::WaitForAccountStream>, Status> {
+        let req = request.into_inner();
+
+        if req.address.is_empty() {
+            return Err(Status::invalid_argument("Account address is required"));
+        }
+        let pubkey = Pubkey::from_str(&req.address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid address format: {e}")))?;
+        let owner = if req.owner.is_empty() {
+            None
+        } else {
+            Some(
+                Pubkey::from_str(&req.owner)
+                    .map_err(|e| Status::invalid_argument(format!("Invalid owner format: {e}")))?,
+            )
+        };
+        if req.timeout_seconds > MAX_MONITOR_ACCOUNT_TIMEOUT_SECONDS {
+            return Err(Status::invalid_argument(format!(
+                "Timeout must be at most {MAX_MONITOR_ACCOUNT_TIMEOUT_SECONDS} seconds"
+            )));
+        }
+        let timeout_seconds = if req.timeout_seconds == 0 {
+            DEFAULT_WAIT_FOR_ACCOUNT_TIMEOUT_SECONDS
+        } else {
+            req.timeout_seconds
+        };
+        let polling = req
+            .polling
+            .map_or_else(
+                || Ok(PollingSchedule::default()),
+                |polling| {
+                    PollingSchedule::from_request(
+                        polling.initial_interval_ms,
+                        polling.max_interval_ms,
+                        polling.backoff_factor,
+                    )
+                },
+            )
+            .map_err(|e| Status::invalid_argument(format!("Invalid polling config: {e}")))?;
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        println!(
+            "⏳ Waiting up to {timeout_seconds}s for account {pubkey} at {:?} commitment",
+            commitment.commitment
+        );
+
+        let updates = self.websocket_manager.subscribe_to_account(
+            pubkey,
+            commitment,
+            Some(Duration::from_secs(u64::from(timeout_seconds))),
+            polling,
+        );
+        let conditions = AccountConditions {
+            min_lamports: req.min_lamports,
+            owner,
+        };
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(forward_until_account_ready(req.address, conditions, updates, tx));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+
     async fn generate_new_key_pair(
         &self,
         request: Request<GenerateNewKeyPairRequest>,
@@ -138,6 +803,14 @@ impl AccountService for AccountServiceImpl {
         println!("Received generate keypair request: {request:?}");
 
         let req = request.into_inner();
+        if !req.store && self.keystore.is_required() {
+            return Err(Status::failed_precondition(
+                "This server does not return private keys; set store to keep the key in the keystore",
+            ));
+        }
+        if req.store && !self.keystore.is_enabled() {
+            return Err(Status::failed_precondition("No keystore is configured"));
+        }
 
         // Generate keypair (random or from seed)
         let keypair = if req.seed.is_empty() {
@@ -159,6 +832,23 @@ impl AccountService for AccountServiceImpl {
             })?
         };
 
+        // Stored keys leave only as a handle; the private key never goes over the wire
+        if req.store {
+            let key_handle = self
+                .keystore
+                .store(&keypair)
+                .map_err(|e| Status::internal(format!("Failed to store key: {e}")))?;
+            println!("Stored keypair with public key: {}", keypair.pubkey());
+
+            return Ok(Response::new(GenerateNewKeyPairResponse {
+                key_pair: Some(KeyPair {
+                    public_key: keypair.pubkey().to_string(),
+                    private_key: String::new(),
+                }),
+                key_handle,
+            }));
+        }
+
         // Create protobuf KeyPair with proper field names
         let key_pair = KeyPair {
             public_key: keypair.pubkey().to_string(), // Base58 encoded
@@ -169,6 +859,101 @@ impl AccountService for AccountServiceImpl {
 
         Ok(Response::new(GenerateNewKeyPairResponse {
             key_pair: Some(key_pair),
+            key_handle: String::new(),
+        }))
+    }
+
+    async fn import_key_pair(
+        &self,
+        request: Request<ImportKeyPairRequest>,
+    ) -> Result<Response<ImportKeyPairResponse>, Status> {
+        let req = request.into_inner();
+        if !req.store && self.keystore.is_required() {
+            return Err(Status::failed_precondition(
+                "This server does not return private keys; set store to keep the key in the keystore",
+            ));
+        }
+        if req.store && !self.keystore.is_enabled() {
+            return Err(Status::failed_precondition("No keystore is configured"));
+        }
+        let secret_key = req
+            .secret_key
+            .as_ref()
+            .ok_or_else(|| Status::invalid_argument("A secret key is required"))?;
+        let keypair =
+            decode_key(secret_key, &req.expected_public_key).map_err(Status::invalid_argument)?;
+
+        if req.store {
+            let key_handle = self
+                .keystore
+                .store(&keypair)
+                .map_err(|e| Status::internal(format!("Failed to store key: {e}")))?;
+            info!(public_key = %keypair.pubkey(), "Imported keypair into keystore");
+
+            return Ok(Response::new(ImportKeyPairResponse {
+                key_pair: Some(KeyPair {
+                    public_key: keypair.pubkey().to_string(),
+                    private_key: String::new(),
+                }),
+                key_handle,
+            }));
+        }
+
+        info!(public_key = %keypair.pubkey(), "Imported keypair");
+        Ok(Response::new(ImportKeyPairResponse {
+            key_pair: Some(KeyPair {
+                public_key: keypair.pubkey().to_string(),
+                private_key: encode_key(&keypair, SecretKeyFormat::Base58),
+            }),
+            key_handle: String::new(),
+        }))
+    }
+
+    async fn export_key_pair(
+        &self,
+        request: Request<ExportKeyPairRequest>,
+    ) -> Result<Response<ExportKeyPairResponse>, Status> {
+        let peer = request
+            .remote_addr()
+            .map(|addr| addr.to_string())
+            .unwrap_or_default();
+        let req = request.into_inner();
+        if !self.keystore.is_export_enabled() {
+            warn!(
+                target: KEYSTORE_AUDIT_TARGET,
+                key_handle = %req.key_handle,
+                peer = %peer,
+                outcome = "denied",
+                "Key export denied: export is disabled"
+            );
+            return Err(Status::failed_precondition("This server does not export private keys"));
+        }
+        if !Keystore::is_handle(&req.key_handle) {
+            return Err(Status::invalid_argument(format!(
+                "Invalid key handle: {}",
+                req.key_handle
+            )));
+        }
+        let format = SecretKeyFormat::try_from(req.format)
+            .map_err(|_| Status::invalid_argument(format!("Unknown format: {}", req.format)))?;
+        let keypair = self
+            .keystore
+            .load(&req.key_handle)
+            .map_err(Status::failed_precondition)?
+            .ok_or_else(|| Status::not_found(format!("Key not found: {}", req.key_handle)))?;
+
+        info!(
+            target: KEYSTORE_AUDIT_TARGET,
+            key_handle = %req.key_handle,
+            public_key = %keypair.pubkey(),
+            format = format.as_str_name(),
+            peer = %peer,
+            outcome = "exported",
+            "Key exported"
+        );
+        Ok(Response::new(ExportKeyPairResponse {
+            public_key: keypair.pubkey().to_string(),
+            secret_key: encode_key(&keypair, format),
         }))
     }
 
@@ -188,19 +973,17 @@ impl AccountService for AccountServiceImpl {
             return Err(Status::invalid_argument("Address is required"));
         }
 
-        if req.amount.is_empty() {
-            return Err(Status::invalid_argument("Amount is required"));
-        }
-
         // Parse and validate address
         let address = Pubkey::from_str(&req.address)
             .map_err(|e| Status::invalid_argument(format!("Invalid address: {e}")))?;
 
-        // Parse and validate amount
-        let amount = req
-            .amount
-            .parse::<u64>()
-            .map_err(|e| Status::invalid_argument(format!("Invalid amount: {e}")))?;
+        // Resolve the amount: typed fields first, then the deprecated (strictly parsed) string
+        let (amount, used_string_amount) = requested_lamports(&req, self.reject_string_amounts)?;
+        if used_string_amount {
+            println!(
+                "⚠️  Deprecated: FundNative called with the string amount field; use lamports or native_amount"
+            );
+        }
 
         if amount == 0 {
             return Err(Status::invalid_argument("Amount must be greater than 0"));
@@ -214,17 +997,45 @@ impl AccountService for AccountServiceImpl {
             ));
         }
 
-        // Request airdrop
-        println!("Requesting airdrop of {amount} lamports to {address}");
-        // RPC client ready for airdrop request
-        let signature = self
+        // Airdrop where the cluster allows it, otherwise fall back to the treasury
+        let genesis_hash = self
             .rpc_client
-            .request_airdrop(&address, amount)
-            .map_err(|e| Status::internal(format!("Airdrop request failed: {e}")))?;
+            .get_genesis_hash()
+            .map_err(|e| Status::unavailable(format!("Failed to identify cluster: {e}")))?;
+        let cluster = cluster_from_genesis_hash(&genesis_hash);
+        let treasury_configured = !self.treasury_key_ref.is_empty();
+        let mut mode = funding_mode(cluster, treasury_configured)?;
 
-        // Wait for transaction success validation (not just confirmation)
-        println!("Waiting for airdrop success validation: {signature}");
+        // Read the starting balance so the wait knows what the funded balance will be
         let commitment = commitment_level_to_config(req.commitment_level);
+        let starting_balance = if req.wait_until_visible {
+            self.rpc_client
+                .get_balance_with_commitment(&address, commitment)
+                .map_err(|e| Status::unavailable(format!("Failed to read balance: {e}")))?
+                .value
+        } else {
+            0
+        };
+
+        let signature = if mode == FundingMode::TreasuryTransfer {
+            println!("Transferring {amount} lamports from treasury to {address} on {cluster:?}");
+            self.treasury_transfer(&address, amount)?
+        } else {
+            println!("Requesting airdrop of {amount} lamports to {address}");
+            match self.rpc_client.request_airdrop(&address, amount) {
+                Ok(signature) => signature,
+                // Faucets rate limit (devnet) or are absent (private clusters)
+                Err(e) if treasury_configured => {
+                    println!("⚠️  Airdrop failed ({e}); transferring from treasury instead");
+                    mode = FundingMode::TreasuryTransfer;
+                    self.treasury_transfer(&address, amount)?
+                }
+                Err(e) => return Err(Status::internal(format!("Airdrop request failed: {e}"))),
+            }
+        };
+
+        // Wait for transaction success validation (not just confirmation)
+        println!("Waiting for funding success validation: {signature}");
         wait_for_transaction_success_by_string(
             self.rpc_client.clone(),
             &signature.to_string(),
@@ -233,10 +1044,263 @@ impl AccountService for AccountServiceImpl {
         )
         .await?;
 
-        println!("Airdrop completed successfully: {signature}");
+        // Optionally hold the response until reads at the commitment see the funds
+        let balance = if req.wait_until_visible {
+            let target = starting_balance.saturating_add(amount);
+            let balance = wait_for_balance(
+                &self.rpc_client,
+                &address,
+                target,
+                commitment,
+                FUNDED_BALANCE_TIMEOUT,
+            )
+            .await?;
+            println!("Funded balance visible: {balance} lamports at {address}");
+            balance
+        } else {
+            0
+        };
+
+        println!("Funding completed successfully: {signature}");
 
-        Ok(Response::new(FundNativeResponse {
+        let mut response = Response::new(FundNativeResponse {
             signature: signature.to_string(),
+            funding_mode: mode.into(),
+            cluster: cluster.into(),
+            balance,
+        });
+        if used_string_amount {
+            mark_string_amount_deprecated(&mut response);
+        }
+        Ok(response)
+    }
+
+    async fn derive_program_address(
+        &self,
+        request: Request<DeriveProgramAddressRequest>,
+    ) -> Result<Response<DeriveProgramAddressResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.program_id.is_empty() {
+            return Err(Status::invalid_argument("Program ID is required"));
+        }
+        let program_id = Pubkey::from_str(&req.program_id)
+            .map_err(|e| Status::invalid_argument(format!("Invalid program ID: {e}")))?;
+        let seeds = decode_seeds(&req.seeds).map_err(Status::invalid_argument)?;
+
+        let (address, bump) =
+            derive_program_address(&seeds, &program_id).map_err(Status::invalid_argument)?;
+
+        Ok(Response::new(DeriveProgramAddressResponse {
+            address: address.to_string(),
+            bump: u32::from(bump),
+        }))
+    }
+
+    async fn derive_associated_token_address(
+        &self,
+        request: Request<DeriveAssociatedTokenAddressRequest>,
+    ) -> Result<Response<DeriveAssociatedTokenAddressResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.owner.is_empty() {
+            return Err(Status::invalid_argument("Owner is required"));
+        }
+        if req.mint.is_empty() {
+            return Err(Status::invalid_argument("Mint is required"));
+        }
+        let owner = Pubkey::from_str(&req.owner)
+            .map_err(|e| Status::invalid_argument(format!("Invalid owner format: {e}")))?;
+        let mint = Pubkey::from_str(&req.mint)
+            .map_err(|e| Status::invalid_argument(format!("Invalid mint format: {e}")))?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        let (address, bump) = derive_associated_token_address(&owner, &mint, &token_program);
+
+        Ok(Response::new(DeriveAssociatedTokenAddressResponse {
+            address: address.to_string(),
+            bump: u32::from(bump),
+            token_program_id: token_program.to_string(),
+        }))
+    }
+
+    /// Captures accounts into a portable snapshot
+    ///
+    /// Listed addresses are read in batches with `getMultipleAccounts` and a program's
+    /// accounts with `getProgramAccounts`, all at the requested commitment. Batches may be
+    /// read at different slots; the snapshot reports the latest. Addresses without an
+    /// account are listed as missing rather than failing the export.
+    async fn export_account_set(
+        &self,
+        request: Request<ExportAccountSetRequest>,
+    ) -> Result<Response<ExportAccountSetResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.addresses.is_empty() && req.program_id.is_empty() {
+            return Err(Status::invalid_argument("Addresses or a program ID are required"));
+        }
+        let max_accounts =
+            resolve_max_accounts(req.max_accounts).map_err(Status::invalid_argument)?;
+        let pubkeys = req
+            .addresses
+            .iter()
+            .map(|address| {
+                Pubkey::from_str(address).map_err(|e| {
+                    Status::invalid_argument(format!("Invalid address format {address}: {e}"))
+                })
+            })
+            .collect::<Result<BTreeSet<_>, _>>()?;
+        if pubkeys.len() > max_accounts {
+            return Err(Status::invalid_argument(format!(
+                "{} addresses exceed max_accounts {max_accounts}",
+                pubkeys.len()
+            )));
+        }
+        let program_id = if req.program_id.is_empty() {
+            None
+        } else {
+            Some(
+                Pubkey::from_str(&req.program_id)
+                    .map_err(|e| Status::invalid_argument(format!("Invalid program ID: {e}")))?,
+            )
+        };
+
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let rpc_client = self.rpc_router.for_commitment(commitment);
+        let pubkeys: Vec<Pubkey> = pubkeys.into_iter().collect();
+        let mut accounts = BTreeMap::new();
+        let mut missing_addresses = Vec::new();
+        let mut slot = 0;
+
+        for batch in pubkeys.chunks(SNAPSHOT_BATCH_SIZE) {
+            let _permit = self
+                .rpc_limiter
+                .acquire(RpcCallClass::AccountRead)
+                .await
+                .map_err(Status::resource_exhausted)?;
+            let (batch_slot, batch_accounts) =
+                get_multiple_accounts(rpc_client, batch, commitment, None)
+                    .map_err(|e| read_error_status(&e, "Failed to fetch accounts"))?;
+            slot = slot.max(batch_slot);
+            for (pubkey, account) in batch.iter().zip(batch_accounts) {
+                match account {
+                    Some(account) => {
+                        accounts.insert(*pubkey, account);
+                    }
+                    None => missing_addresses.push(pubkey.to_string()),
+                }
+            }
+        }
+
+        if let Some(program_id) = program_id {
+            let _permit = self
+                .rpc_limiter
+                .acquire(RpcCallClass::AccountRead)
+                .await
+                .map_err(Status::resource_exhausted)?;
+            let program_accounts = rpc_client
+                .get_program_accounts_with_config(
+                    &program_id,
+                    RpcProgramAccountsConfig {
+                        account_config: RpcAccountInfoConfig {
+                            encoding: Some(UiAccountEncoding::Base64Zstd),
+                            commitment: Some(commitment),
+                            ..Default::default()
+                        },
+                        ..Default::default()
+                    },
+                )
+                .map_err(|e| read_error_status(&e, "Failed to fetch program accounts"))?;
+            let program_slot = rpc_client
+                .get_slot_with_commitment(commitment)
+                .map_err(|e| read_error_status(&e, "Failed to fetch slot"))?;
+            slot = slot.max(program_slot);
+            accounts.extend(program_accounts);
+        }
+
+        if accounts.len() > max_accounts {
+            return Err(Status::failed_precondition(format!(
+                "Snapshot would hold {} accounts, more than max_accounts {max_accounts}",
+                accounts.len()
+            )));
+        }
+
+        let genesis_hash = rpc_client
+            .get_genesis_hash()
+            .map_err(|e| Status::unavailable(format!("Failed to identify cluster: {e}")))?;
+
+        println!(
+            "📸 Exported {} accounts ({} missing) at slot {slot}",
+            accounts.len(),
+            missing_addresses.len()
+        );
+
+        Ok(Response::new(ExportAccountSetResponse {
+            snapshot: Some(AccountSnapshot {
+                accounts: accounts
+                    .iter()
+                    .map(|(address, account)| account_to_proto(address.to_string(), account))
+                    .collect(),
+                missing_addresses,
+                slot,
+                genesis_hash: genesis_hash.to_string(),
+                cluster: cluster_from_genesis_hash(&genesis_hash).into(),
+            }),
+        }))
+    }
+
+    /// Writes a snapshot's accounts to the configured local validator account directory
+    ///
+    /// `solana-test-validator` has no RPC to replace accounts while it runs; it loads the
+    /// JSON files of its `--account-dir` when it starts, so the validator must be restarted
+    /// for imported accounts to appear. Imports are refused unless the server is connected
+    /// to a local validator.
+    async fn import_account_set(
+        &self,
+        request: Request<ImportAccountSetRequest>,
+    ) -> Result<Response<ImportAccountSetResponse>, Status> {
+        let req = request.into_inner();
+
+        if self.snapshot_import_directory.is_empty() {
+            return Err(Status::failed_precondition(
+                "ImportAccountSet is disabled: no account_snapshots.import_directory is configured",
+            ));
+        }
+        let snapshot = req
+            .snapshot
+            .ok_or_else(|| Status::invalid_argument("Snapshot is required"))?;
+        if snapshot.accounts.is_empty() {
+            return Err(Status::invalid_argument("Snapshot holds no accounts"));
+        }
+        let files =
+            validator_account_files(&snapshot.accounts).map_err(Status::invalid_argument)?;
+
+        let genesis_hash = self
+            .rpc_client
+            .get_genesis_hash()
+            .map_err(|e| Status::unavailable(format!("Failed to identify cluster: {e}")))?;
+        if cluster_from_genesis_hash(&genesis_hash) != Cluster::Localnet {
+            return Err(Status::failed_precondition(
+                "ImportAccountSet only loads accounts into a local test validator",
+            ));
+        }
+
+        let directory = Path::new(&self.snapshot_import_directory);
+        let files = write_account_files(directory, &files, req.overwrite).map_err(|e| match e {
+            WriteError::Exists(message) => Status::already_exists(message),
+            WriteError::Io(message) => Status::internal(message),
+        })?;
+
+        println!(
+            "📥 Imported {} accounts into {}; restart the validator with --account-dir to load them",
+            files.len(),
+            self.snapshot_import_directory
+        );
+
+        Ok(Response::new(ImportAccountSetResponse {
+            files,
+            account_directory: self.snapshot_import_directory.clone(),
         }))
     }
 }
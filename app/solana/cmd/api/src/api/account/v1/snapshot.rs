@@ -0,0 +1,166 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use protochain_api::protochain::solana::account::v1::{Account, AccountDataEncoding};
+use solana_sdk::pubkey::Pubkey;
+use std::path::Path;
+use std::str::FromStr;
+
+/// Accounts a snapshot may hold when the request sets no limit
+pub const DEFAULT_MAX_SNAPSHOT_ACCOUNTS: usize = 1_000;
+/// Most accounts a snapshot may hold
+pub const MAX_SNAPSHOT_ACCOUNTS: usize = 10_000;
+/// Listed addresses read per `getMultipleAccounts` call
+pub const SNAPSHOT_BATCH_SIZE: usize = 100;
+
+/// Resolves the requested snapshot size limit, 0 selecting the default
+pub fn resolve_max_accounts(max_accounts: u32) -> Result<usize, String> {
+    match usize::try_from(max_accounts) {
+        Ok(0) => Ok(DEFAULT_MAX_SNAPSHOT_ACCOUNTS),
+        Ok(max) if max <= MAX_SNAPSHOT_ACCOUNTS => Ok(max),
+        _ => Err(format!(
+            "max_accounts may be at most {MAX_SNAPSHOT_ACCOUNTS}, got {max_accounts}"
+        )),
+    }
+}
+
+/// Renders an account as the JSON file `solana-test-validator --account-dir` loads, the
+/// format `solana account --output json` writes
+pub fn validator_account_file(account: &Account) -> Result<String, String> {
+    let address = Pubkey::from_str(&account.address)
+        .map_err(|e| format!("Invalid account address {}: {e}", account.address))?;
+    let owner = Pubkey::from_str(&account.owner)
+        .map_err(|e| format!("Invalid owner of account {address}: {e}"))?;
+    match AccountDataEncoding::try_from(account.encoding) {
+        Ok(AccountDataEncoding::Unspecified | AccountDataEncoding::Base64) => {}
+        _ => return Err(format!("Data of account {address} must be BASE64 encoded")),
+    }
+
+    let file = serde_json::json!({
+        "pubkey": address.to_string(),
+        "account": {
+            "lamports": account.lamports,
+            "data": [STANDARD.encode(&account.data), "base64"],
+            "owner": owner.to_string(),
+            "executable": account.executable,
+            "rentEpoch": account.rent_epoch,
+            "space": account.data.len(),
+        },
+    });
+    serde_json::to_string_pretty(&file)
+        .map_err(|e| format!("Failed to encode account {address}: {e}"))
+}
+
+/// Renders every account of a snapshot, failing on the first invalid one so nothing is
+/// written for a bad snapshot. Returns `(file name, contents)` pairs.
+pub fn validator_account_files(accounts: &[Account]) -> Result<Vec<(String, String)>, String> {
+    accounts
+        .iter()
+        .map(|account| {
+            validator_account_file(account)
+                .map(|contents| (format!("{}.json", account.address), contents))
+        })
+        .collect()
+}
+
+/// Why account files could not be written
+#[derive(Debug, PartialEq, Eq)]
+pub enum WriteError {
+    /// A file exists and overwriting was not requested
+    Exists(String),
+    /// The directory or a file could not be written
+    Io(String),
+}
+
+/// Writes account files into `directory`, creating it if needed. Without `overwrite`,
+/// nothing is written if any of the files already exists. Returns the written paths.
+pub fn write_account_files(
+    directory: &Path,
+    files: &[(String, String)],
+    overwrite: bool,
+) -> Result<Vec<String>, WriteError> {
+    std::fs::create_dir_all(directory).map_err(|e| {
+        WriteError::Io(format!("Failed to create account directory {}: {e}", directory.display()))
+    })?;
+
+    if !overwrite {
+        if let Some((name, _)) = files.iter().find(|(name, _)| directory.join(name).exists()) {
+            return Err(WriteError::Exists(format!(
+                "Account file {} already exists; set overwrite to replace it",
+                directory.join(name).display()
+            )));
+        }
+    }
+
+    files
+        .iter()
+        .map(|(name, contents)| {
+            let path = directory.join(name);
+            std::fs::write(&path, contents).map_err(|e| {
+                WriteError::Io(format!("Failed to write account file {}: {e}", path.display()))
+            })?;
+            Ok(path.display().to_string())
+        })
+        .collect()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn account(data: Vec<u8>) -> Account {
+        Account {
+            address: Pubkey::new_unique().to_string(),
+            lamports: 1_000_000,
+            owner: spl_token_2022::id().to_string(),
+            data,
+            rent_epoch: u64::MAX,
+            encoding: AccountDataEncoding::Base64.into(),
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_resolve_max_accounts() {
+        assert_eq!(resolve_max_accounts(0).unwrap(), DEFAULT_MAX_SNAPSHOT_ACCOUNTS);
+        assert_eq!(resolve_max_accounts(5).unwrap(), 5);
+        assert!(resolve_max_accounts(10_001).is_err());
+    }
+
+    #[test]
+    fn test_validator_account_file_matches_cli_format() {
+        let account = account(vec![1, 2, 3]);
+        let file: serde_json::Value =
+            serde_json::from_str(&validator_account_file(&account).unwrap()).unwrap();
+        assert_eq!(file["pubkey"], account.address.as_str());
+        assert_eq!(file["account"]["lamports"], 1_000_000);
+        assert_eq!(file["account"]["data"][0], "AQID");
+        assert_eq!(file["account"]["data"][1], "base64");
+        assert_eq!(file["account"]["owner"], account.owner.as_str());
+        assert_eq!(file["account"]["rentEpoch"], u64::MAX);
+        assert_eq!(file["account"]["space"], 3);
+    }
+
+    #[test]
+    fn test_compressed_or_parsed_data_is_rejected() {
+        let mut account = account(vec![1]);
+        account.encoding = AccountDataEncoding::JsonParsed.into();
+        assert!(validator_account_file(&account).is_err());
+    }
+
+    #[test]
+    fn test_write_refuses_existing_files_without_overwrite() {
+        let directory =
+            std::env::temp_dir().join(format!("protochain-snapshot-{}", Pubkey::new_unique()));
+        let files = validator_account_files(&[account(vec![]), account(vec![7])]).unwrap();
+
+        let written = write_account_files(&directory, &files, false).unwrap();
+        assert_eq!(written.len(), 2);
+        assert!(matches!(
+            write_account_files(&directory, &files, false),
+            Err(WriteError::Exists(_))
+        ));
+        assert_eq!(write_account_files(&directory, &files, true).unwrap(), written);
+
+        std::fs::remove_dir_all(&directory).unwrap();
+    }
+}
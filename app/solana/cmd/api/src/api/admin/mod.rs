@@ -0,0 +1,8 @@
+//! Administrative services
+//!
+//! This module provides operator-facing operations including:
+//! - Capability discovery (server version, active feature flags)
+//! - Runtime feature flag toggles
+//! - Dead-letter inspection and re-queue for failed managed submissions
+
+pub mod v1;
@@ -0,0 +1,26 @@
+use std::sync::Arc;
+
+use super::AdminServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// gRPC service wrapper for administrative operations
+pub struct AdminV1API {
+    /// Core admin service implementation
+    pub admin_service: Arc<AdminServiceImpl>,
+}
+
+impl AdminV1API {
+    /// Creates a new `AdminV1API` instance with the provided service providers
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            admin_service: Arc::new(AdminServiceImpl::new(
+                Arc::clone(&service_providers.feature_flags),
+                Arc::clone(&service_providers.auth),
+                Arc::clone(&service_providers.dead_letters),
+                service_providers.solana_clients.get_rpc_client(),
+                Arc::clone(&service_providers.rpc_limiter),
+                Arc::clone(&service_providers.retention),
+            )),
+        }
+    }
+}
@@ -0,0 +1,12 @@
+//! Admin service v1 API and implementation
+//!
+//! This module contains the gRPC service definition and business logic
+//! for administrative operations.
+
+/// gRPC service wrapper module for admin operations
+pub mod admin_v1_api;
+/// Core business logic implementation module for admin operations
+pub mod service_impl;
+
+pub use admin_v1_api::AdminV1API;
+pub use service_impl::AdminServiceImpl;
@@ -0,0 +1,280 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    commitment_config::CommitmentConfig, transaction::Transaction as SolanaTransaction,
+};
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+use tracing::{info, warn};
+
+use protochain_api::protochain::solana::admin::v1::{
+    service_server::Service as AdminService, FeatureFlag as ProtoFeatureFlag, FeatureFlagSource,
+    GetCapabilitiesRequest, GetCapabilitiesResponse, GetDeadLetterRequest, GetDeadLetterResponse,
+    GetRpcLimitsRequest, GetRpcLimitsResponse, GetStoreStatsRequest, GetStoreStatsResponse,
+    ListDeadLettersRequest, ListDeadLettersResponse, RequeueDeadLetterRequest,
+    RequeueDeadLetterResponse, RpcLimit, SetFeatureFlagRequest, SetFeatureFlagResponse, StoreStats,
+};
+use protochain_api::protochain::solana::r#type::v1::CommitmentLevel;
+
+use crate::api::transaction::v1::submission::{submit_with_retries, RetrySchedule, SendOptions};
+use crate::service_providers::auth::Authenticator;
+use crate::service_providers::dead_letters::DeadLetterStore;
+use crate::service_providers::feature_flags::{FeatureFlag, FeatureFlags, FlagSource, FlagState};
+use crate::service_providers::retention::StoreCollector;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter};
+
+#[derive(Clone)]
+/// Core business logic implementation for administrative operations
+pub struct AdminServiceImpl {
+    /// Shared feature flag registry
+    feature_flags: Arc<FeatureFlags>,
+    /// Credentials required by operator-only RPCs
+    auth: Arc<Authenticator>,
+    /// Dead-letter store for failed managed submissions
+    dead_letters: Arc<DeadLetterStore>,
+    /// RPC client used to re-queue dead-lettered transactions
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+    /// Retention of the in-memory stores, reporting their sizes
+    retention: Arc<StoreCollector>,
+}
+
+impl AdminServiceImpl {
+    /// Creates a new `AdminServiceImpl` instance with the provided feature flag registry,
+    /// authenticator, dead-letter store, RPC client, RPC concurrency limiter and store
+    /// retention
+    pub const fn new(
+        feature_flags: Arc<FeatureFlags>,
+        auth: Arc<Authenticator>,
+        dead_letters: Arc<DeadLetterStore>,
+        rpc_client: Arc<RpcClient>,
+        rpc_limiter: Arc<RpcLimiter>,
+        retention: Arc<StoreCollector>,
+    ) -> Self {
+        Self {
+            feature_flags,
+            auth,
+            dead_letters,
+            rpc_client,
+            rpc_limiter,
+            retention,
+        }
+    }
+}
+
+/// Converts protobuf `CommitmentLevel` to Solana `CommitmentConfig`
+fn commitment_level_to_config(commitment_level: i32) -> CommitmentConfig {
+    match CommitmentLevel::try_from(commitment_level) {
+        Ok(CommitmentLevel::Processed) => CommitmentConfig::processed(),
+        Ok(CommitmentLevel::Finalized) => CommitmentConfig::finalized(),
+        Ok(CommitmentLevel::Confirmed | CommitmentLevel::Unspecified) | Err(_) => {
+            CommitmentConfig::confirmed()
+        }
+    }
+}
+
+/// Converts a flag and its state into the proto representation
+fn feature_flag_to_proto(flag: FeatureFlag, state: FlagState) -> ProtoFeatureFlag {
+    let source = match state.source {
+        FlagSource::Default => FeatureFlagSource::Default,
+        FlagSource::Config => FeatureFlagSource::Config,
+        FlagSource::Runtime => FeatureFlagSource::Runtime,
+    };
+
+    ProtoFeatureFlag {
+        name: flag.name().to_string(),
+        enabled: state.enabled,
+        source: source.into(),
+        description: flag.description().to_string(),
+    }
+}
+
+#[tonic::async_trait]
+impl AdminService for AdminServiceImpl {
+    async fn get_capabilities(
+        &self,
+        _request: Request<GetCapabilitiesRequest>,
+    ) -> Result<Response<GetCapabilitiesResponse>, Status> {
+        let feature_flags = self
+            .feature_flags
+            .snapshot()
+            .into_iter()
+            .map(|(flag, state)| feature_flag_to_proto(flag, state))
+            .collect();
+
+        Ok(Response::new(GetCapabilitiesResponse {
+            version: env!("CARGO_PKG_VERSION").to_string(),
+            feature_flags,
+        }))
+    }
+
+    async fn set_feature_flag(
+        &self,
+        request: Request<SetFeatureFlagRequest>,
+    ) -> Result<Response<SetFeatureFlagResponse>, Status> {
+        self.auth.require_admin(request.metadata())?;
+        let req = request.into_inner();
+
+        if req.name.is_empty() {
+            return Err(Status::invalid_argument("Feature flag name is required"));
+        }
+
+        let flag = FeatureFlag::from_name(&req.name)
+            .ok_or_else(|| Status::not_found(format!("Unknown feature flag: {}", req.name)))?;
+
+        let state = self
+            .feature_flags
+            .set(flag, req.enabled)
+            .map_err(Status::permission_denied)?;
+
+        warn!(flag = flag.name(), enabled = state.enabled, "Feature flag toggled at runtime");
+
+        Ok(Response::new(SetFeatureFlagResponse {
+            feature_flag: Some(feature_flag_to_proto(flag, state)),
+        }))
+    }
+    async fn list_dead_letters(
+        &self,
+        request: Request<ListDeadLettersRequest>,
+    ) -> Result<Response<ListDeadLettersResponse>, Status> {
+        let req = request.into_inner();
+        let fee_payer = (!req.fee_payer.is_empty()).then_some(req.fee_payer.as_str());
+
+        Ok(Response::new(ListDeadLettersResponse {
+            dead_letters: self.dead_letters.list(fee_payer),
+        }))
+    }
+
+    async fn get_dead_letter(
+        &self,
+        request: Request<GetDeadLetterRequest>,
+    ) -> Result<Response<GetDeadLetterResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.id.is_empty() {
+            return Err(Status::invalid_argument("Dead letter id is required"));
+        }
+
+        let dead_letter = self
+            .dead_letters
+            .get(&req.id)
+            .ok_or_else(|| Status::not_found(format!("Dead letter not found: {}", req.id)))?;
+
+        Ok(Response::new(GetDeadLetterResponse {
+            dead_letter: Some(dead_letter),
+        }))
+    }
+
+    async fn requeue_dead_letter(
+        &self,
+        request: Request<RequeueDeadLetterRequest>,
+    ) -> Result<Response<RequeueDeadLetterResponse>, Status> {
+        self.auth.require_admin(request.metadata())?;
+        let req = request.into_inner();
+
+        if req.id.is_empty() {
+            return Err(Status::invalid_argument("Dead letter id is required"));
+        }
+
+        let dead_letter = self
+            .dead_letters
+            .get(&req.id)
+            .ok_or_else(|| Status::not_found(format!("Dead letter not found: {}", req.id)))?;
+        let transaction = dead_letter
+            .transaction
+            .as_ref()
+            .ok_or_else(|| Status::internal("Dead letter has no transaction"))?;
+
+        let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
+            Status::internal(format!("Failed to decode dead-lettered transaction: {e}"))
+        })?;
+        let solana_transaction: SolanaTransaction = bincode::deserialize(&transaction_data)
+            .map_err(|e| {
+                Status::internal(format!("Failed to deserialize dead-lettered transaction: {e}"))
+            })?;
+
+        let schedule = dead_letter
+            .retry_policy
+            .as_ref()
+            .map_or(Ok(RetrySchedule::single_attempt()), RetrySchedule::from_policy)
+            .map_err(|e| Status::internal(format!("Invalid stored retry policy: {e}")))?;
+
+        let permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::Submission)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let send_options =
+            SendOptions::for_commitment(commitment_level_to_config(dead_letter.commitment_level))
+                .with_transaction_config(transaction);
+        let outcome =
+            submit_with_retries(&self.rpc_client, &solana_transaction, send_options, schedule)
+                .await;
+        drop(permit);
+
+        let dead_letter = if outcome.succeeded() {
+            self.dead_letters.remove(&req.id);
+            info!(
+                dead_letter_id = %req.id,
+                signature = %outcome.signature,
+                "Dead-lettered transaction re-queued successfully"
+            );
+            None
+        } else {
+            warn!(
+                dead_letter_id = %req.id,
+                attempts = outcome.attempts.len(),
+                "Dead-lettered transaction failed again on re-queue"
+            );
+            self.dead_letters
+                .record_failed_requeue(&req.id, outcome.attempts.clone())
+        };
+
+        Ok(Response::new(RequeueDeadLetterResponse {
+            signature: outcome.signature,
+            submission_result: outcome.submission_result.into(),
+            structured_error: outcome.structured_error,
+            attempts: outcome.attempts,
+            dead_letter,
+        }))
+    }
+
+    async fn get_rpc_limits(
+        &self,
+        _request: Request<GetRpcLimitsRequest>,
+    ) -> Result<Response<GetRpcLimitsResponse>, Status> {
+        let limits = self
+            .rpc_limiter
+            .stats()
+            .into_iter()
+            .map(|stats| RpcLimit {
+                name: stats.name.to_string(),
+                limit: u32::try_from(stats.limit).unwrap_or(u32::MAX),
+                in_flight: u32::try_from(stats.in_flight).unwrap_or(u32::MAX),
+                queued: stats.queued,
+                saturated_count: stats.saturated,
+                rejected_count: stats.rejected,
+            })
+            .collect();
+
+        Ok(Response::new(GetRpcLimitsResponse { limits }))
+    }
+
+    async fn get_store_stats(
+        &self,
+        _request: Request<GetStoreStatsRequest>,
+    ) -> Result<Response<GetStoreStatsResponse>, Status> {
+        let stores = self
+            .retention
+            .stats()
+            .into_iter()
+            .map(|stats| StoreStats {
+                name: stats.name.to_string(),
+                entries: u64::try_from(stats.entries).unwrap_or(u64::MAX),
+                purged_count: stats.purged,
+            })
+            .collect();
+
+        Ok(Response::new(GetStoreStatsResponse { stores }))
+    }
+}
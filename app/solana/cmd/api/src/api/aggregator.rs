@@ -1,9 +1,15 @@
 use std::sync::Arc;
 
 use super::account::v1::AccountV1API;
+use super::admin::v1::AdminV1API;
+use super::convenience::v1::ConvenienceV1API;
+use super::key_vault::v1::KeyVaultV1API;
+use super::operations::v1::OperationsV1API;
 use super::program::Program;
 use super::rpc_client::RpcClientV1API;
+use super::staking::v1::StakingV1API;
 use super::transaction::v1::TransactionV1API;
+use super::transaction_template::v1::TransactionTemplateV1API;
 use crate::service_providers::ServiceProviders;
 
 /// Main API aggregator that combines all service implementations
@@ -16,16 +22,40 @@ pub struct Api {
     pub program: Arc<Program>,
     /// RPC Client API v1
     pub rpc_client_v1: Arc<RpcClientV1API>,
+    /// Admin API v1
+    pub admin_v1: Arc<AdminV1API>,
+    /// Key vault API v1
+    pub key_vault_v1: Arc<KeyVaultV1API>,
+    /// Convenience builders API v1
+    pub convenience_v1: Arc<ConvenienceV1API>,
+    /// Transaction template API v1
+    pub transaction_template_v1: Arc<TransactionTemplateV1API>,
+    /// Operations API v1
+    pub operations_v1: Arc<OperationsV1API>,
+    /// Staking API v1
+    pub staking_v1: Arc<StakingV1API>,
 }
 
 impl Api {
     /// Creates a new API instance with the provided service providers
     pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        let transaction_v1 = Arc::new(TransactionV1API::new(service_providers));
+        let program = Arc::new(Program::new(service_providers));
         Self {
             account_v1: Arc::new(AccountV1API::new(service_providers)),
-            transaction_v1: Arc::new(TransactionV1API::new(service_providers)),
-            program: Arc::new(Program::new(service_providers)),
+            convenience_v1: Arc::new(ConvenienceV1API::new(
+                service_providers,
+                &transaction_v1.transaction_service,
+                &program.token.token_program_service,
+            )),
+            transaction_v1,
+            program,
             rpc_client_v1: Arc::new(RpcClientV1API::new(service_providers)),
+            admin_v1: Arc::new(AdminV1API::new(service_providers)),
+            key_vault_v1: Arc::new(KeyVaultV1API::new(service_providers)),
+            transaction_template_v1: Arc::new(TransactionTemplateV1API::new(service_providers)),
+            operations_v1: Arc::new(OperationsV1API::new(service_providers)),
+            staking_v1: Arc::new(StakingV1API::new(service_providers)),
         }
     }
 }
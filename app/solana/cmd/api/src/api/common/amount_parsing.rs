@@ -0,0 +1,253 @@
+//! Strict parsing of amounts supplied as strings in requests
+//!
+//! Amounts are ASCII digits with an optional decimal point, and a decimal point is only
+//! accepted when the request carries an explicit decimals context. Signs, whitespace,
+//! grouping separators (`,` `_` `'`), exponents and non-ASCII digits are rejected so that
+//! an amount can never be read differently depending on a client's locale.
+
+use std::collections::HashMap;
+use tonic::{Code, Status};
+use tonic_types::{ErrorDetails, StatusExt};
+
+/// `ErrorInfo` reason attached to every amount parsing failure
+pub const INVALID_AMOUNT_REASON: &str = "INVALID_AMOUNT";
+/// `ErrorInfo` domain for errors raised by this API
+pub const ERROR_DOMAIN: &str = "protochain.solana";
+/// Largest decimals context an amount may be expressed in (`10^19` overflows u64)
+pub const MAX_AMOUNT_DECIMALS: u32 = 19;
+
+/// Rule an amount string broke
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum AmountViolation {
+    /// No characters at all
+    Empty,
+    /// A character other than an ASCII digit or a single decimal point
+    InvalidCharacter,
+    /// A decimal point without a decimals context
+    DecimalNotAllowed,
+    /// A decimal point without digits on both sides, or more than one
+    MalformedDecimal,
+    /// More fractional digits than the decimals context allows
+    TooManyDecimalPlaces,
+    /// The value does not fit in 64 bits once scaled
+    Overflow,
+}
+
+impl AmountViolation {
+    /// Stable identifier reported in error metadata
+    pub const fn as_str(self) -> &'static str {
+        match self {
+            Self::Empty => "EMPTY",
+            Self::InvalidCharacter => "INVALID_CHARACTER",
+            Self::DecimalNotAllowed => "DECIMAL_NOT_ALLOWED",
+            Self::MalformedDecimal => "MALFORMED_DECIMAL",
+            Self::TooManyDecimalPlaces => "TOO_MANY_DECIMAL_PLACES",
+            Self::Overflow => "OVERFLOW",
+        }
+    }
+}
+
+/// Why and where an amount string was rejected
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct AmountError {
+    /// Rule that was broken
+    pub violation: AmountViolation,
+    /// Zero-based character position of the offending character
+    pub position: usize,
+    /// Human-readable description
+    pub message: String,
+}
+
+impl AmountError {
+    fn new(violation: AmountViolation, position: usize, message: String) -> Self {
+        Self {
+            violation,
+            position,
+            message,
+        }
+    }
+
+    /// Converts the error into an `INVALID_ARGUMENT` status carrying `ErrorInfo` and
+    /// `BadRequest` details, so clients can read the violation and position
+    pub fn into_status(self, field: &str) -> Status {
+        let mut details = ErrorDetails::with_error_info(
+            INVALID_AMOUNT_REASON,
+            ERROR_DOMAIN,
+            HashMap::from([
+                ("field".to_string(), field.to_string()),
+                ("violation".to_string(), self.violation.as_str().to_string()),
+                ("position".to_string(), self.position.to_string()),
+            ]),
+        );
+        details.add_bad_request_violation(field, self.message.clone());
+
+        Status::with_error_details(
+            Code::InvalidArgument,
+            format!("{INVALID_AMOUNT_REASON}: invalid {field}: {}", self.message),
+            details,
+        )
+    }
+}
+
+/// Parses `amount` into base units, scaling by `10^decimals`.
+///
+/// With `decimals == 0` only whole base units are accepted. With `decimals > 0` the
+/// amount may carry up to `decimals` fractional digits, e.g. `"1.5"` with 9 decimals is
+/// `1_500_000_000`.
+pub fn parse_amount(amount: &str, decimals: u32) -> Result<u64, AmountError> {
+    if amount.is_empty() {
+        return Err(AmountError::new(AmountViolation::Empty, 0, "amount is required".to_string()));
+    }
+    if decimals > MAX_AMOUNT_DECIMALS {
+        return Err(AmountError::new(
+            AmountViolation::Overflow,
+            0,
+            format!("decimals must not exceed {MAX_AMOUNT_DECIMALS}"),
+        ));
+    }
+
+    let mut decimal_point = None;
+    for (position, c) in amount.chars().enumerate() {
+        match c {
+            '0'..='9' => {}
+            '.' if decimals == 0 => {
+                return Err(AmountError::new(
+                    AmountViolation::DecimalNotAllowed,
+                    position,
+                    format!(
+                        "decimal point at position {position} requires a decimals context; \
+                         send whole base units or set decimals"
+                    ),
+                ));
+            }
+            '.' if decimal_point.is_some() => {
+                return Err(AmountError::new(
+                    AmountViolation::MalformedDecimal,
+                    position,
+                    format!("second decimal point at position {position}"),
+                ));
+            }
+            '.' => decimal_point = Some(position),
+            other => {
+                return Err(AmountError::new(
+                    AmountViolation::InvalidCharacter,
+                    position,
+                    format!(
+                        "invalid character {other:?} at position {position}; only ASCII digits \
+                         and a single '.' are allowed"
+                    ),
+                ));
+            }
+        }
+    }
+
+    // Every character is now ASCII, so byte offsets equal character positions
+    let (whole, fraction) = match decimal_point {
+        Some(position) => (&amount[..position], &amount[position + 1..]),
+        None => (amount, ""),
+    };
+    if let Some(position) = decimal_point {
+        if whole.is_empty() || fraction.is_empty() {
+            return Err(AmountError::new(
+                AmountViolation::MalformedDecimal,
+                position,
+                format!("decimal point at position {position} must have digits on both sides"),
+            ));
+        }
+    }
+
+    let decimals = decimals as usize;
+    if fraction.len() > decimals {
+        let position = whole.len() + 1 + decimals;
+        return Err(AmountError::new(
+            AmountViolation::TooManyDecimalPlaces,
+            position,
+            format!(
+                "at most {decimals} decimal places are allowed, extra digit at position {position}"
+            ),
+        ));
+    }
+
+    let overflow = || {
+        AmountError::new(
+            AmountViolation::Overflow,
+            0,
+            format!("amount {amount} does not fit in 64 bits"),
+        )
+    };
+    let padded = format!("{fraction:0<decimals$}");
+    whole
+        .chars()
+        .chain(padded.chars())
+        .try_fold(0u64, |value, digit| {
+            value
+                .checked_mul(10)?
+                .checked_add(u64::from(digit.to_digit(10)?))
+        })
+        .ok_or_else(overflow)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn violation(amount: &str, decimals: u32) -> (AmountViolation, usize) {
+        let error = parse_amount(amount, decimals).unwrap_err();
+        (error.violation, error.position)
+    }
+
+    #[test]
+    fn test_whole_units() {
+        assert_eq!(parse_amount("0", 0), Ok(0));
+        assert_eq!(parse_amount("1000000000", 0), Ok(1_000_000_000));
+        assert_eq!(parse_amount("18446744073709551615", 0), Ok(u64::MAX));
+    }
+
+    #[test]
+    fn test_decimal_with_context() {
+        assert_eq!(parse_amount("1.5", 9), Ok(1_500_000_000));
+        assert_eq!(parse_amount("0.000000001", 9), Ok(1));
+        assert_eq!(parse_amount("2", 9), Ok(2_000_000_000));
+    }
+
+    #[test]
+    fn test_locale_formats_rejected() {
+        assert_eq!(violation("1,000", 0), (AmountViolation::InvalidCharacter, 1));
+        assert_eq!(violation("1 000", 0), (AmountViolation::InvalidCharacter, 1));
+        assert_eq!(violation("1_000", 0), (AmountViolation::InvalidCharacter, 1));
+        assert_eq!(violation("1,5", 9), (AmountViolation::InvalidCharacter, 1));
+        assert_eq!(violation("-5", 0), (AmountViolation::InvalidCharacter, 0));
+        assert_eq!(violation("1e9", 0), (AmountViolation::InvalidCharacter, 1));
+        assert_eq!(violation("١٢", 0), (AmountViolation::InvalidCharacter, 0));
+    }
+
+    #[test]
+    fn test_decimal_rules() {
+        assert_eq!(violation("", 0), (AmountViolation::Empty, 0));
+        assert_eq!(violation("1.5", 0), (AmountViolation::DecimalNotAllowed, 1));
+        assert_eq!(violation("1.2.3", 9), (AmountViolation::MalformedDecimal, 3));
+        assert_eq!(violation(".5", 9), (AmountViolation::MalformedDecimal, 0));
+        assert_eq!(violation("5.", 9), (AmountViolation::MalformedDecimal, 1));
+        assert_eq!(violation("1.1234", 2), (AmountViolation::TooManyDecimalPlaces, 4));
+    }
+
+    #[test]
+    fn test_overflow() {
+        assert_eq!(violation("18446744073709551616", 0).0, AmountViolation::Overflow);
+        assert_eq!(violation("18446744074", 9).0, AmountViolation::Overflow);
+        assert_eq!(violation("1", MAX_AMOUNT_DECIMALS + 1).0, AmountViolation::Overflow);
+    }
+
+    #[test]
+    fn test_into_status_carries_details() {
+        let status = parse_amount("1,000", 0).unwrap_err().into_status("amount");
+
+        assert_eq!(status.code(), Code::InvalidArgument);
+        assert!(status.message().starts_with(INVALID_AMOUNT_REASON));
+        let info = status.get_details_error_info().unwrap();
+        assert_eq!(info.reason, INVALID_AMOUNT_REASON);
+        assert_eq!(info.metadata.get("position").unwrap(), "1");
+        assert_eq!(info.metadata.get("violation").unwrap(), "INVALID_CHARACTER");
+    }
+}
@@ -0,0 +1,322 @@
+//! Conversion of RPC inner instructions (CPIs) into protobuf messages
+//!
+//! Nodes report inner instructions in one of three shapes: compiled (account indexes into
+//! the transaction's keys), partially decoded (addresses and raw data for programs the
+//! node does not parse) or parsed (a jsonParsed rendering without raw data). The first
+//! two are decoded like top-level instructions; parsed ones keep the node's JSON.
+//!
+//! The node lists a top-level instruction's invocations flat, in execution order, with
+//! the stack height of each. `call_tree` nests them back into the invocation hierarchy.
+
+use protochain_api::protochain::solana::transaction::v1::{
+    DecodedInstruction, InnerInstruction, InnerInstructions, InvocationNode, ProgramKind,
+};
+use solana_sdk::pubkey::Pubkey;
+use solana_transaction_status::{UiInnerInstructions, UiInstruction, UiParsedInstruction};
+use std::str::FromStr;
+
+use crate::api::common::instruction_decoding::{
+    decode_compiled_instruction, decode_instruction, program_kind,
+};
+
+/// Converts the inner instructions reported for a transaction.
+///
+/// `account_keys` must be the full account list compiled instruction indexes refer to:
+/// static keys followed by any addresses loaded from lookup tables.
+pub fn inner_instructions_to_proto(
+    inner_instructions: &[UiInnerInstructions],
+    account_keys: &[Pubkey],
+) -> Vec<InnerInstructions> {
+    inner_instructions
+        .iter()
+        .map(|inner| InnerInstructions {
+            instruction_index: u32::from(inner.index),
+            instructions: inner
+                .instructions
+                .iter()
+                .enumerate()
+                .map(|(index, instruction)| {
+                    inner_instruction_to_proto(
+                        u32::try_from(index).unwrap_or(u32::MAX),
+                        instruction,
+                        account_keys,
+                    )
+                })
+                .collect(),
+        })
+        .collect()
+}
+
+fn inner_instruction_to_proto(
+    index: u32,
+    instruction: &UiInstruction,
+    account_keys: &[Pubkey],
+) -> InnerInstruction {
+    match instruction {
+        UiInstruction::Compiled(compiled) => InnerInstruction {
+            stack_height: compiled.stack_height.unwrap_or_default(),
+            instruction: Some(decode_compiled_instruction(
+                index,
+                account_keys,
+                compiled.program_id_index,
+                &compiled.accounts,
+                &decode_data(&compiled.data),
+            )),
+            parsed_json: String::new(),
+        },
+        UiInstruction::Parsed(UiParsedInstruction::PartiallyDecoded(partial)) => {
+            let data = decode_data(&partial.data);
+            let accounts: Option<Vec<Pubkey>> = partial
+                .accounts
+                .iter()
+                .map(|account| Pubkey::from_str(account).ok())
+                .collect();
+            let instruction = match (Pubkey::from_str(&partial.program_id), accounts) {
+                (Ok(program_id), Some(accounts)) => {
+                    decode_instruction(index, &program_id, &accounts, &data)
+                }
+                // Keep what the node sent if it is not a valid address
+                _ => DecodedInstruction {
+                    index,
+                    program_id: partial.program_id.clone(),
+                    accounts: partial.accounts.clone(),
+                    data,
+                    ..Default::default()
+                },
+            };
+            InnerInstruction {
+                stack_height: partial.stack_height.unwrap_or_default(),
+                instruction: Some(instruction),
+                parsed_json: String::new(),
+            }
+        }
+        UiInstruction::Parsed(UiParsedInstruction::Parsed(parsed)) => {
+            let program = Pubkey::from_str(&parsed.program_id)
+                .map_or(ProgramKind::Unspecified, |program_id| program_kind(&program_id));
+            InnerInstruction {
+                stack_height: parsed.stack_height.unwrap_or_default(),
+                instruction: Some(DecodedInstruction {
+                    index,
+                    program_id: parsed.program_id.clone(),
+                    program: program.into(),
+                    ..Default::default()
+                }),
+                parsed_json: parsed.parsed.to_string(),
+            }
+        }
+    }
+}
+
+/// Builds the invocation tree of a transaction: one root per top-level instruction with
+/// the inner instructions it made nested by stack height.
+///
+/// An inner instruction without a reported stack height is treated as a direct
+/// invocation of the top-level instruction, and one whose height skips a level is nested
+/// under the most recent instruction.
+pub fn call_tree(
+    top_level: &[DecodedInstruction],
+    inner_instructions: &[InnerInstructions],
+) -> Vec<InvocationNode> {
+    top_level
+        .iter()
+        .enumerate()
+        .map(|(index, instruction)| {
+            let root = InvocationNode {
+                stack_height: 1,
+                instruction: Some(instruction.clone()),
+                ..Default::default()
+            };
+            let invocations = inner_instructions
+                .iter()
+                .filter(|inner| usize::try_from(inner.instruction_index).ok() == Some(index))
+                .flat_map(|inner| &inner.instructions);
+
+            // stack[depth] is the open node at stack height depth + 1
+            let mut stack = vec![root];
+            for invocation in invocations {
+                let height = usize::try_from(invocation.stack_height)
+                    .unwrap_or(usize::MAX)
+                    .clamp(2, stack.len() + 1);
+                close_to_depth(&mut stack, height - 1);
+                let invoking_program_id = stack
+                    .last()
+                    .and_then(|parent| parent.instruction.as_ref())
+                    .map(|parent| parent.program_id.clone())
+                    .unwrap_or_default();
+                stack.push(InvocationNode {
+                    stack_height: u32::try_from(height).unwrap_or(u32::MAX),
+                    invoking_program_id,
+                    instruction: invocation.instruction.clone(),
+                    parsed_json: invocation.parsed_json.clone(),
+                    invocations: Vec::new(),
+                });
+            }
+            close_to_depth(&mut stack, 1);
+            stack.pop().unwrap_or_default()
+        })
+        .collect()
+}
+
+/// Pops open nodes into their parents until `depth` remain
+fn close_to_depth(stack: &mut Vec<InvocationNode>, depth: usize) {
+    while stack.len() > depth.max(1) {
+        if let Some(node) = stack.pop() {
+            if let Some(parent) = stack.last_mut() {
+                parent.invocations.push(node);
+            }
+        }
+    }
+}
+
+/// Decodes base58 instruction data, yielding no bytes if the node sent something else
+fn decode_data(data: &str) -> Vec<u8> {
+    bs58::decode(data).into_vec().unwrap_or_default()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_transaction_status::{
+        parse_instruction::ParsedInstruction, UiCompiledInstruction, UiPartiallyDecodedInstruction,
+    };
+
+    #[test]
+    fn test_compiled_instruction_is_decoded() {
+        let from = Pubkey::new_unique();
+        let to = Pubkey::new_unique();
+        let transfer = solana_sdk::system_instruction::transfer(&from, &to, 42);
+        let account_keys = [from, to, solana_sdk::system_program::id()];
+
+        let converted = inner_instructions_to_proto(
+            &[UiInnerInstructions {
+                index: 1,
+                instructions: vec![UiInstruction::Compiled(UiCompiledInstruction {
+                    program_id_index: 2,
+                    accounts: vec![0, 1],
+                    data: bs58::encode(&transfer.data).into_string(),
+                    stack_height: Some(2),
+                })],
+            }],
+            &account_keys,
+        );
+
+        assert_eq!(converted.len(), 1);
+        assert_eq!(converted[0].instruction_index, 1);
+        let inner = &converted[0].instructions[0];
+        assert_eq!(inner.stack_height, 2);
+        let instruction = inner.instruction.as_ref().unwrap();
+        assert_eq!(instruction.program(), ProgramKind::System);
+        assert_eq!(instruction.instruction_type, "Transfer");
+        assert_eq!(instruction.accounts, vec![from.to_string(), to.to_string()]);
+    }
+
+    #[test]
+    fn test_partially_decoded_and_parsed_instructions() {
+        let program_id = Pubkey::new_unique();
+        let partial = UiInstruction::Parsed(UiParsedInstruction::PartiallyDecoded(
+            UiPartiallyDecodedInstruction {
+                program_id: program_id.to_string(),
+                accounts: vec![Pubkey::new_unique().to_string()],
+                data: bs58::encode([1, 2, 3]).into_string(),
+                stack_height: Some(3),
+            },
+        ));
+        let parsed = UiInstruction::Parsed(UiParsedInstruction::Parsed(ParsedInstruction {
+            program: "spl-token".to_string(),
+            program_id: spl_token_2022::id().to_string(),
+            parsed: serde_json::json!({ "type": "transfer" }),
+            stack_height: Some(2),
+        }));
+
+        let converted = inner_instructions_to_proto(
+            &[UiInnerInstructions {
+                index: 0,
+                instructions: vec![partial, parsed],
+            }],
+            &[],
+        );
+
+        let instructions = &converted[0].instructions;
+        let partial = instructions[0].instruction.as_ref().unwrap();
+        assert_eq!(partial.program(), ProgramKind::Unspecified);
+        assert_eq!(partial.data, vec![1, 2, 3]);
+        assert!(instructions[0].parsed_json.is_empty());
+
+        let parsed = instructions[1].instruction.as_ref().unwrap();
+        assert_eq!(parsed.index, 1);
+        assert_eq!(parsed.program(), ProgramKind::Token2022);
+        assert!(parsed.data.is_empty());
+        assert_eq!(instructions[1].parsed_json, r#"{"type":"transfer"}"#);
+    }
+
+    fn invocation(program_id: &str, stack_height: u32) -> InnerInstruction {
+        InnerInstruction {
+            stack_height,
+            instruction: Some(DecodedInstruction {
+                program_id: program_id.to_string(),
+                ..Default::default()
+            }),
+            parsed_json: String::new(),
+        }
+    }
+
+    #[test]
+    fn test_call_tree_nests_by_stack_height() {
+        let top_level = [
+            DecodedInstruction {
+                program_id: "router".to_string(),
+                ..Default::default()
+            },
+            DecodedInstruction {
+                index: 1,
+                program_id: "system".to_string(),
+                ..Default::default()
+            },
+        ];
+        let inner = [InnerInstructions {
+            instruction_index: 0,
+            instructions: vec![
+                invocation("amm", 2),
+                invocation("token", 3),
+                invocation("token", 3),
+                invocation("memo", 2),
+            ],
+        }];
+
+        let tree = call_tree(&top_level, &inner);
+
+        assert_eq!(tree.len(), 2);
+        assert!(tree[1].invocations.is_empty());
+        let router = &tree[0];
+        assert_eq!(router.stack_height, 1);
+        assert!(router.invoking_program_id.is_empty());
+        assert_eq!(router.invocations.len(), 2);
+
+        let amm = &router.invocations[0];
+        assert_eq!((amm.stack_height, amm.invoking_program_id.as_str()), (2, "router"));
+        assert_eq!(amm.invocations.len(), 2);
+        assert_eq!(amm.invocations[1].invoking_program_id, "amm");
+        assert_eq!(amm.invocations[1].stack_height, 3);
+        assert_eq!(router.invocations[1].invoking_program_id, "router");
+    }
+
+    #[test]
+    fn test_call_tree_tolerates_missing_and_skipped_heights() {
+        let top_level = [DecodedInstruction {
+            program_id: "router".to_string(),
+            ..Default::default()
+        }];
+        let inner = [InnerInstructions {
+            instruction_index: 0,
+            instructions: vec![invocation("amm", 0), invocation("token", 5)],
+        }];
+
+        let tree = call_tree(&top_level, &inner);
+
+        let amm = &tree[0].invocations[0];
+        assert_eq!(amm.stack_height, 2);
+        assert_eq!(amm.invocations[0].stack_height, 3);
+        assert_eq!(amm.invocations[0].invoking_program_id, "amm");
+    }
+}
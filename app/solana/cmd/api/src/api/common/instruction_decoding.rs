@@ -0,0 +1,648 @@
+//! Instruction decoding for well-known Solana programs
+//!
+//! This module resolves compiled instructions against a transaction's account keys and
+//! decodes the data of programs the backend understands (System, SPL Token / Token-2022,
+//! Associated Token Account, Compute Budget and Memo) into typed protobuf details.
+//! Instructions for other programs, or data that fails to decode, are still returned
+//! with their program id, accounts and raw bytes.
+
+use protochain_api::protochain::solana::transaction::v1::{
+    decoded_instruction::Details, AssociatedTokenInstructionDetails,
+    ComputeBudgetInstructionDetails, DecodedInstruction, MemoInstructionDetails, ProgramKind,
+    SystemInstructionDetails, TokenInstructionDetails,
+};
+use solana_sdk::{
+    instruction::CompiledInstruction, pubkey, pubkey::Pubkey, system_instruction::SystemInstruction,
+};
+use spl_token_2022::instruction::TokenInstruction;
+
+/// Legacy SPL Token program id
+pub const TOKEN_PROGRAM_ID: Pubkey = pubkey!("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA");
+/// Associated Token Account program id
+pub const ASSOCIATED_TOKEN_PROGRAM_ID: Pubkey =
+    pubkey!("ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL");
+/// SPL Memo program id (v2)
+pub const MEMO_PROGRAM_ID: Pubkey = pubkey!("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr");
+/// SPL Memo program id (v1, still seen in older transactions)
+pub const MEMO_V1_PROGRAM_ID: Pubkey = pubkey!("Memo1UhkJRfHyvLMcVucJwxXeuD728EqVDDwQDxFMNo");
+
+/// Identifies which decoder, if any, applies to a program
+pub fn program_kind(program_id: &Pubkey) -> ProgramKind {
+    if *program_id == solana_sdk::system_program::id() {
+        ProgramKind::System
+    } else if *program_id == TOKEN_PROGRAM_ID {
+        ProgramKind::Token
+    } else if *program_id == spl_token_2022::id() {
+        ProgramKind::Token2022
+    } else if *program_id == ASSOCIATED_TOKEN_PROGRAM_ID {
+        ProgramKind::AssociatedToken
+    } else if *program_id == solana_sdk::compute_budget::id() {
+        ProgramKind::ComputeBudget
+    } else if *program_id == MEMO_PROGRAM_ID || *program_id == MEMO_V1_PROGRAM_ID {
+        ProgramKind::Memo
+    } else {
+        ProgramKind::Unspecified
+    }
+}
+
+/// Decodes every top-level instruction of a compiled message
+///
+/// `account_keys` must be the full account list the instruction indexes refer to:
+/// static keys followed by any addresses loaded from lookup tables. An instruction whose
+/// program or account indexes fall outside that list is returned raw, with unresolved
+/// accounts left empty, rather than decoded against a shifted account list.
+pub fn decode_compiled_instructions(
+    account_keys: &[Pubkey],
+    instructions: &[CompiledInstruction],
+) -> Vec<DecodedInstruction> {
+    instructions
+        .iter()
+        .enumerate()
+        .map(|(index, instruction)| {
+            decode_compiled_instruction(
+                u32::try_from(index).unwrap_or(u32::MAX),
+                account_keys,
+                instruction.program_id_index,
+                &instruction.accounts,
+                &instruction.data,
+            )
+        })
+        .collect()
+}
+
+/// Decodes one compiled instruction given its program and account indexes into
+/// `account_keys`
+pub fn decode_compiled_instruction(
+    index: u32,
+    account_keys: &[Pubkey],
+    program_id_index: u8,
+    account_indexes: &[u8],
+    data: &[u8],
+) -> DecodedInstruction {
+    let program_id = account_keys.get(usize::from(program_id_index)).copied();
+    let accounts: Vec<Option<Pubkey>> = account_indexes
+        .iter()
+        .map(|account_index| account_keys.get(usize::from(*account_index)).copied())
+        .collect();
+
+    match (program_id, accounts.iter().copied().collect::<Option<Vec<_>>>()) {
+        (Some(program_id), Some(accounts)) => {
+            decode_instruction(index, &program_id, &accounts, data)
+        }
+        _ => unresolved_instruction(index, program_id, &accounts, data),
+    }
+}
+
+/// Returns an instruction that references keys outside the account list without decoding it
+fn unresolved_instruction(
+    index: u32,
+    program_id: Option<Pubkey>,
+    accounts: &[Option<Pubkey>],
+    data: &[u8],
+) -> DecodedInstruction {
+    DecodedInstruction {
+        index,
+        program_id: program_id
+            .as_ref()
+            .map(ToString::to_string)
+            .unwrap_or_default(),
+        program: program_id
+            .as_ref()
+            .map_or(ProgramKind::Unspecified, program_kind)
+            .into(),
+        instruction_type: String::new(),
+        accounts: accounts
+            .iter()
+            .map(|account| {
+                account
+                    .as_ref()
+                    .map(ToString::to_string)
+                    .unwrap_or_default()
+            })
+            .collect(),
+        data: data.to_vec(),
+        details: None,
+    }
+}
+
+/// Decodes a single instruction, falling back to raw data for unknown programs
+pub fn decode_instruction(
+    index: u32,
+    program_id: &Pubkey,
+    accounts: &[Pubkey],
+    data: &[u8],
+) -> DecodedInstruction {
+    let program = program_kind(program_id);
+
+    let decoded = match program {
+        ProgramKind::System => decode_system_instruction(accounts, data),
+        ProgramKind::Token | ProgramKind::Token2022 => decode_token_instruction(accounts, data),
+        ProgramKind::AssociatedToken => decode_associated_token_instruction(accounts, data),
+        ProgramKind::ComputeBudget => decode_compute_budget_instruction(data),
+        ProgramKind::Memo => Some(decode_memo_instruction(accounts, data)),
+        ProgramKind::Unspecified => None,
+    };
+
+    let (instruction_type, details) =
+        decoded.map_or((String::new(), None), |(name, details)| (name, Some(details)));
+
+    DecodedInstruction {
+        index,
+        program_id: program_id.to_string(),
+        program: program.into(),
+        instruction_type,
+        accounts: accounts.iter().map(ToString::to_string).collect(),
+        data: data.to_vec(),
+        details,
+    }
+}
+
+/// Returns the base58 account at `position`, or an empty string if absent
+fn account_at(accounts: &[Pubkey], position: usize) -> String {
+    accounts
+        .get(position)
+        .map(ToString::to_string)
+        .unwrap_or_default()
+}
+
+/// Extracts the enum variant name from a `Debug` representation
+fn variant_name(value: &impl std::fmt::Debug) -> String {
+    format!("{value:?}")
+        .split(|c: char| !c.is_alphanumeric() && c != '_')
+        .next()
+        .unwrap_or_default()
+        .to_string()
+}
+
+/// Decodes a System program instruction (bincode encoded)
+fn decode_system_instruction(accounts: &[Pubkey], data: &[u8]) -> Option<(String, Details)> {
+    let instruction: SystemInstruction = bincode::deserialize(data).ok()?;
+    let mut details = SystemInstructionDetails::default();
+
+    match &instruction {
+        SystemInstruction::CreateAccount {
+            lamports,
+            space,
+            owner,
+        } => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.lamports = *lamports;
+            details.space = *space;
+            details.owner = owner.to_string();
+        }
+        SystemInstruction::Assign { owner } => {
+            details.destination = account_at(accounts, 0);
+            details.owner = owner.to_string();
+        }
+        SystemInstruction::Transfer { lamports } => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.lamports = *lamports;
+        }
+        SystemInstruction::CreateAccountWithSeed {
+            base,
+            seed,
+            lamports,
+            space,
+            owner,
+        } => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.base = base.to_string();
+            details.seed.clone_from(seed);
+            details.lamports = *lamports;
+            details.space = *space;
+            details.owner = owner.to_string();
+        }
+        SystemInstruction::AdvanceNonceAccount => {
+            details.destination = account_at(accounts, 0);
+            details.authority = account_at(accounts, 2);
+        }
+        SystemInstruction::WithdrawNonceAccount(lamports) => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.authority = account_at(accounts, 4);
+            details.lamports = *lamports;
+        }
+        SystemInstruction::InitializeNonceAccount(authority) => {
+            details.destination = account_at(accounts, 0);
+            details.new_authority = authority.to_string();
+        }
+        SystemInstruction::AuthorizeNonceAccount(new_authority) => {
+            details.destination = account_at(accounts, 0);
+            details.authority = account_at(accounts, 1);
+            details.new_authority = new_authority.to_string();
+        }
+        SystemInstruction::Allocate { space } => {
+            details.destination = account_at(accounts, 0);
+            details.space = *space;
+        }
+        SystemInstruction::AllocateWithSeed {
+            base,
+            seed,
+            space,
+            owner,
+        } => {
+            details.destination = account_at(accounts, 0);
+            details.base = base.to_string();
+            details.seed.clone_from(seed);
+            details.space = *space;
+            details.owner = owner.to_string();
+        }
+        SystemInstruction::AssignWithSeed { base, seed, owner } => {
+            details.destination = account_at(accounts, 0);
+            details.base = base.to_string();
+            details.seed.clone_from(seed);
+            details.owner = owner.to_string();
+        }
+        SystemInstruction::TransferWithSeed {
+            lamports,
+            from_seed,
+            from_owner,
+        } => {
+            details.source = account_at(accounts, 0);
+            details.base = account_at(accounts, 1);
+            details.destination = account_at(accounts, 2);
+            details.seed.clone_from(from_seed);
+            details.owner = from_owner.to_string();
+            details.lamports = *lamports;
+        }
+        SystemInstruction::UpgradeNonceAccount => {
+            details.destination = account_at(accounts, 0);
+        }
+    }
+
+    Some((variant_name(&instruction), Details::System(details)))
+}
+
+/// Decodes an SPL Token or Token-2022 instruction
+///
+/// Token-2022 shares the legacy token program's instruction layout for the core
+/// instruction set, so one decoder handles both programs.
+fn decode_token_instruction(accounts: &[Pubkey], data: &[u8]) -> Option<(String, Details)> {
+    let instruction = TokenInstruction::unpack(data).ok()?;
+    let mut details = TokenInstructionDetails::default();
+
+    match &instruction {
+        TokenInstruction::InitializeMint {
+            decimals,
+            mint_authority,
+            ..
+        }
+        | TokenInstruction::InitializeMint2 {
+            decimals,
+            mint_authority,
+            ..
+        } => {
+            details.mint = account_at(accounts, 0);
+            details.decimals = Some(u32::from(*decimals));
+            details.new_authority = mint_authority.to_string();
+        }
+        TokenInstruction::InitializeAccount => {
+            details.destination = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.new_authority = account_at(accounts, 2);
+        }
+        TokenInstruction::InitializeAccount2 { owner }
+        | TokenInstruction::InitializeAccount3 { owner } => {
+            details.destination = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.new_authority = owner.to_string();
+        }
+        #[allow(deprecated)]
+        TokenInstruction::Transfer { amount } => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+            details.amount = *amount;
+        }
+        TokenInstruction::TransferChecked { amount, decimals } => {
+            details.source = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.destination = account_at(accounts, 2);
+            details.authority = account_at(accounts, 3);
+            details.amount = *amount;
+            details.decimals = Some(u32::from(*decimals));
+        }
+        TokenInstruction::Approve { amount } => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+            details.amount = *amount;
+        }
+        TokenInstruction::ApproveChecked { amount, decimals } => {
+            details.source = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.destination = account_at(accounts, 2);
+            details.authority = account_at(accounts, 3);
+            details.amount = *amount;
+            details.decimals = Some(u32::from(*decimals));
+        }
+        TokenInstruction::Revoke => {
+            details.source = account_at(accounts, 0);
+            details.authority = account_at(accounts, 1);
+        }
+        TokenInstruction::MintTo { amount } => {
+            details.mint = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+            details.amount = *amount;
+        }
+        TokenInstruction::MintToChecked { amount, decimals } => {
+            details.mint = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+            details.amount = *amount;
+            details.decimals = Some(u32::from(*decimals));
+        }
+        TokenInstruction::Burn { amount } => {
+            details.source = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+            details.amount = *amount;
+        }
+        TokenInstruction::BurnChecked { amount, decimals } => {
+            details.source = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+            details.amount = *amount;
+            details.decimals = Some(u32::from(*decimals));
+        }
+        TokenInstruction::CloseAccount => {
+            details.source = account_at(accounts, 0);
+            details.destination = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+        }
+        TokenInstruction::FreezeAccount | TokenInstruction::ThawAccount => {
+            details.source = account_at(accounts, 0);
+            details.mint = account_at(accounts, 1);
+            details.authority = account_at(accounts, 2);
+        }
+        TokenInstruction::SetAuthority { new_authority, .. } => {
+            details.source = account_at(accounts, 0);
+            details.authority = account_at(accounts, 1);
+            details.new_authority = Option::<Pubkey>::from(*new_authority)
+                .map(|authority| authority.to_string())
+                .unwrap_or_default();
+        }
+        TokenInstruction::SyncNative => {
+            details.source = account_at(accounts, 0);
+        }
+        // Extension instructions are identified by name only
+        _ => {}
+    }
+
+    Some((variant_name(&instruction), Details::Token(details)))
+}
+
+/// Decodes an Associated Token Account program instruction
+///
+/// The program encodes its instruction as a single tag byte, where empty data is the
+/// original `Create` instruction.
+fn decode_associated_token_instruction(
+    accounts: &[Pubkey],
+    data: &[u8],
+) -> Option<(String, Details)> {
+    let name = match data.first() {
+        None | Some(0) => "Create",
+        Some(1) => "CreateIdempotent",
+        Some(2) => "RecoverNested",
+        Some(_) => return None,
+    };
+
+    let details = if name == "RecoverNested" {
+        // [nested ata, nested mint, destination ata, owner ata, owner mint, wallet, token program]
+        AssociatedTokenInstructionDetails {
+            funding_account: String::new(),
+            associated_account: account_at(accounts, 0),
+            wallet: account_at(accounts, 5),
+            mint: account_at(accounts, 1),
+            token_program: account_at(accounts, 6),
+        }
+    } else {
+        // [funding, associated account, wallet, mint, system program, token program]
+        AssociatedTokenInstructionDetails {
+            funding_account: account_at(accounts, 0),
+            associated_account: account_at(accounts, 1),
+            wallet: account_at(accounts, 2),
+            mint: account_at(accounts, 3),
+            token_program: account_at(accounts, 5),
+        }
+    };
+
+    Some((name.to_string(), Details::AssociatedToken(details)))
+}
+
+/// Reads a little-endian u32 at `offset`
+fn read_u32(data: &[u8], offset: usize) -> Option<u32> {
+    data.get(offset..offset + 4)?
+        .try_into()
+        .ok()
+        .map(u32::from_le_bytes)
+}
+
+/// Reads a little-endian u64 at `offset`
+fn read_u64(data: &[u8], offset: usize) -> Option<u64> {
+    data.get(offset..offset + 8)?
+        .try_into()
+        .ok()
+        .map(u64::from_le_bytes)
+}
+
+/// Decodes a Compute Budget program instruction (borsh: tag byte + little-endian payload)
+fn decode_compute_budget_instruction(data: &[u8]) -> Option<(String, Details)> {
+    let mut details = ComputeBudgetInstructionDetails::default();
+
+    let name = match data.first()? {
+        0 => {
+            // Deprecated RequestUnits { units, additional_fee }
+            details.compute_unit_limit = read_u32(data, 1)?;
+            "RequestUnitsDeprecated"
+        }
+        1 => {
+            details.heap_frame_bytes = read_u32(data, 1)?;
+            "RequestHeapFrame"
+        }
+        2 => {
+            details.compute_unit_limit = read_u32(data, 1)?;
+            "SetComputeUnitLimit"
+        }
+        3 => {
+            details.compute_unit_price_micro_lamports = read_u64(data, 1)?;
+            "SetComputeUnitPrice"
+        }
+        4 => {
+            details.loaded_accounts_data_size_limit = read_u32(data, 1)?;
+            "SetLoadedAccountsDataSizeLimit"
+        }
+        _ => return None,
+    };
+
+    Some((name.to_string(), Details::ComputeBudget(details)))
+}
+
+/// Decodes a Memo program instruction; every account passed to the memo program is a signer
+fn decode_memo_instruction(accounts: &[Pubkey], data: &[u8]) -> (String, Details) {
+    (
+        "Memo".to_string(),
+        Details::Memo(MemoInstructionDetails {
+            text: String::from_utf8_lossy(data).into_owned(),
+            signers: accounts.iter().map(ToString::to_string).collect(),
+        }),
+    )
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used, clippy::panic)] // unwrap/panic are acceptable in tests for cleaner assertions
+mod tests {
+    use super::*;
+    use solana_sdk::compute_budget::ComputeBudgetInstruction;
+    use solana_sdk::instruction::Instruction;
+    use solana_sdk::message::Message;
+
+    fn decode(instruction: &Instruction) -> DecodedInstruction {
+        let accounts: Vec<Pubkey> = instruction
+            .accounts
+            .iter()
+            .map(|meta| meta.pubkey)
+            .collect();
+        decode_instruction(0, &instruction.program_id, &accounts, &instruction.data)
+    }
+
+    #[test]
+    fn test_decode_system_transfer() {
+        let from = Pubkey::new_unique();
+        let to = Pubkey::new_unique();
+        let decoded = decode(&solana_sdk::system_instruction::transfer(&from, &to, 42));
+
+        assert_eq!(decoded.program(), ProgramKind::System);
+        assert_eq!(decoded.instruction_type, "Transfer");
+        let Some(Details::System(details)) = decoded.details else {
+            panic!("expected system details");
+        };
+        assert_eq!(details.source, from.to_string());
+        assert_eq!(details.destination, to.to_string());
+        assert_eq!(details.lamports, 42);
+    }
+
+    #[test]
+    fn test_decode_token_transfer_checked() {
+        let source = Pubkey::new_unique();
+        let mint = Pubkey::new_unique();
+        let destination = Pubkey::new_unique();
+        let owner = Pubkey::new_unique();
+        let instruction = spl_token_2022::instruction::transfer_checked(
+            &spl_token_2022::id(),
+            &source,
+            &mint,
+            &destination,
+            &owner,
+            &[],
+            1_000,
+            6,
+        )
+        .unwrap();
+
+        let decoded = decode(&instruction);
+        assert_eq!(decoded.program(), ProgramKind::Token2022);
+        assert_eq!(decoded.instruction_type, "TransferChecked");
+        let Some(Details::Token(details)) = decoded.details else {
+            panic!("expected token details");
+        };
+        assert_eq!(details.source, source.to_string());
+        assert_eq!(details.mint, mint.to_string());
+        assert_eq!(details.destination, destination.to_string());
+        assert_eq!(details.authority, owner.to_string());
+        assert_eq!(details.amount, 1_000);
+        assert_eq!(details.decimals, Some(6));
+    }
+
+    #[test]
+    fn test_decode_compute_budget() {
+        let decoded = decode(&ComputeBudgetInstruction::set_compute_unit_price(5_000));
+        assert_eq!(decoded.program(), ProgramKind::ComputeBudget);
+        assert_eq!(decoded.instruction_type, "SetComputeUnitPrice");
+        let Some(Details::ComputeBudget(details)) = decoded.details else {
+            panic!("expected compute budget details");
+        };
+        assert_eq!(details.compute_unit_price_micro_lamports, 5_000);
+
+        let decoded = decode(&ComputeBudgetInstruction::set_compute_unit_limit(300_000));
+        assert_eq!(decoded.instruction_type, "SetComputeUnitLimit");
+        let Some(Details::ComputeBudget(details)) = decoded.details else {
+            panic!("expected compute budget details");
+        };
+        assert_eq!(details.compute_unit_limit, 300_000);
+    }
+
+    #[test]
+    fn test_decode_memo() {
+        let signer = Pubkey::new_unique();
+        let decoded = decode_instruction(0, &MEMO_PROGRAM_ID, &[signer], b"order-1234");
+
+        assert_eq!(decoded.program(), ProgramKind::Memo);
+        let Some(Details::Memo(details)) = decoded.details else {
+            panic!("expected memo details");
+        };
+        assert_eq!(details.text, "order-1234");
+        assert_eq!(details.signers, vec![signer.to_string()]);
+    }
+
+    #[test]
+    fn test_unknown_program_falls_back_to_raw_data() {
+        let program_id = Pubkey::new_unique();
+        let decoded = decode_instruction(3, &program_id, &[], &[1, 2, 3]);
+
+        assert_eq!(decoded.index, 3);
+        assert_eq!(decoded.program(), ProgramKind::Unspecified);
+        assert_eq!(decoded.program_id, program_id.to_string());
+        assert!(decoded.instruction_type.is_empty());
+        assert!(decoded.details.is_none());
+        assert_eq!(decoded.data, vec![1, 2, 3]);
+    }
+
+    #[test]
+    fn test_undecodable_known_program_data() {
+        let decoded = decode_instruction(0, &solana_sdk::system_program::id(), &[], &[0xff]);
+        assert_eq!(decoded.program(), ProgramKind::System);
+        assert!(decoded.details.is_none());
+    }
+
+    #[test]
+    fn test_decode_compiled_message() {
+        let payer = Pubkey::new_unique();
+        let to = Pubkey::new_unique();
+        let message = Message::new(
+            &[
+                ComputeBudgetInstruction::set_compute_unit_limit(200_000),
+                solana_sdk::system_instruction::transfer(&payer, &to, 7),
+            ],
+            Some(&payer),
+        );
+
+        let decoded = decode_compiled_instructions(&message.account_keys, &message.instructions);
+        assert_eq!(decoded.len(), 2);
+        assert_eq!(decoded[0].instruction_type, "SetComputeUnitLimit");
+        assert_eq!(decoded[1].index, 1);
+        assert_eq!(decoded[1].instruction_type, "Transfer");
+        assert_eq!(decoded[1].accounts, vec![payer.to_string(), to.to_string()]);
+    }
+
+    #[test]
+    fn test_out_of_range_account_index_is_not_decoded() {
+        let payer = Pubkey::new_unique();
+        let to = Pubkey::new_unique();
+        let mut message =
+            Message::new(&[solana_sdk::system_instruction::transfer(&payer, &to, 7)], Some(&payer));
+        message.instructions[0].accounts[1] = 42;
+
+        let decoded = decode_compiled_instructions(&message.account_keys, &message.instructions);
+        assert_eq!(decoded.len(), 1);
+        assert_eq!(decoded[0].program(), ProgramKind::System);
+        assert!(decoded[0].instruction_type.is_empty());
+        assert!(decoded[0].details.is_none());
+        assert_eq!(decoded[0].accounts, vec![payer.to_string(), String::new()]);
+        assert_eq!(decoded[0].data, message.instructions[0].data);
+    }
+}
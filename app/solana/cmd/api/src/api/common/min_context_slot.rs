@@ -0,0 +1,169 @@
+//! Read-after-write freshness through the `minContextSlot` JSON-RPC parameter
+//!
+//! A caller that has just written at some slot passes it as `min_context_slot`; the node
+//! refuses to answer a read from an older bank instead of returning stale state. The
+//! refusal is surfaced as `UNAVAILABLE` so clients retry rather than poll for the change.
+
+use serde_json::json;
+use solana_account_decoder::UiAccountEncoding;
+use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::{RpcAccountInfoConfig, RpcContextConfig};
+use solana_rpc_client_api::{
+    client_error::{Error as ClientError, ErrorKind as ClientErrorKind},
+    request::{RpcError, RpcRequest, RpcResponseErrorData},
+    response::Response as RpcResponse,
+};
+use solana_sdk::{account::Account, commitment_config::CommitmentConfig, pubkey::Pubkey};
+use tonic::Status;
+
+/// Maps the proto field onto the RPC parameter: zero means no minimum
+pub const fn min_context_slot(value: u64) -> Option<u64> {
+    if value == 0 {
+        None
+    } else {
+        Some(value)
+    }
+}
+
+/// Returns the slot the node had reached if `error` is its refusal to serve a read below
+/// the requested minimum context slot
+pub fn min_context_slot_not_reached(error: &ClientError) -> Option<u64> {
+    match error.kind() {
+        ClientErrorKind::RpcError(RpcError::RpcResponseError {
+            data: RpcResponseErrorData::MinContextSlotNotReached { context_slot },
+            ..
+        }) => Some(*context_slot),
+        _ => None,
+    }
+}
+
+/// Converts a failed read into a status, `UNAVAILABLE` when the minimum context slot has
+/// not been reached and `INTERNAL` with `context` otherwise
+pub fn read_error_status(error: &ClientError, context: &str) -> Status {
+    min_context_slot_not_reached(error).map_or_else(
+        || Status::internal(format!("{context}: {error}")),
+        |context_slot| {
+            Status::unavailable(format!(
+                "Node has not reached the minimum context slot yet (at slot {context_slot})"
+            ))
+        },
+    )
+}
+
+/// Reads an account at `commitment` from a bank no older than `min_context_slot`
+pub fn get_account(
+    rpc_client: &RpcClient,
+    pubkey: &Pubkey,
+    commitment: CommitmentConfig,
+    min_context_slot: Option<u64>,
+) -> Result<Option<Account>, ClientError> {
+    rpc_client
+        .get_account_with_config(
+            pubkey,
+            RpcAccountInfoConfig {
+                encoding: Some(UiAccountEncoding::Base64Zstd),
+                data_slice: None,
+                commitment: Some(commitment),
+                min_context_slot,
+            },
+        )
+        .map(|response| response.value)
+}
+
+/// Reads several accounts in one `getMultipleAccounts` call, returning them in the order
+/// of `pubkeys` (`None` for missing accounts) with the slot they were read at
+pub fn get_multiple_accounts(
+    rpc_client: &RpcClient,
+    pubkeys: &[Pubkey],
+    commitment: CommitmentConfig,
+    min_context_slot: Option<u64>,
+) -> Result<(u64, Vec<Option<Account>>), ClientError> {
+    rpc_client
+        .get_multiple_accounts_with_config(
+            pubkeys,
+            RpcAccountInfoConfig {
+                encoding: Some(UiAccountEncoding::Base64Zstd),
+                data_slice: None,
+                commitment: Some(commitment),
+                min_context_slot,
+            },
+        )
+        .map(|response| (response.context.slot, response.value))
+}
+
+/// Reads an address's balance at `commitment` from a bank no older than
+/// `min_context_slot`, with the slot it was read at
+pub fn get_balance(
+    rpc_client: &RpcClient,
+    pubkey: &Pubkey,
+    commitment: CommitmentConfig,
+    min_context_slot: Option<u64>,
+) -> Result<(u64, u64), ClientError> {
+    rpc_client
+        .send::<RpcResponse<u64>>(
+            RpcRequest::GetBalance,
+            json!([
+                pubkey.to_string(),
+                RpcContextConfig {
+                    commitment: Some(commitment),
+                    min_context_slot,
+                },
+            ]),
+        )
+        .map(|response| (response.context.slot, response.value))
+}
+
+/// Fails unless the node's bank at `commitment` has reached `min_context_slot`.
+///
+/// For reads whose JSON-RPC method has no `minContextSlot` parameter (`getTransaction`),
+/// the check is made with `getSlot` first.
+pub fn ensure_min_context_slot(
+    rpc_client: &RpcClient,
+    commitment: CommitmentConfig,
+    min_context_slot: Option<u64>,
+) -> Result<(), ClientError> {
+    if min_context_slot.is_none() {
+        return Ok(());
+    }
+    rpc_client
+        .send::<u64>(
+            RpcRequest::GetSlot,
+            json!([RpcContextConfig {
+                commitment: Some(commitment),
+                min_context_slot,
+            }]),
+        )
+        .map(|_| ())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_zero_means_no_minimum() {
+        assert_eq!(min_context_slot(0), None);
+        assert_eq!(min_context_slot(42), Some(42));
+    }
+
+    #[test]
+    fn test_min_context_slot_refusal_is_unavailable() {
+        let refusal = ClientError::from(RpcError::RpcResponseError {
+            code: -32016,
+            message: "Minimum context slot has not been reached".to_string(),
+            data: RpcResponseErrorData::MinContextSlotNotReached { context_slot: 99 },
+        });
+        assert_eq!(min_context_slot_not_reached(&refusal), Some(99));
+        assert_eq!(
+            read_error_status(&refusal, "Failed to fetch account").code(),
+            tonic::Code::Unavailable
+        );
+
+        let other = ClientError::from(RpcError::ForUser("boom".to_string()));
+        assert_eq!(min_context_slot_not_reached(&other), None);
+        assert_eq!(
+            read_error_status(&other, "Failed to fetch account").code(),
+            tonic::Code::Internal
+        );
+    }
+}
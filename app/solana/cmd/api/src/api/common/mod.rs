@@ -3,6 +3,18 @@
 //! This module provides shared functionality used across different Solana service implementations,
 //! including conversion utilities and transaction monitoring capabilities.
 
+/// Strict, locale-safe parsing of string amounts in requests
+pub mod amount_parsing;
+
+/// Conversion of inner instructions (CPIs) reported by RPC nodes
+pub mod inner_instructions;
+
+/// Instruction decoding for well-known Solana programs
+pub mod instruction_decoding;
+
+/// Read-after-write freshness through the `minContextSlot` RPC parameter
+pub mod min_context_slot;
+
 /// Conversion utilities between Solana SDK types and protobuf messages
 pub mod solana_conversions;
 
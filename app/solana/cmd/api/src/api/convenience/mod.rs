@@ -0,0 +1,8 @@
+//! Convenience services
+//!
+//! This module provides one-call builders for the most common transactions:
+//! - SOL transfers with an optional memo
+//! - Token 2022 transfers between associated token accounts
+//! - Rent-exempt account creation
+
+pub mod v1;
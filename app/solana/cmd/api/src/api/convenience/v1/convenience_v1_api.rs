@@ -0,0 +1,30 @@
+use std::sync::Arc;
+
+use super::ConvenienceServiceImpl;
+use crate::api::program::token::v1::service_impl::TokenProgramServiceImpl;
+use crate::api::transaction::v1::TransactionServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// gRPC service wrapper for convenience builders
+pub struct ConvenienceV1API {
+    /// Core convenience service implementation
+    pub convenience_service: Arc<ConvenienceServiceImpl>,
+}
+
+impl ConvenienceV1API {
+    /// Creates a new `ConvenienceV1API` instance that compiles and estimates through the
+    /// given transaction service and builds token transfers with the token program service
+    pub fn new(
+        service_providers: &Arc<ServiceProviders>,
+        transaction_service: &Arc<TransactionServiceImpl>,
+        token_program_service: &Arc<TokenProgramServiceImpl>,
+    ) -> Self {
+        Self {
+            convenience_service: Arc::new(ConvenienceServiceImpl::new(
+                service_providers.solana_clients.get_rpc_client(),
+                Arc::clone(transaction_service),
+                Arc::clone(token_program_service),
+            )),
+        }
+    }
+}
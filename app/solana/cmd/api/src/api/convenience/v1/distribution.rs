@@ -0,0 +1,304 @@
+use solana_sdk::{instruction::Instruction, pubkey::Pubkey};
+use spl_token_2022::instruction::transfer_checked;
+use std::collections::HashSet;
+
+use crate::api::account::v1::derivation::derive_associated_token_address;
+use crate::api::program::ata::v1::instructions::create as create_associated_account;
+use crate::api::transaction::v1::splitting::{split_instructions, SplitOptions};
+
+/// Most recipients one distribution may pay
+pub const MAX_DISTRIBUTION_RECIPIENTS: usize = 10_000;
+/// Compute units budgeted for creating an associated token account
+pub const CREATE_ACCOUNT_COMPUTE_UNITS: u64 = 30_000;
+/// Compute units budgeted for a `TransferChecked`
+pub const TRANSFER_COMPUTE_UNITS: u64 = 10_000;
+
+/// A recipient of a distribution
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Recipient {
+    /// Wallet receiving the tokens
+    pub wallet: Pubkey,
+    /// Base units to send
+    pub amount: u64,
+    /// The wallet's associated token account for the mint
+    pub token_account: Pubkey,
+    /// Whether the associated token account must be created first
+    pub create_account: bool,
+}
+
+impl Recipient {
+    /// Creates a recipient of `amount` base units of `mint`, owned by `token_program`
+    pub fn new(wallet: Pubkey, amount: u64, mint: &Pubkey, token_program: &Pubkey) -> Self {
+        Self {
+            wallet,
+            amount,
+            token_account: derive_associated_token_address(&wallet, mint, token_program).0,
+            create_account: false,
+        }
+    }
+}
+
+/// One transaction of a distribution
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct DistributionBatch {
+    /// Indices of the recipients the transaction pays
+    pub recipients: Vec<usize>,
+    /// Account creations and transfers, in order
+    pub instructions: Vec<Instruction>,
+}
+
+/// Accounts and amounts a distribution moves tokens between
+pub struct PackedDistribution {
+    /// Funding authority's associated token account
+    pub source: Pubkey,
+    /// Base units leaving the source account
+    pub total_amount: u64,
+    /// Transactions in sending order
+    pub batches: Vec<DistributionBatch>,
+}
+
+/// Rejects empty, oversized and duplicate recipient lists
+pub fn validate_recipients(recipients: &[Recipient]) -> Result<(), String> {
+    if recipients.is_empty() {
+        return Err("At least one recipient is required".to_string());
+    }
+    if recipients.len() > MAX_DISTRIBUTION_RECIPIENTS {
+        return Err(format!(
+            "At most {MAX_DISTRIBUTION_RECIPIENTS} recipients may be paid at once, got {}",
+            recipients.len()
+        ));
+    }
+    let mut seen = HashSet::with_capacity(recipients.len());
+    for recipient in recipients {
+        if !seen.insert(recipient.wallet) {
+            return Err(format!("Duplicate recipient {}", recipient.wallet));
+        }
+    }
+    Ok(())
+}
+
+/// Packs the transfers into as few transactions as fit, keeping each recipient's account
+/// creation and transfer in the same transaction
+pub fn plan_distribution(
+    mint: &Pubkey,
+    token_program: &Pubkey,
+    decimals: u8,
+    funding_authority: &Pubkey,
+    fee_payer: &Pubkey,
+    recipients: &[Recipient],
+) -> Result<PackedDistribution, String> {
+    validate_recipients(recipients)?;
+    let (source, _) = derive_associated_token_address(funding_authority, mint, token_program);
+    let total_amount = recipients
+        .iter()
+        .try_fold(0u64, |total, recipient| total.checked_add(recipient.amount))
+        .ok_or_else(|| "Total distribution amount overflows".to_string())?;
+
+    let mut instructions = Vec::with_capacity(recipients.len() * 2);
+    let mut owners = Vec::with_capacity(recipients.len() * 2);
+    let mut atomic_ranges = Vec::new();
+    let mut compute_units = Vec::with_capacity(recipients.len() * 2);
+    for (index, recipient) in recipients.iter().enumerate() {
+        if recipient.token_account == source {
+            return Err(format!("Recipient {} is the funding authority", recipient.wallet));
+        }
+        let start = instructions.len();
+        if recipient.create_account {
+            instructions.push(create_associated_account(
+                fee_payer,
+                &recipient.wallet,
+                mint,
+                token_program,
+                true,
+            ));
+            owners.push(index);
+            compute_units.push(CREATE_ACCOUNT_COMPUTE_UNITS);
+        }
+        instructions.push(
+            transfer_checked(
+                token_program,
+                &source,
+                mint,
+                &recipient.token_account,
+                funding_authority,
+                &[],
+                recipient.amount,
+                decimals,
+            )
+            .map_err(|e| format!("Failed to create TransferChecked instruction: {e}"))?,
+        );
+        owners.push(index);
+        compute_units.push(TRANSFER_COMPUTE_UNITS);
+        if instructions.len() - start > 1 {
+            atomic_ranges.push(start..instructions.len());
+        }
+    }
+
+    let options = SplitOptions {
+        atomic_ranges,
+        compute_units,
+        ..Default::default()
+    };
+    let batches = split_instructions(&instructions, fee_payer, &options)?
+        .into_iter()
+        .map(|batch| {
+            let mut batch_recipients: Vec<usize> = owners[batch.instructions.clone()].to_vec();
+            batch_recipients.dedup();
+            DistributionBatch {
+                recipients: batch_recipients,
+                instructions: instructions[batch.instructions].to_vec(),
+            }
+        })
+        .collect();
+
+    Ok(PackedDistribution {
+        source,
+        total_amount,
+        batches,
+    })
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+    use crate::api::transaction::v1::diagnostics::{diagnose_instructions, MAX_TRANSACTION_SIZE};
+    use spl_token_2022::ID as TOKEN_2022_PROGRAM_ID;
+
+    fn recipients(count: usize, mint: &Pubkey, create_every: usize) -> Vec<Recipient> {
+        (0..count)
+            .map(|index| {
+                let mut recipient =
+                    Recipient::new(Pubkey::new_unique(), 1_000, mint, &TOKEN_2022_PROGRAM_ID);
+                recipient.create_account = create_every > 0 && index % create_every == 0;
+                recipient
+            })
+            .collect()
+    }
+
+    #[test]
+    fn test_every_recipient_is_paid_once_in_order() {
+        let mint = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let recipients = recipients(60, &mint, 3);
+
+        let plan = plan_distribution(
+            &mint,
+            &TOKEN_2022_PROGRAM_ID,
+            6,
+            &authority,
+            &authority,
+            &recipients,
+        )
+        .unwrap();
+        assert_eq!(plan.total_amount, 60_000);
+        assert_eq!(
+            plan.source,
+            derive_associated_token_address(&authority, &mint, &TOKEN_2022_PROGRAM_ID).0
+        );
+        assert!(plan.batches.len() > 1);
+
+        let paid: Vec<usize> = plan
+            .batches
+            .iter()
+            .flat_map(|batch| batch.recipients.clone())
+            .collect();
+        assert_eq!(paid, (0..60).collect::<Vec<_>>());
+        for batch in &plan.batches {
+            let report = diagnose_instructions(&batch.instructions, &authority);
+            assert!(report.serialized_size <= MAX_TRANSACTION_SIZE);
+        }
+    }
+
+    #[test]
+    fn test_account_creation_shares_the_transfer_transaction() {
+        let mint = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let recipients = recipients(40, &mint, 1);
+
+        let plan = plan_distribution(
+            &mint,
+            &TOKEN_2022_PROGRAM_ID,
+            6,
+            &authority,
+            &authority,
+            &recipients,
+        )
+        .unwrap();
+        for batch in &plan.batches {
+            assert_eq!(batch.instructions.len(), batch.recipients.len() * 2);
+            for pair in batch.instructions.chunks(2) {
+                assert_ne!(pair[0].program_id, TOKEN_2022_PROGRAM_ID);
+                assert_eq!(pair[1].program_id, TOKEN_2022_PROGRAM_ID);
+                assert_eq!(pair[0].accounts[1].pubkey, pair[1].accounts[2].pubkey);
+            }
+        }
+    }
+
+    #[test]
+    fn test_legacy_mints_use_the_spl_token_program() {
+        let mint = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let mut recipient = Recipient::new(Pubkey::new_unique(), 5, &mint, &TOKEN_PROGRAM_ID);
+        recipient.create_account = true;
+        assert_eq!(
+            recipient.token_account,
+            derive_associated_token_address(&recipient.wallet, &mint, &TOKEN_PROGRAM_ID).0
+        );
+
+        let plan =
+            plan_distribution(&mint, &TOKEN_PROGRAM_ID, 6, &authority, &authority, &[recipient])
+                .unwrap();
+        let instructions = &plan.batches[0].instructions;
+        assert_eq!(instructions[0].accounts[5].pubkey, TOKEN_PROGRAM_ID);
+        assert_eq!(instructions[1].program_id, TOKEN_PROGRAM_ID);
+        assert_eq!(
+            plan.source,
+            derive_associated_token_address(&authority, &mint, &TOKEN_PROGRAM_ID).0
+        );
+    }
+
+    #[test]
+    fn test_invalid_recipient_lists_are_rejected() {
+        let mint = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        assert!(plan_distribution(&mint, &TOKEN_2022_PROGRAM_ID, 6, &authority, &authority, &[])
+            .is_err());
+
+        let mut duplicated = recipients(2, &mint, 0);
+        duplicated[1] = duplicated[0].clone();
+        assert!(plan_distribution(
+            &mint,
+            &TOKEN_2022_PROGRAM_ID,
+            6,
+            &authority,
+            &authority,
+            &duplicated
+        )
+        .is_err());
+
+        let to_self = vec![Recipient::new(authority, 1, &mint, &TOKEN_2022_PROGRAM_ID)];
+        assert!(plan_distribution(
+            &mint,
+            &TOKEN_2022_PROGRAM_ID,
+            6,
+            &authority,
+            &authority,
+            &to_self
+        )
+        .is_err());
+
+        let mut overflowing = recipients(2, &mint, 0);
+        overflowing[0].amount = u64::MAX;
+        assert!(plan_distribution(
+            &mint,
+            &TOKEN_2022_PROGRAM_ID,
+            6,
+            &authority,
+            &authority,
+            &overflowing
+        )
+        .is_err());
+    }
+}
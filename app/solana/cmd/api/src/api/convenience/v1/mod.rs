@@ -0,0 +1,14 @@
+//! Convenience service v1 API and implementation
+//!
+//! This module contains the gRPC service definition and business logic
+//! for building ready-to-sign common transactions in one call.
+
+/// gRPC service wrapper module for convenience builders
+pub mod convenience_v1_api;
+/// Token distribution planning: recipients packed into transactions
+pub mod distribution;
+/// Core business logic implementation module for convenience builders
+pub mod service_impl;
+
+pub use convenience_v1_api::ConvenienceV1API;
+pub use service_impl::ConvenienceServiceImpl;
@@ -0,0 +1,573 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    instruction::Instruction, message::Message, pubkey::Pubkey, system_instruction, system_program,
+};
+use spl_token_2022::ID as TOKEN_2022_PROGRAM_ID;
+use spl_token_2022::{
+    extension::StateWithExtensions,
+    state::{Account, Mint},
+};
+use std::str::FromStr;
+use std::sync::Arc;
+use tokio::sync::mpsc;
+use tokio_stream::wrappers::ReceiverStream;
+use tonic::{Request, Response, Status};
+use tracing::{info, warn};
+
+use protochain_api::protochain::solana::convenience::v1::{
+    distribute_tokens_request::SigningMethod as DistributionSigningMethod,
+    distribute_tokens_response::Event, service_server::Service as ConvenienceService,
+    BuildAccountCreateRequest, BuildSolTransferRequest, BuildTokenTransferRequest,
+    BuildTransactionResponse, DistributeTokensRequest, DistributeTokensResponse, DistributionPlan,
+    DistributionRecipientResult, DistributionRecipientStatus, DistributionSummary,
+};
+use protochain_api::protochain::solana::program::token::v1::{
+    service_server::Service as TokenProgramService,
+    BuildTokenTransferRequest as TokenTransferRequest,
+};
+use protochain_api::protochain::solana::transaction::v1::{
+    service_server::Service as TransactionService, sign_transaction_request::SigningMethod,
+    CompileTransactionRequest, EstimateTransactionRequest, SignTransactionRequest,
+    SubmissionResult, SubmitTransactionRequest, Transaction, TransactionState,
+};
+
+use super::distribution::{plan_distribution, validate_recipients, PackedDistribution, Recipient};
+use crate::api::common::amount_parsing::parse_amount;
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+use crate::api::common::min_context_slot::{get_account, get_multiple_accounts, read_error_status};
+use crate::api::common::solana_conversions::{proto_instruction_to_sdk, sdk_instruction_to_proto};
+use crate::api::common::transaction_monitoring::wait_for_transaction_success_by_string;
+use crate::api::program::token::v1::service_impl::TokenProgramServiceImpl;
+use crate::api::transaction::v1::diagnostics::decode_data;
+use crate::api::transaction::v1::memo::signed_memo_instruction;
+use crate::api::transaction::v1::service_impl::commitment_level_to_config;
+use crate::api::transaction::v1::TransactionServiceImpl;
+
+/// Convenience service implementation that builds common transactions in one call
+#[derive(Clone)]
+pub struct ConvenienceServiceImpl {
+    /// Solana RPC client for rent lookups
+    rpc_client: Arc<RpcClient>,
+    /// Transaction service used to compile and estimate built transactions
+    transaction_service: Arc<TransactionServiceImpl>,
+    /// Token program service that builds token transfers
+    token_program_service: Arc<TokenProgramServiceImpl>,
+}
+
+impl ConvenienceServiceImpl {
+    /// Creates a new `ConvenienceServiceImpl` with the provided RPC client, the
+    /// transaction service it compiles and estimates through and the token program
+    /// service it builds token transfers with
+    pub const fn new(
+        rpc_client: Arc<RpcClient>,
+        transaction_service: Arc<TransactionServiceImpl>,
+        token_program_service: Arc<TokenProgramServiceImpl>,
+    ) -> Self {
+        Self {
+            rpc_client,
+            transaction_service,
+            token_program_service,
+        }
+    }
+
+    /// Compiles `instructions` with `fee_payer` against a fresh blockhash
+    async fn compile(
+        &self,
+        instructions: Vec<Instruction>,
+        fee_payer: &Pubkey,
+    ) -> Result<Transaction, Status> {
+        let draft = Transaction {
+            instructions: instructions
+                .into_iter()
+                .map(sdk_instruction_to_proto)
+                .collect(),
+            state: TransactionState::Draft.into(),
+            ..Default::default()
+        };
+
+        self.transaction_service
+            .compile_transaction(Request::new(CompileTransactionRequest {
+                transaction: Some(draft),
+                fee_payer: fee_payer.to_string(),
+                ..Default::default()
+            }))
+            .await?
+            .into_inner()
+            .transaction
+            .ok_or_else(|| Status::internal("Compilation returned no transaction"))
+    }
+
+    /// Compiles `instructions` with `fee_payer` against a fresh blockhash and estimates
+    /// the resulting transaction
+    async fn build(
+        &self,
+        instructions: Vec<Instruction>,
+        fee_payer: &Pubkey,
+        commitment_level: i32,
+    ) -> Result<BuildTransactionResponse, Status> {
+        let transaction = self.compile(instructions, fee_payer).await?;
+
+        let estimate = self
+            .transaction_service
+            .estimate_transaction(Request::new(EstimateTransactionRequest {
+                transaction: Some(transaction.clone()),
+                commitment_level,
+            }))
+            .await?
+            .into_inner();
+
+        let message: Message = decode_data(&transaction.data).map_err(Status::internal)?;
+        let signers = message
+            .account_keys
+            .iter()
+            .take(usize::from(message.header.num_required_signatures))
+            .map(ToString::to_string)
+            .collect();
+
+        Ok(BuildTransactionResponse {
+            transaction: Some(transaction),
+            compute_units: estimate.compute_units,
+            fee_lamports: estimate.fee_lamports,
+            priority_fee: estimate.priority_fee,
+            signers,
+        })
+    }
+
+    /// Compiles, signs and submits one distribution transaction and waits for it to reach
+    /// `commitment_level`. Returns the signature (empty if it was never submitted) with
+    /// the outcome.
+    async fn send_batch(
+        &self,
+        instructions: Vec<Instruction>,
+        fee_payer: &Pubkey,
+        signing_method: SigningMethod,
+        commitment_level: i32,
+    ) -> (String, Result<(), Status>) {
+        let submitted = async {
+            let compiled = self.compile(instructions, fee_payer).await?;
+            let signed = self
+                .transaction_service
+                .sign_transaction(Request::new(SignTransactionRequest {
+                    transaction: Some(compiled),
+                    signing_method: Some(signing_method),
+                }))
+                .await?
+                .into_inner()
+                .transaction
+                .ok_or_else(|| Status::internal("Signing returned no transaction"))?;
+            let response = self
+                .transaction_service
+                .submit_transaction(Request::new(SubmitTransactionRequest {
+                    transaction: Some(signed),
+                    commitment_level,
+                    ..Default::default()
+                }))
+                .await?
+                .into_inner();
+            if response.submission_result() != SubmissionResult::Submitted {
+                return Err(Status::aborted(format!(
+                    "Transaction was not submitted: {}",
+                    response.error_message
+                )));
+            }
+            Ok(response.signature)
+        }
+        .await;
+
+        match submitted {
+            Ok(signature) => {
+                let confirmed = wait_for_transaction_success_by_string(
+                    Arc::clone(&self.rpc_client),
+                    &signature,
+                    commitment_level_to_config(commitment_level),
+                    Some(CONFIRMATION_TIMEOUT_SECONDS),
+                )
+                .await;
+                (signature, confirmed)
+            }
+            Err(status) => (String::new(), Err(status)),
+        }
+    }
+
+    /// Sends a planned distribution one transaction at a time, streaming the plan, each
+    /// recipient's outcome and a summary. A failed transaction fails only its own
+    /// recipients; sending stops early if the client goes away.
+    async fn distribute(
+        self,
+        recipients: Vec<Recipient>,
+        plan: PackedDistribution,
+        fee_payer: Pubkey,
+        signing_method: SigningMethod,
+        commitment_level: i32,
+        sender: mpsc::Sender<Result<DistributeTokensResponse, Status>>,
+    ) {
+        let event = |event: Event| Ok(DistributeTokensResponse { event: Some(event) });
+
+        let accounts_to_create = recipients
+            .iter()
+            .filter(|recipient| recipient.create_account)
+            .count();
+        let overview = DistributionPlan {
+            recipients: count(recipients.len()),
+            transactions: count(plan.batches.len()),
+            accounts_to_create: count(accounts_to_create),
+            total_amount: plan.total_amount.to_string(),
+            source_account: plan.source.to_string(),
+        };
+        if sender.send(event(Event::Plan(overview))).await.is_err() {
+            return;
+        }
+
+        let mut summary = DistributionSummary::default();
+        for (transaction_index, batch) in plan.batches.into_iter().enumerate() {
+            let (signature, outcome) = self
+                .send_batch(
+                    batch.instructions,
+                    &fee_payer,
+                    signing_method.clone(),
+                    commitment_level,
+                )
+                .await;
+            let (status, error) = match outcome {
+                Ok(()) => {
+                    summary.signatures.push(signature.clone());
+                    (DistributionRecipientStatus::Confirmed, String::new())
+                }
+                Err(status) => {
+                    warn!(
+                        transaction_index,
+                        signature = %signature,
+                        error = %status.message(),
+                        "Distribution transaction failed"
+                    );
+                    (DistributionRecipientStatus::Failed, status.message().to_string())
+                }
+            };
+
+            for index in batch.recipients {
+                let recipient = &recipients[index];
+                if status == DistributionRecipientStatus::Confirmed {
+                    summary.confirmed += 1;
+                } else {
+                    summary.failed += 1;
+                }
+                let result = DistributionRecipientResult {
+                    index: count(index),
+                    address: recipient.wallet.to_string(),
+                    token_account: recipient.token_account.to_string(),
+                    amount: recipient.amount.to_string(),
+                    account_created: recipient.create_account
+                        && status == DistributionRecipientStatus::Confirmed,
+                    status: status.into(),
+                    transaction_index: count(transaction_index),
+                    signature: signature.clone(),
+                    error: error.clone(),
+                };
+                if sender.send(event(Event::Recipient(result))).await.is_err() {
+                    warn!(transaction_index, "Distribution stream closed, stopping");
+                    return;
+                }
+            }
+        }
+
+        info!(
+            confirmed = summary.confirmed,
+            failed = summary.failed,
+            "✅ Token distribution finished"
+        );
+        let _ = sender.send(event(Event::Summary(summary))).await;
+    }
+}
+
+/// Seconds to wait for each distribution transaction to confirm
+const CONFIRMATION_TIMEOUT_SECONDS: u64 = 60;
+
+/// Token accounts read per `getMultipleAccounts` call when checking which recipients
+/// need one created
+const ACCOUNT_READ_BATCH_SIZE: usize = 100;
+
+/// Converts a count or index to its wire type, saturating (distributions are capped well
+/// below `u32::MAX`)
+fn count(value: usize) -> u32 {
+    u32::try_from(value).unwrap_or(u32::MAX)
+}
+
+/// Maps a distribution's signing method onto the transaction service's
+fn signing_method(method: DistributionSigningMethod) -> SigningMethod {
+    match method {
+        DistributionSigningMethod::PrivateKeys(keys) => SigningMethod::PrivateKeys(keys),
+        DistributionSigningMethod::Seeds(seeds) => SigningMethod::Seeds(seeds),
+        DistributionSigningMethod::StoredKeys(keys) => SigningMethod::StoredKeys(keys),
+        DistributionSigningMethod::Mnemonic(mnemonic) => SigningMethod::Mnemonic(mnemonic),
+        DistributionSigningMethod::HardwareWallet(wallet) => SigningMethod::HardwareWallet(wallet),
+        DistributionSigningMethod::Kms(kms) => SigningMethod::Kms(kms),
+        DistributionSigningMethod::Vault(vault) => SigningMethod::Vault(vault),
+    }
+}
+
+/// Parses a required public key field
+#[allow(clippy::result_large_err)]
+fn parse_pubkey(value: &str, field: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} is required")));
+    }
+    Pubkey::from_str(value).map_err(|e| Status::invalid_argument(format!("Invalid {field}: {e}")))
+}
+
+/// Parses an optional fee payer, defaulting to `default`
+#[allow(clippy::result_large_err)]
+fn fee_payer_or(value: &str, default: Pubkey) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        Ok(default)
+    } else {
+        parse_pubkey(value, "fee_payer")
+    }
+}
+
+#[tonic::async_trait]
+impl ConvenienceService for ConvenienceServiceImpl {
+    type DistributeTokensStream = ReceiverStream<Result<DistributeTokensResponse, Status>>;
+
+    /// Builds a SOL transfer with an optional memo
+    async fn build_sol_transfer(
+        &self,
+        request: Request<BuildSolTransferRequest>,
+    ) -> Result<Response<BuildTransactionResponse>, Status> {
+        let req = request.into_inner();
+
+        let from = parse_pubkey(&req.from, "from")?;
+        let to = parse_pubkey(&req.to, "to")?;
+        if req.lamports == 0 {
+            return Err(Status::invalid_argument("lamports must be greater than 0"));
+        }
+        let fee_payer = fee_payer_or(&req.fee_payer, from)?;
+
+        let mut instructions = Vec::with_capacity(2);
+        if !req.memo.is_empty() {
+            instructions.push(
+                signed_memo_instruction(&req.memo, &[from]).map_err(Status::invalid_argument)?,
+            );
+        }
+        instructions.push(system_instruction::transfer(&from, &to, req.lamports));
+
+        info!(from = %from, to = %to, lamports = req.lamports, "🧱 Building SOL transfer");
+        let response = self
+            .build(instructions, &fee_payer, req.commitment_level)
+            .await?;
+        Ok(Response::new(response))
+    }
+
+    /// Builds a `TransferChecked` between associated token accounts under the mint's
+    /// token program, delegating to the token program service's `BuildTokenTransfer`
+    async fn build_token_transfer(
+        &self,
+        request: Request<BuildTokenTransferRequest>,
+    ) -> Result<Response<BuildTransactionResponse>, Status> {
+        let req = request.into_inner();
+
+        let owner = parse_pubkey(&req.owner, "owner")?;
+        let recipient = parse_pubkey(&req.recipient, "recipient")?;
+        let mint = parse_pubkey(&req.mint, "mint")?;
+        let amount =
+            parse_amount(&req.amount, req.decimals).map_err(|e| e.into_status("amount"))?;
+        let fee_payer = fee_payer_or(&req.fee_payer, owner)?;
+
+        let transfer = self
+            .token_program_service
+            .build_token_transfer(Request::new(TokenTransferRequest {
+                sender_pub_key: owner.to_string(),
+                recipient_pub_key: recipient.to_string(),
+                mint_pub_key: mint.to_string(),
+                amount: amount.to_string(),
+                memo: req.memo,
+                payer: fee_payer.to_string(),
+                decimals: Some(req.decimals),
+                ..Default::default()
+            }))
+            .await?
+            .into_inner();
+        if transfer.creates_destination_account && !req.create_recipient_account {
+            return Err(Status::failed_precondition(format!(
+                "Recipient has no associated token account {}; set create_recipient_account",
+                transfer.destination_account_pub_key
+            )));
+        }
+        let instructions = transfer
+            .instructions
+            .into_iter()
+            .map(proto_instruction_to_sdk)
+            .collect::<Result<Vec<_>, _>>()
+            .map_err(Status::internal)?;
+
+        info!(
+            owner = %owner,
+            recipient = %recipient,
+            mint = %mint,
+            amount,
+            token_program = %transfer.token_program_id,
+            "🧱 Building token transfer"
+        );
+        let response = self
+            .build(instructions, &fee_payer, req.commitment_level)
+            .await?;
+        Ok(Response::new(response))
+    }
+
+    /// Builds a system account creation, funding it for rent exemption by default
+    async fn build_account_create(
+        &self,
+        request: Request<BuildAccountCreateRequest>,
+    ) -> Result<Response<BuildTransactionResponse>, Status> {
+        let req = request.into_inner();
+
+        let payer = parse_pubkey(&req.payer, "payer")?;
+        let new_account = parse_pubkey(&req.new_account, "new_account")?;
+        let owner = if req.owner.is_empty() {
+            system_program::id()
+        } else {
+            parse_pubkey(&req.owner, "owner")?
+        };
+
+        let lamports = if req.lamports == 0 {
+            let space = usize::try_from(req.space)
+                .map_err(|_| Status::invalid_argument("space is too large"))?;
+            self.rpc_client
+                .get_minimum_balance_for_rent_exemption(space)
+                .map_err(|e| Status::internal(format!("Failed to get rent exemption: {e}")))?
+        } else {
+            req.lamports
+        };
+
+        info!(
+            payer = %payer,
+            new_account = %new_account,
+            space = req.space,
+            lamports,
+            "🧱 Building account creation"
+        );
+        let instruction =
+            system_instruction::create_account(&payer, &new_account, lamports, req.space, &owner);
+        let response = self
+            .build(vec![instruction], &payer, req.commitment_level)
+            .await?;
+        Ok(Response::new(response))
+    }
+
+    /// Validates and plans a token distribution, then sends it in the background.
+    ///
+    /// Everything that can be checked up front is: recipient addresses and amounts, the
+    /// mint, which recipients need an associated token account, and the source balance.
+    async fn distribute_tokens(
+        &self,
+        request: Request<DistributeTokensRequest>,
+    ) -> Result<Response<Self::DistributeTokensStream>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_pubkey(&req.mint, "mint")?;
+        let funding_authority = parse_pubkey(&req.funding_authority, "funding_authority")?;
+        let fee_payer = fee_payer_or(&req.fee_payer, funding_authority)?;
+        let signing_method = req
+            .signing_method
+            .map(signing_method)
+            .ok_or_else(|| Status::invalid_argument("signing_method is required"))?;
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        let mint_account = get_account(&self.rpc_client, &mint, commitment, None)
+            .map_err(|e| read_error_status(&e, "Failed to read mint"))?
+            .ok_or_else(|| Status::not_found(format!("Mint {mint} not found")))?;
+        let token_program = mint_account.owner;
+        if token_program != TOKEN_PROGRAM_ID && token_program != TOKEN_2022_PROGRAM_ID {
+            return Err(Status::invalid_argument(format!(
+                "Mint {mint} is not owned by the SPL Token or Token-2022 program"
+            )));
+        }
+        let decimals = StateWithExtensions::<Mint>::unpack(&mint_account.data)
+            .map_err(|e| Status::invalid_argument(format!("Failed to parse mint: {e}")))?
+            .base
+            .decimals;
+
+        let mut recipients = req
+            .recipients
+            .iter()
+            .enumerate()
+            .map(|(index, recipient)| {
+                let wallet =
+                    parse_pubkey(&recipient.address, &format!("recipients[{index}].address"))?;
+                let amount = parse_amount(&recipient.amount, u32::from(decimals))
+                    .map_err(|e| e.into_status(&format!("recipients[{index}].amount")))?;
+                if amount == 0 {
+                    return Err(Status::invalid_argument(format!(
+                        "recipients[{index}].amount must be greater than 0"
+                    )));
+                }
+                Ok(Recipient::new(wallet, amount, &mint, &token_program))
+            })
+            .collect::<Result<Vec<_>, Status>>()?;
+        validate_recipients(&recipients).map_err(Status::invalid_argument)?;
+
+        for chunk in recipients.chunks_mut(ACCOUNT_READ_BATCH_SIZE) {
+            let token_accounts: Vec<Pubkey> = chunk
+                .iter()
+                .map(|recipient| recipient.token_account)
+                .collect();
+            let (_, accounts) =
+                get_multiple_accounts(&self.rpc_client, &token_accounts, commitment, None)
+                    .map_err(|e| {
+                        read_error_status(&e, "Failed to read recipient token accounts")
+                    })?;
+            for (recipient, account) in chunk.iter_mut().zip(accounts) {
+                recipient.create_account = account.is_none();
+            }
+        }
+
+        let plan = plan_distribution(
+            &mint,
+            &token_program,
+            decimals,
+            &funding_authority,
+            &fee_payer,
+            &recipients,
+        )
+        .map_err(Status::invalid_argument)?;
+
+        let balance = match get_account(&self.rpc_client, &plan.source, commitment, None)
+            .map_err(|e| read_error_status(&e, "Failed to read source token account"))?
+        {
+            Some(account) => {
+                StateWithExtensions::<Account>::unpack(&account.data)
+                    .map_err(|e| {
+                        Status::internal(format!("Failed to parse source token account: {e}"))
+                    })?
+                    .base
+                    .amount
+            }
+            None => 0,
+        };
+        if balance < plan.total_amount {
+            return Err(Status::failed_precondition(format!(
+                "Source token account {} holds {balance} base units, {} are needed",
+                plan.source, plan.total_amount
+            )));
+        }
+
+        info!(
+            mint = %mint,
+            funding_authority = %funding_authority,
+            recipients = recipients.len(),
+            transactions = plan.batches.len(),
+            total_amount = plan.total_amount,
+            "🚚 Starting token distribution"
+        );
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(self.clone().distribute(
+            recipients,
+            plan,
+            fee_payer,
+            signing_method,
+            req.commitment_level,
+            tx,
+        ));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+}
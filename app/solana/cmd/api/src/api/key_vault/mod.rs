@@ -0,0 +1,8 @@
+//! Key vault services
+//!
+//! This module provides server-held signing keys addressed by alias:
+//! - Key creation and lookup
+//! - Hot key rotation with generated on-chain migration instructions
+//! - Rotation audit trail
+
+pub mod v1;
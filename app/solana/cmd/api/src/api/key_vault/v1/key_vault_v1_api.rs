@@ -0,0 +1,22 @@
+use std::sync::Arc;
+
+use super::KeyVaultServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// gRPC service wrapper for key vault operations
+pub struct KeyVaultV1API {
+    /// Core key vault service implementation
+    pub key_vault_service: Arc<KeyVaultServiceImpl>,
+}
+
+impl KeyVaultV1API {
+    /// Creates a new `KeyVaultV1API` instance with the provided service providers
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            key_vault_service: Arc::new(KeyVaultServiceImpl::new(
+                Arc::clone(&service_providers.key_vault),
+                service_providers.solana_clients.get_rpc_client(),
+            )),
+        }
+    }
+}
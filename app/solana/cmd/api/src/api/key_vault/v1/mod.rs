@@ -0,0 +1,12 @@
+//! Key vault service v1 API and implementation
+//!
+//! This module contains the gRPC service definition and business logic
+//! for managing server-held signing keys.
+
+/// gRPC service wrapper module for key vault operations
+pub mod key_vault_v1_api;
+/// Core business logic implementation module for key vault operations
+pub mod service_impl;
+
+pub use key_vault_v1_api::KeyVaultV1API;
+pub use service_impl::KeyVaultServiceImpl;
@@ -0,0 +1,288 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    commitment_config::CommitmentConfig, instruction::Instruction, pubkey::Pubkey,
+    system_instruction,
+};
+use spl_token_2022::instruction::{set_authority, AuthorityType};
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+use tracing::{info, warn};
+
+use protochain_api::protochain::solana::key_vault::v1::{
+    service_server::Service as KeyVaultService, CreateKeyRequest, CreateKeyResponse, GetKeyRequest,
+    GetKeyResponse, KeyRotation, ListKeyRotationsRequest, ListKeyRotationsResponse,
+    ListKeysRequest, ListKeysResponse, RotateKeyRequest, RotateKeyResponse, VaultKey,
+};
+use protochain_api::protochain::solana::r#type::v1::CommitmentLevel;
+
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::service_providers::key_vault::{AliasState, KeyVault, RotationRecord};
+
+/// Fee charged per signature, reserved when migrating the native balance
+const LAMPORTS_PER_SIGNATURE: u64 = 5_000;
+
+#[derive(Clone)]
+/// Core business logic implementation for key vault operations
+pub struct KeyVaultServiceImpl {
+    /// Shared key vault
+    key_vault: Arc<KeyVault>,
+    /// RPC client used to look up balances for migrations
+    rpc_client: Arc<RpcClient>,
+}
+
+impl KeyVaultServiceImpl {
+    /// Creates a new `KeyVaultServiceImpl` instance with the provided vault and RPC client
+    pub const fn new(key_vault: Arc<KeyVault>, rpc_client: Arc<RpcClient>) -> Self {
+        Self {
+            key_vault,
+            rpc_client,
+        }
+    }
+}
+
+/// Converts protobuf `CommitmentLevel` to Solana `CommitmentConfig`
+fn commitment_level_to_config(commitment_level: i32) -> CommitmentConfig {
+    match CommitmentLevel::try_from(commitment_level) {
+        Ok(CommitmentLevel::Processed) => CommitmentConfig::processed(),
+        Ok(CommitmentLevel::Finalized) => CommitmentConfig::finalized(),
+        Ok(CommitmentLevel::Confirmed | CommitmentLevel::Unspecified) | Err(_) => {
+            CommitmentConfig::confirmed()
+        }
+    }
+}
+
+/// Converts an alias state into the proto representation
+fn vault_key_to_proto(state: AliasState) -> VaultKey {
+    VaultKey {
+        alias: state.alias,
+        public_key: state.public_key.to_string(),
+        version: state.version,
+        created_at: state.created_at,
+        rotated_at: state.rotated_at,
+        retired_public_keys: state.retired.iter().map(ToString::to_string).collect(),
+    }
+}
+
+/// Converts a rotation record into the proto representation
+fn key_rotation_to_proto(record: RotationRecord) -> KeyRotation {
+    KeyRotation {
+        id: record.id,
+        alias: record.alias,
+        previous_public_key: record.previous.to_string(),
+        successor_public_key: record.successor.to_string(),
+        previous_version: record.previous_version,
+        successor_version: record.previous_version + 1,
+        reason: record.reason,
+        rotated_at: record.rotated_at,
+        migration_instructions: record
+            .migration_instructions
+            .into_iter()
+            .map(sdk_instruction_to_proto)
+            .collect(),
+    }
+}
+
+/// Parses a list of base58 addresses, naming the offending field on error
+#[allow(clippy::result_large_err)]
+fn parse_addresses(addresses: &[String], field: &str) -> Result<Vec<Pubkey>, Status> {
+    addresses
+        .iter()
+        .map(|address| {
+            Pubkey::from_str(address)
+                .map_err(|e| Status::invalid_argument(format!("Invalid {field} entry: {e}")))
+        })
+        .collect()
+}
+
+/// Builds the instructions that move authorities and balances from `previous` to `successor`
+fn migration_instructions(
+    token_program_id: &Pubkey,
+    previous: &Pubkey,
+    successor: &Pubkey,
+    authorities: &[(AuthorityType, Vec<Pubkey>)],
+    native_lamports: u64,
+) -> Result<Vec<Instruction>, String> {
+    let mut instructions = Vec::new();
+    for (authority_type, accounts) in authorities {
+        for account in accounts {
+            instructions.push(
+                set_authority(
+                    token_program_id,
+                    account,
+                    Some(successor),
+                    authority_type.clone(),
+                    previous,
+                    &[],
+                )
+                .map_err(|e| format!("Failed to build SetAuthority instruction: {e}"))?,
+            );
+        }
+    }
+    if native_lamports > 0 {
+        instructions.push(system_instruction::transfer(previous, successor, native_lamports));
+    }
+    Ok(instructions)
+}
+
+#[tonic::async_trait]
+impl KeyVaultService for KeyVaultServiceImpl {
+    async fn create_key(
+        &self,
+        request: Request<CreateKeyRequest>,
+    ) -> Result<Response<CreateKeyResponse>, Status> {
+        let req = request.into_inner();
+
+        let state = self.key_vault.create(&req.alias).map_err(|e| {
+            if e.starts_with("Alias already exists") {
+                Status::already_exists(e)
+            } else {
+                Status::invalid_argument(e)
+            }
+        })?;
+
+        info!(alias = %state.alias, public_key = %state.public_key, "🔑 Vault key created");
+
+        Ok(Response::new(CreateKeyResponse {
+            key: Some(vault_key_to_proto(state)),
+        }))
+    }
+
+    async fn get_key(
+        &self,
+        request: Request<GetKeyRequest>,
+    ) -> Result<Response<GetKeyResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.alias.is_empty() {
+            return Err(Status::invalid_argument("Alias is required"));
+        }
+
+        let state = self
+            .key_vault
+            .get(&req.alias)
+            .ok_or_else(|| Status::not_found(format!("Alias not found: {}", req.alias)))?;
+
+        Ok(Response::new(GetKeyResponse {
+            key: Some(vault_key_to_proto(state)),
+        }))
+    }
+
+    async fn list_keys(
+        &self,
+        _request: Request<ListKeysRequest>,
+    ) -> Result<Response<ListKeysResponse>, Status> {
+        Ok(Response::new(ListKeysResponse {
+            keys: self
+                .key_vault
+                .list()
+                .into_iter()
+                .map(vault_key_to_proto)
+                .collect(),
+        }))
+    }
+
+    /// Rotates the key behind an alias and generates the on-chain migration
+    ///
+    /// All inputs are validated and the migration is built before the alias is swapped, so a
+    /// rejected request never leaves the alias half-rotated. The native balance transfer
+    /// keeps back one signature fee, which assumes the retired key pays for the migration.
+    async fn rotate_key(
+        &self,
+        request: Request<RotateKeyRequest>,
+    ) -> Result<Response<RotateKeyResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.alias.is_empty() {
+            return Err(Status::invalid_argument("Alias is required"));
+        }
+        let current = self
+            .key_vault
+            .get(&req.alias)
+            .ok_or_else(|| Status::not_found(format!("Alias not found: {}", req.alias)))?;
+
+        let token_program_id = if req.token_program_id.is_empty() {
+            spl_token_2022::ID
+        } else {
+            Pubkey::from_str(&req.token_program_id)
+                .map_err(|e| Status::invalid_argument(format!("Invalid token_program_id: {e}")))?
+        };
+        let authorities = [
+            (
+                AuthorityType::MintTokens,
+                parse_addresses(&req.mint_authority_mints, "mint_authority_mints")?,
+            ),
+            (
+                AuthorityType::FreezeAccount,
+                parse_addresses(&req.freeze_authority_mints, "freeze_authority_mints")?,
+            ),
+            (
+                AuthorityType::AccountOwner,
+                parse_addresses(&req.token_accounts, "token_accounts")?,
+            ),
+        ];
+
+        // Look up the balance first so a failed lookup leaves the alias untouched
+        let native_balance = if req.migrate_native_balance {
+            self.rpc_client
+                .get_balance_with_commitment(
+                    &current.public_key,
+                    commitment_level_to_config(req.commitment_level),
+                )
+                .map_err(|e| Status::internal(format!("Failed to get balance: {e}")))?
+                .value
+        } else {
+            0
+        };
+
+        let native_lamports = native_balance.saturating_sub(LAMPORTS_PER_SIGNATURE);
+
+        let (state, record) = self
+            .key_vault
+            .rotate(&req.alias, &req.reason, |previous, successor| {
+                // The balance above belongs to the key read earlier
+                if *previous != current.public_key {
+                    return Err("Alias was rotated concurrently, retry the rotation".to_string());
+                }
+                migration_instructions(
+                    &token_program_id,
+                    previous,
+                    successor,
+                    &authorities,
+                    native_lamports,
+                )
+            })
+            .map_err(Status::failed_precondition)?;
+
+        warn!(
+            alias = %record.alias,
+            previous = %record.previous,
+            successor = %record.successor,
+            reason = %record.reason,
+            migration_instructions = record.migration_instructions.len(),
+            "Vault key rotated"
+        );
+
+        Ok(Response::new(RotateKeyResponse {
+            key: Some(vault_key_to_proto(state)),
+            rotation: Some(key_rotation_to_proto(record)),
+        }))
+    }
+
+    async fn list_key_rotations(
+        &self,
+        request: Request<ListKeyRotationsRequest>,
+    ) -> Result<Response<ListKeyRotationsResponse>, Status> {
+        let req = request.into_inner();
+        let alias = (!req.alias.is_empty()).then_some(req.alias.as_str());
+
+        Ok(Response::new(ListKeyRotationsResponse {
+            rotations: self
+                .key_vault
+                .rotations(alias)
+                .into_iter()
+                .map(key_rotation_to_proto)
+                .collect(),
+        }))
+    }
+}
@@ -1,14 +1,26 @@
 /// Account management services
 pub mod account;
+/// Administrative services (capabilities, feature flags)
+pub mod admin;
 /// Main API aggregator
 pub mod aggregator;
 /// Common utilities shared across API implementations
 pub mod common;
+/// One-call builders for common transactions
+pub mod convenience;
+/// Key vault services for server-held signing keys
+pub mod key_vault;
+/// Status and cancellation of long-running orchestrations
+pub mod operations;
 /// Solana program services
 pub mod program;
 /// RPC Client services for direct Solana RPC access
 pub mod rpc_client;
+/// Stake account lifecycles run as operations
+pub mod staking;
 /// Transaction lifecycle services
 pub mod transaction;
+/// Reusable, parameterised transaction templates
+pub mod transaction_template;
 
 pub use aggregator::Api;
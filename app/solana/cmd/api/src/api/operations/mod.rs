@@ -0,0 +1,7 @@
+//! Operation services
+//!
+//! This module provides uniform tracking of long-running orchestrations:
+//! - Operation lookup and listing by kind and target
+//! - Cooperative cancellation
+
+pub mod v1;
@@ -0,0 +1,12 @@
+//! Operations service v1 API and implementation
+//!
+//! This module contains the gRPC service definition and business logic
+//! for polling and cancelling long-running orchestrations.
+
+/// gRPC service wrapper module for operations
+pub mod operations_v1_api;
+/// Core business logic implementation module for operations
+pub mod service_impl;
+
+pub use operations_v1_api::OperationsV1API;
+pub use service_impl::OperationsServiceImpl;
@@ -0,0 +1,21 @@
+use std::sync::Arc;
+
+use super::OperationsServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// gRPC service wrapper for operations
+pub struct OperationsV1API {
+    /// Core operations service implementation
+    pub operations_service: Arc<OperationsServiceImpl>,
+}
+
+impl OperationsV1API {
+    /// Creates a new `OperationsV1API` instance with the provided service providers
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            operations_service: Arc::new(OperationsServiceImpl::new(Arc::clone(
+                &service_providers.operations,
+            ))),
+        }
+    }
+}
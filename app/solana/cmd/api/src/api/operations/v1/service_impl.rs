@@ -0,0 +1,91 @@
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+use tracing::info;
+
+use protochain_api::protochain::solana::operations::v1::{
+    service_server::Service as OperationsService, CancelOperationRequest, CancelOperationResponse,
+    GetOperationRequest, GetOperationResponse, ListOperationsRequest, ListOperationsResponse,
+    Operation,
+};
+
+use crate::service_providers::operations::OperationStore;
+
+#[derive(Clone)]
+/// Core business logic implementation for operations
+pub struct OperationsServiceImpl {
+    /// Shared operation store
+    operations: Arc<OperationStore>,
+}
+
+impl OperationsServiceImpl {
+    /// Creates a new `OperationsServiceImpl` instance with the provided store
+    pub const fn new(operations: Arc<OperationStore>) -> Self {
+        Self { operations }
+    }
+
+    /// Looks up an operation by id
+    #[allow(clippy::result_large_err)]
+    fn operation(&self, id: &str) -> Result<Operation, Status> {
+        if id.is_empty() {
+            return Err(Status::invalid_argument("id is required"));
+        }
+        self.operations
+            .get(id)
+            .ok_or_else(|| Status::not_found(format!("Operation not found: {id}")))
+    }
+}
+
+/// Treats an empty filter field as unset
+fn filter(value: &str) -> Option<&str> {
+    Some(value).filter(|value| !value.is_empty())
+}
+
+#[tonic::async_trait]
+impl OperationsService for OperationsServiceImpl {
+    async fn get_operation(
+        &self,
+        request: Request<GetOperationRequest>,
+    ) -> Result<Response<GetOperationResponse>, Status> {
+        let operation = self.operation(&request.into_inner().id)?;
+        Ok(Response::new(GetOperationResponse {
+            operation: Some(operation),
+        }))
+    }
+
+    async fn list_operations(
+        &self,
+        request: Request<ListOperationsRequest>,
+    ) -> Result<Response<ListOperationsResponse>, Status> {
+        let req = request.into_inner();
+        Ok(Response::new(ListOperationsResponse {
+            operations: self.operations.list(
+                filter(&req.kind),
+                filter(&req.target),
+                req.running_only,
+            ),
+        }))
+    }
+
+    /// Flags a running operation for cancellation; it stops at its next checkpoint
+    async fn cancel_operation(
+        &self,
+        request: Request<CancelOperationRequest>,
+    ) -> Result<Response<CancelOperationResponse>, Status> {
+        let id = request.into_inner().id;
+        if self.operation(&id)?.done {
+            return Err(Status::failed_precondition(format!(
+                "Operation {id} has already finished"
+            )));
+        }
+
+        let operation = self
+            .operations
+            .request_cancel(&id)
+            .ok_or_else(|| Status::not_found(format!("Operation not found: {id}")))?;
+
+        info!(id = %id, kind = %operation.kind, "🛑 Cancellation requested for operation");
+        Ok(Response::new(CancelOperationResponse {
+            operation: Some(operation),
+        }))
+    }
+}
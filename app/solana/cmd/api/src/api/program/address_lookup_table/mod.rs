@@ -0,0 +1,4 @@
+/// Address Lookup Table Program v1 services
+pub mod v1;
+
+pub use v1::address_lookup_table_v1_api::AddressLookupTableV1API;
@@ -0,0 +1,24 @@
+use std::sync::Arc;
+
+use super::service_impl::AddressLookupTableProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Address Lookup Table Program API v1 wrapper
+pub struct AddressLookupTableV1API {
+    /// The Address Lookup Table Program service implementation
+    pub address_lookup_table_program_service: Arc<AddressLookupTableProgramServiceImpl>,
+}
+
+impl AddressLookupTableV1API {
+    /// Creates a new Address Lookup Table V1 API instance
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            address_lookup_table_program_service: Arc::new(
+                AddressLookupTableProgramServiceImpl::new(
+                    Arc::clone(&service_providers.solana_clients.rpc_client),
+                    Arc::clone(&service_providers.rpc_limiter),
+                ),
+            ),
+        }
+    }
+}
@@ -0,0 +1,6 @@
+/// Address Lookup Table program API wrapper
+pub mod address_lookup_table_v1_api;
+/// Address Lookup Table program service implementation
+pub mod service_impl;
+/// Lookup table account decoding
+pub mod state;
@@ -0,0 +1,230 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    address_lookup_table::{self, instruction as lookup_table_instruction},
+    commitment_config::CommitmentConfig,
+    pubkey::Pubkey,
+};
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::address_lookup_table::v1::{
+    service_server::Service as AddressLookupTableProgramService, CloseLookupTableRequest,
+    CreateLookupTableRequest, CreateLookupTableResponse, DeactivateLookupTableRequest,
+    ExtendLookupTableRequest, FreezeLookupTableRequest, ParseLookupTableRequest,
+    ParseLookupTableResponse,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use super::state::{lookup_table_info, MAX_LOOKUP_TABLE_ADDRESSES};
+use crate::api::common::min_context_slot::{get_account, min_context_slot, read_error_status};
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+
+/// Address Lookup Table Program service implementation.
+///
+/// Instruction builders work offline, except that `CreateLookupTable` reads the latest
+/// finalized slot when none is given; `ParseLookupTable` reads the table account.
+#[derive(Clone)]
+pub struct AddressLookupTableProgramServiceImpl {
+    /// Solana RPC client for reading slots and lookup table accounts
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+}
+
+impl AddressLookupTableProgramServiceImpl {
+    /// Creates a new instance of the Address Lookup Table Program service with the provided
+    /// RPC client.
+    pub const fn new(rpc_client: Arc<RpcClient>, rpc_limiter: Arc<RpcLimiter>) -> Self {
+        Self {
+            rpc_client,
+            rpc_limiter,
+        }
+    }
+
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
+    }
+}
+
+/// Parses a required address field of a request
+#[allow(clippy::result_large_err)]
+fn parse_address(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} address is required")));
+    }
+    Pubkey::from_str(value)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} address: {e}")))
+}
+
+#[tonic::async_trait]
+impl AddressLookupTableProgramService for AddressLookupTableProgramServiceImpl {
+    /// Creates an instruction creating a lookup table.
+    async fn create_lookup_table(
+        &self,
+        request: Request<CreateLookupTableRequest>,
+    ) -> Result<Response<CreateLookupTableResponse>, Status> {
+        let req = request.into_inner();
+
+        let authority = parse_address("Authority", &req.authority)?;
+        let payer = parse_address("Payer", &req.payer)?;
+        // The slot must still be in the slot hashes when the transaction lands
+        let recent_slot = if req.recent_slot == 0 {
+            let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+            self.rpc_client
+                .get_slot_with_commitment(CommitmentConfig::finalized())
+                .map_err(|e| Status::internal(format!("Failed to get recent slot: {e}")))?
+        } else {
+            req.recent_slot
+        };
+
+        let (instruction, lookup_table_address) =
+            lookup_table_instruction::create_lookup_table(authority, payer, recent_slot);
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = format!(
+            "Create address lookup table {lookup_table_address} (authority: {authority}, payer: {payer}, recent slot: {recent_slot})"
+        );
+
+        Ok(Response::new(CreateLookupTableResponse {
+            instruction: Some(proto_instruction),
+            lookup_table_address: lookup_table_address.to_string(),
+            recent_slot,
+        }))
+    }
+
+    /// Creates an instruction appending addresses to a lookup table.
+    async fn extend_lookup_table(
+        &self,
+        request: Request<ExtendLookupTableRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let lookup_table = parse_address("Lookup table", &req.lookup_table_address)?;
+        let authority = parse_address("Authority", &req.authority)?;
+        let payer = if req.payer.is_empty() {
+            None
+        } else {
+            Some(parse_address("Payer", &req.payer)?)
+        };
+        if req.new_addresses.is_empty() {
+            return Err(Status::invalid_argument("At least one new address is required"));
+        }
+        if req.new_addresses.len() > MAX_LOOKUP_TABLE_ADDRESSES {
+            return Err(Status::invalid_argument(format!(
+                "A lookup table holds at most {MAX_LOOKUP_TABLE_ADDRESSES} addresses, got {}",
+                req.new_addresses.len()
+            )));
+        }
+        let new_addresses = req
+            .new_addresses
+            .iter()
+            .map(|address| parse_address("New", address))
+            .collect::<Result<Vec<_>, _>>()?;
+
+        let count = new_addresses.len();
+        let instruction = lookup_table_instruction::extend_lookup_table(
+            lookup_table,
+            authority,
+            payer,
+            new_addresses,
+        );
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description =
+            format!("Extend address lookup table {lookup_table} with {count} addresses");
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Creates an instruction freezing a lookup table.
+    async fn freeze_lookup_table(
+        &self,
+        request: Request<FreezeLookupTableRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let lookup_table = parse_address("Lookup table", &req.lookup_table_address)?;
+        let authority = parse_address("Authority", &req.authority)?;
+
+        let mut proto_instruction = sdk_instruction_to_proto(
+            lookup_table_instruction::freeze_lookup_table(lookup_table, authority),
+        );
+        proto_instruction.description = format!("Freeze address lookup table {lookup_table}");
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Creates an instruction deactivating a lookup table.
+    async fn deactivate_lookup_table(
+        &self,
+        request: Request<DeactivateLookupTableRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let lookup_table = parse_address("Lookup table", &req.lookup_table_address)?;
+        let authority = parse_address("Authority", &req.authority)?;
+
+        let mut proto_instruction = sdk_instruction_to_proto(
+            lookup_table_instruction::deactivate_lookup_table(lookup_table, authority),
+        );
+        proto_instruction.description = format!("Deactivate address lookup table {lookup_table}");
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Creates an instruction closing a deactivated lookup table.
+    async fn close_lookup_table(
+        &self,
+        request: Request<CloseLookupTableRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let lookup_table = parse_address("Lookup table", &req.lookup_table_address)?;
+        let authority = parse_address("Authority", &req.authority)?;
+        let recipient = parse_address("Recipient", &req.recipient)?;
+
+        let mut proto_instruction = sdk_instruction_to_proto(
+            lookup_table_instruction::close_lookup_table(lookup_table, authority, recipient),
+        );
+        proto_instruction.description = format!(
+            "Close address lookup table {lookup_table}, sending its lamports to {recipient}"
+        );
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Reads and decodes a lookup table account.
+    async fn parse_lookup_table(
+        &self,
+        request: Request<ParseLookupTableRequest>,
+    ) -> Result<Response<ParseLookupTableResponse>, Status> {
+        let req = request.into_inner();
+
+        let address = parse_address("Account", &req.account_address)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let account = get_account(
+            &self.rpc_client,
+            &address,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
+        if account.owner != address_lookup_table::program::id() {
+            return Err(Status::invalid_argument(
+                "Account is not owned by the address lookup table program",
+            ));
+        }
+
+        let lookup_table = lookup_table_info(&address, account.lamports, &account.data)
+            .map_err(Status::invalid_argument)?;
+        Ok(Response::new(ParseLookupTableResponse {
+            lookup_table: Some(lookup_table),
+        }))
+    }
+}
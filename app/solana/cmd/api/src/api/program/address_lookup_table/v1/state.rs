@@ -0,0 +1,87 @@
+use protochain_api::protochain::solana::program::address_lookup_table::v1::LookupTableInfo;
+use solana_sdk::{address_lookup_table::state::AddressLookupTable, clock::Slot, pubkey::Pubkey};
+
+/// Most addresses a lookup table can hold
+pub const MAX_LOOKUP_TABLE_ADDRESSES: usize = 256;
+
+/// Decodes a lookup table account holding `lamports`
+pub fn lookup_table_info(
+    address: &Pubkey,
+    lamports: u64,
+    data: &[u8],
+) -> Result<LookupTableInfo, String> {
+    let table = AddressLookupTable::deserialize(data)
+        .map_err(|e| format!("Invalid address lookup table {address}: {e}"))?;
+    let meta = &table.meta;
+    Ok(LookupTableInfo {
+        address: address.to_string(),
+        authority: meta
+            .authority
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        frozen: meta.authority.is_none(),
+        deactivation_slot: (meta.deactivation_slot != Slot::MAX).then_some(meta.deactivation_slot),
+        last_extended_slot: meta.last_extended_slot,
+        last_extended_slot_start_index: u32::from(meta.last_extended_slot_start_index),
+        addresses: table.addresses.iter().map(ToString::to_string).collect(),
+        lamports,
+    })
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::address_lookup_table::state::LookupTableMeta;
+    use std::borrow::Cow;
+
+    fn table_data(
+        authority: Option<Pubkey>,
+        deactivation_slot: Slot,
+        addresses: &[Pubkey],
+    ) -> Vec<u8> {
+        AddressLookupTable {
+            meta: LookupTableMeta {
+                authority,
+                deactivation_slot,
+                last_extended_slot: 90,
+                last_extended_slot_start_index: 1,
+                ..LookupTableMeta::default()
+            },
+            addresses: Cow::Borrowed(addresses),
+        }
+        .serialize_for_tests()
+        .unwrap()
+    }
+
+    #[test]
+    fn test_active_table_info() {
+        let address = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let entries = [Pubkey::new_unique(), Pubkey::new_unique()];
+
+        let info =
+            lookup_table_info(&address, 10, &table_data(Some(authority), Slot::MAX, &entries))
+                .unwrap();
+
+        assert_eq!(info.authority, authority.to_string());
+        assert!(!info.frozen);
+        assert_eq!(info.deactivation_slot, None);
+        assert_eq!(info.last_extended_slot, 90);
+        assert_eq!(info.last_extended_slot_start_index, 1);
+        assert_eq!(info.addresses, vec![entries[0].to_string(), entries[1].to_string()]);
+    }
+
+    #[test]
+    fn test_frozen_deactivated_table_info() {
+        let info =
+            lookup_table_info(&Pubkey::new_unique(), 10, &table_data(None, 120, &[])).unwrap();
+        assert!(info.frozen && info.authority.is_empty());
+        assert_eq!(info.deactivation_slot, Some(120));
+    }
+
+    #[test]
+    fn test_garbage_data_is_rejected() {
+        assert!(lookup_table_info(&Pubkey::new_unique(), 0, &[1, 2, 3]).is_err());
+    }
+}
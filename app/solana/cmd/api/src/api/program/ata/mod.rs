@@ -0,0 +1,4 @@
+/// Associated Token Account Program v1 services
+pub mod v1;
+
+pub use v1::ata_v1_api::AtaV1API;
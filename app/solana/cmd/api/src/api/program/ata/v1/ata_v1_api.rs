@@ -0,0 +1,20 @@
+use std::sync::Arc;
+
+use super::service_impl::AtaProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Associated Token Account Program API v1 wrapper
+pub struct AtaV1API {
+    /// The Associated Token Account Program service implementation
+    pub ata_program_service: Arc<AtaProgramServiceImpl>,
+}
+
+impl AtaV1API {
+    /// Creates a new Associated Token Account V1 API instance
+    pub fn new(_service_providers: &Arc<ServiceProviders>) -> Self {
+        // No RPC client needed - instructions and addresses are built offline
+        Self {
+            ata_program_service: Arc::new(AtaProgramServiceImpl::new()),
+        }
+    }
+}
@@ -0,0 +1,167 @@
+use solana_sdk::{
+    instruction::{AccountMeta, Instruction},
+    pubkey::Pubkey,
+    system_program,
+};
+
+use crate::api::account::v1::derivation::derive_associated_token_address;
+use crate::api::common::instruction_decoding::ASSOCIATED_TOKEN_PROGRAM_ID;
+
+/// Associated Token Account program instruction that creates an account
+const CREATE: u8 = 0;
+/// Associated Token Account program instruction that creates an account unless it exists
+const CREATE_IDEMPOTENT: u8 = 1;
+/// Associated Token Account program instruction that recovers a nested account's tokens
+const RECOVER_NESTED: u8 = 2;
+
+/// Creates `wallet`'s associated token account for `mint` under `token_program`, paid by
+/// `payer`. With `idempotent`, the instruction also succeeds when the account exists.
+pub fn create(
+    payer: &Pubkey,
+    wallet: &Pubkey,
+    mint: &Pubkey,
+    token_program: &Pubkey,
+    idempotent: bool,
+) -> Instruction {
+    let (address, _) = derive_associated_token_address(wallet, mint, token_program);
+    Instruction::new_with_bytes(
+        ASSOCIATED_TOKEN_PROGRAM_ID,
+        &[if idempotent {
+            CREATE_IDEMPOTENT
+        } else {
+            CREATE
+        }],
+        vec![
+            AccountMeta::new(*payer, true),
+            AccountMeta::new(address, false),
+            AccountMeta::new_readonly(*wallet, false),
+            AccountMeta::new_readonly(*mint, false),
+            AccountMeta::new_readonly(system_program::id(), false),
+            AccountMeta::new_readonly(*token_program, false),
+        ],
+    )
+}
+
+/// Accounts a `RecoverNested` instruction moves tokens between
+pub struct NestedAccounts {
+    /// `wallet`'s associated token account for the owner mint, which owns the nested account
+    pub owner_account: Pubkey,
+    /// The owner account's associated token account for the nested mint
+    pub nested_account: Pubkey,
+    /// `wallet`'s associated token account for the nested mint, receiving the tokens
+    pub destination_account: Pubkey,
+}
+
+/// Derives the accounts of a `RecoverNested` instruction
+pub fn nested_accounts(
+    wallet: &Pubkey,
+    owner_mint: &Pubkey,
+    nested_mint: &Pubkey,
+    token_program: &Pubkey,
+) -> NestedAccounts {
+    let (owner_account, _) = derive_associated_token_address(wallet, owner_mint, token_program);
+    let (nested_account, _) =
+        derive_associated_token_address(&owner_account, nested_mint, token_program);
+    let (destination_account, _) =
+        derive_associated_token_address(wallet, nested_mint, token_program);
+    NestedAccounts {
+        owner_account,
+        nested_account,
+        destination_account,
+    }
+}
+
+/// Moves the tokens of the nested account (see `nested_accounts`) to `wallet`'s associated
+/// token account for `nested_mint` and closes it, returning its rent to `wallet`
+pub fn recover_nested(
+    wallet: &Pubkey,
+    owner_mint: &Pubkey,
+    nested_mint: &Pubkey,
+    token_program: &Pubkey,
+) -> Instruction {
+    let accounts = nested_accounts(wallet, owner_mint, nested_mint, token_program);
+    Instruction::new_with_bytes(
+        ASSOCIATED_TOKEN_PROGRAM_ID,
+        &[RECOVER_NESTED],
+        vec![
+            AccountMeta::new(accounts.nested_account, false),
+            AccountMeta::new_readonly(*nested_mint, false),
+            AccountMeta::new(accounts.destination_account, false),
+            AccountMeta::new_readonly(accounts.owner_account, false),
+            AccountMeta::new_readonly(*owner_mint, false),
+            AccountMeta::new(*wallet, true),
+            AccountMeta::new_readonly(*token_program, false),
+        ],
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::api::common::instruction_decoding::{decode_instruction, TOKEN_PROGRAM_ID};
+    use protochain_api::protochain::solana::transaction::v1::decoded_instruction::Details;
+    use spl_token_2022::ID as TOKEN_2022_PROGRAM_ID;
+
+    #[test]
+    fn test_create_idempotent() {
+        let payer = Pubkey::new_unique();
+        let wallet = Pubkey::new_unique();
+        let mint = Pubkey::new_unique();
+
+        let instruction = create(&payer, &wallet, &mint, &TOKEN_2022_PROGRAM_ID, true);
+        assert_eq!(instruction.program_id, ASSOCIATED_TOKEN_PROGRAM_ID);
+        assert_eq!(instruction.data, vec![CREATE_IDEMPOTENT]);
+        assert!(instruction.accounts[0].is_signer);
+        assert_eq!(
+            instruction.accounts[1].pubkey,
+            derive_associated_token_address(&wallet, &mint, &TOKEN_2022_PROGRAM_ID).0
+        );
+        assert_eq!(instruction.accounts[5].pubkey, TOKEN_2022_PROGRAM_ID);
+        assert_eq!(
+            create(&payer, &wallet, &mint, &TOKEN_2022_PROGRAM_ID, false).data,
+            vec![CREATE]
+        );
+    }
+
+    #[test]
+    fn test_create_uses_the_requested_token_program() {
+        let wallet = Pubkey::new_unique();
+        let mint = Pubkey::new_unique();
+        let instruction = create(&wallet, &wallet, &mint, &TOKEN_PROGRAM_ID, false);
+
+        assert_eq!(
+            instruction.accounts[1].pubkey,
+            derive_associated_token_address(&wallet, &mint, &TOKEN_PROGRAM_ID).0
+        );
+        assert_eq!(instruction.accounts[5].pubkey, TOKEN_PROGRAM_ID);
+    }
+
+    #[test]
+    fn test_recover_nested_accounts() {
+        let wallet = Pubkey::new_unique();
+        let owner_mint = Pubkey::new_unique();
+        let nested_mint = Pubkey::new_unique();
+        let accounts = nested_accounts(&wallet, &owner_mint, &nested_mint, &TOKEN_2022_PROGRAM_ID);
+        let instruction =
+            recover_nested(&wallet, &owner_mint, &nested_mint, &TOKEN_2022_PROGRAM_ID);
+
+        assert_eq!(instruction.data, vec![RECOVER_NESTED]);
+        assert_eq!(instruction.accounts[0].pubkey, accounts.nested_account);
+        assert_eq!(instruction.accounts[2].pubkey, accounts.destination_account);
+        assert_eq!(instruction.accounts[3].pubkey, accounts.owner_account);
+        assert!(instruction.accounts[5].is_signer && instruction.accounts[5].is_writable);
+
+        let keys: Vec<Pubkey> = instruction
+            .accounts
+            .iter()
+            .map(|meta| meta.pubkey)
+            .collect();
+        let decoded = decode_instruction(0, &instruction.program_id, &keys, &instruction.data);
+        assert_eq!(decoded.instruction_type, "RecoverNested");
+        let Some(Details::AssociatedToken(details)) = decoded.details else {
+            panic!("expected associated token details");
+        };
+        assert_eq!(details.associated_account, accounts.nested_account.to_string());
+        assert_eq!(details.wallet, wallet.to_string());
+    }
+}
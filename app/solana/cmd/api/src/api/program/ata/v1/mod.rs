@@ -0,0 +1,6 @@
+/// Associated Token Account program API wrapper
+pub mod ata_v1_api;
+/// Associated Token Account program instruction builders
+pub mod instructions;
+/// Associated Token Account program service implementation
+pub mod service_impl;
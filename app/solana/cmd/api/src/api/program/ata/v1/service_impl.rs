@@ -0,0 +1,133 @@
+use solana_sdk::pubkey::Pubkey;
+use std::str::FromStr;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::ata::v1::{
+    service_server::Service as AtaProgramService, CreateIdempotentRequest, CreateRequest,
+    DeriveAddressRequest, DeriveAddressResponse, RecoverNestedRequest,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use super::instructions::{create, nested_accounts, recover_nested};
+use crate::api::account::v1::derivation::{derive_associated_token_address, resolve_token_program};
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+
+/// Pure instruction-based Associated Token Account Program service implementation.
+///
+/// All methods build instructions or derive addresses offline; no RPC client is needed.
+/// The token program defaults to Token-2022, like the rest of the API.
+#[derive(Clone, Default)]
+pub struct AtaProgramServiceImpl {}
+
+impl AtaProgramServiceImpl {
+    /// Creates a new instance of the Associated Token Account Program service.
+    pub const fn new() -> Self {
+        Self {}
+    }
+}
+
+/// Parses a required address field of a request
+#[allow(clippy::result_large_err)]
+fn parse_address(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} address is required")));
+    }
+    Pubkey::from_str(value)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} address: {e}")))
+}
+
+/// Builds a `Create` or `CreateIdempotent` instruction with its description
+#[allow(clippy::result_large_err)]
+fn create_instruction(
+    payer: &str,
+    wallet: &str,
+    mint: &str,
+    token_program_id: &str,
+    idempotent: bool,
+) -> Result<SolanaInstruction, Status> {
+    let payer = parse_address("Payer", payer)?;
+    let wallet = parse_address("Wallet", wallet)?;
+    let mint = parse_address("Mint", mint)?;
+    let token_program =
+        resolve_token_program(token_program_id).map_err(Status::invalid_argument)?;
+
+    let instruction = create(&payer, &wallet, &mint, &token_program, idempotent);
+    let address = instruction.accounts[1].pubkey;
+
+    let mut proto_instruction = sdk_instruction_to_proto(instruction);
+    proto_instruction.description = format!(
+        "Create{} associated token account {address} for {wallet} and mint {mint} (payer: {payer}, token program: {token_program})",
+        if idempotent { " (if missing)" } else { "" }
+    );
+    Ok(proto_instruction)
+}
+
+#[tonic::async_trait]
+impl AtaProgramService for AtaProgramServiceImpl {
+    /// Creates an instruction creating a wallet's associated token account.
+    async fn create(
+        &self,
+        request: Request<CreateRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+        let instruction =
+            create_instruction(&req.payer, &req.wallet, &req.mint, &req.token_program_id, false)?;
+        Ok(Response::new(instruction))
+    }
+
+    /// Creates an instruction creating a wallet's associated token account unless it exists.
+    async fn create_idempotent(
+        &self,
+        request: Request<CreateIdempotentRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+        let instruction =
+            create_instruction(&req.payer, &req.wallet, &req.mint, &req.token_program_id, true)?;
+        Ok(Response::new(instruction))
+    }
+
+    /// Creates an instruction recovering the tokens of a nested associated token account.
+    async fn recover_nested(
+        &self,
+        request: Request<RecoverNestedRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let wallet = parse_address("Wallet", &req.wallet)?;
+        let owner_mint = parse_address("Owner mint", &req.owner_mint)?;
+        let nested_mint = parse_address("Nested mint", &req.nested_mint)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        let accounts = nested_accounts(&wallet, &owner_mint, &nested_mint, &token_program);
+        let instruction = recover_nested(&wallet, &owner_mint, &nested_mint, &token_program);
+
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = format!(
+            "Recover nested associated token account {} into {} (wallet: {wallet})",
+            accounts.nested_account, accounts.destination_account
+        );
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Derives a wallet's associated token account address.
+    async fn derive_address(
+        &self,
+        request: Request<DeriveAddressRequest>,
+    ) -> Result<Response<DeriveAddressResponse>, Status> {
+        let req = request.into_inner();
+
+        let wallet = parse_address("Wallet", &req.wallet)?;
+        let mint = parse_address("Mint", &req.mint)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        let (address, bump) = derive_associated_token_address(&wallet, &mint, &token_program);
+
+        Ok(Response::new(DeriveAddressResponse {
+            address: address.to_string(),
+            bump: u32::from(bump),
+            token_program_id: token_program.to_string(),
+        }))
+    }
+}
@@ -0,0 +1,4 @@
+/// Upgradeable BPF Loader v1 services
+pub mod v1;
+
+pub use v1::bpf_loader_v1_api::BpfLoaderV1API;
@@ -0,0 +1,23 @@
+use std::sync::Arc;
+
+use super::service_impl::BpfLoaderProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Upgradeable BPF Loader API v1 wrapper
+pub struct BpfLoaderV1API {
+    /// The Upgradeable BPF Loader service implementation
+    pub bpf_loader_program_service: Arc<BpfLoaderProgramServiceImpl>,
+}
+
+impl BpfLoaderV1API {
+    /// Creates a new BPF Loader V1 API instance
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            bpf_loader_program_service: Arc::new(BpfLoaderProgramServiceImpl::new(
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.rpc_limiter),
+                Arc::clone(&service_providers.key_vault),
+            )),
+        }
+    }
+}
@@ -0,0 +1,402 @@
+use protochain_api::protochain::solana::program::bpf_loader::v1::{
+    DeployProgramResponse, DeployStage,
+};
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    bpf_loader_upgradeable::{self, UpgradeableLoaderState},
+    commitment_config::CommitmentConfig,
+    hash::Hash,
+    instruction::Instruction,
+    message::Message,
+    packet::PACKET_DATA_SIZE,
+    pubkey::Pubkey,
+    signature::{Keypair, Signature, Signer},
+    transaction::Transaction as SolanaTransaction,
+};
+use std::sync::Arc;
+use tokio::sync::mpsc;
+use tonic::Status;
+use tracing::{info, warn};
+
+use crate::api::common::transaction_monitoring::wait_for_transaction_success;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+
+/// How long each deployment transaction may take to confirm
+const CONFIRMATION_TIMEOUT_SECONDS: u64 = 90;
+
+/// Leading bytes of every ELF file
+const ELF_MAGIC: &[u8] = b"\x7fELF";
+
+/// Largest number of program bytes a `Write` instruction can carry in a transaction paid by
+/// `payer`, the way the Solana CLI sizes its buffer writes
+pub fn max_write_chunk_size(payer: &Pubkey, buffer: &Pubkey, authority: &Pubkey) -> usize {
+    let instruction = bpf_loader_upgradeable::write(buffer, authority, 0, Vec::new());
+    let message = Message::new_with_blockhash(&[instruction], Some(payer), &Hash::default());
+    let empty_size = bincode::serialized_size(&SolanaTransaction::new_unsigned(message))
+        .ok()
+        .and_then(|size| usize::try_from(size).ok())
+        .unwrap_or(PACKET_DATA_SIZE);
+    // The instruction data length prefix grows by a byte once the data exceeds 127 bytes
+    PACKET_DATA_SIZE
+        .saturating_sub(empty_size)
+        .saturating_sub(1)
+}
+
+/// Largest program a new deployment's data account holds by default: twice the program,
+/// leaving room for upgrades
+pub const fn default_max_data_len(program_len: usize) -> usize {
+    program_len.saturating_mul(2)
+}
+
+/// Whether `data` is an ELF file, as every deployable program is
+pub fn is_elf(data: &[u8]) -> bool {
+    data.starts_with(ELF_MAGIC)
+}
+
+/// Splits `data`, destined for `buffer` at `offset`, into `Write` instructions of at most
+/// `chunk_size` bytes
+pub fn write_instructions(
+    buffer: &Pubkey,
+    authority: &Pubkey,
+    data: &[u8],
+    offset: u32,
+    chunk_size: usize,
+) -> Result<Vec<Instruction>, String> {
+    if data.is_empty() {
+        return Err("Data is required".to_string());
+    }
+    if chunk_size == 0 {
+        return Err("Chunk size must be positive".to_string());
+    }
+    data.chunks(chunk_size)
+        .enumerate()
+        .map(|(index, chunk)| {
+            let chunk_offset = index
+                .checked_mul(chunk_size)
+                .and_then(|relative| u32::try_from(relative).ok())
+                .and_then(|relative| offset.checked_add(relative))
+                .ok_or_else(|| "Write would exceed the largest buffer offset".to_string())?;
+            Ok(bpf_loader_upgradeable::write(buffer, authority, chunk_offset, chunk.to_vec()))
+        })
+        .collect()
+}
+
+/// Program a deployment writes its buffer into
+pub enum DeployTarget {
+    /// A new program at the address of this key
+    New(Arc<Keypair>),
+    /// An existing program, upgraded
+    Upgrade(Pubkey),
+}
+
+/// Everything an end-to-end deployment needs, validated and resolved
+pub struct DeployPlan {
+    /// Program ELF
+    pub program_data: Vec<u8>,
+    /// Pays fees and rent
+    pub payer: Arc<Keypair>,
+    /// Authority of the buffer and upgrade authority of the program
+    pub upgrade_authority: Arc<Keypair>,
+    /// Program deployed or upgraded
+    pub target: DeployTarget,
+    /// Data account size of a new program
+    pub max_data_len: usize,
+    /// Commitment each transaction waits for
+    pub commitment: CommitmentConfig,
+}
+
+impl DeployPlan {
+    /// Program the plan deploys
+    pub fn program_id(&self) -> Pubkey {
+        match &self.target {
+            DeployTarget::New(program) => program.pubkey(),
+            DeployTarget::Upgrade(program_id) => *program_id,
+        }
+    }
+}
+
+/// Writes the plan's program into a fresh buffer and deploys or upgrades from it, streaming
+/// progress to `sender`. Stops early if the client disconnects.
+pub async fn deploy_program(
+    rpc_client: Arc<RpcClient>,
+    rpc_limiter: Arc<RpcLimiter>,
+    plan: DeployPlan,
+    sender: mpsc::Sender<Result<DeployProgramResponse, Status>>,
+) {
+    let buffer = Keypair::new();
+    let program_id = plan.program_id();
+    info!(
+        program_id = %program_id,
+        buffer = %buffer.pubkey(),
+        bytes = plan.program_data.len(),
+        "🚀 Starting program deployment"
+    );
+
+    if let Err(status) = run_deployment(&rpc_client, &rpc_limiter, &plan, &buffer, &sender).await {
+        warn!(
+            program_id = %program_id,
+            buffer = %buffer.pubkey(),
+            error = %status.message(),
+            "Program deployment failed"
+        );
+        let _ = sender
+            .send(Err(Status::new(
+                status.code(),
+                format!(
+                    "{} (buffer {} keeps its lamports until closed)",
+                    status.message(),
+                    buffer.pubkey()
+                ),
+            )))
+            .await;
+    }
+}
+
+/// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when too
+/// many calls of `class` are already in flight
+async fn rpc_permit(
+    rpc_limiter: &RpcLimiter,
+    class: RpcCallClass,
+) -> Result<RpcPermit<'_>, Status> {
+    rpc_limiter
+        .acquire(class)
+        .await
+        .map_err(Status::resource_exhausted)
+}
+
+async fn run_deployment(
+    rpc_client: &Arc<RpcClient>,
+    rpc_limiter: &RpcLimiter,
+    plan: &DeployPlan,
+    buffer: &Keypair,
+    sender: &mpsc::Sender<Result<DeployProgramResponse, Status>>,
+) -> Result<(), Status> {
+    let payer = plan.payer.as_ref();
+    let authority = plan.upgrade_authority.as_ref();
+    let program_id = plan.program_id();
+    let programdata_address = bpf_loader_upgradeable::get_program_data_address(&program_id);
+    let total_bytes = plan.program_data.len() as u64;
+    let progress =
+        |stage: DeployStage, bytes_written: u64, signature: &Signature| DeployProgramResponse {
+            stage: stage.into(),
+            program_id: program_id.to_string(),
+            programdata_address: programdata_address.to_string(),
+            buffer_address: buffer.pubkey().to_string(),
+            bytes_written,
+            total_bytes,
+            signature: signature.to_string(),
+        };
+
+    let permit = rpc_permit(rpc_limiter, RpcCallClass::AccountRead).await?;
+    let buffer_lamports = rpc_client
+        .get_minimum_balance_for_rent_exemption(UpgradeableLoaderState::size_of_buffer(
+            plan.program_data.len(),
+        ))
+        .map_err(|e| Status::unavailable(format!("Failed to get rent exemption: {e}")))?;
+    drop(permit);
+    let create_buffer = bpf_loader_upgradeable::create_buffer(
+        &payer.pubkey(),
+        &buffer.pubkey(),
+        &authority.pubkey(),
+        buffer_lamports,
+        plan.program_data.len(),
+    )
+    .map_err(|e| Status::internal(format!("Failed to build buffer instructions: {e}")))?;
+    let signature = send_and_confirm(
+        rpc_client,
+        rpc_limiter,
+        payer,
+        &[payer, buffer],
+        &create_buffer,
+        plan.commitment,
+    )
+    .await?;
+    if sender
+        .send(Ok(progress(DeployStage::BufferCreated, 0, &signature)))
+        .await
+        .is_err()
+    {
+        return Ok(());
+    }
+
+    let chunk_size = max_write_chunk_size(&payer.pubkey(), &buffer.pubkey(), &authority.pubkey());
+    let writes = write_instructions(
+        &buffer.pubkey(),
+        &authority.pubkey(),
+        &plan.program_data,
+        0,
+        chunk_size,
+    )
+    .map_err(Status::invalid_argument)?;
+    let mut bytes_written = 0u64;
+    for (write, chunk) in writes.iter().zip(plan.program_data.chunks(chunk_size)) {
+        let signature = send_and_confirm(
+            rpc_client,
+            rpc_limiter,
+            payer,
+            &[payer, authority],
+            std::slice::from_ref(write),
+            plan.commitment,
+        )
+        .await?;
+        bytes_written += chunk.len() as u64;
+        if sender
+            .send(Ok(progress(DeployStage::Writing, bytes_written, &signature)))
+            .await
+            .is_err()
+        {
+            warn!(buffer = %buffer.pubkey(), "Client disconnected from program deployment");
+            return Ok(());
+        }
+    }
+
+    let signature = match &plan.target {
+        DeployTarget::New(program) => {
+            let permit = rpc_permit(rpc_limiter, RpcCallClass::AccountRead).await?;
+            let program_lamports = rpc_client
+                .get_minimum_balance_for_rent_exemption(UpgradeableLoaderState::size_of_program())
+                .map_err(|e| Status::unavailable(format!("Failed to get rent exemption: {e}")))?;
+            drop(permit);
+            let deploy = bpf_loader_upgradeable::deploy_with_max_program_len(
+                &payer.pubkey(),
+                &program.pubkey(),
+                &buffer.pubkey(),
+                &authority.pubkey(),
+                program_lamports,
+                plan.max_data_len,
+            )
+            .map_err(|e| Status::internal(format!("Failed to build deploy instructions: {e}")))?;
+            send_and_confirm(
+                rpc_client,
+                rpc_limiter,
+                payer,
+                &[payer, program.as_ref(), authority],
+                &deploy,
+                plan.commitment,
+            )
+            .await?
+        }
+        DeployTarget::Upgrade(program_id) => {
+            let upgrade = bpf_loader_upgradeable::upgrade(
+                program_id,
+                &buffer.pubkey(),
+                &authority.pubkey(),
+                &payer.pubkey(),
+            );
+            send_and_confirm(
+                rpc_client,
+                rpc_limiter,
+                payer,
+                &[payer, authority],
+                &[upgrade],
+                plan.commitment,
+            )
+            .await?
+        }
+    };
+
+    info!(program_id = %program_id, signature = %signature, "✅ Program deployed");
+    let _ = sender
+        .send(Ok(progress(DeployStage::Deployed, bytes_written, &signature)))
+        .await;
+    Ok(())
+}
+
+/// Signs `instructions` with `signers` (duplicates ignored), `payer` paying the fees,
+/// submits them and waits until the transaction succeeds at `commitment`
+async fn send_and_confirm(
+    rpc_client: &Arc<RpcClient>,
+    rpc_limiter: &RpcLimiter,
+    payer: &Keypair,
+    signers: &[&Keypair],
+    instructions: &[Instruction],
+    commitment: CommitmentConfig,
+) -> Result<Signature, Status> {
+    let mut unique_signers: Vec<&Keypair> = Vec::with_capacity(signers.len());
+    for signer in signers {
+        if !unique_signers
+            .iter()
+            .any(|unique| unique.pubkey() == signer.pubkey())
+        {
+            unique_signers.push(signer);
+        }
+    }
+
+    let permit = rpc_permit(rpc_limiter, RpcCallClass::Blockhash).await?;
+    let recent_blockhash = rpc_client
+        .get_latest_blockhash()
+        .map_err(|e| Status::unavailable(format!("Failed to get latest blockhash: {e}")))?;
+    drop(permit);
+    let mut transaction = SolanaTransaction::new_with_payer(instructions, Some(&payer.pubkey()));
+    transaction
+        .try_sign(unique_signers.as_slice(), recent_blockhash)
+        .map_err(|e| Status::internal(format!("Failed to sign transaction: {e}")))?;
+    let permit = rpc_permit(rpc_limiter, RpcCallClass::Submission).await?;
+    let signature = rpc_client
+        .send_transaction(&transaction)
+        .map_err(|e| Status::aborted(format!("Failed to send transaction: {e}")))?;
+    drop(permit);
+    wait_for_transaction_success(
+        Arc::clone(rpc_client),
+        &signature,
+        commitment,
+        Some(CONFIRMATION_TIMEOUT_SECONDS),
+    )
+    .await
+    .map_err(|status| {
+        Status::aborted(format!("Transaction {signature} failed: {}", status.message()))
+    })?;
+    Ok(signature)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn transaction_size(payer: &Pubkey, instruction: Instruction) -> usize {
+        let message = Message::new_with_blockhash(&[instruction], Some(payer), &Hash::default());
+        usize::try_from(
+            bincode::serialized_size(&SolanaTransaction::new_unsigned(message)).unwrap(),
+        )
+        .unwrap()
+    }
+
+    #[test]
+    fn test_max_write_chunk_fits_one_transaction() {
+        let payer = Pubkey::new_unique();
+        let buffer = Pubkey::new_unique();
+        let chunk_size = max_write_chunk_size(&payer, &buffer, &payer);
+
+        let full = bpf_loader_upgradeable::write(&buffer, &payer, 0, vec![1; chunk_size]);
+        assert!(transaction_size(&payer, full) <= PACKET_DATA_SIZE);
+        let over = bpf_loader_upgradeable::write(&buffer, &payer, 0, vec![1; chunk_size + 2]);
+        assert!(transaction_size(&payer, over) > PACKET_DATA_SIZE);
+
+        // A separate authority signs too, leaving less room
+        assert!(max_write_chunk_size(&payer, &buffer, &Pubkey::new_unique()) < chunk_size);
+    }
+
+    #[test]
+    fn test_write_instructions_cover_data_in_order() {
+        let buffer = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let data: Vec<u8> = (0..=250).collect();
+
+        let writes = write_instructions(&buffer, &authority, &data, 10, 100).unwrap();
+        assert_eq!(writes.len(), 3);
+        assert_eq!(
+            writes[2],
+            bpf_loader_upgradeable::write(&buffer, &authority, 210, data[200..].to_vec())
+        );
+        assert!(write_instructions(&buffer, &authority, &[], 0, 100).is_err());
+        assert!(write_instructions(&buffer, &authority, &data, u32::MAX, 100).is_err());
+    }
+
+    #[test]
+    fn test_is_elf() {
+        assert!(is_elf(b"\x7fELF\x02\x01\x01"));
+        assert!(!is_elf(b"MZ\x90\x00"));
+        assert_eq!(default_max_data_len(1_000), 2_000);
+    }
+}
@@ -0,0 +1,6 @@
+/// Upgradeable BPF Loader API wrapper
+pub mod bpf_loader_v1_api;
+/// Buffer chunking and end-to-end program deployment
+pub mod deployment;
+/// Upgradeable BPF Loader service implementation
+pub mod service_impl;
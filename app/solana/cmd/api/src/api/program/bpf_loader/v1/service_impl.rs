@@ -0,0 +1,448 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    bpf_loader_upgradeable::{self, UpgradeableLoaderState},
+    instruction::Instruction,
+    pubkey::Pubkey,
+    signature::Keypair,
+};
+use std::str::FromStr;
+use std::sync::Arc;
+use tokio::sync::mpsc;
+use tokio_stream::wrappers::ReceiverStream;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::bpf_loader::v1::{
+    service_server::Service as BpfLoaderProgramService, CloseRequest, DeployProgramRequest,
+    DeployProgramResponse, DeployWithMaxDataLenRequest, DeployWithMaxDataLenResponse,
+    InitializeBufferRequest, InitializeBufferResponse, LoaderAccountKind, SetAuthorityRequest,
+    UpgradeRequest, WriteRequest, WriteResponse,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use super::deployment::{
+    default_max_data_len, deploy_program, is_elf, max_write_chunk_size, write_instructions,
+    DeployPlan, DeployTarget,
+};
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::api::transaction::v1::service_impl::commitment_level_to_config;
+use crate::service_providers::key_vault::KeyVault;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter};
+
+/// Upgradeable BPF Loader service implementation.
+///
+/// The builders only read rent exemption from the cluster when no funding is given;
+/// `DeployProgram` submits its transactions, signing with key vault keys.
+#[derive(Clone)]
+pub struct BpfLoaderProgramServiceImpl {
+    /// Solana RPC client for rent exemption and deployments
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+    /// Key vault holding the keys deployments sign with
+    key_vault: Arc<KeyVault>,
+}
+
+impl BpfLoaderProgramServiceImpl {
+    /// Creates a new instance of the Upgradeable BPF Loader service with the RPC client
+    /// deployments go through, its concurrency limiter and the key vault holding their
+    /// signers.
+    pub const fn new(
+        rpc_client: Arc<RpcClient>,
+        rpc_limiter: Arc<RpcLimiter>,
+        key_vault: Arc<KeyVault>,
+    ) -> Self {
+        Self {
+            rpc_client,
+            rpc_limiter,
+            key_vault,
+        }
+    }
+
+    /// Resolves a key vault reference naming a signer of a deployment
+    #[allow(clippy::result_large_err)]
+    fn signer(&self, field: &str, key_ref: &str) -> Result<Arc<Keypair>, Status> {
+        if key_ref.is_empty() {
+            return Err(Status::invalid_argument(format!("{field} is required")));
+        }
+        self.key_vault
+            .resolve(key_ref)
+            .ok_or_else(|| Status::not_found(format!("Key not found in key vault: {key_ref}")))
+    }
+
+    /// Returns `lamports`, or the rent exemption of `size` bytes when it is 0
+    async fn funding(&self, lamports: u64, size: usize) -> Result<u64, Status> {
+        if lamports != 0 {
+            return Ok(lamports);
+        }
+        let _permit = self
+            .rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        self.rpc_client
+            .get_minimum_balance_for_rent_exemption(size)
+            .map_err(|e| Status::unavailable(format!("Failed to get rent exemption: {e}")))
+    }
+}
+
+/// Parses a required address field of a request
+#[allow(clippy::result_large_err)]
+fn parse_address(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} address is required")));
+    }
+    Pubkey::from_str(value)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} address: {e}")))
+}
+
+/// Parses an optional address field of a request, empty meaning unset
+#[allow(clippy::result_large_err)]
+fn parse_optional_address(field: &str, value: &str) -> Result<Option<Pubkey>, Status> {
+    if value.is_empty() {
+        return Ok(None);
+    }
+    parse_address(field, value).map(Some)
+}
+
+/// Converts a program length field
+#[allow(clippy::result_large_err)]
+fn parse_len(field: &str, value: u64) -> Result<usize, Status> {
+    match usize::try_from(value) {
+        Ok(0) => Err(Status::invalid_argument(format!("{field} must be positive"))),
+        Ok(len) => Ok(len),
+        Err(_) => Err(Status::invalid_argument(format!("{field} is too large"))),
+    }
+}
+
+/// Converts an instruction and attaches its description
+fn described(instruction: Instruction, description: String) -> SolanaInstruction {
+    let mut proto_instruction = sdk_instruction_to_proto(instruction);
+    proto_instruction.description = description;
+    proto_instruction
+}
+
+#[tonic::async_trait]
+impl BpfLoaderProgramService for BpfLoaderProgramServiceImpl {
+    type DeployProgramStream = ReceiverStream<Result<DeployProgramResponse, Status>>;
+
+    /// Creates instructions creating and initializing a program buffer.
+    async fn initialize_buffer(
+        &self,
+        request: Request<InitializeBufferRequest>,
+    ) -> Result<Response<InitializeBufferResponse>, Status> {
+        let req = request.into_inner();
+
+        let payer = parse_address("Payer", &req.payer)?;
+        let buffer = parse_address("Buffer", &req.buffer)?;
+        let authority = parse_optional_address("Authority", &req.authority)?.unwrap_or(payer);
+        let program_len = parse_len("Program length", req.program_len)?;
+        let buffer_len = UpgradeableLoaderState::size_of_buffer(program_len);
+        let lamports = self.funding(req.lamports, buffer_len).await?;
+
+        let instructions = bpf_loader_upgradeable::create_buffer(
+            &payer,
+            &buffer,
+            &authority,
+            lamports,
+            program_len,
+        )
+        .map_err(|e| Status::internal(format!("Failed to build instructions: {e}")))?;
+        let descriptions = [
+            format!("Create buffer account {buffer} ({buffer_len} bytes, {lamports} lamports, payer: {payer})"),
+            format!("Initialize buffer {buffer} (authority: {authority})"),
+        ];
+
+        Ok(Response::new(InitializeBufferResponse {
+            instructions: instructions
+                .into_iter()
+                .zip(descriptions)
+                .map(|(instruction, description)| described(instruction, description))
+                .collect(),
+            buffer_len: buffer_len as u64,
+            lamports,
+        }))
+    }
+
+    /// Splits program bytes into buffer writes that each fit a transaction.
+    async fn write(
+        &self,
+        request: Request<WriteRequest>,
+    ) -> Result<Response<WriteResponse>, Status> {
+        let req = request.into_inner();
+
+        let buffer = parse_address("Buffer", &req.buffer)?;
+        let authority = parse_address("Authority", &req.authority)?;
+        let payer = parse_optional_address("Payer", &req.payer)?.unwrap_or(authority);
+
+        let max_chunk_size = max_write_chunk_size(&payer, &buffer, &authority);
+        let chunk_size = match usize::try_from(req.chunk_size) {
+            Ok(0) => max_chunk_size,
+            Ok(chunk_size) if chunk_size <= max_chunk_size => chunk_size,
+            _ => {
+                return Err(Status::invalid_argument(format!(
+                    "Chunk size may be at most {max_chunk_size} bytes"
+                )))
+            }
+        };
+        let instructions =
+            write_instructions(&buffer, &authority, &req.data, req.offset, chunk_size)
+                .map_err(Status::invalid_argument)?;
+
+        let mut offset = u64::from(req.offset);
+        let instructions = instructions
+            .into_iter()
+            .zip(req.data.chunks(chunk_size))
+            .map(|(instruction, chunk)| {
+                let description =
+                    format!("Write {} bytes to buffer {buffer} at offset {offset}", chunk.len());
+                offset += chunk.len() as u64;
+                described(instruction, description)
+            })
+            .collect();
+
+        Ok(Response::new(WriteResponse {
+            instructions,
+            chunk_size: u32::try_from(chunk_size).unwrap_or(u32::MAX),
+        }))
+    }
+
+    /// Creates instructions creating a program account and deploying a buffer into it.
+    async fn deploy_with_max_data_len(
+        &self,
+        request: Request<DeployWithMaxDataLenRequest>,
+    ) -> Result<Response<DeployWithMaxDataLenResponse>, Status> {
+        let req = request.into_inner();
+
+        let payer = parse_address("Payer", &req.payer)?;
+        let program = parse_address("Program", &req.program)?;
+        let buffer = parse_address("Buffer", &req.buffer)?;
+        let upgrade_authority = parse_address("Upgrade authority", &req.upgrade_authority)?;
+        let max_data_len = if req.max_data_len == 0 {
+            if req.program_len == 0 {
+                return Err(Status::invalid_argument(
+                    "program_len is required when max_data_len is unset",
+                ));
+            }
+            default_max_data_len(parse_len("Program length", req.program_len)?)
+        } else {
+            parse_len("Max data length", req.max_data_len)?
+        };
+        let program_lamports = self
+            .funding(req.program_lamports, UpgradeableLoaderState::size_of_program())
+            .await?;
+
+        let instructions = bpf_loader_upgradeable::deploy_with_max_program_len(
+            &payer,
+            &program,
+            &buffer,
+            &upgrade_authority,
+            program_lamports,
+            max_data_len,
+        )
+        .map_err(|e| Status::internal(format!("Failed to build instructions: {e}")))?;
+        let programdata_address = bpf_loader_upgradeable::get_program_data_address(&program);
+        let descriptions = [
+            format!("Create program account {program} ({program_lamports} lamports, payer: {payer})"),
+            format!("Deploy buffer {buffer} to program {program} (max data length: {max_data_len} bytes, upgrade authority: {upgrade_authority})"),
+        ];
+
+        Ok(Response::new(DeployWithMaxDataLenResponse {
+            instructions: instructions
+                .into_iter()
+                .zip(descriptions)
+                .map(|(instruction, description)| described(instruction, description))
+                .collect(),
+            programdata_address: programdata_address.to_string(),
+            max_data_len: max_data_len as u64,
+        }))
+    }
+
+    /// Creates an instruction upgrading a program from a buffer.
+    async fn upgrade(
+        &self,
+        request: Request<UpgradeRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let program = parse_address("Program", &req.program)?;
+        let buffer = parse_address("Buffer", &req.buffer)?;
+        let upgrade_authority = parse_address("Upgrade authority", &req.upgrade_authority)?;
+        let spill = parse_optional_address("Spill", &req.spill)?.unwrap_or(upgrade_authority);
+
+        let instruction =
+            bpf_loader_upgradeable::upgrade(&program, &buffer, &upgrade_authority, &spill);
+        Ok(Response::new(described(
+            instruction,
+            format!("Upgrade program {program} from buffer {buffer} (buffer lamports to {spill})"),
+        )))
+    }
+
+    /// Creates an instruction changing a buffer's authority or a program's upgrade authority.
+    async fn set_authority(
+        &self,
+        request: Request<SetAuthorityRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let account = parse_address("Account", &req.account)?;
+        let current_authority = parse_address("Current authority", &req.current_authority)?;
+        let new_authority = parse_optional_address("New authority", &req.new_authority)?;
+
+        let (instruction, description) =
+            match (LoaderAccountKind::try_from(req.kind), new_authority) {
+                (Ok(LoaderAccountKind::Buffer), Some(new_authority)) => (
+                    if req.checked {
+                        bpf_loader_upgradeable::set_buffer_authority_checked(
+                            &account,
+                            &current_authority,
+                            &new_authority,
+                        )
+                    } else {
+                        bpf_loader_upgradeable::set_buffer_authority(
+                            &account,
+                            &current_authority,
+                            &new_authority,
+                        )
+                    },
+                    format!("Set authority of buffer {account} to {new_authority}"),
+                ),
+                (Ok(LoaderAccountKind::Buffer), None) => {
+                    return Err(Status::invalid_argument("Buffers require a new authority"))
+                }
+                (Ok(LoaderAccountKind::Program), Some(new_authority)) => (
+                    if req.checked {
+                        bpf_loader_upgradeable::set_upgrade_authority_checked(
+                            &account,
+                            &current_authority,
+                            &new_authority,
+                        )
+                    } else {
+                        bpf_loader_upgradeable::set_upgrade_authority(
+                            &account,
+                            &current_authority,
+                            Some(&new_authority),
+                        )
+                    },
+                    format!("Set upgrade authority of program {account} to {new_authority}"),
+                ),
+                (Ok(LoaderAccountKind::Program), None) => {
+                    if req.checked {
+                        return Err(Status::invalid_argument(
+                            "Checked authority changes require a new authority",
+                        ));
+                    }
+                    (
+                        bpf_loader_upgradeable::set_upgrade_authority(
+                            &account,
+                            &current_authority,
+                            None,
+                        ),
+                        format!("Make program {account} immutable"),
+                    )
+                }
+                _ => return Err(Status::invalid_argument("kind must be BUFFER or PROGRAM")),
+            };
+
+        Ok(Response::new(described(instruction, description)))
+    }
+
+    /// Creates an instruction closing a buffer or a program's data account.
+    async fn close(
+        &self,
+        request: Request<CloseRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let account = parse_address("Account", &req.account)?;
+        let recipient = parse_address("Recipient", &req.recipient)?;
+        let authority = parse_address("Authority", &req.authority)?;
+
+        let (instruction, description) = match LoaderAccountKind::try_from(req.kind) {
+            Ok(LoaderAccountKind::Buffer) => (
+                bpf_loader_upgradeable::close_any(&account, &recipient, Some(&authority), None),
+                format!("Close buffer {account} (lamports to {recipient})"),
+            ),
+            Ok(LoaderAccountKind::Program) => {
+                let programdata_address =
+                    bpf_loader_upgradeable::get_program_data_address(&account);
+                (
+                    bpf_loader_upgradeable::close_any(
+                        &programdata_address,
+                        &recipient,
+                        Some(&authority),
+                        Some(&account),
+                    ),
+                    format!("Close program {account} (lamports to {recipient})"),
+                )
+            }
+            _ => return Err(Status::invalid_argument("kind must be BUFFER or PROGRAM")),
+        };
+
+        Ok(Response::new(described(instruction, description)))
+    }
+
+    /// Deploys or upgrades a program end to end, streaming each step.
+    async fn deploy_program(
+        &self,
+        request: Request<DeployProgramRequest>,
+    ) -> Result<Response<Self::DeployProgramStream>, Status> {
+        let req = request.into_inner();
+
+        if !is_elf(&req.program_data) {
+            return Err(Status::invalid_argument("Program data must be an ELF file"));
+        }
+        let payer = self.signer("payer_key_ref", &req.payer_key_ref)?;
+        let upgrade_authority = if req.upgrade_authority_key_ref.is_empty() {
+            Arc::clone(&payer)
+        } else {
+            self.signer("upgrade_authority_key_ref", &req.upgrade_authority_key_ref)?
+        };
+        let target = match (req.program_key_ref.is_empty(), req.program_id.is_empty()) {
+            (false, true) => {
+                DeployTarget::New(self.signer("program_key_ref", &req.program_key_ref)?)
+            }
+            (true, false) => {
+                if req.max_data_len != 0 {
+                    return Err(Status::invalid_argument(
+                        "max_data_len only applies to new deployments",
+                    ));
+                }
+                DeployTarget::Upgrade(parse_address("Program", &req.program_id)?)
+            }
+            _ => {
+                return Err(Status::invalid_argument(
+                    "Exactly one of program_key_ref and program_id is required",
+                ))
+            }
+        };
+        let max_data_len = if req.max_data_len == 0 {
+            default_max_data_len(req.program_data.len())
+        } else {
+            parse_len("Max data length", req.max_data_len)?
+        };
+        if max_data_len < req.program_data.len() {
+            return Err(Status::invalid_argument(
+                "max_data_len must be at least the program length",
+            ));
+        }
+
+        let plan = DeployPlan {
+            program_data: req.program_data,
+            payer,
+            upgrade_authority,
+            target,
+            max_data_len,
+            commitment: commitment_level_to_config(req.commitment_level),
+        };
+
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(deploy_program(
+            Arc::clone(&self.rpc_client),
+            Arc::clone(&self.rpc_limiter),
+            plan,
+            tx,
+        ));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+}
@@ -0,0 +1,4 @@
+/// Compute Budget Program v1 services
+pub mod v1;
+
+pub use v1::compute_budget_v1_api::ComputeBudgetV1API;
@@ -0,0 +1,20 @@
+use std::sync::Arc;
+
+use super::service_impl::ComputeBudgetProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Compute Budget Program API v1 wrapper
+pub struct ComputeBudgetV1API {
+    /// The Compute Budget Program service implementation
+    pub compute_budget_program_service: Arc<ComputeBudgetProgramServiceImpl>,
+}
+
+impl ComputeBudgetV1API {
+    /// Creates a new Compute Budget V1 API instance
+    pub fn new(_service_providers: &Arc<ServiceProviders>) -> Self {
+        // No RPC client needed - compute budget instructions are built offline
+        Self {
+            compute_budget_program_service: Arc::new(ComputeBudgetProgramServiceImpl::new()),
+        }
+    }
+}
@@ -0,0 +1,4 @@
+/// Compute Budget program API wrapper
+pub mod compute_budget_v1_api;
+/// Compute Budget program service implementation
+pub mod service_impl;
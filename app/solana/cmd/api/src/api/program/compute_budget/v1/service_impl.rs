@@ -0,0 +1,92 @@
+use solana_sdk::compute_budget::ComputeBudgetInstruction;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::compute_budget::v1::{
+    service_server::Service as ComputeBudgetProgramService, RequestHeapFrameRequest,
+    SetComputeUnitLimitRequest, SetComputeUnitPriceRequest, SetLoadedAccountsDataSizeLimitRequest,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::api::transaction::v1::compute_budget::{
+    validate_compute_unit_limit, validate_heap_frame_bytes,
+    validate_loaded_accounts_data_size_limit,
+};
+
+/// Pure instruction-based Compute Budget Program service implementation.
+///
+/// Instructions are built offline; no RPC client is needed. Transactions carrying any of
+/// these instructions are left alone by `CompileTransaction`'s `auto_compute_budget`.
+#[derive(Clone, Default)]
+pub struct ComputeBudgetProgramServiceImpl {}
+
+impl ComputeBudgetProgramServiceImpl {
+    /// Creates a new instance of the Compute Budget Program service.
+    pub const fn new() -> Self {
+        Self {}
+    }
+}
+
+#[tonic::async_trait]
+impl ComputeBudgetProgramService for ComputeBudgetProgramServiceImpl {
+    /// Creates an instruction capping the compute units a transaction may consume.
+    async fn set_compute_unit_limit(
+        &self,
+        request: Request<SetComputeUnitLimitRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+        validate_compute_unit_limit(req.units).map_err(Status::invalid_argument)?;
+
+        let instruction = ComputeBudgetInstruction::set_compute_unit_limit(req.units);
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = format!("Set compute unit limit to {}", req.units);
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Creates an instruction setting the priority fee paid per compute unit.
+    async fn set_compute_unit_price(
+        &self,
+        request: Request<SetComputeUnitPriceRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let instruction = ComputeBudgetInstruction::set_compute_unit_price(req.micro_lamports);
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description =
+            format!("Set compute unit price to {} micro-lamports", req.micro_lamports);
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Creates an instruction capping the account data a transaction may load.
+    async fn set_loaded_accounts_data_size_limit(
+        &self,
+        request: Request<SetLoadedAccountsDataSizeLimitRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+        validate_loaded_accounts_data_size_limit(req.bytes).map_err(Status::invalid_argument)?;
+
+        let instruction = ComputeBudgetInstruction::set_loaded_accounts_data_size_limit(req.bytes);
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description =
+            format!("Set loaded accounts data size limit to {} bytes", req.bytes);
+
+        Ok(Response::new(proto_instruction))
+    }
+
+    /// Creates an instruction requesting a larger heap frame.
+    async fn request_heap_frame(
+        &self,
+        request: Request<RequestHeapFrameRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+        validate_heap_frame_bytes(req.bytes).map_err(Status::invalid_argument)?;
+
+        let instruction = ComputeBudgetInstruction::request_heap_frame(req.bytes);
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = format!("Request a {} byte heap frame", req.bytes);
+
+        Ok(Response::new(proto_instruction))
+    }
+}
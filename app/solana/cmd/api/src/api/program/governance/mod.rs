@@ -0,0 +1,4 @@
+/// SPL Governance v1 services
+pub mod v1;
+
+pub use v1::governance_v1_api::GovernanceV1API;
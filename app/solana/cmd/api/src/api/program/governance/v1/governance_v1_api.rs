@@ -0,0 +1,22 @@
+use std::sync::Arc;
+
+use super::service_impl::GovernanceProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// SPL Governance API v1 wrapper
+pub struct GovernanceV1API {
+    /// The SPL Governance service implementation
+    pub governance_program_service: Arc<GovernanceProgramServiceImpl>,
+}
+
+impl GovernanceV1API {
+    /// Creates a new Governance V1 API instance
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            governance_program_service: Arc::new(GovernanceProgramServiceImpl::new(
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.rpc_limiter),
+            )),
+        }
+    }
+}
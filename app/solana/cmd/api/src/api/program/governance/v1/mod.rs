@@ -0,0 +1,6 @@
+/// SPL Governance API wrapper
+pub mod governance_v1_api;
+/// SPL Governance service implementation
+pub mod service_impl;
+/// Governance account decoding
+pub mod state;
@@ -0,0 +1,251 @@
+use solana_account_decoder::{UiAccountEncoding, UiDataSliceConfig};
+use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::{RpcAccountInfoConfig, RpcProgramAccountsConfig};
+use solana_client::rpc_filter::{Memcmp, RpcFilterType};
+use solana_sdk::{account::Account, commitment_config::CommitmentConfig, pubkey::Pubkey};
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::governance::v1::{
+    service_server::Service as GovernanceProgramService, ListProposalsRequest,
+    ListProposalsResponse, ParseProposalRequest, ParseProposalResponse, ParseRealmRequest,
+    ParseRealmResponse, ParseTokenOwnerRecordRequest, ParseTokenOwnerRecordResponse,
+};
+
+use super::state::{
+    is_governance, proposal_info, realm_info, token_owner_record_info, GOVERNANCE_PROGRAM_ID,
+    PARENT_OFFSET, PROPOSAL_V2,
+};
+use crate::api::common::min_context_slot::{get_account, min_context_slot, read_error_status};
+use crate::api::transaction::v1::service_impl::commitment_level_to_config;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+
+/// Read-only SPL Governance service implementation
+#[derive(Clone)]
+pub struct GovernanceProgramServiceImpl {
+    /// Solana RPC client for reading governance accounts
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+}
+
+impl GovernanceProgramServiceImpl {
+    /// Creates a new instance of the SPL Governance service with the provided RPC client
+    /// and RPC concurrency limiter.
+    pub const fn new(rpc_client: Arc<RpcClient>, rpc_limiter: Arc<RpcLimiter>) -> Self {
+        Self {
+            rpc_client,
+            rpc_limiter,
+        }
+    }
+
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
+    }
+
+    /// Reads an account owned by the governance program `program_id`
+    async fn read_governance_account(
+        &self,
+        address: &Pubkey,
+        program_id: &Pubkey,
+        min_slot: u64,
+    ) -> Result<Account, Status> {
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let account = get_account(
+            &self.rpc_client,
+            address,
+            CommitmentConfig::confirmed(),
+            min_context_slot(min_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
+        if account.owner != *program_id {
+            return Err(Status::invalid_argument(format!(
+                "Account is not owned by the governance program {program_id}"
+            )));
+        }
+        Ok(account)
+    }
+
+    /// Lists the accounts of `program_id` matching `filters`
+    async fn program_accounts(
+        &self,
+        program_id: &Pubkey,
+        filters: Vec<RpcFilterType>,
+        data_slice: Option<UiDataSliceConfig>,
+        commitment: CommitmentConfig,
+    ) -> Result<Vec<(Pubkey, Account)>, Status> {
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        self.rpc_client
+            .get_program_accounts_with_config(
+                program_id,
+                RpcProgramAccountsConfig {
+                    filters: Some(filters),
+                    account_config: RpcAccountInfoConfig {
+                        encoding: Some(UiAccountEncoding::Base64),
+                        data_slice,
+                        commitment: Some(commitment),
+                        ..Default::default()
+                    },
+                    ..Default::default()
+                },
+            )
+            .map_err(|e| read_error_status(&e, "Failed to get governance accounts"))
+    }
+}
+
+/// Parses a required address field of a request
+#[allow(clippy::result_large_err)]
+fn parse_address(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} address is required")));
+    }
+    Pubkey::from_str(value)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} address: {e}")))
+}
+
+/// Parses the requested governance program, defaulting to the main deployment
+#[allow(clippy::result_large_err)]
+fn parse_program_id(value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Ok(GOVERNANCE_PROGRAM_ID);
+    }
+    parse_address("Program", value)
+}
+
+#[tonic::async_trait]
+impl GovernanceProgramService for GovernanceProgramServiceImpl {
+    /// Reads and decodes a realm.
+    async fn parse_realm(
+        &self,
+        request: Request<ParseRealmRequest>,
+    ) -> Result<Response<ParseRealmResponse>, Status> {
+        let req = request.into_inner();
+
+        let address = parse_address("Account", &req.account_address)?;
+        let program_id = parse_program_id(&req.program_id)?;
+        let account = self
+            .read_governance_account(&address, &program_id, req.min_context_slot)
+            .await?;
+
+        let realm =
+            realm_info(&program_id, &address, &account.data).map_err(Status::invalid_argument)?;
+        Ok(Response::new(ParseRealmResponse { realm: Some(realm) }))
+    }
+
+    /// Reads and decodes a proposal.
+    async fn parse_proposal(
+        &self,
+        request: Request<ParseProposalRequest>,
+    ) -> Result<Response<ParseProposalResponse>, Status> {
+        let req = request.into_inner();
+
+        let address = parse_address("Account", &req.account_address)?;
+        let program_id = parse_program_id(&req.program_id)?;
+        let account = self
+            .read_governance_account(&address, &program_id, req.min_context_slot)
+            .await?;
+
+        let proposal = proposal_info(&program_id, &address, &account.data)
+            .map_err(Status::invalid_argument)?;
+        Ok(Response::new(ParseProposalResponse {
+            proposal: Some(proposal),
+        }))
+    }
+
+    /// Reads and decodes a token owner record.
+    async fn parse_token_owner_record(
+        &self,
+        request: Request<ParseTokenOwnerRecordRequest>,
+    ) -> Result<Response<ParseTokenOwnerRecordResponse>, Status> {
+        let req = request.into_inner();
+
+        let address = parse_address("Account", &req.account_address)?;
+        let program_id = parse_program_id(&req.program_id)?;
+        let account = self
+            .read_governance_account(&address, &program_id, req.min_context_slot)
+            .await?;
+
+        let token_owner_record = token_owner_record_info(&program_id, &address, &account.data)
+            .map_err(Status::invalid_argument)?;
+        Ok(Response::new(ParseTokenOwnerRecordResponse {
+            token_owner_record: Some(token_owner_record),
+        }))
+    }
+
+    /// Lists the proposals of a governance or realm, newest draft first.
+    async fn list_proposals(
+        &self,
+        request: Request<ListProposalsRequest>,
+    ) -> Result<Response<ListProposalsResponse>, Status> {
+        let req = request.into_inner();
+
+        let program_id = parse_program_id(&req.program_id)?;
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        let governances = match (req.governance.is_empty(), req.realm.is_empty()) {
+            (false, true) => vec![parse_address("Governance", &req.governance)?],
+            (true, false) => {
+                let realm = parse_address("Realm", &req.realm)?;
+                // Only the discriminator is needed to tell governances from the realm's
+                // token owner records
+                self.program_accounts(
+                    &program_id,
+                    vec![RpcFilterType::Memcmp(Memcmp::new_base58_encoded(
+                        PARENT_OFFSET,
+                        realm.as_ref(),
+                    ))],
+                    Some(UiDataSliceConfig {
+                        offset: 0,
+                        length: 1,
+                    }),
+                    commitment,
+                )
+                .await?
+                .into_iter()
+                .filter(|(_, account)| account.data.first().copied().is_some_and(is_governance))
+                .map(|(address, _)| address)
+                .collect()
+            }
+            _ => {
+                return Err(Status::invalid_argument(
+                    "Exactly one of governance and realm is required",
+                ))
+            }
+        };
+
+        let mut proposals = Vec::new();
+        for governance in governances {
+            let accounts = self
+                .program_accounts(
+                    &program_id,
+                    vec![
+                        RpcFilterType::Memcmp(Memcmp::new_base58_encoded(0, &[PROPOSAL_V2])),
+                        RpcFilterType::Memcmp(Memcmp::new_base58_encoded(
+                            PARENT_OFFSET,
+                            governance.as_ref(),
+                        )),
+                    ],
+                    None,
+                    commitment,
+                )
+                .await?;
+            for (address, account) in accounts {
+                let proposal = proposal_info(&program_id, &address, &account.data)
+                    .map_err(Status::internal)?;
+                if req.states.is_empty() || req.states.contains(&proposal.state) {
+                    proposals.push(proposal);
+                }
+            }
+        }
+        proposals.sort_by(|a, b| b.draft_at.cmp(&a.draft_at));
+
+        Ok(Response::new(ListProposalsResponse { proposals }))
+    }
+}
@@ -0,0 +1,496 @@
+use protochain_api::protochain::solana::program::governance::v1::{
+    MaxVoterWeightSource, OptionVoteResult, ProposalInfo, ProposalOption, ProposalState, RealmInfo,
+    TokenOwnerRecordInfo, VoteThreshold, VoteThresholdType, VoteType,
+};
+use solana_sdk::{pubkey, pubkey::Pubkey};
+
+/// Main deployment of the SPL Governance program
+pub const GOVERNANCE_PROGRAM_ID: Pubkey = pubkey!("GovER5Lthms3bLBqWub97yVrMmEogzX7xNjdXpPPCVZw");
+
+/// `GovernanceAccountType` discriminators, the first byte of every governance account
+const REALM_V1: u8 = 1;
+const TOKEN_OWNER_RECORD_V1: u8 = 2;
+const GOVERNANCE_V1: u8 = 3;
+const PROGRAM_GOVERNANCE_V1: u8 = 4;
+const MINT_GOVERNANCE_V1: u8 = 9;
+const TOKEN_GOVERNANCE_V1: u8 = 10;
+/// Discriminator of the proposals this service decodes
+pub const PROPOSAL_V2: u8 = 14;
+const REALM_V2: u8 = 16;
+const TOKEN_OWNER_RECORD_V2: u8 = 17;
+const GOVERNANCE_V2: u8 = 18;
+const PROGRAM_GOVERNANCE_V2: u8 = 19;
+const MINT_GOVERNANCE_V2: u8 = 20;
+const TOKEN_GOVERNANCE_V2: u8 = 21;
+
+/// Offset of the realm in governance accounts and of the governance in proposals, right
+/// after the discriminator
+pub const PARENT_OFFSET: usize = 1;
+
+/// Whether an account discriminator denotes a governance (of any kind), whose realm is
+/// stored at `PARENT_OFFSET`
+pub const fn is_governance(account_type: u8) -> bool {
+    matches!(
+        account_type,
+        GOVERNANCE_V1
+            | PROGRAM_GOVERNANCE_V1
+            | MINT_GOVERNANCE_V1
+            | TOKEN_GOVERNANCE_V1
+            | GOVERNANCE_V2
+            | PROGRAM_GOVERNANCE_V2
+            | MINT_GOVERNANCE_V2
+            | TOKEN_GOVERNANCE_V2
+    )
+}
+
+/// Decodes a `RealmV1` or `RealmV2` account
+pub fn realm_info(program_id: &Pubkey, address: &Pubkey, data: &[u8]) -> Result<RealmInfo, String> {
+    let mut reader = Reader::new(data);
+    match reader.u8()? {
+        REALM_V1 | REALM_V2 => {}
+        _ => return Err(format!("Account {address} is not a governance realm")),
+    }
+    let community_mint = reader.pubkey()?;
+    // RealmConfig: two legacy flags and reserved bytes precede the governance threshold
+    reader.take(8)?;
+    let min_community_weight_to_create_governance = reader.u64()?;
+    let community_max_voter_weight_source = match reader.u8()? {
+        0 => MaxVoterWeightSource::SupplyFraction,
+        1 => MaxVoterWeightSource::Absolute,
+        other => return Err(format!("Unknown max voter weight source {other}")),
+    };
+    let community_max_voter_weight_value = reader.u64()?;
+    let council_mint = reader.option(Reader::pubkey)?;
+    // Reserved bytes and the legacy voting proposal count
+    reader.take(8)?;
+    let authority = reader.option(Reader::pubkey)?;
+    let name = reader.string()?;
+
+    Ok(RealmInfo {
+        address: address.to_string(),
+        program_id: program_id.to_string(),
+        name,
+        community_mint: community_mint.to_string(),
+        council_mint: optional_key(council_mint),
+        authority: optional_key(authority),
+        min_community_weight_to_create_governance,
+        community_max_voter_weight_source: community_max_voter_weight_source.into(),
+        community_max_voter_weight_value,
+    })
+}
+
+/// Decodes a `TokenOwnerRecordV1` or `TokenOwnerRecordV2` account
+pub fn token_owner_record_info(
+    program_id: &Pubkey,
+    address: &Pubkey,
+    data: &[u8],
+) -> Result<TokenOwnerRecordInfo, String> {
+    let mut reader = Reader::new(data);
+    let account_type = reader.u8()?;
+    if !matches!(account_type, TOKEN_OWNER_RECORD_V1 | TOKEN_OWNER_RECORD_V2) {
+        return Err(format!("Account {address} is not a governance token owner record"));
+    }
+    let realm = reader.pubkey()?;
+    let governing_token_mint = reader.pubkey()?;
+    let governing_token_owner = reader.pubkey()?;
+    let governing_token_deposit_amount = reader.u64()?;
+    // V1 records count unrelinquished and total votes as two u32s where V2 holds one u64
+    let unrelinquished_votes_count = if account_type == TOKEN_OWNER_RECORD_V1 {
+        let count = u64::from(reader.u32()?);
+        reader.u32()?;
+        count
+    } else {
+        reader.u64()?
+    };
+    let outstanding_proposal_count = reader.u8()?;
+    // Version and reserved bytes
+    reader.take(7)?;
+    let governance_delegate = reader.option(Reader::pubkey)?;
+
+    Ok(TokenOwnerRecordInfo {
+        address: address.to_string(),
+        program_id: program_id.to_string(),
+        realm: realm.to_string(),
+        governing_token_mint: governing_token_mint.to_string(),
+        governing_token_owner: governing_token_owner.to_string(),
+        governing_token_deposit_amount,
+        unrelinquished_votes_count,
+        outstanding_proposal_count: u32::from(outstanding_proposal_count),
+        governance_delegate: optional_key(governance_delegate),
+    })
+}
+
+/// Decodes a `ProposalV2` account in the layout of governance program v3
+pub fn proposal_info(
+    program_id: &Pubkey,
+    address: &Pubkey,
+    data: &[u8],
+) -> Result<ProposalInfo, String> {
+    let mut reader = Reader::new(data);
+    if reader.u8()? != PROPOSAL_V2 {
+        return Err(format!("Account {address} is not a governance proposal (V2)"));
+    }
+    let governance = reader.pubkey()?;
+    let governing_token_mint = reader.pubkey()?;
+    let state = proposal_state(reader.u8()?)?;
+    let token_owner_record = reader.pubkey()?;
+    let signatories_count = reader.u8()?;
+    let signatories_signed_off_count = reader.u8()?;
+    let vote_type = match reader.u8()? {
+        0 => VoteType::SingleChoice,
+        1 => {
+            // Choice type, min and max voter options, max winning options
+            reader.take(4)?;
+            VoteType::MultiChoice
+        }
+        other => return Err(format!("Unknown vote type {other}")),
+    };
+    let option_count = reader.u32()?;
+    let options = (0..option_count)
+        .map(|_| proposal_option(&mut reader))
+        .collect::<Result<Vec<_>, _>>()?;
+    let deny_vote_weight = reader.option(Reader::u64)?;
+    // Reserved
+    reader.u8()?;
+    let abstain_vote_weight = reader.option(Reader::u64)?;
+    // Start voting at
+    reader.option(Reader::i64)?;
+    let draft_at = reader.i64()?;
+    let signing_off_at = reader.option(Reader::i64)?;
+    let voting_at = reader.option(Reader::i64)?;
+    let voting_at_slot = reader.option(Reader::u64)?;
+    let voting_completed_at = reader.option(Reader::i64)?;
+    let executing_at = reader.option(Reader::i64)?;
+    let closed_at = reader.option(Reader::i64)?;
+    // Execution flags
+    reader.u8()?;
+    let max_vote_weight = reader.option(Reader::u64)?;
+    // Max voting time
+    reader.option(Reader::u32)?;
+    let vote_threshold = reader.option(vote_threshold)?;
+    // Reserved
+    reader.take(64)?;
+    let name = reader.string()?;
+    let description_link = reader.string()?;
+    let veto_vote_weight = reader.u64()?;
+
+    Ok(ProposalInfo {
+        address: address.to_string(),
+        program_id: program_id.to_string(),
+        governance: governance.to_string(),
+        governing_token_mint: governing_token_mint.to_string(),
+        token_owner_record: token_owner_record.to_string(),
+        name,
+        description_link,
+        state: state.into(),
+        vote_type: vote_type.into(),
+        options,
+        deny_vote_weight,
+        abstain_vote_weight,
+        veto_vote_weight,
+        max_vote_weight,
+        vote_threshold,
+        signatories_count: u32::from(signatories_count),
+        signatories_signed_off_count: u32::from(signatories_signed_off_count),
+        draft_at,
+        signing_off_at,
+        voting_at,
+        voting_completed_at,
+        executing_at,
+        closed_at,
+        voting_at_slot,
+    })
+}
+
+/// Converts an on-chain `ProposalState`
+fn proposal_state(state: u8) -> Result<ProposalState, String> {
+    Ok(match state {
+        0 => ProposalState::Draft,
+        1 => ProposalState::SigningOff,
+        2 => ProposalState::Voting,
+        3 => ProposalState::Succeeded,
+        4 => ProposalState::Executing,
+        5 => ProposalState::Completed,
+        6 => ProposalState::Cancelled,
+        7 => ProposalState::Defeated,
+        8 => ProposalState::ExecutingWithErrors,
+        9 => ProposalState::Vetoed,
+        other => return Err(format!("Unknown proposal state {other}")),
+    })
+}
+
+/// Decodes a `ProposalOption`
+fn proposal_option(reader: &mut Reader) -> Result<ProposalOption, String> {
+    let label = reader.string()?;
+    let vote_weight = reader.u64()?;
+    let vote_result = match reader.u8()? {
+        0 => OptionVoteResult::None,
+        1 => OptionVoteResult::Succeeded,
+        2 => OptionVoteResult::Defeated,
+        other => return Err(format!("Unknown option vote result {other}")),
+    };
+    let transactions_executed_count = reader.u16()?;
+    let transactions_count = reader.u16()?;
+    // Next transaction index
+    reader.u16()?;
+
+    Ok(ProposalOption {
+        label,
+        vote_weight,
+        vote_result: vote_result.into(),
+        transactions_count: u32::from(transactions_count),
+        transactions_executed_count: u32::from(transactions_executed_count),
+    })
+}
+
+/// Decodes a `VoteThreshold`
+fn vote_threshold(reader: &mut Reader) -> Result<VoteThreshold, String> {
+    let (threshold_type, percentage) = match reader.u8()? {
+        0 => (VoteThresholdType::YesVotePercentage, reader.u8()?),
+        1 => (VoteThresholdType::QuorumPercentage, reader.u8()?),
+        2 => (VoteThresholdType::Disabled, 0),
+        other => return Err(format!("Unknown vote threshold {other}")),
+    };
+    Ok(VoteThreshold {
+        r#type: threshold_type.into(),
+        percentage: u32::from(percentage),
+    })
+}
+
+/// Renders an optional key, empty when unset
+fn optional_key(key: Option<Pubkey>) -> String {
+    key.map(|key| key.to_string()).unwrap_or_default()
+}
+
+/// Cursor over borsh-encoded governance account data
+struct Reader<'a> {
+    data: &'a [u8],
+    offset: usize,
+}
+
+impl<'a> Reader<'a> {
+    const fn new(data: &'a [u8]) -> Self {
+        Self { data, offset: 0 }
+    }
+
+    fn take(&mut self, len: usize) -> Result<&'a [u8], String> {
+        let end = self
+            .offset
+            .checked_add(len)
+            .filter(|end| *end <= self.data.len())
+            .ok_or_else(|| "Governance account is truncated".to_string())?;
+        let bytes = &self.data[self.offset..end];
+        self.offset = end;
+        Ok(bytes)
+    }
+
+    fn array<const N: usize>(&mut self) -> Result<[u8; N], String> {
+        self.take(N)?
+            .try_into()
+            .map_err(|_| "Governance account is truncated".to_string())
+    }
+
+    fn u8(&mut self) -> Result<u8, String> {
+        Ok(self.take(1)?[0])
+    }
+
+    fn u16(&mut self) -> Result<u16, String> {
+        self.array().map(u16::from_le_bytes)
+    }
+
+    fn u32(&mut self) -> Result<u32, String> {
+        self.array().map(u32::from_le_bytes)
+    }
+
+    fn u64(&mut self) -> Result<u64, String> {
+        self.array().map(u64::from_le_bytes)
+    }
+
+    fn i64(&mut self) -> Result<i64, String> {
+        self.array().map(i64::from_le_bytes)
+    }
+
+    fn pubkey(&mut self) -> Result<Pubkey, String> {
+        self.array().map(Pubkey::new_from_array)
+    }
+
+    fn string(&mut self) -> Result<String, String> {
+        let len = usize::try_from(self.u32()?)
+            .map_err(|_| "Governance account string is too long".to_string())?;
+        String::from_utf8(self.take(len)?.to_vec())
+            .map_err(|e| format!("Governance account string is not UTF-8: {e}"))
+    }
+
+    /// Reads a borsh `Option`: a presence byte followed by the value when present
+    fn option<T>(
+        &mut self,
+        read: impl FnOnce(&mut Self) -> Result<T, String>,
+    ) -> Result<Option<T>, String> {
+        match self.u8()? {
+            0 => Ok(None),
+            1 => read(self).map(Some),
+            other => Err(format!("Invalid option tag {other}")),
+        }
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn string(value: &str) -> Vec<u8> {
+        let mut out = u32::try_from(value.len()).unwrap().to_le_bytes().to_vec();
+        out.extend_from_slice(value.as_bytes());
+        out
+    }
+
+    fn some_key(key: &Pubkey) -> Vec<u8> {
+        let mut out = vec![1];
+        out.extend_from_slice(key.as_ref());
+        out
+    }
+
+    fn some_u64(value: u64) -> Vec<u8> {
+        let mut out = vec![1];
+        out.extend_from_slice(&value.to_le_bytes());
+        out
+    }
+
+    #[test]
+    fn test_realm_info() {
+        let address = Pubkey::new_unique();
+        let community_mint = Pubkey::new_unique();
+        let council_mint = Pubkey::new_unique();
+
+        let mut data = vec![REALM_V2];
+        data.extend_from_slice(community_mint.as_ref());
+        data.extend_from_slice(&[0; 8]);
+        data.extend_from_slice(&1_000u64.to_le_bytes());
+        data.push(0);
+        data.extend_from_slice(&10_000_000_000u64.to_le_bytes());
+        data.extend(some_key(&council_mint));
+        data.extend_from_slice(&[0; 8]);
+        data.push(0);
+        data.extend(string("Example DAO"));
+        data.extend_from_slice(&[0; 128]);
+
+        let realm = realm_info(&GOVERNANCE_PROGRAM_ID, &address, &data).unwrap();
+        assert_eq!(realm.name, "Example DAO");
+        assert_eq!(realm.community_mint, community_mint.to_string());
+        assert_eq!(realm.council_mint, council_mint.to_string());
+        assert_eq!(realm.authority, "");
+        assert_eq!(realm.min_community_weight_to_create_governance, 1_000);
+        assert_eq!(realm.community_max_voter_weight_source(), MaxVoterWeightSource::SupplyFraction);
+        assert_eq!(realm.community_max_voter_weight_value, 10_000_000_000);
+
+        data[0] = TOKEN_OWNER_RECORD_V2;
+        assert!(realm_info(&GOVERNANCE_PROGRAM_ID, &address, &data).is_err());
+    }
+
+    #[test]
+    fn test_token_owner_record_versions() {
+        let address = Pubkey::new_unique();
+        let realm = Pubkey::new_unique();
+        let delegate = Pubkey::new_unique();
+
+        let record = |account_type: u8| {
+            let mut data = vec![account_type];
+            data.extend_from_slice(realm.as_ref());
+            data.extend_from_slice(Pubkey::new_unique().as_ref());
+            data.extend_from_slice(Pubkey::new_unique().as_ref());
+            data.extend_from_slice(&500u64.to_le_bytes());
+            if account_type == TOKEN_OWNER_RECORD_V1 {
+                data.extend_from_slice(&3u32.to_le_bytes());
+                data.extend_from_slice(&9u32.to_le_bytes());
+            } else {
+                data.extend_from_slice(&3u64.to_le_bytes());
+            }
+            data.push(2);
+            data.extend_from_slice(&[0; 7]);
+            data.extend(some_key(&delegate));
+            data
+        };
+
+        for account_type in [TOKEN_OWNER_RECORD_V1, TOKEN_OWNER_RECORD_V2] {
+            let info =
+                token_owner_record_info(&GOVERNANCE_PROGRAM_ID, &address, &record(account_type))
+                    .unwrap();
+            assert_eq!(info.realm, realm.to_string());
+            assert_eq!(info.governing_token_deposit_amount, 500);
+            assert_eq!(info.unrelinquished_votes_count, 3);
+            assert_eq!(info.outstanding_proposal_count, 2);
+            assert_eq!(info.governance_delegate, delegate.to_string());
+        }
+    }
+
+    #[test]
+    fn test_proposal_info() {
+        let address = Pubkey::new_unique();
+        let governance = Pubkey::new_unique();
+
+        let mut data = vec![PROPOSAL_V2];
+        data.extend_from_slice(governance.as_ref());
+        data.extend_from_slice(Pubkey::new_unique().as_ref());
+        data.push(5);
+        data.extend_from_slice(Pubkey::new_unique().as_ref());
+        data.extend_from_slice(&[1, 1]);
+        data.push(0);
+        data.extend_from_slice(&1u32.to_le_bytes());
+        data.extend(string("Approve"));
+        data.extend_from_slice(&700u64.to_le_bytes());
+        data.push(1);
+        data.extend_from_slice(&1u16.to_le_bytes());
+        data.extend_from_slice(&1u16.to_le_bytes());
+        data.extend_from_slice(&1u16.to_le_bytes());
+        data.extend(some_u64(200));
+        data.push(0);
+        data.push(0);
+        data.push(0);
+        data.extend_from_slice(&1_700_000_000i64.to_le_bytes());
+        data.extend(some_u64(1_700_000_100));
+        data.extend(some_u64(1_700_000_200));
+        data.extend(some_u64(250_000_000));
+        data.extend(some_u64(1_700_100_000));
+        data.extend(some_u64(1_700_100_100));
+        data.extend(some_u64(1_700_100_200));
+        data.push(0);
+        data.extend(some_u64(1_000));
+        data.push(0);
+        data.extend_from_slice(&[1, 0, 60]);
+        data.extend_from_slice(&[0; 64]);
+        data.extend(string("Fund the grants program"));
+        data.extend(string("https://example.com/proposal"));
+        data.extend_from_slice(&0u64.to_le_bytes());
+
+        let proposal = proposal_info(&GOVERNANCE_PROGRAM_ID, &address, &data).unwrap();
+        assert_eq!(proposal.governance, governance.to_string());
+        assert_eq!(proposal.state(), ProposalState::Completed);
+        assert_eq!(proposal.vote_type(), VoteType::SingleChoice);
+        assert_eq!(proposal.options.len(), 1);
+        assert_eq!(proposal.options[0].label, "Approve");
+        assert_eq!(proposal.options[0].vote_weight, 700);
+        assert_eq!(proposal.options[0].vote_result(), OptionVoteResult::Succeeded);
+        assert_eq!(proposal.deny_vote_weight, Some(200));
+        assert_eq!(proposal.abstain_vote_weight, None);
+        assert_eq!(proposal.draft_at, 1_700_000_000);
+        assert_eq!(proposal.voting_at_slot, Some(250_000_000));
+        assert_eq!(proposal.closed_at, Some(1_700_100_200));
+        assert_eq!(proposal.max_vote_weight, Some(1_000));
+        let threshold = proposal.vote_threshold.unwrap();
+        assert_eq!(threshold.r#type(), VoteThresholdType::YesVotePercentage);
+        assert_eq!(threshold.percentage, 60);
+        assert_eq!(proposal.name, "Fund the grants program");
+        assert_eq!(proposal.description_link, "https://example.com/proposal");
+
+        assert!(proposal_info(&GOVERNANCE_PROGRAM_ID, &address, &data[..200]).is_err());
+    }
+
+    #[test]
+    fn test_is_governance() {
+        assert!(is_governance(GOVERNANCE_V2));
+        assert!(is_governance(MINT_GOVERNANCE_V1));
+        assert!(!is_governance(PROPOSAL_V2));
+        assert!(!is_governance(REALM_V2));
+    }
+}
@@ -1,7 +1,16 @@
 use std::sync::Arc;
 
+use super::address_lookup_table::AddressLookupTableV1API;
+use super::ata::AtaV1API;
+use super::bpf_loader::BpfLoaderV1API;
+use super::compute_budget::ComputeBudgetV1API;
+use super::governance::GovernanceV1API;
+use super::memo::MemoV1API;
+use super::metadata::MetadataV1API;
+use super::stake::StakeV1API;
 use super::system::System;
 use super::token::TokenV1API;
+use super::vote::VoteV1API;
 use crate::service_providers::ServiceProviders;
 
 /// Program services aggregator that provides access to all Solana program interfaces
@@ -10,6 +19,24 @@ pub struct Program {
     pub system: Arc<System>,
     /// Token program service interface
     pub token: Arc<TokenV1API>,
+    /// Associated Token Account program service interface
+    pub ata: Arc<AtaV1API>,
+    /// Memo program service interface
+    pub memo: Arc<MemoV1API>,
+    /// Compute Budget program service interface
+    pub compute_budget: Arc<ComputeBudgetV1API>,
+    /// Stake program service interface
+    pub stake: Arc<StakeV1API>,
+    /// Address Lookup Table program service interface
+    pub address_lookup_table: Arc<AddressLookupTableV1API>,
+    /// Vote program service interface
+    pub vote: Arc<VoteV1API>,
+    /// Token Metadata service interface
+    pub metadata: Arc<MetadataV1API>,
+    /// SPL Governance program service interface
+    pub governance: Arc<GovernanceV1API>,
+    /// Upgradeable BPF Loader program service interface
+    pub bpf_loader: Arc<BpfLoaderV1API>,
 }
 
 impl Program {
@@ -18,6 +45,15 @@ impl Program {
         Self {
             system: Arc::new(System::new(service_providers)),
             token: Arc::new(TokenV1API::new(service_providers)),
+            ata: Arc::new(AtaV1API::new(service_providers)),
+            memo: Arc::new(MemoV1API::new(service_providers)),
+            compute_budget: Arc::new(ComputeBudgetV1API::new(service_providers)),
+            stake: Arc::new(StakeV1API::new(service_providers)),
+            address_lookup_table: Arc::new(AddressLookupTableV1API::new(service_providers)),
+            vote: Arc::new(VoteV1API::new(service_providers)),
+            metadata: Arc::new(MetadataV1API::new(service_providers)),
+            governance: Arc::new(GovernanceV1API::new(service_providers)),
+            bpf_loader: Arc::new(BpfLoaderV1API::new(service_providers)),
         }
     }
 }
@@ -0,0 +1,4 @@
+/// Memo Program v1 services
+pub mod v1;
+
+pub use v1::memo_v1_api::MemoV1API;
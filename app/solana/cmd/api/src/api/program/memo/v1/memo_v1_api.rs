@@ -0,0 +1,20 @@
+use std::sync::Arc;
+
+use super::service_impl::MemoProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Memo Program API v1 wrapper
+pub struct MemoV1API {
+    /// The Memo Program service implementation
+    pub memo_program_service: Arc<MemoProgramServiceImpl>,
+}
+
+impl MemoV1API {
+    /// Creates a new Memo V1 API instance
+    pub fn new(_service_providers: &Arc<ServiceProviders>) -> Self {
+        // No RPC client needed - memo instructions are built offline
+        Self {
+            memo_program_service: Arc::new(MemoProgramServiceImpl::new()),
+        }
+    }
+}
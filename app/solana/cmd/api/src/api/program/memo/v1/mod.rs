@@ -0,0 +1,4 @@
+/// Memo program API wrapper
+pub mod memo_v1_api;
+/// Memo program service implementation
+pub mod service_impl;
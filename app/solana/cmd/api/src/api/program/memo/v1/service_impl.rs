@@ -0,0 +1,61 @@
+use solana_sdk::pubkey::Pubkey;
+use std::str::FromStr;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::memo::v1::{
+    service_server::Service as MemoProgramService, MemoRequest,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::api::transaction::v1::memo::signed_memo_instruction;
+
+/// Pure instruction-based Memo Program service implementation.
+///
+/// Memos are built offline; no RPC client is needed.
+#[derive(Clone, Default)]
+pub struct MemoProgramServiceImpl {}
+
+impl MemoProgramServiceImpl {
+    /// Creates a new instance of the Memo Program service.
+    pub const fn new() -> Self {
+        Self {}
+    }
+}
+
+#[tonic::async_trait]
+impl MemoProgramService for MemoProgramServiceImpl {
+    /// Creates a memo instruction, signed by the requested signers.
+    async fn memo(
+        &self,
+        request: Request<MemoRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        if req.text.is_empty() {
+            return Err(Status::invalid_argument("Memo text is required"));
+        }
+        let mut signers: Vec<Pubkey> = Vec::with_capacity(req.signers.len());
+        for signer in &req.signers {
+            let signer = Pubkey::from_str(signer).map_err(|e| {
+                Status::invalid_argument(format!("Invalid signer address {signer}: {e}"))
+            })?;
+            if signers.contains(&signer) {
+                return Err(Status::invalid_argument(format!("Duplicate signer {signer}")));
+            }
+            signers.push(signer);
+        }
+
+        let instruction =
+            signed_memo_instruction(&req.text, &signers).map_err(Status::invalid_argument)?;
+
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = if signers.is_empty() {
+            format!("Memo: \"{}\"", req.text)
+        } else {
+            format!("Memo: \"{}\" (signed by {})", req.text, req.signers.join(", "))
+        };
+
+        Ok(Response::new(proto_instruction))
+    }
+}
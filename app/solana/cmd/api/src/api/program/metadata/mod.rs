@@ -0,0 +1,4 @@
+/// Token Metadata v1 services
+pub mod v1;
+
+pub use v1::metadata_v1_api::MetadataV1API;
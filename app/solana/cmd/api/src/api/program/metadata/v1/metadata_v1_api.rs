@@ -0,0 +1,22 @@
+use std::sync::Arc;
+
+use super::service_impl::MetadataServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Token Metadata API v1 wrapper
+pub struct MetadataV1API {
+    /// The Token Metadata service implementation
+    pub metadata_service: Arc<MetadataServiceImpl>,
+}
+
+impl MetadataV1API {
+    /// Creates a new Metadata V1 API instance
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            metadata_service: Arc::new(MetadataServiceImpl::new(
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.rpc_limiter),
+            )),
+        }
+    }
+}
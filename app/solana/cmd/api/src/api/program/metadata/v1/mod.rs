@@ -0,0 +1,4 @@
+/// Token Metadata API wrapper
+pub mod metadata_v1_api;
+/// Token Metadata service implementation
+pub mod service_impl;
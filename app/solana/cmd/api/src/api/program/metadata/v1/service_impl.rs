@@ -0,0 +1,293 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{instruction::Instruction, pubkey::Pubkey};
+use spl_token_2022::{extension::metadata_pointer, ID as TOKEN_2022_PROGRAM_ID};
+use spl_token_metadata_interface::{instruction as metadata_instruction, state::Field};
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::metadata::v1::{
+    service_server::Service as MetadataService, GetTokenMetadataRequest, GetTokenMetadataResponse,
+    InitializeMetadataPointerRequest, InitializeMetadataRequest, MetadataField,
+    RemoveMetadataKeyRequest, TokenMetadataInfo, UpdateMetadataAuthorityRequest,
+    UpdateMetadataFieldRequest, UpdateMetadataPointerRequest,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use crate::api::common::min_context_slot::min_context_slot;
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::api::program::token::v1::metadata::{metadata_to_proto, read_on_chain_metadata};
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+
+/// Token Metadata service implementation.
+///
+/// Instruction builders work offline and always target Token-2022, which stores metadata on
+/// the mint; `GetTokenMetadata` also reads Metaplex metadata of either token program's mints.
+#[derive(Clone)]
+pub struct MetadataServiceImpl {
+    /// Solana RPC client for reading mints and metadata accounts
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+}
+
+impl MetadataServiceImpl {
+    /// Creates a new instance of the Token Metadata service with the provided RPC client
+    /// and RPC concurrency limiter.
+    pub const fn new(rpc_client: Arc<RpcClient>, rpc_limiter: Arc<RpcLimiter>) -> Self {
+        Self {
+            rpc_client,
+            rpc_limiter,
+        }
+    }
+
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
+    }
+}
+
+/// Parses a required address field of a request
+#[allow(clippy::result_large_err)]
+fn parse_address(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} address is required")));
+    }
+    Pubkey::from_str(value)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} address: {e}")))
+}
+
+/// Parses an optional address field of a request, empty meaning unset
+#[allow(clippy::result_large_err)]
+fn parse_optional_address(field: &str, value: &str) -> Result<Option<Pubkey>, Status> {
+    if value.is_empty() {
+        return Ok(None);
+    }
+    parse_address(field, value).map(Some)
+}
+
+/// Converts an instruction and attaches its description
+fn described(instruction: Instruction, description: String) -> SolanaInstruction {
+    let mut proto_instruction = sdk_instruction_to_proto(instruction);
+    proto_instruction.description = description;
+    proto_instruction
+}
+
+#[tonic::async_trait]
+impl MetadataService for MetadataServiceImpl {
+    /// Resolves a mint's on-chain metadata.
+    async fn get_token_metadata(
+        &self,
+        request: Request<GetTokenMetadataRequest>,
+    ) -> Result<Response<GetTokenMetadataResponse>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let resolved = read_on_chain_metadata(
+            &self.rpc_client,
+            &mint,
+            min_context_slot(req.min_context_slot),
+        )?;
+        let metadata = metadata_to_proto(&mint, resolved.metadata);
+
+        Ok(Response::new(GetTokenMetadataResponse {
+            metadata: Some(TokenMetadataInfo {
+                mint: metadata.mint_pub_key,
+                source: metadata.source,
+                metadata_address: resolved.metadata_address.to_string(),
+                update_authority: metadata.update_authority_pub_key,
+                name: metadata.name,
+                symbol: metadata.symbol,
+                uri: metadata.uri,
+                decimals: u32::from(resolved.decimals),
+                additional_metadata: metadata.additional_metadata,
+                token_program_id: resolved.token_program.to_string(),
+            }),
+        }))
+    }
+
+    /// Creates an instruction initializing a mint's metadata pointer.
+    async fn initialize_metadata_pointer(
+        &self,
+        request: Request<InitializeMetadataPointerRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let authority = parse_optional_address("Authority", &req.authority)?;
+        let metadata_address =
+            parse_optional_address("Metadata", &req.metadata_address)?.unwrap_or(mint);
+
+        let instruction = metadata_pointer::instruction::initialize(
+            &TOKEN_2022_PROGRAM_ID,
+            &mint,
+            authority,
+            Some(metadata_address),
+        )
+        .map_err(|e| Status::internal(format!("Failed to build instruction: {e}")))?;
+
+        Ok(Response::new(described(
+            instruction,
+            format!("Point metadata of mint {mint} at {metadata_address}"),
+        )))
+    }
+
+    /// Creates an instruction changing a mint's metadata pointer.
+    async fn update_metadata_pointer(
+        &self,
+        request: Request<UpdateMetadataPointerRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let authority = parse_address("Authority", &req.authority)?;
+        let metadata_address = parse_optional_address("Metadata", &req.metadata_address)?;
+
+        let instruction = metadata_pointer::instruction::update(
+            &TOKEN_2022_PROGRAM_ID,
+            &mint,
+            &authority,
+            &[],
+            metadata_address,
+        )
+        .map_err(|e| Status::internal(format!("Failed to build instruction: {e}")))?;
+
+        let description = metadata_address.map_or_else(
+            || format!("Clear metadata pointer of mint {mint}"),
+            |address| format!("Point metadata of mint {mint} at {address}"),
+        );
+        Ok(Response::new(described(instruction, description)))
+    }
+
+    /// Creates an instruction initializing a mint's metadata.
+    async fn initialize_metadata(
+        &self,
+        request: Request<InitializeMetadataRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let mint_authority = parse_address("Mint authority", &req.mint_authority)?;
+        let update_authority = parse_optional_address("Update authority", &req.update_authority)?
+            .unwrap_or(mint_authority);
+        if req.name.is_empty() {
+            return Err(Status::invalid_argument("Name is required"));
+        }
+
+        let description = format!(
+            "Initialize metadata of mint {mint}: {} ({}) (update authority: {update_authority})",
+            req.name, req.symbol
+        );
+        let instruction = metadata_instruction::initialize(
+            &TOKEN_2022_PROGRAM_ID,
+            &mint,
+            &update_authority,
+            &mint,
+            &mint_authority,
+            req.name,
+            req.symbol,
+            req.uri,
+        );
+
+        Ok(Response::new(described(instruction, description)))
+    }
+
+    /// Creates an instruction setting a field of a mint's metadata.
+    async fn update_metadata_field(
+        &self,
+        request: Request<UpdateMetadataFieldRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let update_authority = parse_address("Update authority", &req.update_authority)?;
+        let field = match MetadataField::try_from(req.field) {
+            Ok(MetadataField::Name) => Field::Name,
+            Ok(MetadataField::Symbol) => Field::Symbol,
+            Ok(MetadataField::Uri) => Field::Uri,
+            Ok(MetadataField::Additional) if !req.key.is_empty() => Field::Key(req.key.clone()),
+            Ok(MetadataField::Additional) => {
+                return Err(Status::invalid_argument("Key is required for additional fields"))
+            }
+            _ => {
+                return Err(Status::invalid_argument(
+                    "field must be NAME, SYMBOL, URI or ADDITIONAL",
+                ))
+            }
+        };
+
+        let field_name = match &field {
+            Field::Name => "name".to_string(),
+            Field::Symbol => "symbol".to_string(),
+            Field::Uri => "uri".to_string(),
+            Field::Key(key) => key.clone(),
+        };
+        let description = format!("Set metadata {field_name} of mint {mint} to \"{}\"", req.value);
+        let instruction = metadata_instruction::update_field(
+            &TOKEN_2022_PROGRAM_ID,
+            &mint,
+            &update_authority,
+            field,
+            req.value,
+        );
+
+        Ok(Response::new(described(instruction, description)))
+    }
+
+    /// Creates an instruction removing an additional key from a mint's metadata.
+    async fn remove_metadata_key(
+        &self,
+        request: Request<RemoveMetadataKeyRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let update_authority = parse_address("Update authority", &req.update_authority)?;
+        if req.key.is_empty() {
+            return Err(Status::invalid_argument("Key is required"));
+        }
+
+        let description = format!("Remove metadata key \"{}\" of mint {mint}", req.key);
+        let instruction = metadata_instruction::remove_key(
+            &TOKEN_2022_PROGRAM_ID,
+            &mint,
+            &update_authority,
+            req.key,
+            req.idempotent,
+        );
+
+        Ok(Response::new(described(instruction, description)))
+    }
+
+    /// Creates an instruction replacing or removing a mint's metadata update authority.
+    async fn update_metadata_authority(
+        &self,
+        request: Request<UpdateMetadataAuthorityRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let mint = parse_address("Mint", &req.mint)?;
+        let update_authority = parse_address("Update authority", &req.update_authority)?;
+        let new_authority = parse_optional_address("New authority", &req.new_authority)?;
+
+        let instruction = metadata_instruction::update_authority(
+            &TOKEN_2022_PROGRAM_ID,
+            &mint,
+            &update_authority,
+            new_authority
+                .try_into()
+                .map_err(|e| Status::invalid_argument(format!("Invalid new authority: {e}")))?,
+        );
+
+        let description = new_authority.map_or_else(
+            || format!("Make metadata of mint {mint} immutable"),
+            |authority| format!("Set metadata update authority of mint {mint} to {authority}"),
+        );
+        Ok(Response::new(described(instruction, description)))
+    }
+}
@@ -3,11 +3,29 @@
 //! This module provides interfaces for interacting with various Solana programs.
 //! Currently supports the System Program with plans to expand to other programs.
 
+/// Address Lookup Table program specific services and operations
+pub mod address_lookup_table;
+/// Associated Token Account program specific services and operations
+pub mod ata;
+/// Upgradeable BPF Loader program specific services and operations
+pub mod bpf_loader;
+/// Compute Budget program specific services and operations
+pub mod compute_budget;
+/// SPL Governance program specific services and operations
+pub mod governance;
 /// Program services aggregator and coordinator
 pub mod manager;
+/// Memo program specific services and operations
+pub mod memo;
+/// Token Metadata services (Token-2022 metadata extension and Metaplex)
+pub mod metadata;
+/// Stake program specific services and operations
+pub mod stake;
 /// System program specific services and operations
 pub mod system;
 /// Token program specific services and operations
 pub mod token;
+/// Vote program specific services and operations
+pub mod vote;
 
 pub use manager::Program;
@@ -0,0 +1,4 @@
+/// Stake Program v1 services
+pub mod v1;
+
+pub use v1::stake_v1_api::StakeV1API;
@@ -0,0 +1,6 @@
+/// Stake program service implementation
+pub mod service_impl;
+/// Stake program API wrapper
+pub mod stake_v1_api;
+/// Stake account decoding
+pub mod state;
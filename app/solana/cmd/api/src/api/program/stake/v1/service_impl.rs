@@ -0,0 +1,331 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    commitment_config::CommitmentConfig,
+    instruction::Instruction,
+    pubkey::Pubkey,
+    stake::{
+        self,
+        state::{Authorized, Lockup, StakeAuthorize as SdkStakeAuthorize},
+    },
+};
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::stake::v1::{
+    service_server::Service as StakeProgramService, AuthorizeRequest, CreateStakeAccountRequest,
+    CreateStakeAccountResponse, DeactivateRequest, DelegateStakeRequest, Lockup as ProtoLockup,
+    MergeRequest, ParseStakeAccountRequest, ParseStakeAccountResponse, SplitRequest, SplitResponse,
+    StakeAuthorize, WithdrawRequest,
+};
+use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
+
+use super::state::{decode_stake_state, stake_account_info};
+use crate::api::common::min_context_slot::{get_account, min_context_slot, read_error_status};
+use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+
+/// Stake Program service implementation.
+///
+/// Instruction builders work offline; only `ParseStakeAccount` reads from the cluster.
+#[derive(Clone)]
+pub struct StakeProgramServiceImpl {
+    /// Solana RPC client for reading stake accounts
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+}
+
+impl StakeProgramServiceImpl {
+    /// Creates a new instance of the Stake Program service with the provided RPC client
+    /// and RPC concurrency limiter.
+    pub const fn new(rpc_client: Arc<RpcClient>, rpc_limiter: Arc<RpcLimiter>) -> Self {
+        Self {
+            rpc_client,
+            rpc_limiter,
+        }
+    }
+
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
+    }
+}
+
+/// Parses a required address field of a request
+#[allow(clippy::result_large_err)]
+fn parse_address(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} address is required")));
+    }
+    Pubkey::from_str(value)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} address: {e}")))
+}
+
+/// Parses an optional address field of a request, empty meaning unset
+#[allow(clippy::result_large_err)]
+fn parse_optional_address(field: &str, value: &str) -> Result<Option<Pubkey>, Status> {
+    if value.is_empty() {
+        return Ok(None);
+    }
+    parse_address(field, value).map(Some)
+}
+
+/// Converts a requested lockup, absent meaning none
+#[allow(clippy::result_large_err)]
+fn parse_lockup(lockup: Option<&ProtoLockup>) -> Result<Lockup, Status> {
+    let Some(lockup) = lockup else {
+        return Ok(Lockup::default());
+    };
+    Ok(Lockup {
+        unix_timestamp: lockup.unix_timestamp,
+        epoch: lockup.epoch,
+        custodian: parse_optional_address("Custodian", &lockup.custodian)?.unwrap_or_default(),
+    })
+}
+
+/// Converts an instruction and attaches its description
+fn described(instruction: Instruction, description: String) -> SolanaInstruction {
+    let mut proto_instruction = sdk_instruction_to_proto(instruction);
+    proto_instruction.description = description;
+    proto_instruction
+}
+
+#[tonic::async_trait]
+impl StakeProgramService for StakeProgramServiceImpl {
+    /// Creates the instructions creating and initializing a stake account.
+    async fn create_stake_account(
+        &self,
+        request: Request<CreateStakeAccountRequest>,
+    ) -> Result<Response<CreateStakeAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let payer = parse_address("Payer", &req.payer)?;
+        let stake_account = parse_address("Stake account", &req.stake_account)?;
+        let staker = parse_optional_address("Staker", &req.staker)?.unwrap_or(payer);
+        let withdrawer = parse_optional_address("Withdrawer", &req.withdrawer)?.unwrap_or(payer);
+        let lockup = parse_lockup(req.lockup.as_ref())?;
+        if req.lamports == 0 {
+            return Err(Status::invalid_argument("Lamports must be greater than zero"));
+        }
+
+        let instructions = stake::instruction::create_account(
+            &payer,
+            &stake_account,
+            &Authorized { staker, withdrawer },
+            &lockup,
+            req.lamports,
+        );
+        let descriptions = [
+            format!(
+                "Create stake account {stake_account} with {} lamports (payer: {payer})",
+                req.lamports
+            ),
+            format!(
+                "Initialize stake account {stake_account} (staker: {staker}, withdrawer: {withdrawer})"
+            ),
+        ];
+
+        Ok(Response::new(CreateStakeAccountResponse {
+            instructions: instructions
+                .into_iter()
+                .zip(descriptions)
+                .map(|(instruction, description)| described(instruction, description))
+                .collect(),
+        }))
+    }
+
+    /// Creates an instruction delegating a stake account to a vote account.
+    async fn delegate_stake(
+        &self,
+        request: Request<DelegateStakeRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let stake_account = parse_address("Stake account", &req.stake_account)?;
+        let vote_account = parse_address("Vote account", &req.vote_account)?;
+        let staker = parse_address("Staker", &req.staker)?;
+
+        Ok(Response::new(described(
+            stake::instruction::delegate_stake(&stake_account, &staker, &vote_account),
+            format!("Delegate stake account {stake_account} to vote account {vote_account}"),
+        )))
+    }
+
+    /// Creates an instruction deactivating a stake account.
+    async fn deactivate(
+        &self,
+        request: Request<DeactivateRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let stake_account = parse_address("Stake account", &req.stake_account)?;
+        let staker = parse_address("Staker", &req.staker)?;
+
+        Ok(Response::new(described(
+            stake::instruction::deactivate_stake(&stake_account, &staker),
+            format!("Deactivate stake account {stake_account}"),
+        )))
+    }
+
+    /// Creates an instruction withdrawing lamports from a stake account.
+    async fn withdraw(
+        &self,
+        request: Request<WithdrawRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let stake_account = parse_address("Stake account", &req.stake_account)?;
+        let withdrawer = parse_address("Withdrawer", &req.withdrawer)?;
+        let destination = parse_address("Destination", &req.destination)?;
+        let custodian = parse_optional_address("Custodian", &req.custodian)?;
+        if req.lamports == 0 {
+            return Err(Status::invalid_argument("Lamports must be greater than zero"));
+        }
+
+        Ok(Response::new(described(
+            stake::instruction::withdraw(
+                &stake_account,
+                &withdrawer,
+                &destination,
+                req.lamports,
+                custodian.as_ref(),
+            ),
+            format!(
+                "Withdraw {} lamports from stake account {stake_account} to {destination}",
+                req.lamports
+            ),
+        )))
+    }
+
+    /// Creates the instructions splitting lamports off a stake account into a new one.
+    async fn split(
+        &self,
+        request: Request<SplitRequest>,
+    ) -> Result<Response<SplitResponse>, Status> {
+        let req = request.into_inner();
+
+        let stake_account = parse_address("Stake account", &req.stake_account)?;
+        let staker = parse_address("Staker", &req.staker)?;
+        let split_stake_account = parse_address("Split stake account", &req.split_stake_account)?;
+        if req.lamports == 0 {
+            return Err(Status::invalid_argument("Lamports must be greater than zero"));
+        }
+        if split_stake_account == stake_account {
+            return Err(Status::invalid_argument(
+                "Split stake account must differ from the stake account",
+            ));
+        }
+
+        let instructions =
+            stake::instruction::split(&stake_account, &staker, req.lamports, &split_stake_account);
+        let description = format!(
+            "Split {} lamports from stake account {stake_account} into {split_stake_account}",
+            req.lamports
+        );
+
+        Ok(Response::new(SplitResponse {
+            instructions: instructions
+                .into_iter()
+                .map(|instruction| described(instruction, description.clone()))
+                .collect(),
+        }))
+    }
+
+    /// Creates an instruction merging one stake account into another.
+    async fn merge(
+        &self,
+        request: Request<MergeRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let destination =
+            parse_address("Destination stake account", &req.destination_stake_account)?;
+        let source = parse_address("Source stake account", &req.source_stake_account)?;
+        let staker = parse_address("Staker", &req.staker)?;
+        if destination == source {
+            return Err(Status::invalid_argument("Cannot merge a stake account into itself"));
+        }
+
+        // The stake program merges with a single instruction
+        let instruction = stake::instruction::merge(&destination, &source, &staker)
+            .into_iter()
+            .next()
+            .ok_or_else(|| Status::internal("Stake program returned no merge instruction"))?;
+
+        Ok(Response::new(described(
+            instruction,
+            format!("Merge stake account {source} into {destination}"),
+        )))
+    }
+
+    /// Creates an instruction replacing a stake account's staker or withdrawer.
+    async fn authorize(
+        &self,
+        request: Request<AuthorizeRequest>,
+    ) -> Result<Response<SolanaInstruction>, Status> {
+        let req = request.into_inner();
+
+        let stake_account = parse_address("Stake account", &req.stake_account)?;
+        let authority = parse_address("Authority", &req.authority)?;
+        let new_authority = parse_address("New authority", &req.new_authority)?;
+        let custodian = parse_optional_address("Custodian", &req.custodian)?;
+        let (stake_authorize, role) = match StakeAuthorize::try_from(req.stake_authorize) {
+            Ok(StakeAuthorize::Staker) => (SdkStakeAuthorize::Staker, "staker"),
+            Ok(StakeAuthorize::Withdrawer) => (SdkStakeAuthorize::Withdrawer, "withdrawer"),
+            _ => {
+                return Err(Status::invalid_argument(
+                    "stake_authorize must be STAKER or WITHDRAWER",
+                ))
+            }
+        };
+
+        Ok(Response::new(described(
+            stake::instruction::authorize(
+                &stake_account,
+                &authority,
+                &new_authority,
+                stake_authorize,
+                custodian.as_ref(),
+            ),
+            format!("Set {role} of stake account {stake_account} to {new_authority}"),
+        )))
+    }
+
+    /// Reads and decodes a stake account.
+    async fn parse_stake_account(
+        &self,
+        request: Request<ParseStakeAccountRequest>,
+    ) -> Result<Response<ParseStakeAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let address = parse_address("Account", &req.account_address)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let account = get_account(
+            &self.rpc_client,
+            &address,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
+        if account.owner != stake::program::id() {
+            return Err(Status::invalid_argument("Account is not owned by the stake program"));
+        }
+        let state =
+            decode_stake_state(&address, &account.data).map_err(Status::invalid_argument)?;
+        let epoch = self
+            .rpc_client
+            .get_epoch_info()
+            .map_err(|e| Status::internal(format!("Failed to get epoch info: {e}")))?
+            .epoch;
+
+        Ok(Response::new(ParseStakeAccountResponse {
+            stake_account: Some(stake_account_info(&address, account.lamports, &state, epoch)),
+        }))
+    }
+}
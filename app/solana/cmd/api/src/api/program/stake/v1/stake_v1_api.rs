@@ -0,0 +1,22 @@
+use std::sync::Arc;
+
+use super::service_impl::StakeProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Stake Program API v1 wrapper
+pub struct StakeV1API {
+    /// The Stake Program service implementation
+    pub stake_program_service: Arc<StakeProgramServiceImpl>,
+}
+
+impl StakeV1API {
+    /// Creates a new Stake V1 API instance
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            stake_program_service: Arc::new(StakeProgramServiceImpl::new(
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.rpc_limiter),
+            )),
+        }
+    }
+}
@@ -0,0 +1,145 @@
+use protochain_api::protochain::solana::program::stake::v1::{
+    Delegation, DelegationPhase as ProtoDelegationPhase, Lockup as ProtoLockup, StakeAccountInfo,
+    StakeState,
+};
+use solana_sdk::{
+    clock::Epoch,
+    pubkey::Pubkey,
+    stake::state::{Lockup, Meta, StakeStateV2},
+};
+
+use crate::api::staking::v1::lifecycle::{delegation_phase, DelegationPhase};
+
+/// Decodes stake account data
+pub fn decode_stake_state(address: &Pubkey, data: &[u8]) -> Result<StakeStateV2, String> {
+    bincode::deserialize::<StakeStateV2>(data)
+        .map_err(|e| format!("Invalid stake account {address}: {e}"))
+}
+
+/// Converts a lockup to proto, leaving an unset custodian empty
+pub fn lockup_to_proto(lockup: &Lockup) -> ProtoLockup {
+    ProtoLockup {
+        unix_timestamp: lockup.unix_timestamp,
+        epoch: lockup.epoch,
+        custodian: if lockup.custodian == Pubkey::default() {
+            String::new()
+        } else {
+            lockup.custodian.to_string()
+        },
+    }
+}
+
+const fn phase_to_proto(phase: DelegationPhase) -> ProtoDelegationPhase {
+    match phase {
+        DelegationPhase::Undelegated => ProtoDelegationPhase::Undelegated,
+        DelegationPhase::Activating => ProtoDelegationPhase::Activating,
+        DelegationPhase::Active => ProtoDelegationPhase::Active,
+        DelegationPhase::Deactivating => ProtoDelegationPhase::Deactivating,
+        DelegationPhase::Inactive => ProtoDelegationPhase::Inactive,
+    }
+}
+
+/// Describes a stake account in `current_epoch`
+pub fn stake_account_info(
+    address: &Pubkey,
+    lamports: u64,
+    state: &StakeStateV2,
+    current_epoch: Epoch,
+) -> StakeAccountInfo {
+    let mut info = StakeAccountInfo {
+        address: address.to_string(),
+        lamports,
+        phase: phase_to_proto(delegation_phase(state, current_epoch)).into(),
+        current_epoch,
+        ..Default::default()
+    };
+    let with_meta = |info: &mut StakeAccountInfo, meta: &Meta| {
+        info.rent_exempt_reserve = meta.rent_exempt_reserve;
+        info.staker = meta.authorized.staker.to_string();
+        info.withdrawer = meta.authorized.withdrawer.to_string();
+        info.lockup = Some(lockup_to_proto(&meta.lockup));
+    };
+    match state {
+        StakeStateV2::Uninitialized => info.state = StakeState::Uninitialized.into(),
+        StakeStateV2::RewardsPool => info.state = StakeState::RewardsPool.into(),
+        StakeStateV2::Initialized(meta) => {
+            info.state = StakeState::Initialized.into();
+            with_meta(&mut info, meta);
+        }
+        StakeStateV2::Stake(meta, stake, _) => {
+            info.state = StakeState::Delegated.into();
+            with_meta(&mut info, meta);
+            let delegation = &stake.delegation;
+            info.delegation = Some(Delegation {
+                vote_account: delegation.voter_pubkey.to_string(),
+                stake: delegation.stake,
+                activation_epoch: delegation.activation_epoch,
+                deactivation_epoch: (delegation.deactivation_epoch != Epoch::MAX)
+                    .then_some(delegation.deactivation_epoch),
+                credits_observed: stake.credits_observed,
+            });
+        }
+    }
+    info
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::stake::stake_flags::StakeFlags;
+    use solana_sdk::stake::state::{Authorized, Delegation as SdkDelegation, Stake};
+
+    #[test]
+    fn test_delegated_account_info() {
+        let address = Pubkey::new_unique();
+        let staker = Pubkey::new_unique();
+        let vote_account = Pubkey::new_unique();
+        let meta = Meta {
+            rent_exempt_reserve: 2_282_880,
+            authorized: Authorized::auto(&staker),
+            lockup: Lockup::default(),
+        };
+        let state = StakeStateV2::Stake(
+            meta,
+            Stake {
+                delegation: SdkDelegation {
+                    voter_pubkey: vote_account,
+                    stake: 5_000_000_000,
+                    activation_epoch: 10,
+                    ..SdkDelegation::default()
+                },
+                credits_observed: 42,
+            },
+            StakeFlags::empty(),
+        );
+        let data = bincode::serialize(&state).unwrap();
+
+        let decoded = decode_stake_state(&address, &data).unwrap();
+        let info = stake_account_info(&address, 5_002_282_880, &decoded, 10);
+
+        assert_eq!(info.state, i32::from(StakeState::Delegated));
+        assert_eq!(info.phase, i32::from(ProtoDelegationPhase::Activating));
+        assert_eq!(info.staker, staker.to_string());
+        assert_eq!(info.withdrawer, staker.to_string());
+        assert_eq!(info.lockup.unwrap().custodian, "");
+        let delegation = info.delegation.unwrap();
+        assert_eq!(delegation.vote_account, vote_account.to_string());
+        assert_eq!(delegation.deactivation_epoch, None);
+        assert_eq!(delegation.credits_observed, 42);
+    }
+
+    #[test]
+    fn test_uninitialized_account_info() {
+        let address = Pubkey::new_unique();
+        let info = stake_account_info(&address, 1, &StakeStateV2::Uninitialized, 3);
+        assert_eq!(info.state, i32::from(StakeState::Uninitialized));
+        assert_eq!(info.phase, i32::from(ProtoDelegationPhase::Undelegated));
+        assert!(info.lockup.is_none() && info.delegation.is_none());
+    }
+
+    #[test]
+    fn test_garbage_data_is_rejected() {
+        assert!(decode_stake_state(&Pubkey::new_unique(), &[9]).is_err());
+    }
+}
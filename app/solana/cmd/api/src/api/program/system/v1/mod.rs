@@ -5,6 +5,8 @@
 
 /// Protocol buffer conversion utilities for System Program operations
 pub mod conversion;
+/// Durable nonce account decoding
+pub mod nonce;
 /// Core business logic implementation for System Program operations
 pub mod service_impl;
 /// gRPC service wrapper for System Program v1 API
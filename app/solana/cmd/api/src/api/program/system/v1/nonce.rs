@@ -0,0 +1,76 @@
+use protochain_api::protochain::solana::program::system::v1::NonceAccountInfo;
+use solana_sdk::{
+    nonce::state::{State, Versions},
+    pubkey::Pubkey,
+    rent::Rent,
+};
+
+/// Balance a nonce account is created with when the request sets none: the rent-exempt
+/// minimum under the default rent, which every public cluster uses
+pub fn default_nonce_account_lamports() -> u64 {
+    Rent::default().minimum_balance(State::size())
+}
+
+/// Decodes a nonce account holding `lamports`
+pub fn nonce_account_info(
+    address: &Pubkey,
+    lamports: u64,
+    data: &[u8],
+) -> Result<NonceAccountInfo, String> {
+    let versions = bincode::deserialize::<Versions>(data)
+        .map_err(|e| format!("Invalid nonce account {address}: {e}"))?;
+    let mut info = NonceAccountInfo {
+        address: address.to_string(),
+        lamports,
+        legacy: matches!(versions, Versions::Legacy(_)),
+        ..Default::default()
+    };
+    if let State::Initialized(data) = versions.state() {
+        info.initialized = true;
+        info.authority = data.authority.to_string();
+        info.durable_nonce = data.blockhash().to_string();
+        info.lamports_per_signature = data.get_lamports_per_signature();
+    }
+    Ok(info)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{
+        hash::Hash,
+        nonce::state::{Data, DurableNonce},
+    };
+
+    #[test]
+    fn test_initialized_nonce_account_info() {
+        let address = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let durable_nonce = DurableNonce::from_blockhash(&Hash::new_unique());
+        let versions = Versions::new(State::Initialized(Data::new(authority, durable_nonce, 5000)));
+        let data = bincode::serialize(&versions).unwrap();
+
+        let info = nonce_account_info(&address, 1_447_680, &data).unwrap();
+
+        assert!(info.initialized);
+        assert!(!info.legacy);
+        assert_eq!(info.authority, authority.to_string());
+        assert_eq!(info.durable_nonce, durable_nonce.as_hash().to_string());
+        assert_eq!(info.lamports_per_signature, 5000);
+    }
+
+    #[test]
+    fn test_uninitialized_nonce_account_info() {
+        let data = bincode::serialize(&Versions::new(State::Uninitialized)).unwrap();
+        let info = nonce_account_info(&Pubkey::new_unique(), 0, &data).unwrap();
+        assert!(!info.initialized);
+        assert!(info.authority.is_empty() && info.durable_nonce.is_empty());
+    }
+
+    #[test]
+    fn test_default_lamports_cover_rent() {
+        assert_eq!(State::size(), 80);
+        assert!(default_nonce_account_lamports() > 0);
+    }
+}
@@ -1,24 +1,41 @@
-use solana_sdk::{pubkey::Pubkey, system_instruction, system_program};
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    commitment_config::CommitmentConfig, nonce::State as NonceState, pubkey::Pubkey,
+    system_instruction, system_program,
+};
 use std::str::FromStr;
+use std::sync::Arc;
 use tonic::{Request, Response, Status};
 
 use protochain_api::protochain::solana::program::system::v1::{
     service_server::Service as SystemProgramService, AdvanceNonceAccountRequest, AllocateRequest,
     AllocateWithSeedRequest, AssignRequest, AssignWithSeedRequest, AuthorizeNonceAccountRequest,
-    CreateRequest, CreateWithSeedRequest, InitializeNonceAccountRequest, TransferRequest,
-    TransferWithSeedRequest, UpgradeNonceAccountRequest, WithdrawNonceAccountRequest,
+    CreateNonceAccountRequest, CreateNonceAccountResponse, CreateRequest, CreateWithSeedRequest,
+    InitializeNonceAccountRequest, ParseNonceAccountRequest, ParseNonceAccountResponse,
+    TransferRequest, TransferWithSeedRequest, UpgradeNonceAccountRequest,
+    WithdrawNonceAccountRequest,
 };
 use protochain_api::protochain::solana::transaction::v1::SolanaInstruction;
 
+use super::nonce::{default_nonce_account_lamports, nonce_account_info};
+use crate::api::common::min_context_slot::{get_account, min_context_slot, read_error_status};
 use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::service_providers::localization::MessageCatalog;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter};
 
 /// Pure instruction-based System Program service implementation.
 ///
 /// All methods return composable `SolanaInstruction` objects for transaction building.
-/// This is a pure SDK wrapper - no RPC client or transaction compilation here.
+/// This is a pure SDK wrapper - no transaction compilation here. The RPC client is only
+/// used by `ParseNonceAccount`, which instances built for offline use do not serve.
 #[derive(Clone)]
 pub struct SystemProgramServiceImpl {
-    // No RPC client needed - we only build instructions
+    /// Instruction descriptions in the caller's locale
+    message_catalog: Arc<MessageCatalog>,
+    /// Solana RPC client for reading nonce accounts, if connected
+    rpc_client: Option<Arc<RpcClient>>,
+    /// Concurrency limits on calls to the RPC node, set along with `rpc_client`
+    rpc_limiter: Option<Arc<RpcLimiter>>,
 }
 
 impl Default for SystemProgramServiceImpl {
@@ -28,10 +45,42 @@ impl Default for SystemProgramServiceImpl {
 }
 
 impl SystemProgramServiceImpl {
-    /// Creates a new instance of the System Program service.
-    pub const fn new() -> Self {
-        Self {}
+    /// Creates a new instance of the System Program service with English descriptions.
+    pub fn new() -> Self {
+        Self::with_catalog(Arc::new(MessageCatalog::builtin()))
+    }
+
+    /// Creates a new instance describing instructions in the locales of `message_catalog`.
+    pub const fn with_catalog(message_catalog: Arc<MessageCatalog>) -> Self {
+        Self {
+            message_catalog,
+            rpc_client: None,
+            rpc_limiter: None,
+        }
+    }
+
+    /// Creates a new instance that can also read nonce accounts through `rpc_client`,
+    /// within the concurrency limits of `rpc_limiter`.
+    pub const fn with_rpc_client(
+        message_catalog: Arc<MessageCatalog>,
+        rpc_client: Arc<RpcClient>,
+        rpc_limiter: Arc<RpcLimiter>,
+    ) -> Self {
+        Self {
+            message_catalog,
+            rpc_client: Some(rpc_client),
+            rpc_limiter: Some(rpc_limiter),
+        }
+    }
+}
+
+/// Parses an optional program field, defaulting to the system program
+#[allow(clippy::result_large_err)]
+fn parse_owner(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Ok(system_program::id());
     }
+    Pubkey::from_str(value).map_err(|e| Status::invalid_argument(format!("Invalid {field}: {e}")))
 }
 
 #[tonic::async_trait]
@@ -41,6 +90,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<CreateRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         // Validation
@@ -80,16 +130,24 @@ impl SystemProgramService for SystemProgramServiceImpl {
 
         // Add descriptive information for composable transactions
         let owner_display = if req.owner.is_empty() {
-            "system program (default)".to_string()
+            localizer.text("instruction.system.default_owner", &[])
         } else {
             req.owner.clone()
         };
-        proto_instruction.description = format!(
-            "Create account: {} (payer: {}, owner: {}, lamports: {}, space: {})",
-            req.new_account, req.payer, owner_display, req.lamports, req.space
+        proto_instruction.description = localizer.text(
+            "instruction.system.create",
+            &[
+                ("new_account", &req.new_account),
+                ("payer", &req.payer),
+                ("owner", &owner_display),
+                ("lamports", &req.lamports),
+                ("space", &req.space),
+            ],
         );
 
-        Ok(Response::new(proto_instruction))
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates a transfer instruction.
@@ -97,6 +155,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<TransferRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.from.is_empty() {
@@ -116,10 +175,18 @@ impl SystemProgramService for SystemProgramServiceImpl {
 
         // Convert to proto format and add description
         let mut proto_instruction = sdk_instruction_to_proto(instruction);
-        proto_instruction.description =
-            format!("Transfer {} lamports from {} to {}", req.lamports, req.from, req.to);
+        proto_instruction.description = localizer.text(
+            "instruction.system.transfer",
+            &[
+                ("lamports", &req.lamports),
+                ("from", &req.from),
+                ("to", &req.to),
+            ],
+        );
 
-        Ok(Response::new(proto_instruction))
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates an allocate instruction.
@@ -127,6 +194,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<AllocateRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.account.is_empty() {
@@ -137,7 +205,16 @@ impl SystemProgramService for SystemProgramServiceImpl {
             .map_err(|e| Status::invalid_argument(format!("Invalid account address: {e}")))?;
 
         let instruction = system_instruction::allocate(&account, req.space);
-        Ok(Response::new(sdk_instruction_to_proto(instruction)))
+
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = localizer.text(
+            "instruction.system.allocate",
+            &[("space", &req.space), ("account", &req.account)],
+        );
+
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates an assign instruction.
@@ -145,6 +222,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<AssignRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.account.is_empty() {
@@ -161,7 +239,16 @@ impl SystemProgramService for SystemProgramServiceImpl {
             .map_err(|e| Status::invalid_argument(format!("Invalid owner program: {e}")))?;
 
         let instruction = system_instruction::assign(&account, &owner_program);
-        Ok(Response::new(sdk_instruction_to_proto(instruction)))
+
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = localizer.text(
+            "instruction.system.assign",
+            &[("account", &req.account), ("owner", &req.owner_program)],
+        );
+
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates a create-with-seed instruction.
@@ -169,6 +256,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<CreateWithSeedRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.payer.is_empty() {
@@ -193,6 +281,8 @@ impl SystemProgramService for SystemProgramServiceImpl {
         let base = Pubkey::from_str(&req.base)
             .map_err(|e| Status::invalid_argument(format!("Invalid base address: {e}")))?;
 
+        let owner = parse_owner("owner program address", &req.owner)?;
+
         let instruction = system_instruction::create_account_with_seed(
             &payer,
             &new_account,
@@ -200,10 +290,26 @@ impl SystemProgramService for SystemProgramServiceImpl {
             &req.seed,
             req.lamports,
             req.space,
-            &system_program::id(),
+            &owner,
         );
 
-        Ok(Response::new(sdk_instruction_to_proto(instruction)))
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = localizer.text(
+            "instruction.system.create_with_seed",
+            &[
+                ("new_account", &req.new_account),
+                ("base", &req.base),
+                ("seed", &req.seed),
+                ("payer", &req.payer),
+                ("owner", &owner),
+                ("lamports", &req.lamports),
+                ("space", &req.space),
+            ],
+        );
+
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates an allocate-with-seed instruction.
@@ -211,6 +317,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<AllocateWithSeedRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.account.is_empty() {
@@ -229,15 +336,26 @@ impl SystemProgramService for SystemProgramServiceImpl {
         let base = Pubkey::from_str(&req.base)
             .map_err(|e| Status::invalid_argument(format!("Invalid base address: {e}")))?;
 
-        let instruction = system_instruction::allocate_with_seed(
-            &account,
-            &base,
-            &req.seed,
-            req.space,
-            &system_program::id(),
+        let owner = parse_owner("owner program address", &req.owner)?;
+
+        let instruction =
+            system_instruction::allocate_with_seed(&account, &base, &req.seed, req.space, &owner);
+
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = localizer.text(
+            "instruction.system.allocate_with_seed",
+            &[
+                ("space", &req.space),
+                ("account", &req.account),
+                ("base", &req.base),
+                ("seed", &req.seed),
+                ("owner", &owner),
+            ],
         );
 
-        Ok(Response::new(sdk_instruction_to_proto(instruction)))
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates an assign-with-seed instruction.
@@ -245,6 +363,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<AssignWithSeedRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.account.is_empty() {
@@ -272,7 +391,20 @@ impl SystemProgramService for SystemProgramServiceImpl {
         let instruction =
             system_instruction::assign_with_seed(&account, &base, &req.seed, &owner_program);
 
-        Ok(Response::new(sdk_instruction_to_proto(instruction)))
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = localizer.text(
+            "instruction.system.assign_with_seed",
+            &[
+                ("account", &req.account),
+                ("base", &req.base),
+                ("seed", &req.seed),
+                ("owner", &req.owner_program),
+            ],
+        );
+
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates a transfer-with-seed instruction.
@@ -280,6 +412,7 @@ impl SystemProgramService for SystemProgramServiceImpl {
         &self,
         request: Request<TransferWithSeedRequest>,
     ) -> Result<Response<SolanaInstruction>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
 
         if req.from.is_empty() {
@@ -304,16 +437,32 @@ impl SystemProgramService for SystemProgramServiceImpl {
         let to = Pubkey::from_str(&req.to)
             .map_err(|e| Status::invalid_argument(format!("Invalid to address: {e}")))?;
 
+        let from_owner = parse_owner("from owner program address", &req.from_owner)?;
+
         let instruction = system_instruction::transfer_with_seed(
             &from,
             &from_base,
             req.from_seed.clone(),
-            &system_program::id(),
+            &from_owner,
             &to,
             req.lamports,
         );
 
-        Ok(Response::new(sdk_instruction_to_proto(instruction)))
+        let mut proto_instruction = sdk_instruction_to_proto(instruction);
+        proto_instruction.description = localizer.text(
+            "instruction.system.transfer_with_seed",
+            &[
+                ("lamports", &req.lamports),
+                ("from", &req.from),
+                ("base", &req.from_base),
+                ("seed", &req.from_seed),
+                ("to", &req.to),
+            ],
+        );
+
+        let mut response = Response::new(proto_instruction);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
     /// Creates an initialize-nonce-account instruction.
@@ -468,6 +617,117 @@ impl SystemProgramService for SystemProgramServiceImpl {
 
         Ok(Response::new(sdk_instruction_to_proto(instruction)))
     }
+
+    /// Creates the create-account and initialize-nonce-account instructions of a new nonce
+    /// account.
+    async fn create_nonce_account(
+        &self,
+        request: Request<CreateNonceAccountRequest>,
+    ) -> Result<Response<CreateNonceAccountResponse>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
+        let req = request.into_inner();
+
+        if req.payer.is_empty() {
+            return Err(Status::invalid_argument("Payer address is required"));
+        }
+        if req.nonce_account.is_empty() {
+            return Err(Status::invalid_argument("Nonce account address is required"));
+        }
+
+        let payer = Pubkey::from_str(&req.payer)
+            .map_err(|e| Status::invalid_argument(format!("Invalid payer address: {e}")))?;
+
+        let nonce_account = Pubkey::from_str(&req.nonce_account)
+            .map_err(|e| Status::invalid_argument(format!("Invalid nonce account address: {e}")))?;
+
+        let authority = if req.authority.is_empty() {
+            payer
+        } else {
+            Pubkey::from_str(&req.authority)
+                .map_err(|e| Status::invalid_argument(format!("Invalid authority address: {e}")))?
+        };
+
+        let lamports = if req.lamports == 0 {
+            default_nonce_account_lamports()
+        } else {
+            req.lamports
+        };
+
+        let instructions =
+            system_instruction::create_nonce_account(&payer, &nonce_account, &authority, lamports);
+        let descriptions = [
+            localizer.text(
+                "instruction.system.create",
+                &[
+                    ("new_account", &nonce_account),
+                    ("payer", &payer),
+                    ("owner", &system_program::id()),
+                    ("lamports", &lamports),
+                    ("space", &NonceState::size()),
+                ],
+            ),
+            localizer.text(
+                "describe.system.initialize_nonce",
+                &[("nonce_account", &nonce_account), ("authority", &authority)],
+            ),
+        ];
+
+        let mut response = Response::new(CreateNonceAccountResponse {
+            instructions: instructions
+                .into_iter()
+                .zip(descriptions)
+                .map(|(instruction, description)| {
+                    let mut proto_instruction = sdk_instruction_to_proto(instruction);
+                    proto_instruction.description = description;
+                    proto_instruction
+                })
+                .collect(),
+        });
+        localizer.set_content_language(&mut response);
+        Ok(response)
+    }
+
+    /// Reads a nonce account's durable blockhash and authority.
+    async fn parse_nonce_account(
+        &self,
+        request: Request<ParseNonceAccountRequest>,
+    ) -> Result<Response<ParseNonceAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let (Some(rpc_client), Some(rpc_limiter)) = (&self.rpc_client, &self.rpc_limiter) else {
+            return Err(Status::failed_precondition(
+                "Reading nonce accounts requires an RPC client",
+            ));
+        };
+        if req.account_address.is_empty() {
+            return Err(Status::invalid_argument("Account address is required"));
+        }
+
+        let address = Pubkey::from_str(&req.account_address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid account address: {e}")))?;
+
+        let _permit = rpc_limiter
+            .acquire(RpcCallClass::AccountRead)
+            .await
+            .map_err(Status::resource_exhausted)?;
+        let account = get_account(
+            rpc_client,
+            &address,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
+        if account.owner != system_program::id() {
+            return Err(Status::invalid_argument("Account is not owned by the system program"));
+        }
+
+        let nonce_account = nonce_account_info(&address, account.lamports, &account.data)
+            .map_err(Status::invalid_argument)?;
+        Ok(Response::new(ParseNonceAccountResponse {
+            nonce_account: Some(nonce_account),
+        }))
+    }
 }
 
 #[cfg(test)]
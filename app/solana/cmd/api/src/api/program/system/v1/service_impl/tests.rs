@@ -629,6 +629,7 @@ async fn test_create_with_seed_request_validation() {
             seed: test_case.seed.to_string(),
             lamports: test_case.lamports,
             space: test_case.space,
+            owner: String::new(),
         });
 
         let result = service.create_with_seed(request).await;
@@ -669,3 +670,42 @@ async fn test_create_with_seed_request_validation() {
         }
     }
 }
+
+#[tokio::test(flavor = "multi_thread")]
+#[allow(clippy::unwrap_used)]
+async fn test_create_with_seed_uses_requested_owner() {
+    let service = create_test_service();
+
+    const PAYER: &str = "SysvarC1ock11111111111111111111111111111111";
+    const STAKE_PROGRAM: &str = "Stake11111111111111111111111111111111111111";
+
+    let request = Request::new(CreateWithSeedRequest {
+        payer: PAYER.to_string(),
+        new_account: "SysvarS1otHashes111111111111111111111111111".to_string(),
+        base: PAYER.to_string(),
+        seed: "stake:0".to_string(),
+        lamports: 1_000_000,
+        space: 200,
+        owner: STAKE_PROGRAM.to_string(),
+    });
+
+    let instruction = service
+        .create_with_seed(request)
+        .await
+        .unwrap()
+        .into_inner();
+    assert!(instruction.description.contains(STAKE_PROGRAM));
+    assert!(instruction.description.contains("stake:0"));
+
+    let request = Request::new(CreateWithSeedRequest {
+        payer: PAYER.to_string(),
+        new_account: PAYER.to_string(),
+        base: PAYER.to_string(),
+        seed: "seed".to_string(),
+        owner: "invalid_not_base58!!!".to_string(),
+        ..Default::default()
+    });
+    let error = service.create_with_seed(request).await.unwrap_err();
+    assert!(is_validation_error(&error));
+    assert!(error.message().contains("Invalid owner program address"));
+}
@@ -11,11 +11,14 @@ pub struct SystemProgramV1API {
 
 impl SystemProgramV1API {
     /// Creates a new `SystemProgramV1API` instance with the provided service providers
-    pub fn new(_service_providers: &Arc<ServiceProviders>) -> Self {
-        // No RPC client needed for instruction-based system program service
-
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        // Instructions are built offline; the RPC client only reads nonce accounts
         Self {
-            system_program_service: Arc::new(SystemProgramServiceImpl::new()),
+            system_program_service: Arc::new(SystemProgramServiceImpl::with_rpc_client(
+                Arc::clone(&service_providers.message_catalog),
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.rpc_limiter),
+            )),
         }
     }
 }
@@ -0,0 +1,98 @@
+use protochain_api::protochain::solana::program::token::v1::TokenAuthorityType;
+use solana_sdk::pubkey::Pubkey;
+use spl_token_2022::instruction::AuthorityType;
+
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+
+/// Maps a requested authority type to the token program's, rejecting Token-2022 extension
+/// authorities for legacy SPL Token, which only knows the four base authorities
+pub fn authority_type(
+    authority_type: TokenAuthorityType,
+    token_program: &Pubkey,
+) -> Result<AuthorityType, String> {
+    let authority_type = match authority_type {
+        TokenAuthorityType::Unspecified => return Err("authority_type is required".to_string()),
+        TokenAuthorityType::MintTokens => AuthorityType::MintTokens,
+        TokenAuthorityType::FreezeAccount => AuthorityType::FreezeAccount,
+        TokenAuthorityType::AccountOwner => AuthorityType::AccountOwner,
+        TokenAuthorityType::CloseAccount => AuthorityType::CloseAccount,
+        TokenAuthorityType::TransferFeeConfig => AuthorityType::TransferFeeConfig,
+        TokenAuthorityType::WithheldWithdraw => AuthorityType::WithheldWithdraw,
+        TokenAuthorityType::CloseMint => AuthorityType::CloseMint,
+        TokenAuthorityType::InterestRate => AuthorityType::InterestRate,
+        TokenAuthorityType::PermanentDelegate => AuthorityType::PermanentDelegate,
+        TokenAuthorityType::ConfidentialTransferMint => AuthorityType::ConfidentialTransferMint,
+        TokenAuthorityType::TransferHookProgramId => AuthorityType::TransferHookProgramId,
+        TokenAuthorityType::ConfidentialTransferFeeConfig => {
+            AuthorityType::ConfidentialTransferFeeConfig
+        }
+        TokenAuthorityType::MetadataPointer => AuthorityType::MetadataPointer,
+        TokenAuthorityType::GroupPointer => AuthorityType::GroupPointer,
+        TokenAuthorityType::GroupMemberPointer => AuthorityType::GroupMemberPointer,
+    };
+
+    let base = matches!(
+        authority_type,
+        AuthorityType::MintTokens
+            | AuthorityType::FreezeAccount
+            | AuthorityType::AccountOwner
+            | AuthorityType::CloseAccount
+    );
+    if !base && *token_program == TOKEN_PROGRAM_ID {
+        return Err(format!(
+            "{authority_type:?} is a Token-2022 extension authority; SPL Token does not support it"
+        ));
+    }
+    Ok(authority_type)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use spl_token_2022::instruction::{set_authority, TokenInstruction};
+    use spl_token_2022::ID as TOKEN_2022_PROGRAM_ID;
+
+    #[test]
+    fn test_base_authorities_work_with_both_programs() {
+        for program in [TOKEN_PROGRAM_ID, TOKEN_2022_PROGRAM_ID] {
+            assert_eq!(
+                authority_type(TokenAuthorityType::MintTokens, &program).unwrap(),
+                AuthorityType::MintTokens
+            );
+            assert_eq!(
+                authority_type(TokenAuthorityType::CloseAccount, &program).unwrap(),
+                AuthorityType::CloseAccount
+            );
+        }
+    }
+
+    #[test]
+    fn test_extension_authorities_require_token_2022() {
+        assert_eq!(
+            authority_type(TokenAuthorityType::PermanentDelegate, &TOKEN_2022_PROGRAM_ID).unwrap(),
+            AuthorityType::PermanentDelegate
+        );
+        assert!(authority_type(TokenAuthorityType::PermanentDelegate, &TOKEN_PROGRAM_ID).is_err());
+        assert!(authority_type(TokenAuthorityType::Unspecified, &TOKEN_2022_PROGRAM_ID).is_err());
+    }
+
+    #[test]
+    fn test_set_authority_to_none_burns_the_authority() {
+        let mint = Pubkey::new_unique();
+        let owner = Pubkey::new_unique();
+        let authority =
+            authority_type(TokenAuthorityType::MintTokens, &TOKEN_2022_PROGRAM_ID).unwrap();
+        let instruction =
+            set_authority(&TOKEN_2022_PROGRAM_ID, &mint, None, authority, &owner, &[]).unwrap();
+
+        assert_eq!(
+            TokenInstruction::unpack(&instruction.data).unwrap(),
+            TokenInstruction::SetAuthority {
+                authority_type: AuthorityType::MintTokens,
+                new_authority: None.into(),
+            }
+        );
+        assert!(instruction.accounts[1].is_signer);
+    }
+}
@@ -0,0 +1,199 @@
+use protochain_api::protochain::solana::program::token::v1::{
+    holding_account_extension::State, CpiGuardState, HoldingAccountExtension, HoldingAccountInfo,
+    HoldingAccountState, MemoTransferState, TransferFeeAmountState, TransferHookAccountState,
+};
+use solana_sdk::pubkey::Pubkey;
+use spl_token_2022::{
+    extension::{
+        cpi_guard::CpiGuard, memo_transfer::MemoTransfer, transfer_fee::TransferFeeAmount,
+        transfer_hook::TransferHookAccount, BaseStateWithExtensions, ExtensionType,
+        StateWithExtensions,
+    },
+    state::{Account, AccountState},
+};
+
+/// Parses holding account data owned by `token_program`. Legacy SPL Token accounts have no
+/// extensions; Token-2022 accounts list every enabled extension.
+pub fn holding_account_info(
+    data: &[u8],
+    token_program: &Pubkey,
+) -> Result<HoldingAccountInfo, String> {
+    let account = StateWithExtensions::<Account>::unpack(data)
+        .map_err(|e| format!("Failed to parse holding account: {e}"))?;
+    let base = &account.base;
+
+    let extensions = account
+        .get_extension_types()
+        .map_err(|e| format!("Failed to read holding account extensions: {e}"))?
+        .into_iter()
+        .map(|extension_type| HoldingAccountExtension {
+            name: format!("{extension_type:?}"),
+            state: extension_state(&account, extension_type),
+        })
+        .collect();
+
+    Ok(HoldingAccountInfo {
+        mint_pub_key: base.mint.to_string(),
+        owner_pub_key: base.owner.to_string(),
+        amount: base.amount.to_string(),
+        delegate_pub_key: base.delegate.map(|key| key.to_string()).unwrap_or_default(),
+        delegated_amount: base.delegated_amount.to_string(),
+        state: match base.state {
+            AccountState::Uninitialized => HoldingAccountState::Uninitialized,
+            AccountState::Initialized => HoldingAccountState::Initialized,
+            AccountState::Frozen => HoldingAccountState::Frozen,
+        }
+        .into(),
+        is_native: base.is_native.is_some(),
+        native_rent_exempt_reserve: base
+            .is_native
+            .map(|reserve| reserve.to_string())
+            .unwrap_or_default(),
+        close_authority_pub_key: base
+            .close_authority
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        token_program_id: token_program.to_string(),
+        extensions,
+    })
+}
+
+/// Decodes the state of an extension that has any worth reporting
+fn extension_state(
+    account: &StateWithExtensions<Account>,
+    extension_type: ExtensionType,
+) -> Option<State> {
+    match extension_type {
+        ExtensionType::TransferFeeAmount => {
+            account
+                .get_extension::<TransferFeeAmount>()
+                .ok()
+                .map(|ext| {
+                    State::TransferFeeAmount(TransferFeeAmountState {
+                        withheld_amount: u64::from(ext.withheld_amount).to_string(),
+                    })
+                })
+        }
+        ExtensionType::MemoTransfer => account.get_extension::<MemoTransfer>().ok().map(|ext| {
+            State::MemoTransfer(MemoTransferState {
+                require_incoming_transfer_memos: bool::from(ext.require_incoming_transfer_memos),
+            })
+        }),
+        ExtensionType::CpiGuard => account.get_extension::<CpiGuard>().ok().map(|ext| {
+            State::CpiGuard(CpiGuardState {
+                lock_cpi: bool::from(ext.lock_cpi),
+            })
+        }),
+        ExtensionType::TransferHookAccount => account
+            .get_extension::<TransferHookAccount>()
+            .ok()
+            .map(|ext| {
+                State::TransferHookAccount(TransferHookAccountState {
+                    transferring: bool::from(ext.transferring),
+                })
+            }),
+        _ => None,
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+    use solana_sdk::program_option::COption;
+    use solana_sdk::program_pack::Pack;
+    use spl_token_2022::extension::{
+        immutable_owner::ImmutableOwner, BaseStateWithExtensionsMut, StateWithExtensionsMut,
+    };
+    use spl_token_2022::ID as TOKEN_2022_PROGRAM_ID;
+
+    fn account() -> Account {
+        Account {
+            mint: Pubkey::new_unique(),
+            owner: Pubkey::new_unique(),
+            amount: 1_000,
+            state: AccountState::Initialized,
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_legacy_account() {
+        let mut base = account();
+        base.delegate = COption::Some(Pubkey::new_unique());
+        base.delegated_amount = 250;
+        base.is_native = COption::Some(2_039_280);
+        base.state = AccountState::Frozen;
+        let mut data = vec![0; Account::LEN];
+        base.pack_into_slice(&mut data);
+
+        let info = holding_account_info(&data, &TOKEN_PROGRAM_ID).unwrap();
+        assert_eq!(info.mint_pub_key, base.mint.to_string());
+        assert_eq!(info.amount, "1000");
+        assert_eq!(info.delegate_pub_key, base.delegate.unwrap().to_string());
+        assert_eq!(info.delegated_amount, "250");
+        assert_eq!(info.state, i32::from(HoldingAccountState::Frozen));
+        assert!(info.is_native);
+        assert_eq!(info.native_rent_exempt_reserve, "2039280");
+        assert!(info.close_authority_pub_key.is_empty());
+        assert!(info.extensions.is_empty());
+    }
+
+    #[test]
+    fn test_token_2022_extensions() {
+        let space = ExtensionType::try_calculate_account_len::<Account>(&[
+            ExtensionType::ImmutableOwner,
+            ExtensionType::MemoTransfer,
+            ExtensionType::TransferFeeAmount,
+        ])
+        .unwrap();
+        let mut data = vec![0; space];
+        let mut state = StateWithExtensionsMut::<Account>::unpack_uninitialized(&mut data).unwrap();
+        state.init_extension::<ImmutableOwner>(true).unwrap();
+        state
+            .init_extension::<MemoTransfer>(true)
+            .unwrap()
+            .require_incoming_transfer_memos = true.into();
+        state
+            .init_extension::<TransferFeeAmount>(true)
+            .unwrap()
+            .withheld_amount = 42u64.into();
+        state.base = account();
+        state.pack_base();
+        state.init_account_type().unwrap();
+
+        let info = holding_account_info(&data, &TOKEN_2022_PROGRAM_ID).unwrap();
+        assert_eq!(info.token_program_id, TOKEN_2022_PROGRAM_ID.to_string());
+        let names: Vec<&str> = info
+            .extensions
+            .iter()
+            .map(|ext| ext.name.as_str())
+            .collect();
+        assert_eq!(names, ["ImmutableOwner", "MemoTransfer", "TransferFeeAmount"]);
+        assert_eq!(info.extensions[0].state, None);
+        assert_eq!(
+            info.extensions[1].state,
+            Some(State::MemoTransfer(MemoTransferState {
+                require_incoming_transfer_memos: true
+            }))
+        );
+        assert_eq!(
+            info.extensions[2].state,
+            Some(State::TransferFeeAmount(TransferFeeAmountState {
+                withheld_amount: "42".to_string()
+            }))
+        );
+    }
+
+    #[test]
+    fn test_mint_data_is_rejected() {
+        let mut data = vec![0; spl_token_2022::state::Mint::LEN];
+        spl_token_2022::state::Mint {
+            is_initialized: true,
+            ..Default::default()
+        }
+        .pack_into_slice(&mut data);
+        assert!(holding_account_info(&data, &TOKEN_2022_PROGRAM_ID).is_err());
+    }
+}
@@ -0,0 +1,285 @@
+use protochain_api::protochain::solana::program::token::v1::{TokenMetadata, TokenMetadataSource};
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{commitment_config::CommitmentConfig, pubkey, pubkey::Pubkey};
+use spl_token_2022::{
+    extension::{metadata_pointer::MetadataPointer, BaseStateWithExtensions, StateWithExtensions},
+    state::Mint,
+    ID as TOKEN_2022_PROGRAM_ID,
+};
+use spl_token_metadata_interface::state::TokenMetadata;
+use tonic::Status;
+
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+use crate::api::common::min_context_slot::{get_account, read_error_status};
+
+/// Metaplex Token Metadata program id
+pub const METAPLEX_METADATA_PROGRAM_ID: Pubkey =
+    pubkey!("metaqbxxUerdq28cj1RbAWkYQm3ybzjb6a8bt518x1s");
+/// Account discriminator of a Metaplex `MetadataV1` account
+const METAPLEX_METADATA_V1_KEY: u8 = 4;
+
+/// Where a mint's on-chain metadata was found
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum MetadataOrigin {
+    /// The Token-2022 metadata extension on the mint itself
+    Token2022,
+    /// A Metaplex Token Metadata account
+    Metaplex,
+}
+
+/// A mint's on-chain metadata, from either origin
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct OnChainMetadata {
+    /// Where the metadata was found
+    pub origin: MetadataOrigin,
+    /// Authority allowed to update the metadata, if any
+    pub update_authority: Option<Pubkey>,
+    /// Token name
+    pub name: String,
+    /// Token symbol
+    pub symbol: String,
+    /// URI of the off-chain JSON document
+    pub uri: String,
+    /// Additional key/value pairs (Token-2022 only)
+    pub additional_metadata: Vec<(String, String)>,
+}
+
+/// Reads the Token-2022 metadata extension of a mint, if the mint carries it.
+///
+/// A metadata pointer that names another account is not followed here: such metadata
+/// usually lives in a Metaplex account, which the caller looks up next.
+pub fn token_2022_metadata(mint: &Pubkey, data: &[u8]) -> Result<Option<OnChainMetadata>, String> {
+    let state = StateWithExtensions::<Mint>::unpack(data)
+        .map_err(|e| format!("Failed to parse mint account: {e}"))?;
+    if let Ok(pointer) = state.get_extension::<MetadataPointer>() {
+        if Option::<Pubkey>::from(pointer.metadata_address).is_some_and(|address| address != *mint)
+        {
+            return Ok(None);
+        }
+    }
+    let Ok(metadata) = state.get_variable_len_extension::<TokenMetadata>() else {
+        return Ok(None);
+    };
+
+    Ok(Some(OnChainMetadata {
+        origin: MetadataOrigin::Token2022,
+        update_authority: Option::<Pubkey>::from(metadata.update_authority),
+        name: metadata.name,
+        symbol: metadata.symbol,
+        uri: metadata.uri,
+        additional_metadata: metadata.additional_metadata,
+    }))
+}
+
+/// A mint's on-chain metadata together with the mint facts needed to present it
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ResolvedMetadata {
+    /// The metadata
+    pub metadata: OnChainMetadata,
+    /// Account holding the metadata: the mint itself or its Metaplex account
+    pub metadata_address: Pubkey,
+    /// Token program owning the mint
+    pub token_program: Pubkey,
+    /// Decimals of the mint
+    pub decimals: u8,
+}
+
+/// Reads a mint's on-chain metadata from its Token-2022 extension or, failing that, its
+/// Metaplex account
+#[allow(clippy::result_large_err)]
+pub fn read_on_chain_metadata(
+    rpc_client: &RpcClient,
+    mint: &Pubkey,
+    min_context_slot: Option<u64>,
+) -> Result<ResolvedMetadata, Status> {
+    let account = get_account(rpc_client, mint, CommitmentConfig::confirmed(), min_context_slot)
+        .map_err(|e| read_error_status(&e, "Failed to get mint account"))?
+        .ok_or_else(|| Status::not_found(format!("Mint not found: {mint}")))?;
+    if account.owner != TOKEN_2022_PROGRAM_ID && account.owner != TOKEN_PROGRAM_ID {
+        return Err(Status::invalid_argument("Account is not owned by a token program"));
+    }
+    // Legacy mints are the Token-2022 base state without extensions
+    let decimals = StateWithExtensions::<Mint>::unpack(&account.data)
+        .map_err(|e| Status::invalid_argument(format!("Failed to parse mint account: {e}")))?
+        .base
+        .decimals;
+    let resolved = |metadata, metadata_address| ResolvedMetadata {
+        metadata,
+        metadata_address,
+        token_program: account.owner,
+        decimals,
+    };
+
+    if account.owner == TOKEN_2022_PROGRAM_ID {
+        if let Some(metadata) =
+            token_2022_metadata(mint, &account.data).map_err(Status::invalid_argument)?
+        {
+            return Ok(resolved(metadata, *mint));
+        }
+    }
+
+    let metadata_address = metaplex_metadata_address(mint);
+    let metadata_account =
+        get_account(rpc_client, &metadata_address, CommitmentConfig::confirmed(), min_context_slot)
+            .map_err(|e| read_error_status(&e, "Failed to get Metaplex metadata account"))?
+            .ok_or_else(|| Status::not_found(format!("Mint {mint} has no metadata")))?;
+    let metadata =
+        parse_metaplex_metadata(mint, &metadata_account.data).map_err(Status::internal)?;
+    Ok(resolved(metadata, metadata_address))
+}
+
+/// Converts on-chain metadata to its proto form, without any off-chain document
+pub fn metadata_to_proto(mint: &Pubkey, on_chain: OnChainMetadata) -> TokenMetadata {
+    TokenMetadata {
+        mint_pub_key: mint.to_string(),
+        source: match on_chain.origin {
+            MetadataOrigin::Token2022 => TokenMetadataSource::Token2022,
+            MetadataOrigin::Metaplex => TokenMetadataSource::Metaplex,
+        }
+        .into(),
+        update_authority_pub_key: on_chain
+            .update_authority
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        name: on_chain.name,
+        symbol: on_chain.symbol,
+        uri: on_chain.uri,
+        additional_metadata: on_chain.additional_metadata.into_iter().collect(),
+        ..Default::default()
+    }
+}
+
+/// Address of the Metaplex metadata account of a mint
+pub fn metaplex_metadata_address(mint: &Pubkey) -> Pubkey {
+    Pubkey::find_program_address(
+        &[
+            b"metadata",
+            METAPLEX_METADATA_PROGRAM_ID.as_ref(),
+            mint.as_ref(),
+        ],
+        &METAPLEX_METADATA_PROGRAM_ID,
+    )
+    .0
+}
+
+/// Parses the leading fields of a Metaplex `MetadataV1` account: key, update authority,
+/// mint, then the name, symbol and URI as borsh strings padded with NULs.
+/// The remaining fields (royalties, creators, collection) are not needed here.
+pub fn parse_metaplex_metadata(mint: &Pubkey, data: &[u8]) -> Result<OnChainMetadata, String> {
+    let mut reader = Reader { data, offset: 0 };
+    if reader.take(1)?[0] != METAPLEX_METADATA_V1_KEY {
+        return Err("Account is not a Metaplex metadata account".to_string());
+    }
+    let update_authority = reader.pubkey()?;
+    if reader.pubkey()? != *mint {
+        return Err("Metaplex metadata belongs to another mint".to_string());
+    }
+
+    Ok(OnChainMetadata {
+        origin: MetadataOrigin::Metaplex,
+        update_authority: Some(update_authority),
+        name: reader.string()?,
+        symbol: reader.string()?,
+        uri: reader.string()?,
+        additional_metadata: Vec::new(),
+    })
+}
+
+/// Cursor over borsh-encoded account data
+struct Reader<'a> {
+    data: &'a [u8],
+    offset: usize,
+}
+
+impl<'a> Reader<'a> {
+    fn take(&mut self, len: usize) -> Result<&'a [u8], String> {
+        let end = self
+            .offset
+            .checked_add(len)
+            .filter(|end| *end <= self.data.len())
+            .ok_or_else(|| "Metaplex metadata account is truncated".to_string())?;
+        let bytes = &self.data[self.offset..end];
+        self.offset = end;
+        Ok(bytes)
+    }
+
+    fn pubkey(&mut self) -> Result<Pubkey, String> {
+        Pubkey::try_from(self.take(32)?).map_err(|e| format!("Invalid public key: {e}"))
+    }
+
+    fn string(&mut self) -> Result<String, String> {
+        let len_bytes: [u8; 4] = self
+            .take(4)?
+            .try_into()
+            .map_err(|_| "Metaplex metadata account is truncated".to_string())?;
+        let len = usize::try_from(u32::from_le_bytes(len_bytes))
+            .map_err(|_| "Metaplex metadata string is too long".to_string())?;
+        let bytes = self.take(len)?;
+        Ok(String::from_utf8_lossy(bytes)
+            .trim_end_matches('\0')
+            .trim()
+            .to_string())
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn borsh_string(value: &str, padded_len: usize) -> Vec<u8> {
+        let mut padded = value.as_bytes().to_vec();
+        padded.resize(padded_len, 0);
+        let mut out = u32::try_from(padded.len()).unwrap().to_le_bytes().to_vec();
+        out.extend(padded);
+        out
+    }
+
+    fn metaplex_account(mint: &Pubkey, authority: &Pubkey) -> Vec<u8> {
+        let mut data = vec![METAPLEX_METADATA_V1_KEY];
+        data.extend_from_slice(authority.as_ref());
+        data.extend_from_slice(mint.as_ref());
+        data.extend(borsh_string("Example Token", 32));
+        data.extend(borsh_string("EX", 10));
+        data.extend(borsh_string("https://example.com/ex.json", 200));
+        // Royalties and the remaining fields follow
+        data.extend_from_slice(&500u16.to_le_bytes());
+        data
+    }
+
+    #[test]
+    fn test_parses_metaplex_metadata() {
+        let mint = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let metadata =
+            parse_metaplex_metadata(&mint, &metaplex_account(&mint, &authority)).unwrap();
+
+        assert_eq!(metadata.origin, MetadataOrigin::Metaplex);
+        assert_eq!(metadata.update_authority, Some(authority));
+        assert_eq!(metadata.name, "Example Token");
+        assert_eq!(metadata.symbol, "EX");
+        assert_eq!(metadata.uri, "https://example.com/ex.json");
+    }
+
+    #[test]
+    fn test_rejects_foreign_or_truncated_metaplex_accounts() {
+        let mint = Pubkey::new_unique();
+        let data = metaplex_account(&mint, &Pubkey::new_unique());
+
+        assert!(parse_metaplex_metadata(&Pubkey::new_unique(), &data).is_err());
+        assert!(parse_metaplex_metadata(&mint, &data[..80]).is_err());
+        let mut wrong_key = data;
+        wrong_key[0] = 6;
+        assert!(parse_metaplex_metadata(&mint, &wrong_key).is_err());
+    }
+
+    #[test]
+    fn test_metaplex_address_is_deterministic() {
+        let mint = Pubkey::new_unique();
+        assert_eq!(metaplex_metadata_address(&mint), metaplex_metadata_address(&mint));
+        assert_ne!(
+            metaplex_metadata_address(&mint),
+            metaplex_metadata_address(&Pubkey::new_unique())
+        );
+    }
+}
@@ -0,0 +1,287 @@
+use protochain_api::protochain::solana::program::token::v1::{
+    InterestBearingMintConfig, InterestBearingMintInfo, MintInfo,
+};
+use solana_sdk::{clock::UnixTimestamp, instruction::Instruction, pubkey::Pubkey};
+use spl_token_2022::{
+    extension::{
+        interest_bearing_mint::{instruction::initialize, InterestBearingConfig},
+        non_transferable::NonTransferable,
+        BaseStateWithExtensions, ExtensionType, StateWithExtensions,
+    },
+    instruction::initialize_non_transferable_mint,
+    state::Mint,
+    ID as TOKEN_2022_PROGRAM_ID,
+};
+use std::str::FromStr;
+
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+
+/// Token-2022 extensions a mint is created with
+#[derive(Debug, Default, PartialEq, Eq)]
+pub struct MintExtensions {
+    /// Interest-bearing config: optional rate authority and rate in basis points
+    pub interest_bearing: Option<(Option<Pubkey>, i16)>,
+    /// Tokens of the mint can never be transferred
+    pub non_transferable: bool,
+}
+
+impl MintExtensions {
+    /// Reads the requested extensions of an `InitialiseMint` or `CreateMint` request
+    pub fn from_request(
+        interest_bearing: Option<&InterestBearingMintConfig>,
+        non_transferable: bool,
+    ) -> Result<Self, String> {
+        let interest_bearing = interest_bearing
+            .map(|config| {
+                let rate_authority = if config.rate_authority_pub_key.is_empty() {
+                    None
+                } else {
+                    Some(Pubkey::from_str(&config.rate_authority_pub_key).map_err(|e| {
+                        format!("Invalid interest_bearing.rate_authority_pub_key: {e}")
+                    })?)
+                };
+                let rate = i16::try_from(config.rate).map_err(|_| {
+                    format!(
+                        "interest_bearing.rate must be between {} and {} basis points",
+                        i16::MIN,
+                        i16::MAX
+                    )
+                })?;
+                Ok::<_, String>((rate_authority, rate))
+            })
+            .transpose()?;
+        Ok(Self {
+            interest_bearing,
+            non_transferable,
+        })
+    }
+
+    /// Extension types the mint account needs space for
+    pub fn extension_types(&self) -> Vec<ExtensionType> {
+        let mut types = Vec::new();
+        if self.interest_bearing.is_some() {
+            types.push(ExtensionType::InterestBearingConfig);
+        }
+        if self.non_transferable {
+            types.push(ExtensionType::NonTransferable);
+        }
+        types
+    }
+
+    /// Rejects extensions for legacy SPL Token mints, which have none
+    pub fn check_token_program(&self, token_program: &Pubkey) -> Result<(), String> {
+        if *token_program == TOKEN_PROGRAM_ID && !self.extension_types().is_empty() {
+            return Err(
+                "Mint extensions require Token-2022; SPL Token does not support them".to_string()
+            );
+        }
+        Ok(())
+    }
+
+    /// Size of a mint account with these extensions
+    pub fn mint_space(&self) -> Result<usize, String> {
+        ExtensionType::try_calculate_account_len::<Mint>(&self.extension_types())
+            .map_err(|e| format!("Failed to calculate mint account length: {e}"))
+    }
+
+    /// Instructions initializing the extensions, which must come before `InitializeMint2`
+    pub fn instructions(&self, mint: &Pubkey) -> Result<Vec<Instruction>, String> {
+        let mut instructions = Vec::new();
+        if let Some((rate_authority, rate)) = self.interest_bearing {
+            instructions.push(
+                initialize(&TOKEN_2022_PROGRAM_ID, mint, rate_authority, rate).map_err(|e| {
+                    format!("Failed to create InitializeInterestBearingMint instruction: {e}")
+                })?,
+            );
+        }
+        if self.non_transferable {
+            instructions.push(
+                initialize_non_transferable_mint(&TOKEN_2022_PROGRAM_ID, mint).map_err(|e| {
+                    format!("Failed to create InitializeNonTransferableMint instruction: {e}")
+                })?,
+            );
+        }
+        Ok(instructions)
+    }
+}
+
+/// Whether mint account data carries the interest-bearing extension, so parsing it needs
+/// the cluster's clock
+pub fn is_interest_bearing(data: &[u8]) -> bool {
+    StateWithExtensions::<Mint>::unpack(data)
+        .is_ok_and(|mint| mint.get_extension::<InterestBearingConfig>().is_ok())
+}
+
+/// Parses mint account data owned by `token_program`, including the interest-bearing and
+/// non-transferable extensions of Token-2022 mints. UI amounts of interest-bearing mints
+/// are computed at `unix_timestamp`.
+pub fn mint_info(
+    data: &[u8],
+    token_program: &Pubkey,
+    unix_timestamp: UnixTimestamp,
+) -> Result<MintInfo, String> {
+    let mint = StateWithExtensions::<Mint>::unpack(data)
+        .map_err(|e| format!("Failed to parse mint account: {e}"))?;
+    let base = &mint.base;
+
+    let interest_bearing = mint
+        .get_extension::<InterestBearingConfig>()
+        .ok()
+        .map(|config| InterestBearingMintInfo {
+            rate_authority_pub_key: Option::<Pubkey>::from(config.rate_authority)
+                .map(|key| key.to_string())
+                .unwrap_or_default(),
+            current_rate: i32::from(i16::from(config.current_rate)),
+            pre_update_average_rate: i32::from(i16::from(config.pre_update_average_rate)),
+            initialization_timestamp: i64::from(config.initialization_timestamp),
+            last_update_timestamp: i64::from(config.last_update_timestamp),
+            unix_timestamp,
+            ui_supply: config
+                .amount_to_ui_amount(base.supply, base.decimals, unix_timestamp)
+                .unwrap_or_default(),
+        });
+
+    Ok(MintInfo {
+        mint_authority_pub_key: base
+            .mint_authority
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        freeze_authority_pub_key: base
+            .freeze_authority
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        decimals: u32::from(base.decimals),
+        supply: base.supply.to_string(),
+        is_initialized: base.is_initialized,
+        interest_bearing,
+        non_transferable: mint.get_extension::<NonTransferable>().is_ok(),
+        token_program_id: token_program.to_string(),
+    })
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::program_pack::Pack;
+    use spl_token_2022::extension::{BaseStateWithExtensionsMut, StateWithExtensionsMut};
+
+    /// Seconds in a year, as the interest-bearing extension counts them
+    const SECONDS_PER_YEAR: i64 = 31_556_736;
+
+    fn mint() -> Mint {
+        Mint {
+            decimals: 2,
+            supply: 10_000,
+            is_initialized: true,
+            ..Default::default()
+        }
+    }
+
+    fn interest_bearing_mint_data(rate: i16) -> Vec<u8> {
+        let extensions = MintExtensions {
+            interest_bearing: Some((None, rate)),
+            non_transferable: true,
+        };
+        let mut data = vec![0; extensions.mint_space().unwrap()];
+        let mut state = StateWithExtensionsMut::<Mint>::unpack_uninitialized(&mut data).unwrap();
+        let config = state.init_extension::<InterestBearingConfig>(true).unwrap();
+        config.current_rate = rate.into();
+        config.pre_update_average_rate = rate.into();
+        state.init_extension::<NonTransferable>(true).unwrap();
+        state.base = mint();
+        state.pack_base();
+        state.init_account_type().unwrap();
+        data
+    }
+
+    #[test]
+    fn test_from_request() {
+        let rate_authority = Pubkey::new_unique();
+        let config = InterestBearingMintConfig {
+            rate_authority_pub_key: rate_authority.to_string(),
+            rate: 500,
+        };
+        assert_eq!(
+            MintExtensions::from_request(Some(&config), false).unwrap(),
+            MintExtensions {
+                interest_bearing: Some((Some(rate_authority), 500)),
+                non_transferable: false,
+            }
+        );
+
+        let too_high = InterestBearingMintConfig {
+            rate: 40_000,
+            ..Default::default()
+        };
+        assert!(MintExtensions::from_request(Some(&too_high), false).is_err());
+    }
+
+    #[test]
+    fn test_extension_instructions_and_space() {
+        let mint = Pubkey::new_unique();
+        let none = MintExtensions::default();
+        assert_eq!(none.mint_space().unwrap(), Mint::LEN);
+        assert!(none.instructions(&mint).unwrap().is_empty());
+
+        let both = MintExtensions {
+            interest_bearing: Some((None, 100)),
+            non_transferable: true,
+        };
+        assert!(both.mint_space().unwrap() > Mint::LEN);
+        let instructions = both.instructions(&mint).unwrap();
+        assert_eq!(instructions.len(), 2);
+        assert!(instructions
+            .iter()
+            .all(|ix| ix.program_id == TOKEN_2022_PROGRAM_ID && ix.accounts[0].pubkey == mint));
+    }
+
+    #[test]
+    fn test_extensions_require_token_2022() {
+        let none = MintExtensions::default();
+        assert!(none.check_token_program(&TOKEN_PROGRAM_ID).is_ok());
+
+        let non_transferable = MintExtensions {
+            non_transferable: true,
+            ..Default::default()
+        };
+        assert!(non_transferable
+            .check_token_program(&TOKEN_2022_PROGRAM_ID)
+            .is_ok());
+        assert!(non_transferable
+            .check_token_program(&TOKEN_PROGRAM_ID)
+            .is_err());
+    }
+
+    #[test]
+    fn test_plain_mint_info() {
+        let mut data = vec![0; Mint::LEN];
+        mint().pack_into_slice(&mut data);
+
+        assert!(!is_interest_bearing(&data));
+        let info = mint_info(&data, &TOKEN_PROGRAM_ID, 0).unwrap();
+        assert_eq!(info.token_program_id, TOKEN_PROGRAM_ID.to_string());
+        assert_eq!(info.supply, "10000");
+        assert_eq!(info.decimals, 2);
+        assert_eq!(info.interest_bearing, None);
+        assert!(!info.non_transferable);
+    }
+
+    #[test]
+    fn test_interest_bearing_mint_info_accrues_interest() {
+        let data = interest_bearing_mint_data(500);
+        assert!(is_interest_bearing(&data));
+
+        let at_start = mint_info(&data, &TOKEN_2022_PROGRAM_ID, 0).unwrap();
+        assert!(at_start.non_transferable);
+        let interest = at_start.interest_bearing.unwrap();
+        assert_eq!(interest.current_rate, 500);
+        assert!((interest.ui_supply.parse::<f64>().unwrap() - 100.0).abs() < 0.01);
+
+        // 5% continuously compounded for a year: 100 * e^0.05
+        let after_a_year = mint_info(&data, &TOKEN_2022_PROGRAM_ID, SECONDS_PER_YEAR).unwrap();
+        let ui_supply = after_a_year.interest_bearing.unwrap().ui_supply;
+        assert!((ui_supply.parse::<f64>().unwrap() - 105.127).abs() < 0.01);
+        assert_eq!(after_a_year.supply, "10000");
+    }
+}
@@ -1,4 +1,18 @@
+/// Token program authority types
+pub mod authority;
+/// Holding account parsing, including Token-2022 extensions
+pub mod holding_account;
+/// On-chain token metadata: the Token-2022 extension and Metaplex accounts
+pub mod metadata;
+/// Mint parsing and Token-2022 mint extensions
+pub mod mint;
 /// Token program service implementation
 pub mod service_impl;
+/// Holding account space for Token-2022 extensions
+pub mod space;
+/// Mint supply and largest holder conversion
+pub mod supply;
 /// Token program API wrapper
 pub mod token_v1_api;
+/// Transfer amounts and Token-2022 transfer fees
+pub mod transfer;
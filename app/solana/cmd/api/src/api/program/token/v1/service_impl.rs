@@ -2,72 +2,223 @@ use std::sync::Arc;
 use tonic::{Request, Response, Status};
 
 use protochain_api::protochain::solana::program::token::v1::{
-    service_server::Service as TokenProgramService, CreateHoldingAccountRequest,
-    CreateHoldingAccountResponse, CreateMintRequest, CreateMintResponse,
-    GetCurrentMinRentForHoldingAccountRequest, GetCurrentMinRentForHoldingAccountResponse,
-    GetCurrentMinRentForTokenAccountRequest, GetCurrentMinRentForTokenAccountResponse,
-    InitialiseHoldingAccountRequest, InitialiseHoldingAccountResponse, InitialiseMintRequest,
-    InitialiseMintResponse, MintInfo, MintRequest, MintResponse, ParseMintRequest,
-    ParseMintResponse,
+    service_server::Service as TokenProgramService, ApproveRequest, ApproveResponse,
+    BuildTokenTransferRequest, BuildTokenTransferResponse, BurnRequest, BurnResponse,
+    CalculateTokenAccountSizeRequest, CalculateTokenAccountSizeResponse, CloseAccountRequest,
+    CloseAccountResponse, CreateAssociatedHoldingAccountRequest,
+    CreateAssociatedHoldingAccountResponse, CreateHoldingAccountRequest,
+    CreateHoldingAccountResponse, CreateMintRequest, CreateMintResponse, FreezeAccountRequest,
+    FreezeAccountResponse, GetCurrentMinRentForHoldingAccountRequest,
+    GetCurrentMinRentForHoldingAccountResponse, GetCurrentMinRentForTokenAccountRequest,
+    GetCurrentMinRentForTokenAccountResponse, GetMintSupplyRequest, GetMintSupplyResponse,
+    GetTokenMetadataRequest, GetTokenMetadataResponse, InitialiseHoldingAccountRequest,
+    InitialiseHoldingAccountResponse, InitialiseMintRequest, InitialiseMintResponse,
+    ListLargestHoldersRequest, ListLargestHoldersResponse, MintRequest, MintResponse,
+    OffChainMetadataStatus, OffChainTokenMetadata, ParseHoldingAccountRequest,
+    ParseHoldingAccountResponse, ParseMintRequest, ParseMintResponse, RevokeRequest,
+    RevokeResponse, SetAuthorityRequest, SetAuthorityResponse, ThawAccountRequest,
+    ThawAccountResponse, TokenAuthorityType, TokenMetadataAttribute, TransferCheckedRequest,
+    TransferCheckedResponse, TransferRequest, TransferResponse,
 };
 
 use solana_client::rpc_client::RpcClient;
-use solana_sdk::{commitment_config::CommitmentConfig, program_pack::Pack, pubkey::Pubkey};
+use solana_sdk::{
+    clock::Clock, commitment_config::CommitmentConfig, instruction::Instruction,
+    program_pack::Pack, pubkey::Pubkey, sysvar,
+};
 use spl_token_2022::{
-    extension::{memo_transfer::instruction::enable_required_transfer_memos, ExtensionType},
-    instruction::{initialize_account, initialize_mint2, mint_to_checked},
-    state::{Account, Mint},
+    extension::{
+        cpi_guard::instruction::enable_cpi_guard,
+        memo_transfer::instruction::enable_required_transfer_memos,
+        transfer_fee::instruction::transfer_checked_with_fee, ExtensionType,
+    },
+    instruction::{
+        approve_checked, burn_checked, close_account, freeze_account, initialize_account,
+        initialize_immutable_owner, initialize_mint2, mint_to_checked, revoke, set_authority,
+        thaw_account, transfer, transfer_checked,
+    },
+    state::Mint,
     ID as TOKEN_2022_PROGRAM_ID,
 };
 use std::str::FromStr;
 
+use crate::api::account::v1::derivation::{derive_associated_token_address, resolve_token_program};
+use crate::api::common::amount_parsing::parse_amount;
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+use crate::api::common::min_context_slot::{
+    get_account, get_multiple_accounts, min_context_slot, read_error_status,
+};
 use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+use crate::api::program::ata::v1::instructions::create as create_associated_account;
 use crate::api::program::system::v1::service_impl::SystemProgramServiceImpl;
+use crate::api::program::token::v1::authority::authority_type;
+use crate::api::program::token::v1::holding_account::holding_account_info;
+use crate::api::program::token::v1::metadata::{metadata_to_proto, read_on_chain_metadata};
+use crate::api::program::token::v1::mint::{is_interest_bearing, mint_info, MintExtensions};
+use crate::api::program::token::v1::space::{
+    check_token_program, holding_account_extensions, holding_account_len,
+};
+use crate::api::program::token::v1::supply::{largest_holders, mint_supply};
+use crate::api::program::token::v1::transfer::{transfer_amounts, TransferAmounts};
+use crate::api::transaction::v1::memo::signed_memo_instruction;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+use crate::service_providers::token_metadata::{MetadataFetchError, TokenMetadataFetcher};
 use protochain_api::protochain::solana::program::system::v1::{
     service_server::Service as SystemProgramService, CreateRequest as SystemCreateRequest,
 };
 
-/// Token Program service implementation for Token 2022 operations
+/// Token Program service implementation for SPL Token and Token 2022 operations
 #[derive(Clone)]
 pub struct TokenProgramServiceImpl {
     /// Solana RPC client for blockchain interactions
     rpc_client: Arc<RpcClient>,
+    /// Fetcher of off-chain metadata documents
+    token_metadata: Arc<TokenMetadataFetcher>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
 }
 
 impl TokenProgramServiceImpl {
-    /// Creates a new `TokenProgramServiceImpl` instance with the provided RPC client
-    pub const fn new(rpc_client: Arc<RpcClient>) -> Self {
-        Self { rpc_client }
+    /// Creates a new `TokenProgramServiceImpl` instance with the provided RPC client,
+    /// off-chain metadata fetcher and RPC concurrency limiter
+    pub const fn new(
+        rpc_client: Arc<RpcClient>,
+        token_metadata: Arc<TokenMetadataFetcher>,
+        rpc_limiter: Arc<RpcLimiter>,
+    ) -> Self {
+        Self {
+            rpc_client,
+            token_metadata,
+            rpc_limiter,
+        }
     }
-}
 
-#[allow(clippy::result_large_err)]
-fn holding_account_space(require_memo: bool) -> Result<u64, Status> {
-    if !require_memo {
-        return Ok(Account::LEN as u64);
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
     }
 
-    let len = ExtensionType::try_calculate_account_len::<Account>(&[ExtensionType::MemoTransfer])
-        .map_err(|e| {
-        Status::internal(format!("failed to calculate memo-transfer account length: {e}"))
-    })?;
+    /// Reads `mint` for its token program and works out a checked transfer of `amount`,
+    /// rejecting expected `decimals` that differ from the mint's
+    async fn checked_transfer_amounts(
+        &self,
+        mint: &Pubkey,
+        amount: u64,
+        decimals: Option<u32>,
+        recipient_receives_amount: bool,
+        min_slot: u64,
+    ) -> Result<(Pubkey, TransferAmounts), Status> {
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let mint_account = get_account(
+            &self.rpc_client,
+            mint,
+            CommitmentConfig::confirmed(),
+            min_context_slot(min_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get mint"))?
+        .ok_or_else(|| Status::not_found("Mint not found"))?;
+        let token_program = mint_account.owner;
+        let epoch = if token_program == TOKEN_2022_PROGRAM_ID {
+            self.rpc_client
+                .get_epoch_info()
+                .map_err(|e| Status::unavailable(format!("Failed to get epoch: {e}")))?
+                .epoch
+        } else if token_program == TOKEN_PROGRAM_ID {
+            0
+        } else {
+            return Err(Status::invalid_argument(
+                "Mint is not owned by SPL Token or Token 2022 program",
+            ));
+        };
 
-    u64::try_from(len).map_err(|_| Status::internal("memo-transfer account length overflow"))
+        let amounts =
+            transfer_amounts(&mint_account.data, epoch, amount, recipient_receives_amount)
+                .map_err(Status::invalid_argument)?;
+        if let Some(decimals) = decimals {
+            if decimals != u32::from(amounts.decimals) {
+                return Err(Status::invalid_argument(format!(
+                    "decimals {decimals} do not match the mint's {}",
+                    amounts.decimals
+                )));
+            }
+        }
+        Ok((token_program, amounts))
+    }
 }
 
+/// Parses a public key field of a request
 #[allow(clippy::result_large_err)]
-fn memo_rent_lamports(rpc: &RpcClient, require_memo: bool) -> Result<u64, Status> {
-    let space = holding_account_space(require_memo)?;
-    let space_usize = usize::try_from(space)
-        .map_err(|_| Status::internal("memo-transfer account length overflow"))?;
+fn parse_pub_key(field: &str, value: &str) -> Result<Pubkey, Status> {
+    Pubkey::from_str(value).map_err(|e| Status::invalid_argument(format!("Invalid {field}: {e}")))
+}
 
-    rpc.get_minimum_balance_for_rent_exemption(space_usize)
-        .map_err(|e| Status::internal(format!("failed to fetch memo-aware rent: {e}")))
+/// Validates a decimals field
+#[allow(clippy::result_large_err)]
+fn parse_decimals(decimals: u32) -> Result<u8, Status> {
+    u8::try_from(decimals)
+        .map_err(|_| Status::invalid_argument("decimals must be between 0 and 255"))
+}
+
+/// Space and rent-exempt minimum of a holding account with the given extensions
+#[allow(clippy::result_large_err)]
+fn holding_account_space_and_rent(
+    rpc: &RpcClient,
+    extensions: &[ExtensionType],
+) -> Result<(u64, u64), Status> {
+    let space = holding_account_len(extensions).map_err(Status::internal)?;
+    let lamports = rpc
+        .get_minimum_balance_for_rent_exemption(space)
+        .map_err(|e| Status::internal(format!("failed to fetch extension-aware rent: {e}")))?;
+
+    Ok((space as u64, lamports))
+}
+
+/// Creates a `TransferChecked` instruction, or `TransferCheckedWithFee` pinning the fee for
+/// mints with a transfer fee
+#[allow(clippy::result_large_err)]
+fn transfer_checked_instruction(
+    token_program: &Pubkey,
+    source: &Pubkey,
+    mint: &Pubkey,
+    destination: &Pubkey,
+    owner: &Pubkey,
+    amounts: &TransferAmounts,
+) -> Result<Instruction, Status> {
+    match amounts.fee {
+        Some(fee) => transfer_checked_with_fee(
+            token_program,
+            source,
+            mint,
+            destination,
+            owner,
+            &[], // Empty signer array for single owner
+            amounts.amount,
+            amounts.decimals,
+            fee,
+        ),
+        None => transfer_checked(
+            token_program,
+            source,
+            mint,
+            destination,
+            owner,
+            &[], // Empty signer array for single owner
+            amounts.amount,
+            amounts.decimals,
+        ),
+    }
+    .map_err(|e| {
+        Status::invalid_argument(format!("Failed to create TransferChecked instruction: {e}"))
+    })
 }
 
 #[tonic::async_trait]
 impl TokenProgramService for TokenProgramServiceImpl {
-    /// Creates an `InitialiseMint` instruction for Token 2022 program
+    /// Creates an `InitialiseMint` instruction for the selected token program
     async fn initialise_mint(
         &self,
         request: Request<InitialiseMintRequest>,
@@ -89,10 +240,12 @@ impl TokenProgramService for TokenProgramServiceImpl {
                 Status::invalid_argument(format!("Invalid freeze_authority_pub_key: {e}"))
             })?)
         };
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
 
         // Create the InitialiseMint instruction
         let instruction = initialize_mint2(
-            &TOKEN_2022_PROGRAM_ID,
+            &token_program,
             &mint_pubkey,
             &mint_authority,
             freeze_authority.as_ref(),
@@ -103,10 +256,26 @@ impl TokenProgramService for TokenProgramServiceImpl {
             Status::invalid_argument(format!("Failed to create InitialiseMint instruction: {e}"))
         })?;
 
+        // Extension initialisation must precede InitialiseMint
+        let extensions =
+            MintExtensions::from_request(req.interest_bearing.as_ref(), req.non_transferable)
+                .map_err(Status::invalid_argument)?;
+        extensions
+            .check_token_program(&token_program)
+            .map_err(Status::invalid_argument)?;
+        let mut instructions: Vec<_> = extensions
+            .instructions(&mint_pubkey)
+            .map_err(Status::invalid_argument)?
+            .into_iter()
+            .map(sdk_instruction_to_proto)
+            .collect();
+
         // Convert to proto and return
         let proto_instruction = sdk_instruction_to_proto(instruction);
+        instructions.push(proto_instruction.clone());
         Ok(Response::new(InitialiseMintResponse {
             instruction: Some(proto_instruction),
+            instructions,
         }))
     }
 
@@ -116,6 +285,7 @@ impl TokenProgramService for TokenProgramServiceImpl {
         _request: Request<GetCurrentMinRentForTokenAccountRequest>,
     ) -> Result<Response<GetCurrentMinRentForTokenAccountResponse>, Status> {
         // Get minimum balance for rent exemption using Mint::LEN
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
         match self
             .rpc_client
             .get_minimum_balance_for_rent_exemption(Mint::LEN)
@@ -142,43 +312,83 @@ impl TokenProgramService for TokenProgramServiceImpl {
             .map_err(|e| Status::invalid_argument(format!("Invalid account_address: {e}")))?;
 
         // Get the account data
-        let account = self
-            .rpc_client
-            .get_account_with_commitment(&account_pubkey, CommitmentConfig::confirmed())
-            .map_err(|e| Status::internal(format!("Failed to get account: {e}")))?
-            .value
-            .ok_or_else(|| Status::not_found("Account not found"))?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let account = get_account(
+            &self.rpc_client,
+            &account_pubkey,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
 
-        // Verify the account is owned by the Token 2022 program
-        if account.owner != TOKEN_2022_PROGRAM_ID {
-            return Err(Status::invalid_argument("Account is not owned by Token 2022 program"));
+        // Verify the account is owned by a token program
+        if account.owner != TOKEN_2022_PROGRAM_ID && account.owner != TOKEN_PROGRAM_ID {
+            return Err(Status::invalid_argument(
+                "Account is not owned by SPL Token or Token 2022 program",
+            ));
         }
 
-        // Unpack the mint account data
-        let mint = Mint::unpack(&account.data)
-            .map_err(|e| Status::invalid_argument(format!("Failed to parse mint account: {e}")))?;
-
-        // Convert to proto format
-        let mint_info = MintInfo {
-            mint_authority_pub_key: mint
-                .mint_authority
-                .map(|key| key.to_string())
-                .unwrap_or_default(),
-            freeze_authority_pub_key: mint
-                .freeze_authority
-                .map(|key| key.to_string())
-                .unwrap_or_default(),
-            decimals: u32::from(mint.decimals),
-            supply: mint.supply.to_string(),
-            is_initialized: mint.is_initialized,
+        // Interest accrues with cluster time, so read the clock for interest-bearing mints
+        let unix_timestamp = if is_interest_bearing(&account.data) {
+            let clock_account = get_account(
+                &self.rpc_client,
+                &sysvar::clock::id(),
+                CommitmentConfig::confirmed(),
+                min_context_slot(req.min_context_slot),
+            )
+            .map_err(|e| read_error_status(&e, "Failed to get clock"))?
+            .ok_or_else(|| Status::internal("Clock sysvar not found"))?;
+            bincode::deserialize::<Clock>(&clock_account.data)
+                .map_err(|e| Status::internal(format!("Failed to parse clock: {e}")))?
+                .unix_timestamp
+        } else {
+            0
         };
 
+        // Unpack the mint account data, including extensions
+        let mint_info = mint_info(&account.data, &account.owner, unix_timestamp)
+            .map_err(Status::invalid_argument)?;
+
         Ok(Response::new(ParseMintResponse {
             mint: Some(mint_info),
         }))
     }
 
-    /// Creates an `InitialiseHoldingAccount` instruction for Token 2022 program
+    /// Parses SPL Token or Token 2022 holding account data
+    async fn parse_holding_account(
+        &self,
+        request: Request<ParseHoldingAccountRequest>,
+    ) -> Result<Response<ParseHoldingAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let account_pubkey = parse_pub_key("account_address", &req.account_address)?;
+
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let account = get_account(
+            &self.rpc_client,
+            &account_pubkey,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
+
+        if account.owner != TOKEN_2022_PROGRAM_ID && account.owner != TOKEN_PROGRAM_ID {
+            return Err(Status::invalid_argument(
+                "Account is not owned by SPL Token or Token 2022 program",
+            ));
+        }
+
+        let info = holding_account_info(&account.data, &account.owner)
+            .map_err(Status::invalid_argument)?;
+
+        Ok(Response::new(ParseHoldingAccountResponse {
+            account: Some(info),
+        }))
+    }
+
+    /// Creates an `InitialiseHoldingAccount` instruction for the selected token program
     async fn initialise_holding_account(
         &self,
         request: Request<InitialiseHoldingAccountRequest>,
@@ -197,39 +407,61 @@ impl TokenProgramService for TokenProgramServiceImpl {
             .map_err(|e| Status::invalid_argument(format!("Invalid mint_pub_key: {e}")))?;
         let owner_pubkey = Pubkey::from_str(&req.owner_pub_key)
             .map_err(|e| Status::invalid_argument(format!("Invalid owner_pub_key: {e}")))?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        let extensions = holding_account_extensions(&req.extensions, require_memo)
+            .map_err(Status::invalid_argument)?;
+        check_token_program(&extensions, &token_program).map_err(Status::invalid_argument)?;
+        let require_memo = extensions.contains(&ExtensionType::MemoTransfer);
+        let cpi_guard = extensions.contains(&ExtensionType::CpiGuard);
+
+        // ImmutableOwner must be initialised before the account itself
+        let mut instruction_list = Vec::with_capacity(extensions.len() + 1);
+        if extensions.contains(&ExtensionType::ImmutableOwner) {
+            let immutable_owner_instruction =
+                initialize_immutable_owner(&token_program, &account_pubkey).map_err(|e| {
+                    Status::invalid_argument(format!(
+                        "Failed to create immutable owner initialise instruction: {e}"
+                    ))
+                })?;
+            instruction_list.push(sdk_instruction_to_proto(immutable_owner_instruction));
+        }
 
         // Create the InitializeAccount instruction
-        let init_instruction = initialize_account(
-            &TOKEN_2022_PROGRAM_ID,
-            &account_pubkey,
-            &mint_pubkey,
-            &owner_pubkey,
-        )
-        .map_err(|e| {
-            Status::invalid_argument(format!(
-                "Failed to create InitialiseHoldingAccount instruction: {e}"
-            ))
-        })?;
+        let init_instruction =
+            initialize_account(&token_program, &account_pubkey, &mint_pubkey, &owner_pubkey)
+                .map_err(|e| {
+                    Status::invalid_argument(format!(
+                        "Failed to create InitialiseHoldingAccount instruction: {e}"
+                    ))
+                })?;
 
         let init_proto = sdk_instruction_to_proto(init_instruction);
-        let mut instruction_list = Vec::with_capacity(if require_memo { 2 } else { 1 });
         instruction_list.push(init_proto.clone());
 
         if require_memo {
-            let memo_instruction = enable_required_transfer_memos(
-                &TOKEN_2022_PROGRAM_ID,
-                &account_pubkey,
-                &owner_pubkey,
-                &[],
-            )
-            .map_err(|e| {
-                Status::invalid_argument(format!(
-                    "Failed to create memo transfer enable instruction: {e}"
-                ))
-            })?;
+            let memo_instruction =
+                enable_required_transfer_memos(&token_program, &account_pubkey, &owner_pubkey, &[])
+                    .map_err(|e| {
+                        Status::invalid_argument(format!(
+                            "Failed to create memo transfer enable instruction: {e}"
+                        ))
+                    })?;
             instruction_list.push(sdk_instruction_to_proto(memo_instruction));
         }
 
+        if cpi_guard {
+            let cpi_guard_instruction =
+                enable_cpi_guard(&token_program, &account_pubkey, &owner_pubkey, &[]).map_err(
+                    |e| {
+                        Status::invalid_argument(format!(
+                            "Failed to create CPI guard enable instruction: {e}"
+                        ))
+                    },
+                )?;
+            instruction_list.push(sdk_instruction_to_proto(cpi_guard_instruction));
+        }
+
         Ok(Response::new(InitialiseHoldingAccountResponse {
             instruction: Some(init_proto),
             instructions: instruction_list,
@@ -247,11 +479,29 @@ impl TokenProgramService for TokenProgramServiceImpl {
             .as_ref()
             .is_some_and(|cfg| cfg.require_incoming_memo);
 
-        let lamports = memo_rent_lamports(&self.rpc_client, require_memo)?;
+        let extensions =
+            holding_account_extensions(&[], require_memo).map_err(Status::invalid_argument)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let (_, lamports) = holding_account_space_and_rent(&self.rpc_client, &extensions)?;
         let response = GetCurrentMinRentForHoldingAccountResponse { lamports };
         Ok(Response::new(response))
     }
 
+    /// Calculates the exact space and rent of a holding account with the requested extensions
+    async fn calculate_token_account_size(
+        &self,
+        request: Request<CalculateTokenAccountSizeRequest>,
+    ) -> Result<Response<CalculateTokenAccountSizeResponse>, Status> {
+        let req = request.into_inner();
+
+        let extensions =
+            holding_account_extensions(&req.extensions, false).map_err(Status::invalid_argument)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let (space, lamports) = holding_account_space_and_rent(&self.rpc_client, &extensions)?;
+
+        Ok(Response::new(CalculateTokenAccountSizeResponse { space, lamports }))
+    }
+
     /// Creates both system account creation and mint initialization instructions
     async fn create_mint(
         &self,
@@ -270,13 +520,23 @@ impl TokenProgramService for TokenProgramServiceImpl {
             return Err(Status::invalid_argument("mint_pub_key must match new_account"));
         }
 
-        // Step 1: Get current rent for mint account
-        let rent_response = self
-            .get_current_min_rent_for_token_account(Request::new(
-                GetCurrentMinRentForTokenAccountRequest {},
-            ))
-            .await?
-            .into_inner();
+        // Step 1: Get current rent for mint account, sized for any extensions
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        let extensions =
+            MintExtensions::from_request(req.interest_bearing.as_ref(), req.non_transferable)
+                .map_err(Status::invalid_argument)?;
+        extensions
+            .check_token_program(&token_program)
+            .map_err(Status::invalid_argument)?;
+        let space = extensions.mint_space().map_err(Status::internal)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let lamports = self
+            .rpc_client
+            .get_minimum_balance_for_rent_exemption(space)
+            .map_err(|e| {
+                Status::internal(format!("Failed to get minimum balance for mint account: {e}"))
+            })?;
 
         // Step 2: Create system account creation instruction
         let system_service = SystemProgramServiceImpl::new();
@@ -284,9 +544,9 @@ impl TokenProgramService for TokenProgramServiceImpl {
             .create(Request::new(SystemCreateRequest {
                 payer: req.payer.clone(),
                 new_account: req.new_account.clone(),
-                owner: TOKEN_2022_PROGRAM_ID.to_string(),
-                lamports: rent_response.lamports,
-                space: Mint::LEN as u64,
+                owner: token_program.to_string(),
+                lamports,
+                space: space as u64,
             }))
             .await?
             .into_inner();
@@ -298,16 +558,16 @@ impl TokenProgramService for TokenProgramServiceImpl {
                 mint_authority_pub_key: req.mint_authority_pub_key,
                 freeze_authority_pub_key: req.freeze_authority_pub_key,
                 decimals: req.decimals,
+                interest_bearing: req.interest_bearing,
+                non_transferable: req.non_transferable,
+                token_program_id: token_program.to_string(),
             }))
             .await?
             .into_inner();
 
-        // Step 4: Compose response with both instructions
-        let mut instructions = Vec::new();
-        instructions.push(create_instruction);
-        if let Some(init_instruction) = init_response.instruction {
-            instructions.push(init_instruction);
-        }
+        // Step 4: Compose response with account creation first
+        let mut instructions = vec![create_instruction];
+        instructions.extend(init_response.instructions);
 
         Ok(Response::new(CreateMintResponse { instructions }))
     }
@@ -335,8 +595,13 @@ impl TokenProgramService for TokenProgramServiceImpl {
             .as_ref()
             .is_some_and(|cfg| cfg.require_incoming_memo);
 
-        let space = holding_account_space(require_memo)?;
-        let rent_lamports = memo_rent_lamports(&self.rpc_client, require_memo)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        let extensions = holding_account_extensions(&req.extensions, require_memo)
+            .map_err(Status::invalid_argument)?;
+        check_token_program(&extensions, &token_program).map_err(Status::invalid_argument)?;
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let (space, rent_lamports) = holding_account_space_and_rent(&self.rpc_client, &extensions)?;
 
         // Step 2: Create system account creation instruction
         let system_service = SystemProgramServiceImpl::new();
@@ -344,7 +609,7 @@ impl TokenProgramService for TokenProgramServiceImpl {
             .create(Request::new(SystemCreateRequest {
                 payer: req.payer.clone(),
                 new_account: req.new_account.clone(),
-                owner: TOKEN_2022_PROGRAM_ID.to_string(),
+                owner: token_program.to_string(),
                 lamports: rent_lamports,
                 space,
             }))
@@ -358,6 +623,8 @@ impl TokenProgramService for TokenProgramServiceImpl {
                 mint_pub_key: req.mint_pub_key,
                 owner_pub_key: req.owner_pub_key,
                 memo_transfer_config: req.memo_transfer_config,
+                extensions: req.extensions,
+                token_program_id: token_program.to_string(),
             }))
             .await?
             .into_inner();
@@ -370,7 +637,40 @@ impl TokenProgramService for TokenProgramServiceImpl {
         Ok(Response::new(CreateHoldingAccountResponse { instructions }))
     }
 
-    /// Creates a `MintToChecked` instruction for Token 2022 program
+    /// Creates an owner's associated holding account for a mint, deriving its address
+    async fn create_associated_holding_account(
+        &self,
+        request: Request<CreateAssociatedHoldingAccountRequest>,
+    ) -> Result<Response<CreateAssociatedHoldingAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        // Validation
+        if req.payer.is_empty() {
+            return Err(Status::invalid_argument("Payer address is required"));
+        }
+        let payer_pubkey = parse_pub_key("payer", &req.payer)?;
+        let owner_pubkey = parse_pub_key("owner_pub_key", &req.owner_pub_key)?;
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        // Idempotent, so the bundle also succeeds when the account already exists
+        let instruction = create_associated_account(
+            &payer_pubkey,
+            &owner_pubkey,
+            &mint_pubkey,
+            &token_program,
+            true,
+        );
+        let holding_account = instruction.accounts[1].pubkey;
+
+        Ok(Response::new(CreateAssociatedHoldingAccountResponse {
+            instructions: vec![sdk_instruction_to_proto(instruction)],
+            holding_account_pub_key: holding_account.to_string(),
+        }))
+    }
+
+    /// Creates a `MintToChecked` instruction for the selected token program
     async fn mint(&self, request: Request<MintRequest>) -> Result<Response<MintResponse>, Status> {
         let req = request.into_inner();
 
@@ -385,19 +685,18 @@ impl TokenProgramService for TokenProgramServiceImpl {
             Status::invalid_argument(format!("Invalid mint_authority_pub_key: {e}"))
         })?;
 
-        // Parse amount from string to handle large numbers
-        let amount = req
-            .amount
-            .parse::<u64>()
-            .map_err(|e| Status::invalid_argument(format!("Invalid amount: {e}")))?;
+        // Amounts are whole base units given as a string to handle large numbers
+        let amount = parse_amount(&req.amount, 0).map_err(|e| e.into_status("amount"))?;
 
         // Validate decimals
         let decimals = u8::try_from(req.decimals)
             .map_err(|_| Status::invalid_argument("decimals must be between 0 and 255"))?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
 
         // Create the MintToChecked instruction (no additional signers for single authority)
         let instruction = mint_to_checked(
-            &TOKEN_2022_PROGRAM_ID,
+            &token_program,
             &mint_pubkey,
             &destination_account_pubkey,
             &mint_authority_pubkey,
@@ -415,4 +714,515 @@ impl TokenProgramService for TokenProgramServiceImpl {
             instruction: Some(proto_instruction),
         }))
     }
+
+    /// Resolves a mint's metadata and its off-chain JSON document
+    async fn get_token_metadata(
+        &self,
+        request: Request<GetTokenMetadataRequest>,
+    ) -> Result<Response<GetTokenMetadataResponse>, Status> {
+        let req = request.into_inner();
+        let mint = Pubkey::from_str(&req.mint_pub_key)
+            .map_err(|e| Status::invalid_argument(format!("Invalid mint_pub_key: {e}")))?;
+
+        let permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let on_chain = read_on_chain_metadata(
+            &self.rpc_client,
+            &mint,
+            min_context_slot(req.min_context_slot),
+        )?
+        .metadata;
+        drop(permit);
+        let mut metadata = metadata_to_proto(&mint, on_chain);
+
+        let status = if req.skip_off_chain {
+            OffChainMetadataStatus::Skipped
+        } else if metadata.uri.is_empty() {
+            OffChainMetadataStatus::NoUri
+        } else {
+            match self.token_metadata.fetch(&metadata.uri).await {
+                Ok(document) => {
+                    metadata.off_chain = Some(OffChainTokenMetadata {
+                        name: document.name,
+                        symbol: document.symbol,
+                        description: document.description,
+                        image: document.image,
+                        external_url: document.external_url,
+                        attributes: document
+                            .attributes
+                            .into_iter()
+                            .map(|(trait_type, value)| TokenMetadataAttribute { trait_type, value })
+                            .collect(),
+                    });
+                    OffChainMetadataStatus::Ok
+                }
+                Err(e) => {
+                    metadata.off_chain_error = e.to_string();
+                    match e {
+                        MetadataFetchError::Fetch(_) => OffChainMetadataStatus::FetchFailed,
+                        MetadataFetchError::Invalid(_) => OffChainMetadataStatus::Invalid,
+                    }
+                }
+            }
+        };
+        metadata.off_chain_status = status.into();
+
+        Ok(Response::new(GetTokenMetadataResponse {
+            metadata: Some(metadata),
+        }))
+    }
+
+    /// Creates a `Transfer` instruction for SPL Token or Token 2022 program
+    async fn transfer(
+        &self,
+        request: Request<TransferRequest>,
+    ) -> Result<Response<TransferResponse>, Status> {
+        let req = request.into_inner();
+
+        // Parse public keys
+        let source_pubkey = Pubkey::from_str(&req.source_account_pub_key).map_err(|e| {
+            Status::invalid_argument(format!("Invalid source_account_pub_key: {e}"))
+        })?;
+        let destination_pubkey =
+            Pubkey::from_str(&req.destination_account_pub_key).map_err(|e| {
+                Status::invalid_argument(format!("Invalid destination_account_pub_key: {e}"))
+            })?;
+        let owner_pubkey = Pubkey::from_str(&req.owner_pub_key)
+            .map_err(|e| Status::invalid_argument(format!("Invalid owner_pub_key: {e}")))?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        // Amounts are whole base units given as a string to handle large numbers
+        let amount = parse_amount(&req.amount, 0).map_err(|e| e.into_status("amount"))?;
+
+        let instruction = transfer(
+            &token_program,
+            &source_pubkey,
+            &destination_pubkey,
+            &owner_pubkey,
+            &[], // Empty signer array for single owner
+            amount,
+        )
+        .map_err(|e| {
+            Status::invalid_argument(format!("Failed to create Transfer instruction: {e}"))
+        })?;
+
+        Ok(Response::new(TransferResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates a `TransferChecked` instruction, or `TransferCheckedWithFee` for Token 2022
+    /// mints charging a transfer fee
+    async fn transfer_checked(
+        &self,
+        request: Request<TransferCheckedRequest>,
+    ) -> Result<Response<TransferCheckedResponse>, Status> {
+        let req = request.into_inner();
+
+        // Parse public keys
+        let source_pubkey = Pubkey::from_str(&req.source_account_pub_key).map_err(|e| {
+            Status::invalid_argument(format!("Invalid source_account_pub_key: {e}"))
+        })?;
+        let mint_pubkey = Pubkey::from_str(&req.mint_pub_key)
+            .map_err(|e| Status::invalid_argument(format!("Invalid mint_pub_key: {e}")))?;
+        let destination_pubkey =
+            Pubkey::from_str(&req.destination_account_pub_key).map_err(|e| {
+                Status::invalid_argument(format!("Invalid destination_account_pub_key: {e}"))
+            })?;
+        let owner_pubkey = Pubkey::from_str(&req.owner_pub_key)
+            .map_err(|e| Status::invalid_argument(format!("Invalid owner_pub_key: {e}")))?;
+
+        // Amounts are whole base units given as a string to handle large numbers
+        let amount = parse_amount(&req.amount, 0).map_err(|e| e.into_status("amount"))?;
+
+        // The mint decides the token program, its decimals and any transfer fee
+        let (token_program, amounts) = self
+            .checked_transfer_amounts(
+                &mint_pubkey,
+                amount,
+                req.decimals,
+                req.recipient_receives_amount,
+                req.min_context_slot,
+            )
+            .await?;
+        let instruction = transfer_checked_instruction(
+            &token_program,
+            &source_pubkey,
+            &mint_pubkey,
+            &destination_pubkey,
+            &owner_pubkey,
+            &amounts,
+        )?;
+
+        Ok(Response::new(TransferCheckedResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+            amount: amounts.amount.to_string(),
+            fee: amounts.fee.unwrap_or(0).to_string(),
+            received_amount: amounts.received().to_string(),
+            decimals: u32::from(amounts.decimals),
+            token_program_id: token_program.to_string(),
+        }))
+    }
+
+    /// Builds a transfer between wallets' associated holding accounts, creating the
+    /// recipient's account first when it does not exist yet
+    async fn build_token_transfer(
+        &self,
+        request: Request<BuildTokenTransferRequest>,
+    ) -> Result<Response<BuildTokenTransferResponse>, Status> {
+        let req = request.into_inner();
+
+        let sender_pubkey = parse_pub_key("sender_pub_key", &req.sender_pub_key)?;
+        let recipient_pubkey = parse_pub_key("recipient_pub_key", &req.recipient_pub_key)?;
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+        let payer_pubkey = if req.payer.is_empty() {
+            sender_pubkey
+        } else {
+            parse_pub_key("payer", &req.payer)?
+        };
+        let amount = parse_amount(&req.amount, 0).map_err(|e| e.into_status("amount"))?;
+        if amount == 0 {
+            return Err(Status::invalid_argument("amount must be greater than 0"));
+        }
+
+        let (token_program, amounts) = self
+            .checked_transfer_amounts(
+                &mint_pubkey,
+                amount,
+                req.decimals,
+                req.recipient_receives_amount,
+                req.min_context_slot,
+            )
+            .await?;
+        let (source_pubkey, _) =
+            derive_associated_token_address(&sender_pubkey, &mint_pubkey, &token_program);
+        let (destination_pubkey, _) =
+            derive_associated_token_address(&recipient_pubkey, &mint_pubkey, &token_program);
+
+        let permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let creates_destination_account = get_account(
+            &self.rpc_client,
+            &destination_pubkey,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get destination account"))?
+        .is_none();
+        drop(permit);
+
+        let mut instructions = Vec::with_capacity(3);
+        // Idempotent, so the transfer still lands if another transaction creates it first
+        if creates_destination_account {
+            instructions.push(create_associated_account(
+                &payer_pubkey,
+                &recipient_pubkey,
+                &mint_pubkey,
+                &token_program,
+                true,
+            ));
+        }
+        // Recipients that require incoming transfer memos expect it directly before
+        if !req.memo.is_empty() {
+            instructions.push(
+                signed_memo_instruction(&req.memo, &[sender_pubkey])
+                    .map_err(Status::invalid_argument)?,
+            );
+        }
+        instructions.push(transfer_checked_instruction(
+            &token_program,
+            &source_pubkey,
+            &mint_pubkey,
+            &destination_pubkey,
+            &sender_pubkey,
+            &amounts,
+        )?);
+
+        Ok(Response::new(BuildTokenTransferResponse {
+            instructions: instructions
+                .into_iter()
+                .map(sdk_instruction_to_proto)
+                .collect(),
+            source_account_pub_key: source_pubkey.to_string(),
+            destination_account_pub_key: destination_pubkey.to_string(),
+            creates_destination_account,
+            amount: amounts.amount.to_string(),
+            fee: amounts.fee.unwrap_or(0).to_string(),
+            received_amount: amounts.received().to_string(),
+            decimals: u32::from(amounts.decimals),
+            token_program_id: token_program.to_string(),
+        }))
+    }
+
+    /// Creates a `BurnChecked` instruction for SPL Token or Token 2022 program
+    async fn burn(&self, request: Request<BurnRequest>) -> Result<Response<BurnResponse>, Status> {
+        let req = request.into_inner();
+
+        let account_pubkey = parse_pub_key("account_pub_key", &req.account_pub_key)?;
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+        let owner_pubkey = parse_pub_key("owner_pub_key", &req.owner_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        let amount = parse_amount(&req.amount, 0).map_err(|e| e.into_status("amount"))?;
+        let decimals = parse_decimals(req.decimals)?;
+
+        let instruction = burn_checked(
+            &token_program,
+            &account_pubkey,
+            &mint_pubkey,
+            &owner_pubkey,
+            &[], // Empty signer array for single owner
+            amount,
+            decimals,
+        )
+        .map_err(|e| {
+            Status::invalid_argument(format!("Failed to create BurnChecked instruction: {e}"))
+        })?;
+
+        Ok(Response::new(BurnResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates an `ApproveChecked` instruction for SPL Token or Token 2022 program
+    async fn approve(
+        &self,
+        request: Request<ApproveRequest>,
+    ) -> Result<Response<ApproveResponse>, Status> {
+        let req = request.into_inner();
+
+        let source_pubkey = parse_pub_key("source_account_pub_key", &req.source_account_pub_key)?;
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+        let delegate_pubkey = parse_pub_key("delegate_pub_key", &req.delegate_pub_key)?;
+        let owner_pubkey = parse_pub_key("owner_pub_key", &req.owner_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        let amount = parse_amount(&req.amount, 0).map_err(|e| e.into_status("amount"))?;
+        let decimals = parse_decimals(req.decimals)?;
+
+        let instruction = approve_checked(
+            &token_program,
+            &source_pubkey,
+            &mint_pubkey,
+            &delegate_pubkey,
+            &owner_pubkey,
+            &[], // Empty signer array for single owner
+            amount,
+            decimals,
+        )
+        .map_err(|e| {
+            Status::invalid_argument(format!("Failed to create ApproveChecked instruction: {e}"))
+        })?;
+
+        Ok(Response::new(ApproveResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates a `Revoke` instruction for SPL Token or Token 2022 program
+    async fn revoke(
+        &self,
+        request: Request<RevokeRequest>,
+    ) -> Result<Response<RevokeResponse>, Status> {
+        let req = request.into_inner();
+
+        let source_pubkey = parse_pub_key("source_account_pub_key", &req.source_account_pub_key)?;
+        let owner_pubkey = parse_pub_key("owner_pub_key", &req.owner_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        let instruction =
+            revoke(&token_program, &source_pubkey, &owner_pubkey, &[]).map_err(|e| {
+                Status::invalid_argument(format!("Failed to create Revoke instruction: {e}"))
+            })?;
+
+        Ok(Response::new(RevokeResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates a `CloseAccount` instruction for SPL Token or Token 2022 program
+    async fn close_account(
+        &self,
+        request: Request<CloseAccountRequest>,
+    ) -> Result<Response<CloseAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let account_pubkey = parse_pub_key("account_pub_key", &req.account_pub_key)?;
+        let destination_pubkey = parse_pub_key("destination_pub_key", &req.destination_pub_key)?;
+        let owner_pubkey = parse_pub_key("owner_pub_key", &req.owner_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        if account_pubkey == destination_pubkey {
+            return Err(Status::invalid_argument(
+                "destination_pub_key must differ from account_pub_key",
+            ));
+        }
+
+        let instruction =
+            close_account(&token_program, &account_pubkey, &destination_pubkey, &owner_pubkey, &[])
+                .map_err(|e| {
+                    Status::invalid_argument(format!(
+                        "Failed to create CloseAccount instruction: {e}"
+                    ))
+                })?;
+
+        Ok(Response::new(CloseAccountResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates a `FreezeAccount` instruction for SPL Token or Token 2022 program
+    async fn freeze_account(
+        &self,
+        request: Request<FreezeAccountRequest>,
+    ) -> Result<Response<FreezeAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let account_pubkey = parse_pub_key("account_pub_key", &req.account_pub_key)?;
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+        let freeze_authority =
+            parse_pub_key("freeze_authority_pub_key", &req.freeze_authority_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        let instruction =
+            freeze_account(&token_program, &account_pubkey, &mint_pubkey, &freeze_authority, &[])
+                .map_err(|e| {
+                Status::invalid_argument(format!("Failed to create FreezeAccount instruction: {e}"))
+            })?;
+
+        Ok(Response::new(FreezeAccountResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates a `ThawAccount` instruction for SPL Token or Token 2022 program
+    async fn thaw_account(
+        &self,
+        request: Request<ThawAccountRequest>,
+    ) -> Result<Response<ThawAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        let account_pubkey = parse_pub_key("account_pub_key", &req.account_pub_key)?;
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+        let freeze_authority =
+            parse_pub_key("freeze_authority_pub_key", &req.freeze_authority_pub_key)?;
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+
+        let instruction =
+            thaw_account(&token_program, &account_pubkey, &mint_pubkey, &freeze_authority, &[])
+                .map_err(|e| {
+                    Status::invalid_argument(format!(
+                        "Failed to create ThawAccount instruction: {e}"
+                    ))
+                })?;
+
+        Ok(Response::new(ThawAccountResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Creates a `SetAuthority` instruction for SPL Token or Token 2022 program
+    async fn set_authority(
+        &self,
+        request: Request<SetAuthorityRequest>,
+    ) -> Result<Response<SetAuthorityResponse>, Status> {
+        let req = request.into_inner();
+
+        let owned_pubkey = parse_pub_key("owned_pub_key", &req.owned_pub_key)?;
+        let current_authority =
+            parse_pub_key("current_authority_pub_key", &req.current_authority_pub_key)?;
+        // An empty new authority removes the authority for good
+        let new_authority = if req.new_authority_pub_key.is_empty() {
+            None
+        } else {
+            Some(parse_pub_key("new_authority_pub_key", &req.new_authority_pub_key)?)
+        };
+        let token_program =
+            resolve_token_program(&req.token_program_id).map_err(Status::invalid_argument)?;
+        let requested_type = TokenAuthorityType::try_from(req.authority_type)
+            .map_err(|_| Status::invalid_argument("Invalid authority_type"))?;
+        let authority_type =
+            authority_type(requested_type, &token_program).map_err(Status::invalid_argument)?;
+
+        let instruction = set_authority(
+            &token_program,
+            &owned_pubkey,
+            new_authority.as_ref(),
+            authority_type,
+            &current_authority,
+            &[],
+        )
+        .map_err(|e| {
+            Status::invalid_argument(format!("Failed to create SetAuthority instruction: {e}"))
+        })?;
+
+        Ok(Response::new(SetAuthorityResponse {
+            instruction: Some(sdk_instruction_to_proto(instruction)),
+        }))
+    }
+
+    /// Reads a mint's total supply with its UI amount
+    async fn get_mint_supply(
+        &self,
+        request: Request<GetMintSupplyRequest>,
+    ) -> Result<Response<GetMintSupplyResponse>, Status> {
+        let req = request.into_inner();
+
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let supply = self
+            .rpc_client
+            .get_token_supply_with_commitment(&mint_pubkey, CommitmentConfig::confirmed())
+            .map_err(|e| read_error_status(&e, "Failed to get token supply"))?;
+
+        let response = mint_supply(&supply.value, supply.context.slot).map_err(Status::internal)?;
+        Ok(Response::new(response))
+    }
+
+    /// Lists a mint's largest holding accounts, reading each one for its owner
+    async fn list_largest_holders(
+        &self,
+        request: Request<ListLargestHoldersRequest>,
+    ) -> Result<Response<ListLargestHoldersResponse>, Status> {
+        let req = request.into_inner();
+
+        let mint_pubkey = parse_pub_key("mint_pub_key", &req.mint_pub_key)?;
+
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let balances = self
+            .rpc_client
+            .get_token_largest_accounts_with_commitment(&mint_pubkey, CommitmentConfig::confirmed())
+            .map_err(|e| read_error_status(&e, "Failed to get largest token accounts"))?;
+        let slot = balances.context.slot;
+
+        let addresses = balances
+            .value
+            .iter()
+            .map(|balance| {
+                Pubkey::from_str(&balance.address).map_err(|e| {
+                    Status::internal(format!("Invalid holding account address from node: {e}"))
+                })
+            })
+            .collect::<Result<Vec<_>, Status>>()?;
+
+        // Owners are read no older than the balances
+        let accounts = if addresses.is_empty() {
+            Vec::new()
+        } else {
+            get_multiple_accounts(
+                &self.rpc_client,
+                &addresses,
+                CommitmentConfig::confirmed(),
+                Some(slot),
+            )
+            .map_err(|e| read_error_status(&e, "Failed to get holding accounts"))?
+            .1
+        };
+
+        let holders = largest_holders(&balances.value, &accounts).map_err(Status::internal)?;
+        Ok(Response::new(ListLargestHoldersResponse { holders, slot }))
+    }
 }
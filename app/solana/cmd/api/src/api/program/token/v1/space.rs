@@ -0,0 +1,130 @@
+use protochain_api::protochain::solana::program::token::v1::HoldingAccountExtensionType;
+use solana_sdk::pubkey::Pubkey;
+use spl_token_2022::{extension::ExtensionType, state::Account};
+
+use crate::api::common::instruction_decoding::TOKEN_PROGRAM_ID;
+
+/// Resolves requested holding account extensions, adding `MemoTransfer` when a memo is
+/// required. Duplicates are dropped so each extension is sized once.
+pub fn holding_account_extensions(
+    requested: &[i32],
+    require_memo: bool,
+) -> Result<Vec<ExtensionType>, String> {
+    let mut extensions = Vec::with_capacity(requested.len() + 1);
+    for &value in requested {
+        let extension = match HoldingAccountExtensionType::try_from(value) {
+            Ok(HoldingAccountExtensionType::MemoTransfer) => ExtensionType::MemoTransfer,
+            Ok(HoldingAccountExtensionType::ImmutableOwner) => ExtensionType::ImmutableOwner,
+            Ok(HoldingAccountExtensionType::CpiGuard) => ExtensionType::CpiGuard,
+            Ok(HoldingAccountExtensionType::TransferFeeAmount) => ExtensionType::TransferFeeAmount,
+            Ok(HoldingAccountExtensionType::ConfidentialTransfer) => {
+                ExtensionType::ConfidentialTransferAccount
+            }
+            Ok(HoldingAccountExtensionType::NonTransferableAccount) => {
+                ExtensionType::NonTransferableAccount
+            }
+            Ok(HoldingAccountExtensionType::TransferHookAccount) => {
+                ExtensionType::TransferHookAccount
+            }
+            Ok(HoldingAccountExtensionType::Unspecified) | Err(_) => {
+                return Err(format!("Invalid holding account extension: {value}"))
+            }
+        };
+        if !extensions.contains(&extension) {
+            extensions.push(extension);
+        }
+    }
+    if require_memo && !extensions.contains(&ExtensionType::MemoTransfer) {
+        extensions.push(ExtensionType::MemoTransfer);
+    }
+    Ok(extensions)
+}
+
+/// Rejects extensions for legacy SPL Token holding accounts, which have none
+pub fn check_token_program(
+    extensions: &[ExtensionType],
+    token_program: &Pubkey,
+) -> Result<(), String> {
+    if *token_program == TOKEN_PROGRAM_ID && !extensions.is_empty() {
+        return Err(format!(
+            "Holding account extensions require Token-2022; SPL Token does not support {extensions:?}"
+        ));
+    }
+    Ok(())
+}
+
+/// Exact size of a holding account with `extensions`
+pub fn holding_account_len(extensions: &[ExtensionType]) -> Result<usize, String> {
+    ExtensionType::try_calculate_account_len::<Account>(extensions)
+        .map_err(|e| format!("Failed to calculate holding account length: {e}"))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::program_pack::Pack;
+
+    fn extensions(requested: &[HoldingAccountExtensionType]) -> Vec<i32> {
+        requested
+            .iter()
+            .map(|&extension| extension.into())
+            .collect()
+    }
+
+    #[test]
+    fn test_no_extensions_is_a_plain_account() {
+        assert!(holding_account_extensions(&[], false).unwrap().is_empty());
+        assert_eq!(holding_account_len(&[]).unwrap(), Account::LEN);
+    }
+
+    #[test]
+    fn test_memo_requirement_is_merged() {
+        let requested = extensions(&[HoldingAccountExtensionType::MemoTransfer]);
+        assert_eq!(
+            holding_account_extensions(&requested, true).unwrap(),
+            [ExtensionType::MemoTransfer]
+        );
+        assert_eq!(holding_account_extensions(&[], true).unwrap(), [ExtensionType::MemoTransfer]);
+    }
+
+    #[test]
+    fn test_each_extension_adds_space() {
+        let requested = extensions(&[
+            HoldingAccountExtensionType::ImmutableOwner,
+            HoldingAccountExtensionType::CpiGuard,
+            HoldingAccountExtensionType::CpiGuard,
+            HoldingAccountExtensionType::TransferFeeAmount,
+        ]);
+        let resolved = holding_account_extensions(&requested, false).unwrap();
+        assert_eq!(
+            resolved,
+            [
+                ExtensionType::ImmutableOwner,
+                ExtensionType::CpiGuard,
+                ExtensionType::TransferFeeAmount
+            ]
+        );
+
+        let with_memo = holding_account_extensions(&requested, true).unwrap();
+        assert!(holding_account_len(&with_memo).unwrap() > holding_account_len(&resolved).unwrap());
+        assert_eq!(
+            holding_account_len(&resolved).unwrap(),
+            ExtensionType::try_calculate_account_len::<Account>(&resolved).unwrap()
+        );
+    }
+
+    #[test]
+    fn test_extensions_require_token_2022() {
+        let memo = holding_account_extensions(&[], true).unwrap();
+        assert!(check_token_program(&memo, &spl_token_2022::ID).is_ok());
+        assert!(check_token_program(&memo, &TOKEN_PROGRAM_ID).is_err());
+        assert!(check_token_program(&[], &TOKEN_PROGRAM_ID).is_ok());
+    }
+
+    #[test]
+    fn test_unknown_extension_is_rejected() {
+        assert!(holding_account_extensions(&[0], false).is_err());
+        assert!(holding_account_extensions(&[99], false).is_err());
+    }
+}
@@ -0,0 +1,131 @@
+use protochain_api::protochain::solana::program::token::v1::{
+    GetMintSupplyResponse, LargestHolder,
+};
+use solana_account_decoder::parse_token::UiTokenAmount;
+use solana_rpc_client_api::response::RpcTokenAccountBalance;
+use solana_sdk::account::Account;
+use spl_token_2022::{extension::StateWithExtensions, state::Account as HoldingAccount};
+
+use crate::api::transaction::v1::description::format_amount;
+
+/// Reads the base units of an RPC token amount, returning them with their UI amount.
+/// UI amounts are formatted from the base units rather than taken from the node, so they
+/// match the rest of the API.
+fn amounts(amount: &UiTokenAmount) -> Result<(u64, String), String> {
+    let base = amount
+        .amount
+        .parse::<u64>()
+        .map_err(|e| format!("Invalid token amount {:?}: {e}", amount.amount))?;
+    Ok((base, format_amount(base, u32::from(amount.decimals))))
+}
+
+/// Converts a `getTokenSupply` result read at `slot`
+pub fn mint_supply(supply: &UiTokenAmount, slot: u64) -> Result<GetMintSupplyResponse, String> {
+    let (base, ui_supply) = amounts(supply)?;
+    Ok(GetMintSupplyResponse {
+        supply: base.to_string(),
+        decimals: u32::from(supply.decimals),
+        ui_supply,
+        slot,
+    })
+}
+
+/// Pairs `getTokenLargestAccounts` balances with the listed holding accounts, read in the
+/// same order, to report each one's owner. Accounts closed since they were listed keep an
+/// empty owner.
+pub fn largest_holders(
+    balances: &[RpcTokenAccountBalance],
+    accounts: &[Option<Account>],
+) -> Result<Vec<LargestHolder>, String> {
+    balances
+        .iter()
+        .zip(accounts)
+        .map(|(balance, account)| {
+            let (base, ui_amount) = amounts(&balance.amount)?;
+            let owner = account
+                .as_ref()
+                .and_then(|account| {
+                    StateWithExtensions::<HoldingAccount>::unpack(&account.data).ok()
+                })
+                .map(|holding| holding.base.owner.to_string())
+                .unwrap_or_default();
+            Ok(LargestHolder {
+                holding_account_pub_key: balance.address.clone(),
+                owner_pub_key: owner,
+                amount: base.to_string(),
+                decimals: u32::from(balance.amount.decimals),
+                ui_amount,
+            })
+        })
+        .collect()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::program_pack::Pack;
+    use solana_sdk::pubkey::Pubkey;
+    use spl_token_2022::state::AccountState;
+
+    fn ui_amount(amount: &str, decimals: u8) -> UiTokenAmount {
+        UiTokenAmount {
+            ui_amount: None,
+            decimals,
+            amount: amount.to_string(),
+            ui_amount_string: String::new(),
+        }
+    }
+
+    #[test]
+    fn test_mint_supply() {
+        let supply = mint_supply(&ui_amount("1500000", 6), 42).unwrap();
+        assert_eq!(supply.supply, "1500000");
+        assert_eq!(supply.decimals, 6);
+        assert_eq!(supply.ui_supply, "1.5");
+        assert_eq!(supply.slot, 42);
+
+        assert!(mint_supply(&ui_amount("-1", 6), 42).is_err());
+    }
+
+    #[test]
+    fn test_largest_holders_report_owners() {
+        let owner = Pubkey::new_unique();
+        let mut data = vec![0; HoldingAccount::LEN];
+        HoldingAccount {
+            mint: Pubkey::new_unique(),
+            owner,
+            amount: 2_500,
+            state: AccountState::Initialized,
+            ..Default::default()
+        }
+        .pack_into_slice(&mut data);
+        let account = Account {
+            lamports: 2_039_280,
+            data,
+            owner: spl_token_2022::ID,
+            executable: false,
+            rent_epoch: 0,
+        };
+
+        let balances = [
+            RpcTokenAccountBalance {
+                address: Pubkey::new_unique().to_string(),
+                amount: ui_amount("2500", 2),
+            },
+            RpcTokenAccountBalance {
+                address: Pubkey::new_unique().to_string(),
+                amount: ui_amount("100", 2),
+            },
+        ];
+        let holders = largest_holders(&balances, &[Some(account), None]).unwrap();
+
+        assert_eq!(holders.len(), 2);
+        assert_eq!(holders[0].holding_account_pub_key, balances[0].address);
+        assert_eq!(holders[0].owner_pub_key, owner.to_string());
+        assert_eq!(holders[0].ui_amount, "25");
+        assert_eq!(holders[1].owner_pub_key, "");
+        assert_eq!(holders[1].amount, "100");
+        assert_eq!(holders[1].ui_amount, "1");
+    }
+}
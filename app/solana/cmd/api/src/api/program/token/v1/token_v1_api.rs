@@ -13,9 +13,11 @@ impl TokenV1API {
     /// Creates a new Token V1 API instance
     pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
         Self {
-            token_program_service: Arc::new(TokenProgramServiceImpl::new(Arc::clone(
-                &service_providers.solana_clients.rpc_client,
-            ))),
+            token_program_service: Arc::new(TokenProgramServiceImpl::new(
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.token_metadata),
+                Arc::clone(&service_providers.rpc_limiter),
+            )),
         }
     }
 }
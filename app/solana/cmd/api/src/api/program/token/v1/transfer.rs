@@ -0,0 +1,150 @@
+use solana_sdk::clock::Epoch;
+use spl_token_2022::{
+    extension::{transfer_fee::TransferFeeConfig, BaseStateWithExtensions, StateWithExtensions},
+    state::Mint,
+};
+
+/// What a checked transfer moves once the mint's transfer fee is applied
+#[derive(Debug, PartialEq, Eq)]
+pub struct TransferAmounts {
+    /// Decimals of the mint
+    pub decimals: u8,
+    /// Amount debited from the source account
+    pub amount: u64,
+    /// Fee withheld in the destination account, `None` for mints without a transfer fee
+    pub fee: Option<u64>,
+}
+
+impl TransferAmounts {
+    /// Amount the destination account's owner receives
+    pub fn received(&self) -> u64 {
+        self.amount.saturating_sub(self.fee.unwrap_or(0))
+    }
+}
+
+/// Works out a checked transfer of `amount` from a mint's account data in `epoch`.
+///
+/// By default `amount` leaves the source and the fee comes out of it; with
+/// `recipient_receives_amount` the amount is grossed up so the recipient receives exactly
+/// `amount`. Legacy SPL Token mints have no fees.
+pub fn transfer_amounts(
+    mint_data: &[u8],
+    epoch: Epoch,
+    amount: u64,
+    recipient_receives_amount: bool,
+) -> Result<TransferAmounts, String> {
+    let mint = StateWithExtensions::<Mint>::unpack(mint_data)
+        .map_err(|e| format!("Failed to parse mint account: {e}"))?;
+    let decimals = mint.base.decimals;
+    let Ok(fee_config) = mint.get_extension::<TransferFeeConfig>() else {
+        return Ok(TransferAmounts {
+            decimals,
+            amount,
+            fee: None,
+        });
+    };
+
+    let fee = if recipient_receives_amount {
+        fee_config
+            .calculate_inverse_epoch_fee(epoch, amount)
+            .ok_or_else(|| "Transfer fee overflows".to_string())?
+    } else {
+        fee_config
+            .calculate_epoch_fee(epoch, amount)
+            .ok_or_else(|| "Transfer fee overflows".to_string())?
+    };
+    let amount = if recipient_receives_amount {
+        amount
+            .checked_add(fee)
+            .ok_or_else(|| "Amount plus transfer fee overflows".to_string())?
+    } else {
+        amount
+    };
+
+    Ok(TransferAmounts {
+        decimals,
+        amount,
+        fee: Some(fee),
+    })
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::program_pack::Pack;
+    use spl_token_2022::extension::{
+        transfer_fee::TransferFee, BaseStateWithExtensionsMut, ExtensionType,
+        StateWithExtensionsMut,
+    };
+
+    fn mint(decimals: u8) -> Mint {
+        Mint {
+            decimals,
+            is_initialized: true,
+            ..Default::default()
+        }
+    }
+
+    fn fee_mint_data(basis_points: u16, maximum_fee: u64) -> Vec<u8> {
+        let space =
+            ExtensionType::try_calculate_account_len::<Mint>(&[ExtensionType::TransferFeeConfig])
+                .unwrap();
+        let mut data = vec![0; space];
+        let mut state = StateWithExtensionsMut::<Mint>::unpack_uninitialized(&mut data).unwrap();
+        let fee = TransferFee {
+            epoch: 0.into(),
+            maximum_fee: maximum_fee.into(),
+            transfer_fee_basis_points: basis_points.into(),
+        };
+        let config = state.init_extension::<TransferFeeConfig>(true).unwrap();
+        config.older_transfer_fee = fee;
+        config.newer_transfer_fee = fee;
+        state.base = mint(6);
+        state.pack_base();
+        state.init_account_type().unwrap();
+        data
+    }
+
+    #[test]
+    fn test_mint_without_fee() {
+        let mut data = vec![0; Mint::LEN];
+        mint(9).pack_into_slice(&mut data);
+
+        let amounts = transfer_amounts(&data, 100, 1_000, false).unwrap();
+        assert_eq!(
+            amounts,
+            TransferAmounts {
+                decimals: 9,
+                amount: 1_000,
+                fee: None
+            }
+        );
+        assert_eq!(amounts.received(), 1_000);
+    }
+
+    #[test]
+    fn test_fee_comes_out_of_amount() {
+        // 1% capped at 500
+        let data = fee_mint_data(100, 500);
+
+        let amounts = transfer_amounts(&data, 10, 10_000, false).unwrap();
+        assert_eq!(amounts.decimals, 6);
+        assert_eq!(amounts.amount, 10_000);
+        assert_eq!(amounts.fee, Some(100));
+        assert_eq!(amounts.received(), 9_900);
+
+        let capped = transfer_amounts(&data, 10, 1_000_000, false).unwrap();
+        assert_eq!(capped.fee, Some(500));
+    }
+
+    #[test]
+    fn test_recipient_receives_amount() {
+        let data = fee_mint_data(100, 500);
+
+        let amounts = transfer_amounts(&data, 10, 9_900, true).unwrap();
+        assert_eq!(amounts.amount, 10_000);
+        assert_eq!(amounts.fee, Some(100));
+        assert_eq!(amounts.received(), 9_900);
+    }
+}
@@ -0,0 +1,4 @@
+/// Vote Program v1 services
+pub mod v1;
+
+pub use v1::vote_v1_api::VoteV1API;
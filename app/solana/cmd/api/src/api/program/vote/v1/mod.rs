@@ -0,0 +1,6 @@
+/// Vote program service implementation
+pub mod service_impl;
+/// Vote account decoding
+pub mod state;
+/// Vote program API wrapper
+pub mod vote_v1_api;
@@ -0,0 +1,133 @@
+use solana_client::rpc_client::RpcClient;
+use solana_rpc_client_api::config::RpcGetVoteAccountsConfig;
+use solana_sdk::{commitment_config::CommitmentConfig, pubkey::Pubkey, vote};
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+
+use protochain_api::protochain::solana::program::vote::v1::{
+    service_server::Service as VoteProgramService, ListVoteAccountsRequest,
+    ListVoteAccountsResponse, ParseVoteAccountRequest, ParseVoteAccountResponse,
+};
+
+use super::state::{validator_vote_account, vote_account_info};
+use crate::api::common::min_context_slot::{get_account, min_context_slot, read_error_status};
+use crate::api::transaction::v1::service_impl::commitment_level_to_config;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+
+/// Read-only Vote Program service implementation
+#[derive(Clone)]
+pub struct VoteProgramServiceImpl {
+    /// Solana RPC client for reading vote accounts
+    rpc_client: Arc<RpcClient>,
+    /// Concurrency limits on calls to the RPC node
+    rpc_limiter: Arc<RpcLimiter>,
+}
+
+impl VoteProgramServiceImpl {
+    /// Creates a new instance of the Vote Program service with the provided RPC client
+    /// and RPC concurrency limiter.
+    pub const fn new(rpc_client: Arc<RpcClient>, rpc_limiter: Arc<RpcLimiter>) -> Self {
+        Self {
+            rpc_client,
+            rpc_limiter,
+        }
+    }
+
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
+    }
+}
+
+#[tonic::async_trait]
+impl VoteProgramService for VoteProgramServiceImpl {
+    /// Reads and decodes a vote account.
+    async fn parse_vote_account(
+        &self,
+        request: Request<ParseVoteAccountRequest>,
+    ) -> Result<Response<ParseVoteAccountResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.account_address.is_empty() {
+            return Err(Status::invalid_argument("Account address is required"));
+        }
+        let address = Pubkey::from_str(&req.account_address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid account address: {e}")))?;
+
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let account = get_account(
+            &self.rpc_client,
+            &address,
+            CommitmentConfig::confirmed(),
+            min_context_slot(req.min_context_slot),
+        )
+        .map_err(|e| read_error_status(&e, "Failed to get account"))?
+        .ok_or_else(|| Status::not_found("Account not found"))?;
+        if account.owner != vote::program::id() {
+            return Err(Status::invalid_argument("Account is not owned by the vote program"));
+        }
+
+        let vote_account = vote_account_info(&address, account.lamports, &account.data)
+            .map_err(Status::invalid_argument)?;
+        Ok(Response::new(ParseVoteAccountResponse {
+            vote_account: Some(vote_account),
+        }))
+    }
+
+    /// Lists the cluster's vote accounts, current validators by descending stake first.
+    async fn list_vote_accounts(
+        &self,
+        request: Request<ListVoteAccountsRequest>,
+    ) -> Result<Response<ListVoteAccountsResponse>, Status> {
+        let req = request.into_inner();
+
+        let vote_pubkey = if req.vote_account.is_empty() {
+            None
+        } else {
+            Pubkey::from_str(&req.vote_account).map_err(|e| {
+                Status::invalid_argument(format!("Invalid vote account address: {e}"))
+            })?;
+            Some(req.vote_account.clone())
+        };
+
+        let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+        let status = self
+            .rpc_client
+            .get_vote_accounts_with_config(RpcGetVoteAccountsConfig {
+                vote_pubkey,
+                commitment: Some(commitment_level_to_config(req.commitment_level)),
+                keep_unstaked_delinquents: Some(req.keep_unstaked_delinquents),
+                delinquent_slot_distance: (req.delinquent_slot_distance != 0)
+                    .then_some(req.delinquent_slot_distance),
+            })
+            .map_err(|e| Status::internal(format!("Failed to get vote accounts: {e}")))?;
+
+        let mut current = status.current;
+        current.sort_by(|a, b| b.activated_stake.cmp(&a.activated_stake));
+        let mut delinquent = status.delinquent;
+        delinquent.sort_by(|a, b| b.activated_stake.cmp(&a.activated_stake));
+
+        let delinquent_activated_stake = delinquent.iter().map(|info| info.activated_stake).sum();
+        let total_activated_stake = current.iter().map(|info| info.activated_stake).sum::<u64>()
+            + delinquent_activated_stake;
+
+        Ok(Response::new(ListVoteAccountsResponse {
+            vote_accounts: current
+                .iter()
+                .map(|info| validator_vote_account(info, false))
+                .chain(
+                    delinquent
+                        .iter()
+                        .map(|info| validator_vote_account(info, true)),
+                )
+                .collect(),
+            total_activated_stake,
+            delinquent_activated_stake,
+        }))
+    }
+}
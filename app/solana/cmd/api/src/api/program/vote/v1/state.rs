@@ -0,0 +1,127 @@
+use protochain_api::protochain::solana::program::vote::v1::{
+    EpochCredits, ValidatorVoteAccount, VoteAccountInfo,
+};
+use solana_rpc_client_api::response::RpcVoteAccountInfo;
+use solana_sdk::{clock::Epoch, pubkey::Pubkey, vote::state::VoteState};
+
+/// Converts `(epoch, credits, previous credits)` entries
+pub fn epoch_credits_to_proto(epoch_credits: &[(Epoch, u64, u64)]) -> Vec<EpochCredits> {
+    epoch_credits
+        .iter()
+        .map(|&(epoch, credits, previous_credits)| EpochCredits {
+            epoch,
+            credits,
+            previous_credits,
+        })
+        .collect()
+}
+
+/// Decodes a vote account holding `lamports`
+pub fn vote_account_info(
+    address: &Pubkey,
+    lamports: u64,
+    data: &[u8],
+) -> Result<VoteAccountInfo, String> {
+    let vote_state =
+        VoteState::deserialize(data).map_err(|e| format!("Invalid vote account {address}: {e}"))?;
+    let (authorized_voter_epoch, authorized_voter) = vote_state
+        .authorized_voters()
+        .last()
+        .map(|(epoch, voter)| (*epoch, voter.to_string()))
+        .unwrap_or_default();
+
+    Ok(VoteAccountInfo {
+        address: address.to_string(),
+        lamports,
+        node: vote_state.node_pubkey.to_string(),
+        authorized_voter,
+        authorized_voter_epoch,
+        authorized_withdrawer: vote_state.authorized_withdrawer.to_string(),
+        commission: u32::from(vote_state.commission),
+        credits: vote_state.credits(),
+        last_vote_slot: vote_state.last_voted_slot(),
+        root_slot: vote_state.root_slot,
+        epoch_credits: epoch_credits_to_proto(&vote_state.epoch_credits),
+        last_timestamp_slot: vote_state.last_timestamp.slot,
+        last_timestamp: vote_state.last_timestamp.timestamp,
+    })
+}
+
+/// Converts a `getVoteAccounts` entry
+pub fn validator_vote_account(info: &RpcVoteAccountInfo, delinquent: bool) -> ValidatorVoteAccount {
+    ValidatorVoteAccount {
+        vote_account: info.vote_pubkey.clone(),
+        node: info.node_pubkey.clone(),
+        activated_stake: info.activated_stake,
+        commission: u32::from(info.commission),
+        epoch_vote_account: info.epoch_vote_account,
+        last_vote: info.last_vote,
+        root_slot: info.root_slot,
+        delinquent,
+        epoch_credits: epoch_credits_to_proto(&info.epoch_credits),
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::vote::state::{VoteInit, VoteStateVersions};
+
+    #[test]
+    fn test_vote_account_info() {
+        let address = Pubkey::new_unique();
+        let node = Pubkey::new_unique();
+        let voter = Pubkey::new_unique();
+        let withdrawer = Pubkey::new_unique();
+        let vote_state = VoteState::new(
+            &VoteInit {
+                node_pubkey: node,
+                authorized_voter: voter,
+                authorized_withdrawer: withdrawer,
+                commission: 7,
+            },
+            &solana_sdk::clock::Clock {
+                epoch: 3,
+                ..Default::default()
+            },
+        );
+        let mut data = vec![0; VoteState::size_of()];
+        VoteState::serialize(&VoteStateVersions::new_current(vote_state), &mut data).unwrap();
+
+        let info = vote_account_info(&address, 42, &data).unwrap();
+
+        assert_eq!(info.node, node.to_string());
+        assert_eq!(info.authorized_voter, voter.to_string());
+        assert_eq!(info.authorized_voter_epoch, 3);
+        assert_eq!(info.authorized_withdrawer, withdrawer.to_string());
+        assert_eq!(info.commission, 7);
+        assert_eq!(info.credits, 0);
+        assert_eq!(info.last_vote_slot, None);
+        assert_eq!(info.root_slot, None);
+        assert!(info.epoch_credits.is_empty());
+    }
+
+    #[test]
+    fn test_garbage_data_is_rejected() {
+        assert!(vote_account_info(&Pubkey::new_unique(), 0, &[1, 2]).is_err());
+    }
+
+    #[test]
+    fn test_validator_vote_account() {
+        let info = RpcVoteAccountInfo {
+            vote_pubkey: "vote".to_string(),
+            node_pubkey: "node".to_string(),
+            activated_stake: 500,
+            commission: 10,
+            epoch_vote_account: true,
+            epoch_credits: vec![(4, 20, 12)],
+            last_vote: 100,
+            root_slot: 68,
+        };
+        let account = validator_vote_account(&info, true);
+        assert!(account.delinquent);
+        assert_eq!(account.commission, 10);
+        assert_eq!(account.epoch_credits[0].previous_credits, 12);
+    }
+}
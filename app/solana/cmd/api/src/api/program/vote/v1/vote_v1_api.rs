@@ -0,0 +1,22 @@
+use std::sync::Arc;
+
+use super::service_impl::VoteProgramServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// Vote Program API v1 wrapper
+pub struct VoteV1API {
+    /// The Vote Program service implementation
+    pub vote_program_service: Arc<VoteProgramServiceImpl>,
+}
+
+impl VoteV1API {
+    /// Creates a new Vote V1 API instance
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            vote_program_service: Arc::new(VoteProgramServiceImpl::new(
+                Arc::clone(&service_providers.solana_clients.rpc_client),
+                Arc::clone(&service_providers.rpc_limiter),
+            )),
+        }
+    }
+}
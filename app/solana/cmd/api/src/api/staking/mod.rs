@@ -0,0 +1,7 @@
+//! Staking services
+//!
+//! This module provides stake account lifecycles run as long-running operations:
+//! - Creating, funding and delegating a stake account
+//! - Deactivating a stake account and withdrawing it after cooldown
+
+pub mod v1;
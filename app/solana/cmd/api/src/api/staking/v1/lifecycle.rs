@@ -0,0 +1,444 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    clock::Epoch,
+    commitment_config::CommitmentConfig,
+    instruction::Instruction,
+    pubkey::Pubkey,
+    signature::{Keypair, Signature, Signer},
+    stake::{
+        self,
+        state::{Authorized, Lockup, StakeStateV2},
+    },
+    transaction::Transaction as SolanaTransaction,
+};
+use std::collections::HashMap;
+use std::sync::Arc;
+use std::time::Duration;
+use tracing::{info, warn};
+
+use crate::api::common::transaction_monitoring::wait_for_transaction_success;
+use crate::service_providers::operations::OperationStore;
+
+/// operations_v1 kind of the stake-to-validator orchestration
+pub const STAKE_TO_VALIDATOR_OPERATION_KIND: &str = "staking.stake_to_validator";
+/// operations_v1 kind of the unstake-and-withdraw orchestration
+pub const UNSTAKE_AND_WITHDRAW_OPERATION_KIND: &str = "staking.unstake_and_withdraw";
+/// Delay between checks of a stake account waiting for an epoch boundary. Epochs last
+/// about two days on mainnet-beta, so there is no point checking more often.
+const EPOCH_POLL_INTERVAL: Duration = Duration::from_secs(30);
+/// How long each transaction of a sequence may take to confirm
+const CONFIRMATION_TIMEOUT_SECONDS: u64 = 90;
+
+/// Where a stake account's delegation stands in an epoch
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum DelegationPhase {
+    /// Not delegated (uninitialized, initialized or a rewards pool)
+    Undelegated,
+    /// Delegated in the current epoch; active from the next one
+    Activating,
+    /// Delegated and earning rewards
+    Active,
+    /// Deactivated in the current epoch; withdrawable from the next one
+    Deactivating,
+    /// Fully cooled down; the whole balance can be withdrawn
+    Inactive,
+}
+
+/// Phase of `state` in `epoch`.
+///
+/// Stake activates and cools down at the first epoch boundary after the delegation or
+/// deactivation. The network-wide warmup and cooldown rate limits can stretch this over
+/// more epochs when a large share of all stake moves at once; a withdrawal attempted too
+/// early then fails and the orchestration reports it.
+pub fn delegation_phase(state: &StakeStateV2, epoch: Epoch) -> DelegationPhase {
+    let StakeStateV2::Stake(_, stake, _) = state else {
+        return DelegationPhase::Undelegated;
+    };
+    let delegation = &stake.delegation;
+    if delegation.deactivation_epoch != Epoch::MAX {
+        if epoch > delegation.deactivation_epoch {
+            DelegationPhase::Inactive
+        } else {
+            DelegationPhase::Deactivating
+        }
+    } else if delegation.activation_epoch == Epoch::MAX || epoch > delegation.activation_epoch {
+        // Bootstrap stake is active from genesis, with an activation epoch of Epoch::MAX
+        DelegationPhase::Active
+    } else {
+        DelegationPhase::Activating
+    }
+}
+
+/// A validated `StakeToValidator` request
+pub struct StakePlan {
+    /// Funds the stake, pays fees and becomes stake and withdraw authority
+    pub staker: Arc<Keypair>,
+    /// Vote account to delegate to
+    pub vote_account: Pubkey,
+    /// Stake account derived from the staker with `seed`
+    pub stake_account: Pubkey,
+    /// Seed of the stake account
+    pub seed: String,
+    /// Lamports to fund the stake account with (rent-exempt reserve included)
+    pub lamports: u64,
+    /// Keep running until the delegation is active
+    pub wait_for_activation: bool,
+    /// Commitment each transaction is confirmed to
+    pub commitment: CommitmentConfig,
+}
+
+impl StakePlan {
+    /// Instructions creating, funding and delegating the stake account in one transaction
+    pub fn instructions(&self) -> Vec<Instruction> {
+        let staker = self.staker.pubkey();
+        stake::instruction::create_account_with_seed_and_delegate_stake(
+            &staker,
+            &self.stake_account,
+            &staker,
+            &self.seed,
+            &self.vote_account,
+            &Authorized::auto(&staker),
+            &Lockup::default(),
+            self.lamports,
+        )
+    }
+}
+
+/// A validated `UnstakeAndWithdraw` request
+pub struct UnstakePlan {
+    /// Stake and withdraw authority, also the fee payer
+    pub authority: Arc<Keypair>,
+    /// Stake account to deactivate and drain
+    pub stake_account: Pubkey,
+    /// Recipient of the withdrawn lamports
+    pub destination: Pubkey,
+    /// Commitment each transaction is confirmed to
+    pub commitment: CommitmentConfig,
+}
+
+/// How a step sequence ended, when it did not fail
+enum Outcome {
+    Completed(HashMap<String, String>),
+    Cancelled,
+}
+
+/// Runs the stake-to-validator sequence as operation `operation_id`: the create, fund and
+/// delegate transaction, its confirmation and, if requested, the wait for activation
+pub async fn stake_to_validator(
+    rpc_client: Arc<RpcClient>,
+    operations: Arc<OperationStore>,
+    operation_id: String,
+    plan: StakePlan,
+) {
+    let result = run_stake_to_validator(&rpc_client, &operations, &operation_id, &plan).await;
+    finish(&operations, &operation_id, result, "Delegated");
+    info!(
+        operation_id = %operation_id,
+        stake_account = %plan.stake_account,
+        vote_account = %plan.vote_account,
+        "🏁 Stake to validator stopped"
+    );
+}
+
+/// Runs the unstake-and-withdraw sequence as operation `operation_id`: deactivation, the
+/// wait for cooldown and the withdrawal of the whole balance
+pub async fn unstake_and_withdraw(
+    rpc_client: Arc<RpcClient>,
+    operations: Arc<OperationStore>,
+    operation_id: String,
+    plan: UnstakePlan,
+) {
+    let result = run_unstake_and_withdraw(&rpc_client, &operations, &operation_id, &plan).await;
+    finish(&operations, &operation_id, result, "Withdrawn");
+    info!(
+        operation_id = %operation_id,
+        stake_account = %plan.stake_account,
+        "🏁 Unstake and withdraw stopped"
+    );
+}
+
+async fn run_stake_to_validator(
+    rpc_client: &Arc<RpcClient>,
+    operations: &OperationStore,
+    operation_id: &str,
+    plan: &StakePlan,
+) -> Result<Outcome, String> {
+    if operations.is_cancel_requested(operation_id) {
+        return Ok(Outcome::Cancelled);
+    }
+    let signature =
+        send_and_confirm(rpc_client, &plan.staker, &plan.instructions(), plan.commitment).await?;
+    operations.record_signature(operation_id, &signature.to_string());
+    operations.progress(operation_id, 1, "Stake account created and delegated");
+
+    if plan.wait_for_activation
+        && !wait_for_phase(
+            rpc_client,
+            operations,
+            operation_id,
+            &plan.stake_account,
+            DelegationPhase::Active,
+            1,
+            "Waiting for the delegation to activate",
+        )
+        .await?
+    {
+        return Ok(Outcome::Cancelled);
+    }
+
+    Ok(Outcome::Completed(HashMap::from([
+        ("stake_account".to_string(), plan.stake_account.to_string()),
+        ("vote_account".to_string(), plan.vote_account.to_string()),
+        ("lamports".to_string(), plan.lamports.to_string()),
+    ])))
+}
+
+async fn run_unstake_and_withdraw(
+    rpc_client: &Arc<RpcClient>,
+    operations: &OperationStore,
+    operation_id: &str,
+    plan: &UnstakePlan,
+) -> Result<Outcome, String> {
+    let authority = plan.authority.pubkey();
+    let (state, _) = stake_state(rpc_client, &plan.stake_account)?;
+
+    // A retried orchestration picks up where a previous one stopped
+    if matches!(
+        delegation_phase(&state, current_epoch(rpc_client)?),
+        DelegationPhase::Activating | DelegationPhase::Active
+    ) {
+        if operations.is_cancel_requested(operation_id) {
+            return Ok(Outcome::Cancelled);
+        }
+        let signature = send_and_confirm(
+            rpc_client,
+            &plan.authority,
+            &[stake::instruction::deactivate_stake(
+                &plan.stake_account,
+                &authority,
+            )],
+            plan.commitment,
+        )
+        .await?;
+        operations.record_signature(operation_id, &signature.to_string());
+    }
+    operations.progress(operation_id, 1, "Stake deactivated");
+
+    if !wait_for_phase(
+        rpc_client,
+        operations,
+        operation_id,
+        &plan.stake_account,
+        DelegationPhase::Inactive,
+        1,
+        "Waiting for the stake to cool down",
+    )
+    .await?
+    {
+        return Ok(Outcome::Cancelled);
+    }
+    operations.progress(operation_id, 2, "Stake cooled down");
+
+    if operations.is_cancel_requested(operation_id) {
+        return Ok(Outcome::Cancelled);
+    }
+    let (_, lamports) = stake_state(rpc_client, &plan.stake_account)?;
+    let signature = send_and_confirm(
+        rpc_client,
+        &plan.authority,
+        &[stake::instruction::withdraw(
+            &plan.stake_account,
+            &authority,
+            &plan.destination,
+            lamports,
+            None,
+        )],
+        plan.commitment,
+    )
+    .await?;
+    operations.record_signature(operation_id, &signature.to_string());
+    operations.progress(operation_id, 3, "Balance withdrawn");
+
+    Ok(Outcome::Completed(HashMap::from([
+        ("stake_account".to_string(), plan.stake_account.to_string()),
+        ("destination".to_string(), plan.destination.to_string()),
+        ("lamports".to_string(), lamports.to_string()),
+    ])))
+}
+
+/// Records the end of a sequence on its operation
+fn finish(
+    operations: &OperationStore,
+    operation_id: &str,
+    result: Result<Outcome, String>,
+    message: &str,
+) {
+    match result {
+        Ok(Outcome::Completed(outputs)) => operations.complete(operation_id, message, outputs),
+        Ok(Outcome::Cancelled) => operations.cancelled(operation_id),
+        Err(e) => {
+            warn!(operation_id = %operation_id, error = %e, "Staking operation failed");
+            operations.fail(operation_id, &e);
+        }
+    }
+}
+
+/// Signs `instructions` with `signer` as the fee payer, submits them and waits until the
+/// transaction succeeds at `commitment`
+async fn send_and_confirm(
+    rpc_client: &Arc<RpcClient>,
+    signer: &Keypair,
+    instructions: &[Instruction],
+    commitment: CommitmentConfig,
+) -> Result<Signature, String> {
+    let recent_blockhash = rpc_client
+        .get_latest_blockhash()
+        .map_err(|e| format!("Failed to get latest blockhash: {e}"))?;
+    let transaction = SolanaTransaction::new_signed_with_payer(
+        instructions,
+        Some(&signer.pubkey()),
+        &[signer],
+        recent_blockhash,
+    );
+    let signature = rpc_client
+        .send_transaction(&transaction)
+        .map_err(|e| format!("Failed to send transaction: {e}"))?;
+    wait_for_transaction_success(
+        Arc::clone(rpc_client),
+        &signature,
+        commitment,
+        Some(CONFIRMATION_TIMEOUT_SECONDS),
+    )
+    .await
+    .map_err(|status| format!("Transaction {signature} failed: {}", status.message()))?;
+    Ok(signature)
+}
+
+/// Polls a stake account until its delegation reaches `phase`, reporting the wait as
+/// progress. Returns false if the operation was cancelled first. RPC errors are treated
+/// as transient, since the wait can span days.
+async fn wait_for_phase(
+    rpc_client: &RpcClient,
+    operations: &OperationStore,
+    operation_id: &str,
+    stake_account: &Pubkey,
+    phase: DelegationPhase,
+    completed_steps: u32,
+    message: &str,
+) -> Result<bool, String> {
+    loop {
+        if operations.is_cancel_requested(operation_id) {
+            return Ok(false);
+        }
+        match current_epoch(rpc_client).and_then(|epoch| {
+            stake_state(rpc_client, stake_account).map(|(state, _)| (state, epoch))
+        }) {
+            Ok((state, epoch)) => {
+                let current = delegation_phase(&state, epoch);
+                if current == phase {
+                    return Ok(true);
+                }
+                if current == DelegationPhase::Undelegated {
+                    return Err(format!("Stake account {stake_account} is not delegated"));
+                }
+                operations.progress(
+                    operation_id,
+                    completed_steps,
+                    &format!("{message} (epoch {epoch})"),
+                );
+            }
+            Err(e) => {
+                warn!(operation_id = %operation_id, error = %e, "Stake account check failed");
+            }
+        }
+        tokio::time::sleep(EPOCH_POLL_INTERVAL).await;
+    }
+}
+
+fn current_epoch(rpc_client: &RpcClient) -> Result<Epoch, String> {
+    rpc_client
+        .get_epoch_info()
+        .map(|info| info.epoch)
+        .map_err(|e| format!("Failed to get epoch info: {e}"))
+}
+
+/// Reads and decodes a stake account, returning its state and balance
+pub fn stake_state(
+    rpc_client: &RpcClient,
+    stake_account: &Pubkey,
+) -> Result<(StakeStateV2, u64), String> {
+    let account = rpc_client
+        .get_account(stake_account)
+        .map_err(|e| format!("Failed to read stake account {stake_account}: {e}"))?;
+    if account.owner != stake::program::id() {
+        return Err(format!("{stake_account} is not a stake account"));
+    }
+    let state = bincode::deserialize::<StakeStateV2>(&account.data)
+        .map_err(|e| format!("Invalid stake account {stake_account}: {e}"))?;
+    Ok((state, account.lamports))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::stake::stake_flags::StakeFlags;
+    use solana_sdk::stake::state::{Delegation, Meta, Stake};
+
+    fn delegated(activation_epoch: Epoch, deactivation_epoch: Epoch) -> StakeStateV2 {
+        StakeStateV2::Stake(
+            Meta::default(),
+            Stake {
+                delegation: Delegation {
+                    activation_epoch,
+                    deactivation_epoch,
+                    ..Delegation::default()
+                },
+                credits_observed: 0,
+            },
+            StakeFlags::empty(),
+        )
+    }
+
+    #[test]
+    fn test_delegation_phases() {
+        assert_eq!(
+            delegation_phase(&StakeStateV2::Uninitialized, 10),
+            DelegationPhase::Undelegated
+        );
+        assert_eq!(delegation_phase(&delegated(10, Epoch::MAX), 10), DelegationPhase::Activating);
+        assert_eq!(delegation_phase(&delegated(10, Epoch::MAX), 11), DelegationPhase::Active);
+        assert_eq!(
+            delegation_phase(&delegated(Epoch::MAX, Epoch::MAX), 0),
+            DelegationPhase::Active
+        );
+        assert_eq!(delegation_phase(&delegated(10, 20), 20), DelegationPhase::Deactivating);
+        assert_eq!(delegation_phase(&delegated(10, 20), 21), DelegationPhase::Inactive);
+    }
+
+    #[test]
+    fn test_stake_plan_delegates_derived_account() {
+        let staker = Arc::new(Keypair::new());
+        let vote_account = Pubkey::new_unique();
+        let seed = "stake:1".to_string();
+        let stake_account =
+            Pubkey::create_with_seed(&staker.pubkey(), &seed, &stake::program::id()).unwrap();
+        let plan = StakePlan {
+            staker: Arc::clone(&staker),
+            vote_account,
+            stake_account,
+            seed,
+            lamports: 5_000_000_000,
+            wait_for_activation: false,
+            commitment: CommitmentConfig::confirmed(),
+        };
+
+        let instructions = plan.instructions();
+        // create with seed, initialize, delegate
+        assert_eq!(instructions.len(), 3);
+        let delegate = instructions.last().unwrap();
+        assert_eq!(delegate.program_id, stake::program::id());
+        assert_eq!(delegate.accounts[0].pubkey, stake_account);
+        assert_eq!(delegate.accounts[1].pubkey, vote_account);
+    }
+}
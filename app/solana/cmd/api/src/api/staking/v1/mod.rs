@@ -0,0 +1,14 @@
+//! Staking service v1 API and implementation
+//!
+//! This module contains the gRPC service definition and business logic
+//! for the stake-to-validator and unstake-and-withdraw orchestrations.
+
+/// Transaction sequences and epoch waits of the staking orchestrations
+pub mod lifecycle;
+/// Core business logic implementation module for staking
+pub mod service_impl;
+/// gRPC service wrapper module for staking
+pub mod staking_v1_api;
+
+pub use service_impl::StakingServiceImpl;
+pub use staking_v1_api::StakingV1API;
@@ -0,0 +1,250 @@
+use std::str::FromStr;
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+use tracing::info;
+
+use protochain_api::protochain::solana::operations::v1::Operation;
+use protochain_api::protochain::solana::r#type::v1::CommitmentLevel;
+use protochain_api::protochain::solana::staking::v1::{
+    service_server::Service as StakingService, StakeToValidatorRequest, StakeToValidatorResponse,
+    UnstakeAndWithdrawRequest, UnstakeAndWithdrawResponse,
+};
+
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{
+    commitment_config::CommitmentConfig,
+    pubkey::{Pubkey, MAX_SEED_LEN},
+    signature::{Keypair, Signer},
+    stake::{self, state::StakeStateV2},
+};
+
+use crate::api::staking::v1::lifecycle::{
+    delegation_phase, stake_state, stake_to_validator, unstake_and_withdraw, DelegationPhase,
+    StakePlan, UnstakePlan, STAKE_TO_VALIDATOR_OPERATION_KIND, UNSTAKE_AND_WITHDRAW_OPERATION_KIND,
+};
+use crate::service_providers::key_vault::KeyVault;
+use crate::service_providers::operations::OperationStore;
+
+/// Steps reported by a stake-to-validator operation: submission and, if requested, the wait
+/// for activation
+const STAKE_STEPS: u32 = 1;
+/// Steps reported by an unstake-and-withdraw operation: deactivation, cooldown, withdrawal
+const UNSTAKE_STEPS: u32 = 3;
+
+#[derive(Clone)]
+/// Starts stake account lifecycles as operations signed with key vault keys
+pub struct StakingServiceImpl {
+    rpc_client: Arc<RpcClient>,
+    key_vault: Arc<KeyVault>,
+    operations: Arc<OperationStore>,
+}
+
+impl StakingServiceImpl {
+    /// Creates a new `StakingServiceImpl` with the RPC client transactions go through, the
+    /// key vault holding stakers and authorities and the operation store lifecycles report to
+    pub const fn new(
+        rpc_client: Arc<RpcClient>,
+        key_vault: Arc<KeyVault>,
+        operations: Arc<OperationStore>,
+    ) -> Self {
+        Self {
+            rpc_client,
+            key_vault,
+            operations,
+        }
+    }
+
+    /// Resolves a key vault reference naming the signer of a lifecycle
+    #[allow(clippy::result_large_err)]
+    fn signer(&self, field: &str, key_ref: &str) -> Result<Arc<Keypair>, Status> {
+        if key_ref.is_empty() {
+            return Err(Status::invalid_argument(format!("{field} is required")));
+        }
+        self.key_vault
+            .resolve(key_ref)
+            .ok_or_else(|| Status::not_found(format!("Key not found in key vault: {key_ref}")))
+    }
+
+    /// Starts an operation, returning its id and initial state
+    #[allow(clippy::result_large_err)]
+    fn start_operation(
+        &self,
+        kind: &str,
+        target: &Pubkey,
+        total_steps: u32,
+    ) -> Result<(String, Operation), Status> {
+        let operation_id = self
+            .operations
+            .start(kind, &target.to_string(), total_steps)
+            .map_err(Status::resource_exhausted)?;
+        let operation = self.operations.get(&operation_id).ok_or_else(|| {
+            Status::internal(format!("Operation {operation_id} was not recorded"))
+        })?;
+        Ok((operation_id, operation))
+    }
+}
+
+/// Parses a required public key field
+#[allow(clippy::result_large_err)]
+fn parse_pubkey(field: &str, value: &str) -> Result<Pubkey, Status> {
+    if value.is_empty() {
+        return Err(Status::invalid_argument(format!("{field} is required")));
+    }
+    Pubkey::from_str(value).map_err(|e| Status::invalid_argument(format!("Invalid {field}: {e}")))
+}
+
+/// Seed for a new stake account when the request names none
+fn generated_seed() -> String {
+    // 32 hex characters fill the seed limit exactly
+    uuid::Uuid::new_v4().simple().to_string()
+}
+
+/// Converts protobuf `CommitmentLevel` to Solana `CommitmentConfig`
+fn commitment_level_to_config(commitment_level: i32) -> CommitmentConfig {
+    match CommitmentLevel::try_from(commitment_level) {
+        Ok(CommitmentLevel::Processed) => CommitmentConfig::processed(),
+        Ok(CommitmentLevel::Finalized) => CommitmentConfig::finalized(),
+        Ok(CommitmentLevel::Confirmed | CommitmentLevel::Unspecified) | Err(_) => {
+            CommitmentConfig::confirmed()
+        }
+    }
+}
+
+#[tonic::async_trait]
+impl StakingService for StakingServiceImpl {
+    async fn stake_to_validator(
+        &self,
+        request: Request<StakeToValidatorRequest>,
+    ) -> Result<Response<StakeToValidatorResponse>, Status> {
+        let req = request.into_inner();
+        let staker = self.signer("staker_key_ref", &req.staker_key_ref)?;
+        let vote_account = parse_pubkey("vote_account", &req.vote_account)?;
+        if req.lamports == 0 {
+            return Err(Status::invalid_argument("lamports must be greater than zero"));
+        }
+        let seed = if req.seed.is_empty() {
+            generated_seed()
+        } else if req.seed.len() > MAX_SEED_LEN {
+            return Err(Status::invalid_argument(format!(
+                "seed must be at most {MAX_SEED_LEN} bytes"
+            )));
+        } else {
+            req.seed
+        };
+
+        let stake_account =
+            Pubkey::create_with_seed(&staker.pubkey(), &seed, &stake::program::id())
+                .map_err(|e| Status::invalid_argument(format!("Invalid seed: {e}")))?;
+        if self.rpc_client.get_account(&stake_account).is_ok() {
+            return Err(Status::already_exists(format!(
+                "Stake account {stake_account} already exists; choose another seed"
+            )));
+        }
+        let reserve = self
+            .rpc_client
+            .get_minimum_balance_for_rent_exemption(StakeStateV2::size_of())
+            .map_err(|e| Status::internal(format!("Failed to get rent exemption: {e}")))?;
+        let lamports = reserve
+            .checked_add(req.lamports)
+            .ok_or_else(|| Status::invalid_argument("lamports is too large"))?;
+
+        let total_steps = STAKE_STEPS + u32::from(req.wait_for_activation);
+        let (operation_id, operation) =
+            self.start_operation(STAKE_TO_VALIDATOR_OPERATION_KIND, &stake_account, total_steps)?;
+        info!(
+            operation_id = %operation_id,
+            stake_account = %stake_account,
+            vote_account = %vote_account,
+            lamports,
+            "🥩 Starting stake to validator"
+        );
+
+        let plan = StakePlan {
+            staker,
+            vote_account,
+            stake_account,
+            seed,
+            lamports,
+            wait_for_activation: req.wait_for_activation,
+            commitment: commitment_level_to_config(req.commitment_level),
+        };
+        tokio::spawn(stake_to_validator(
+            Arc::clone(&self.rpc_client),
+            Arc::clone(&self.operations),
+            operation_id,
+            plan,
+        ));
+
+        Ok(Response::new(StakeToValidatorResponse {
+            operation: Some(operation),
+            stake_account: stake_account.to_string(),
+        }))
+    }
+
+    async fn unstake_and_withdraw(
+        &self,
+        request: Request<UnstakeAndWithdrawRequest>,
+    ) -> Result<Response<UnstakeAndWithdrawResponse>, Status> {
+        let req = request.into_inner();
+        let stake_account = parse_pubkey("stake_account", &req.stake_account)?;
+        let authority = self.signer("authority_key_ref", &req.authority_key_ref)?;
+        let destination = if req.destination.is_empty() {
+            authority.pubkey()
+        } else {
+            parse_pubkey("destination", &req.destination)?
+        };
+
+        let (state, _) =
+            stake_state(&self.rpc_client, &stake_account).map_err(Status::failed_precondition)?;
+        let Some(authorized) = state.authorized() else {
+            return Err(Status::failed_precondition(format!(
+                "Stake account {stake_account} is not initialized"
+            )));
+        };
+        if authorized.staker != authority.pubkey() || authorized.withdrawer != authority.pubkey() {
+            return Err(Status::permission_denied(format!(
+                "{} is not the stake and withdraw authority of {stake_account}",
+                authority.pubkey()
+            )));
+        }
+        let epoch = self
+            .rpc_client
+            .get_epoch_info()
+            .map_err(|e| Status::internal(format!("Failed to get epoch info: {e}")))?
+            .epoch;
+        if delegation_phase(&state, epoch) == DelegationPhase::Undelegated {
+            return Err(Status::failed_precondition(format!(
+                "Stake account {stake_account} is not delegated"
+            )));
+        }
+
+        let (operation_id, operation) = self.start_operation(
+            UNSTAKE_AND_WITHDRAW_OPERATION_KIND,
+            &stake_account,
+            UNSTAKE_STEPS,
+        )?;
+        info!(
+            operation_id = %operation_id,
+            stake_account = %stake_account,
+            destination = %destination,
+            "🥩 Starting unstake and withdraw"
+        );
+
+        let plan = UnstakePlan {
+            authority,
+            stake_account,
+            destination,
+            commitment: commitment_level_to_config(req.commitment_level),
+        };
+        tokio::spawn(unstake_and_withdraw(
+            Arc::clone(&self.rpc_client),
+            Arc::clone(&self.operations),
+            operation_id,
+            plan,
+        ));
+
+        Ok(Response::new(UnstakeAndWithdrawResponse {
+            operation: Some(operation),
+        }))
+    }
+}
@@ -0,0 +1,23 @@
+use std::sync::Arc;
+
+use super::StakingServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// gRPC service wrapper for staking orchestrations
+pub struct StakingV1API {
+    /// Core staking service implementation
+    pub staking_service: Arc<StakingServiceImpl>,
+}
+
+impl StakingV1API {
+    /// Creates a new `StakingV1API` instance with the provided service providers
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            staking_service: Arc::new(StakingServiceImpl::new(
+                service_providers.solana_clients.get_rpc_client(),
+                Arc::clone(&service_providers.key_vault),
+                Arc::clone(&service_providers.operations),
+            )),
+        }
+    }
+}
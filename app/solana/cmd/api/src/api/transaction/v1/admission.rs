@@ -0,0 +1,66 @@
+use std::collections::HashMap;
+use std::time::Duration;
+use tonic::{metadata::MetadataValue, Code, Response, Status};
+use tonic_types::{ErrorDetails, StatusExt};
+
+use crate::api::common::amount_parsing::ERROR_DOMAIN;
+use crate::service_providers::admission::Overloaded;
+
+/// `ErrorInfo` reason of a request turned away by admission control
+pub const OVERLOADED_REASON: &str = "OVERLOADED";
+
+/// Response header reporting how long the request waited for admission, in milliseconds
+pub const QUEUE_TIME_HEADER: &str = "x-queue-time-ms";
+
+/// `RESOURCE_EXHAUSTED` status carrying `ErrorInfo` with the cause and `RetryInfo`
+/// suggesting a wait of `retry_after`, so clients can back off instead of failing
+pub fn overloaded_status(overloaded: Overloaded, retry_after: Duration) -> Status {
+    let mut details = ErrorDetails::with_error_info(
+        OVERLOADED_REASON,
+        ERROR_DOMAIN,
+        HashMap::from([("cause".to_string(), overloaded.name().to_string())]),
+    );
+    details.set_retry_info(Some(retry_after));
+
+    Status::with_error_details(
+        Code::ResourceExhausted,
+        format!(
+            "{OVERLOADED_REASON}: too many submissions in flight ({}), retry later",
+            overloaded.name()
+        ),
+        details,
+    )
+}
+
+/// Reports the time a request waited for admission in its response metadata
+pub fn record_queue_time<T>(response: &mut Response<T>, queued_for: Duration) {
+    let millis = u64::try_from(queued_for.as_millis()).unwrap_or(u64::MAX);
+    response
+        .metadata_mut()
+        .insert(QUEUE_TIME_HEADER, MetadataValue::from(millis));
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_overloaded_status_carries_cause() {
+        let status = overloaded_status(Overloaded::QueueTimeout, Duration::from_millis(250));
+        assert_eq!(status.code(), Code::ResourceExhausted);
+
+        let details = status.get_error_details();
+        let info = details.error_info().unwrap();
+        assert_eq!(info.reason, OVERLOADED_REASON);
+        assert_eq!(info.metadata.get("cause").unwrap(), "queue_timeout");
+        assert_eq!(details.retry_info().unwrap().retry_delay, Some(Duration::from_millis(250)));
+    }
+
+    #[test]
+    fn test_queue_time_is_reported_in_metadata() {
+        let mut response = Response::new(());
+        record_queue_time(&mut response, Duration::from_millis(42));
+        assert_eq!(response.metadata().get(QUEUE_TIME_HEADER).unwrap(), "42");
+    }
+}
@@ -0,0 +1,325 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use serde::{Deserialize, Serialize};
+use solana_sdk::{
+    message::Message, pubkey::Pubkey, signature::Signature,
+    transaction::Transaction as SolanaTransaction,
+};
+use std::str::FromStr;
+
+use crate::api::common::instruction_decoding::decode_compiled_instructions;
+use protochain_api::protochain::solana::transaction::v1::{
+    ProgramKind, SolanaInstruction, TransactionBundleFormat,
+};
+
+/// Envelope version written by this server and the only one accepted on import
+pub const BUNDLE_VERSION: u32 = 1;
+
+/// Portable envelope carrying an unsigned or partially signed transaction to an
+/// air-gapped signer and back.
+///
+/// `message` holds the exact bytes each signer signs. Everything else is there for the
+/// signing device to display and is re-derived from `message` on import, so an edited
+/// description cannot change what is submitted.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct TransactionBundle {
+    /// Envelope format version
+    pub version: u32,
+    /// Base64 serialized message
+    pub message: String,
+    /// Fee payer, the first signer
+    pub fee_payer: String,
+    /// Blockhash the message was compiled against
+    pub recent_blockhash: String,
+    /// Block height after which the blockhash, and so the transaction, expires
+    pub last_valid_block_height: u64,
+    /// What each instruction does, in order
+    pub instructions: Vec<BundleInstruction>,
+    /// One entry per required signer, in message order
+    pub signatures: Vec<BundleSignature>,
+}
+
+/// Human-readable summary of one instruction
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BundleInstruction {
+    /// Invoked program
+    pub program_id: String,
+    /// What the instruction does
+    pub description: String,
+    /// Accounts the instruction references, in order
+    pub accounts: Vec<String>,
+}
+
+/// A required signer and, once signed, its base58 signature
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct BundleSignature {
+    /// Signer public key
+    pub signer: String,
+    /// Base58 signature over `message`, empty until signed
+    #[serde(default)]
+    pub signature: String,
+}
+
+/// Names a well-known program for display
+const fn program_label(program: ProgramKind) -> &'static str {
+    match program {
+        ProgramKind::System => "System program",
+        ProgramKind::Token => "SPL Token program",
+        ProgramKind::Token2022 => "Token-2022 program",
+        ProgramKind::AssociatedToken => "Associated Token Account program",
+        ProgramKind::ComputeBudget => "Compute Budget program",
+        ProgramKind::Memo => "Memo program",
+        ProgramKind::Unspecified => "",
+    }
+}
+
+/// Describes each instruction of a message, preferring the descriptions given on the
+/// draft instructions when they line up with the message
+fn describe_instructions(
+    message: &Message,
+    draft_instructions: &[SolanaInstruction],
+) -> Vec<BundleInstruction> {
+    let drafts_match = draft_instructions.len() == message.instructions.len();
+    decode_compiled_instructions(&message.account_keys, &message.instructions)
+        .into_iter()
+        .enumerate()
+        .map(|(index, decoded)| {
+            let draft_description = draft_instructions
+                .get(index)
+                .filter(|_| drafts_match)
+                .map(|instruction| instruction.description.clone())
+                .filter(|description| !description.is_empty());
+            let description = draft_description.unwrap_or_else(|| {
+                let program = program_label(decoded.program());
+                match (decoded.instruction_type.is_empty(), program.is_empty()) {
+                    (false, false) => format!("{} ({program})", decoded.instruction_type),
+                    (false, true) => decoded.instruction_type.clone(),
+                    (true, false) => format!("Unrecognised {program} instruction"),
+                    (true, true) => format!("Unrecognised instruction for {}", decoded.program_id),
+                }
+            });
+            BundleInstruction {
+                program_id: decoded.program_id,
+                description,
+                accounts: decoded.accounts,
+            }
+        })
+        .collect()
+}
+
+/// Builds the bundle for a compiled or partially signed transaction
+pub fn export_bundle(
+    transaction: &SolanaTransaction,
+    draft_instructions: &[SolanaInstruction],
+    last_valid_block_height: u64,
+) -> TransactionBundle {
+    let message = &transaction.message;
+    let signatures = message
+        .account_keys
+        .iter()
+        .take(usize::from(message.header.num_required_signatures))
+        .enumerate()
+        .map(|(index, signer)| BundleSignature {
+            signer: signer.to_string(),
+            signature: transaction
+                .signatures
+                .get(index)
+                .filter(|signature| **signature != Signature::default())
+                .map(ToString::to_string)
+                .unwrap_or_default(),
+        })
+        .collect();
+
+    TransactionBundle {
+        version: BUNDLE_VERSION,
+        message: STANDARD.encode(message.serialize()),
+        fee_payer: message
+            .account_keys
+            .first()
+            .map(ToString::to_string)
+            .unwrap_or_default(),
+        recent_blockhash: message.recent_blockhash.to_string(),
+        last_valid_block_height,
+        instructions: describe_instructions(message, draft_instructions),
+        signatures,
+    }
+}
+
+/// Rebuilds the transaction a returned bundle carries.
+///
+/// Signatures are matched to the message's signers by public key and each one is
+/// verified against the message bytes, so a bundle signed over a different message, or
+/// with the wrong key, is rejected rather than failing on submission.
+pub fn import_bundle(bundle: &TransactionBundle) -> Result<SolanaTransaction, String> {
+    if bundle.version != BUNDLE_VERSION {
+        return Err(format!(
+            "Unsupported bundle version {} (expected {BUNDLE_VERSION})",
+            bundle.version
+        ));
+    }
+    let message_bytes = STANDARD
+        .decode(&bundle.message)
+        .map_err(|e| format!("Invalid bundle message encoding: {e}"))?;
+    let message: Message =
+        bincode::deserialize(&message_bytes).map_err(|e| format!("Invalid bundle message: {e}"))?;
+
+    let mut transaction = SolanaTransaction::new_unsigned(message);
+    let message_data = transaction.message_data();
+    for entry in bundle
+        .signatures
+        .iter()
+        .filter(|entry| !entry.signature.is_empty())
+    {
+        let signer = Pubkey::from_str(&entry.signer)
+            .map_err(|e| format!("Invalid signer {}: {e}", entry.signer))?;
+        let index = transaction
+            .message
+            .account_keys
+            .iter()
+            .take(transaction.signatures.len())
+            .position(|key| *key == signer)
+            .ok_or_else(|| format!("{signer} is not a signer of the bundled message"))?;
+        let signature = Signature::from_str(&entry.signature)
+            .map_err(|e| format!("Invalid signature for {signer}: {e}"))?;
+        if !signature.verify(signer.as_ref(), &message_data) {
+            return Err(format!("Signature for {signer} does not match the bundled message"));
+        }
+        transaction.signatures[index] = signature;
+    }
+    Ok(transaction)
+}
+
+/// Serializes a bundle, defaulting to JSON
+pub fn encode_bundle(
+    bundle: &TransactionBundle,
+    format: TransactionBundleFormat,
+) -> Result<Vec<u8>, String> {
+    match format {
+        TransactionBundleFormat::Cbor => {
+            let mut bytes = Vec::new();
+            ciborium::into_writer(bundle, &mut bytes)
+                .map_err(|e| format!("Failed to encode bundle as CBOR: {e}"))?;
+            Ok(bytes)
+        }
+        TransactionBundleFormat::Json | TransactionBundleFormat::Unspecified => {
+            serde_json::to_vec_pretty(bundle)
+                .map_err(|e| format!("Failed to encode bundle as JSON: {e}"))
+        }
+    }
+}
+
+/// Parses a bundle, detecting the format when unspecified (JSON envelopes are objects,
+/// so they start with `{`)
+pub fn decode_bundle(
+    bytes: &[u8],
+    format: TransactionBundleFormat,
+) -> Result<TransactionBundle, String> {
+    let format = match format {
+        TransactionBundleFormat::Unspecified => {
+            if bytes.iter().find(|byte| !byte.is_ascii_whitespace()) == Some(&b'{') {
+                TransactionBundleFormat::Json
+            } else {
+                TransactionBundleFormat::Cbor
+            }
+        }
+        format => format,
+    };
+    match format {
+        TransactionBundleFormat::Cbor => {
+            ciborium::from_reader(bytes).map_err(|e| format!("Invalid CBOR bundle: {e}"))
+        }
+        _ => serde_json::from_slice(bytes).map_err(|e| format!("Invalid JSON bundle: {e}")),
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{
+        hash::Hash,
+        signature::{Keypair, Signer},
+        system_instruction,
+    };
+
+    fn unsigned_transfer(payer: &Keypair) -> SolanaTransaction {
+        let instruction =
+            system_instruction::transfer(&payer.pubkey(), &Pubkey::new_unique(), 1_000);
+        SolanaTransaction::new_unsigned(Message::new_with_blockhash(
+            &[instruction],
+            Some(&payer.pubkey()),
+            &Hash::new_unique(),
+        ))
+    }
+
+    /// Signs a bundle the way an offline device would
+    fn sign_bundle(bundle: &mut TransactionBundle, keypair: &Keypair) {
+        let message = STANDARD.decode(&bundle.message).unwrap();
+        let entry = bundle
+            .signatures
+            .iter_mut()
+            .find(|entry| entry.signer == keypair.pubkey().to_string())
+            .unwrap();
+        entry.signature = keypair.sign_message(&message).to_string();
+    }
+
+    #[test]
+    fn test_export_describes_instructions_and_signers() {
+        let payer = Keypair::new();
+        let bundle = export_bundle(&unsigned_transfer(&payer), &[], 1_234);
+
+        assert_eq!(bundle.version, BUNDLE_VERSION);
+        assert_eq!(bundle.fee_payer, payer.pubkey().to_string());
+        assert_eq!(bundle.last_valid_block_height, 1_234);
+        assert_eq!(bundle.instructions.len(), 1);
+        assert_eq!(bundle.instructions[0].description, "Transfer (System program)");
+        assert_eq!(bundle.signatures.len(), 1);
+        assert!(bundle.signatures[0].signature.is_empty());
+    }
+
+    #[test]
+    fn test_draft_descriptions_are_preferred() {
+        let payer = Keypair::new();
+        let draft = SolanaInstruction {
+            description: "Pay invoice 42".to_string(),
+            ..Default::default()
+        };
+        let bundle = export_bundle(&unsigned_transfer(&payer), &[draft], 0);
+        assert_eq!(bundle.instructions[0].description, "Pay invoice 42");
+    }
+
+    #[test]
+    fn test_signed_bundle_round_trips_in_both_formats() {
+        let payer = Keypair::new();
+        let mut bundle = export_bundle(&unsigned_transfer(&payer), &[], 0);
+        sign_bundle(&mut bundle, &payer);
+
+        for format in [TransactionBundleFormat::Json, TransactionBundleFormat::Cbor] {
+            let bytes = encode_bundle(&bundle, format).unwrap();
+            let decoded = decode_bundle(&bytes, TransactionBundleFormat::Unspecified).unwrap();
+            assert_eq!(decoded, bundle);
+
+            let transaction = import_bundle(&decoded).unwrap();
+            assert!(transaction.is_signed());
+            assert!(transaction.verify().is_ok());
+        }
+    }
+
+    #[test]
+    fn test_import_rejects_tampered_bundles() {
+        let payer = Keypair::new();
+        let mut bundle = export_bundle(&unsigned_transfer(&payer), &[], 0);
+        sign_bundle(&mut bundle, &payer);
+
+        let mut wrong_message = bundle.clone();
+        wrong_message.message = export_bundle(&unsigned_transfer(&payer), &[], 0).message;
+        assert!(import_bundle(&wrong_message).is_err());
+
+        let mut wrong_signer = bundle.clone();
+        wrong_signer.signatures[0].signer = Pubkey::new_unique().to_string();
+        assert!(import_bundle(&wrong_signer).is_err());
+
+        let mut wrong_version = bundle;
+        wrong_version.version = BUNDLE_VERSION + 1;
+        assert!(import_bundle(&wrong_version).is_err());
+    }
+}
@@ -0,0 +1,468 @@
+use solana_sdk::{
+    hash::Hash,
+    instruction::{AccountMeta, Instruction},
+    message::Message,
+    pubkey::Pubkey,
+    transaction::Transaction as SolanaTransaction,
+};
+use std::str::FromStr;
+
+use crate::api::common::solana_conversions::{proto_instruction_to_sdk, sdk_instruction_to_proto};
+use crate::api::transaction::v1::diagnostics::decode_data;
+use protochain_api::protochain::solana::transaction::v1::{
+    AccountChange, AccountChangeKind, CompareTransactionsResponse, InstructionChange,
+    InstructionChangeKind, Transaction, TransactionState,
+};
+
+/// A transaction in any state, reduced to the parts that can be compared
+#[derive(Debug, Clone)]
+struct MessageView {
+    message: Message,
+    fee_payer: Option<Pubkey>,
+    recent_blockhash: Option<Hash>,
+}
+
+/// Builds the comparable view of a transaction, compiling drafts locally
+fn message_view(transaction: &Transaction, fee_payer: &str) -> Result<MessageView, String> {
+    match transaction.state() {
+        TransactionState::Draft | TransactionState::Unspecified => {
+            let instructions = transaction
+                .instructions
+                .iter()
+                .enumerate()
+                .map(|(index, proto_ix)| {
+                    proto_instruction_to_sdk(proto_ix.clone())
+                        .map_err(|e| format!("Invalid instruction {index}: {e}"))
+                })
+                .collect::<Result<Vec<Instruction>, String>>()?;
+
+            let fee_payer = if transaction.fee_payer.is_empty() {
+                fee_payer
+            } else {
+                &transaction.fee_payer
+            };
+            let fee_payer = (!fee_payer.is_empty())
+                .then(|| Pubkey::from_str(fee_payer))
+                .transpose()
+                .map_err(|e| format!("Invalid fee_payer: {e}"))?;
+            let recent_blockhash = (!transaction.recent_blockhash.is_empty())
+                .then(|| Hash::from_str(&transaction.recent_blockhash))
+                .transpose()
+                .map_err(|e| format!("Invalid recent_blockhash: {e}"))?;
+
+            Ok(MessageView {
+                message: Message::new(&instructions, fee_payer.as_ref()),
+                fee_payer,
+                recent_blockhash,
+            })
+        }
+        TransactionState::Compiled => Ok(compiled_view(decode_data(&transaction.data)?)),
+        TransactionState::PartiallySigned | TransactionState::FullySigned => {
+            let signed: SolanaTransaction = decode_data(&transaction.data)?;
+            Ok(compiled_view(signed.message))
+        }
+    }
+}
+
+fn compiled_view(message: Message) -> MessageView {
+    let fee_payer = (message.header.num_required_signatures > 0)
+        .then(|| message.account_keys.first().copied())
+        .flatten();
+    MessageView {
+        recent_blockhash: Some(message.recent_blockhash),
+        message,
+        fee_payer,
+    }
+}
+
+/// Whether account `index` is writable according to the message header
+pub fn is_writable_index(message: &Message, index: usize) -> bool {
+    let header = &message.header;
+    let signers = usize::from(header.num_required_signatures);
+    if index < signers {
+        index < signers.saturating_sub(usize::from(header.num_readonly_signed_accounts))
+    } else {
+        index
+            < message
+                .account_keys
+                .len()
+                .saturating_sub(usize::from(header.num_readonly_unsigned_accounts))
+    }
+}
+
+/// Expands compiled instructions back into instructions with full account metas
+pub fn message_instructions(message: &Message) -> Vec<Instruction> {
+    let key = |index: usize| message.account_keys.get(index).copied().unwrap_or_default();
+    message
+        .instructions
+        .iter()
+        .map(|compiled| Instruction {
+            program_id: key(usize::from(compiled.program_id_index)),
+            accounts: compiled
+                .accounts
+                .iter()
+                .map(|index| {
+                    let index = usize::from(*index);
+                    AccountMeta {
+                        pubkey: key(index),
+                        is_signer: message.is_signer(index),
+                        is_writable: is_writable_index(message, index),
+                    }
+                })
+                .collect(),
+            data: compiled.data.clone(),
+        })
+        .collect()
+}
+
+/// Index pairs of a longest common subsequence of `a` and `b`, in order
+fn common_subsequence<T: PartialEq>(a: &[T], b: &[T]) -> Vec<(usize, usize)> {
+    let mut lengths = vec![vec![0usize; b.len() + 1]; a.len() + 1];
+    for i in (0..a.len()).rev() {
+        for j in (0..b.len()).rev() {
+            lengths[i][j] = if a[i] == b[j] {
+                lengths[i + 1][j + 1] + 1
+            } else {
+                lengths[i + 1][j].max(lengths[i][j + 1])
+            };
+        }
+    }
+
+    let (mut i, mut j) = (0, 0);
+    let mut pairs = Vec::new();
+    while i < a.len() && j < b.len() {
+        if a[i] == b[j] {
+            pairs.push((i, j));
+            i += 1;
+            j += 1;
+        } else if lengths[i + 1][j] >= lengths[i][j + 1] {
+            i += 1;
+        } else {
+            j += 1;
+        }
+    }
+    pairs
+}
+
+fn to_index(index: Option<usize>) -> i32 {
+    index
+        .and_then(|index| i32::try_from(index).ok())
+        .unwrap_or(-1)
+}
+
+/// Account list differences: membership, relative order and permissions
+fn account_changes(base: &Message, target: &Message) -> Vec<AccountChange> {
+    let position = |message: &Message, account: &Pubkey| {
+        message.account_keys.iter().position(|key| key == account)
+    };
+    let change = |kind: AccountChangeKind, account: &Pubkey| {
+        let base_index = position(base, account);
+        let target_index = position(target, account);
+        AccountChange {
+            account: account.to_string(),
+            kind: kind.into(),
+            base_index: to_index(base_index),
+            target_index: to_index(target_index),
+            base_signer: base_index.is_some_and(|index| base.is_signer(index)),
+            target_signer: target_index.is_some_and(|index| target.is_signer(index)),
+            base_writable: base_index.is_some_and(|index| is_writable_index(base, index)),
+            target_writable: target_index.is_some_and(|index| is_writable_index(target, index)),
+        }
+    };
+
+    let mut changes = Vec::new();
+    for account in &base.account_keys {
+        if position(target, account).is_none() {
+            changes.push(change(AccountChangeKind::Removed, account));
+        }
+    }
+    for account in &target.account_keys {
+        if position(base, account).is_none() {
+            changes.push(change(AccountChangeKind::Added, account));
+        }
+    }
+
+    // Shared accounts outside the longest common ordering moved relative to the others
+    let shared_base: Vec<Pubkey> = base
+        .account_keys
+        .iter()
+        .filter(|account| position(target, account).is_some())
+        .copied()
+        .collect();
+    let shared_target: Vec<Pubkey> = target
+        .account_keys
+        .iter()
+        .filter(|account| position(base, account).is_some())
+        .copied()
+        .collect();
+    let in_order = common_subsequence(&shared_base, &shared_target);
+    for (index, account) in shared_base.iter().enumerate() {
+        if !in_order.iter().any(|(base_index, _)| *base_index == index) {
+            changes.push(change(AccountChangeKind::Reordered, account));
+        }
+    }
+
+    for account in &shared_base {
+        let permissions = change(AccountChangeKind::PermissionsChanged, account);
+        if permissions.base_signer != permissions.target_signer
+            || permissions.base_writable != permissions.target_writable
+        {
+            changes.push(permissions);
+        }
+    }
+
+    changes
+}
+
+/// Instruction differences after aligning identical instructions
+fn instruction_changes(base: &[Instruction], target: &[Instruction]) -> Vec<InstructionChange> {
+    let change = |kind: InstructionChangeKind,
+                  base_index: Option<usize>,
+                  target_index: Option<usize>| {
+        let base_ix = base_index.map(|index| &base[index]);
+        let target_ix = target_index.map(|index| &target[index]);
+        let modified = kind == InstructionChangeKind::Modified;
+        InstructionChange {
+            kind: kind.into(),
+            base_index: to_index(base_index),
+            target_index: to_index(target_index),
+            program_changed: modified
+                && base_ix.map(|ix| ix.program_id) != target_ix.map(|ix| ix.program_id),
+            accounts_changed: modified
+                && base_ix.map(|ix| &ix.accounts) != target_ix.map(|ix| &ix.accounts),
+            data_changed: modified && base_ix.map(|ix| &ix.data) != target_ix.map(|ix| &ix.data),
+            base: base_ix.cloned().map(sdk_instruction_to_proto),
+            target: target_ix.cloned().map(sdk_instruction_to_proto),
+        }
+    };
+
+    let mut changes = Vec::new();
+    let mut anchors = common_subsequence(base, target);
+    anchors.push((base.len(), target.len()));
+
+    // Between aligned instructions, pair leftovers positionally as modifications
+    let (mut base_next, mut target_next) = (0, 0);
+    for (base_anchor, target_anchor) in anchors {
+        let removed = base_next..base_anchor;
+        let added = target_next..target_anchor;
+        let paired = removed.len().min(added.len());
+        for offset in 0..paired {
+            changes.push(change(
+                InstructionChangeKind::Modified,
+                Some(base_next + offset),
+                Some(target_next + offset),
+            ));
+        }
+        for index in removed.skip(paired) {
+            changes.push(change(InstructionChangeKind::Removed, Some(index), None));
+        }
+        for index in added.skip(paired) {
+            changes.push(change(InstructionChangeKind::Added, None, Some(index)));
+        }
+        base_next = base_anchor + 1;
+        target_next = target_anchor + 1;
+    }
+
+    changes
+}
+
+/// Required signers of a message, in account order
+fn signers(message: &Message) -> Vec<Pubkey> {
+    message
+        .account_keys
+        .iter()
+        .take(usize::from(message.header.num_required_signatures))
+        .copied()
+        .collect()
+}
+
+/// Compares two transactions in any state without touching the network
+pub fn compare_transactions(
+    base: &Transaction,
+    target: &Transaction,
+    fee_payer: &str,
+) -> Result<CompareTransactionsResponse, String> {
+    let base = message_view(base, fee_payer).map_err(|e| format!("Invalid base: {e}"))?;
+    let target = message_view(target, fee_payer).map_err(|e| format!("Invalid target: {e}"))?;
+
+    let account_changes = account_changes(&base.message, &target.message);
+    let instruction_changes = instruction_changes(
+        &message_instructions(&base.message),
+        &message_instructions(&target.message),
+    );
+
+    let base_signers = signers(&base.message);
+    let target_signers = signers(&target.message);
+    let added_signers: Vec<String> = target_signers
+        .iter()
+        .filter(|signer| !base_signers.contains(signer))
+        .map(ToString::to_string)
+        .collect();
+    let removed_signers: Vec<String> = base_signers
+        .iter()
+        .filter(|signer| !target_signers.contains(signer))
+        .map(ToString::to_string)
+        .collect();
+
+    let fee_payer_changed = base.fee_payer != target.fee_payer;
+    let recent_blockhash_changed = matches!(
+        (base.recent_blockhash, target.recent_blockhash),
+        (Some(base_hash), Some(target_hash)) if base_hash != target_hash
+    );
+
+    Ok(CompareTransactionsResponse {
+        identical: account_changes.is_empty()
+            && instruction_changes.is_empty()
+            && !fee_payer_changed
+            && !recent_blockhash_changed,
+        account_changes,
+        instruction_changes,
+        added_signers,
+        removed_signers,
+        base_fee_payer: base
+            .fee_payer
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        target_fee_payer: target
+            .fee_payer
+            .map(|key| key.to_string())
+            .unwrap_or_default(),
+        fee_payer_changed,
+        recent_blockhash_changed,
+        base_required_signers: u32::from(base.message.header.num_required_signatures),
+        target_required_signers: u32::from(target.message.header.num_required_signatures),
+    })
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::compute_budget::ComputeBudgetInstruction;
+    use solana_sdk::system_instruction;
+
+    fn draft(instructions: &[Instruction], fee_payer: &Pubkey) -> Transaction {
+        Transaction {
+            instructions: instructions
+                .iter()
+                .cloned()
+                .map(sdk_instruction_to_proto)
+                .collect(),
+            state: TransactionState::Draft.into(),
+            fee_payer: fee_payer.to_string(),
+            ..Default::default()
+        }
+    }
+
+    fn compiled(instructions: &[Instruction], fee_payer: &Pubkey) -> Transaction {
+        let message =
+            Message::new_with_blockhash(instructions, Some(fee_payer), &Hash::new_unique());
+        Transaction {
+            state: TransactionState::Compiled.into(),
+            data: bs58::encode(bincode::serialize(&message).unwrap()).into_string(),
+            fee_payer: fee_payer.to_string(),
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_draft_matches_its_compiled_form() {
+        let payer = Pubkey::new_unique();
+        let instructions = [system_instruction::transfer(
+            &payer,
+            &Pubkey::new_unique(),
+            1,
+        )];
+
+        let diff = compare_transactions(
+            &draft(&instructions, &payer),
+            &compiled(&instructions, &payer),
+            "",
+        )
+        .unwrap();
+
+        assert!(diff.identical);
+        assert_eq!(diff.base_required_signers, 1);
+    }
+
+    #[test]
+    fn test_prepended_instruction_is_added_not_modified() {
+        let payer = Pubkey::new_unique();
+        let transfer = system_instruction::transfer(&payer, &Pubkey::new_unique(), 1);
+        let budget = ComputeBudgetInstruction::set_compute_unit_limit(10_000);
+
+        let diff = compare_transactions(
+            &draft(&[transfer.clone()], &payer),
+            &draft(&[budget, transfer], &payer),
+            "",
+        )
+        .unwrap();
+
+        assert!(!diff.identical);
+        assert_eq!(diff.instruction_changes.len(), 1);
+        let added = &diff.instruction_changes[0];
+        assert_eq!(added.kind(), InstructionChangeKind::Added);
+        assert_eq!((added.base_index, added.target_index), (-1, 0));
+        assert!(diff
+            .account_changes
+            .iter()
+            .any(|change| change.kind() == AccountChangeKind::Added));
+    }
+
+    #[test]
+    fn test_modified_instruction_and_signer_changes() {
+        let payer = Pubkey::new_unique();
+        let recipient = Pubkey::new_unique();
+        let other_payer = Pubkey::new_unique();
+
+        let diff = compare_transactions(
+            &draft(&[system_instruction::transfer(&payer, &recipient, 1)], &payer),
+            &draft(&[system_instruction::transfer(&other_payer, &recipient, 2)], &other_payer),
+            "",
+        )
+        .unwrap();
+
+        let modified = &diff.instruction_changes[0];
+        assert_eq!(modified.kind(), InstructionChangeKind::Modified);
+        assert!(!modified.program_changed);
+        assert!(modified.accounts_changed);
+        assert!(modified.data_changed);
+        assert!(diff.fee_payer_changed);
+        assert_eq!(diff.added_signers, vec![other_payer.to_string()]);
+        assert_eq!(diff.removed_signers, vec![payer.to_string()]);
+    }
+
+    #[test]
+    fn test_permission_change_detected() {
+        let payer = Pubkey::new_unique();
+        let account = Pubkey::new_unique();
+        let program = Pubkey::new_unique();
+        let readonly = Instruction::new_with_bytes(
+            program,
+            &[],
+            vec![AccountMeta::new_readonly(account, false)],
+        );
+        let writable =
+            Instruction::new_with_bytes(program, &[], vec![AccountMeta::new(account, false)]);
+
+        let diff =
+            compare_transactions(&draft(&[readonly], &payer), &draft(&[writable], &payer), "")
+                .unwrap();
+
+        let permissions = diff
+            .account_changes
+            .iter()
+            .find(|change| change.kind() == AccountChangeKind::PermissionsChanged)
+            .unwrap();
+        assert_eq!(permissions.account, account.to_string());
+        assert!(!permissions.base_writable);
+        assert!(permissions.target_writable);
+    }
+
+    #[test]
+    fn test_common_subsequence() {
+        assert_eq!(common_subsequence(&[1, 2, 3], &[0, 1, 3]), vec![(0, 1), (2, 2)]);
+        assert_eq!(common_subsequence(&[1, 2], &[2, 1]).len(), 1);
+        assert!(common_subsequence::<u8>(&[], &[1]).is_empty());
+    }
+}
@@ -0,0 +1,141 @@
+use solana_sdk::compute_budget::{self, ComputeBudgetInstruction};
+use solana_sdk::instruction::Instruction;
+
+/// Maximum compute units a single transaction may request
+pub const MAX_COMPUTE_UNIT_LIMIT: u32 = 1_400_000;
+/// Default safety margin added on top of simulated compute units
+pub const DEFAULT_COMPUTE_UNIT_MARGIN_PERCENT: u32 = 10;
+/// Upper bound on the safety margin
+pub const MAX_COMPUTE_UNIT_MARGIN_PERCENT: u32 = 100;
+/// Smallest heap frame `RequestHeapFrame` may request (the default heap)
+pub const MIN_HEAP_FRAME_BYTES: u32 = 32 * 1024;
+/// Largest heap frame `RequestHeapFrame` may request
+pub const MAX_HEAP_FRAME_BYTES: u32 = 256 * 1024;
+/// Granularity of requested heap frames
+pub const HEAP_FRAME_GRANULARITY_BYTES: u32 = 1024;
+/// Largest account data a transaction may load
+pub const MAX_LOADED_ACCOUNTS_DATA_SIZE_BYTES: u32 = 64 * 1024 * 1024;
+
+/// Whether any instruction targets the compute budget program
+pub fn has_compute_budget_instruction(instructions: &[Instruction]) -> bool {
+    instructions
+        .iter()
+        .any(|instruction| instruction.program_id == compute_budget::id())
+}
+
+/// Resolves the requested margin, where zero selects the default
+pub fn resolve_margin_percent(margin_percent: u32) -> Result<u32, String> {
+    match margin_percent {
+        0 => Ok(DEFAULT_COMPUTE_UNIT_MARGIN_PERCENT),
+        margin if margin > MAX_COMPUTE_UNIT_MARGIN_PERCENT => Err(format!(
+            "Compute unit margin must not exceed {MAX_COMPUTE_UNIT_MARGIN_PERCENT}%"
+        )),
+        margin => Ok(margin),
+    }
+}
+
+/// Checks a requested compute unit limit
+pub fn validate_compute_unit_limit(units: u32) -> Result<(), String> {
+    if units == 0 || units > MAX_COMPUTE_UNIT_LIMIT {
+        return Err(format!(
+            "Compute unit limit must be between 1 and {MAX_COMPUTE_UNIT_LIMIT}, got {units}"
+        ));
+    }
+    Ok(())
+}
+
+/// Checks a requested heap frame, which the runtime only accepts as a multiple of 1 KiB
+/// between 32 KiB and 256 KiB
+pub fn validate_heap_frame_bytes(bytes: u32) -> Result<(), String> {
+    if !(MIN_HEAP_FRAME_BYTES..=MAX_HEAP_FRAME_BYTES).contains(&bytes)
+        || bytes % HEAP_FRAME_GRANULARITY_BYTES != 0
+    {
+        return Err(format!(
+            "Heap frame must be a multiple of {HEAP_FRAME_GRANULARITY_BYTES} bytes between {MIN_HEAP_FRAME_BYTES} and {MAX_HEAP_FRAME_BYTES}, got {bytes}"
+        ));
+    }
+    Ok(())
+}
+
+/// Checks a requested loaded accounts data size limit
+pub fn validate_loaded_accounts_data_size_limit(bytes: u32) -> Result<(), String> {
+    if bytes == 0 || bytes > MAX_LOADED_ACCOUNTS_DATA_SIZE_BYTES {
+        return Err(format!(
+            "Loaded accounts data size limit must be between 1 and {MAX_LOADED_ACCOUNTS_DATA_SIZE_BYTES} bytes, got {bytes}"
+        ));
+    }
+    Ok(())
+}
+
+/// Compute unit limit covering `units_consumed` plus `margin_percent`, rounded up and capped
+pub fn compute_unit_limit_with_margin(units_consumed: u64, margin_percent: u32) -> u32 {
+    let scaled = u128::from(units_consumed) * u128::from(100 + margin_percent);
+    u32::try_from(scaled.div_ceil(100))
+        .unwrap_or(MAX_COMPUTE_UNIT_LIMIT)
+        .min(MAX_COMPUTE_UNIT_LIMIT)
+}
+
+/// Prepends SetComputeUnitLimit (and SetComputeUnitPrice when non-zero) to `instructions`
+pub fn with_compute_budget(
+    instructions: &[Instruction],
+    compute_unit_limit: u32,
+    compute_unit_price: u64,
+) -> Vec<Instruction> {
+    let mut budgeted = Vec::with_capacity(instructions.len() + 2);
+    budgeted.push(ComputeBudgetInstruction::set_compute_unit_limit(compute_unit_limit));
+    if compute_unit_price > 0 {
+        budgeted.push(ComputeBudgetInstruction::set_compute_unit_price(compute_unit_price));
+    }
+    budgeted.extend_from_slice(instructions);
+    budgeted
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use solana_sdk::{pubkey::Pubkey, system_instruction};
+
+    #[test]
+    fn test_margin_rounds_up_and_caps() {
+        assert_eq!(compute_unit_limit_with_margin(1_000, 10), 1_100);
+        assert_eq!(compute_unit_limit_with_margin(1_001, 10), 1_102);
+        assert_eq!(compute_unit_limit_with_margin(1_000, 0), 1_000);
+        assert_eq!(compute_unit_limit_with_margin(1_300_000, 50), MAX_COMPUTE_UNIT_LIMIT);
+    }
+
+    #[test]
+    fn test_resolve_margin_percent() {
+        assert_eq!(resolve_margin_percent(0), Ok(DEFAULT_COMPUTE_UNIT_MARGIN_PERCENT));
+        assert_eq!(resolve_margin_percent(25), Ok(25));
+        assert!(resolve_margin_percent(MAX_COMPUTE_UNIT_MARGIN_PERCENT + 1).is_err());
+    }
+
+    #[test]
+    fn test_validate_budget_requests() {
+        assert!(validate_compute_unit_limit(200_000).is_ok());
+        assert!(validate_compute_unit_limit(0).is_err());
+        assert!(validate_compute_unit_limit(MAX_COMPUTE_UNIT_LIMIT + 1).is_err());
+        assert!(validate_heap_frame_bytes(64 * 1024).is_ok());
+        assert!(validate_heap_frame_bytes(64 * 1024 + 1).is_err());
+        assert!(validate_heap_frame_bytes(512 * 1024).is_err());
+        assert!(validate_loaded_accounts_data_size_limit(1).is_ok());
+        assert!(validate_loaded_accounts_data_size_limit(0).is_err());
+    }
+
+    #[test]
+    fn test_with_compute_budget_prepends_instructions() {
+        let transfer =
+            system_instruction::transfer(&Pubkey::new_unique(), &Pubkey::new_unique(), 1);
+
+        let budgeted = with_compute_budget(&[transfer.clone()], 5_000, 0);
+        assert_eq!(budgeted.len(), 2);
+        assert_eq!(budgeted[0], ComputeBudgetInstruction::set_compute_unit_limit(5_000));
+        assert_eq!(budgeted[1], transfer);
+
+        let priced = with_compute_budget(&[transfer.clone()], 5_000, 42);
+        assert_eq!(priced.len(), 3);
+        assert_eq!(priced[1], ComputeBudgetInstruction::set_compute_unit_price(42));
+        assert!(has_compute_budget_instruction(&priced));
+        assert!(!has_compute_budget_instruction(&[transfer]));
+    }
+}
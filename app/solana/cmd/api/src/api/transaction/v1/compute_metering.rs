@@ -0,0 +1,197 @@
+use protochain_api::protochain::solana::transaction::v1::InstructionComputeUsage;
+
+/// A runtime log line that affects instruction boundaries or metering
+#[derive(Debug, PartialEq, Eq)]
+enum ProgramLog<'a> {
+    /// `Program <id> invoke [<depth>]`
+    Invoke { program_id: &'a str, depth: usize },
+    /// `Program <id> consumed <n> of <m> compute units`
+    Consumed { consumed: u64, remaining: u64 },
+    /// `Program <id> success`
+    Success,
+    /// `Program <id> failed: <reason>`
+    Failed { reason: &'a str },
+}
+
+/// Parses the runtime's program log lines, ignoring program output (`Program log:` etc.)
+fn parse_program_log(line: &str) -> Option<ProgramLog<'_>> {
+    let rest = line.strip_prefix("Program ")?;
+    let (program_id, event) = rest.split_once(' ')?;
+
+    if let Some(depth) = event.strip_prefix("invoke [") {
+        let depth = depth.strip_suffix(']')?.parse().ok()?;
+        return Some(ProgramLog::Invoke { program_id, depth });
+    }
+    if let Some(consumed) = event.strip_prefix("consumed ") {
+        let (consumed, remaining) = consumed
+            .strip_suffix(" compute units")?
+            .split_once(" of ")?;
+        return Some(ProgramLog::Consumed {
+            consumed: consumed.parse().ok()?,
+            remaining: remaining.parse().ok()?,
+        });
+    }
+    if event == "success" {
+        return Some(ProgramLog::Success);
+    }
+    event
+        .strip_prefix("failed: ")
+        .map(|reason| ProgramLog::Failed { reason })
+}
+
+/// Splits simulation logs into per top-level instruction slices with their compute usage.
+///
+/// Top-level instructions are the `invoke [1]` frames, which the runtime executes in
+/// transaction order. Consumption is only attributed from the top-level frame, since it
+/// already includes every CPI it made. If the logs were truncated the last instruction
+/// is reported as incomplete.
+pub fn meter_instructions(logs: &[String]) -> Vec<InstructionComputeUsage> {
+    let mut usages: Vec<InstructionComputeUsage> = Vec::new();
+    let mut depth = 0usize;
+
+    for line in logs {
+        let event = parse_program_log(line);
+
+        if let Some(ProgramLog::Invoke {
+            program_id,
+            depth: 1,
+        }) = event
+        {
+            usages.push(InstructionComputeUsage {
+                instruction_index: u32::try_from(usages.len()).unwrap_or(u32::MAX),
+                program_id: program_id.to_string(),
+                truncated: true,
+                ..Default::default()
+            });
+        }
+
+        // Lines outside an instruction frame (e.g. "Log truncated") belong to no instruction
+        if depth == 0 && !matches!(event, Some(ProgramLog::Invoke { .. })) {
+            continue;
+        }
+        let Some(usage) = usages.last_mut() else {
+            continue;
+        };
+        usage.logs.push(line.clone());
+
+        match event {
+            Some(ProgramLog::Invoke { depth: invoked, .. }) => depth = invoked,
+            Some(ProgramLog::Consumed {
+                consumed,
+                remaining,
+            }) if depth == 1 => {
+                usage.compute_units_consumed = Some(consumed);
+                usage.compute_units_remaining = remaining;
+            }
+            Some(ProgramLog::Success) => {
+                depth = depth.saturating_sub(1);
+                if depth == 0 {
+                    usage.success = true;
+                    usage.truncated = false;
+                }
+            }
+            Some(ProgramLog::Failed { reason }) => {
+                depth = depth.saturating_sub(1);
+                if depth == 0 {
+                    usage.error = reason.to_string();
+                    usage.truncated = false;
+                }
+            }
+            _ => {}
+        }
+    }
+
+    usages
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    fn logs(lines: &[&str]) -> Vec<String> {
+        lines.iter().map(ToString::to_string).collect()
+    }
+
+    #[test]
+    fn test_parse_program_log() {
+        assert_eq!(
+            parse_program_log("Program 11111111111111111111111111111111 invoke [1]"),
+            Some(ProgramLog::Invoke {
+                program_id: "11111111111111111111111111111111",
+                depth: 1
+            })
+        );
+        assert_eq!(
+            parse_program_log("Program Tok consumed 2712 of 199850 compute units"),
+            Some(ProgramLog::Consumed {
+                consumed: 2712,
+                remaining: 199_850
+            })
+        );
+        assert_eq!(parse_program_log("Program Tok success"), Some(ProgramLog::Success));
+        assert_eq!(parse_program_log("Program log: Instruction: Transfer"), None);
+        assert_eq!(parse_program_log("Program data: AAEC"), None);
+    }
+
+    #[test]
+    fn test_meters_each_top_level_instruction() {
+        let usages = meter_instructions(&logs(&[
+            "Program ComputeBudget111111111111111111111111111111 invoke [1]",
+            "Program ComputeBudget111111111111111111111111111111 success",
+            "Program Tok invoke [1]",
+            "Program log: Instruction: MintTo",
+            "Program Tok consumed 4500 of 199850 compute units",
+            "Program Tok success",
+            "Program 11111111111111111111111111111111 invoke [1]",
+            "Program 11111111111111111111111111111111 success",
+        ]));
+
+        assert_eq!(usages.len(), 3);
+        assert_eq!(usages[0].compute_units_consumed, None);
+        assert!(usages[0].success);
+        assert_eq!(usages[1].instruction_index, 1);
+        assert_eq!(usages[1].program_id, "Tok");
+        assert_eq!(usages[1].compute_units_consumed, Some(4500));
+        assert_eq!(usages[1].compute_units_remaining, 199_850);
+        assert_eq!(usages[1].logs.len(), 4);
+        assert_eq!(usages[2].instruction_index, 2);
+    }
+
+    #[test]
+    fn test_cpi_consumption_attributed_to_top_level() {
+        let usages = meter_instructions(&logs(&[
+            "Program Ata invoke [1]",
+            "Program Tok invoke [2]",
+            "Program Tok consumed 1000 of 190000 compute units",
+            "Program Tok success",
+            "Program Ata consumed 9000 of 200000 compute units",
+            "Program Ata success",
+        ]));
+
+        assert_eq!(usages.len(), 1);
+        assert_eq!(usages[0].compute_units_consumed, Some(9000));
+        assert_eq!(usages[0].logs.len(), 6);
+        assert!(usages[0].success);
+    }
+
+    #[test]
+    fn test_failed_and_truncated_instructions() {
+        let failed = meter_instructions(&logs(&[
+            "Program Tok invoke [1]",
+            "Program log: Error: insufficient funds",
+            "Program Tok consumed 3000 of 200000 compute units",
+            "Program Tok failed: custom program error: 0x1",
+        ]));
+        assert!(!failed[0].success);
+        assert_eq!(failed[0].error, "custom program error: 0x1");
+        assert!(!failed[0].truncated);
+
+        let truncated = meter_instructions(&logs(&[
+            "Program Tok invoke [1]",
+            "Program log: a",
+            "Log truncated",
+        ]));
+        assert!(truncated[0].truncated);
+        assert!(!truncated[0].success);
+    }
+}
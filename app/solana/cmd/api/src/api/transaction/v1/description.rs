@@ -0,0 +1,634 @@
+use solana_account_decoder::UiAccount;
+use solana_sdk::{account::Account, message::Message, pubkey::Pubkey};
+use spl_token_2022::{
+    extension::StateWithExtensions,
+    state::{Account as TokenAccount, Mint},
+};
+use std::collections::HashMap;
+use std::fmt::Display;
+use std::str::FromStr;
+
+use crate::api::common::instruction_decoding::{program_kind, TOKEN_PROGRAM_ID};
+use crate::api::transaction::v1::comparison::is_writable_index;
+use crate::service_providers::localization::Localizer;
+use protochain_api::protochain::solana::transaction::v1::{
+    decoded_instruction::Details, DecodedInstruction, DescribedAccount, DescribedInstruction,
+    InstructionAccount, LamportMovement, ProgramKind, SimulatedAccount, TokenInstructionDetails,
+    TokenMovement,
+};
+
+/// Decimals of the native SOL balance
+pub const SOL_DECIMALS: u32 = 9;
+
+/// Display name of a program, the program ID itself when it is not a known program
+pub fn program_name(program_id: &Pubkey) -> String {
+    match program_kind(program_id) {
+        ProgramKind::System => "System Program".to_string(),
+        ProgramKind::Token => "Token Program".to_string(),
+        ProgramKind::Token2022 => "Token-2022 Program".to_string(),
+        ProgramKind::AssociatedToken => "Associated Token Account Program".to_string(),
+        ProgramKind::ComputeBudget => "Compute Budget Program".to_string(),
+        ProgramKind::Memo => "Memo Program".to_string(),
+        ProgramKind::Unspecified => program_id.to_string(),
+    }
+}
+
+/// Formats a raw amount with `decimals`, trimming trailing zeros (1500 with 3 is "1.5")
+pub fn format_amount(amount: u64, decimals: u32) -> String {
+    let Some(scale) = 10u128.checked_pow(decimals) else {
+        return amount.to_string();
+    };
+    let amount = u128::from(amount);
+    let whole = amount / scale;
+    let fraction = amount % scale;
+    if fraction == 0 {
+        return whole.to_string();
+    }
+    let fraction = format!("{fraction:0width$}", width = decimals as usize);
+    format!("{whole}.{}", fraction.trim_end_matches('0'))
+}
+
+/// Formats lamports as SOL
+fn format_sol(lamports: u64, localizer: &Localizer) -> String {
+    localizer.text("amount.sol", &[("amount", &format_amount(lamports, SOL_DECIMALS))])
+}
+
+/// Formats a token instruction's amount, scaled by the decimals the instruction carries
+/// or, failing that, the known decimals of its mint
+fn format_token_amount(
+    details: &TokenInstructionDetails,
+    decimals: &HashMap<String, u32>,
+    localizer: &Localizer,
+) -> String {
+    let known = details
+        .decimals
+        .or_else(|| decimals.get(&details.mint).copied());
+    match (known, details.mint.is_empty()) {
+        (Some(decimals), false) => localizer.text(
+            "amount.token",
+            &[
+                ("amount", &format_amount(details.amount, decimals)),
+                ("mint", &details.mint),
+            ],
+        ),
+        (None, false) => localizer.text(
+            "amount.token_base_units",
+            &[("amount", &details.amount), ("mint", &details.mint)],
+        ),
+        (_, true) => localizer.text("amount.base_units", &[("amount", &details.amount)]),
+    }
+}
+
+/// One-line description of a decoded instruction, in the localizer's locale.
+///
+/// `decimals` holds the decimals of mints read from the chain, used for token amounts
+/// whose instruction does not carry them.
+pub fn summarize_instruction(
+    instruction: &DecodedInstruction,
+    program_name: &str,
+    decimals: &HashMap<String, u32>,
+    localizer: &Localizer,
+) -> String {
+    let action = instruction.instruction_type.as_str();
+    let text = |key: &str, args: &[(&str, &dyn Display)]| Some(localizer.text(key, args));
+    let summary = match &instruction.details {
+        Some(Details::System(d)) => match action {
+            "Transfer" | "TransferWithSeed" => text(
+                "describe.system.transfer",
+                &[
+                    ("amount", &format_sol(d.lamports, localizer)),
+                    ("source", &d.source),
+                    ("destination", &d.destination),
+                ],
+            ),
+            "CreateAccount" | "CreateAccountWithSeed" => text(
+                "describe.system.create_account",
+                &[
+                    ("account", &d.destination),
+                    ("amount", &format_sol(d.lamports, localizer)),
+                    ("space", &d.space),
+                    ("owner", &owner_name(&d.owner)),
+                    ("payer", &d.source),
+                ],
+            ),
+            "WithdrawNonceAccount" => text(
+                "describe.system.withdraw_nonce",
+                &[
+                    ("amount", &format_sol(d.lamports, localizer)),
+                    ("nonce_account", &d.source),
+                    ("destination", &d.destination),
+                ],
+            ),
+            "AdvanceNonceAccount" => {
+                text("describe.system.advance_nonce", &[("nonce_account", &d.destination)])
+            }
+            "InitializeNonceAccount" => text(
+                "describe.system.initialize_nonce",
+                &[
+                    ("nonce_account", &d.destination),
+                    ("authority", &d.new_authority),
+                ],
+            ),
+            "AuthorizeNonceAccount" => text(
+                "describe.system.authorize_nonce",
+                &[
+                    ("nonce_account", &d.destination),
+                    ("authority", &d.new_authority),
+                ],
+            ),
+            "Assign" | "AssignWithSeed" => text(
+                "describe.system.assign",
+                &[
+                    ("account", &d.destination),
+                    ("owner", &owner_name(&d.owner)),
+                ],
+            ),
+            "Allocate" | "AllocateWithSeed" => text(
+                "describe.system.allocate",
+                &[("space", &d.space), ("account", &d.destination)],
+            ),
+            _ => None,
+        },
+        Some(Details::Token(d)) => {
+            let amount = || format_token_amount(d, decimals, localizer);
+            match action {
+                "Transfer" | "TransferChecked" => text(
+                    "describe.token.transfer",
+                    &[
+                        ("amount", &amount()),
+                        ("source", &d.source),
+                        ("destination", &d.destination),
+                    ],
+                ),
+                "MintTo" | "MintToChecked" => text(
+                    "describe.token.mint_to",
+                    &[("amount", &amount()), ("destination", &d.destination)],
+                ),
+                "Burn" | "BurnChecked" => {
+                    text("describe.token.burn", &[("amount", &amount()), ("source", &d.source)])
+                }
+                "Approve" | "ApproveChecked" => text(
+                    "describe.token.approve",
+                    &[
+                        ("delegate", &d.destination),
+                        ("amount", &amount()),
+                        ("source", &d.source),
+                    ],
+                ),
+                "Revoke" => text("describe.token.revoke", &[("account", &d.source)]),
+                "CloseAccount" => text(
+                    "describe.token.close_account",
+                    &[("account", &d.source), ("destination", &d.destination)],
+                ),
+                "FreezeAccount" => text("describe.token.freeze_account", &[("account", &d.source)]),
+                "ThawAccount" => text("describe.token.thaw_account", &[("account", &d.source)]),
+                "InitializeMint" | "InitializeMint2" => text(
+                    "describe.token.initialize_mint",
+                    &[
+                        ("mint", &d.mint),
+                        ("decimals", &d.decimals.unwrap_or_default()),
+                        ("authority", &d.new_authority),
+                    ],
+                ),
+                "InitializeAccount" | "InitializeAccount2" | "InitializeAccount3" => text(
+                    "describe.token.initialize_account",
+                    &[
+                        ("account", &d.destination),
+                        ("mint", &d.mint),
+                        ("owner", &d.new_authority),
+                    ],
+                ),
+                "SetAuthority" if d.new_authority.is_empty() => {
+                    text("describe.token.remove_authority", &[("account", &d.source)])
+                }
+                "SetAuthority" => text(
+                    "describe.token.set_authority",
+                    &[("account", &d.source), ("authority", &d.new_authority)],
+                ),
+                "SyncNative" => text("describe.token.sync_native", &[("account", &d.source)]),
+                _ => None,
+            }
+        }
+        Some(Details::AssociatedToken(d)) if action != "RecoverNested" => text(
+            "describe.associated_token.create",
+            &[
+                ("account", &d.associated_account),
+                ("wallet", &d.wallet),
+                ("mint", &d.mint),
+                ("payer", &d.funding_account),
+            ],
+        ),
+        Some(Details::ComputeBudget(d)) => match action {
+            "SetComputeUnitLimit" | "RequestUnitsDeprecated" => text(
+                "describe.compute_budget.set_compute_unit_limit",
+                &[("units", &d.compute_unit_limit)],
+            ),
+            "SetComputeUnitPrice" => text(
+                "describe.compute_budget.set_compute_unit_price",
+                &[("price", &d.compute_unit_price_micro_lamports)],
+            ),
+            "RequestHeapFrame" => text(
+                "describe.compute_budget.request_heap_frame",
+                &[("bytes", &d.heap_frame_bytes)],
+            ),
+            "SetLoadedAccountsDataSizeLimit" => text(
+                "describe.compute_budget.set_loaded_accounts_data_size_limit",
+                &[("bytes", &d.loaded_accounts_data_size_limit)],
+            ),
+            _ => None,
+        },
+        Some(Details::Memo(d)) => text("describe.memo", &[("text", &d.text)]),
+        _ => None,
+    };
+
+    summary.unwrap_or_else(|| {
+        if action.is_empty() {
+            localizer.text("describe.call", &[("program", &program_name)])
+        } else {
+            localizer.text("describe.action", &[("action", &action), ("program", &program_name)])
+        }
+    })
+}
+
+/// Display name of the program an account is assigned to
+fn owner_name(owner: &str) -> String {
+    Pubkey::from_str(owner).map_or_else(|_| owner.to_string(), |owner| program_name(&owner))
+}
+
+/// Roles decoded details give each account, in the order of the details' fields
+fn account_roles(details: Option<&Details>) -> Vec<(&str, &'static str)> {
+    match details {
+        Some(Details::System(d)) => vec![
+            (d.source.as_str(), "source"),
+            (d.destination.as_str(), "destination"),
+            (d.base.as_str(), "base"),
+            (d.authority.as_str(), "authority"),
+        ],
+        Some(Details::Token(d)) => vec![
+            (d.source.as_str(), "source"),
+            (d.destination.as_str(), "destination"),
+            (d.mint.as_str(), "mint"),
+            (d.authority.as_str(), "authority"),
+        ],
+        Some(Details::AssociatedToken(d)) => vec![
+            (d.funding_account.as_str(), "payer"),
+            (d.associated_account.as_str(), "associated account"),
+            (d.wallet.as_str(), "wallet"),
+            (d.mint.as_str(), "mint"),
+            (d.token_program.as_str(), "token program"),
+        ],
+        Some(Details::Memo(d)) => d.signers.iter().map(|s| (s.as_str(), "signer")).collect(),
+        _ => Vec::new(),
+    }
+}
+
+/// Describes each instruction of a message from its decoded form.
+///
+/// `decoded` must come from decoding `message`'s instructions, in order.
+pub fn describe_instructions(
+    message: &Message,
+    decoded: &[DecodedInstruction],
+    decimals: &HashMap<String, u32>,
+    localizer: &Localizer,
+) -> Vec<DescribedInstruction> {
+    message
+        .instructions
+        .iter()
+        .zip(decoded)
+        .map(|(compiled, instruction)| {
+            let program_id = message
+                .account_keys
+                .get(usize::from(compiled.program_id_index))
+                .copied()
+                .unwrap_or_default();
+            let program_name = program_name(&program_id);
+            let roles = account_roles(instruction.details.as_ref());
+
+            let accounts = compiled
+                .accounts
+                .iter()
+                .filter_map(|index| {
+                    let index = usize::from(*index);
+                    let address = message.account_keys.get(index)?.to_string();
+                    let role = roles
+                        .iter()
+                        .find(|(account, _)| *account == address)
+                        .map(|(_, role)| (*role).to_string())
+                        .unwrap_or_default();
+                    Some(InstructionAccount {
+                        signer: message.is_signer(index),
+                        writable: is_writable_index(message, index),
+                        address,
+                        role,
+                    })
+                })
+                .collect();
+
+            DescribedInstruction {
+                index: instruction.index,
+                program_id: program_id.to_string(),
+                summary: summarize_instruction(instruction, &program_name, decimals, localizer),
+                program_name,
+                action: if instruction.instruction_type.is_empty() {
+                    "Unknown".to_string()
+                } else {
+                    instruction.instruction_type.clone()
+                },
+                accounts,
+            }
+        })
+        .collect()
+}
+
+/// Describes every account of a message, in message order
+pub fn describe_accounts(message: &Message) -> Vec<DescribedAccount> {
+    message
+        .account_keys
+        .iter()
+        .enumerate()
+        .map(|(index, address)| DescribedAccount {
+            address: address.to_string(),
+            signer: message.is_signer(index),
+            writable: is_writable_index(message, index),
+            fee_payer: index == 0 && message.header.num_required_signatures > 0,
+            program: message.is_key_called_as_program(index),
+        })
+        .collect()
+}
+
+/// Writable accounts of a message, whose balances a simulation can change
+pub fn writable_accounts(message: &Message) -> Vec<Pubkey> {
+    message
+        .account_keys
+        .iter()
+        .enumerate()
+        .filter(|(index, _)| is_writable_index(message, *index))
+        .map(|(_, address)| *address)
+        .collect()
+}
+
+/// SOL movements of simulated accounts, leaving out accounts whose balance is unchanged
+pub fn lamport_movements(accounts: &[SimulatedAccount]) -> Vec<LamportMovement> {
+    accounts
+        .iter()
+        .filter(|account| account.lamports_delta != 0)
+        .map(|account| LamportMovement {
+            address: account.address.clone(),
+            pre_lamports: account.pre_lamports,
+            post_lamports: account.lamports,
+            delta: account.lamports_delta,
+        })
+        .collect()
+}
+
+/// Parses a token account owned by either token program
+fn token_account(account: &Account) -> Option<TokenAccount> {
+    if account.owner != TOKEN_PROGRAM_ID && account.owner != spl_token_2022::id() {
+        return None;
+    }
+    StateWithExtensions::<TokenAccount>::unpack(&account.data)
+        .ok()
+        .map(|state| state.base)
+}
+
+/// Decimals of a mint account owned by either token program
+pub fn mint_decimals(account: &Account) -> Option<u32> {
+    if account.owner != TOKEN_PROGRAM_ID && account.owner != spl_token_2022::id() {
+        return None;
+    }
+    StateWithExtensions::<Mint>::unpack(&account.data)
+        .ok()
+        .map(|state| u32::from(state.base.decimals))
+}
+
+/// Token balance changes of the token accounts among `addresses`.
+///
+/// `pre` and `post` are in the order of `addresses`, as returned by the RPC node.
+/// Accounts created or closed by the transaction count as holding nothing before or after
+/// it. Decimals are left at zero for the caller to fill in from the mints.
+pub fn token_movements(
+    addresses: &[Pubkey],
+    pre: &[Option<Account>],
+    post: &[Option<UiAccount>],
+) -> Vec<TokenMovement> {
+    addresses
+        .iter()
+        .enumerate()
+        .filter_map(|(index, address)| {
+            let pre = pre
+                .get(index)
+                .and_then(Option::as_ref)
+                .and_then(token_account);
+            let post = post
+                .get(index)
+                .and_then(Option::as_ref)
+                .and_then(UiAccount::decode::<Account>)
+                .as_ref()
+                .and_then(token_account);
+            let state = post.as_ref().or(pre.as_ref())?;
+
+            let pre_amount = pre.as_ref().map_or(0, |account| account.amount);
+            let post_amount = post.as_ref().map_or(0, |account| account.amount);
+            if pre_amount == post_amount {
+                return None;
+            }
+            let delta = i128::from(post_amount) - i128::from(pre_amount);
+            Some(TokenMovement {
+                token_account: address.to_string(),
+                mint: state.mint.to_string(),
+                owner: state.owner.to_string(),
+                decimals: 0,
+                pre_amount,
+                post_amount,
+                delta: i64::try_from(delta).unwrap_or(if delta < 0 { i64::MIN } else { i64::MAX }),
+            })
+        })
+        .collect()
+}
+
+/// Mints whose decimals a description needs: those of token movements and of token
+/// instructions that do not carry their own decimals
+pub fn referenced_mints(
+    decoded: &[DecodedInstruction],
+    movements: &[TokenMovement],
+) -> Vec<Pubkey> {
+    let mut mints: Vec<Pubkey> = decoded
+        .iter()
+        .filter_map(|instruction| match &instruction.details {
+            Some(Details::Token(d)) if d.decimals.is_none() => Some(d.mint.as_str()),
+            _ => None,
+        })
+        .chain(movements.iter().map(|movement| movement.mint.as_str()))
+        .filter_map(|mint| Pubkey::from_str(mint).ok())
+        .collect();
+    mints.sort_unstable();
+    mints.dedup();
+    mints
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::instruction_decoding::decode_compiled_instructions;
+    use crate::service_providers::localization::MessageCatalog;
+    use solana_account_decoder::UiAccountEncoding;
+    use solana_sdk::{program_pack::Pack, system_instruction};
+    use spl_token_2022::state::AccountState;
+
+    fn token_account_data(mint: &Pubkey, owner: &Pubkey, amount: u64) -> Account {
+        let mut data = vec![0u8; TokenAccount::LEN];
+        TokenAccount::pack(
+            TokenAccount {
+                mint: *mint,
+                owner: *owner,
+                amount,
+                state: AccountState::Initialized,
+                ..Default::default()
+            },
+            &mut data,
+        )
+        .unwrap();
+        Account {
+            lamports: 2_039_280,
+            data,
+            owner: spl_token_2022::id(),
+            executable: false,
+            rent_epoch: 0,
+        }
+    }
+
+    #[test]
+    fn test_format_amount_trims_trailing_zeros() {
+        assert_eq!(format_amount(1_500, 3), "1.5");
+        assert_eq!(format_amount(1_000_000_000, 9), "1");
+        assert_eq!(format_amount(1, 9), "0.000000001");
+        assert_eq!(format_amount(42, 0), "42");
+    }
+
+    #[test]
+    fn test_describe_system_transfer() {
+        let catalog = MessageCatalog::builtin();
+        let english = catalog.localizer_for("en");
+        let payer = Pubkey::new_unique();
+        let recipient = Pubkey::new_unique();
+        let message = Message::new(
+            &[system_instruction::transfer(
+                &payer,
+                &recipient,
+                500_000_000,
+            )],
+            Some(&payer),
+        );
+        let decoded = decode_compiled_instructions(&message.account_keys, &message.instructions);
+
+        let described = describe_instructions(&message, &decoded, &HashMap::new(), &english);
+        assert_eq!(described.len(), 1);
+        assert_eq!(described[0].program_name, "System Program");
+        assert_eq!(described[0].action, "Transfer");
+        assert_eq!(described[0].summary, format!("Transfer 0.5 SOL from {payer} to {recipient}"));
+        assert_eq!(described[0].accounts[0].role, "source");
+        assert!(described[0].accounts[0].signer && described[0].accounts[0].writable);
+        assert_eq!(described[0].accounts[1].role, "destination");
+        assert!(!described[0].accounts[1].signer);
+
+        let accounts = describe_accounts(&message);
+        assert!(accounts[0].fee_payer);
+        assert!(accounts[2].program && !accounts[2].writable);
+        assert_eq!(writable_accounts(&message), vec![payer, recipient]);
+    }
+
+    #[test]
+    fn test_token_amounts_use_known_mint_decimals() {
+        let catalog = MessageCatalog::builtin();
+        let english = catalog.localizer_for("en");
+        let source = Pubkey::new_unique();
+        let mint = Pubkey::new_unique();
+        let owner = Pubkey::new_unique();
+        let burn = spl_token_2022::instruction::burn(
+            &spl_token_2022::id(),
+            &source,
+            &mint,
+            &owner,
+            &[],
+            2_500,
+        )
+        .unwrap();
+        let message = Message::new(&[burn], Some(&owner));
+        let decoded = decode_compiled_instructions(&message.account_keys, &message.instructions);
+        assert_eq!(referenced_mints(&decoded, &[]), vec![mint]);
+
+        let unknown = describe_instructions(&message, &decoded, &HashMap::new(), &english);
+        assert_eq!(
+            unknown[0].summary,
+            format!("Burn 2500 base units of mint {mint} from {source}")
+        );
+        let known = describe_instructions(
+            &message,
+            &decoded,
+            &HashMap::from([(mint.to_string(), 3)]),
+            &english,
+        );
+        assert_eq!(known[0].summary, format!("Burn 2.5 of mint {mint} from {source}"));
+    }
+
+    #[test]
+    fn test_unknown_programs_fall_back_to_program_id() {
+        let catalog = MessageCatalog::builtin();
+        let english = catalog.localizer_for("en");
+        let program = Pubkey::new_unique();
+        let payer = Pubkey::new_unique();
+        let message = Message::new(
+            &[solana_sdk::instruction::Instruction::new_with_bytes(
+                program,
+                &[1, 2, 3],
+                vec![],
+            )],
+            Some(&payer),
+        );
+        let decoded = decode_compiled_instructions(&message.account_keys, &message.instructions);
+
+        let described = describe_instructions(&message, &decoded, &HashMap::new(), &english);
+        assert_eq!(described[0].action, "Unknown");
+        assert_eq!(described[0].summary, format!("Call {program}"));
+    }
+
+    #[test]
+    fn test_token_movements_only_report_changed_balances() {
+        let mint = Pubkey::new_unique();
+        let owner = Pubkey::new_unique();
+        let sent = Pubkey::new_unique();
+        let untouched = Pubkey::new_unique();
+        let created = Pubkey::new_unique();
+        let encode = |account: &Account| {
+            Some(UiAccount::encode(
+                &Pubkey::default(),
+                account,
+                UiAccountEncoding::Base64,
+                None,
+                None,
+            ))
+        };
+
+        let movements = token_movements(
+            &[sent, untouched, created],
+            &[
+                Some(token_account_data(&mint, &owner, 100)),
+                Some(token_account_data(&mint, &owner, 7)),
+                None,
+            ],
+            &[
+                encode(&token_account_data(&mint, &owner, 60)),
+                encode(&token_account_data(&mint, &owner, 7)),
+                encode(&token_account_data(&mint, &owner, 40)),
+            ],
+        );
+
+        assert_eq!(movements.len(), 2);
+        assert_eq!(movements[0].token_account, sent.to_string());
+        assert_eq!(movements[0].delta, -40);
+        assert_eq!(movements[1].token_account, created.to_string());
+        assert_eq!((movements[1].pre_amount, movements[1].delta), (0, 40));
+        assert_eq!(movements[1].mint, mint.to_string());
+    }
+}
@@ -0,0 +1,480 @@
+use solana_sdk::{
+    hash::Hash, instruction::Instruction, message::Message, packet::PACKET_DATA_SIZE,
+    pubkey::Pubkey, signature::Signature, transaction::Transaction as SolanaTransaction,
+};
+use std::collections::BTreeSet;
+use std::str::FromStr;
+
+use crate::api::common::solana_conversions::proto_instruction_to_sdk;
+use protochain_api::protochain::solana::transaction::v1::{
+    DiagnosticCode, DiagnosticSeverity, Transaction, TransactionDiagnostic, TransactionState,
+};
+
+/// Maximum serialized size of a transaction, bounded by the network packet size
+pub const MAX_TRANSACTION_SIZE: usize = PACKET_DATA_SIZE;
+/// Maximum number of accounts a single transaction may lock
+pub const MAX_ACCOUNT_LOCKS: usize = 64;
+/// Bytes saved by replacing a 32-byte account key with a 1-byte lookup table index
+const LOOKUP_SAVING_PER_ACCOUNT: usize = 31;
+/// Bytes added by referencing one lookup table (key plus two empty index lists)
+const LOOKUP_TABLE_OVERHEAD: usize = 34;
+
+/// Outcome of validating a transaction offline
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct ValidationReport {
+    /// Bytes of the signed wire transaction (0 if it could not be built)
+    pub serialized_size: usize,
+    /// Signatures the transaction needs
+    pub required_signers: usize,
+    /// Unique accounts referenced by the message
+    pub account_count: usize,
+    /// Accounts listed more than once within a single instruction
+    pub duplicate_accounts: Vec<Pubkey>,
+    /// Findings, errors and warnings alike
+    pub diagnostics: Vec<TransactionDiagnostic>,
+}
+
+impl ValidationReport {
+    /// Whether no finding prevents submission
+    pub fn is_valid(&self) -> bool {
+        !self
+            .diagnostics
+            .iter()
+            .any(|diagnostic| diagnostic.severity() == DiagnosticSeverity::Error)
+    }
+
+    /// Bytes left under the packet limit (negative when over)
+    pub fn remaining_bytes(&self) -> i64 {
+        i64::try_from(MAX_TRANSACTION_SIZE).unwrap_or(i64::MAX)
+            - i64::try_from(self.serialized_size).unwrap_or(i64::MAX)
+    }
+}
+
+/// Builds a diagnostic, where `instruction_index` is `None` for whole-transaction findings
+fn diagnostic(
+    code: DiagnosticCode,
+    severity: DiagnosticSeverity,
+    message: String,
+    suggestion: &str,
+    instruction_index: Option<usize>,
+    account: Option<&Pubkey>,
+) -> TransactionDiagnostic {
+    TransactionDiagnostic {
+        code: code.into(),
+        severity: severity.into(),
+        message,
+        suggestion: suggestion.to_string(),
+        instruction_index: instruction_index
+            .and_then(|index| i32::try_from(index).ok())
+            .unwrap_or(-1),
+        account: account.map(ToString::to_string).unwrap_or_default(),
+    }
+}
+
+/// Validates a transaction in any state without touching the network.
+///
+/// Drafts are compiled locally against a placeholder blockhash, which does not change the
+/// serialized size. A draft without a fee payer is compiled against a placeholder key so
+/// that its size can still be reported.
+pub fn validate_transaction(transaction: &Transaction, fee_payer: &str) -> ValidationReport {
+    let state = transaction.state();
+    let decoded = match state {
+        TransactionState::Draft | TransactionState::Unspecified => {
+            return validate_draft(transaction, fee_payer);
+        }
+        TransactionState::Compiled => {
+            decode_data::<Message>(&transaction.data).map(SolanaTransaction::new_unsigned)
+        }
+        TransactionState::PartiallySigned | TransactionState::FullySigned => {
+            decode_data::<SolanaTransaction>(&transaction.data)
+        }
+    };
+
+    match decoded {
+        Ok(decoded) => diagnose(&decoded, state == TransactionState::FullySigned),
+        Err(message) => ValidationReport {
+            diagnostics: vec![diagnostic(
+                DiagnosticCode::InvalidTransactionData,
+                DiagnosticSeverity::Error,
+                message,
+                "Recompile the transaction from its draft",
+                None,
+                None,
+            )],
+            ..Default::default()
+        },
+    }
+}
+
+/// Decodes base58 bincode transaction data (a `Message` or a signed transaction)
+pub fn decode_data<T: serde::de::DeserializeOwned>(data: &str) -> Result<T, String> {
+    if data.is_empty() {
+        return Err("Transaction has no compiled data".to_string());
+    }
+    let bytes = bs58::decode(data)
+        .into_vec()
+        .map_err(|e| format!("Failed to decode transaction data: {e}"))?;
+    bincode::deserialize(&bytes).map_err(|e| format!("Failed to deserialize transaction: {e}"))
+}
+
+/// Compiles a draft locally and validates the result
+fn validate_draft(transaction: &Transaction, fee_payer: &str) -> ValidationReport {
+    let mut diagnostics = Vec::new();
+
+    if transaction.instructions.is_empty() {
+        diagnostics.push(diagnostic(
+            DiagnosticCode::NoInstructions,
+            DiagnosticSeverity::Error,
+            "Transaction must have at least one instruction".to_string(),
+            "Add instructions before compiling",
+            None,
+            None,
+        ));
+        return ValidationReport {
+            diagnostics,
+            ..Default::default()
+        };
+    }
+
+    let mut instructions = Vec::with_capacity(transaction.instructions.len());
+    for (index, proto_ix) in transaction.instructions.iter().enumerate() {
+        match proto_instruction_to_sdk(proto_ix.clone()) {
+            Ok(instruction) => instructions.push(instruction),
+            Err(e) => diagnostics.push(diagnostic(
+                DiagnosticCode::InvalidInstruction,
+                DiagnosticSeverity::Error,
+                format!("Invalid instruction: {e}"),
+                "Check the program id and account addresses are valid base58 public keys",
+                Some(index),
+                None,
+            )),
+        }
+    }
+    if !diagnostics.is_empty() {
+        return ValidationReport {
+            diagnostics,
+            ..Default::default()
+        };
+    }
+
+    let fee_payer = if fee_payer.is_empty() {
+        &transaction.fee_payer
+    } else {
+        fee_payer
+    };
+    let fee_payer_key = if fee_payer.is_empty() {
+        diagnostics.push(diagnostic(
+            DiagnosticCode::MissingFeePayer,
+            DiagnosticSeverity::Error,
+            "fee_payer is required to compile the transaction".to_string(),
+            "Set fee_payer; the reported size assumes a fee payer not used by any instruction",
+            None,
+            None,
+        ));
+        Pubkey::new_unique()
+    } else {
+        match Pubkey::from_str(fee_payer) {
+            Ok(key) => key,
+            Err(e) => {
+                diagnostics.push(diagnostic(
+                    DiagnosticCode::MissingFeePayer,
+                    DiagnosticSeverity::Error,
+                    format!("Invalid fee_payer: {e}"),
+                    "Set fee_payer to a base58 public key",
+                    None,
+                    None,
+                ));
+                Pubkey::new_unique()
+            }
+        }
+    };
+
+    let mut report = diagnose_instructions(&instructions, &fee_payer_key);
+    diagnostics.append(&mut report.diagnostics);
+    report.diagnostics = diagnostics;
+    report
+}
+
+/// Compiles instructions for `fee_payer` and validates the resulting unsigned transaction
+pub fn diagnose_instructions(instructions: &[Instruction], fee_payer: &Pubkey) -> ValidationReport {
+    let message = Message::new_with_blockhash(instructions, Some(fee_payer), &Hash::default());
+    diagnose(&SolanaTransaction::new_unsigned(message), false)
+}
+
+/// Validates a compiled transaction: size, account locks, duplicates and signatures
+fn diagnose(transaction: &SolanaTransaction, require_signatures: bool) -> ValidationReport {
+    let message = &transaction.message;
+    let mut diagnostics = Vec::new();
+
+    let serialized_size = bincode::serialized_size(transaction)
+        .ok()
+        .and_then(|size| usize::try_from(size).ok())
+        .unwrap_or(usize::MAX);
+    let required_signers = usize::from(message.header.num_required_signatures);
+    let account_count = message.account_keys.len();
+
+    if message.instructions.is_empty() {
+        diagnostics.push(diagnostic(
+            DiagnosticCode::NoInstructions,
+            DiagnosticSeverity::Error,
+            "Transaction must have at least one instruction".to_string(),
+            "Add instructions before compiling",
+            None,
+            None,
+        ));
+    }
+
+    if serialized_size > MAX_TRANSACTION_SIZE {
+        diagnostics.push(diagnostic(
+            DiagnosticCode::TransactionTooLarge,
+            DiagnosticSeverity::Error,
+            format!(
+                "Transaction is {serialized_size} bytes, {} over the {MAX_TRANSACTION_SIZE}-byte \
+                 packet limit",
+                serialized_size - MAX_TRANSACTION_SIZE
+            ),
+            &size_suggestion(message, serialized_size - MAX_TRANSACTION_SIZE),
+            None,
+            None,
+        ));
+    }
+
+    if account_count > MAX_ACCOUNT_LOCKS {
+        diagnostics.push(diagnostic(
+            DiagnosticCode::TooManyAccounts,
+            DiagnosticSeverity::Error,
+            format!(
+                "Transaction references {account_count} accounts, the limit is \
+                 {MAX_ACCOUNT_LOCKS}"
+            ),
+            "Split the instructions across multiple transactions",
+            None,
+            None,
+        ));
+    }
+
+    let mut duplicate_accounts = BTreeSet::new();
+    for (index, instruction) in message.instructions.iter().enumerate() {
+        let mut seen = BTreeSet::new();
+        for account_index in &instruction.accounts {
+            if seen.insert(*account_index) {
+                continue;
+            }
+            let Some(account) = message.account_keys.get(usize::from(*account_index)) else {
+                continue;
+            };
+            if duplicate_accounts.insert(*account) {
+                diagnostics.push(diagnostic(
+                    DiagnosticCode::DuplicateAccount,
+                    DiagnosticSeverity::Warning,
+                    format!("Account {account} is listed more than once in instruction {index}"),
+                    "Check the instruction's accounts; most programs expect distinct accounts",
+                    Some(index),
+                    Some(account),
+                ));
+            }
+        }
+    }
+
+    if require_signatures {
+        let missing: Vec<&Pubkey> = transaction
+            .signatures
+            .iter()
+            .zip(&message.account_keys)
+            .filter(|(signature, _)| **signature == Signature::default())
+            .map(|(_, key)| key)
+            .collect();
+        for key in missing {
+            diagnostics.push(diagnostic(
+                DiagnosticCode::MissingSignatures,
+                DiagnosticSeverity::Error,
+                format!("Missing signature for required signer {key}"),
+                "Sign the transaction with every required signer before submitting",
+                None,
+                Some(key),
+            ));
+        }
+    }
+
+    ValidationReport {
+        serialized_size,
+        required_signers,
+        account_count,
+        duplicate_accounts: duplicate_accounts.into_iter().collect(),
+        diagnostics,
+    }
+}
+
+/// Suggests how to shed `overflow` bytes: a lookup table if it saves enough, else a split
+fn size_suggestion(message: &Message, overflow: usize) -> String {
+    // Signers and invoked programs must stay in the static account keys
+    let lookup_candidates = message
+        .account_keys
+        .iter()
+        .enumerate()
+        .filter(|(index, _)| {
+            !message.is_signer(*index) && !message.is_key_called_as_program(*index)
+        })
+        .count();
+    let potential_saving =
+        (lookup_candidates * LOOKUP_SAVING_PER_ACCOUNT).saturating_sub(LOOKUP_TABLE_OVERHEAD);
+
+    if potential_saving >= overflow {
+        let needed = (overflow + LOOKUP_TABLE_OVERHEAD).div_ceil(LOOKUP_SAVING_PER_ACCOUNT);
+        format!(
+            "Use an address lookup table with a v0 transaction: moving {needed} of the \
+             {lookup_candidates} non-signer accounts saves enough space"
+        )
+    } else {
+        "Split the instructions across multiple transactions; an address lookup table \
+         cannot save enough space"
+            .to_string()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+    use solana_sdk::instruction::AccountMeta;
+    use solana_sdk::signature::{Keypair, Signer};
+    use solana_sdk::system_instruction;
+
+    fn draft(instructions: Vec<Instruction>) -> Transaction {
+        Transaction {
+            instructions: instructions
+                .into_iter()
+                .map(sdk_instruction_to_proto)
+                .collect(),
+            state: TransactionState::Draft.into(),
+            ..Default::default()
+        }
+    }
+
+    fn codes(report: &ValidationReport) -> Vec<DiagnosticCode> {
+        report
+            .diagnostics
+            .iter()
+            .map(TransactionDiagnostic::code)
+            .collect()
+    }
+
+    #[test]
+    fn test_simple_transfer_is_valid() {
+        let payer = Pubkey::new_unique();
+        let transfer = system_instruction::transfer(&payer, &Pubkey::new_unique(), 1);
+
+        let report = validate_transaction(&draft(vec![transfer]), &payer.to_string());
+
+        assert!(report.is_valid());
+        assert_eq!(report.required_signers, 1);
+        assert_eq!(report.account_count, 3);
+        // 1 + 64 signature bytes, 3 header, 1 + 3 * 32 keys, 32 blockhash, 1 + 1 + 1 + 2 + 1 + 12
+        assert_eq!(report.serialized_size, 215);
+        assert_eq!(report.remaining_bytes(), 1232 - 215);
+    }
+
+    #[test]
+    fn test_oversized_transaction_suggests_lookup_table() {
+        let payer = Pubkey::new_unique();
+        let transfers: Vec<Instruction> = (0..30)
+            .map(|_| system_instruction::transfer(&payer, &Pubkey::new_unique(), 1))
+            .collect();
+
+        let report = validate_transaction(&draft(transfers), &payer.to_string());
+
+        assert!(!report.is_valid());
+        assert!(report.remaining_bytes() < 0);
+        let too_large = &report.diagnostics[0];
+        assert_eq!(too_large.code(), DiagnosticCode::TransactionTooLarge);
+        assert!(too_large.suggestion.contains("address lookup table"));
+    }
+
+    #[test]
+    fn test_oversized_data_suggests_split() {
+        let payer = Pubkey::new_unique();
+        let big = Instruction::new_with_bytes(Pubkey::new_unique(), &[0; 1200], vec![]);
+
+        let report = diagnose_instructions(&[big], &payer);
+
+        assert_eq!(codes(&report), vec![DiagnosticCode::TransactionTooLarge]);
+        assert!(report.diagnostics[0].suggestion.starts_with("Split"));
+    }
+
+    #[test]
+    fn test_duplicate_accounts_within_instruction() {
+        let payer = Pubkey::new_unique();
+        let account = Pubkey::new_unique();
+        let instruction = Instruction::new_with_bytes(
+            Pubkey::new_unique(),
+            &[],
+            vec![
+                AccountMeta::new(account, false),
+                AccountMeta::new(account, false),
+            ],
+        );
+
+        let report = diagnose_instructions(&[instruction], &payer);
+
+        assert!(report.is_valid());
+        assert_eq!(report.duplicate_accounts, vec![account]);
+        assert_eq!(report.diagnostics[0].instruction_index, 0);
+    }
+
+    #[test]
+    fn test_draft_without_fee_payer_still_sized() {
+        let transfer =
+            system_instruction::transfer(&Pubkey::new_unique(), &Pubkey::new_unique(), 1);
+
+        let report = validate_transaction(&draft(vec![transfer]), "");
+
+        assert_eq!(codes(&report), vec![DiagnosticCode::MissingFeePayer]);
+        assert_eq!(report.required_signers, 2);
+        assert!(report.serialized_size > 0);
+    }
+
+    #[test]
+    fn test_fully_signed_missing_signature() {
+        let payer = Keypair::new();
+        let other = Pubkey::new_unique();
+        let instruction = Instruction::new_with_bytes(
+            Pubkey::new_unique(),
+            &[],
+            vec![AccountMeta::new(other, true)],
+        );
+        let message = Message::new(&[instruction], Some(&payer.pubkey()));
+        let mut signed = SolanaTransaction::new_unsigned(message);
+        signed.partial_sign(&[&payer], Hash::default());
+
+        let transaction = Transaction {
+            state: TransactionState::FullySigned.into(),
+            data: bs58::encode(bincode::serialize(&signed).unwrap()).into_string(),
+            ..Default::default()
+        };
+        let report = validate_transaction(&transaction, "");
+
+        assert_eq!(codes(&report), vec![DiagnosticCode::MissingSignatures]);
+        assert_eq!(report.diagnostics[0].account, other.to_string());
+    }
+
+    #[test]
+    fn test_invalid_inputs() {
+        let mut bad = draft(vec![]);
+        assert_eq!(codes(&validate_transaction(&bad, "")), vec![DiagnosticCode::NoInstructions]);
+
+        bad.instructions.push(Default::default());
+        let report = validate_transaction(&bad, "");
+        assert_eq!(codes(&report), vec![DiagnosticCode::InvalidInstruction]);
+
+        let compiled = Transaction {
+            state: TransactionState::Compiled.into(),
+            data: "not-base58!".to_string(),
+            ..Default::default()
+        };
+        assert_eq!(
+            codes(&validate_transaction(&compiled, "")),
+            vec![DiagnosticCode::InvalidTransactionData]
+        );
+    }
+}
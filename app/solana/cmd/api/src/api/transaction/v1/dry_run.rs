@@ -0,0 +1,109 @@
+use solana_rpc_client_api::{
+    client_error::Error as ClientError,
+    request::{RpcError, RpcResponseErrorData},
+    response::RpcSimulateTransactionResult,
+};
+use solana_sdk::{
+    hash::hashv,
+    signature::{Signature, SIGNATURE_BYTES},
+    transaction::Transaction as SolanaTransaction,
+};
+
+/// Domain separator so synthetic signatures cannot collide with other message hashes
+const SYNTHETIC_SIGNATURE_DOMAIN: &[u8] = b"protochain-dry-run-signature";
+
+/// JSON-RPC code the node answers `sendTransaction` with when preflight simulation fails
+const PREFLIGHT_FAILURE_CODE: i64 = -32002;
+
+/// Deterministic stand-in for the signature a dry-run transaction would have landed with.
+///
+/// Derived from the message alone, so repeated dry runs of a transaction agree. A real
+/// ed25519 signature cannot be predicted from the message, so it never matches one.
+pub fn synthetic_signature(transaction: &SolanaTransaction) -> Signature {
+    let message = transaction.message_data();
+    let first = hashv(&[SYNTHETIC_SIGNATURE_DOMAIN, &message]);
+    let second = hashv(&[SYNTHETIC_SIGNATURE_DOMAIN, first.as_ref()]);
+
+    let mut bytes = [0u8; SIGNATURE_BYTES];
+    bytes[..32].copy_from_slice(first.as_ref());
+    bytes[32..].copy_from_slice(second.as_ref());
+    Signature::from(bytes)
+}
+
+/// The error `sendTransaction` would have failed with had the simulated transaction been
+/// broadcast with preflight checks, `None` if the simulation succeeded.
+///
+/// Shaping a failed dry run like a refused send lets it be classified exactly as a real
+/// submission would be.
+pub fn preflight_failure(simulation: &RpcSimulateTransactionResult) -> Option<ClientError> {
+    let error = simulation.err.as_ref()?;
+    Some(ClientError::from(RpcError::RpcResponseError {
+        code: PREFLIGHT_FAILURE_CODE,
+        message: format!("Transaction simulation failed: {error}"),
+        data: RpcResponseErrorData::SendTransactionPreflightFailure(simulation.clone()),
+    }))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{
+        hash::Hash,
+        pubkey::Pubkey,
+        signature::{Keypair, Signer},
+        system_instruction,
+        transaction::TransactionError,
+    };
+
+    fn transfer(payer: &Keypair, recent_blockhash: Hash) -> SolanaTransaction {
+        SolanaTransaction::new_signed_with_payer(
+            &[system_instruction::transfer(
+                &payer.pubkey(),
+                &Pubkey::new_unique(),
+                1,
+            )],
+            Some(&payer.pubkey()),
+            &[payer],
+            recent_blockhash,
+        )
+    }
+
+    fn simulation(err: Option<TransactionError>) -> RpcSimulateTransactionResult {
+        serde_json::from_value(serde_json::json!({
+            "err": err,
+            "logs": ["Program 11111111111111111111111111111111 invoke [1]"],
+            "accounts": null,
+            "unitsConsumed": 150,
+            "returnData": null,
+        }))
+        .unwrap()
+    }
+
+    #[test]
+    fn test_synthetic_signature_is_deterministic() {
+        let payer = Keypair::new();
+        let transaction = transfer(&payer, Hash::new_unique());
+
+        let signature = synthetic_signature(&transaction);
+        assert_eq!(signature, synthetic_signature(&transaction));
+        assert_ne!(signature, transaction.signatures[0]);
+        assert_ne!(signature, synthetic_signature(&transfer(&payer, Hash::new_unique())));
+    }
+
+    #[test]
+    fn test_preflight_failure_only_for_failed_simulations() {
+        assert!(preflight_failure(&simulation(None)).is_none());
+
+        let failure =
+            preflight_failure(&simulation(Some(TransactionError::InsufficientFundsForFee)))
+                .unwrap();
+        assert!(matches!(
+            failure.kind(),
+            solana_rpc_client_api::client_error::ErrorKind::RpcError(RpcError::RpcResponseError {
+                data: RpcResponseErrorData::SendTransactionPreflightFailure(_),
+                ..
+            })
+        ));
+    }
+}
@@ -0,0 +1,179 @@
+use num_traits::FromPrimitive;
+use solana_rpc_client_api::{
+    client_error::{Error as ClientError, ErrorKind as ClientErrorKind},
+    request::{RpcError, RpcResponseErrorData},
+};
+use solana_sdk::{
+    instruction::{CompiledInstruction, InstructionError},
+    pubkey::Pubkey,
+    system_instruction::SystemError,
+    system_program,
+    transaction::TransactionError,
+};
+use spl_token_2022::{error::TokenError, ID as TOKEN_2022_PROGRAM_ID};
+
+use crate::api::common::instruction_decoding::{ASSOCIATED_TOKEN_PROGRAM_ID, TOKEN_PROGRAM_ID};
+use crate::api::transaction::v1::compute_metering::meter_instructions;
+use protochain_api::protochain::solana::transaction::v1::InstructionFailure;
+
+/// Highest error code the legacy SPL Token program defines (`NonNativeNotSupported`);
+/// Token 2022 shares codes up to here and adds its own above
+const LEGACY_TOKEN_MAX_ERROR_CODE: u32 = 19;
+
+/// Returns the program each top-level instruction invokes, in order.
+///
+/// Program ids are always static account keys, so address lookup tables never need
+/// resolving here.
+pub fn instruction_program_ids(
+    account_keys: &[Pubkey],
+    instructions: &[CompiledInstruction],
+) -> Vec<Pubkey> {
+    instructions
+        .iter()
+        .map(|instruction| {
+            account_keys
+                .get(usize::from(instruction.program_id_index))
+                .copied()
+                .unwrap_or_default()
+        })
+        .collect()
+}
+
+/// Names a custom error code of a program the backend knows
+fn program_error_name(program_id: &Pubkey, code: u32) -> Option<String> {
+    let is_token_error = *program_id == TOKEN_2022_PROGRAM_ID
+        || (*program_id == TOKEN_PROGRAM_ID && code <= LEGACY_TOKEN_MAX_ERROR_CODE);
+
+    if is_token_error {
+        TokenError::from_u32(code).map(|error| format!("{error:?}"))
+    } else if system_program::check_id(program_id) {
+        SystemError::from_u32(code).map(|error| format!("{error:?}"))
+    } else if *program_id == ASSOCIATED_TOKEN_PROGRAM_ID && code == 0 {
+        Some("InvalidOwner".to_string())
+    } else {
+        None
+    }
+}
+
+/// Attributes a transaction error to the instruction that failed.
+///
+/// `program_ids` lists the program of each top-level instruction (see
+/// `instruction_program_ids`) and `logs` the execution logs, which are split per
+/// instruction to scope them. Returns `None` for errors not raised by an instruction.
+pub fn attribute_failure(
+    error: &TransactionError,
+    program_ids: &[Pubkey],
+    logs: &[String],
+) -> Option<InstructionFailure> {
+    let TransactionError::InstructionError(index, instruction_error) = error else {
+        return None;
+    };
+    let index = usize::from(*index);
+    let usage = meter_instructions(logs).into_iter().nth(index);
+
+    let program_id = program_ids.get(index).map_or_else(
+        || {
+            usage
+                .as_ref()
+                .map(|usage| usage.program_id.clone())
+                .unwrap_or_default()
+        },
+        ToString::to_string,
+    );
+    let custom_error_code = match instruction_error {
+        InstructionError::Custom(code) => Some(*code),
+        _ => None,
+    };
+    let program_error_name = custom_error_code
+        .zip(program_ids.get(index))
+        .and_then(|(code, program_id)| program_error_name(program_id, code))
+        .unwrap_or_default();
+
+    Some(InstructionFailure {
+        instruction_index: u32::try_from(index).unwrap_or(u32::MAX),
+        program_id,
+        error: instruction_error.to_string(),
+        custom_error_code,
+        program_error_name,
+        logs: usage.map(|usage| usage.logs).unwrap_or_default(),
+    })
+}
+
+/// Attributes a failed send to an instruction, using the preflight simulation's error and
+/// logs when the node reports them
+pub fn attribute_client_error(
+    client_error: &ClientError,
+    account_keys: &[Pubkey],
+    instructions: &[CompiledInstruction],
+) -> Option<InstructionFailure> {
+    let (error, logs) = match &client_error.kind {
+        ClientErrorKind::TransactionError(error) => (error, None),
+        ClientErrorKind::RpcError(RpcError::RpcResponseError {
+            data: RpcResponseErrorData::SendTransactionPreflightFailure(simulation),
+            ..
+        }) => (simulation.err.as_ref()?, simulation.logs.as_deref()),
+        _ => return None,
+    };
+    attribute_failure(
+        error,
+        &instruction_program_ids(account_keys, instructions),
+        logs.unwrap_or_default(),
+    )
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn logs(lines: &[&str]) -> Vec<String> {
+        lines.iter().map(ToString::to_string).collect()
+    }
+
+    #[test]
+    fn test_attributes_custom_token_error() {
+        let program_ids = [system_program::id(), TOKEN_2022_PROGRAM_ID];
+        let token = TOKEN_2022_PROGRAM_ID.to_string();
+        let logs = logs(&[
+            "Program 11111111111111111111111111111111 invoke [1]",
+            "Program 11111111111111111111111111111111 success",
+            &format!("Program {token} invoke [1]"),
+            "Program log: Error: insufficient funds",
+            &format!("Program {token} failed: custom program error: 0x1"),
+        ]);
+        let error = TransactionError::InstructionError(1, InstructionError::Custom(1));
+
+        let failure = attribute_failure(&error, &program_ids, &logs).unwrap();
+        assert_eq!(failure.instruction_index, 1);
+        assert_eq!(failure.program_id, token);
+        assert_eq!(failure.custom_error_code, Some(1));
+        assert_eq!(failure.program_error_name, "InsufficientFunds");
+        assert_eq!(failure.error, "custom program error: 0x1");
+        assert_eq!(failure.logs.len(), 3);
+    }
+
+    #[test]
+    fn test_known_program_error_names() {
+        assert_eq!(program_error_name(&system_program::id(), 0).unwrap(), "AccountAlreadyInUse");
+        assert_eq!(program_error_name(&TOKEN_PROGRAM_ID, 1).unwrap(), "InsufficientFunds");
+        assert!(program_error_name(&TOKEN_PROGRAM_ID, LEGACY_TOKEN_MAX_ERROR_CODE + 1).is_none());
+        assert!(program_error_name(&Pubkey::new_unique(), 0).is_none());
+    }
+
+    #[test]
+    fn test_builtin_error_without_logs() {
+        let program_ids = [system_program::id()];
+        let error = TransactionError::InstructionError(0, InstructionError::InsufficientFunds);
+
+        let failure = attribute_failure(&error, &program_ids, &[]).unwrap();
+        assert_eq!(failure.program_id, system_program::id().to_string());
+        assert_eq!(failure.custom_error_code, None);
+        assert!(failure.program_error_name.is_empty());
+        assert!(failure.logs.is_empty());
+    }
+
+    #[test]
+    fn test_ignores_transaction_level_errors() {
+        assert!(attribute_failure(&TransactionError::BlockhashNotFound, &[], &[]).is_none());
+    }
+}
@@ -12,6 +12,12 @@ use solana_sdk::{
     transaction::TransactionError as SdkTransactionError,
 };
 
+use crate::service_providers::localization::Localizer;
+
+/// Prefix of the English message of every structured submission error, ahead of the
+/// client error it describes
+pub const SUBMISSION_FAILED_PREFIX: &str = "Transaction submission failed: ";
+
 /// Builds structured error responses for transaction submission failures
 ///
 /// This module provides the core logic for mapping Solana RPC client errors to
@@ -51,15 +57,32 @@ pub fn build_structured_error(
 
     TransactionError {
         code: error_code.into(),
-        message: format!("Transaction submission failed: {client_error}"),
+        message: format!("{SUBMISSION_FAILED_PREFIX}{client_error}"),
         details,
         retryable,
         certainty: certainty.into(),
         blockhash: transaction_blockhash.to_string(),
         blockhash_expiry_slot: expiry_slot,
+        instruction_failure: None,
     }
 }
 
+/// Renders a structured error's message in the localizer's locale
+///
+/// Only the human-readable message changes; the code, certainty and JSON details stay
+/// machine-readable. The client error text embedded in the message comes from the RPC
+/// node and is kept as is.
+pub fn localize_structured_error(error: &mut TransactionError, localizer: &Localizer) {
+    let Some(detail) = error.message.strip_prefix(SUBMISSION_FAILED_PREFIX) else {
+        return;
+    };
+    let code = TransactionErrorCode::try_from(error.code)
+        .unwrap_or(TransactionErrorCode::Unspecified)
+        .as_str_name();
+    let code = code.strip_prefix("TRANSACTION_ERROR_CODE_").unwrap_or(code);
+    error.message = localizer.transaction_error(code, detail);
+}
+
 /// Classifies client errors into specific error codes with certainty assessment
 ///
 /// This function implements the core error classification logic based on the
@@ -0,0 +1,164 @@
+use solana_sdk::message::Message;
+use solana_sdk::pubkey::Pubkey;
+use solana_sdk::signature::Signature;
+use solana_sdk::transaction::Transaction as SolanaTransaction;
+
+use crate::api::common::instruction_decoding::program_kind;
+use crate::api::transaction::v1::mnemonic::{
+    parse_derivation_path, DEFAULT_DERIVATION_PATH, MAX_DERIVATION_PATHS,
+};
+use crate::service_providers::hardware_wallet::{HardwareDevice, HardwareWalletAgent};
+use protochain_api::protochain::solana::transaction::v1::ProgramKind;
+
+/// Programs whose instructions the device shows but which have no decoder here
+const CLEAR_SIGNED_PROGRAMS: [Pubkey; 2] = [
+    solana_sdk::stake::program::ID,
+    solana_sdk::vote::program::ID,
+];
+
+/// Programs invoked by `message` whose instructions the Ledger Solana app cannot display,
+/// so approving them on the device means blind signing
+pub fn blind_signed_programs(message: &Message) -> Vec<Pubkey> {
+    let mut programs: Vec<Pubkey> = message
+        .instructions
+        .iter()
+        .filter_map(|instruction| {
+            message
+                .account_keys
+                .get(usize::from(instruction.program_id_index))
+        })
+        .filter(|program_id| {
+            program_kind(program_id) == ProgramKind::Unspecified
+                && !CLEAR_SIGNED_PROGRAMS.contains(program_id)
+        })
+        .copied()
+        .collect();
+    programs.sort();
+    programs.dedup();
+    programs
+}
+
+/// Applies the blind-signing policy: a transaction the device cannot display is only sent
+/// to it when the server allows blind signing and the device has it enabled
+pub fn check_blind_signing(
+    message: &Message,
+    device: &HardwareDevice,
+    allow_blind_signing: bool,
+) -> Result<(), String> {
+    let programs = blind_signed_programs(message);
+    if programs.is_empty() {
+        return Ok(());
+    }
+    let programs = programs
+        .iter()
+        .map(ToString::to_string)
+        .collect::<Vec<_>>()
+        .join(", ");
+    if !allow_blind_signing {
+        return Err(format!(
+            "Transaction invokes programs the device cannot display ({programs}) and blind \
+             signing is not allowed by this server"
+        ));
+    }
+    if !device.blind_signing_enabled {
+        return Err(format!(
+            "Transaction invokes programs the device cannot display ({programs}); enable \
+             blind signing in the device's Solana app settings"
+        ));
+    }
+    Ok(())
+}
+
+/// Has the device sign `transaction` with the keys at `paths` (the default path if empty)
+/// that are required signers, returning each signature with its signer index.
+///
+/// Signatures are verified against the derived key before being returned, so a device
+/// answering for the wrong key is caught here rather than at submission.
+pub fn device_signatures(
+    agent: &HardwareWalletAgent,
+    device_id: &str,
+    paths: &[String],
+    transaction: &SolanaTransaction,
+) -> Result<Vec<(usize, Signature)>, String> {
+    if paths.len() > MAX_DERIVATION_PATHS {
+        return Err(format!("At most {MAX_DERIVATION_PATHS} derivation paths may be given"));
+    }
+    let paths = if paths.is_empty() {
+        vec![DEFAULT_DERIVATION_PATH.to_string()]
+    } else {
+        paths.to_vec()
+    };
+
+    let message_data = transaction.message_data();
+    let mut signatures = Vec::new();
+    for path in &paths {
+        parse_derivation_path(path)?;
+        let pubkey = agent.public_key(device_id, path)?;
+        let Some(index) = transaction
+            .message
+            .account_keys
+            .iter()
+            .take(transaction.signatures.len())
+            .position(|key| key == &pubkey)
+        else {
+            continue;
+        };
+
+        let signature = agent.sign_message(device_id, path, &message_data)?;
+        if !signature.verify(pubkey.as_ref(), &message_data) {
+            return Err(format!("Device returned an invalid signature for {pubkey}"));
+        }
+        signatures.push((index, signature));
+    }
+    Ok(signatures)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::instruction::Instruction;
+    use solana_sdk::system_instruction;
+
+    fn device(blind_signing_enabled: bool) -> HardwareDevice {
+        HardwareDevice {
+            id: "ledger".to_string(),
+            model: "nanoX".to_string(),
+            app_version: "1.4.1".to_string(),
+            blind_signing_enabled,
+        }
+    }
+
+    #[test]
+    fn test_known_programs_are_clear_signed() {
+        let payer = Pubkey::new_unique();
+        let message = Message::new(
+            &[system_instruction::transfer(
+                &payer,
+                &Pubkey::new_unique(),
+                1,
+            )],
+            Some(&payer),
+        );
+        assert!(blind_signed_programs(&message).is_empty());
+        assert!(check_blind_signing(&message, &device(false), false).is_ok());
+    }
+
+    #[test]
+    fn test_unknown_programs_need_blind_signing() {
+        let payer = Pubkey::new_unique();
+        let program = Pubkey::new_unique();
+        let message = Message::new(
+            &[
+                Instruction::new_with_bytes(program, &[1], vec![]),
+                Instruction::new_with_bytes(program, &[2], vec![]),
+            ],
+            Some(&payer),
+        );
+        assert_eq!(blind_signed_programs(&message), vec![program]);
+
+        assert!(check_blind_signing(&message, &device(true), false).is_err());
+        assert!(check_blind_signing(&message, &device(false), true).is_err());
+        assert!(check_blind_signing(&message, &device(true), true).is_ok());
+    }
+}
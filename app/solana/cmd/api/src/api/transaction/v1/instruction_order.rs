@@ -0,0 +1,163 @@
+use solana_sdk::{instruction::Instruction, message::Message};
+
+use protochain_api::protochain::solana::transaction::v1::{InstructionOrigin, InstructionPosition};
+
+/// Instructions the backend added around a draft's instructions during compilation
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct InjectedInstructions {
+    /// Compute budget instructions prepended before the draft's instructions
+    pub compute_budget: usize,
+    /// Whether a memo instruction was appended after them
+    pub memo: bool,
+}
+
+impl InjectedInstructions {
+    /// Where the compiled instruction at `index` of `total` came from, with its position in
+    /// the draft for draft instructions
+    fn origin(self, index: usize, total: usize) -> (InstructionOrigin, Option<usize>) {
+        if index < self.compute_budget {
+            (InstructionOrigin::ComputeBudget, None)
+        } else if self.memo && index + 1 == total {
+            (InstructionOrigin::Memo, None)
+        } else {
+            (InstructionOrigin::Draft, Some(index - self.compute_budget))
+        }
+    }
+}
+
+/// Describes every instruction of a compiled message: where it came from and the indexes
+/// of its program and accounts in the message's account keys
+pub fn instruction_positions(
+    message: &Message,
+    injected: InjectedInstructions,
+) -> Vec<InstructionPosition> {
+    let total = message.instructions.len();
+    message
+        .instructions
+        .iter()
+        .enumerate()
+        .map(|(index, compiled)| {
+            let (origin, draft_index) = injected.origin(index, total);
+            InstructionPosition {
+                index: u32::try_from(index).unwrap_or(u32::MAX),
+                origin: origin.into(),
+                draft_index: draft_index.map(|index| u32::try_from(index).unwrap_or(u32::MAX)),
+                program_id_index: u32::from(compiled.program_id_index),
+                account_indexes: compiled.accounts.iter().copied().map(u32::from).collect(),
+            }
+        })
+        .collect()
+}
+
+/// Checks that the compiled message holds each of `draft` at its draft position, offset
+/// only by the instructions injected before it: same program, same accounts in the same
+/// order and the same data
+pub fn verify_instruction_order(
+    message: &Message,
+    draft: &[Instruction],
+    injected: InjectedInstructions,
+) -> Result<(), String> {
+    let expected_total = injected.compute_budget + draft.len() + usize::from(injected.memo);
+    if message.instructions.len() != expected_total {
+        return Err(format!(
+            "Compiled message has {} instructions, expected {expected_total}",
+            message.instructions.len()
+        ));
+    }
+    for (draft_index, instruction) in draft.iter().enumerate() {
+        let index = injected.compute_budget + draft_index;
+        let compiled = &message.instructions[index];
+        let key = |account_index: u8| message.account_keys.get(usize::from(account_index));
+
+        let same_program = key(compiled.program_id_index) == Some(&instruction.program_id);
+        let same_accounts = compiled.accounts.len() == instruction.accounts.len()
+            && compiled
+                .accounts
+                .iter()
+                .zip(&instruction.accounts)
+                .all(|(account_index, meta)| key(*account_index) == Some(&meta.pubkey));
+        if !same_program || !same_accounts || compiled.data != instruction.data {
+            return Err(format!(
+                "Draft instruction {draft_index} did not compile to position {index}"
+            ));
+        }
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{hash::Hash, pubkey::Pubkey, system_instruction};
+
+    fn transfers(payer: &Pubkey) -> Vec<Instruction> {
+        vec![
+            system_instruction::transfer(payer, &Pubkey::new_unique(), 1),
+            system_instruction::transfer(payer, &Pubkey::new_unique(), 2),
+        ]
+    }
+
+    #[test]
+    fn test_positions_mark_injected_instructions() {
+        let payer = Pubkey::new_unique();
+        let mut instructions = vec![Instruction::new_with_bytes(
+            solana_sdk::compute_budget::id(),
+            &[2, 0, 0, 0, 0],
+            Vec::new(),
+        )];
+        instructions.extend(transfers(&payer));
+        instructions.push(Instruction::new_with_bytes(Pubkey::new_unique(), b"memo", Vec::new()));
+        let message = Message::new_with_blockhash(&instructions, Some(&payer), &Hash::default());
+        let injected = InjectedInstructions {
+            compute_budget: 1,
+            memo: true,
+        };
+
+        let positions = instruction_positions(&message, injected);
+        let origins: Vec<_> = positions.iter().map(InstructionPosition::origin).collect();
+        assert_eq!(
+            origins,
+            vec![
+                InstructionOrigin::ComputeBudget,
+                InstructionOrigin::Draft,
+                InstructionOrigin::Draft,
+                InstructionOrigin::Memo,
+            ]
+        );
+        assert_eq!(positions[1].draft_index, Some(0));
+        assert_eq!(positions[2].draft_index, Some(1));
+        assert_eq!(positions[3].draft_index, None);
+
+        // The payer signs and comes first; the transfer names it, then the recipient
+        assert_eq!(positions[1].account_indexes[0], 0);
+        let recipient = usize::try_from(positions[1].account_indexes[1]).unwrap();
+        assert_eq!(message.account_keys[recipient], instructions[1].accounts[1].pubkey);
+        assert!(verify_instruction_order(&message, &instructions[1..3], injected).is_ok());
+    }
+
+    #[test]
+    fn test_verify_detects_moved_instructions() {
+        let payer = Pubkey::new_unique();
+        let draft = transfers(&payer);
+        let swapped = vec![draft[1].clone(), draft[0].clone()];
+        let message = Message::new_with_blockhash(&swapped, Some(&payer), &Hash::default());
+
+        let error = verify_instruction_order(&message, &draft, InjectedInstructions::default())
+            .unwrap_err();
+        assert!(error.contains("Draft instruction 0"));
+    }
+
+    #[test]
+    fn test_verify_detects_unexpected_instructions() {
+        let payer = Pubkey::new_unique();
+        let draft = transfers(&payer);
+        let message = Message::new_with_blockhash(&draft, Some(&payer), &Hash::default());
+
+        let injected = InjectedInstructions {
+            compute_budget: 0,
+            memo: true,
+        };
+        assert!(verify_instruction_order(&message, &draft, injected).is_err());
+    }
+}
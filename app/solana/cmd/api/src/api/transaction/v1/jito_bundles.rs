@@ -0,0 +1,230 @@
+use solana_sdk::{
+    commitment_config::CommitmentConfig, pubkey::Pubkey, signature::Signature,
+    system_instruction::SystemInstruction, system_program,
+    transaction::Transaction as SolanaTransaction,
+};
+use std::collections::HashSet;
+
+use crate::service_providers::jito::InflightBundleState;
+use protochain_api::protochain::solana::transaction::v1::{
+    BundleState, BundleTransactionStatus, TransactionStatus,
+};
+
+/// Most transactions the block engine accepts in one bundle
+pub const MAX_BUNDLE_TRANSACTIONS: usize = 5;
+
+/// Checks that a bundle holds 1-5 distinct transactions that every signer has signed
+pub fn validate_bundle(transactions: &[SolanaTransaction]) -> Result<(), String> {
+    if transactions.is_empty() || transactions.len() > MAX_BUNDLE_TRANSACTIONS {
+        return Err(format!(
+            "A bundle holds 1-{MAX_BUNDLE_TRANSACTIONS} transactions, got {}",
+            transactions.len()
+        ));
+    }
+
+    let mut seen = HashSet::new();
+    for (index, transaction) in transactions.iter().enumerate() {
+        let Some(signature) = transaction
+            .signatures
+            .first()
+            .filter(|_| transaction.is_signed())
+        else {
+            return Err(format!("Transaction {index} is not fully signed"));
+        };
+        if !seen.insert(*signature) {
+            return Err(format!("Transaction {index} appears more than once"));
+        }
+    }
+    Ok(())
+}
+
+/// Total lamports the bundle transfers to any of `tip_accounts` with System program
+/// transfers
+pub fn tip_lamports(transactions: &[SolanaTransaction], tip_accounts: &[Pubkey]) -> u64 {
+    transactions
+        .iter()
+        .flat_map(|transaction| {
+            let keys = &transaction.message.account_keys;
+            transaction
+                .message
+                .instructions
+                .iter()
+                .filter_map(move |instruction| {
+                    let program_id = keys.get(usize::from(instruction.program_id_index))?;
+                    if !system_program::check_id(program_id) {
+                        return None;
+                    }
+                    let SystemInstruction::Transfer { lamports } =
+                        bincode::deserialize(&instruction.data).ok()?
+                    else {
+                        return None;
+                    };
+                    let recipient = keys.get(usize::from(*instruction.accounts.get(1)?))?;
+                    tip_accounts.contains(recipient).then_some(lamports)
+                })
+        })
+        .fold(0, u64::saturating_add)
+}
+
+/// Maps a block engine state onto the API's
+pub const fn bundle_state(state: InflightBundleState) -> BundleState {
+    match state {
+        InflightBundleState::Invalid => BundleState::Invalid,
+        InflightBundleState::Pending => BundleState::Pending,
+        InflightBundleState::Failed => BundleState::Failed,
+        InflightBundleState::Landed => BundleState::Landed,
+    }
+}
+
+/// Builds the status of one bundled transaction from its signature status, if the node
+/// has seen it.
+///
+/// A bundle is atomic, so once it has failed none of its transactions can land.
+pub fn transaction_status(
+    signature: &Signature,
+    status: Option<&solana_transaction_status::TransactionStatus>,
+    state: BundleState,
+) -> BundleTransactionStatus {
+    let mut result = BundleTransactionStatus {
+        signature: signature.to_string(),
+        ..Default::default()
+    };
+    if let Some(status) = status {
+        result.slot = status.slot;
+        result.error_message = status
+            .err
+            .as_ref()
+            .map(ToString::to_string)
+            .unwrap_or_default();
+        result.status = if status.err.is_some() {
+            TransactionStatus::Failed
+        } else if status.satisfies_commitment(CommitmentConfig::finalized()) {
+            TransactionStatus::Finalized
+        } else if status.satisfies_commitment(CommitmentConfig::confirmed()) {
+            TransactionStatus::Confirmed
+        } else {
+            TransactionStatus::Processed
+        }
+        .into();
+    } else if state == BundleState::Failed {
+        result.status = TransactionStatus::Dropped.into();
+    }
+    result
+}
+
+/// Whether a bundle needs no more monitoring: it failed or is unknown, or it landed with
+/// every transaction at `commitment`
+pub fn is_settled(
+    state: BundleState,
+    statuses: &[Option<solana_transaction_status::TransactionStatus>],
+    commitment: CommitmentConfig,
+) -> bool {
+    match state {
+        BundleState::Failed | BundleState::Invalid => true,
+        BundleState::Landed => {
+            !statuses.is_empty()
+                && statuses.iter().all(|status| {
+                    status
+                        .as_ref()
+                        .is_some_and(|status| status.satisfies_commitment(commitment))
+                })
+        }
+        BundleState::Pending | BundleState::Timeout | BundleState::Unspecified => false,
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{
+        hash::Hash,
+        signature::{Keypair, Signer},
+        system_instruction,
+    };
+    use solana_transaction_status::TransactionConfirmationStatus;
+
+    fn transfer(payer: &Keypair, to: &Pubkey, lamports: u64) -> SolanaTransaction {
+        SolanaTransaction::new_signed_with_payer(
+            &[system_instruction::transfer(&payer.pubkey(), to, lamports)],
+            Some(&payer.pubkey()),
+            &[payer],
+            Hash::new_unique(),
+        )
+    }
+
+    fn status(
+        confirmation: TransactionConfirmationStatus,
+    ) -> solana_transaction_status::TransactionStatus {
+        solana_transaction_status::TransactionStatus {
+            slot: 42,
+            confirmations: None,
+            status: Ok(()),
+            err: None,
+            confirmation_status: Some(confirmation),
+        }
+    }
+
+    #[test]
+    fn test_validate_bundle() {
+        let payer = Keypair::new();
+        let transaction = transfer(&payer, &Pubkey::new_unique(), 1);
+
+        assert!(validate_bundle(&[transaction.clone()]).is_ok());
+        assert!(validate_bundle(&[]).is_err());
+        assert!(validate_bundle(&vec![transaction.clone(); 2]).is_err());
+        assert!(validate_bundle(
+            &(0..=MAX_BUNDLE_TRANSACTIONS)
+                .map(|_| transfer(&payer, &Pubkey::new_unique(), 1))
+                .collect::<Vec<_>>()
+        )
+        .is_err());
+
+        let unsigned = SolanaTransaction::new_unsigned(transaction.message);
+        assert!(validate_bundle(&[unsigned]).is_err());
+    }
+
+    #[test]
+    fn test_tip_lamports_counts_transfers_to_tip_accounts() {
+        let payer = Keypair::new();
+        let tip_account = Pubkey::new_unique();
+        let bundle = [
+            transfer(&payer, &Pubkey::new_unique(), 5_000_000),
+            transfer(&payer, &tip_account, 10_000),
+        ];
+
+        assert_eq!(tip_lamports(&bundle, &[tip_account]), 10_000);
+        assert_eq!(tip_lamports(&bundle, &[Pubkey::new_unique()]), 0);
+    }
+
+    #[test]
+    fn test_transaction_status_mapping() {
+        let signature = Signature::default();
+        let confirmed = status(TransactionConfirmationStatus::Confirmed);
+
+        let landed = transaction_status(&signature, Some(&confirmed), BundleState::Landed);
+        assert_eq!(landed.status(), TransactionStatus::Confirmed);
+        assert_eq!(landed.slot, 42);
+        assert_eq!(
+            transaction_status(&signature, None, BundleState::Pending).status(),
+            TransactionStatus::Unspecified
+        );
+        assert_eq!(
+            transaction_status(&signature, None, BundleState::Failed).status(),
+            TransactionStatus::Dropped
+        );
+    }
+
+    #[test]
+    fn test_settles_once_landed_at_commitment() {
+        let processed = Some(status(TransactionConfirmationStatus::Processed));
+        let confirmed = Some(status(TransactionConfirmationStatus::Confirmed));
+        let commitment = CommitmentConfig::confirmed();
+
+        assert!(!is_settled(BundleState::Pending, &[None], commitment));
+        assert!(!is_settled(BundleState::Landed, &[confirmed.clone(), processed], commitment));
+        assert!(!is_settled(BundleState::Landed, &[], commitment));
+        assert!(is_settled(BundleState::Landed, &[confirmed], commitment));
+        assert!(is_settled(BundleState::Failed, &[None], commitment));
+    }
+}
@@ -0,0 +1,47 @@
+use solana_sdk::message::Message;
+use solana_sdk::pubkey::Pubkey;
+
+/// Programs invoked by the top-level instructions of `message`, which KMS key policies
+/// restrict
+pub fn invoked_programs(message: &Message) -> Vec<Pubkey> {
+    let mut programs: Vec<Pubkey> = message
+        .instructions
+        .iter()
+        .filter_map(|instruction| {
+            message
+                .account_keys
+                .get(usize::from(instruction.program_id_index))
+        })
+        .copied()
+        .collect();
+    programs.sort();
+    programs.dedup();
+    programs
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::instruction::Instruction;
+    use solana_sdk::system_instruction;
+
+    #[test]
+    fn test_invoked_programs() {
+        let payer = Pubkey::new_unique();
+        let recipient = Pubkey::new_unique();
+        let program = Pubkey::new_unique();
+        let message = Message::new(
+            &[
+                system_instruction::transfer(&payer, &recipient, 1),
+                Instruction::new_with_bytes(program, &[1], vec![]),
+                system_instruction::transfer(&payer, &recipient, 2),
+            ],
+            Some(&payer),
+        );
+
+        let mut expected = vec![solana_sdk::system_program::ID, program];
+        expected.sort();
+        assert_eq!(invoked_programs(&message), expected);
+    }
+}
@@ -0,0 +1,63 @@
+use solana_sdk::{
+    instruction::{AccountMeta, Instruction},
+    pubkey::Pubkey,
+};
+
+use crate::api::common::instruction_decoding::MEMO_PROGRAM_ID;
+
+/// Longest memo accepted on compile; longer memos leave too little of the 1232 byte
+/// packet for the payment they tag
+pub const MAX_MEMO_BYTES: usize = 566;
+
+/// Builds an SPL Memo instruction carrying `memo`.
+///
+/// No signer accounts are attached, so the memo is logged without adding signatures to
+/// the transaction.
+pub fn memo_instruction(memo: &str) -> Result<Instruction, String> {
+    signed_memo_instruction(memo, &[])
+}
+
+/// Builds an SPL Memo instruction carrying `memo` that `signers` must sign.
+///
+/// The Memo program fails the transaction unless every account passed to it signed, so
+/// required signers prove who attached the memo.
+pub fn signed_memo_instruction(memo: &str, signers: &[Pubkey]) -> Result<Instruction, String> {
+    if memo.len() > MAX_MEMO_BYTES {
+        return Err(format!("Memo is {} bytes; at most {MAX_MEMO_BYTES} are allowed", memo.len()));
+    }
+    Ok(Instruction::new_with_bytes(
+        MEMO_PROGRAM_ID,
+        memo.as_bytes(),
+        signers
+            .iter()
+            .map(|signer| AccountMeta::new_readonly(*signer, true))
+            .collect(),
+    ))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_memo_instruction() {
+        let instruction = memo_instruction("invoice-42").unwrap();
+        assert_eq!(instruction.program_id, MEMO_PROGRAM_ID);
+        assert_eq!(instruction.data, b"invoice-42");
+        assert!(instruction.accounts.is_empty());
+    }
+
+    #[test]
+    fn test_signed_memo_instruction() {
+        let signer = Pubkey::new_unique();
+        let instruction = signed_memo_instruction("deposit 7", &[signer]).unwrap();
+        assert_eq!(instruction.accounts, vec![AccountMeta::new_readonly(signer, true)]);
+    }
+
+    #[test]
+    fn test_rejects_long_memos() {
+        assert!(memo_instruction(&"a".repeat(MAX_MEMO_BYTES)).is_ok());
+        assert!(memo_instruction(&"a".repeat(MAX_MEMO_BYTES + 1)).is_err());
+    }
+}
@@ -0,0 +1,105 @@
+use solana_sdk::derivation_path::DerivationPath;
+use solana_sdk::signature::Keypair;
+use solana_sdk::signer::keypair::{
+    generate_seed_from_seed_phrase_and_passphrase, keypair_from_seed_and_derivation_path,
+};
+
+use crate::service_providers::key_vault::normalize_mnemonic;
+
+/// Path of the first account of a Solana wallet, used when a request names none
+pub const DEFAULT_DERIVATION_PATH: &str = "m/44'/501'/0'/0'";
+/// Most signers a single request may derive
+pub const MAX_DERIVATION_PATHS: usize = 16;
+
+/// Parses a BIP44 path such as `m/44'/501'/0'/0'`.
+///
+/// ed25519 derivation (SLIP-0010) only supports hardened indexes, so every index must
+/// be marked hardened rather than being silently hardened here.
+pub fn parse_derivation_path(path: &str) -> Result<DerivationPath, String> {
+    let indexes = path
+        .strip_prefix("m/")
+        .ok_or_else(|| format!("Derivation path must start with m/: {path}"))?;
+    if indexes
+        .split('/')
+        .any(|index| !index.ends_with('\'') || index.len() < 2)
+    {
+        return Err(format!("Every index of derivation path {path} must be hardened"));
+    }
+    DerivationPath::from_absolute_path_str(path)
+        .map_err(|e| format!("Invalid derivation path {path}: {e}"))
+}
+
+/// Derives the keypairs at `paths` (the default path if empty) from a BIP39 mnemonic and
+/// optional passphrase, the way Solana wallets derive their accounts
+pub fn derive_keypairs(
+    phrase: &str,
+    passphrase: &str,
+    paths: &[String],
+) -> Result<Vec<Keypair>, String> {
+    if paths.len() > MAX_DERIVATION_PATHS {
+        return Err(format!("At most {MAX_DERIVATION_PATHS} derivation paths may be given"));
+    }
+    let derivation_paths = if paths.is_empty() {
+        vec![parse_derivation_path(DEFAULT_DERIVATION_PATH)?]
+    } else {
+        paths
+            .iter()
+            .map(|path| parse_derivation_path(path))
+            .collect::<Result<Vec<_>, _>>()?
+    };
+
+    let seed =
+        generate_seed_from_seed_phrase_and_passphrase(&normalize_mnemonic(phrase)?, passphrase);
+    derivation_paths
+        .into_iter()
+        .map(|path| {
+            keypair_from_seed_and_derivation_path(&seed, Some(path))
+                .map_err(|e| format!("Key derivation failed: {e}"))
+        })
+        .collect()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::signature::Signer;
+
+    const PHRASE: &str = "abandon abandon abandon abandon abandon abandon abandon abandon \
+                          abandon abandon abandon about";
+
+    #[test]
+    fn test_derivation_is_deterministic_per_path() {
+        let paths = vec![
+            "m/44'/501'/0'/0'".to_string(),
+            "m/44'/501'/1'/0'".to_string(),
+        ];
+        let first = derive_keypairs(PHRASE, "", &paths).unwrap();
+        let again = derive_keypairs(&PHRASE.to_uppercase(), "", &paths).unwrap();
+
+        assert_eq!(first[0].pubkey(), again[0].pubkey());
+        assert_ne!(first[0].pubkey(), first[1].pubkey());
+
+        // No paths selects the default path
+        let default = derive_keypairs(PHRASE, "", &[]).unwrap();
+        assert_eq!(default[0].pubkey(), first[0].pubkey());
+    }
+
+    #[test]
+    fn test_passphrase_changes_keys() {
+        let plain = derive_keypairs(PHRASE, "", &[]).unwrap();
+        let protected = derive_keypairs(PHRASE, "secret", &[]).unwrap();
+        assert_ne!(plain[0].pubkey(), protected[0].pubkey());
+    }
+
+    #[test]
+    fn test_invalid_inputs_are_rejected() {
+        assert!(derive_keypairs("abandon about", "", &[]).is_err());
+        assert!(parse_derivation_path("44'/501'/0'").is_err());
+        assert!(parse_derivation_path("m/44'/501'/0/0").is_err());
+        assert!(parse_derivation_path("m/44'/501'/0'/0'").is_ok());
+
+        let too_many = vec![DEFAULT_DERIVATION_PATH.to_string(); MAX_DERIVATION_PATHS + 1];
+        assert!(derive_keypairs(PHRASE, "", &too_many).is_err());
+    }
+}
@@ -3,14 +3,68 @@
 //! This module contains the version 1 implementation of the Transaction API,
 //! including state machine validation, service implementation, and gRPC wrappers.
 
+/// Overload errors and queue-time reporting for admission-controlled submissions
+pub mod admission;
+/// Portable envelopes for signing transactions on air-gapped devices
+pub mod bundle;
+/// Offline comparison of transactions in any state
+pub mod comparison;
+/// Automatic compute budget sizing for compilation
+pub mod compute_budget;
+/// Per-instruction compute metering from simulation logs
+pub mod compute_metering;
+/// Human-readable transaction summaries for approval screens
+pub mod description;
+/// Offline size, account and signer diagnostics for transactions
+pub mod diagnostics;
+/// Validate-and-simulate submissions that never broadcast
+pub mod dry_run;
+/// Attribution of transaction failures to the failing instruction
+pub mod error_attribution;
 /// Structured error building for enhanced transaction submission responses
 pub mod error_builder;
+/// Ledger signing through the companion agent and its blind-signing policy
+pub mod hardware_wallet;
+/// On-wire instruction positions and order checks for compilation
+pub mod instruction_order;
+/// Jito bundle validation, tip detection and landing status
+pub mod jito_bundles;
+/// Programs KMS key policies are applied to
+pub mod kms;
+/// Memo instructions attached at compile time
+pub mod memo;
+/// Signing keys derived from BIP39 mnemonics along BIP44 paths
+pub mod mnemonic;
+/// Priority fee percentile aggregation over recent fee markets
+pub mod priority_fees;
+/// Rate-limited, per-fee-payer ordered dispatch of queued transactions
+pub mod queue;
+/// Post-submission rebroadcasting of signed transactions until confirmation
+pub mod rebroadcast;
+/// Execution records: raw encodings and native and token balance changes
+pub mod records;
 /// Core business logic implementation for transaction operations
 pub mod service_impl;
+/// Required signer derivation and per-signer signing status
+pub mod signers;
+/// Account state, return data and inner instruction enrichment of simulation results
+pub mod simulation;
+/// Order-preserving splitting of instruction lists across transactions
+pub mod splitting;
+/// Sponsor signing and instruction checks for sponsored fee payers
+pub mod sponsored;
+/// One-shot landing, pending and expiry checks for submitted transactions
+pub mod status_check;
+/// Signed transaction submission with retry schedules for managed submissions
+pub mod submission;
 /// gRPC service wrapper for Transaction v1 API
 pub mod transaction_v1_api;
+/// Normalized transfer events parsed from block transactions, and their filters
+pub mod transfer_events;
 /// Transaction state machine validation utilities
 pub mod validation;
+/// Flattening of v0 transactions into legacy transactions
+pub mod version_conversion;
 
 pub use service_impl::TransactionServiceImpl;
 pub use transaction_v1_api::TransactionV1API;
@@ -0,0 +1,180 @@
+use solana_sdk::pubkey::Pubkey;
+use std::collections::BTreeSet;
+use std::str::FromStr;
+
+use protochain_api::protochain::solana::transaction::v1::Transaction;
+
+/// getRecentPrioritizationFees accepts at most this many accounts
+pub const MAX_PRIORITY_FEE_ACCOUNTS: usize = 128;
+
+/// Micro-lamports per lamport, the unit of compute unit prices
+const MICRO_LAMPORTS_PER_LAMPORT: u128 = 1_000_000;
+
+/// Compute unit price percentiles over a set of recent slot samples
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct FeePercentiles {
+    /// 25th percentile
+    pub p25: u64,
+    /// Median
+    pub p50: u64,
+    /// 75th percentile
+    pub p75: u64,
+    /// 90th percentile
+    pub p90: u64,
+}
+
+impl FeePercentiles {
+    /// Computes nearest-rank percentiles; all zero when there are no samples
+    pub fn from_samples(samples: &[u64]) -> Self {
+        let mut sorted = samples.to_vec();
+        sorted.sort_unstable();
+
+        Self {
+            p25: percentile(&sorted, 25),
+            p50: percentile(&sorted, 50),
+            p75: percentile(&sorted, 75),
+            p90: percentile(&sorted, 90),
+        }
+    }
+
+    /// Recommended compute unit price, biased towards landing quickly
+    pub const fn suggested(&self) -> u64 {
+        self.p75
+    }
+}
+
+/// Nearest-rank percentile of an ascending slice
+fn percentile(sorted: &[u64], pct: usize) -> u64 {
+    if sorted.is_empty() {
+        return 0;
+    }
+    let rank = (pct * sorted.len()).div_ceil(100).max(1);
+    sorted[rank - 1]
+}
+
+/// Lamports paid for `compute_units` at `compute_unit_price` micro-lamports per unit, rounded up
+pub fn priority_fee_lamports(compute_unit_price: u64, compute_units: u64) -> u64 {
+    let micro_lamports = u128::from(compute_unit_price) * u128::from(compute_units);
+    u64::try_from(micro_lamports.div_ceil(MICRO_LAMPORTS_PER_LAMPORT)).unwrap_or(u64::MAX)
+}
+
+/// Collects the accounts a transaction would write-lock, plus any extra accounts.
+///
+/// Only write locks contend in local fee markets, so read-only accounts are skipped.
+pub fn write_locked_accounts(
+    transaction: Option<&Transaction>,
+    extra_accounts: &[String],
+) -> Result<Vec<Pubkey>, String> {
+    let mut accounts = BTreeSet::new();
+    let mut add = |address: &str| -> Result<(), String> {
+        let pubkey =
+            Pubkey::from_str(address).map_err(|e| format!("Invalid account {address}: {e}"))?;
+        accounts.insert(pubkey);
+        Ok(())
+    };
+
+    if let Some(transaction) = transaction {
+        if !transaction.fee_payer.is_empty() {
+            add(&transaction.fee_payer)?;
+        }
+        for instruction in &transaction.instructions {
+            for meta in instruction.accounts.iter().filter(|meta| meta.is_writable) {
+                add(&meta.pubkey)?;
+            }
+        }
+    }
+    for address in extra_accounts {
+        add(address)?;
+    }
+
+    if accounts.len() > MAX_PRIORITY_FEE_ACCOUNTS {
+        return Err(format!(
+            "At most {MAX_PRIORITY_FEE_ACCOUNTS} accounts can be considered, got {}",
+            accounts.len()
+        ));
+    }
+
+    Ok(accounts.into_iter().collect())
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use protochain_api::protochain::solana::transaction::v1::{
+        SolanaAccountMeta, SolanaInstruction,
+    };
+
+    #[test]
+    fn test_percentiles() {
+        let samples: Vec<u64> = (1..=100).collect();
+        let percentiles = FeePercentiles::from_samples(&samples);
+
+        assert_eq!(percentiles.p25, 25);
+        assert_eq!(percentiles.p50, 50);
+        assert_eq!(percentiles.p75, 75);
+        assert_eq!(percentiles.p90, 90);
+        assert_eq!(percentiles.suggested(), 75);
+    }
+
+    #[test]
+    fn test_percentiles_unsorted_and_small() {
+        let percentiles = FeePercentiles::from_samples(&[300, 0, 100]);
+        assert_eq!(percentiles.p25, 0);
+        assert_eq!(percentiles.p50, 100);
+        assert_eq!(percentiles.p90, 300);
+    }
+
+    #[test]
+    fn test_percentiles_empty() {
+        assert_eq!(FeePercentiles::from_samples(&[]), FeePercentiles::default());
+    }
+
+    #[test]
+    fn test_priority_fee_lamports_rounds_up() {
+        assert_eq!(priority_fee_lamports(1_000, 200_000), 200);
+        assert_eq!(priority_fee_lamports(1, 1), 1);
+        assert_eq!(priority_fee_lamports(0, 200_000), 0);
+    }
+
+    #[test]
+    fn test_write_locked_accounts() {
+        let payer = Pubkey::new_unique();
+        let writable = Pubkey::new_unique();
+        let readonly = Pubkey::new_unique();
+        let extra = Pubkey::new_unique();
+
+        let transaction = Transaction {
+            fee_payer: payer.to_string(),
+            instructions: vec![SolanaInstruction {
+                program_id: Pubkey::new_unique().to_string(),
+                accounts: vec![
+                    SolanaAccountMeta {
+                        pubkey: writable.to_string(),
+                        is_signer: false,
+                        is_writable: true,
+                    },
+                    SolanaAccountMeta {
+                        pubkey: readonly.to_string(),
+                        is_signer: false,
+                        is_writable: false,
+                    },
+                ],
+                ..Default::default()
+            }],
+            ..Default::default()
+        };
+
+        let accounts = write_locked_accounts(Some(&transaction), &[extra.to_string()]).unwrap();
+        assert_eq!(accounts.len(), 3);
+        assert!(accounts.contains(&payer));
+        assert!(accounts.contains(&writable));
+        assert!(accounts.contains(&extra));
+        assert!(!accounts.contains(&readonly));
+    }
+
+    #[test]
+    fn test_write_locked_accounts_rejects_invalid() {
+        assert!(write_locked_accounts(None, &["not-a-key".to_string()]).is_err());
+    }
+}
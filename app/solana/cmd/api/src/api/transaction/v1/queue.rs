@@ -0,0 +1,146 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::transaction::Transaction as SolanaTransaction;
+use std::sync::Arc;
+use tracing::{debug, info, warn};
+
+use crate::api::transaction::v1::service_impl::commitment_level_to_config;
+use crate::api::transaction::v1::submission::{
+    submit_with_retries, RetrySchedule, SendOptions, SubmissionOutcome,
+};
+use crate::service_providers::dead_letters::DeadLetterStore;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter};
+use crate::service_providers::submissions::SubmissionLog;
+use crate::service_providers::transaction_queue::{QueuedJob, TransactionQueue};
+use crate::service_providers::unix_timestamp;
+use protochain_api::protochain::solana::transaction::v1::{
+    QueuedTransactionState, SubmissionRecord,
+};
+
+/// Services a queue dispatcher sends through and records outcomes in
+#[derive(Clone)]
+pub struct QueueDispatch {
+    /// Client transactions are sent through
+    pub rpc_client: Arc<RpcClient>,
+    /// Limits on outbound RPC calls, shared with direct submissions
+    pub rpc_limiter: Arc<RpcLimiter>,
+    /// The queue being drained
+    pub queue: Arc<TransactionQueue>,
+    /// Where transactions that fail after their retries are kept
+    pub dead_letters: Arc<DeadLetterStore>,
+    /// Record of submissions for tag searches
+    pub submissions: Arc<SubmissionLog>,
+}
+
+/// Decodes the signed transaction of a queued job
+fn decode_job(job: &QueuedJob) -> Result<SolanaTransaction, String> {
+    let data = bs58::decode(&job.transaction.data)
+        .into_vec()
+        .map_err(|e| format!("Failed to decode transaction data: {e}"))?;
+    bincode::deserialize(&data).map_err(|e| format!("Failed to deserialize transaction: {e}"))
+}
+
+/// Sends a queued job under its retry schedule (the default managed schedule if it has
+/// none), waiting for an RPC permit as long as it takes
+async fn send_job(
+    dispatch: &QueueDispatch,
+    transaction: &SolanaTransaction,
+    job: &QueuedJob,
+) -> Result<SubmissionOutcome, String> {
+    let schedule = RetrySchedule::from_policy(&job.retry_policy.clone().unwrap_or_default())?;
+    let options = SendOptions::for_commitment(commitment_level_to_config(job.commitment_level))
+        .with_transaction_config(&job.transaction);
+
+    let _permit = loop {
+        match dispatch.rpc_limiter.acquire(RpcCallClass::Submission).await {
+            Ok(permit) => break permit,
+            Err(e) => debug!(error = %e, "Queue dispatch waiting for an RPC permit"),
+        }
+    };
+    Ok(submit_with_retries(&dispatch.rpc_client, transaction, options, schedule).await)
+}
+
+/// Sends the queued transactions of `fee_payer` one at a time, in enqueue order, until
+/// its lane is empty.
+///
+/// Each send waits for the queue-wide rate limit, and the next transaction is only taken
+/// once the previous one was accepted or finally failed, so transactions of one fee payer
+/// reach the node in the order they were enqueued. A failed transaction is dead-lettered
+/// and does not hold up the ones behind it.
+pub async fn dispatch_fee_payer(dispatch: QueueDispatch, fee_payer: String) {
+    debug!(fee_payer = %fee_payer, "📬 Queue dispatcher started");
+
+    while let Some((id, job)) = dispatch.queue.next(&fee_payer) {
+        dispatch.queue.throttle().await;
+
+        let outcome = match decode_job(&job) {
+            Ok(transaction) => send_job(&dispatch, &transaction, &job).await,
+            Err(e) => Err(e),
+        };
+        let outcome = match outcome {
+            Ok(outcome) => outcome,
+            Err(e) => {
+                // Enqueue validated the job, so this only happens if that validation changed
+                warn!(queue_id = %id, error = %e, "Queued transaction could not be sent");
+                dispatch
+                    .queue
+                    .finish(&id, QueuedTransactionState::Failed, |_| {});
+                continue;
+            }
+        };
+
+        let entry = dispatch.queue.get(&id).unwrap_or_default();
+        let succeeded = outcome.succeeded();
+        let dead_letter_id = if succeeded {
+            String::new()
+        } else {
+            dispatch.dead_letters.insert(
+                job.transaction.clone(),
+                job.commitment_level,
+                job.retry_policy.clone(),
+                outcome.attempts.clone(),
+                entry.tags.clone(),
+            )
+        };
+
+        dispatch.submissions.record(SubmissionRecord {
+            signature: entry.signature,
+            tags: entry.tags,
+            fee_payer: fee_payer.clone(),
+            submission_result: outcome.submission_result.into(),
+            commitment_level: job.commitment_level,
+            attempt_count: u32::try_from(outcome.attempts.len()).unwrap_or(u32::MAX),
+            dead_letter_id: dead_letter_id.clone(),
+            submitted_at: unix_timestamp(),
+        });
+
+        if succeeded {
+            info!(
+                queue_id = %id,
+                signature = %outcome.signature,
+                fee_payer = %fee_payer,
+                "📤 Dispatched queued transaction"
+            );
+        } else {
+            warn!(
+                queue_id = %id,
+                dead_letter_id = %dead_letter_id,
+                fee_payer = %fee_payer,
+                attempts = outcome.attempts.len(),
+                "Queued transaction failed after its retries and was dead-lettered"
+            );
+        }
+
+        let state = if succeeded {
+            QueuedTransactionState::Submitted
+        } else {
+            QueuedTransactionState::Failed
+        };
+        dispatch.queue.finish(&id, state, |entry| {
+            entry.attempts = outcome.attempts;
+            entry.structured_error = outcome.structured_error;
+            entry.dead_letter_id = dead_letter_id;
+        });
+    }
+
+    debug!(fee_payer = %fee_payer, "📭 Queue dispatcher drained its lane");
+}
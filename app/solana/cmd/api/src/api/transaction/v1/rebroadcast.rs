@@ -0,0 +1,185 @@
+use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::RpcSendTransactionConfig;
+use solana_sdk::{
+    commitment_config::CommitmentConfig, signature::Signature,
+    transaction::Transaction as SolanaTransaction,
+};
+use solana_transaction_status::UiTransactionEncoding;
+use std::collections::HashMap;
+use std::sync::Arc;
+use std::time::{Duration, Instant};
+use tracing::{debug, info, warn};
+
+use crate::service_providers::operations::OperationStore;
+use crate::service_providers::rebroadcasts::RebroadcastTracker;
+use protochain_api::protochain::solana::transaction::v1::{RebroadcastPolicy, RebroadcastState};
+
+/// operations_v1 kind of the operation tracking a rebroadcast loop
+pub const REBROADCAST_OPERATION_KIND: &str = "transaction.rebroadcast";
+/// Default delay between resends
+pub const DEFAULT_REBROADCAST_INTERVAL_MS: u32 = 2_000;
+/// Shortest delay between resends a request may ask for
+const MIN_REBROADCAST_INTERVAL_MS: u32 = 500;
+/// Longest delay between resends a request may ask for
+const MAX_REBROADCAST_INTERVAL_MS: u32 = 60_000;
+/// Hard stop for a loop whose blockhash expiry cannot be observed (e.g. the RPC is down).
+/// A blockhash is valid for 150 slots, roughly 60-90 seconds.
+const MAX_REBROADCAST_DURATION: Duration = Duration::from_secs(180);
+
+/// How often a signed transaction is resent after submission
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct RebroadcastSchedule {
+    interval: Duration,
+}
+
+impl RebroadcastSchedule {
+    /// Builds a schedule from a request policy, where a zero interval selects the default
+    pub fn from_policy(policy: &RebroadcastPolicy) -> Result<Self, String> {
+        let interval_ms = if policy.interval_ms == 0 {
+            DEFAULT_REBROADCAST_INTERVAL_MS
+        } else {
+            policy.interval_ms
+        };
+
+        if !(MIN_REBROADCAST_INTERVAL_MS..=MAX_REBROADCAST_INTERVAL_MS).contains(&interval_ms) {
+            return Err(format!(
+                "Interval must be between {MIN_REBROADCAST_INTERVAL_MS} and \
+                 {MAX_REBROADCAST_INTERVAL_MS} ms"
+            ));
+        }
+
+        Ok(Self {
+            interval: Duration::from_millis(u64::from(interval_ms)),
+        })
+    }
+
+    /// Delay between resends
+    pub const fn interval(&self) -> Duration {
+        self.interval
+    }
+}
+
+/// Whether `signature` has reached confirmed commitment, successfully or not
+fn is_confirmed(rpc_client: &RpcClient, signature: &Signature) -> bool {
+    match rpc_client.get_signature_statuses(&[*signature]) {
+        Ok(statuses) => statuses
+            .value
+            .first()
+            .and_then(Option::as_ref)
+            .is_some_and(|status| status.satisfies_commitment(CommitmentConfig::confirmed())),
+        Err(e) => {
+            debug!(signature = %signature, error = %e, "Rebroadcast status check failed");
+            false
+        }
+    }
+}
+
+/// Resends a submitted transaction per `schedule` until it is confirmed, its blockhash
+/// expires or its operation is cancelled, recording every resend on `tracker` and as
+/// progress of `operation_id`.
+///
+/// Resends carry the same signed bytes, so validators deduplicate them and the
+/// transaction can land at most once. Preflight is skipped because the original
+/// submission already passed it, and RPC-side retries are disabled since this loop is
+/// the retry mechanism.
+pub async fn rebroadcast_until_confirmed(
+    rpc_client: Arc<RpcClient>,
+    tracker: Arc<RebroadcastTracker>,
+    operations: Arc<OperationStore>,
+    operation_id: String,
+    transaction: SolanaTransaction,
+    schedule: RebroadcastSchedule,
+) {
+    let Some(signature) = transaction.signatures.first().copied() else {
+        return;
+    };
+    let key = signature.to_string();
+    let deadline = Instant::now() + MAX_REBROADCAST_DURATION;
+
+    let state = loop {
+        tokio::time::sleep(schedule.interval).await;
+
+        if operations.is_cancel_requested(&operation_id) {
+            break RebroadcastState::Cancelled;
+        }
+        if is_confirmed(&rpc_client, &signature) {
+            break RebroadcastState::Confirmed;
+        }
+
+        let blockhash_expired = matches!(
+            rpc_client.is_blockhash_valid(
+                &transaction.message.recent_blockhash,
+                CommitmentConfig::processed(),
+            ),
+            Ok(false)
+        );
+        if blockhash_expired || Instant::now() >= deadline {
+            // The transaction may have landed since the last check
+            break if is_confirmed(&rpc_client, &signature) {
+                RebroadcastState::Confirmed
+            } else {
+                RebroadcastState::Expired
+            };
+        }
+
+        let count = tracker.record_resend(&key);
+        operations.progress(&operation_id, count, &format!("Resent {count} times"));
+        match rpc_client.send_transaction_with_config(
+            &transaction,
+            RpcSendTransactionConfig {
+                skip_preflight: true,
+                preflight_commitment: None,
+                encoding: Some(UiTransactionEncoding::Base64),
+                max_retries: Some(0),
+                min_context_slot: None,
+            },
+        ) {
+            Ok(_) => debug!(signature = %key, count, "📣 Rebroadcast transaction"),
+            Err(e) => warn!(signature = %key, count, error = %e, "Rebroadcast send failed"),
+        }
+    };
+
+    tracker.finish(&key, state);
+    let rebroadcasts = tracker.get(&key).map_or(0, |progress| progress.count);
+    match state {
+        RebroadcastState::Cancelled => operations.cancelled(&operation_id),
+        RebroadcastState::Confirmed => operations.complete(
+            &operation_id,
+            "Confirmed",
+            HashMap::from([("rebroadcasts".to_string(), rebroadcasts.to_string())]),
+        ),
+        _ => operations.fail(&operation_id, "Blockhash expired before confirmation"),
+    }
+    info!(
+        signature = %key,
+        operation_id = %operation_id,
+        state = ?state,
+        rebroadcasts,
+        "🏁 Rebroadcast stopped"
+    );
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_policy_default_interval() {
+        let schedule = RebroadcastSchedule::from_policy(&RebroadcastPolicy::default()).unwrap();
+        assert_eq!(
+            schedule.interval(),
+            Duration::from_millis(u64::from(DEFAULT_REBROADCAST_INTERVAL_MS))
+        );
+    }
+
+    #[test]
+    fn test_policy_bounds() {
+        for interval_ms in [100, MAX_REBROADCAST_INTERVAL_MS + 1] {
+            assert!(RebroadcastSchedule::from_policy(&RebroadcastPolicy { interval_ms }).is_err());
+        }
+        for interval_ms in [MIN_REBROADCAST_INTERVAL_MS, MAX_REBROADCAST_INTERVAL_MS] {
+            assert!(RebroadcastSchedule::from_policy(&RebroadcastPolicy { interval_ms }).is_ok());
+        }
+    }
+}
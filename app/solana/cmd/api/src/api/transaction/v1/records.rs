@@ -0,0 +1,177 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use solana_sdk::{pubkey::Pubkey, transaction::VersionedTransaction};
+use solana_transaction_status::{UiTransactionStatusMeta, UiTransactionTokenBalance};
+use std::collections::BTreeMap;
+
+use protochain_api::protochain::solana::transaction::v1::{
+    BalanceChange, TokenBalanceChange, TransactionEncoding,
+};
+
+/// Serializes a transaction in a binary `encoding`, `None` for encodings that are not a
+/// rendering of the wire bytes
+pub fn encode_transaction(
+    transaction: &VersionedTransaction,
+    encoding: TransactionEncoding,
+) -> Option<Result<String, String>> {
+    let encode: fn(Vec<u8>) -> String = match encoding {
+        TransactionEncoding::Base64 => |bytes| STANDARD.encode(bytes),
+        TransactionEncoding::Base58 => |bytes| bs58::encode(bytes).into_string(),
+        TransactionEncoding::JsonParsed | TransactionEncoding::Unspecified => return None,
+    };
+    Some(
+        bincode::serialize(transaction)
+            .map(encode)
+            .map_err(|e| format!("Failed to serialize transaction: {e}")),
+    )
+}
+
+/// Native balance of every account before and after execution.
+///
+/// Balances are indexed by the full account list: static keys, then loaded addresses.
+pub fn balance_changes(
+    account_keys: &[Pubkey],
+    meta: &UiTransactionStatusMeta,
+) -> Vec<BalanceChange> {
+    account_keys
+        .iter()
+        .zip(meta.pre_balances.iter().zip(meta.post_balances.iter()))
+        .map(|(address, (pre_balance, post_balance))| BalanceChange {
+            address: address.to_string(),
+            pre_balance: *pre_balance,
+            post_balance: *post_balance,
+        })
+        .collect()
+}
+
+/// Token balance of every token account the transaction touched, in account order.
+///
+/// The node reports pre and post balances separately and omits an account on the side
+/// where it did not exist, so the two lists are joined on account index.
+pub fn token_balance_changes(
+    account_keys: &[Pubkey],
+    meta: &UiTransactionStatusMeta,
+) -> Vec<TokenBalanceChange> {
+    let pre = Option::<Vec<UiTransactionTokenBalance>>::from(meta.pre_token_balances.clone())
+        .unwrap_or_default();
+    let post = Option::<Vec<UiTransactionTokenBalance>>::from(meta.post_token_balances.clone())
+        .unwrap_or_default();
+
+    let mut changes: BTreeMap<u8, TokenBalanceChange> = BTreeMap::new();
+    for (balance, is_post) in pre
+        .iter()
+        .map(|balance| (balance, false))
+        .chain(post.iter().map(|balance| (balance, true)))
+    {
+        let Some(address) = account_keys.get(usize::from(balance.account_index)) else {
+            continue;
+        };
+        let change = changes
+            .entry(balance.account_index)
+            .or_insert_with(|| TokenBalanceChange {
+                address: address.to_string(),
+                mint: balance.mint.clone(),
+                owner: Option::<String>::from(balance.owner.clone()).unwrap_or_default(),
+                program_id: Option::<String>::from(balance.program_id.clone()).unwrap_or_default(),
+                decimals: u32::from(balance.ui_token_amount.decimals),
+                ..Default::default()
+            });
+        let amount = balance.ui_token_amount.amount.parse().unwrap_or_default();
+        if is_post {
+            change.post_amount = amount;
+        } else {
+            change.pre_amount = amount;
+        }
+    }
+    changes.into_values().collect()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use serde_json::json;
+    use solana_sdk::{
+        hash::Hash,
+        message::Message,
+        signature::{Keypair, Signer},
+    };
+
+    fn meta(
+        pre_token_balances: serde_json::Value,
+        post_token_balances: serde_json::Value,
+    ) -> UiTransactionStatusMeta {
+        serde_json::from_value(json!({
+            "err": null,
+            "status": { "Ok": null },
+            "fee": 5000,
+            "preBalances": [10_000, 0, 1],
+            "postBalances": [4_000, 1_000, 1],
+            "preTokenBalances": pre_token_balances,
+            "postTokenBalances": post_token_balances,
+        }))
+        .unwrap()
+    }
+
+    fn token_balance(account_index: u8, amount: &str) -> serde_json::Value {
+        json!({
+            "accountIndex": account_index,
+            "mint": "So11111111111111111111111111111111111111112",
+            "owner": "11111111111111111111111111111111",
+            "uiTokenAmount": { "amount": amount, "decimals": 6, "uiAmount": null, "uiAmountString": "0" },
+        })
+    }
+
+    #[test]
+    fn test_balance_changes_follow_account_order() {
+        let keys = [
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+        ];
+        let changes = balance_changes(&keys, &meta(json!([]), json!([])));
+
+        assert_eq!(changes.len(), 3);
+        assert_eq!(changes[1].address, keys[1].to_string());
+        assert_eq!((changes[0].pre_balance, changes[0].post_balance), (10_000, 4_000));
+    }
+
+    #[test]
+    fn test_token_balance_changes_join_pre_and_post() {
+        let keys = [
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+            Pubkey::new_unique(),
+        ];
+        let changes = token_balance_changes(
+            &keys,
+            &meta(
+                json!([token_balance(1, "500")]),
+                json!([token_balance(1, "200"), token_balance(2, "300")]),
+            ),
+        );
+
+        assert_eq!(changes.len(), 2);
+        assert_eq!(changes[0].address, keys[1].to_string());
+        assert_eq!((changes[0].pre_amount, changes[0].post_amount), (500, 200));
+        assert_eq!(changes[0].decimals, 6);
+        // Created by the transaction: no pre balance
+        assert_eq!((changes[1].pre_amount, changes[1].post_amount), (0, 300));
+    }
+
+    #[test]
+    fn test_encode_transaction() {
+        let payer = Keypair::new();
+        let transaction = VersionedTransaction::from(solana_sdk::transaction::Transaction::new(
+            &[&payer],
+            Message::new(&[], Some(&payer.pubkey())),
+            Hash::new_unique(),
+        ));
+        let bytes = bincode::serialize(&transaction).unwrap();
+
+        let base64 = encode_transaction(&transaction, TransactionEncoding::Base64).unwrap();
+        assert_eq!(STANDARD.decode(base64.unwrap()).unwrap(), bytes);
+        let base58 = encode_transaction(&transaction, TransactionEncoding::Base58).unwrap();
+        assert_eq!(bs58::decode(base58.unwrap()).into_vec().unwrap(), bytes);
+        assert!(encode_transaction(&transaction, TransactionEncoding::JsonParsed).is_none());
+    }
+}
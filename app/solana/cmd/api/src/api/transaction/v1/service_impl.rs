@@ -1,6 +1,35 @@
-use crate::websocket::WebSocketManager;
-use solana_client::rpc_client::RpcClient;
-use solana_client::rpc_config::RpcTransactionConfig;
+use crate::service_providers::admission::AdmissionController;
+use crate::service_providers::auth::Authenticator;
+use crate::service_providers::dead_letters::DeadLetterStore;
+use crate::service_providers::feature_flags::{FeatureFlag, FeatureFlags};
+use crate::service_providers::hardware_wallet::HardwareWalletAgent;
+use crate::service_providers::idempotency::{IdempotencyCache, Reservation};
+use crate::service_providers::jito::JitoBlockEngine;
+use crate::service_providers::key_vault::KeyVault;
+use crate::service_providers::keystore::Keystore;
+use crate::service_providers::kms::KmsSigner;
+use crate::service_providers::localization::MessageCatalog;
+use crate::service_providers::operations::OperationStore;
+use crate::service_providers::rebroadcasts::RebroadcastTracker;
+use crate::service_providers::rpc_limits::{RpcCallClass, RpcLimiter, RpcPermit};
+use crate::service_providers::simulation_cache::{SimulationCache, SimulationCacheKey};
+use crate::service_providers::solana_clients::RpcRouter;
+use crate::service_providers::sponsorship::{SponsorPool, SponsorshipGrant};
+use crate::service_providers::submission_tokens::{resolve_token_ttl, SubmissionTokenStore};
+use crate::service_providers::submissions::{
+    validate_tags, SubmissionFilter, SubmissionLog, DEFAULT_SEARCH_LIMIT, MAX_SEARCH_LIMIT,
+};
+use crate::service_providers::transaction_queue::{QueuedJob, TransactionQueue};
+use crate::service_providers::unix_timestamp;
+use crate::service_providers::vault::VaultSigner;
+use crate::websocket::{PollingSchedule, WebSocketManager};
+use base64::{engine::general_purpose::STANDARD, Engine};
+use solana_account_decoder::UiAccountEncoding;
+use solana_client::rpc_client::{GetConfirmedSignaturesForAddress2Config, RpcClient};
+use solana_client::rpc_config::{
+    RpcAccountInfoConfig, RpcBlockConfig, RpcSimulateTransactionAccountsConfig,
+    RpcTransactionConfig,
+};
 use solana_rpc_client_api::{
     client_error::{Error as ClientError, ErrorKind as ClientErrorKind},
     request::{RpcError, RpcResponseErrorData},
@@ -8,40 +37,137 @@ use solana_rpc_client_api::{
 use solana_sdk::commitment_config::CommitmentConfig;
 use solana_sdk::transaction::TransactionError;
 use solana_sdk::{
+    address_lookup_table,
     hash::Hash,
     instruction::{Instruction, InstructionError},
-    message::Message,
+    message::{Message, VersionedMessage},
     pubkey::Pubkey,
     signature::{Keypair, Signature, Signer},
-    transaction::Transaction as SolanaTransaction,
+    transaction::{Transaction as SolanaTransaction, VersionedTransaction},
 };
-use solana_transaction_status::{EncodedTransaction, UiTransactionEncoding};
+use solana_transaction_status::{
+    EncodedConfirmedTransactionWithStatusMeta, TransactionDetails, UiConfirmedBlock,
+    UiInnerInstructions, UiLoadedAddresses, UiTransactionEncoding,
+};
+use std::collections::HashMap;
 use std::str::FromStr;
 use std::sync::Arc;
-use std::time::Duration;
-use tokio::sync::mpsc;
+use std::time::{Duration, Instant};
+use tokio::sync::{broadcast, mpsc};
 use tokio::time::timeout;
 use tokio_stream::wrappers::ReceiverStream;
 use tonic::{Request, Response, Status};
 use tracing::{debug, error, info, warn};
 
-use crate::api::common::solana_conversions::proto_instruction_to_sdk;
-use crate::api::transaction::v1::error_builder;
+use crate::api::common::inner_instructions::{call_tree, inner_instructions_to_proto};
+use crate::api::common::instruction_decoding::decode_compiled_instructions;
+use crate::api::common::min_context_slot::{
+    ensure_min_context_slot, min_context_slot, min_context_slot_not_reached, read_error_status,
+};
+use crate::api::common::solana_conversions::{proto_instruction_to_sdk, sdk_instruction_to_proto};
+use crate::api::transaction::v1::admission::{overloaded_status, record_queue_time};
+use crate::api::transaction::v1::bundle::{
+    decode_bundle, encode_bundle, export_bundle, import_bundle,
+};
+use crate::api::transaction::v1::comparison::{compare_transactions, message_instructions};
+use crate::api::transaction::v1::compute_budget::{
+    compute_unit_limit_with_margin, has_compute_budget_instruction, resolve_margin_percent,
+    with_compute_budget, MAX_COMPUTE_UNIT_LIMIT,
+};
+use crate::api::transaction::v1::compute_metering::meter_instructions;
+use crate::api::transaction::v1::description::{
+    describe_accounts, describe_instructions, lamport_movements, mint_decimals, referenced_mints,
+    token_movements, writable_accounts,
+};
+use crate::api::transaction::v1::diagnostics::{
+    decode_data, validate_transaction, MAX_TRANSACTION_SIZE,
+};
+use crate::api::transaction::v1::dry_run::{preflight_failure, synthetic_signature};
+use crate::api::transaction::v1::error_attribution::{
+    attribute_client_error, attribute_failure, instruction_program_ids,
+};
+use crate::api::transaction::v1::error_builder::{
+    build_structured_error, localize_structured_error,
+};
+use crate::api::transaction::v1::hardware_wallet::{check_blind_signing, device_signatures};
+use crate::api::transaction::v1::instruction_order::{
+    instruction_positions, verify_instruction_order, InjectedInstructions,
+};
+use crate::api::transaction::v1::jito_bundles::{
+    bundle_state, is_settled, tip_lamports, transaction_status, validate_bundle,
+};
+use crate::api::transaction::v1::kms::invoked_programs;
+use crate::api::transaction::v1::memo::memo_instruction;
+use crate::api::transaction::v1::mnemonic::{derive_keypairs, parse_derivation_path};
+use crate::api::transaction::v1::priority_fees::{
+    priority_fee_lamports, write_locked_accounts, FeePercentiles,
+};
+use crate::api::transaction::v1::queue::{dispatch_fee_payer, QueueDispatch};
+use crate::api::transaction::v1::rebroadcast::{
+    rebroadcast_until_confirmed, RebroadcastSchedule, REBROADCAST_OPERATION_KIND,
+};
+use crate::api::transaction::v1::records::{
+    balance_changes, encode_transaction, token_balance_changes,
+};
+use crate::api::transaction::v1::signers::{
+    required_signers, signer_index, signers_of, signing_status,
+};
+use crate::api::transaction::v1::simulation::{
+    parse_account_addresses, return_data_to_proto, simulated_accounts, simulation_cache_key,
+    simulation_transaction, SimulationOptions, MAX_SIMULATED_ACCOUNTS,
+};
+use crate::api::transaction::v1::splitting::{split_instructions, SplitOptions};
+use crate::api::transaction::v1::sponsored::{add_sponsor_signature, references_sponsor};
+use crate::api::transaction::v1::status_check::check_transaction_status;
+use crate::api::transaction::v1::submission::{
+    submit_with_retries, RetrySchedule, SendOptions, DEFAULT_NODE_MAX_RETRIES,
+};
+use crate::api::transaction::v1::transfer_events::{
+    transaction_transfers, BlockContext, TransferFilter,
+};
 use crate::api::transaction::v1::validation::{
     validate_operation_allowed_for_state, validate_state_transition,
     validate_transaction_state_consistency,
 };
+use crate::api::transaction::v1::version_conversion::{flatten_v0_message, lookup_table_addresses};
 use protochain_api::protochain::solana::r#type::v1::CommitmentLevel;
 use protochain_api::protochain::solana::transaction::v1::{
-    service_server::Service as TransactionService, sign_transaction_request,
-    CompileTransactionRequest, CompileTransactionResponse, EstimateTransactionRequest,
-    EstimateTransactionResponse, GetTransactionRequest, GetTransactionResponse,
-    MonitorTransactionRequest, MonitorTransactionResponse, SignTransactionRequest,
-    SignTransactionResponse, SimulateTransactionRequest, SimulateTransactionResponse,
-    SubmissionResult, SubmitTransactionRequest, SubmitTransactionResponse, Transaction,
-    TransactionState, TransactionStatus,
+    service_server::Service as TransactionService, sign_transaction_request, AutoComputeBudget,
+    BundleState, CheckTransactionStatusRequest, CheckTransactionStatusResponse,
+    CompareTransactionsRequest, CompareTransactionsResponse, CompileTransactionRequest,
+    CompileTransactionResponse, ConvertTransactionVersionRequest,
+    ConvertTransactionVersionResponse, DescribeTransactionRequest, DescribeTransactionResponse,
+    EnqueueTransactionRequest, EnqueueTransactionResponse, EstimateTransactionRequest,
+    EstimateTransactionResponse, ExportTransactionBundleRequest, ExportTransactionBundleResponse,
+    GetPriorityFeeEstimateRequest, GetPriorityFeeEstimateResponse, GetQueueStatusRequest,
+    GetQueueStatusResponse, GetRequiredSignersRequest, GetRequiredSignersResponse,
+    GetTransactionHistoryRequest, GetTransactionHistoryResponse, GetTransactionRequest,
+    GetTransactionResponse, HardwareWallet, ImportTransactionBundleRequest,
+    ImportTransactionBundleResponse, ListHardwareWalletsRequest, ListHardwareWalletsResponse,
+    MintSubmissionTokenRequest, MintSubmissionTokenResponse, MonitorBundleRequest,
+    MonitorBundleResponse, MonitorTransactionRequest, MonitorTransactionResponse,
+    MonitorTransfersRequest, MonitorTransfersResponse, MonitoringMechanism, RebroadcastState,
+    SearchSubmissionsRequest, SearchSubmissionsResponse, SignTransactionRequest,
+    SignTransactionResponse, SignWithHardwareWallet, SignWithKms, SignWithVault,
+    SimulateTransactionRequest, SimulateTransactionResponse, SimulationCacheInfo,
+    SplitInstructionsRequest, SplitInstructionsResponse, SplitTransaction, SponsorshipQuote,
+    StreamQueueEventsRequest, StreamQueueEventsResponse, SubmissionRecord, SubmissionResult,
+    SubmitBundleRequest, SubmitBundleResponse, SubmitTransactionRequest, SubmitTransactionResponse,
+    Transaction, TransactionBundleFormat, TransactionEncoding, TransactionHistoryEntry,
+    TransactionState, TransactionStatus, TransferEvent, ValidateTransactionRequest,
+    ValidateTransactionResponse,
 };
 
+/// Default page size for `GetTransactionHistory`
+const DEFAULT_HISTORY_PAGE_SIZE: u32 = 20;
+/// Maximum page size for `GetTransactionHistory` (each entry costs one getTransaction call)
+const MAX_HISTORY_PAGE_SIZE: u32 = 100;
+/// Furthest behind the current slot a `MonitorTransfers` stream may start
+const MAX_TRANSFER_START_SLOT_LAG: u64 = 1000;
+/// Most slots one `MonitorTransfers` scan covers, so a stream that fell behind catches up
+/// in bounded steps
+const MAX_TRANSFER_SCAN_SLOTS: u64 = 100;
+
 /// Composable Transaction Service Implementation
 ///
 /// This service implements the full transaction lifecycle for Solana blockchain operations:
@@ -64,745 +190,2817 @@ use protochain_api::protochain::solana::transaction::v1::{
 pub struct TransactionServiceImpl {
     rpc_client: Arc<RpcClient>,
     websocket_manager: Arc<WebSocketManager>,
+    dead_letters: Arc<DeadLetterStore>,
+    key_vault: Arc<KeyVault>,
+    idempotency: Arc<IdempotencyCache>,
+    rebroadcasts: Arc<RebroadcastTracker>,
+    submissions: Arc<SubmissionLog>,
+    sponsorship: Arc<SponsorPool>,
+    auth: Arc<Authenticator>,
+    operations: Arc<OperationStore>,
+    feature_flags: Arc<FeatureFlags>,
+    jito: Arc<JitoBlockEngine>,
+    rpc_limiter: Arc<RpcLimiter>,
+    rpc_router: Arc<RpcRouter>,
+    admission: Arc<AdmissionController>,
+    submission_tokens: Arc<SubmissionTokenStore>,
+    transaction_queue: Arc<TransactionQueue>,
+    hardware_wallet: Arc<HardwareWalletAgent>,
+    kms: Arc<KmsSigner>,
+    vault: Arc<VaultSigner>,
+    keystore: Arc<Keystore>,
+    simulation_cache: Arc<SimulationCache>,
+    message_catalog: Arc<MessageCatalog>,
+    dry_run: bool,
+    require_token: bool,
 }
 
 impl TransactionServiceImpl {
-    /// Creates a new `TransactionServiceImpl` with the provided RPC client and WebSocket manager
-    pub const fn new(rpc_client: Arc<RpcClient>, websocket_manager: Arc<WebSocketManager>) -> Self {
+    /// Creates a new `TransactionServiceImpl` with the provided RPC client, WebSocket manager,
+    /// dead-letter store for failed managed submissions, key vault for stored-key signing,
+    /// idempotency cache for deduplicating retried submissions, rebroadcast tracker,
+    /// submission log for tag searches, sponsored fee payer pool, the credentials callers
+    /// authenticate with, the operation store rebroadcast loops report to, the feature flags
+    /// and block engine bundles go through, the limiter bounding concurrent calls to the RPC
+    /// node, the router choosing the node reads at each commitment go to, the admission
+    /// controller queueing submission bursts, the store of single-use submission tokens, the
+    /// queue of transactions awaiting server-side dispatch, the agent relaying signing to
+    /// Ledger devices, the cloud KMS keys and their access policies, the Vault transit keys,
+    /// the encrypted keystore of generated keys, the cache of recent simulation results, the
+    /// catalog human-readable fields are localized from, whether every submission is a dry run
+    /// and whether every submission must present a token
+    #[allow(clippy::too_many_arguments)]
+    pub const fn new(
+        rpc_client: Arc<RpcClient>,
+        websocket_manager: Arc<WebSocketManager>,
+        dead_letters: Arc<DeadLetterStore>,
+        key_vault: Arc<KeyVault>,
+        idempotency: Arc<IdempotencyCache>,
+        rebroadcasts: Arc<RebroadcastTracker>,
+        submissions: Arc<SubmissionLog>,
+        sponsorship: Arc<SponsorPool>,
+        auth: Arc<Authenticator>,
+        operations: Arc<OperationStore>,
+        feature_flags: Arc<FeatureFlags>,
+        jito: Arc<JitoBlockEngine>,
+        rpc_limiter: Arc<RpcLimiter>,
+        rpc_router: Arc<RpcRouter>,
+        admission: Arc<AdmissionController>,
+        submission_tokens: Arc<SubmissionTokenStore>,
+        transaction_queue: Arc<TransactionQueue>,
+        hardware_wallet: Arc<HardwareWalletAgent>,
+        kms: Arc<KmsSigner>,
+        vault: Arc<VaultSigner>,
+        keystore: Arc<Keystore>,
+        simulation_cache: Arc<SimulationCache>,
+        message_catalog: Arc<MessageCatalog>,
+        dry_run: bool,
+        require_token: bool,
+    ) -> Self {
         Self {
             rpc_client,
             websocket_manager,
+            dead_letters,
+            key_vault,
+            idempotency,
+            rebroadcasts,
+            submissions,
+            sponsorship,
+            auth,
+            operations,
+            feature_flags,
+            jito,
+            rpc_limiter,
+            rpc_router,
+            admission,
+            submission_tokens,
+            transaction_queue,
+            hardware_wallet,
+            kms,
+            vault,
+            keystore,
+            simulation_cache,
+            message_catalog,
+            dry_run,
+            require_token,
         }
     }
-}
 
-/// Classifies Solana RPC client errors into appropriate `SubmissionResult` categories
-///
-/// DEPRECATED: This function provides backward compatibility for the legacy enum classification.
-/// New code should use `error_builder::build_structured_error` for comprehensive error analysis.
-///
-/// This function performs type-safe error analysis using Solana's structured error types
-/// instead of fragile string pattern matching. It provides reliable classification based
-/// on the actual error enums from the Solana codebase.
-///
-/// Type-Safe Classification Strategy:
-/// 1. Direct `TransactionError` variants (most reliable)
-/// 2. RPC preflight failure errors with embedded `TransactionError`
-/// 3. Network/transport errors (Io, Reqwest)
-/// 4. Signing errors from cryptographic operations
-/// 5. Node health issues
-/// 6. Fallback to string analysis for unstructured errors
-///
-/// Reference: Solana Agave codebase at /Users/bernardbussy/Projects/github.com/anza-xyz/agave
-/// - rpc-client-api/src/client_error.rs: Main `ClientError` structure
-/// - rpc-client-types/src/request.rs: RPC error types and response data
-/// - transaction-status/src/lib.rs: `TransactionError` enum variants
-///
-/// This approach provides reliable error classification that won't break with message
-/// format changes and enables precise automated retry logic.
-fn classify_submission_error(error: &ClientError) -> SubmissionResult {
-    match &error.kind {
-        // Direct transaction errors - most reliable classification path
-        ClientErrorKind::TransactionError(transaction_error) => {
-            classify_transaction_error(transaction_error)
+    /// Submits an admitted `SubmitTransaction` request (see `submit_transaction`)
+    async fn submit(
+        &self,
+        req: SubmitTransactionRequest,
+    ) -> Result<Response<SubmitTransactionResponse>, Status> {
+        let mut transaction = req
+            .transaction
+            .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
+
+        // A sponsored transaction arrives signed by everyone but its sponsor
+        let sponsored_message = if transaction.state() == TransactionState::PartiallySigned {
+            self.complete_sponsored_transaction(&mut transaction)?
+        } else {
+            None
+        };
+
+        // Validate current state allows submission
+        let current_state = transaction.state();
+        validate_operation_allowed_for_state(current_state, "submit")
+            .map_err(Status::failed_precondition)?;
+
+        // Validate transaction state consistency
+        validate_transaction_state_consistency(&transaction)
+            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
+
+        // Ensure transaction is fully signed
+        if current_state != TransactionState::FullySigned {
+            return Err(Status::failed_precondition(
+                "Transaction must be fully signed before submission",
+            ));
         }
 
-        // RPC response errors with embedded transaction simulation results
-        // This occurs when send_transaction fails during preflight checks
-        ClientErrorKind::RpcError(RpcError::RpcResponseError {
-            data: RpcResponseErrorData::SendTransactionPreflightFailure(simulation_result),
-            ..
-        }) => simulation_result
-            .err
-            .as_ref()
-            .map_or(SubmissionResult::FailedValidation, classify_transaction_error),
+        // Deserialize the signed transaction data
+        let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
+            Status::invalid_argument(format!("Failed to decode transaction data: {e}"))
+        })?;
 
-        // Node health issues - network problems at the validator level
-        ClientErrorKind::RpcError(RpcError::RpcResponseError {
-            data: RpcResponseErrorData::NodeUnhealthy { .. },
-            ..
-        }) => SubmissionResult::FailedNetworkError,
+        let solana_transaction: SolanaTransaction = bincode::deserialize(&transaction_data)
+            .map_err(|e| {
+                Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
+            })?;
 
-        // Network transport errors - connectivity, timeouts, HTTP issues (INDETERMINATE)
-        ClientErrorKind::Io(_) => SubmissionResult::Indeterminate,
+        validate_tags(&req.tags)
+            .map_err(|e| Status::invalid_argument(format!("Invalid tags: {e}")))?;
 
-        ClientErrorKind::Reqwest(reqwest_error) => {
-            if reqwest_error.is_timeout() {
-                // Timeouts are especially dangerous - transaction might have been sent
-            }
-            // Connection/request failures - also indeterminate
-            SubmissionResult::Indeterminate
+        // Verify transaction is properly signed
+        if solana_transaction
+            .signatures
+            .iter()
+            .any(|sig| *sig == Signature::default())
+        {
+            return Err(Status::failed_precondition("Transaction contains unsigned accounts"));
         }
 
-        // Cryptographic signing errors
-        ClientErrorKind::SigningError(_) => SubmissionResult::FailedInvalidSignature,
+        // A submission token authorizes exactly this message, once
+        let message_hash = solana_transaction.message.hash().to_string();
+        let submission_token = Some(req.submission_token.as_str()).filter(|t| !t.is_empty());
+        if submission_token.is_none() && self.require_token {
+            return Err(Status::permission_denied(
+                "A submission token from MintSubmissionToken is required",
+            ));
+        }
 
-        // JSON serialization/parsing errors - usually validation issues
-        ClientErrorKind::SerdeJson(_) | ClientErrorKind::RpcError(RpcError::ParseError(_)) => {
-            SubmissionResult::FailedValidation
+        // Managed submissions resend on retryable failures and are dead-lettered on final failure
+        let schedule = match req.retry_policy.as_ref() {
+            Some(policy) => RetrySchedule::from_policy(policy)
+                .map_err(|e| Status::invalid_argument(format!("Invalid retry policy: {e}")))?,
+            None => RetrySchedule::single_attempt(),
+        };
+        let rebroadcast_schedule = req
+            .rebroadcast
+            .as_ref()
+            .map(RebroadcastSchedule::from_policy)
+            .transpose()
+            .map_err(|e| Status::invalid_argument(format!("Invalid rebroadcast policy: {e}")))?;
+
+        // Dry runs stop here: nothing is sent, recorded, charged to a sponsor or spent
+        if req.dry_run || self.dry_run {
+            if let Some(token) = submission_token {
+                self.submission_tokens
+                    .check(token, &message_hash)
+                    .map_err(Status::permission_denied)?;
+            }
+            let response = self
+                .dry_run_submission(
+                    &solana_transaction,
+                    commitment_level_to_config(req.commitment_level),
+                    sponsored_message.is_some(),
+                )
+                .await?;
+            return Ok(Response::new(response));
         }
 
-        // Fallback for unstructured errors - use string analysis as last resort
-        ClientErrorKind::RpcError(_) => {
-            // Generic RPC errors are typically indeterminate
-            SubmissionResult::Indeterminate
+        // Calls with an idempotency key replay the recorded result instead of submitting again
+        let fingerprint = solana_transaction
+            .signatures
+            .first()
+            .map(ToString::to_string)
+            .unwrap_or_default();
+        let reservation = if req.idempotency_key.is_empty() {
+            None
+        } else {
+            match self
+                .idempotency
+                .reserve(&req.idempotency_key)
+                .map_err(Status::invalid_argument)?
+            {
+                Reservation::Fresh(guard) => Some(guard),
+                Reservation::Replay(cached) => {
+                    let transaction_mismatch = cached.fingerprint != fingerprint;
+                    info!(
+                        idempotency_key = %req.idempotency_key,
+                        signature = %cached.response.signature,
+                        transaction_mismatch,
+                        "♻️ Replaying recorded submission for idempotency key"
+                    );
+                    return Ok(Response::new(SubmitTransactionResponse {
+                        replayed: true,
+                        first_submitted_at: cached.submitted_at,
+                        transaction_mismatch,
+                        ..cached.response
+                    }));
+                }
+                Reservation::InProgress => {
+                    return Err(Status::aborted(
+                        "A submission with this idempotency key is in progress",
+                    ));
+                }
+            }
+        };
+
+        // Spent before sending, so a token never authorizes a second broadcast (replays of an
+        // idempotent submission above send nothing and need no live token)
+        if let Some(token) = submission_token {
+            self.submission_tokens
+                .redeem(token, &message_hash)
+                .map_err(Status::permission_denied)?;
         }
 
-        ClientErrorKind::Custom(_) => {
-            // Only use string matching for truly unstructured error types
-            classify_by_message(&error.to_string())
+        // Submit the transaction to the Solana network with explicit commitment level
+        info!(
+            fee_payer = %transaction.fee_payer,
+            data_length = transaction.data.len(),
+            "🚀 Submitting transaction to Solana network"
+        );
+
+        // Asynchronously submit transaction without waiting for confirmation
+        //
+        // Design philosophy:
+        // 1. PURE WRAPPER: Maintains the protocol buffer wrapper philosophy - just send
+        //    the transaction without adding business logic like confirmation waiting
+        //
+        // 2. CLIENT CONTROL: Clients decide whether to wait for confirmation using
+        //    the separate MonitorTransaction streaming RPC
+        //
+        // 3. NON-BLOCKING: Returns immediately after network submission, enabling
+        //    parallel operations and custom confirmation strategies
+        //
+        // 4. BACKEND APPROPRIATE: Uses send_transaction_with_config for proper
+        //    configuration without any UI dependencies or confirmation polling
+        let commitment = commitment_level_to_config(req.commitment_level);
+        debug!(
+            commitment_level = ?commitment,
+            fee_payer = %transaction.fee_payer,
+            "Transaction submission configured with commitment level"
+        );
+
+        // Preflight runs at the submission's commitment unless the caller picks another
+        let preflight_commitment = if req.preflight_commitment() == CommitmentLevel::Unspecified {
+            commitment
+        } else {
+            commitment_level_to_config(req.preflight_commitment)
+        };
+        let send_options = SendOptions {
+            skip_preflight: req.skip_preflight,
+            preflight_commitment,
+            max_retries: req.max_retries.unwrap_or(DEFAULT_NODE_MAX_RETRIES),
         }
-    }
-}
+        .with_transaction_config(&transaction);
 
-/// Classifies `TransactionError` variants into `SubmissionResult` categories
-///
-/// This function maps specific Solana transaction errors to actionable response categories
-/// based on the transaction error variants defined in the Solana SDK.
-///
-/// Error Categories:
-/// - `InsufficientFunds`: Account balance or fee issues requiring funding
-/// - `InvalidSignature`: Cryptographic signature problems requiring re-signing  
-/// - `NetworkError`: Network capacity, maintenance, or timeout issues (retryable)
-/// - Validation: Transaction format, account, or instruction issues (not retryable)
-/// - Submitted: Transaction already processed (actually successful)
-///
-/// Reference: Solana transaction error definitions in transaction-status crate
-const fn classify_transaction_error(transaction_error: &TransactionError) -> SubmissionResult {
-    match transaction_error {
-        // Account balance and fee-related errors
-        TransactionError::InsufficientFundsForFee
-        | TransactionError::InsufficientFundsForRent { .. } => {
-            SubmissionResult::FailedInsufficientFunds
+        let permit = self.rpc_permit(RpcCallClass::Submission).await?;
+        let outcome =
+            submit_with_retries(&self.rpc_client, &solana_transaction, send_options, schedule)
+                .await;
+        drop(permit);
+
+        let dead_letter_id = if req.retry_policy.is_some() && !outcome.succeeded() {
+            let id = self.dead_letters.insert(
+                transaction.clone(),
+                req.commitment_level,
+                req.retry_policy,
+                outcome.attempts.clone(),
+                req.tags.clone(),
+            );
+            warn!(
+                dead_letter_id = %id,
+                fee_payer = %transaction.fee_payer,
+                attempts = outcome.attempts.len(),
+                "Managed submission exhausted its retries and was dead-lettered"
+            );
+            id
+        } else {
+            String::new()
+        };
+
+        let succeeded = outcome.succeeded();
+        if let Some(message_hash) = sponsored_message.as_ref().filter(|_| succeeded) {
+            self.sponsorship.redeem(message_hash);
         }
+        let rebroadcast_operation_id = match rebroadcast_schedule {
+            Some(rebroadcast_schedule) if succeeded => {
+                self.start_rebroadcast(&outcome.signature, solana_transaction, rebroadcast_schedule)
+            }
+            _ => None,
+        };
+        let response = SubmitTransactionResponse {
+            signature: outcome.signature,
+            submission_result: outcome.submission_result.into(),
+            error_message: outcome
+                .structured_error
+                .as_ref()
+                .map(|e| e.message.clone())
+                .unwrap_or_default(),
+            structured_error: outcome.structured_error,
+            attempts: outcome.attempts,
+            dead_letter_id,
+            replayed: false,
+            first_submitted_at: 0,
+            transaction_mismatch: false,
+            rebroadcasting: rebroadcast_operation_id.is_some(),
+            sponsored: sponsored_message.is_some(),
+            rebroadcast_operation_id: rebroadcast_operation_id.unwrap_or_default(),
+            dry_run: false,
+            simulation_logs: Vec::new(),
+            simulation_units_consumed: 0,
+        };
 
-        // Signature and authorization errors
-        TransactionError::SignatureFailure | TransactionError::MissingSignatureForFee => {
-            SubmissionResult::FailedInvalidSignature
+        // Failed sends report no signature, so records are keyed by the transaction's own
+        self.submissions.record(SubmissionRecord {
+            signature: fingerprint.clone(),
+            tags: req.tags,
+            fee_payer: transaction.fee_payer.clone(),
+            submission_result: response.submission_result,
+            commitment_level: req.commitment_level,
+            attempt_count: u32::try_from(response.attempts.len()).unwrap_or(u32::MAX),
+            dead_letter_id: response.dead_letter_id.clone(),
+            submitted_at: unix_timestamp(),
+        });
+
+        // Failures the same signed transaction may still overcome leave the key free for a retry
+        if let Some(guard) = reservation {
+            let retryable = response
+                .structured_error
+                .as_ref()
+                .is_some_and(|e| e.retryable);
+            if succeeded || !retryable {
+                guard.complete(&response, &fingerprint);
+            } else {
+                guard.release();
+            }
         }
 
-        // Network capacity and node availability issues (potentially retryable)
-        TransactionError::WouldExceedMaxBlockCostLimit
-        | TransactionError::WouldExceedMaxAccountCostLimit
-        | TransactionError::WouldExceedMaxVoteCostLimit
-        | TransactionError::WouldExceedAccountDataBlockLimit
-        | TransactionError::WouldExceedAccountDataTotalLimit
-        | TransactionError::TooManyAccountLocks
-        | TransactionError::ClusterMaintenance => SubmissionResult::FailedNetworkError,
+        Ok(Response::new(response))
+    }
 
-        // Transaction already successfully processed
-        TransactionError::AlreadyProcessed => SubmissionResult::Submitted,
+    /// Waits for a permit to call the RPC node, failing with `RESOURCE_EXHAUSTED` when
+    /// too many calls of `class` are already in flight
+    async fn rpc_permit(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, Status> {
+        self.rpc_limiter
+            .acquire(class)
+            .await
+            .map_err(Status::resource_exhausted)
+    }
 
-        // Account and validation errors (transaction format issues)
-        TransactionError::AccountNotFound
-        | TransactionError::ProgramAccountNotFound
-        | TransactionError::InvalidAccountForFee
-        | TransactionError::AccountInUse
-        | TransactionError::AccountLoadedTwice
-        | TransactionError::AccountBorrowOutstanding
-        | TransactionError::BlockhashNotFound
-        | TransactionError::CallChainTooDeep
-        | TransactionError::InvalidAccountIndex
-        | TransactionError::InvalidProgramForExecution
-        | TransactionError::SanitizeFailure
-        | TransactionError::UnsupportedVersion
-        | TransactionError::InvalidWritableAccount
-        | TransactionError::AddressLookupTableNotFound
-        | TransactionError::InvalidAddressLookupTableOwner
-        | TransactionError::InvalidAddressLookupTableData
-        | TransactionError::InvalidAddressLookupTableIndex
-        | TransactionError::InvalidRentPayingAccount
-        | TransactionError::DuplicateInstruction(_)
-        | TransactionError::MaxLoadedAccountsDataSizeExceeded
-        | TransactionError::InvalidLoadedAccountsDataSizeLimit
-        | TransactionError::ResanitizationNeeded
-        | TransactionError::ProgramExecutionTemporarilyRestricted { .. }
-        | TransactionError::UnbalancedTransaction => SubmissionResult::FailedValidation,
+    /// Fails with `FAILED_PRECONDITION` unless the server's transaction queue is enabled
+    #[allow(clippy::result_large_err)]
+    fn ensure_queue_enabled(&self) -> Result<(), Status> {
+        if self.transaction_queue.is_enabled() {
+            Ok(())
+        } else {
+            Err(Status::failed_precondition("The transaction queue is not enabled"))
+        }
+    }
 
-        // Instruction-level errors require detailed analysis
-        TransactionError::InstructionError(instruction_index, instruction_error) => {
-            classify_instruction_error(*instruction_index, instruction_error)
+    /// Fails with `FAILED_PRECONDITION` unless Jito bundles are enabled and a block engine
+    /// is configured
+    #[allow(clippy::result_large_err)]
+    fn ensure_bundles_available(&self) -> Result<(), Status> {
+        self.feature_flags
+            .ensure_enabled(FeatureFlag::JitoBundles)?;
+        if self.jito.is_configured() {
+            Ok(())
+        } else {
+            Err(Status::failed_precondition("No Jito block engine is configured"))
         }
     }
-}
 
-/// Classifies instruction-level errors that occur during program execution
-///
-/// Instruction errors provide detailed information about failures within specific
-/// transaction instructions, enabling precise error handling for program-specific issues.
+    /// Fails with `FAILED_PRECONDITION` unless a hardware wallet agent is configured
+    #[allow(clippy::result_large_err)]
+    fn ensure_hardware_wallet_available(&self) -> Result<(), Status> {
+        if self.hardware_wallet.is_configured() {
+            Ok(())
+        } else {
+            Err(Status::failed_precondition("No hardware wallet agent is configured"))
+        }
+    }
+
+    /// Has a Ledger device sign `transaction` under the blind-signing policy, returning
+    /// each signature with its signer index.
+    ///
+    /// The agent blocks until the user approves on the device, so it runs off the async
+    /// runtime. A device rejection surfaces as `ABORTED`.
+    async fn hardware_wallet_signatures(
+        &self,
+        method: &SignWithHardwareWallet,
+        transaction: &SolanaTransaction,
+    ) -> Result<Vec<(usize, Signature)>, Status> {
+        self.ensure_hardware_wallet_available()?;
+        if method.device_id.is_empty() {
+            return Err(Status::invalid_argument("Device id is required"));
+        }
+        for path in &method.derivation_paths {
+            parse_derivation_path(path).map_err(Status::invalid_argument)?;
+        }
+
+        let agent = Arc::clone(&self.hardware_wallet);
+        let device_id = method.device_id.clone();
+        let device = tokio::task::spawn_blocking(move || agent.device(&device_id))
+            .await
+            .map_err(|e| Status::internal(format!("Hardware wallet task failed: {e}")))?
+            .map_err(Status::unavailable)?;
+        check_blind_signing(
+            &transaction.message,
+            &device,
+            self.hardware_wallet.allow_blind_signing(),
+        )
+        .map_err(Status::failed_precondition)?;
+        let model = device.model.clone();
+
+        let agent = Arc::clone(&self.hardware_wallet);
+        let paths = method.derivation_paths.clone();
+        let unsigned = transaction.clone();
+        let signatures = tokio::task::spawn_blocking(move || {
+            device_signatures(&agent, &device.id, &paths, &unsigned)
+        })
+        .await
+        .map_err(|e| Status::internal(format!("Hardware wallet task failed: {e}")))?
+        .map_err(Status::aborted)?;
+
+        info!(
+            device_id = %method.device_id,
+            model = %model,
+            signatures = signatures.len(),
+            "🔐 Signed with hardware wallet"
+        );
+        Ok(signatures)
+    }
+
+    /// Signs `transaction` with the requested cloud KMS keys that are required signers,
+    /// returning each signature with its signer index.
+    ///
+    /// `caller_id` is the authenticated caller each key's policy is checked against. Every
+    /// key's policy is checked before any of them signs, so a denied key leaves the
+    /// transaction untouched. Policy denials surface as `PERMISSION_DENIED` and KMS failures
+    /// as `UNAVAILABLE`; both are recorded in the audit log along with each signature.
+    async fn kms_signatures(
+        &self,
+        method: &SignWithKms,
+        caller_id: &str,
+        transaction: &SolanaTransaction,
+    ) -> Result<Vec<(usize, Signature)>, Status> {
+        self.feature_flags.ensure_enabled(FeatureFlag::KmsSigning)?;
+        if !self.kms.is_configured() {
+            return Err(Status::failed_precondition("No KMS keys are configured"));
+        }
+        if method.key_ids.is_empty() {
+            return Err(Status::invalid_argument("At least one KMS key id is required"));
+        }
+
+        let message_data = transaction.message_data();
+        let programs = invoked_programs(&transaction.message);
+        let mut signers = Vec::new();
+        for key_id in &method.key_ids {
+            let key = self
+                .kms
+                .key(key_id)
+                .ok_or_else(|| Status::not_found(format!("KMS key not found: {key_id}")))?;
+            let Some(index) = signer_index(&transaction.message, &key.public_key) else {
+                continue;
+            };
+            key.authorize(caller_id, &programs, &message_data)
+                .map_err(Status::permission_denied)?;
+            signers.push((index, key));
+        }
+
+        let mut signatures = Vec::with_capacity(signers.len());
+        for (index, key) in signers {
+            let signature = self
+                .kms
+                .sign(key, caller_id, &message_data)
+                .await
+                .map_err(Status::unavailable)?;
+            signatures.push((index, signature));
+        }
+        Ok(signatures)
+    }
+
+    /// Signs `transaction` with the requested Vault transit keys (the configured default key
+    /// if none are named) that are required signers, returning each signature with its
+    /// signer index. Vault errors surface as `UNAVAILABLE`.
+    async fn vault_signatures(
+        &self,
+        method: &SignWithVault,
+        transaction: &SolanaTransaction,
+    ) -> Result<Vec<(usize, Signature)>, Status> {
+        if !self.vault.is_configured() {
+            return Err(Status::failed_precondition("No Vault server is configured"));
+        }
+        let key_names = if method.key_names.is_empty() {
+            if self.vault.default_key().is_empty() {
+                return Err(Status::invalid_argument(
+                    "A Vault key name is required when no default key is configured",
+                ));
+            }
+            vec![self.vault.default_key().to_string()]
+        } else {
+            method.key_names.clone()
+        };
+
+        let message_data = transaction.message_data();
+        let mut signatures = Vec::new();
+        for key_name in &key_names {
+            if key_name.is_empty() || key_name.contains('/') {
+                return Err(Status::invalid_argument(format!(
+                    "Invalid Vault key name: {key_name:?}"
+                )));
+            }
+            let public_key = self
+                .vault
+                .public_key(key_name)
+                .await
+                .map_err(Status::unavailable)?;
+            let Some(index) = signer_index(&transaction.message, &public_key) else {
+                continue;
+            };
+            let signature = self
+                .vault
+                .sign(key_name, &public_key, &message_data)
+                .await
+                .map_err(Status::unavailable)?;
+            info!(
+                key_name = %key_name,
+                public_key = %public_key,
+                "🔐 Signed with Vault transit key"
+            );
+            signatures.push((index, signature));
+        }
+        Ok(signatures)
+    }
+
+    /// Adds the sponsor's signature to a sponsored transaction every other signer has
+    /// signed, returning the hash of its granted message (`None` if it was not sponsored)
+    #[allow(clippy::result_large_err)]
+    fn complete_sponsored_transaction(
+        &self,
+        transaction: &mut Transaction,
+    ) -> Result<Option<String>, Status> {
+        let Ok(mut solana_transaction) = decode_data::<SolanaTransaction>(&transaction.data) else {
+            return Ok(None);
+        };
+        let message_hash = solana_transaction.message.hash().to_string();
+        let Some((caller_id, grant)) = self.sponsorship.get_grant(&message_hash) else {
+            return Ok(None);
+        };
+
+        let sponsor = self
+            .key_vault
+            .resolve(&grant.fee_payer.to_string())
+            .ok_or_else(|| Status::failed_precondition("Sponsor key is no longer available"))?;
+        add_sponsor_signature(&mut solana_transaction, &sponsor)
+            .map_err(Status::failed_precondition)?;
+
+        let signed_transaction_bytes = bincode::serialize(&solana_transaction).map_err(|e| {
+            Status::internal(format!("Failed to serialize signed transaction: {e}"))
+        })?;
+        transaction.signing_status = signing_status(&signers_of(
+            &solana_transaction.message.header,
+            &solana_transaction.message.account_keys,
+            &solana_transaction.signatures,
+        ));
+        transaction.signatures = solana_transaction
+            .signatures
+            .iter()
+            .map(ToString::to_string)
+            .collect();
+        transaction.data = bs58::encode(&signed_transaction_bytes).into_string();
+        transaction.state = TransactionState::FullySigned.into();
+
+        info!(
+            caller_id = %caller_id,
+            sponsor = %grant.fee_payer,
+            fee = grant.fee,
+            "💸 Added sponsor signature"
+        );
+        Ok(Some(message_hash))
+    }
+
+    /// Simulates a validated submission with signature verification instead of sending it.
+    ///
+    /// A failed simulation is classified as the preflight refusal `sendTransaction` would
+    /// have returned, so a dry run fails exactly where the real submission would.
+    async fn dry_run_submission(
+        &self,
+        transaction: &SolanaTransaction,
+        commitment: CommitmentConfig,
+        sponsored: bool,
+    ) -> Result<SubmitTransactionResponse, Status> {
+        let signature = synthetic_signature(transaction).to_string();
+        info!(%signature, "🧪 Dry run: simulating submission without broadcasting");
+
+        let permit = self.rpc_permit(RpcCallClass::Simulation).await?;
+        let simulation = self.rpc_client.simulate_transaction_with_config(
+            transaction,
+            solana_client::rpc_config::RpcSimulateTransactionConfig {
+                sig_verify: true,
+                replace_recent_blockhash: false,
+                commitment: Some(commitment),
+                encoding: None,
+                accounts: None,
+                min_context_slot: None,
+                inner_instructions: false,
+            },
+        );
+        drop(permit);
+
+        let (error, logs, units_consumed) = match simulation {
+            Ok(simulation) => {
+                let result = simulation.value;
+                (
+                    preflight_failure(&result),
+                    result.logs.unwrap_or_default(),
+                    result.units_consumed.unwrap_or_default(),
+                )
+            }
+            Err(e) => (Some(e), Vec::new(), 0),
+        };
+
+        let (submission_result, structured_error) = match error {
+            None => (SubmissionResult::DryRun, None),
+            Some(e) => {
+                let classification = classify_submission_error(&e);
+                let mut structured_error = build_structured_error(
+                    &e,
+                    classification,
+                    &transaction.message.recent_blockhash,
+                    self.rpc_client.get_slot().unwrap_or(0),
+                );
+                structured_error.instruction_failure = attribute_client_error(
+                    &e,
+                    &transaction.message.account_keys,
+                    &transaction.message.instructions,
+                );
+                warn!(error = %e, classification = ?classification, "Dry run failed");
+                (classification, Some(structured_error))
+            }
+        };
+
+        Ok(SubmitTransactionResponse {
+            signature,
+            submission_result: submission_result.into(),
+            error_message: structured_error
+                .as_ref()
+                .map(|e| e.message.clone())
+                .unwrap_or_default(),
+            structured_error,
+            sponsored,
+            dry_run: true,
+            simulation_logs: logs,
+            simulation_units_consumed: units_consumed,
+            ..Default::default()
+        })
+    }
+
+    /// Spawns a rebroadcast loop for a submitted transaction, returning the id of the
+    /// operation tracking it if the signature is now being rebroadcast (an identical
+    /// earlier submission may own the loop)
+    fn start_rebroadcast(
+        &self,
+        signature: &str,
+        transaction: SolanaTransaction,
+        schedule: RebroadcastSchedule,
+    ) -> Option<String> {
+        match self.rebroadcasts.start(signature) {
+            Ok(true) => {
+                let operation_id =
+                    match self
+                        .operations
+                        .start(REBROADCAST_OPERATION_KIND, signature, 0)
+                    {
+                        Ok(operation_id) => operation_id,
+                        Err(e) => {
+                            self.rebroadcasts
+                                .finish(signature, RebroadcastState::Cancelled);
+                            warn!(signature = %signature, error = %e, "Rebroadcast not started");
+                            return None;
+                        }
+                    };
+                self.operations.record_signature(&operation_id, signature);
+
+                info!(
+                    signature = %signature,
+                    operation_id = %operation_id,
+                    interval_ms = schedule.interval().as_millis(),
+                    "📣 Starting transaction rebroadcast"
+                );
+                tokio::spawn(rebroadcast_until_confirmed(
+                    Arc::clone(&self.rpc_client),
+                    Arc::clone(&self.rebroadcasts),
+                    Arc::clone(&self.operations),
+                    operation_id.clone(),
+                    transaction,
+                    schedule,
+                ));
+                Some(operation_id)
+            }
+            Ok(false) => {
+                debug!(signature = %signature, "Transaction is already being rebroadcast");
+                self.operations
+                    .list(Some(REBROADCAST_OPERATION_KIND), Some(signature), true)
+                    .into_iter()
+                    .next()
+                    .map(|operation| operation.id)
+            }
+            Err(e) => {
+                warn!(signature = %signature, error = %e, "Rebroadcast not started");
+                None
+            }
+        }
+    }
+
+    /// Keys a simulation for the cache by the slot bucket the node is currently in, or
+    /// `None` when the slot cannot be read, in which case the simulation is not cached
+    fn simulation_cache_key(
+        &self,
+        req: &SimulateTransactionRequest,
+        transaction: &SolanaTransaction,
+        commitment: CommitmentConfig,
+    ) -> Option<SimulationCacheKey> {
+        match self
+            .rpc_router
+            .for_commitment(commitment)
+            .get_slot_with_commitment(commitment)
+        {
+            Ok(slot) => Some(simulation_cache_key(
+                req,
+                transaction,
+                self.simulation_cache.slot_bucket(slot),
+            )),
+            Err(e) => {
+                warn!(error = %e, "Failed to read the slot, simulating without the cache");
+                None
+            }
+        }
+    }
+
+    /// Simulates a draft with the maximum compute budget and sizes the real budget from it
+    ///
+    /// The price comes from the request, then the transaction config, then (if asked for)
+    /// the fee market suggestion. The simulation includes the compute budget instructions
+    /// themselves so their own cost is covered by the resulting limit.
+    #[allow(clippy::result_large_err)]
+    async fn resolve_auto_compute_budget(
+        &self,
+        transaction: &Transaction,
+        instructions: &[Instruction],
+        fee_payer: &Pubkey,
+        recent_blockhash: &Hash,
+        options: &AutoComputeBudget,
+    ) -> Result<ResolvedComputeBudget, Status> {
+        if has_compute_budget_instruction(instructions) {
+            return Err(Status::invalid_argument(
+                "Transaction already contains compute budget instructions",
+            ));
+        }
+
+        let margin_percent = resolve_margin_percent(options.margin_percent)
+            .map_err(|e| Status::invalid_argument(format!("Invalid auto_compute_budget: {e}")))?;
+
+        let configured_price = transaction
+            .config
+            .as_ref()
+            .map_or(0, |config| config.compute_unit_price);
+        let compute_unit_price = if options.compute_unit_price > 0 {
+            options.compute_unit_price
+        } else if configured_price > 0 {
+            configured_price
+        } else if options.use_fee_market_price {
+            let accounts = write_locked_accounts(Some(transaction), &[fee_payer.to_string()])
+                .map_err(Status::invalid_argument)?;
+            let recent_fees = self
+                .rpc_client
+                .get_recent_prioritization_fees(&accounts)
+                .map_err(|e| {
+                    Status::internal(format!("Failed to get recent prioritization fees: {e}"))
+                })?;
+            let samples: Vec<u64> = recent_fees
+                .iter()
+                .map(|fee| fee.prioritization_fee)
+                .collect();
+            FeePercentiles::from_samples(&samples).suggested()
+        } else {
+            0
+        };
+
+        let probe = Message::new_with_blockhash(
+            &with_compute_budget(instructions, MAX_COMPUTE_UNIT_LIMIT, compute_unit_price),
+            Some(fee_payer),
+            recent_blockhash,
+        );
+        let _permit = self.rpc_permit(RpcCallClass::Simulation).await?;
+        let simulation = self
+            .rpc_client
+            .simulate_transaction_with_config(
+                &SolanaTransaction::new_unsigned(probe),
+                solana_client::rpc_config::RpcSimulateTransactionConfig {
+                    sig_verify: false,
+                    replace_recent_blockhash: true,
+                    commitment: Some(CommitmentConfig::confirmed()),
+                    encoding: None,
+                    accounts: None,
+                    min_context_slot: None,
+                    inner_instructions: false,
+                },
+            )
+            .map_err(|e| Status::internal(format!("Compute budget simulation failed: {e}")))?
+            .value;
+
+        if let Some(err) = simulation.err {
+            return Err(Status::failed_precondition(format!(
+                "Compute budget simulation failed: {err}"
+            )));
+        }
+        let simulated_compute_units = simulation
+            .units_consumed
+            .filter(|units| *units > 0)
+            .ok_or_else(|| {
+                Status::internal("Compute budget simulation did not report consumed units")
+            })?;
+
+        Ok(ResolvedComputeBudget {
+            compute_unit_limit: compute_unit_limit_with_margin(
+                simulated_compute_units,
+                margin_percent,
+            ),
+            compute_unit_price,
+            simulated_compute_units,
+        })
+    }
+}
+
+/// Compute budget chosen by `auto_compute_budget` during compilation
+struct ResolvedComputeBudget {
+    compute_unit_limit: u32,
+    compute_unit_price: u64,
+    simulated_compute_units: u64,
+}
+
+/// Classifies Solana RPC client errors into appropriate `SubmissionResult` categories
 ///
-/// Reference: solana-sdk instruction error definitions
-const fn classify_instruction_error(
-    _instruction_index: u8,
-    instruction_error: &InstructionError,
-) -> SubmissionResult {
-    match instruction_error {
-        // Program detected insufficient funds (e.g., token transfer, program fee)
-        InstructionError::InsufficientFunds => SubmissionResult::FailedInsufficientFunds,
+/// DEPRECATED: This function provides backward compatibility for the legacy enum classification.
+/// New code should use `error_builder::build_structured_error` for comprehensive error analysis.
+///
+/// This function performs type-safe error analysis using Solana's structured error types
+/// instead of fragile string pattern matching. It provides reliable classification based
+/// on the actual error enums from the Solana codebase.
+///
+/// Type-Safe Classification Strategy:
+/// 1. Direct `TransactionError` variants (most reliable)
+/// 2. RPC preflight failure errors with embedded `TransactionError`
+/// 3. Network/transport errors (Io, Reqwest)
+/// 4. Signing errors from cryptographic operations
+/// 5. Node health issues
+/// 6. Fallback to string analysis for unstructured errors
+///
+/// Reference: Solana Agave codebase at /Users/bernardbussy/Projects/github.com/anza-xyz/agave
+/// - rpc-client-api/src/client_error.rs: Main `ClientError` structure
+/// - rpc-client-types/src/request.rs: RPC error types and response data
+/// - transaction-status/src/lib.rs: `TransactionError` enum variants
+///
+/// This approach provides reliable error classification that won't break with message
+/// format changes and enables precise automated retry logic.
+pub(crate) fn classify_submission_error(error: &ClientError) -> SubmissionResult {
+    match &error.kind {
+        // Direct transaction errors - most reliable classification path
+        ClientErrorKind::TransactionError(transaction_error) => {
+            classify_transaction_error(transaction_error)
+        }
 
-        // Missing required signatures for instruction execution
-        InstructionError::MissingRequiredSignature => SubmissionResult::FailedInvalidSignature,
+        // RPC response errors with embedded transaction simulation results
+        // This occurs when send_transaction fails during preflight checks
+        ClientErrorKind::RpcError(RpcError::RpcResponseError {
+            data: RpcResponseErrorData::SendTransactionPreflightFailure(simulation_result),
+            ..
+        }) => simulation_result
+            .err
+            .as_ref()
+            .map_or(SubmissionResult::FailedValidation, classify_transaction_error),
 
-        // Compute budget exhausted during execution
-        InstructionError::ComputationalBudgetExceeded => SubmissionResult::FailedNetworkError,
+        // Node health issues - network problems at the validator level
+        ClientErrorKind::RpcError(RpcError::RpcResponseError {
+            data: RpcResponseErrorData::NodeUnhealthy { .. },
+            ..
+        }) => SubmissionResult::FailedNetworkError,
 
-        // Most instruction errors are validation issues - handled by wildcard below
+        // Network transport errors - connectivity, timeouts, HTTP issues (INDETERMINATE)
+        ClientErrorKind::Io(_) => SubmissionResult::Indeterminate,
+
+        ClientErrorKind::Reqwest(reqwest_error) => {
+            if reqwest_error.is_timeout() {
+                // Timeouts are especially dangerous - transaction might have been sent
+            }
+            // Connection/request failures - also indeterminate
+            SubmissionResult::Indeterminate
+        }
+
+        // Cryptographic signing errors
+        ClientErrorKind::SigningError(_) => SubmissionResult::FailedInvalidSignature,
+
+        // JSON serialization/parsing errors - usually validation issues
+        ClientErrorKind::SerdeJson(_) | ClientErrorKind::RpcError(RpcError::ParseError(_)) => {
+            SubmissionResult::FailedValidation
+        }
+
+        // Fallback for unstructured errors - use string analysis as last resort
+        ClientErrorKind::RpcError(_) => {
+            // Generic RPC errors are typically indeterminate
+            SubmissionResult::Indeterminate
+        }
+
+        ClientErrorKind::Custom(_) => {
+            // Only use string matching for truly unstructured error types
+            classify_by_message(&error.to_string())
+        }
+    }
+}
+
+/// Classifies `TransactionError` variants into `SubmissionResult` categories
+///
+/// This function maps specific Solana transaction errors to actionable response categories
+/// based on the transaction error variants defined in the Solana SDK.
+///
+/// Error Categories:
+/// - `InsufficientFunds`: Account balance or fee issues requiring funding
+/// - `InvalidSignature`: Cryptographic signature problems requiring re-signing  
+/// - `NetworkError`: Network capacity, maintenance, or timeout issues (retryable)
+/// - Validation: Transaction format, account, or instruction issues (not retryable)
+/// - Submitted: Transaction already processed (actually successful)
+///
+/// Reference: Solana transaction error definitions in transaction-status crate
+const fn classify_transaction_error(transaction_error: &TransactionError) -> SubmissionResult {
+    match transaction_error {
+        // Account balance and fee-related errors
+        TransactionError::InsufficientFundsForFee
+        | TransactionError::InsufficientFundsForRent { .. } => {
+            SubmissionResult::FailedInsufficientFunds
+        }
+
+        // Signature and authorization errors
+        TransactionError::SignatureFailure | TransactionError::MissingSignatureForFee => {
+            SubmissionResult::FailedInvalidSignature
+        }
+
+        // Network capacity and node availability issues (potentially retryable)
+        TransactionError::WouldExceedMaxBlockCostLimit
+        | TransactionError::WouldExceedMaxAccountCostLimit
+        | TransactionError::WouldExceedMaxVoteCostLimit
+        | TransactionError::WouldExceedAccountDataBlockLimit
+        | TransactionError::WouldExceedAccountDataTotalLimit
+        | TransactionError::TooManyAccountLocks
+        | TransactionError::ClusterMaintenance => SubmissionResult::FailedNetworkError,
+
+        // Transaction already successfully processed
+        TransactionError::AlreadyProcessed => SubmissionResult::Submitted,
+
+        // Account and validation errors (transaction format issues)
+        TransactionError::AccountNotFound
+        | TransactionError::ProgramAccountNotFound
+        | TransactionError::InvalidAccountForFee
+        | TransactionError::AccountInUse
+        | TransactionError::AccountLoadedTwice
+        | TransactionError::AccountBorrowOutstanding
+        | TransactionError::BlockhashNotFound
+        | TransactionError::CallChainTooDeep
+        | TransactionError::InvalidAccountIndex
+        | TransactionError::InvalidProgramForExecution
+        | TransactionError::SanitizeFailure
+        | TransactionError::UnsupportedVersion
+        | TransactionError::InvalidWritableAccount
+        | TransactionError::AddressLookupTableNotFound
+        | TransactionError::InvalidAddressLookupTableOwner
+        | TransactionError::InvalidAddressLookupTableData
+        | TransactionError::InvalidAddressLookupTableIndex
+        | TransactionError::InvalidRentPayingAccount
+        | TransactionError::DuplicateInstruction(_)
+        | TransactionError::MaxLoadedAccountsDataSizeExceeded
+        | TransactionError::InvalidLoadedAccountsDataSizeLimit
+        | TransactionError::ResanitizationNeeded
+        | TransactionError::ProgramExecutionTemporarilyRestricted { .. }
+        | TransactionError::UnbalancedTransaction => SubmissionResult::FailedValidation,
+
+        // Instruction-level errors require detailed analysis
+        TransactionError::InstructionError(instruction_index, instruction_error) => {
+            classify_instruction_error(*instruction_index, instruction_error)
+        }
+    }
+}
+
+/// Classifies instruction-level errors that occur during program execution
+///
+/// Instruction errors provide detailed information about failures within specific
+/// transaction instructions, enabling precise error handling for program-specific issues.
+///
+/// Reference: solana-sdk instruction error definitions
+const fn classify_instruction_error(
+    _instruction_index: u8,
+    instruction_error: &InstructionError,
+) -> SubmissionResult {
+    match instruction_error {
+        // Program detected insufficient funds (e.g., token transfer, program fee)
+        InstructionError::InsufficientFunds => SubmissionResult::FailedInsufficientFunds,
+
+        // Missing required signatures for instruction execution
+        InstructionError::MissingRequiredSignature => SubmissionResult::FailedInvalidSignature,
+
+        // Compute budget exhausted during execution
+        InstructionError::ComputationalBudgetExceeded => SubmissionResult::FailedNetworkError,
+
+        // Most instruction errors are validation issues - handled by wildcard below
+
+        // Program-specific custom error codes
+        InstructionError::Custom(_error_code) => {
+            // Custom error codes are program-specific and could indicate various issues
+            // Without context about the specific program, treat as validation error
+            SubmissionResult::FailedValidation
+        }
+
+        // Any new instruction error variants default to validation
+        _ => SubmissionResult::FailedValidation,
+    }
+}
+
+/// Fallback error classification using string pattern matching
+///
+/// This function is used only when structured error information is not available,
+/// serving as a compatibility layer for unstructured error messages.
+///
+/// This approach is intentionally limited and should only be reached for:
+/// - Custom error messages that don't fit standard patterns
+/// - Legacy error formats
+/// - Middleware or proxy errors
+///
+/// The type-safe classification above should handle 95%+ of real-world cases.
+fn classify_by_message(error_message: &str) -> SubmissionResult {
+    let error_str = error_message.to_lowercase();
+
+    if error_str.contains("insufficient")
+        && (error_str.contains("fund") || error_str.contains("balance"))
+    {
+        SubmissionResult::FailedInsufficientFunds
+    } else if error_str.contains("invalid") && error_str.contains("signature") {
+        SubmissionResult::FailedInvalidSignature
+    } else if error_str.contains("network")
+        || error_str.contains("connection")
+        || error_str.contains("timeout")
+    {
+        SubmissionResult::FailedNetworkError
+    } else {
+        // Default to validation error for unknown unstructured errors
+        SubmissionResult::FailedValidation
+    }
+}
+
+/// Converts protobuf `CommitmentLevel` enum to Solana SDK `CommitmentConfig`
+///
+/// This function handles the impedance mismatch between protobuf enums and Rust enums,
+/// providing safe conversion with fallback behavior for invalid or unspecified values.
+///
+/// Default Behavior:
+/// - Uses CONFIRMED commitment as default (balances speed vs. reliability)
+/// - Matches the account service default to maintain API consistency
+/// - Invalid enum values fallback to CONFIRMED for predictable behavior
+///
+/// Commitment Levels Explained:
+/// - PROCESSED: Fastest, least reliable (single validator confirmation)
+/// - CONFIRMED: Balanced (supermajority of validators, ~400ms typical)
+/// - FINALIZED: Slowest, most reliable (irreversible, ~13s typical)
+///
+/// The confirmed default prevents timing issues while maintaining reasonable performance.
+pub(crate) fn commitment_level_to_config(commitment_level: i32) -> CommitmentConfig {
+    match CommitmentLevel::try_from(commitment_level) {
+        Ok(CommitmentLevel::Processed) => CommitmentConfig::processed(),
+        Ok(CommitmentLevel::Confirmed) => CommitmentConfig::confirmed(),
+        Ok(CommitmentLevel::Finalized) => CommitmentConfig::finalized(),
+        Ok(CommitmentLevel::Unspecified) | Err(_) => {
+            // Default to confirmed for reliability - matches account service default
+            CommitmentConfig::confirmed()
+        }
+    }
+}
+
+/// Commitment used for historical reads.
+///
+/// getSignaturesForAddress, getTransaction and getBlock reject PROCESSED, so it is raised
+/// to CONFIRMED.
+fn history_commitment_config(commitment_level: i32) -> CommitmentConfig {
+    let commitment = commitment_level_to_config(commitment_level);
+    if commitment.is_finalized() {
+        commitment
+    } else {
+        CommitmentConfig::confirmed()
+    }
+}
+
+/// Parses an optional signature cursor, treating an empty string as absent
+#[allow(clippy::result_large_err)]
+fn parse_optional_signature(value: &str, field: &str) -> Result<Option<Signature>, Status> {
+    if value.is_empty() {
+        return Ok(None);
+    }
+    Signature::from_str(value)
+        .map(Some)
+        .map_err(|e| Status::invalid_argument(format!("Invalid {field} signature: {e}")))
+}
+
+/// Converts a transaction fetched from the network into its protochain representation
+///
+/// Data Reconstruction:
+/// Since blockchain storage is optimized and doesn't preserve all original metadata:
+/// - instructions: Empty (not stored on-chain after execution)
+/// - state: `FULLY_SIGNED` (network transactions are always fully signed)
+/// - config: None (execution config not preserved)
+/// - signatures: Reconstructed from on-chain data
+/// - `fee_payer`: First account key (Solana convention)
+/// - data: Raw transaction bytes (preserved exactly)
+#[allow(clippy::result_large_err)]
+fn network_transaction_to_proto(
+    versioned_transaction: &VersionedTransaction,
+    signature: &str,
+) -> Result<Transaction, Status> {
+    let transaction_bytes = bincode::serialize(versioned_transaction)
+        .map_err(|e| Status::internal(format!("Failed to serialize transaction: {e}")))?;
+
+    Ok(Transaction {
+        instructions: vec![], // Instructions are not preserved in network storage
+        state: TransactionState::FullySigned.into(), // Network transactions are fully signed
+        config: None,         // Config is not preserved in network storage
+        data: bs58::encode(&transaction_bytes).into_string(),
+        fee_payer: versioned_transaction
+            .message
+            .static_account_keys()
+            .first()
+            .map(std::string::ToString::to_string)
+            .unwrap_or_default(),
+        recent_blockhash: versioned_transaction.message.recent_blockhash().to_string(),
+        signatures: versioned_transaction
+            .signatures
+            .iter()
+            .map(std::string::ToString::to_string)
+            .collect(),
+        hash: signature.to_string(), // Use signature as hash for compatibility
+        signature: signature.to_string(),
+        signing_status: signing_status(&signers_of(
+            versioned_transaction.message.header(),
+            versioned_transaction.message.static_account_keys(),
+            &versioned_transaction.signatures,
+        )),
+    })
+}
+
+/// Returns the full account list instructions index into: static keys, then loaded addresses
+pub(crate) fn resolved_account_keys(
+    versioned_transaction: &VersionedTransaction,
+    loaded_addresses: Option<&UiLoadedAddresses>,
+) -> Vec<Pubkey> {
+    let mut account_keys = versioned_transaction.message.static_account_keys().to_vec();
+    if let Some(loaded) = loaded_addresses {
+        account_keys.extend(
+            loaded
+                .writable
+                .iter()
+                .chain(loaded.readonly.iter())
+                .filter_map(|address| Pubkey::from_str(address).ok()),
+        );
+    }
+    account_keys
+}
+
+/// Builds a history entry from a confirmed transaction and its status metadata
+#[allow(clippy::result_large_err)]
+fn history_entry_from_confirmed(
+    signature: &str,
+    confirmed_transaction: EncodedConfirmedTransactionWithStatusMeta,
+    include_logs: bool,
+    include_balance_changes: bool,
+) -> Result<TransactionHistoryEntry, Status> {
+    let versioned_transaction = confirmed_transaction
+        .transaction
+        .transaction
+        .decode()
+        .ok_or_else(|| Status::internal(format!("Failed to decode transaction {signature}")))?;
+
+    let mut entry = TransactionHistoryEntry {
+        transaction: Some(network_transaction_to_proto(&versioned_transaction, signature)?),
+        slot: confirmed_transaction.slot,
+        block_time: confirmed_transaction.block_time.unwrap_or_default(),
+        success: true,
+        error: String::new(),
+        fee: 0,
+        logs: vec![],
+        balance_changes: vec![],
+    };
+
+    if let Some(meta) = confirmed_transaction.transaction.meta {
+        entry.success = meta.err.is_none();
+        entry.error = meta.err.map(|err| format!("{err:?}")).unwrap_or_default();
+        entry.fee = meta.fee;
+
+        if include_logs {
+            entry.logs = Option::<Vec<String>>::from(meta.log_messages.clone()).unwrap_or_default();
+        }
+
+        if include_balance_changes {
+            let loaded_addresses = Option::<UiLoadedAddresses>::from(meta.loaded_addresses.clone());
+            let account_keys =
+                resolved_account_keys(&versioned_transaction, loaded_addresses.as_ref());
+            entry.balance_changes = balance_changes(&account_keys, &meta);
+        }
+    }
+
+    Ok(entry)
+}
+
+/// Builds the proto form of a legacy transaction: COMPILED (data is the bare message)
+/// when it carries no signatures, otherwise partially or fully signed
+#[allow(clippy::result_large_err)]
+fn legacy_transaction_to_proto(
+    solana_transaction: &SolanaTransaction,
+) -> Result<Transaction, Status> {
+    let message = &solana_transaction.message;
+    let signers =
+        signers_of(&message.header, &message.account_keys, &solana_transaction.signatures);
+    let (state, data) = if !signers.iter().any(|signer| signer.signed) {
+        (TransactionState::Compiled, bincode::serialize(message))
+    } else if signers.iter().all(|signer| signer.signed) {
+        (TransactionState::FullySigned, bincode::serialize(solana_transaction))
+    } else {
+        (TransactionState::PartiallySigned, bincode::serialize(solana_transaction))
+    };
+    let data =
+        data.map_err(|e| Status::internal(format!("Failed to serialize transaction: {e}")))?;
+
+    Ok(Transaction {
+        instructions: message_instructions(message)
+            .into_iter()
+            .map(sdk_instruction_to_proto)
+            .collect(),
+        state: state.into(),
+        fee_payer: message
+            .account_keys
+            .first()
+            .map(ToString::to_string)
+            .unwrap_or_default(),
+        recent_blockhash: message.recent_blockhash.to_string(),
+        data: bs58::encode(&data).into_string(),
+        signatures: solana_transaction
+            .signatures
+            .iter()
+            .filter(|sig| **sig != Signature::default())
+            .map(ToString::to_string)
+            .collect(),
+        signing_status: signing_status(&signers),
+        ..Default::default()
+    })
+}
+
+#[tonic::async_trait]
+impl TransactionService for TransactionServiceImpl {
+    type MonitorTransactionStream = ReceiverStream<Result<MonitorTransactionResponse, Status>>;
+    type MonitorBundleStream = ReceiverStream<Result<MonitorBundleResponse, Status>>;
+    type MonitorTransfersStream = ReceiverStream<Result<MonitorTransfersResponse, Status>>;
+    type StreamQueueEventsStream = ReceiverStream<Result<StreamQueueEventsResponse, Status>>;
+    /// Compiles a draft transaction with instructions into executable transaction bytecode
+    ///
+    /// State Transition: DRAFT → COMPILED
+    ///
+    /// This method performs the critical compilation step that transforms human-readable
+    /// instructions into binary transaction data that can be executed on Solana blockchain.
+    ///
+    /// Compilation Process:
+    /// 1. Validates current transaction state allows compilation
+    /// 2. Converts protobuf instructions to Solana SDK instructions
+    /// 3. Fetches recent blockhash (or uses provided one)
+    /// 4. Uses Solana SDK `Message::new_with_blockhash` for proper compilation
+    /// 5. Serializes compiled message with bincode for compact binary encoding
+    /// 6. Base58 encodes for safe protobuf transport
+    /// 7. Updates transaction metadata and validates state consistency
+    ///
+    /// Critical Design Notes:
+    /// - Uses Solana SDK compilation (not manual) for proper account deduplication
+    /// - Handles signing requirements calculation automatically
+    /// - Fetches blockhash if not provided (network call for freshness)
+    /// - All validation occurs before and after compilation for safety
+    /// - Draft instructions keep their relative order; the response maps every compiled
+    ///   instruction to its origin and account indexes, and strict compiles verify it
+    ///
+    /// Memory Management:
+    /// - Instructions are converted (not cloned) to minimize allocations
+    /// - Bincode provides zero-copy serialization where possible
+    /// - Base58 encoding only happens once at the end
+    async fn compile_transaction(
+        &self,
+        request: Request<CompileTransactionRequest>,
+    ) -> Result<Response<CompileTransactionResponse>, Status> {
+        // Sponsored compiles are charged to the caller their token authenticates
+        let caller_id = if request.get_ref().use_sponsored_fee_payer {
+            Some(self.auth.require_caller(request.metadata())?)
+        } else {
+            None
+        };
+        let req = request.into_inner();
+        let mut transaction = req
+            .transaction
+            .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
+
+        // Validate current state allows compilation
+        let current_state = transaction.state();
+        validate_operation_allowed_for_state(current_state, "compile")
+            .map_err(Status::failed_precondition)?;
+
+        // Validate transaction consistency in current state
+        validate_transaction_state_consistency(&transaction)
+            .map_err(|e| Status::invalid_argument(format!("Invalid transaction state: {e}")))?;
+
+        // Ensure we have instructions
+        if transaction.instructions.is_empty() {
+            return Err(Status::invalid_argument("Transaction must have at least one instruction"));
+        }
+        if req.strict_instruction_order && req.auto_compute_budget.is_some() {
+            return Err(Status::invalid_argument(
+                "auto_compute_budget prepends instructions and cannot be combined with \
+                 strict_instruction_order",
+            ));
+        }
+        let draft_len = transaction.instructions.len();
+
+        // Sponsored compiles take a funded fee payer from the server's pool
+        let sponsor = if req.use_sponsored_fee_payer {
+            if !req.fee_payer.is_empty() {
+                return Err(Status::invalid_argument(
+                    "fee_payer must be empty when use_sponsored_fee_payer is set",
+                ));
+            }
+            if !self.sponsorship.is_enabled() {
+                return Err(Status::failed_precondition("Sponsored fee payers are not configured"));
+            }
+            let sponsor = self
+                .sponsorship
+                .select_fee_payer(&self.key_vault, |key| self.rpc_client.get_balance(key).ok())
+                .map_err(Status::resource_exhausted)?;
+            Some(sponsor)
+        } else {
+            // Validate fee_payer is provided
+            if req.fee_payer.is_empty() {
+                return Err(Status::invalid_argument("fee_payer is required"));
+            }
+            None
+        };
+
+        // Convert proto instructions to SDK instructions
+        let sdk_instructions: Result<Vec<Instruction>, String> = transaction
+            .instructions
+            .iter()
+            .map(|proto_ix| proto_instruction_to_sdk(proto_ix.clone()))
+            .collect();
+
+        let mut sdk_instructions = sdk_instructions
+            .map_err(|e| Status::invalid_argument(format!("Invalid instruction: {e}")))?;
+
+        // Tag the transaction with a memo appended after the caller's instructions
+        if !req.memo.is_empty() {
+            let memo = memo_instruction(&req.memo).map_err(Status::invalid_argument)?;
+            let mut proto_ix = sdk_instruction_to_proto(memo.clone());
+            proto_ix.description = "Memo".to_string();
+            transaction.instructions.push(proto_ix);
+            sdk_instructions.push(memo);
+        }
+
+        // Parse fee payer pubkey
+        let fee_payer = match sponsor.as_ref() {
+            Some(sponsor) => sponsor.pubkey(),
+            None => Pubkey::from_str(&req.fee_payer)
+                .map_err(|e| Status::invalid_argument(format!("Invalid fee_payer: {e}")))?,
+        };
+        if sponsor.is_some() && references_sponsor(&sdk_instructions, &fee_payer) {
+            return Err(Status::invalid_argument(
+                "Instructions may not reference the sponsored fee payer",
+            ));
+        }
+
+        // Get recent blockhash (from request or fetch from network)
+        let recent_blockhash = if req.recent_blockhash.is_empty() {
+            // Fetch latest blockhash from network
+            let _permit = self.rpc_permit(RpcCallClass::Blockhash).await?;
+            self.rpc_client
+                .get_latest_blockhash()
+                .map_err(|e| Status::internal(format!("Failed to get latest blockhash: {e}")))?
+        } else {
+            // Use provided blockhash
+            Hash::from_str(&req.recent_blockhash)
+                .map_err(|e| Status::invalid_argument(format!("Invalid blockhash format: {e}")))?
+        };
+
+        // Optionally size the compute budget from a simulation and prepend its instructions
+        let (sdk_instructions, simulated_compute_units, injected_compute_budget) =
+            match req.auto_compute_budget.as_ref() {
+                Some(options) => {
+                    let budget = self
+                        .resolve_auto_compute_budget(
+                            &transaction,
+                            &sdk_instructions,
+                            &fee_payer,
+                            &recent_blockhash,
+                            options,
+                        )
+                        .await?;
+                    let budgeted = with_compute_budget(
+                        &sdk_instructions,
+                        budget.compute_unit_limit,
+                        budget.compute_unit_price,
+                    );
+
+                    // Keep the proto instructions and config in step with the compiled message
+                    let injected = budgeted.len() - sdk_instructions.len();
+                    transaction.instructions.splice(
+                        0..0,
+                        budgeted[..injected].iter().cloned().map(|instruction| {
+                            let mut proto_ix = sdk_instruction_to_proto(instruction);
+                            proto_ix.description = "Auto compute budget".to_string();
+                            proto_ix
+                        }),
+                    );
+                    let config = transaction.config.get_or_insert_with(Default::default);
+                    config.compute_unit_limit = budget.compute_unit_limit;
+                    config.compute_unit_price = budget.compute_unit_price;
+
+                    (budgeted, budget.simulated_compute_units, injected)
+                }
+                None => (sdk_instructions, 0, 0),
+            };
+
+        // CRITICAL: Use Solana SDK to compile the transaction
+        // This handles all the complexity of account deduplication, signing requirements, etc.
+        let message =
+            Message::new_with_blockhash(&sdk_instructions, Some(&fee_payer), &recent_blockhash);
+
+        // Report where every instruction landed; strict compiles also prove that each draft
+        // instruction kept its position
+        let injected = InjectedInstructions {
+            compute_budget: injected_compute_budget,
+            memo: !req.memo.is_empty(),
+        };
+        if req.strict_instruction_order {
+            let draft =
+                &sdk_instructions[injected_compute_budget..injected_compute_budget + draft_len];
+            verify_instruction_order(&message, draft, injected).map_err(|e| {
+                Status::internal(format!("Instruction order was not preserved: {e}"))
+            })?;
+        }
+        let instruction_positions = instruction_positions(&message, injected);
+        let account_keys = message
+            .account_keys
+            .iter()
+            .map(ToString::to_string)
+            .collect();
+
+        // Quote the fee against the caller's budget and grant the message for submission
+        let sponsorship = match (sponsor, caller_id) {
+            (Some(_), Some(caller_id)) => {
+                let fee = self.rpc_client.get_fee_for_message(&message).map_err(|e| {
+                    Status::unavailable(format!("Failed to quote sponsored fee: {e}"))
+                })?;
+                let remaining_budget_lamports = self.sponsorship.remaining_budget(&caller_id);
+                self.sponsorship
+                    .grant(
+                        &caller_id,
+                        &message.hash().to_string(),
+                        SponsorshipGrant {
+                            fee_payer,
+                            fee,
+                            granted_at: unix_timestamp(),
+                        },
+                    )
+                    .map_err(Status::resource_exhausted)?;
+                Some(SponsorshipQuote {
+                    fee_payer: fee_payer.to_string(),
+                    fee_lamports: fee,
+                    remaining_budget_lamports,
+                })
+            }
+            _ => None,
+        };
+
+        // Serialize the compiled message for transport
+        let transaction_bytes = bincode::serialize(&message)
+            .map_err(|e| Status::internal(format!("Transaction serialization failed: {e}")))?;
+
+        // Encode as base58 for proto transport
+        let transaction_data = bs58::encode(&transaction_bytes).into_string();
+
+        // Validate state transition DRAFT -> COMPILED
+        validate_state_transition(current_state, TransactionState::Compiled)
+            .map_err(|e| Status::internal(format!("State transition validation failed: {e}")))?;
+
+        // Update transaction with compiled data and metadata
+        transaction.data = transaction_data;
+        transaction.state = TransactionState::Compiled.into();
+        transaction.fee_payer = fee_payer.to_string();
+        transaction.recent_blockhash = recent_blockhash.to_string();
+        transaction.signing_status =
+            signing_status(&signers_of(&message.header, &message.account_keys, &[]));
+
+        // Validate the updated transaction consistency
+        validate_transaction_state_consistency(&transaction).map_err(|e| {
+            Status::internal(format!("Compiled transaction validation failed: {e}"))
+        })?;
+
+        Ok(Response::new(CompileTransactionResponse {
+            transaction: Some(transaction),
+            simulated_compute_units,
+            sponsorship,
+            instruction_positions,
+            account_keys,
+        }))
+    }
+
+    /// Estimates compute units and transaction fees for a compiled transaction
+    ///
+    /// This method provides accurate resource consumption estimates by simulating
+    /// transaction execution without actually submitting to the blockchain.
+    ///
+    /// Estimation Strategy:
+    /// 1. Primary: Uses RPC `simulate_transaction_with_config` for real execution analysis
+    /// 2. Fallback: Instruction-count-based heuristics if simulation fails
+    /// 3. Handles both None and 0 compute units with reasonable defaults
+    ///
+    /// Compute Unit Estimation:
+    /// - Real simulation: Uses actual execution consumption when available
+    /// - Fallback formula: instructions * 50,000 CU (realistic per-instruction average)
+    /// - Bounds: minimum 200,000 CU, maximum 1,400,000 CU (network limits)
+    ///
+    /// Fee Calculation:
+    /// - Base fee: 5,000 lamports (standard transaction fee)
+    /// - Priority fee: `compute_units` * `compute_unit_price` (from transaction config)
+    /// - Caps priority fee at 1,000,000 lamports to prevent excessive costs
+    /// - Fallback priority fee: 1,000 lamports for network prioritization
+    ///
+    /// The estimation accuracy helps users avoid transaction failures due to
+    /// insufficient fees or compute budget exhaustion.
+    async fn estimate_transaction(
+        &self,
+        request: Request<EstimateTransactionRequest>,
+    ) -> Result<Response<EstimateTransactionResponse>, Status> {
+        let req = request.into_inner();
+        let transaction = req
+            .transaction
+            .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
+
+        // Validate current state allows estimation
+        let current_state = transaction.state();
+        validate_operation_allowed_for_state(current_state, "estimate")
+            .map_err(Status::failed_precondition)?;
+
+        // Validate transaction state consistency
+        validate_transaction_state_consistency(&transaction)
+            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
+
+        // Ensure transaction has compiled data
+        if transaction.data.is_empty() {
+            return Err(Status::invalid_argument("Transaction must be compiled before estimation"));
+        }
+
+        // Deserialize the compiled transaction data
+        let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
+            Status::invalid_argument(format!("Failed to decode transaction data: {e}"))
+        })?;
+
+        let message: Message = bincode::deserialize(&transaction_data).map_err(|e| {
+            Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
+        })?;
+
+        // Create an unsigned transaction for simulation
+        let solana_transaction = SolanaTransaction::new_unsigned(message);
+
+        // Get commitment level for estimation simulation
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        // Use simulation to get accurate compute unit estimation with configurable commitment level
+        let _permit = self.rpc_permit(RpcCallClass::Simulation).await?;
+        let (compute_units, _logs) = if let Ok(simulation_result) =
+            self.rpc_client.simulate_transaction_with_config(
+                &solana_transaction,
+                solana_client::rpc_config::RpcSimulateTransactionConfig {
+                    sig_verify: false,
+                    replace_recent_blockhash: false,
+                    commitment: Some(commitment),
+                    encoding: None,
+                    accounts: None,
+                    min_context_slot: None,
+                    inner_instructions: false,
+                },
+            ) {
+            // Handle both None and 0 cases by providing reasonable fallback
+            let compute_units = match simulation_result.value.units_consumed {
+                Some(units) if units > 0 => units,
+                _ => {
+                    // Fallback estimation based on instruction count
+                    let instruction_count = transaction.instructions.len() as u64;
+                    (instruction_count * 50_000).clamp(200_000, 1_400_000)
+                }
+            };
+            let logs = simulation_result.value.logs.unwrap_or_default();
+            (compute_units, logs)
+        } else {
+            // Fallback to basic estimation if simulation fails
+            let instruction_count = transaction.instructions.len() as u64;
+            let estimated_compute_units = (instruction_count * 50_000).clamp(200_000, 1_400_000);
+            (estimated_compute_units, vec![])
+        };
+
+        // Calculate fee estimation
+        let base_fee_lamports = 5_000; // Base transaction fee
+        let compute_unit_price = transaction
+            .config
+            .as_ref()
+            .map_or(0, |config| config.compute_unit_price);
+
+        // Priority fee calculation based on compute units and price
+        let priority_fee = if compute_unit_price > 0 {
+            (compute_units * compute_unit_price).min(1_000_000) // Cap priority fee
+        } else {
+            // Default priority fee estimation based on network conditions
+            1_000
+        };
+
+        let total_fee = base_fee_lamports + priority_fee;
+
+        Ok(Response::new(EstimateTransactionResponse {
+            compute_units,
+            fee_lamports: total_fee,
+            priority_fee,
+        }))
+    }
+
+    /// Simulates a transaction execution without blockchain submission
+    ///
+    /// This method provides a "dry run" execution of the transaction to predict
+    /// outcomes, catch errors early, and analyze execution logs before submission.
+    /// Drafts are compiled locally, so they can be simulated before compiling or signing.
+    ///
+    /// Simulation Benefits:
+    /// 1. Error Detection: Catches failures before expensive submission
+    /// 2. Log Analysis: Provides execution logs for debugging
+    /// 3. State Validation: Confirms transaction will succeed given current blockchain state
+    /// 4. Cost Prevention: Avoids wasted transaction fees on failing operations
+    ///
+    /// Simulation Configuration:
+    /// - `sig_verify`: off unless requested (`FULLY_SIGNED` transactions only)
+    /// - `replace_recent_blockhash`: off unless requested (required for drafts without a
+    ///   blockhash, and useful for compiled transactions whose blockhash expired)
+    /// - commitment: configurable (matches user's desired confirmation level)
+    /// - `inner_instructions`: only when requested (adds simulation overhead)
+    /// - accounts: post-simulation state of the requested `account_addresses`
+    /// - `min_context_slot`: optional; a node behind it fails the call with `UNAVAILABLE`
+    ///
+    /// Response Format:
+    /// - success: boolean indicating if transaction would succeed
+    /// - error: detailed error message if simulation fails
+    /// - logs: program execution logs for analysis and debugging
+    /// - `units_consumed`: compute units consumed by the whole transaction
+    /// - `instruction_compute_usage`: per-instruction consumption and log slices, parsed
+    ///   from the logs so the instruction that exhausts the budget can be identified
+    /// - accounts: pre/post state of each requested account, for balance change previews
+    /// - `return_data`: data set by the last program that called `set_return_data`
+    /// - `inner_instructions`: CPIs per top-level instruction, decoded for known programs
+    ///
+    /// Note: Without `sig_verify` signatures aren't validated, which allows simulation of
+    /// unsigned and partially signed transactions during development.
+    async fn simulate_transaction(
+        &self,
+        request: Request<SimulateTransactionRequest>,
+    ) -> Result<Response<SimulateTransactionResponse>, Status> {
+        let req = request.into_inner();
+        let transaction = req
+            .transaction
+            .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
+
+        // Validate current state allows simulation
+        let current_state = transaction.state();
+        validate_operation_allowed_for_state(current_state, "simulate")
+            .map_err(Status::failed_precondition)?;
+
+        // Validate transaction state consistency
+        validate_transaction_state_consistency(&transaction)
+            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
+
+        // Drafts are compiled locally; signed transactions keep their signatures for sig_verify
+        let options = SimulationOptions {
+            sig_verify: req.sig_verify,
+            replace_recent_blockhash: req.replace_recent_blockhash,
+        };
+        let solana_transaction = simulation_transaction(&transaction, &req.fee_payer, options)
+            .map_err(Status::invalid_argument)?;
+
+        // Get commitment level for simulation
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        let min_context_slot = min_context_slot(req.min_context_slot);
+
+        // Requested accounts are read before simulating so balance changes can be previewed
+        let addresses =
+            parse_account_addresses(&req.account_addresses).map_err(Status::invalid_argument)?;
 
-        // Program-specific custom error codes
-        InstructionError::Custom(_error_code) => {
-            // Custom error codes are program-specific and could indicate various issues
-            // Without context about the specific program, treat as validation error
-            SubmissionResult::FailedValidation
+        // Opted-in callers reuse a result simulated for the same message in this slot bucket
+        let cache_key = if req.use_cache && self.simulation_cache.is_enabled() {
+            self.simulation_cache_key(&req, &solana_transaction, commitment)
+        } else {
+            None
+        };
+        if let Some(key) = &cache_key {
+            if let Some(cached) = self.simulation_cache.get(key) {
+                debug!(message_hash = %key.message_hash, "🧪 Simulation served from cache");
+                return Ok(Response::new(SimulateTransactionResponse {
+                    cache: Some(SimulationCacheInfo {
+                        hit: true,
+                        message_hash: key.message_hash.clone(),
+                        slot_bucket: key.slot_bucket,
+                        simulated_slot: cached.simulated_slot,
+                        age_ms: u64::try_from(cached.age.as_millis()).unwrap_or(u64::MAX),
+                    }),
+                    ..cached.response
+                }));
+            }
         }
 
-        // Any new instruction error variants default to validation
-        _ => SubmissionResult::FailedValidation,
-    }
-}
+        let pre_accounts = if addresses.is_empty() {
+            Vec::new()
+        } else {
+            let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+            self.rpc_router
+                .for_commitment(commitment)
+                .get_multiple_accounts_with_config(
+                    &addresses,
+                    RpcAccountInfoConfig {
+                        encoding: Some(UiAccountEncoding::Base64Zstd),
+                        data_slice: None,
+                        commitment: Some(commitment),
+                        min_context_slot,
+                    },
+                )
+                .map_err(|e| read_error_status(&e, "Failed to fetch account state"))?
+                .value
+        };
 
-/// Fallback error classification using string pattern matching
-///
-/// This function is used only when structured error information is not available,
-/// serving as a compatibility layer for unstructured error messages.
-///
-/// This approach is intentionally limited and should only be reached for:
-/// - Custom error messages that don't fit standard patterns
-/// - Legacy error formats
-/// - Middleware or proxy errors
-///
-/// The type-safe classification above should handle 95%+ of real-world cases.
-fn classify_by_message(error_message: &str) -> SubmissionResult {
-    let error_str = error_message.to_lowercase();
+        // Simulate the transaction using RPC with configurable commitment level
+        let _permit = self.rpc_permit(RpcCallClass::Simulation).await?;
+        match self
+            .rpc_router
+            .for_commitment(commitment)
+            .simulate_transaction_with_config(
+                &solana_transaction,
+                solana_client::rpc_config::RpcSimulateTransactionConfig {
+                    sig_verify: options.sig_verify,
+                    replace_recent_blockhash: options.replace_recent_blockhash,
+                    commitment: Some(commitment),
+                    encoding: None,
+                    accounts: (!addresses.is_empty()).then(|| {
+                        RpcSimulateTransactionAccountsConfig {
+                            encoding: Some(UiAccountEncoding::Base64),
+                            addresses: req.account_addresses.clone(),
+                        }
+                    }),
+                    min_context_slot,
+                    inner_instructions: req.include_inner_instructions,
+                },
+            ) {
+            Ok(simulation_result) => {
+                let simulated_slot = simulation_result.context.slot;
+                let result = simulation_result.value;
+                let success = result.err.is_none();
+                let error = result.err.map(|err| format!("{err:?}")).unwrap_or_default();
+                let logs = result.logs.unwrap_or_default();
 
-    if error_str.contains("insufficient")
-        && (error_str.contains("fund") || error_str.contains("balance"))
-    {
-        SubmissionResult::FailedInsufficientFunds
-    } else if error_str.contains("invalid") && error_str.contains("signature") {
-        SubmissionResult::FailedInvalidSignature
-    } else if error_str.contains("network")
-        || error_str.contains("connection")
-        || error_str.contains("timeout")
-    {
-        SubmissionResult::FailedNetworkError
-    } else {
-        // Default to validation error for unknown unstructured errors
-        SubmissionResult::FailedValidation
-    }
-}
+                // Attribute compute consumption to each top-level instruction
+                let instruction_compute_usage = meter_instructions(&logs);
 
-/// Converts protobuf `CommitmentLevel` enum to Solana SDK `CommitmentConfig`
-///
-/// This function handles the impedance mismatch between protobuf enums and Rust enums,
-/// providing safe conversion with fallback behavior for invalid or unspecified values.
-///
-/// Default Behavior:
-/// - Uses CONFIRMED commitment as default (balances speed vs. reliability)
-/// - Matches the account service default to maintain API consistency
-/// - Invalid enum values fallback to CONFIRMED for predictable behavior
-///
-/// Commitment Levels Explained:
-/// - PROCESSED: Fastest, least reliable (single validator confirmation)
-/// - CONFIRMED: Balanced (supermajority of validators, ~400ms typical)
-/// - FINALIZED: Slowest, most reliable (irreversible, ~13s typical)
-///
-/// The confirmed default prevents timing issues while maintaining reasonable performance.
-fn commitment_level_to_config(commitment_level: i32) -> CommitmentConfig {
-    match CommitmentLevel::try_from(commitment_level) {
-        Ok(CommitmentLevel::Processed) => CommitmentConfig::processed(),
-        Ok(CommitmentLevel::Confirmed) => CommitmentConfig::confirmed(),
-        Ok(CommitmentLevel::Finalized) => CommitmentConfig::finalized(),
-        Ok(CommitmentLevel::Unspecified) | Err(_) => {
-            // Default to confirmed for reliability - matches account service default
-            CommitmentConfig::confirmed()
+                let mut response = SimulateTransactionResponse {
+                    success,
+                    error,
+                    logs,
+                    units_consumed: result.units_consumed.unwrap_or_default(),
+                    instruction_compute_usage,
+                    // Nodes only report account state for simulations that succeeded
+                    accounts: result
+                        .accounts
+                        .map(|post| simulated_accounts(&addresses, &pre_accounts, &post))
+                        .unwrap_or_default(),
+                    return_data: result.return_data.as_ref().map(return_data_to_proto),
+                    inner_instructions: result
+                        .inner_instructions
+                        .map(|inner| {
+                            inner_instructions_to_proto(
+                                &inner,
+                                &solana_transaction.message.account_keys,
+                            )
+                        })
+                        .unwrap_or_default(),
+                    cache: None,
+                };
+                if let Some(key) = cache_key {
+                    let info = SimulationCacheInfo {
+                        hit: false,
+                        message_hash: key.message_hash.clone(),
+                        slot_bucket: key.slot_bucket,
+                        simulated_slot,
+                        age_ms: 0,
+                    };
+                    self.simulation_cache
+                        .insert(key, response.clone(), simulated_slot);
+                    response.cache = Some(info);
+                }
+                Ok(Response::new(response))
+            }
+            Err(e) if min_context_slot_not_reached(&e).is_some() => {
+                Err(read_error_status(&e, "Simulation failed"))
+            }
+            Err(e) => {
+                // Simulation failed - this could be due to network issues or invalid transaction
+                Ok(Response::new(SimulateTransactionResponse {
+                    success: false,
+                    error: format!("Simulation failed: {e}"),
+                    ..Default::default()
+                }))
+            }
         }
     }
-}
 
-#[tonic::async_trait]
-impl TransactionService for TransactionServiceImpl {
-    type MonitorTransactionStream = ReceiverStream<Result<MonitorTransactionResponse, Status>>;
-    /// Compiles a draft transaction with instructions into executable transaction bytecode
+    /// Signs a compiled transaction with cryptographic signatures for authorization
     ///
-    /// State Transition: DRAFT → COMPILED
+    /// State Transition: COMPILED → `PARTIALLY_SIGNED` or `FULLY_SIGNED`
     ///
-    /// This method performs the critical compilation step that transforms human-readable
-    /// instructions into binary transaction data that can be executed on Solana blockchain.
+    /// This method applies cryptographic signatures to authorize transaction execution.
+    /// It supports multiple signing methods and automatically determines completion state.
     ///
-    /// Compilation Process:
-    /// 1. Validates current transaction state allows compilation
-    /// 2. Converts protobuf instructions to Solana SDK instructions
-    /// 3. Fetches recent blockhash (or uses provided one)
-    /// 4. Uses Solana SDK `Message::new_with_blockhash` for proper compilation
-    /// 5. Serializes compiled message with bincode for compact binary encoding
-    /// 6. Base58 encodes for safe protobuf transport
-    /// 7. Updates transaction metadata and validates state consistency
+    /// Signing Process:
+    /// 1. Validates transaction state allows signing (must be COMPILED or `PARTIALLY_SIGNED`)
+    /// 2. Deserializes compiled transaction data back to Solana SDK format
+    /// 3. Processes signing method (currently supports private key signing)
+    /// 4. Matches provided keys with transaction's required signers
+    /// 5. Applies signatures for matching accounts only
+    /// 6. Determines final state based on signature completeness
+    /// 7. Re-serializes signed transaction for storage
     ///
-    /// Critical Design Notes:
-    /// - Uses Solana SDK compilation (not manual) for proper account deduplication
-    /// - Handles signing requirements calculation automatically
-    /// - Fetches blockhash if not provided (network call for freshness)
-    /// - All validation occurs before and after compilation for safety
+    /// State Determination Logic:
+    /// - `FULLY_SIGNED`: All required signatures present (ready for submission)
+    /// - `PARTIALLY_SIGNED`: Some signatures present, more needed
     ///
-    /// Memory Management:
-    /// - Instructions are converted (not cloned) to minimize allocations
-    /// - Bincode provides zero-copy serialization where possible
-    /// - Base58 encoding only happens once at the end
-    async fn compile_transaction(
+    /// Security Features:
+    /// - Only signs for accounts present in transaction (prevents signature reuse)
+    /// - Validates private key format (64 bytes, Base58 encoded)
+    /// - Signature verification through Solana SDK cryptographic functions
+    /// - No signature storage of private keys (used and discarded)
+    ///
+    /// Signing Methods:
+    /// - `PrivateKeys`: Direct private key signing (current implementation)
+    /// - Seeds: Deterministic key derivation (not yet implemented)
+    /// - `Mnemonic`: Keys derived from a BIP39 mnemonic (inline or held by the key vault)
+    ///   along hardened BIP44 paths, see `derive_keypairs`
+    /// - `HardwareWallet`: A Ledger device signs through the companion agent, subject to
+    ///   the blind-signing policy, see `check_blind_signing`
+    ///
+    /// The multi-step signing support enables complex workflows like multi-signature
+    /// transactions and hardware wallet integration.
+    async fn sign_transaction(
         &self,
-        request: Request<CompileTransactionRequest>,
-    ) -> Result<Response<CompileTransactionResponse>, Status> {
+        request: Request<SignTransactionRequest>,
+    ) -> Result<Response<SignTransactionResponse>, Status> {
+        // KMS key policies are checked against the caller the request's token authenticates
+        let kms_caller_id = match request.get_ref().signing_method {
+            Some(sign_transaction_request::SigningMethod::Kms(_)) => {
+                self.auth.require_caller(request.metadata())?
+            }
+            _ => String::new(),
+        };
         let req = request.into_inner();
         let mut transaction = req
             .transaction
             .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
 
-        // Validate current state allows compilation
+        // Validate current state allows signing
         let current_state = transaction.state();
-        validate_operation_allowed_for_state(current_state, "compile")
+        validate_operation_allowed_for_state(current_state, "sign")
             .map_err(Status::failed_precondition)?;
 
-        // Validate transaction consistency in current state
+        // Validate transaction state consistency
         validate_transaction_state_consistency(&transaction)
-            .map_err(|e| Status::invalid_argument(format!("Invalid transaction state: {e}")))?;
-
-        // Ensure we have instructions
-        if transaction.instructions.is_empty() {
-            return Err(Status::invalid_argument("Transaction must have at least one instruction"));
-        }
+            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
 
-        // Validate fee_payer is provided
-        if req.fee_payer.is_empty() {
-            return Err(Status::invalid_argument("fee_payer is required"));
+        // Ensure transaction has compiled data
+        if transaction.data.is_empty() {
+            return Err(Status::invalid_argument("Transaction must be compiled before signing"));
         }
 
-        // Convert proto instructions to SDK instructions
-        let sdk_instructions: Result<Vec<Instruction>, String> = transaction
-            .instructions
-            .iter()
-            .map(|proto_ix| proto_instruction_to_sdk(proto_ix.clone()))
-            .collect();
+        // Compiled data is a bare message; partially signed data is a transaction whose
+        // existing signatures must be kept
+        let mut solana_transaction = if current_state == TransactionState::Compiled {
+            SolanaTransaction::new_unsigned(decode_data::<Message>(&transaction.data).map_err(
+                |e| Status::invalid_argument(format!("Failed to deserialize transaction: {e}")),
+            )?)
+        } else {
+            decode_data::<SolanaTransaction>(&transaction.data).map_err(|e| {
+                Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
+            })?
+        };
 
-        let sdk_instructions = sdk_instructions
-            .map_err(|e| Status::invalid_argument(format!("Invalid instruction: {e}")))?;
+        // Process signing method and apply signatures
+        let mut signatures_applied = 0;
+        let keypairs = match req.signing_method {
+            Some(signing_method) => match signing_method {
+                sign_transaction_request::SigningMethod::PrivateKeys(private_keys_method) => {
+                    // Parse private keys into keypairs
+                    let mut keypairs = Vec::new();
+                    for private_key_str in &private_keys_method.private_keys {
+                        let private_key_bytes =
+                            bs58::decode(private_key_str).into_vec().map_err(|e| {
+                                Status::invalid_argument(format!("Invalid private key format: {e}"))
+                            })?;
 
-        // Parse fee payer pubkey
-        let fee_payer = Pubkey::from_str(&req.fee_payer)
-            .map_err(|e| Status::invalid_argument(format!("Invalid fee_payer: {e}")))?;
+                        if private_key_bytes.len() != 64 {
+                            return Err(Status::invalid_argument("Private key must be 64 bytes"));
+                        }
 
-        // Get recent blockhash (from request or fetch from network)
-        let recent_blockhash = if req.recent_blockhash.is_empty() {
-            // Fetch latest blockhash from network
-            self.rpc_client
-                .get_latest_blockhash()
-                .map_err(|e| Status::internal(format!("Failed to get latest blockhash: {e}")))?
-        } else {
-            // Use provided blockhash
-            Hash::from_str(&req.recent_blockhash)
-                .map_err(|e| Status::invalid_argument(format!("Invalid blockhash format: {e}")))?
+                        let keypair = Keypair::from_bytes(&private_key_bytes).map_err(|e| {
+                            Status::invalid_argument(format!("Invalid private key: {e}"))
+                        })?;
+                        keypairs.push(Arc::new(keypair));
+                    }
+                    keypairs
+                }
+                sign_transaction_request::SigningMethod::Seeds(_seed_method) => {
+                    // Seed-based signing not implemented in current version
+                    return Err(Status::unimplemented("Seed-based signing not available"));
+                }
+                sign_transaction_request::SigningMethod::StoredKeys(stored_keys_method) => {
+                    // Resolve vault aliases, public keys or keystore handles; key material
+                    // never leaves the server
+                    let mut keypairs = Vec::new();
+                    for key_ref in &stored_keys_method.key_refs {
+                        let keypair = if Keystore::is_handle(key_ref) {
+                            self.keystore
+                                .load(key_ref)
+                                .map_err(Status::failed_precondition)?
+                        } else {
+                            self.key_vault.resolve(key_ref)
+                        }
+                        .ok_or_else(|| {
+                            Status::not_found(format!("Stored key not found: {key_ref}"))
+                        })?;
+                        keypairs.push(keypair);
+                    }
+                    keypairs
+                }
+                sign_transaction_request::SigningMethod::Mnemonic(mnemonic_method) => {
+                    // Keys are derived per request and dropped with it; the phrase is never logged
+                    let phrase = match (
+                        mnemonic_method.mnemonic.is_empty(),
+                        mnemonic_method.mnemonic_ref.is_empty(),
+                    ) {
+                        (false, true) => mnemonic_method.mnemonic.clone(),
+                        (true, false) => self
+                            .key_vault
+                            .mnemonic(&mnemonic_method.mnemonic_ref)
+                            .ok_or_else(|| {
+                                Status::not_found(format!(
+                                    "Stored mnemonic not found: {}",
+                                    mnemonic_method.mnemonic_ref
+                                ))
+                            })?,
+                        _ => {
+                            return Err(Status::invalid_argument(
+                                "Exactly one of mnemonic and mnemonic_ref is required",
+                            ));
+                        }
+                    };
+                    derive_keypairs(
+                        &phrase,
+                        &mnemonic_method.passphrase,
+                        &mnemonic_method.derivation_paths,
+                    )
+                    .map_err(Status::invalid_argument)?
+                    .into_iter()
+                    .map(Arc::new)
+                    .collect()
+                }
+                sign_transaction_request::SigningMethod::HardwareWallet(hardware_method) => {
+                    // The device signs itself, so its signatures are applied here directly
+                    for (index, signature) in self
+                        .hardware_wallet_signatures(&hardware_method, &solana_transaction)
+                        .await?
+                    {
+                        solana_transaction.signatures[index] = signature;
+                        signatures_applied += 1;
+                    }
+                    Vec::new()
+                }
+                sign_transaction_request::SigningMethod::Kms(kms_method) => {
+                    // The KMS signs remotely, so its signatures are applied here directly
+                    for (index, signature) in self
+                        .kms_signatures(&kms_method, &kms_caller_id, &solana_transaction)
+                        .await?
+                    {
+                        solana_transaction.signatures[index] = signature;
+                        signatures_applied += 1;
+                    }
+                    Vec::new()
+                }
+                sign_transaction_request::SigningMethod::Vault(vault_method) => {
+                    // Vault signs remotely, so its signatures are applied here directly
+                    for (index, signature) in self
+                        .vault_signatures(&vault_method, &solana_transaction)
+                        .await?
+                    {
+                        solana_transaction.signatures[index] = signature;
+                        signatures_applied += 1;
+                    }
+                    Vec::new()
+                }
+            },
+            None => return Err(Status::invalid_argument("Signing method is required")),
         };
 
-        // CRITICAL: Use Solana SDK to compile the transaction
-        // This handles all the complexity of account deduplication, signing requirements, etc.
-        let message =
-            Message::new_with_blockhash(&sdk_instructions, Some(&fee_payer), &recent_blockhash);
+        // Sign with each keypair that has a matching account in the transaction
+        for keypair in &keypairs {
+            if let Some(account_index) = solana_transaction
+                .message
+                .account_keys
+                .iter()
+                .take(solana_transaction.signatures.len())
+                .position(|key| key == &keypair.pubkey())
+            {
+                // Apply signature for this account
+                let signature = keypair.sign_message(&solana_transaction.message_data());
+                solana_transaction.signatures[account_index] = signature;
+                signatures_applied += 1;
+            }
+        }
+
+        if signatures_applied == 0 {
+            return Err(Status::invalid_argument("No matching accounts found for provided keys"));
+        }
 
-        // Serialize the compiled message for transport
-        let transaction_bytes = bincode::serialize(&message)
-            .map_err(|e| Status::internal(format!("Transaction serialization failed: {e}")))?;
+        // Update transaction with signatures and the per-signer status
+        let signers = signers_of(
+            &solana_transaction.message.header,
+            &solana_transaction.message.account_keys,
+            &solana_transaction.signatures,
+        );
+        transaction.signing_status = signing_status(&signers);
+        transaction.signatures = solana_transaction
+            .signatures
+            .iter()
+            .filter(|sig| **sig != Signature::default())
+            .map(std::string::ToString::to_string)
+            .collect();
 
-        // Encode as base58 for proto transport
-        let transaction_data = bs58::encode(&transaction_bytes).into_string();
+        // Determine new state based on signature completeness
+        let new_state = if signers.iter().all(|signer| signer.signed) {
+            TransactionState::FullySigned
+        } else {
+            TransactionState::PartiallySigned
+        };
 
-        // Validate state transition DRAFT -> COMPILED
-        validate_state_transition(current_state, TransactionState::Compiled)
+        // Validate state transition
+        validate_state_transition(current_state, new_state)
             .map_err(|e| Status::internal(format!("State transition validation failed: {e}")))?;
 
-        // Update transaction with compiled data and metadata
-        transaction.data = transaction_data;
-        transaction.state = TransactionState::Compiled.into();
-        transaction.fee_payer = req.fee_payer;
-        transaction.recent_blockhash = recent_blockhash.to_string();
+        transaction.state = new_state.into();
 
-        // Validate the updated transaction consistency
-        validate_transaction_state_consistency(&transaction).map_err(|e| {
-            Status::internal(format!("Compiled transaction validation failed: {e}"))
+        // Update the transaction data with signed transaction
+        let signed_transaction_bytes = bincode::serialize(&solana_transaction).map_err(|e| {
+            Status::internal(format!("Failed to serialize signed transaction: {e}"))
         })?;
+        transaction.data = bs58::encode(&signed_transaction_bytes).into_string();
 
-        Ok(Response::new(CompileTransactionResponse {
+        Ok(Response::new(SignTransactionResponse {
             transaction: Some(transaction),
         }))
     }
 
-    /// Estimates compute units and transaction fees for a compiled transaction
-    ///
-    /// This method provides accurate resource consumption estimates by simulating
-    /// transaction execution without actually submitting to the blockchain.
-    ///
-    /// Estimation Strategy:
-    /// 1. Primary: Uses RPC `simulate_transaction_with_config` for real execution analysis
-    /// 2. Fallback: Instruction-count-based heuristics if simulation fails
-    /// 3. Handles both None and 0 compute units with reasonable defaults
-    ///
-    /// Compute Unit Estimation:
-    /// - Real simulation: Uses actual execution consumption when available
-    /// - Fallback formula: instructions * 50,000 CU (realistic per-instruction average)
-    /// - Bounds: minimum 200,000 CU, maximum 1,400,000 CU (network limits)
-    ///
-    /// Fee Calculation:
-    /// - Base fee: 5,000 lamports (standard transaction fee)
-    /// - Priority fee: `compute_units` * `compute_unit_price` (from transaction config)
-    /// - Caps priority fee at 1,000,000 lamports to prevent excessive costs
-    /// - Fallback priority fee: 1,000 lamports for network prioritization
+    /// Lists the Ledger devices connected to the hardware wallet agent
+    async fn list_hardware_wallets(
+        &self,
+        _request: Request<ListHardwareWalletsRequest>,
+    ) -> Result<Response<ListHardwareWalletsResponse>, Status> {
+        self.ensure_hardware_wallet_available()?;
+
+        let agent = Arc::clone(&self.hardware_wallet);
+        let devices = tokio::task::spawn_blocking(move || agent.list_devices())
+            .await
+            .map_err(|e| Status::internal(format!("Hardware wallet task failed: {e}")))?
+            .map_err(Status::unavailable)?;
+
+        Ok(Response::new(ListHardwareWalletsResponse {
+            wallets: devices
+                .into_iter()
+                .map(|device| HardwareWallet {
+                    device_id: device.id,
+                    model: device.model,
+                    app_version: device.app_version,
+                    blind_signing_enabled: device.blind_signing_enabled,
+                })
+                .collect(),
+            blind_signing_allowed: self.hardware_wallet.allow_blind_signing(),
+        }))
+    }
+
+    /// Lists the signers a transaction requires and which of them have signed
     ///
-    /// The estimation accuracy helps users avoid transaction failures due to
-    /// insufficient fees or compute budget exhaustion.
-    async fn estimate_transaction(
+    /// The signer set comes from the compiled message header (drafts are compiled
+    /// locally), so a coordinator can see which parties still need to sign a
+    /// PARTIALLY_SIGNED transaction before forwarding it.
+    async fn get_required_signers(
         &self,
-        request: Request<EstimateTransactionRequest>,
-    ) -> Result<Response<EstimateTransactionResponse>, Status> {
+        request: Request<GetRequiredSignersRequest>,
+    ) -> Result<Response<GetRequiredSignersResponse>, Status> {
         let req = request.into_inner();
         let transaction = req
             .transaction
             .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
 
-        // Validate current state allows estimation
-        let current_state = transaction.state();
-        validate_operation_allowed_for_state(current_state, "estimate")
-            .map_err(Status::failed_precondition)?;
-
-        // Validate transaction state consistency
-        validate_transaction_state_consistency(&transaction)
-            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
+        let signers =
+            required_signers(&transaction, &req.fee_payer).map_err(Status::invalid_argument)?;
+        let outstanding_signers: Vec<String> = signers
+            .iter()
+            .filter(|signer| !signer.signed)
+            .map(|signer| signer.address.clone())
+            .collect();
 
-        // Ensure transaction has compiled data
-        if transaction.data.is_empty() {
-            return Err(Status::invalid_argument("Transaction must be compiled before estimation"));
-        }
+        debug!(
+            state = ?transaction.state(),
+            required = signers.len(),
+            outstanding = outstanding_signers.len(),
+            "Derived required signers"
+        );
 
-        // Deserialize the compiled transaction data
-        let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
-            Status::invalid_argument(format!("Failed to decode transaction data: {e}"))
-        })?;
+        Ok(Response::new(GetRequiredSignersResponse {
+            fully_signed: outstanding_signers.is_empty(),
+            signers,
+            outstanding_signers,
+        }))
+    }
 
-        let message: Message = bincode::deserialize(&transaction_data).map_err(|e| {
-            Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
-        })?;
+    /// Validates a transaction offline, before any network call
+    ///
+    /// Reports the serialized size against the 1232-byte packet limit, the signers and
+    /// accounts the message needs, and accounts repeated within an instruction. Findings
+    /// come back as diagnostics with suggested fixes rather than as RPC errors, so a
+    /// caller can collect every problem from a single call.
+    async fn validate_transaction(
+        &self,
+        request: Request<ValidateTransactionRequest>,
+    ) -> Result<Response<ValidateTransactionResponse>, Status> {
+        let req = request.into_inner();
+        let transaction = req
+            .transaction
+            .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
 
-        // Create an unsigned transaction for simulation
-        let solana_transaction = SolanaTransaction::new_unsigned(message);
+        let report = validate_transaction(&transaction, &req.fee_payer);
 
-        // Get commitment level for estimation simulation
-        let commitment = commitment_level_to_config(req.commitment_level);
+        debug!(
+            state = ?transaction.state(),
+            serialized_size = report.serialized_size,
+            diagnostics = report.diagnostics.len(),
+            "Validated transaction"
+        );
 
-        // Use simulation to get accurate compute unit estimation with configurable commitment level
-        let (compute_units, _logs) = if let Ok(simulation_result) =
-            self.rpc_client.simulate_transaction_with_config(
-                &solana_transaction,
-                solana_client::rpc_config::RpcSimulateTransactionConfig {
-                    sig_verify: false,
-                    replace_recent_blockhash: false,
-                    commitment: Some(commitment),
-                    encoding: None,
-                    accounts: None,
-                    min_context_slot: None,
-                    inner_instructions: false,
-                },
-            ) {
-            // Handle both None and 0 cases by providing reasonable fallback
-            let compute_units = match simulation_result.value.units_consumed {
-                Some(units) if units > 0 => units,
-                _ => {
-                    // Fallback estimation based on instruction count
-                    let instruction_count = transaction.instructions.len() as u64;
-                    (instruction_count * 50_000).clamp(200_000, 1_400_000)
-                }
-            };
-            let logs = simulation_result.value.logs.unwrap_or_default();
-            (compute_units, logs)
-        } else {
-            // Fallback to basic estimation if simulation fails
-            let instruction_count = transaction.instructions.len() as u64;
-            let estimated_compute_units = (instruction_count * 50_000).clamp(200_000, 1_400_000);
-            (estimated_compute_units, vec![])
-        };
+        Ok(Response::new(ValidateTransactionResponse {
+            valid: report.is_valid(),
+            serialized_size: u32::try_from(report.serialized_size).unwrap_or(u32::MAX),
+            max_size: u32::try_from(MAX_TRANSACTION_SIZE).unwrap_or(u32::MAX),
+            remaining_bytes: i32::try_from(report.remaining_bytes()).unwrap_or(i32::MIN),
+            required_signers: u32::try_from(report.required_signers).unwrap_or(u32::MAX),
+            account_count: u32::try_from(report.account_count).unwrap_or(u32::MAX),
+            duplicate_accounts: report
+                .duplicate_accounts
+                .iter()
+                .map(ToString::to_string)
+                .collect(),
+            diagnostics: report.diagnostics,
+        }))
+    }
 
-        // Calculate fee estimation
-        let base_fee_lamports = 5_000; // Base transaction fee
-        let compute_unit_price = transaction
-            .config
-            .as_ref()
-            .map_or(0, |config| config.compute_unit_price);
+    /// Flattens a v0 transaction into a legacy transaction
+    ///
+    /// Lookup tables are read at the requested commitment and their accounts listed in the
+    /// legacy message. When that does not fit, or a table cannot be resolved, the response
+    /// explains why instead of failing the call.
+    async fn convert_transaction_version(
+        &self,
+        request: Request<ConvertTransactionVersionRequest>,
+    ) -> Result<Response<ConvertTransactionVersionResponse>, Status> {
+        self.feature_flags
+            .ensure_enabled(FeatureFlag::V0Transactions)?;
+        let req = request.into_inner();
+        if req.encoded_transaction.is_empty() {
+            return Err(Status::invalid_argument("Encoded transaction is required"));
+        }
 
-        // Priority fee calculation based on compute units and price
-        let priority_fee = if compute_unit_price > 0 {
-            (compute_units * compute_unit_price).min(1_000_000) // Cap priority fee
-        } else {
-            // Default priority fee estimation based on network conditions
-            1_000
+        let bytes = match req.encoding() {
+            TransactionEncoding::Unspecified | TransactionEncoding::Base58 => {
+                bs58::decode(&req.encoded_transaction)
+                    .into_vec()
+                    .map_err(|e| {
+                        Status::invalid_argument(format!("Invalid base58 transaction: {e}"))
+                    })?
+            }
+            TransactionEncoding::Base64 => {
+                STANDARD.decode(&req.encoded_transaction).map_err(|e| {
+                    Status::invalid_argument(format!("Invalid base64 transaction: {e}"))
+                })?
+            }
+            TransactionEncoding::JsonParsed => {
+                return Err(Status::invalid_argument(
+                    "Transactions must be given in BASE58 or BASE64 encoding",
+                ));
+            }
+        };
+        let versioned_transaction: VersionedTransaction =
+            bincode::deserialize(&bytes).map_err(|e| {
+                Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
+            })?;
+        let signature_count = versioned_transaction
+            .signatures
+            .iter()
+            .filter(|signature| **signature != Signature::default())
+            .count();
+
+        let message = match versioned_transaction.message {
+            // Legacy messages need no conversion and keep their signatures
+            VersionedMessage::Legacy(message) => {
+                let solana_transaction = SolanaTransaction {
+                    signatures: versioned_transaction.signatures,
+                    message,
+                };
+                let serialized_size = bincode::serialized_size(&solana_transaction).unwrap_or(0);
+                return Ok(Response::new(ConvertTransactionVersionResponse {
+                    converted: true,
+                    account_count: u32::try_from(solana_transaction.message.account_keys.len())
+                        .unwrap_or(u32::MAX),
+                    serialized_size: u32::try_from(serialized_size).unwrap_or(u32::MAX),
+                    transaction: Some(legacy_transaction_to_proto(&solana_transaction)?),
+                    ..Default::default()
+                }));
+            }
+            VersionedMessage::V0(message) => message,
         };
 
-        let total_fee = base_fee_lamports + priority_fee;
+        let table_keys: Vec<Pubkey> = message
+            .address_table_lookups
+            .iter()
+            .map(|lookup| lookup.account_key)
+            .collect();
+        let mut tables = HashMap::new();
+        if !table_keys.is_empty() {
+            let commitment = commitment_level_to_config(req.commitment_level);
+            let accounts = {
+                let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+                self.rpc_router
+                    .for_commitment(commitment)
+                    .get_multiple_accounts_with_commitment(&table_keys, commitment)
+                    .map_err(|e| {
+                        Status::internal(format!("Failed to fetch address lookup tables: {e}"))
+                    })?
+                    .value
+            };
+            for (key, account) in table_keys.iter().zip(accounts) {
+                // Missing or malformed tables are reported as blockers by the conversion
+                let addresses = account
+                    .filter(|account| account.owner == address_lookup_table::program::id())
+                    .and_then(|account| lookup_table_addresses(&account.data).ok());
+                if let Some(addresses) = addresses {
+                    tables.insert(*key, addresses);
+                }
+            }
+        }
 
-        Ok(Response::new(EstimateTransactionResponse {
-            compute_units,
-            fee_lamports: total_fee,
-            priority_fee,
+        let conversion = flatten_v0_message(&message, &tables);
+        let transaction = conversion
+            .message
+            .map(|message| legacy_transaction_to_proto(&SolanaTransaction::new_unsigned(message)))
+            .transpose()?;
+
+        Ok(Response::new(ConvertTransactionVersionResponse {
+            converted: transaction.is_some(),
+            transaction,
+            blockers: conversion.blockers,
+            account_count: u32::try_from(conversion.account_count).unwrap_or(u32::MAX),
+            serialized_size: u32::try_from(conversion.serialized_size).unwrap_or(u32::MAX),
+            dropped_signatures: u32::try_from(signature_count).unwrap_or(u32::MAX),
         }))
     }
 
-    /// Simulates a compiled transaction execution without blockchain submission
-    ///
-    /// This method provides a "dry run" execution of the transaction to predict
-    /// outcomes, catch errors early, and analyze execution logs before submission.
-    ///
-    /// Simulation Benefits:
-    /// 1. Error Detection: Catches failures before expensive submission
-    /// 2. Log Analysis: Provides execution logs for debugging
-    /// 3. State Validation: Confirms transaction will succeed given current blockchain state
-    /// 4. Cost Prevention: Avoids wasted transaction fees on failing operations
+    /// Diffs two transactions in any state without touching the network
     ///
-    /// Simulation Configuration:
-    /// - `sig_verify`: false (bypasses signature validation for simulation)
-    /// - `replace_recent_blockhash`: false (uses transaction's blockhash)
-    /// - commitment: configurable (matches user's desired confirmation level)
-    /// - `inner_instructions`: false (reduces simulation overhead)
-    ///
-    /// Response Format:
-    /// - success: boolean indicating if transaction would succeed
-    /// - error: detailed error message if simulation fails
-    /// - logs: program execution logs for analysis and debugging
+    /// Drafts are compiled locally, so a draft can be compared with its compiled or signed
+    /// form to see why a re-compile changed the account list or required signers.
+    async fn compare_transactions(
+        &self,
+        request: Request<CompareTransactionsRequest>,
+    ) -> Result<Response<CompareTransactionsResponse>, Status> {
+        let req = request.into_inner();
+        let base = req
+            .base
+            .ok_or_else(|| Status::invalid_argument("Base transaction is required"))?;
+        let target = req
+            .target
+            .ok_or_else(|| Status::invalid_argument("Target transaction is required"))?;
+
+        let comparison = compare_transactions(&base, &target, &req.fee_payer)
+            .map_err(Status::invalid_argument)?;
+
+        debug!(
+            identical = comparison.identical,
+            account_changes = comparison.account_changes.len(),
+            instruction_changes = comparison.instruction_changes.len(),
+            "Compared transactions"
+        );
+
+        Ok(Response::new(comparison))
+    }
+
+    /// Renders a transaction as a human-readable summary for wallet approval screens
     ///
-    /// Note: Simulation uses unsigned transaction since signatures aren't validated.
-    /// This allows simulation of partially signed transactions during development.
-    async fn simulate_transaction(
+    /// Instructions are decoded offline and each is given a one-line summary. Unless
+    /// `skip_simulation` is set, the writable accounts are read and the transaction is
+    /// simulated against the latest blockhash without checking signatures, so it can be
+    /// described before it is signed. The SOL and token balance changes are then reported,
+    /// with token amounts scaled by the decimals of their mints. A failed simulation is
+    /// reported in the response rather than as an error, so it can still be shown.
+    async fn describe_transaction(
         &self,
-        request: Request<SimulateTransactionRequest>,
-    ) -> Result<Response<SimulateTransactionResponse>, Status> {
+        request: Request<DescribeTransactionRequest>,
+    ) -> Result<Response<DescribeTransactionResponse>, Status> {
+        let localizer = self.message_catalog.localizer(request.metadata());
         let req = request.into_inner();
         let transaction = req
             .transaction
             .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
 
-        // Validate current state allows simulation
-        let current_state = transaction.state();
-        validate_operation_allowed_for_state(current_state, "simulate")
-            .map_err(Status::failed_precondition)?;
-
-        // Validate transaction state consistency
+        // Only a compiled message fixes the accounts and instructions an approver signs
+        if transaction.state() == TransactionState::Draft {
+            return Err(Status::failed_precondition(
+                "DRAFT transactions must be compiled before they can be described",
+            ));
+        }
         validate_transaction_state_consistency(&transaction)
             .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
 
-        // Ensure transaction has compiled data
-        if transaction.data.is_empty() {
-            return Err(Status::invalid_argument("Transaction must be compiled before simulation"));
-        }
-
-        // Deserialize the compiled transaction data
-        let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
-            Status::invalid_argument(format!("Failed to decode transaction data: {e}"))
-        })?;
-
-        let message: Message = bincode::deserialize(&transaction_data).map_err(|e| {
-            Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
-        })?;
-
-        // Create an unsigned transaction for simulation
-        let solana_transaction = SolanaTransaction::new_unsigned(message);
+        let options = SimulationOptions {
+            sig_verify: false,
+            replace_recent_blockhash: true,
+        };
+        let solana_transaction =
+            simulation_transaction(&transaction, "", options).map_err(Status::invalid_argument)?;
+        let message = &solana_transaction.message;
+        let decoded = decode_compiled_instructions(&message.account_keys, &message.instructions);
 
-        // Get commitment level for simulation
-        let commitment = commitment_level_to_config(req.commitment_level);
+        let mut response = DescribeTransactionResponse {
+            fee_payer: message
+                .account_keys
+                .first()
+                .map(ToString::to_string)
+                .unwrap_or_default(),
+            accounts: describe_accounts(message),
+            ..Default::default()
+        };
 
-        // Simulate the transaction using RPC with configurable commitment level
-        match self.rpc_client.simulate_transaction_with_config(
-            &solana_transaction,
-            solana_client::rpc_config::RpcSimulateTransactionConfig {
-                sig_verify: false,
-                replace_recent_blockhash: false,
+        let mut decimals = HashMap::new();
+        if !req.skip_simulation {
+            let commitment = commitment_level_to_config(req.commitment_level);
+            let rpc_client = self.rpc_router.for_commitment(commitment);
+            let account_config = RpcAccountInfoConfig {
+                encoding: Some(UiAccountEncoding::Base64Zstd),
+                data_slice: None,
                 commitment: Some(commitment),
-                encoding: None,
-                accounts: None,
                 min_context_slot: None,
-                inner_instructions: false,
-            },
-        ) {
-            Ok(simulation_result) => {
-                let success = simulation_result.value.err.is_none();
-                let error = simulation_result
+            };
+
+            // Writable accounts are read before simulating so their balance changes can be
+            // reported
+            let addresses: Vec<Pubkey> = writable_accounts(message)
+                .into_iter()
+                .take(MAX_SIMULATED_ACCOUNTS)
+                .collect();
+            let pre_accounts = {
+                let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+                rpc_client
+                    .get_multiple_accounts_with_config(&addresses, account_config.clone())
+                    .map_err(|e| Status::internal(format!("Failed to fetch account state: {e}")))?
                     .value
-                    .err
-                    .map(|err| format!("{err:?}"))
-                    .unwrap_or_default();
-                let logs = simulation_result.value.logs.unwrap_or_default();
+            };
 
-                Ok(Response::new(SimulateTransactionResponse {
-                    success,
-                    error,
-                    logs,
-                }))
+            let simulation = {
+                let _permit = self.rpc_permit(RpcCallClass::Simulation).await?;
+                rpc_client.simulate_transaction_with_config(
+                    &solana_transaction,
+                    solana_client::rpc_config::RpcSimulateTransactionConfig {
+                        sig_verify: options.sig_verify,
+                        replace_recent_blockhash: options.replace_recent_blockhash,
+                        commitment: Some(commitment),
+                        encoding: None,
+                        accounts: Some(RpcSimulateTransactionAccountsConfig {
+                            encoding: Some(UiAccountEncoding::Base64),
+                            addresses: addresses.iter().map(ToString::to_string).collect(),
+                        }),
+                        min_context_slot: None,
+                        inner_instructions: false,
+                    },
+                )
+            };
+            match simulation {
+                Ok(simulation) => {
+                    let result = simulation.value;
+                    response.simulated = true;
+                    response.simulation_success = result.err.is_none();
+                    response.simulation_error =
+                        result.err.map(|err| format!("{err:?}")).unwrap_or_default();
+                    // Nodes only report account state for simulations that succeeded
+                    if let Some(post) = result.accounts {
+                        response.lamport_movements = lamport_movements(&simulated_accounts(
+                            &addresses,
+                            &pre_accounts,
+                            &post,
+                        ));
+                        response.token_movements =
+                            token_movements(&addresses, &pre_accounts, &post);
+                    }
+                }
+                Err(e) => response.simulation_error = format!("Simulation failed: {e}"),
+            }
+
+            let mints = referenced_mints(&decoded, &response.token_movements);
+            if !mints.is_empty() {
+                let _permit = self.rpc_permit(RpcCallClass::AccountRead).await?;
+                let mint_accounts = rpc_client
+                    .get_multiple_accounts_with_config(&mints, account_config)
+                    .map_err(|e| Status::internal(format!("Failed to fetch mints: {e}")))?
+                    .value;
+                for (mint, account) in mints.iter().zip(mint_accounts) {
+                    if let Some(mint_decimals) = account.as_ref().and_then(mint_decimals) {
+                        decimals.insert(mint.to_string(), mint_decimals);
+                    }
+                }
             }
-            Err(e) => {
-                // Simulation failed - this could be due to network issues or invalid transaction
-                Ok(Response::new(SimulateTransactionResponse {
-                    success: false,
-                    error: format!("Simulation failed: {e}"),
-                    logs: vec![],
-                }))
+            for movement in &mut response.token_movements {
+                movement.decimals = decimals.get(&movement.mint).copied().unwrap_or_default();
             }
         }
+        response.instructions = describe_instructions(message, &decoded, &decimals, &localizer);
+
+        debug!(
+            instructions = response.instructions.len(),
+            simulated = response.simulated,
+            lamport_movements = response.lamport_movements.len(),
+            token_movements = response.token_movements.len(),
+            locale = localizer.locale(),
+            "Described transaction"
+        );
+
+        let mut response = Response::new(response);
+        localizer.set_content_language(&mut response);
+        Ok(response)
     }
 
-    /// Signs a compiled transaction with cryptographic signatures for authorization
-    ///
-    /// State Transition: COMPILED → `PARTIALLY_SIGNED` or `FULLY_SIGNED`
-    ///
-    /// This method applies cryptographic signatures to authorize transaction execution.
-    /// It supports multiple signing methods and automatically determines completion state.
-    ///
-    /// Signing Process:
-    /// 1. Validates transaction state allows signing (must be COMPILED or `PARTIALLY_SIGNED`)
-    /// 2. Deserializes compiled transaction data back to Solana SDK format
-    /// 3. Processes signing method (currently supports private key signing)
-    /// 4. Matches provided keys with transaction's required signers
-    /// 5. Applies signatures for matching accounts only
-    /// 6. Determines final state based on signature completeness
-    /// 7. Re-serializes signed transaction for storage
-    ///
-    /// State Determination Logic:
-    /// - `FULLY_SIGNED`: All required signatures present (ready for submission)
-    /// - `PARTIALLY_SIGNED`: Some signatures present, more needed
-    ///
-    /// Security Features:
-    /// - Only signs for accounts present in transaction (prevents signature reuse)
-    /// - Validates private key format (64 bytes, Base58 encoded)
-    /// - Signature verification through Solana SDK cryptographic functions
-    /// - No signature storage of private keys (used and discarded)
-    ///
-    /// Signing Methods:
-    /// - `PrivateKeys`: Direct private key signing (current implementation)
-    /// - Seeds: Deterministic key derivation (not yet implemented)
+    /// Mints a single-use token authorizing one `SubmitTransaction` of the given message
     ///
-    /// The multi-step signing support enables complex workflows like multi-signature
-    /// transactions and hardware wallet integration.
-    async fn sign_transaction(
+    /// The token is bound to the hash of the compiled message, which signing does not
+    /// change, so it may be minted before the transaction is signed. Only backends holding
+    /// the admin token may mint; the untrusted client only ever sees the minted token.
+    async fn mint_submission_token(
         &self,
-        request: Request<SignTransactionRequest>,
-    ) -> Result<Response<SignTransactionResponse>, Status> {
+        request: Request<MintSubmissionTokenRequest>,
+    ) -> Result<Response<MintSubmissionTokenResponse>, Status> {
+        self.auth.require_admin(request.metadata())?;
         let req = request.into_inner();
-        let mut transaction = req
+        let transaction = req
             .transaction
             .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
 
-        // Validate current state allows signing
-        let current_state = transaction.state();
-        validate_operation_allowed_for_state(current_state, "sign")
-            .map_err(Status::failed_precondition)?;
-
-        // Validate transaction state consistency
+        if transaction.state() == TransactionState::Draft {
+            return Err(Status::failed_precondition(
+                "DRAFT transactions must be compiled before a submission token can be minted",
+            ));
+        }
         validate_transaction_state_consistency(&transaction)
             .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
 
-        // Ensure transaction has compiled data
-        if transaction.data.is_empty() {
-            return Err(Status::invalid_argument("Transaction must be compiled before signing"));
-        }
+        let ttl_seconds = resolve_token_ttl(req.ttl_seconds).map_err(Status::invalid_argument)?;
+        let solana_transaction =
+            simulation_transaction(&transaction, "", SimulationOptions::default())
+                .map_err(Status::invalid_argument)?;
+        let minted = self
+            .submission_tokens
+            .mint(&solana_transaction.message.hash().to_string(), ttl_seconds);
 
-        // Deserialize the compiled transaction data
-        let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
-            Status::invalid_argument(format!("Failed to decode transaction data: {e}"))
-        })?;
+        info!(
+            message_hash = %minted.message_hash,
+            expires_at = minted.expires_at,
+            "🎟️ Minted submission token"
+        );
 
-        let message: Message = bincode::deserialize(&transaction_data).map_err(|e| {
-            Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
-        })?;
+        Ok(Response::new(MintSubmissionTokenResponse {
+            token: minted.token,
+            message_hash: minted.message_hash,
+            expires_at: minted.expires_at,
+        }))
+    }
 
-        // Process signing method and apply signatures
-        let keypairs = match req.signing_method {
-            Some(signing_method) => match signing_method {
-                sign_transaction_request::SigningMethod::PrivateKeys(private_keys_method) => {
-                    // Parse private keys into keypairs
-                    let mut keypairs = Vec::new();
-                    for private_key_str in &private_keys_method.private_keys {
-                        let private_key_bytes =
-                            bs58::decode(private_key_str).into_vec().map_err(|e| {
-                                Status::invalid_argument(format!("Invalid private key format: {e}"))
-                            })?;
+    /// Splits an instruction list into the fewest DRAFT transactions that fit the limits
+    ///
+    /// Works offline: sizes come from compiling each batch locally for the fee payer, and
+    /// compute comes from the caller's estimates (or the per-instruction default).
+    async fn split_instructions(
+        &self,
+        request: Request<SplitInstructionsRequest>,
+    ) -> Result<Response<SplitInstructionsResponse>, Status> {
+        let req = request.into_inner();
 
-                        if private_key_bytes.len() != 64 {
-                            return Err(Status::invalid_argument("Private key must be 64 bytes"));
-                        }
+        if req.fee_payer.is_empty() {
+            return Err(Status::invalid_argument("fee_payer is required"));
+        }
+        let fee_payer = Pubkey::from_str(&req.fee_payer)
+            .map_err(|e| Status::invalid_argument(format!("Invalid fee_payer: {e}")))?;
+        let sdk_instructions = req
+            .instructions
+            .iter()
+            .map(|proto_ix| proto_instruction_to_sdk(proto_ix.clone()))
+            .collect::<Result<Vec<Instruction>, String>>()
+            .map_err(|e| Status::invalid_argument(format!("Invalid instruction: {e}")))?;
 
-                        let keypair = Keypair::from_bytes(&private_key_bytes).map_err(|e| {
-                            Status::invalid_argument(format!("Invalid private key: {e}"))
-                        })?;
-                        keypairs.push(keypair);
-                    }
-                    keypairs
-                }
-                sign_transaction_request::SigningMethod::Seeds(_seed_method) => {
-                    // Seed-based signing not implemented in current version
-                    return Err(Status::unimplemented("Seed-based signing not available"));
-                }
-            },
-            None => return Err(Status::invalid_argument("Signing method is required")),
+        let to_index = |index: u32| usize::try_from(index).unwrap_or(usize::MAX);
+        let options = SplitOptions {
+            atomic_ranges: req
+                .atomic_ranges
+                .iter()
+                .map(|range| to_index(range.start)..to_index(range.end))
+                .collect(),
+            dependencies: req
+                .dependencies
+                .iter()
+                .map(|dependency| {
+                    (to_index(dependency.instruction_index), to_index(dependency.depends_on))
+                })
+                .collect(),
+            compute_units: req.compute_units,
+            max_compute_units: req.max_compute_units,
+            reserve_compute_budget: req.reserve_compute_budget,
         };
+        let batches = split_instructions(&sdk_instructions, &fee_payer, &options)
+            .map_err(Status::invalid_argument)?;
+
+        let to_u32 = |index: usize| u32::try_from(index).unwrap_or(u32::MAX);
+        let transactions: Vec<SplitTransaction> = batches
+            .into_iter()
+            .map(|batch| SplitTransaction {
+                transaction: Some(Transaction {
+                    instructions: req.instructions[batch.instructions.clone()].to_vec(),
+                    state: TransactionState::Draft.into(),
+                    fee_payer: req.fee_payer.clone(),
+                    ..Default::default()
+                }),
+                instruction_indices: batch.instructions.map(to_u32).collect(),
+                size_bytes: to_u32(batch.size_bytes),
+                compute_units: batch.compute_units,
+                depends_on: batch.depends_on.into_iter().map(to_u32).collect(),
+            })
+            .collect();
 
-        // Create Solana transaction with message and apply signatures
-        let mut solana_transaction = SolanaTransaction::new_unsigned(message);
+        debug!(
+            instructions = req.instructions.len(),
+            transactions = transactions.len(),
+            "Split instructions"
+        );
 
-        // Sign with each keypair that has a matching account in the transaction
-        let mut signatures_applied = 0;
-        for keypair in &keypairs {
-            if let Some(account_index) = solana_transaction
-                .message
-                .account_keys
-                .iter()
-                .position(|key| key == &keypair.pubkey())
-            {
-                // Apply signature for this account
-                let signature = keypair.sign_message(&solana_transaction.message_data());
-                solana_transaction.signatures[account_index] = signature;
-                signatures_applied += 1;
-            }
-        }
+        Ok(Response::new(SplitInstructionsResponse { transactions }))
+    }
 
-        if signatures_applied == 0 {
-            return Err(Status::invalid_argument("No matching accounts found for provided keys"));
+    /// Packages a COMPILED or PARTIALLY_SIGNED transaction for an air-gapped signer
+    ///
+    /// Refuses transactions whose blockhash has already expired, since a device could
+    /// never return them in time. The reported expiry is the latest blockhash's last valid
+    /// height, an upper bound for the transaction's own blockhash.
+    async fn export_transaction_bundle(
+        &self,
+        request: Request<ExportTransactionBundleRequest>,
+    ) -> Result<Response<ExportTransactionBundleResponse>, Status> {
+        let req = request.into_inner();
+        let transaction = req
+            .transaction
+            .as_ref()
+            .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
+
+        let state = transaction.state();
+        if !matches!(state, TransactionState::Compiled | TransactionState::PartiallySigned) {
+            return Err(Status::failed_precondition(format!(
+                "Only COMPILED or PARTIALLY_SIGNED transactions can be exported, got {state:?}"
+            )));
         }
+        let solana_transaction = if state == TransactionState::Compiled {
+            SolanaTransaction::new_unsigned(decode_data::<Message>(&transaction.data).map_err(
+                |e| Status::invalid_argument(format!("Failed to deserialize transaction: {e}")),
+            )?)
+        } else {
+            decode_data::<SolanaTransaction>(&transaction.data).map_err(|e| {
+                Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
+            })?
+        };
 
-        // Update transaction with signatures
-        transaction.signatures = solana_transaction
+        let commitment = commitment_level_to_config(req.commitment_level);
+        let blockhash_valid = self
+            .rpc_client
+            .is_blockhash_valid(&solana_transaction.message.recent_blockhash, commitment)
+            .map_err(|e| Status::internal(format!("Failed to check blockhash: {e}")))?;
+        if !blockhash_valid {
+            return Err(Status::failed_precondition(
+                "Transaction blockhash has expired; recompile before exporting",
+            ));
+        }
+        let _permit = self.rpc_permit(RpcCallClass::Blockhash).await?;
+        let (_, last_valid_block_height) = self
+            .rpc_client
+            .get_latest_blockhash_with_commitment(commitment)
+            .map_err(|e| Status::internal(format!("Failed to get latest blockhash: {e}")))?;
+
+        let format = match req.format() {
+            TransactionBundleFormat::Unspecified => TransactionBundleFormat::Json,
+            format => format,
+        };
+        let bundle =
+            export_bundle(&solana_transaction, &transaction.instructions, last_valid_block_height);
+        let outstanding_signers = bundle
             .signatures
             .iter()
-            .filter(|sig| **sig != Signature::default())
-            .map(std::string::ToString::to_string)
+            .filter(|entry| entry.signature.is_empty())
+            .map(|entry| entry.signer.clone())
             .collect();
+        let encoded = encode_bundle(&bundle, format).map_err(Status::internal)?;
 
-        // Determine new state based on signature completeness
-        let required_signatures =
-            solana_transaction.message.header.num_required_signatures as usize;
-        let provided_signatures = transaction.signatures.len();
+        info!(
+            format = ?format,
+            bytes = encoded.len(),
+            last_valid_block_height,
+            "📦 Exported transaction bundle"
+        );
+
+        Ok(Response::new(ExportTransactionBundleResponse {
+            bundle: encoded,
+            format: format.into(),
+            last_valid_block_height,
+            outstanding_signers,
+        }))
+    }
+
+    /// Reads a bundle returned by a signing device back into a signed transaction
+    ///
+    /// The transaction is rebuilt from the bundled message and every signature is verified
+    /// against it; instruction descriptions are carried over by position.
+    async fn import_transaction_bundle(
+        &self,
+        request: Request<ImportTransactionBundleRequest>,
+    ) -> Result<Response<ImportTransactionBundleResponse>, Status> {
+        let req = request.into_inner();
+        if req.bundle.is_empty() {
+            return Err(Status::invalid_argument("Bundle is required"));
+        }
+
+        let bundle = decode_bundle(&req.bundle, req.format()).map_err(Status::invalid_argument)?;
+        let solana_transaction = import_bundle(&bundle).map_err(Status::invalid_argument)?;
 
-        let new_state = if provided_signatures >= required_signatures {
+        let message = &solana_transaction.message;
+        let signers =
+            signers_of(&message.header, &message.account_keys, &solana_transaction.signatures);
+        if !signers.iter().any(|signer| signer.signed) {
+            return Err(Status::invalid_argument("Bundle carries no signatures"));
+        }
+        let state = if signers.iter().all(|signer| signer.signed) {
             TransactionState::FullySigned
         } else {
             TransactionState::PartiallySigned
         };
 
-        // Validate state transition
-        validate_state_transition(current_state, new_state)
-            .map_err(|e| Status::internal(format!("State transition validation failed: {e}")))?;
+        let instructions = message_instructions(message)
+            .into_iter()
+            .enumerate()
+            .map(|(index, instruction)| {
+                let mut proto_ix = sdk_instruction_to_proto(instruction);
+                if let Some(described) = bundle.instructions.get(index) {
+                    proto_ix.description.clone_from(&described.description);
+                }
+                proto_ix
+            })
+            .collect();
+        let data = bincode::serialize(&solana_transaction)
+            .map_err(|e| Status::internal(format!("Failed to serialize transaction: {e}")))?;
+        let outstanding_signers = signers
+            .iter()
+            .filter(|signer| !signer.signed)
+            .map(|signer| signer.address.clone())
+            .collect();
 
-        transaction.state = new_state.into();
+        let transaction = Transaction {
+            instructions,
+            state: state.into(),
+            fee_payer: message
+                .account_keys
+                .first()
+                .map(ToString::to_string)
+                .unwrap_or_default(),
+            recent_blockhash: message.recent_blockhash.to_string(),
+            data: bs58::encode(&data).into_string(),
+            signatures: solana_transaction
+                .signatures
+                .iter()
+                .filter(|sig| **sig != Signature::default())
+                .map(ToString::to_string)
+                .collect(),
+            signing_status: signing_status(&signers),
+            ..Default::default()
+        };
 
-        // Update the transaction data with signed transaction
-        let signed_transaction_bytes = bincode::serialize(&solana_transaction).map_err(|e| {
-            Status::internal(format!("Failed to serialize signed transaction: {e}"))
-        })?;
-        transaction.data = bs58::encode(&signed_transaction_bytes).into_string();
+        info!(state = ?state, "📥 Imported transaction bundle");
 
-        Ok(Response::new(SignTransactionResponse {
+        Ok(Response::new(ImportTransactionBundleResponse {
             transaction: Some(transaction),
+            outstanding_signers,
+        }))
+    }
+
+    /// Recommends a compute unit price from recent fee markets
+    ///
+    /// Aggregates getRecentPrioritizationFees over the accounts the transaction would
+    /// write-lock (fee payer and writable instruction accounts, plus any extra accounts)
+    /// into percentiles. With no accounts the estimate reflects the global fee market.
+    async fn get_priority_fee_estimate(
+        &self,
+        request: Request<GetPriorityFeeEstimateRequest>,
+    ) -> Result<Response<GetPriorityFeeEstimateResponse>, Status> {
+        let req = request.into_inner();
+
+        let accounts = write_locked_accounts(req.transaction.as_ref(), &req.accounts)
+            .map_err(Status::invalid_argument)?;
+
+        let recent_fees = self
+            .rpc_client
+            .get_recent_prioritization_fees(&accounts)
+            .map_err(|e| {
+                Status::internal(format!("Failed to get recent prioritization fees: {e}"))
+            })?;
+
+        let samples: Vec<u64> = recent_fees
+            .iter()
+            .map(|fee| fee.prioritization_fee)
+            .collect();
+        let percentiles = FeePercentiles::from_samples(&samples);
+        let suggested_compute_unit_price = percentiles.suggested();
+
+        debug!(
+            accounts = accounts.len(),
+            samples = samples.len(),
+            p50 = percentiles.p50,
+            p75 = percentiles.p75,
+            "Computed priority fee estimate"
+        );
+
+        Ok(Response::new(GetPriorityFeeEstimateResponse {
+            p25: percentiles.p25,
+            p50: percentiles.p50,
+            p75: percentiles.p75,
+            p90: percentiles.p90,
+            suggested_compute_unit_price,
+            suggested_priority_fee_lamports: priority_fee_lamports(
+                suggested_compute_unit_price,
+                req.compute_units,
+            ),
+            sample_count: u32::try_from(samples.len()).unwrap_or(u32::MAX),
+            min_slot: recent_fees
+                .iter()
+                .map(|fee| fee.slot)
+                .min()
+                .unwrap_or_default(),
+            max_slot: recent_fees
+                .iter()
+                .map(|fee| fee.slot)
+                .max()
+                .unwrap_or_default(),
+            accounts: accounts.iter().map(ToString::to_string).collect(),
         }))
     }
 
@@ -834,44 +3032,142 @@ impl TransactionService for TransactionServiceImpl {
     /// - Network Error: Connectivity, timeout, or RPC issues
     /// - Validation Error: Transaction format or content problems
     ///
+    /// Idempotency:
+    /// With an `idempotency_key` the first call reserves the key and records its response;
+    /// retried calls replay that response (even for a re-signed transaction) rather than
+    /// submitting a second transaction. Retryable failures are not recorded.
+    ///
+    /// Rebroadcasting:
+    /// With a `rebroadcast` policy a successful submission starts a background loop that
+    /// resends the same signed bytes until the transaction is confirmed or its blockhash
+    /// expires (see `rebroadcast_until_confirmed`). `MonitorTransaction` reports its progress.
+    ///
+    /// Dry runs:
+    /// With `dry_run` (or the server's `submission.dry_run`) the transaction is validated
+    /// and simulated with signature verification but never sent. The response carries a
+    /// synthetic signature, `SUBMISSION_RESULT_DRY_RUN` and the simulation's logs.
+    ///
+    /// Admission control:
+    /// Submissions beyond the in-flight limit queue for a slot (see `AdmissionController`);
+    /// the wait is reported in the `x-queue-time-ms` response header, and calls that cannot
+    /// be queued fail with an `OVERLOADED` status.
+    ///
+    /// Preflight:
+    /// The node simulates the transaction before forwarding it, at `preflight_commitment`
+    /// (default: the submission's commitment). `skip_preflight` on the request or on the
+    /// transaction's config skips that check, and `max_retries` bounds how often the node
+    /// itself resends the transaction.
+    ///
+    /// Submission tokens:
+    /// A `submission_token` from `MintSubmissionToken` must be bound to the transaction's
+    /// message and is spent just before sending (see `SubmissionTokenStore`). Dry runs only
+    /// check it. With the server's `submission.require_token` calls without one are denied.
+    ///
     /// NOTE: Successful submission only means the transaction was sent to the network,
     /// not that it was confirmed or executed. Use `MonitorTransaction` for confirmation.
     async fn submit_transaction(
         &self,
         request: Request<SubmitTransactionRequest>,
     ) -> Result<Response<SubmitTransactionResponse>, Status> {
+        // Bursts wait for a slot here instead of piling onto the RPC node
+        let admission = self.admission.admit().await.map_err(|overloaded| {
+            warn!(cause = overloaded.name(), "Submission turned away by admission control");
+            overloaded_status(overloaded, self.admission.max_wait())
+        })?;
+
+        let localizer = self.message_catalog.localizer(request.metadata());
+        let mut response = self.submit(request.into_inner()).await?;
+        record_queue_time(&mut response, admission.queued_for);
+
+        // Error messages are rendered in the caller's locale; codes stay as they are
+        let body = response.get_mut();
+        let attempt_errors = body
+            .attempts
+            .iter_mut()
+            .filter_map(|a| a.structured_error.as_mut());
+        for error in body.structured_error.iter_mut().chain(attempt_errors) {
+            localize_structured_error(error, &localizer);
+        }
+        localizer.set_content_language(&mut response);
+        Ok(response)
+    }
+
+    /// Finds recorded submissions by their tags
+    ///
+    /// Every submission is recorded with the tags it was submitted with, so callers can
+    /// look up transactions by their own identifiers. Only recent submissions are held.
+    async fn search_submissions(
+        &self,
+        request: Request<SearchSubmissionsRequest>,
+    ) -> Result<Response<SearchSubmissionsResponse>, Status> {
+        let req = request.into_inner();
+        validate_tags(&req.tags)
+            .map_err(|e| Status::invalid_argument(format!("Invalid tags: {e}")))?;
+
+        let limit = match req.limit {
+            0 => DEFAULT_SEARCH_LIMIT,
+            limit if limit > MAX_SEARCH_LIMIT => {
+                return Err(Status::invalid_argument(format!(
+                    "Limit must be at most {MAX_SEARCH_LIMIT}"
+                )));
+            }
+            limit => limit,
+        };
+        let submission_result = match req.submission_result() {
+            SubmissionResult::Unspecified => None,
+            result => Some(result),
+        };
+        let filter = SubmissionFilter {
+            tags: req.tags,
+            fee_payer: (!req.fee_payer.is_empty()).then_some(req.fee_payer),
+            submission_result,
+        };
+
+        let (submissions, truncated) = self.submissions.search(&filter, limit as usize);
+        debug!(matches = submissions.len(), truncated, "Searched recorded submissions");
+
+        Ok(Response::new(SearchSubmissionsResponse {
+            submissions,
+            truncated,
+        }))
+    }
+
+    /// Queues a fully signed transaction for server-side dispatch
+    ///
+    /// The transaction is validated like a submission and placed behind earlier ones of
+    /// its fee payer. If no dispatcher is draining that fee payer's lane one is started
+    /// (see `dispatch_fee_payer`); the call returns without waiting for the send.
+    async fn enqueue_transaction(
+        &self,
+        request: Request<EnqueueTransactionRequest>,
+    ) -> Result<Response<EnqueueTransactionResponse>, Status> {
+        self.ensure_queue_enabled()?;
         let req = request.into_inner();
         let transaction = req
             .transaction
             .ok_or_else(|| Status::invalid_argument("Transaction is required"))?;
 
-        // Validate current state allows submission
-        let current_state = transaction.state();
-        validate_operation_allowed_for_state(current_state, "submit")
-            .map_err(Status::failed_precondition)?;
-
-        // Validate transaction state consistency
-        validate_transaction_state_consistency(&transaction)
-            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
-
-        // Ensure transaction is fully signed
-        if current_state != TransactionState::FullySigned {
+        if transaction.state() != TransactionState::FullySigned {
             return Err(Status::failed_precondition(
-                "Transaction must be fully signed before submission",
+                "Transaction must be fully signed before it can be queued",
             ));
         }
+        validate_transaction_state_consistency(&transaction)
+            .map_err(|e| Status::invalid_argument(format!("Transaction validation failed: {e}")))?;
+        validate_tags(&req.tags)
+            .map_err(|e| Status::invalid_argument(format!("Invalid tags: {e}")))?;
+        if let Some(policy) = req.retry_policy.as_ref() {
+            RetrySchedule::from_policy(policy)
+                .map_err(|e| Status::invalid_argument(format!("Invalid retry policy: {e}")))?;
+        }
 
-        // Deserialize the signed transaction data
         let transaction_data = bs58::decode(&transaction.data).into_vec().map_err(|e| {
             Status::invalid_argument(format!("Failed to decode transaction data: {e}"))
         })?;
-
         let solana_transaction: SolanaTransaction = bincode::deserialize(&transaction_data)
             .map_err(|e| {
                 Status::invalid_argument(format!("Failed to deserialize transaction: {e}"))
             })?;
-
-        // Verify transaction is properly signed
         if solana_transaction
             .signatures
             .iter()
@@ -879,102 +3175,112 @@ impl TransactionService for TransactionServiceImpl {
         {
             return Err(Status::failed_precondition("Transaction contains unsigned accounts"));
         }
+        let signature = solana_transaction
+            .signatures
+            .first()
+            .map(ToString::to_string)
+            .unwrap_or_default();
+
+        let enqueued = self
+            .transaction_queue
+            .enqueue(
+                QueuedJob {
+                    transaction,
+                    commitment_level: req.commitment_level,
+                    retry_policy: req.retry_policy,
+                },
+                &signature,
+                req.tags,
+            )
+            .map_err(Status::resource_exhausted)?;
 
-        // Submit the transaction to the Solana network with explicit commitment level
-        info!(
-            fee_payer = %transaction.fee_payer,
-            data_length = transaction.data.len(),
-            "🚀 Submitting transaction to Solana network"
-        );
-
-        // Asynchronously submit transaction without waiting for confirmation
-        //
-        // Design philosophy:
-        // 1. PURE WRAPPER: Maintains the protocol buffer wrapper philosophy - just send
-        //    the transaction without adding business logic like confirmation waiting
-        //
-        // 2. CLIENT CONTROL: Clients decide whether to wait for confirmation using
-        //    the separate MonitorTransaction streaming RPC
-        //
-        // 3. NON-BLOCKING: Returns immediately after network submission, enabling
-        //    parallel operations and custom confirmation strategies
-        //
-        // 4. BACKEND APPROPRIATE: Uses send_transaction_with_config for proper
-        //    configuration without any UI dependencies or confirmation polling
-        let commitment = commitment_level_to_config(req.commitment_level);
-        debug!(
-            commitment_level = ?commitment,
-            fee_payer = %transaction.fee_payer,
-            "Transaction submission configured with commitment level"
+        info!(
+            queue_id = %enqueued.entry.id,
+            signature = %signature,
+            fee_payer = %enqueued.entry.fee_payer,
+            sequence = enqueued.entry.sequence,
+            "📥 Queued transaction for dispatch"
         );
-
-        // Submit the transaction with proper configuration
-        let (signature_result, submission_result, structured_error) =
-            match self.rpc_client.send_transaction_with_config(
-                &solana_transaction,
-                solana_client::rpc_config::RpcSendTransactionConfig {
-                    skip_preflight: false,
-                    preflight_commitment: Some(commitment.commitment),
-                    encoding: Some(solana_transaction_status::UiTransactionEncoding::Base64),
-                    max_retries: Some(3),
-                    min_context_slot: None,
+        if enqueued.start_dispatcher {
+            tokio::spawn(dispatch_fee_payer(
+                QueueDispatch {
+                    rpc_client: Arc::clone(&self.rpc_client),
+                    rpc_limiter: Arc::clone(&self.rpc_limiter),
+                    queue: Arc::clone(&self.transaction_queue),
+                    dead_letters: Arc::clone(&self.dead_letters),
+                    submissions: Arc::clone(&self.submissions),
                 },
-            ) {
-                Ok(signature) => {
-                    info!(
-                        signature = %signature,
-                        fee_payer = %transaction.fee_payer,
-                        commitment_level = ?commitment,
-                        "✅ Transaction submitted successfully (asynchronously)"
-                    );
+                enqueued.entry.fee_payer.clone(),
+            ));
+        }
 
-                    // Return immediately after submission without waiting for confirmation
-                    // Clients can use MonitorTransaction to poll for confirmation if desired
-                    (signature.to_string(), SubmissionResult::Submitted, None)
-                }
-                Err(e) => {
-                    let classification = classify_submission_error(&e);
-
-                    // Get current slot for blockhash resolution
-                    let current_slot = self.rpc_client.get_slot().unwrap_or(0);
-
-                    // Parse blockhash from transaction for resolution strategy
-                    let transaction_blockhash = transaction
-                        .recent_blockhash
-                        .parse()
-                        .unwrap_or_else(|_| solana_sdk::hash::Hash::default());
-
-                    // Build comprehensive structured error
-                    let structured_err = error_builder::build_structured_error(
-                        &e,
-                        classification,
-                        &transaction_blockhash,
-                        current_slot,
-                    );
+        Ok(Response::new(EnqueueTransactionResponse {
+            entry: Some(enqueued.entry),
+        }))
+    }
 
-                    error!(
-                        error = %e,
-                        fee_payer = %transaction.fee_payer,
-                        commitment_level = ?commitment,
-                        classification = ?classification,
-                        certainty = ?structured_err.certainty,
-                        retryable = structured_err.retryable,
-                        "Transaction submission failed"
-                    );
+    /// Reports queue depth and dispatch counters, and the requested entry if any
+    async fn get_queue_status(
+        &self,
+        request: Request<GetQueueStatusRequest>,
+    ) -> Result<Response<GetQueueStatusResponse>, Status> {
+        self.ensure_queue_enabled()?;
+        let req = request.into_inner();
+
+        let entry = if req.id.is_empty() {
+            None
+        } else {
+            Some(self.transaction_queue.get(&req.id).ok_or_else(|| {
+                Status::not_found(format!("No queued transaction with id {}", req.id))
+            })?)
+        };
+        let stats = self.transaction_queue.stats();
+
+        Ok(Response::new(GetQueueStatusResponse {
+            pending: u32::try_from(stats.pending).unwrap_or(u32::MAX),
+            dispatching: u32::try_from(stats.dispatching).unwrap_or(u32::MAX),
+            fee_payers: u32::try_from(stats.fee_payers).unwrap_or(u32::MAX),
+            submitted: stats.submitted,
+            failed: stats.failed,
+            max_tps: self.transaction_queue.max_tps(),
+            entry,
+        }))
+    }
+
+    /// Streams state changes of queued transactions, optionally for one fee payer
+    ///
+    /// Only changes after the call are sent. A subscriber that falls too far behind
+    /// skips the changes it missed rather than slowing down dispatch.
+    async fn stream_queue_events(
+        &self,
+        request: Request<StreamQueueEventsRequest>,
+    ) -> Result<Response<Self::StreamQueueEventsStream>, Status> {
+        self.ensure_queue_enabled()?;
+        let req = request.into_inner();
 
-                    (String::new(), classification, Some(structured_err))
+        let mut events = self.transaction_queue.subscribe();
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(async move {
+            loop {
+                let entry = match events.recv().await {
+                    Ok(entry) => entry,
+                    Err(broadcast::error::RecvError::Lagged(skipped)) => {
+                        warn!(skipped, "Queue event subscriber lagged behind");
+                        continue;
+                    }
+                    Err(broadcast::error::RecvError::Closed) => break,
+                };
+                if !req.fee_payer.is_empty() && entry.fee_payer != req.fee_payer {
+                    continue;
                 }
-            };
+                let response = StreamQueueEventsResponse { entry: Some(entry) };
+                if tx.send(Ok(response)).await.is_err() {
+                    break;
+                }
+            }
+        });
 
-        Ok(Response::new(SubmitTransactionResponse {
-            signature: signature_result,
-            submission_result: submission_result.into(),
-            error_message: structured_error
-                .as_ref()
-                .map(|e| e.message.clone())
-                .unwrap_or_default(),
-            structured_error,
-        }))
+        Ok(Response::new(ReceiverStream::new(rx)))
     }
 
     /// Retrieves a previously submitted transaction from the blockchain by signature
@@ -991,13 +3297,8 @@ impl TransactionService for TransactionServiceImpl {
     /// 6. Reconstructs transaction metadata for API consistency
     ///
     /// Data Reconstruction:
-    /// Since blockchain storage is optimized and doesn't preserve all original metadata:
-    /// - instructions: Empty (not stored on-chain after execution)
-    /// - state: `FULLY_SIGNED` (network transactions are always fully signed)
-    /// - config: None (execution config not preserved)
-    /// - signatures: Reconstructed from on-chain data
-    /// - `fee_payer`: First account key (Solana convention)
-    /// - data: Raw transaction bytes (preserved exactly)
+    /// See `network_transaction_to_proto` - on-chain storage doesn't preserve the original
+    /// instructions or config, so only the compiled bytes and signatures are returned.
     ///
     /// Commitment Level Impact:
     /// - PROCESSED: May return transactions not yet finalized
@@ -1026,9 +3327,15 @@ impl TransactionService for TransactionServiceImpl {
 
         // Get commitment level for transaction retrieval
         let commitment = commitment_level_to_config(req.commitment_level);
+        let encoding = req.encoding();
+        let rpc_client = self.rpc_router.for_commitment(commitment);
+
+        // getTransaction takes no minContextSlot, so the node's slot is checked first
+        ensure_min_context_slot(rpc_client, commitment, min_context_slot(req.min_context_slot))
+            .map_err(|e| read_error_status(&e, "Failed to check context slot"))?;
 
         // Query the transaction from the network with configurable commitment level
-        match self.rpc_client.get_transaction_with_config(
+        match rpc_client.get_transaction_with_config(
             &signature,
             RpcTransactionConfig {
                 encoding: Some(UiTransactionEncoding::Base64),
@@ -1037,49 +3344,83 @@ impl TransactionService for TransactionServiceImpl {
             },
         ) {
             Ok(confirmed_transaction) => {
-                // Extract transaction data
-                let transaction_data = match confirmed_transaction.transaction.transaction {
-                    EncodedTransaction::Binary(data, _) => {
-                        bs58::decode(&data).into_vec().map_err(|e| {
-                            Status::internal(format!("Failed to decode transaction data: {e}"))
-                        })?
-                    }
-                    _ => {
-                        return Err(Status::internal("Unsupported transaction encoding"));
-                    }
-                };
-
-                // Deserialize the transaction
-                let solana_transaction: SolanaTransaction = bincode::deserialize(&transaction_data)
-                    .map_err(|e| {
-                        Status::internal(format!("Failed to deserialize transaction: {e}"))
-                    })?;
+                // Decode whichever binary encoding the node returned
+                let versioned_transaction = confirmed_transaction
+                    .transaction
+                    .transaction
+                    .decode()
+                    .ok_or_else(|| Status::internal("Failed to decode transaction data"))?;
 
                 // Convert to our proto format
-                let proto_transaction = Transaction {
-                    instructions: vec![], // Instructions are not preserved in network storage
-                    state: TransactionState::FullySigned.into(), // Network transactions are fully signed
-                    config: None, // Config is not preserved in network storage
-                    data: bs58::encode(&transaction_data).into_string(),
-                    fee_payer: solana_transaction
-                        .message
-                        .account_keys
-                        .first()
-                        .map(std::string::ToString::to_string)
+                let proto_transaction =
+                    network_transaction_to_proto(&versioned_transaction, &req.signature)?;
+
+                // Decode top-level instructions against the full account list
+                let meta = confirmed_transaction.transaction.meta;
+                let loaded_addresses = meta.as_ref().and_then(|meta| {
+                    Option::<UiLoadedAddresses>::from(meta.loaded_addresses.clone())
+                });
+                let account_keys =
+                    resolved_account_keys(&versioned_transaction, loaded_addresses.as_ref());
+                let decoded_instructions = decode_compiled_instructions(
+                    &account_keys,
+                    versioned_transaction.message.instructions(),
+                );
+
+                // Binary encodings are rendered locally; jsonParsed needs the node's parsers
+                let encoded_transaction = match encoding {
+                    TransactionEncoding::JsonParsed => {
+                        let parsed = rpc_client
+                            .get_transaction_with_config(
+                                &signature,
+                                RpcTransactionConfig {
+                                    encoding: Some(UiTransactionEncoding::JsonParsed),
+                                    commitment: Some(commitment),
+                                    max_supported_transaction_version: Some(0),
+                                },
+                            )
+                            .map_err(|e| {
+                                Status::internal(format!("Failed to get parsed transaction: {e}"))
+                            })?;
+                        serde_json::to_string(&parsed.transaction.transaction).map_err(|e| {
+                            Status::internal(format!("Failed to encode parsed transaction: {e}"))
+                        })?
+                    }
+                    encoding => encode_transaction(&versioned_transaction, encoding)
+                        .transpose()
+                        .map_err(Status::internal)?
                         .unwrap_or_default(),
-                    recent_blockhash: solana_transaction.message.recent_blockhash.to_string(),
-                    signatures: solana_transaction
-                        .signatures
-                        .iter()
-                        .map(std::string::ToString::to_string)
-                        .collect(),
-                    hash: signature.to_string(), // Use signature as hash for compatibility
-                    signature: req.signature,
                 };
 
-                Ok(Response::new(GetTransactionResponse {
+                let mut response = GetTransactionResponse {
                     transaction: Some(proto_transaction),
-                }))
+                    decoded_instructions,
+                    encoding: encoding.into(),
+                    encoded_transaction,
+                    slot: confirmed_transaction.slot,
+                    block_time: confirmed_transaction.block_time.unwrap_or_default(),
+                    success: true,
+                    ..Default::default()
+                };
+                if let Some(meta) = meta {
+                    response.success = meta.err.is_none();
+                    response.error = meta
+                        .err
+                        .as_ref()
+                        .map(|err| format!("{err:?}"))
+                        .unwrap_or_default();
+                    response.fee = meta.fee;
+                    response.balance_changes = balance_changes(&account_keys, &meta);
+                    response.token_balance_changes = token_balance_changes(&account_keys, &meta);
+                    response.inner_instructions =
+                        Option::<Vec<UiInnerInstructions>>::from(meta.inner_instructions)
+                            .map(|inner| inner_instructions_to_proto(&inner, &account_keys))
+                            .unwrap_or_default();
+                }
+                response.call_tree =
+                    call_tree(&response.decoded_instructions, &response.inner_instructions);
+
+                Ok(Response::new(response))
             }
             Err(e) => {
                 // Transaction not found or other error
@@ -1088,6 +3429,104 @@ impl TransactionService for TransactionServiceImpl {
         }
     }
 
+    /// Lists transactions involving an address, newest first
+    ///
+    /// Wraps `getSignaturesForAddress` for the page of signatures and then fetches each
+    /// transaction with `getTransaction`, so the cost of a page grows with `limit`.
+    ///
+    /// Pagination:
+    /// - `before`: cursor - only transactions older than this signature are returned
+    /// - `until`: lower bound - listing stops once this signature is reached
+    /// - `next_cursor`: last signature of a full page, empty once history is exhausted
+    async fn get_transaction_history(
+        &self,
+        request: Request<GetTransactionHistoryRequest>,
+    ) -> Result<Response<GetTransactionHistoryResponse>, Status> {
+        let req = request.into_inner();
+
+        if req.address.is_empty() {
+            return Err(Status::invalid_argument("Address is required"));
+        }
+
+        let address = Pubkey::from_str(&req.address)
+            .map_err(|e| Status::invalid_argument(format!("Invalid address: {e}")))?;
+        let before = parse_optional_signature(&req.before, "before")?;
+        let until = parse_optional_signature(&req.until, "until")?;
+
+        let limit = match req.limit {
+            0 => DEFAULT_HISTORY_PAGE_SIZE,
+            limit if limit > MAX_HISTORY_PAGE_SIZE => {
+                return Err(Status::invalid_argument(format!(
+                    "Limit must not exceed {MAX_HISTORY_PAGE_SIZE}"
+                )));
+            }
+            limit => limit,
+        };
+
+        let commitment = history_commitment_config(req.commitment_level);
+        let rpc_client = self.rpc_router.for_commitment(commitment);
+
+        let signature_statuses = rpc_client
+            .get_signatures_for_address_with_config(
+                &address,
+                GetConfirmedSignaturesForAddress2Config {
+                    before,
+                    until,
+                    limit: Some(limit as usize),
+                    commitment: Some(commitment),
+                },
+            )
+            .map_err(|e| Status::internal(format!("Failed to get signatures for address: {e}")))?;
+
+        let mut entries = Vec::with_capacity(signature_statuses.len());
+        for signature_status in &signature_statuses {
+            let signature = Signature::from_str(&signature_status.signature)
+                .map_err(|e| Status::internal(format!("Invalid signature returned by RPC: {e}")))?;
+
+            let confirmed_transaction = rpc_client
+                .get_transaction_with_config(
+                    &signature,
+                    RpcTransactionConfig {
+                        encoding: Some(UiTransactionEncoding::Base64),
+                        commitment: Some(commitment),
+                        max_supported_transaction_version: Some(0),
+                    },
+                )
+                .map_err(|e| {
+                    Status::internal(format!("Failed to get transaction {signature}: {e}"))
+                })?;
+
+            entries.push(history_entry_from_confirmed(
+                &signature_status.signature,
+                confirmed_transaction,
+                req.include_logs,
+                req.include_balance_changes,
+            )?);
+        }
+
+        // A short page means the history is exhausted
+        let next_cursor = if signature_statuses.len() == limit as usize {
+            signature_statuses
+                .last()
+                .map(|status| status.signature.clone())
+                .unwrap_or_default()
+        } else {
+            String::new()
+        };
+
+        debug!(
+            address = %req.address,
+            entries = entries.len(),
+            has_more = !next_cursor.is_empty(),
+            "Transaction history page fetched"
+        );
+
+        Ok(Response::new(GetTransactionHistoryResponse {
+            entries,
+            next_cursor,
+        }))
+    }
+
     /// Monitors a transaction for real-time status changes via WebSocket streaming
     ///
     /// This method establishes a persistent gRPC server streaming connection that pushes
@@ -1158,6 +3597,21 @@ impl TransactionService for TransactionServiceImpl {
             return Err(Status::invalid_argument("Timeout must be between 5 and 300 seconds"));
         }
 
+        // Resolve the polling fallback schedule (zero values select server defaults)
+        let polling = req
+            .polling
+            .map_or_else(
+                || Ok(PollingSchedule::default()),
+                |polling| {
+                    PollingSchedule::from_request(
+                        polling.initial_interval_ms,
+                        polling.max_interval_ms,
+                        polling.backoff_factor,
+                    )
+                },
+            )
+            .map_err(|e| Status::invalid_argument(format!("Invalid polling config: {e}")))?;
+
         info!(
             signature = %req.signature,
             commitment_level = ?commitment_level,
@@ -1177,6 +3631,7 @@ impl TransactionService for TransactionServiceImpl {
             commitment_level,
             req.include_logs,
             Some(timeout_seconds),
+            polling,
         ) {
             Ok(rx) => rx,
             Err(e) => {
@@ -1187,9 +3642,19 @@ impl TransactionService for TransactionServiceImpl {
         // Spawn task to bridge WebSocket updates to gRPC stream
         // This task handles protocol translation between WebSocket pubsub and gRPC streaming
         let signature_for_task = req.signature.clone();
+        let rebroadcasts = Arc::clone(&self.rebroadcasts);
+        let rpc_client = Arc::clone(&self.rpc_client);
         tokio::spawn(async move {
-            bridge_websocket_to_grpc_stream(signature_for_task, websocket_rx, tx, timeout_seconds)
-                .await;
+            bridge_websocket_to_grpc_stream(
+                signature_for_task,
+                websocket_rx,
+                tx,
+                timeout_seconds,
+                rebroadcasts,
+                rpc_client,
+                req.include_inner_instructions,
+            )
+            .await;
         });
 
         info!(
@@ -1200,6 +3665,505 @@ impl TransactionService for TransactionServiceImpl {
 
         Ok(Response::new(ReceiverStream::new(rx)))
     }
+
+    /// Resolves whether a submitted transaction landed, is still pending or has expired
+    ///
+    /// Answers the indeterminate cases described in `error_builder` with one call instead
+    /// of a stream: a signature the node has not seen is pending while its blockhash is
+    /// valid and EXPIRED_NOT_LANDED once the blockhash expires, at which point the
+    /// transaction can never land and may safely be rebuilt and resubmitted.
+    async fn check_transaction_status(
+        &self,
+        request: Request<CheckTransactionStatusRequest>,
+    ) -> Result<Response<CheckTransactionStatusResponse>, Status> {
+        let req = request.into_inner();
+        let signature = Signature::from_str(&req.signature)
+            .map_err(|e| Status::invalid_argument(format!("Invalid signature: {e}")))?;
+        if req.blockhash.is_empty() {
+            return Err(Status::invalid_argument("Blockhash is required"));
+        }
+        let blockhash = Hash::from_str(&req.blockhash)
+            .map_err(|e| Status::invalid_argument(format!("Invalid blockhash: {e}")))?;
+
+        let response = check_transaction_status(&self.rpc_client, &signature, &blockhash)
+            .map_err(Status::unavailable)?;
+
+        debug!(
+            signature = %req.signature,
+            result = ?response.result(),
+            blockhash_valid = response.blockhash_valid,
+            "Checked transaction status"
+        );
+
+        Ok(Response::new(response))
+    }
+
+    /// Streams transfers parsed from new blocks that pass the request's filters
+    ///
+    /// Blocks are scanned whenever the slot subscription reports a new slot; slot polling
+    /// on the request's schedule keeps the stream moving if the WebSocket is unavailable.
+    async fn monitor_transfers(
+        &self,
+        request: Request<MonitorTransfersRequest>,
+    ) -> Result<Response<Self::MonitorTransfersStream>, Status> {
+        let req = request.into_inner();
+
+        let filter = TransferFilter::from_request(&req.addresses, &req.mints, req.min_amount)
+            .map_err(Status::invalid_argument)?;
+        let commitment = history_commitment_config(req.commitment_level);
+        let polling = req
+            .polling
+            .map_or_else(
+                || Ok(PollingSchedule::default()),
+                |polling| {
+                    PollingSchedule::from_request(
+                        polling.initial_interval_ms,
+                        polling.max_interval_ms,
+                        polling.backoff_factor,
+                    )
+                },
+            )
+            .map_err(|e| Status::invalid_argument(format!("Invalid polling config: {e}")))?;
+
+        let current_slot = self
+            .rpc_client
+            .get_slot_with_commitment(commitment)
+            .map_err(|e| Status::unavailable(format!("Failed to get current slot: {e}")))?;
+        let start_slot = if req.start_slot == 0 {
+            current_slot.saturating_add(1)
+        } else if req.start_slot < current_slot.saturating_sub(MAX_TRANSFER_START_SLOT_LAG) {
+            return Err(Status::invalid_argument(format!(
+                "Start slot must be at most {MAX_TRANSFER_START_SLOT_LAG} slots behind the current slot {current_slot}"
+            )));
+        } else {
+            req.start_slot
+        };
+
+        info!(
+            addresses = req.addresses.len(),
+            mints = req.mints.len(),
+            min_amount = req.min_amount,
+            start_slot = start_slot,
+            commitment = ?commitment.commitment,
+            "🔍 Starting transfer monitoring"
+        );
+
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(stream_transfers(
+            Arc::clone(&self.rpc_client),
+            Arc::new(filter),
+            start_slot,
+            commitment,
+            polling,
+            self.websocket_manager.subscribe_to_slots(),
+            tx,
+        ));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+
+    /// Submits fully signed transactions to the Jito block engine as one atomic bundle
+    ///
+    /// The block engine drops bundles that do not tip, so the bundle is checked for the
+    /// minimum tip to a tip account before it is sent.
+    async fn submit_bundle(
+        &self,
+        request: Request<SubmitBundleRequest>,
+    ) -> Result<Response<SubmitBundleResponse>, Status> {
+        self.ensure_bundles_available()?;
+        let req = request.into_inner();
+
+        let transactions = req
+            .transactions
+            .iter()
+            .enumerate()
+            .map(|(index, transaction)| {
+                if transaction.state() != TransactionState::FullySigned {
+                    return Err(Status::failed_precondition(format!(
+                        "Transaction {index} must be FULLY_SIGNED"
+                    )));
+                }
+                decode_data::<SolanaTransaction>(&transaction.data).map_err(|e| {
+                    Status::invalid_argument(format!(
+                        "Failed to deserialize transaction {index}: {e}"
+                    ))
+                })
+            })
+            .collect::<Result<Vec<SolanaTransaction>, Status>>()?;
+        validate_bundle(&transactions).map_err(Status::invalid_argument)?;
+
+        let tip_accounts = self.jito.tip_accounts().map_err(Status::unavailable)?;
+        let tip = tip_lamports(&transactions, &tip_accounts);
+        if tip < self.jito.min_tip_lamports() {
+            let accounts: Vec<String> = tip_accounts.iter().map(ToString::to_string).collect();
+            return Err(Status::failed_precondition(format!(
+                "Bundle tips {tip} lamports; transfer at least {} lamports to a Jito tip account ({})",
+                self.jito.min_tip_lamports(),
+                accounts.join(", ")
+            )));
+        }
+
+        let bundle_id = self
+            .jito
+            .send_bundle(&transactions)
+            .map_err(Status::unavailable)?;
+        let signatures: Vec<String> = transactions
+            .iter()
+            .map(|transaction| transaction.signatures[0].to_string())
+            .collect();
+
+        info!(
+            bundle_id = %bundle_id,
+            transactions = transactions.len(),
+            tip_lamports = tip,
+            "🎁 Submitted Jito bundle"
+        );
+
+        Ok(Response::new(SubmitBundleResponse {
+            bundle_id,
+            signatures,
+            tip_lamports: tip,
+        }))
+    }
+
+    /// Streams the landing status of a submitted bundle and its transactions
+    ///
+    /// Bundle state comes from the block engine and per-transaction status from the
+    /// cluster, both polled on the request's schedule.
+    async fn monitor_bundle(
+        &self,
+        request: Request<MonitorBundleRequest>,
+    ) -> Result<Response<Self::MonitorBundleStream>, Status> {
+        self.ensure_bundles_available()?;
+        let req = request.into_inner();
+
+        if req.bundle_id.is_empty() {
+            return Err(Status::invalid_argument("Bundle id is required"));
+        }
+        let signatures = req
+            .signatures
+            .iter()
+            .map(|signature| Signature::from_str(signature))
+            .collect::<Result<Vec<Signature>, _>>()
+            .map_err(|e| Status::invalid_argument(format!("Invalid signature: {e}")))?;
+        let commitment = commitment_level_to_config(req.commitment_level);
+
+        let timeout_seconds = if req.timeout_seconds == 0 {
+            60
+        } else {
+            req.timeout_seconds
+        };
+        if !(5..=300).contains(&timeout_seconds) {
+            return Err(Status::invalid_argument("Timeout must be between 5 and 300 seconds"));
+        }
+        let polling = req
+            .polling
+            .map_or_else(
+                || Ok(PollingSchedule::default()),
+                |polling| {
+                    PollingSchedule::from_request(
+                        polling.initial_interval_ms,
+                        polling.max_interval_ms,
+                        polling.backoff_factor,
+                    )
+                },
+            )
+            .map_err(|e| Status::invalid_argument(format!("Invalid polling config: {e}")))?;
+
+        info!(
+            bundle_id = %req.bundle_id,
+            timeout_seconds = timeout_seconds,
+            "🔍 Starting bundle monitoring"
+        );
+
+        let (tx, rx) = mpsc::channel(100);
+        tokio::spawn(monitor_bundle_until_settled(
+            Arc::clone(&self.jito),
+            Arc::clone(&self.rpc_client),
+            req.bundle_id,
+            signatures,
+            commitment,
+            Duration::from_secs(u64::from(timeout_seconds)),
+            polling,
+            tx,
+        ));
+
+        Ok(Response::new(ReceiverStream::new(rx)))
+    }
+}
+
+/// Scans the blocks from `next_slot` up to the current slot at `commitment`, at most
+/// `MAX_TRANSFER_SCAN_SLOTS` of them, collecting the transfers that pass `filter`.
+///
+/// `next_slot` advances past each block as it is scanned, so a failed read resumes from
+/// the block that failed without repeating the events already collected.
+fn scan_transfers(
+    rpc_client: &RpcClient,
+    next_slot: &mut u64,
+    commitment: CommitmentConfig,
+    filter: &TransferFilter,
+    events: &mut Vec<TransferEvent>,
+) -> Result<(), String> {
+    let current_slot = rpc_client
+        .get_slot_with_commitment(commitment)
+        .map_err(|e| format!("Failed to get current slot: {e}"))?;
+    if current_slot < *next_slot {
+        return Ok(());
+    }
+    let end_slot = current_slot.min(next_slot.saturating_add(MAX_TRANSFER_SCAN_SLOTS - 1));
+    let slots = rpc_client
+        .get_blocks_with_commitment(*next_slot, Some(end_slot), commitment)
+        .map_err(|e| format!("Failed to list blocks: {e}"))?;
+
+    for slot in slots {
+        let block = rpc_client
+            .get_block_with_config(
+                slot,
+                RpcBlockConfig {
+                    encoding: Some(UiTransactionEncoding::Base64),
+                    transaction_details: Some(TransactionDetails::Full),
+                    rewards: Some(false),
+                    commitment: Some(commitment),
+                    max_supported_transaction_version: Some(0),
+                },
+            )
+            .map_err(|e| format!("Failed to get block {slot}: {e}"))?;
+        events.extend(
+            block_transfers(slot, block)
+                .into_iter()
+                .filter(|event| filter.matches(event)),
+        );
+        *next_slot = slot.saturating_add(1);
+    }
+    // Slots without a block up to the end of the range are skipped, not pending
+    *next_slot = end_slot.saturating_add(1);
+    Ok(())
+}
+
+/// Every transfer made by the successful transactions of a block, in block order
+fn block_transfers(slot: u64, block: UiConfirmedBlock) -> Vec<TransferEvent> {
+    let context = BlockContext {
+        slot,
+        block_time: block.block_time.unwrap_or_default(),
+    };
+    block
+        .transactions
+        .unwrap_or_default()
+        .into_iter()
+        .filter_map(|transaction| {
+            let meta = transaction.meta?;
+            if meta.err.is_some() {
+                return None;
+            }
+            let versioned_transaction = transaction.transaction.decode()?;
+            let signature = versioned_transaction.signatures.first()?.to_string();
+            let loaded_addresses = Option::<UiLoadedAddresses>::from(meta.loaded_addresses.clone());
+            let account_keys =
+                resolved_account_keys(&versioned_transaction, loaded_addresses.as_ref());
+            let top_level = decode_compiled_instructions(
+                &account_keys,
+                versioned_transaction.message.instructions(),
+            );
+            let inner_instructions = inner_instructions_to_proto(
+                &Option::<Vec<UiInnerInstructions>>::from(meta.inner_instructions.clone())
+                    .unwrap_or_default(),
+                &account_keys,
+            );
+            Some(transaction_transfers(
+                &signature,
+                context,
+                &account_keys,
+                &top_level,
+                &inner_instructions,
+                &meta,
+            ))
+        })
+        .flatten()
+        .collect()
+}
+
+/// Scans new blocks for transfers until the client disconnects, waking on each slot the
+/// subscription reports or, failing that, on the polling schedule.
+///
+/// Polling returns to its initial interval whenever a scan finds new blocks and backs off
+/// while none arrive.
+async fn stream_transfers(
+    rpc_client: Arc<RpcClient>,
+    filter: Arc<TransferFilter>,
+    mut next_slot: u64,
+    commitment: CommitmentConfig,
+    polling: PollingSchedule,
+    mut slots: mpsc::UnboundedReceiver<u64>,
+    grpc_tx: mpsc::Sender<Result<MonitorTransfersResponse, Status>>,
+) {
+    let mut interval = polling.initial_interval();
+    let mut slots_open = true;
+
+    loop {
+        let scan_client = Arc::clone(&rpc_client);
+        let scan_filter = Arc::clone(&filter);
+        let scanned = tokio::task::spawn_blocking(move || {
+            let mut events = Vec::new();
+            let mut slot = next_slot;
+            let result =
+                scan_transfers(&scan_client, &mut slot, commitment, &scan_filter, &mut events);
+            (slot, events, result)
+        })
+        .await;
+
+        let advanced = match scanned {
+            Ok((slot, events, result)) => {
+                if let Err(e) = result {
+                    warn!(slot = slot, error = %e, "Transfer scan failed");
+                }
+                for event in events {
+                    let response = MonitorTransfersResponse {
+                        transfer: Some(event),
+                    };
+                    if grpc_tx.send(Ok(response)).await.is_err() {
+                        debug!("Client disconnected from transfer monitoring");
+                        return;
+                    }
+                }
+                let advanced = slot > next_slot;
+                next_slot = slot;
+                advanced
+            }
+            Err(e) => {
+                error!(error = %e, "Transfer scan task failed");
+                false
+            }
+        };
+        interval = if advanced {
+            polling.initial_interval()
+        } else {
+            polling.next_interval(interval)
+        };
+
+        tokio::select! {
+            () = grpc_tx.closed() => {
+                debug!("Client disconnected from transfer monitoring");
+                return;
+            }
+            slot = slots.recv(), if slots_open => {
+                if slot.is_none() {
+                    debug!("Slot subscription closed, continuing with polling");
+                    slots_open = false;
+                }
+                // One scan covers every slot reported while the last one ran
+                while slots.try_recv().is_ok() {}
+            }
+            () = tokio::time::sleep(interval) => {}
+        }
+    }
+}
+
+/// Polls a bundle's state and its transactions' statuses once, returning the update and
+/// whether the bundle has settled.
+///
+/// Signatures are read from the block engine once the bundle lands if the caller did not
+/// supply them.
+fn poll_bundle(
+    jito: &JitoBlockEngine,
+    rpc_client: &RpcClient,
+    bundle_id: &str,
+    signatures: &mut Vec<Signature>,
+    commitment: CommitmentConfig,
+) -> Result<(MonitorBundleResponse, bool), String> {
+    let inflight = jito.inflight_status(bundle_id)?;
+    let (state, landed_slot) = inflight.map_or((BundleState::Invalid, 0), |inflight| {
+        (bundle_state(inflight.status), inflight.landed_slot.unwrap_or_default())
+    });
+
+    if state == BundleState::Landed && signatures.is_empty() {
+        *signatures = jito
+            .landed_signatures(bundle_id)?
+            .iter()
+            .filter_map(|signature| Signature::from_str(signature).ok())
+            .collect();
+    }
+    let statuses = if signatures.is_empty() {
+        Vec::new()
+    } else {
+        rpc_client
+            .get_signature_statuses(signatures)
+            .map_err(|e| format!("Failed to get signature statuses: {e}"))?
+            .value
+    };
+
+    let update = MonitorBundleResponse {
+        bundle_id: bundle_id.to_string(),
+        state: state.into(),
+        landed_slot,
+        transactions: signatures
+            .iter()
+            .zip(statuses.iter())
+            .map(|(signature, status)| transaction_status(signature, status.as_ref(), state))
+            .collect(),
+        poll_count: 0,
+    };
+    Ok((update, is_settled(state, &statuses, commitment)))
+}
+
+/// Polls a bundle until it settles, the client disconnects or `timeout` elapses, sending
+/// an update whenever the bundle or one of its transactions changes
+#[allow(clippy::too_many_arguments)]
+async fn monitor_bundle_until_settled(
+    jito: Arc<JitoBlockEngine>,
+    rpc_client: Arc<RpcClient>,
+    bundle_id: String,
+    mut signatures: Vec<Signature>,
+    commitment: CommitmentConfig,
+    timeout: Duration,
+    polling: PollingSchedule,
+    grpc_tx: mpsc::Sender<Result<MonitorBundleResponse, Status>>,
+) {
+    let deadline = Instant::now() + timeout;
+    let mut interval = polling.initial_interval();
+    let mut poll_count: u32 = 0;
+    let mut last_update: Option<MonitorBundleResponse> = None;
+
+    loop {
+        if grpc_tx.is_closed() {
+            debug!(bundle_id = %bundle_id, "Client disconnected from bundle monitoring");
+            return;
+        }
+
+        poll_count = poll_count.saturating_add(1);
+        match poll_bundle(&jito, &rpc_client, &bundle_id, &mut signatures, commitment) {
+            Ok((update, settled)) => {
+                if last_update.as_ref() != Some(&update) {
+                    last_update = Some(update.clone());
+                    let update = MonitorBundleResponse {
+                        poll_count,
+                        ..update
+                    };
+                    if grpc_tx.send(Ok(update)).await.is_err() {
+                        return;
+                    }
+                }
+                if settled {
+                    info!(bundle_id = %bundle_id, poll_count, "✅ Bundle settled");
+                    return;
+                }
+            }
+            Err(e) => warn!(bundle_id = %bundle_id, error = %e, "Bundle status poll failed"),
+        }
+
+        if Instant::now() >= deadline {
+            let update = MonitorBundleResponse {
+                bundle_id: bundle_id.clone(),
+                state: BundleState::Timeout.into(),
+                poll_count,
+                ..last_update.unwrap_or_default()
+            };
+            // Best effort - ignore if client already disconnected
+            let _ = grpc_tx.send(Ok(update)).await;
+            return;
+        }
+        tokio::time::sleep(interval).await;
+        interval = polling.next_interval(interval);
+    }
 }
 
 /// Bridges WebSocket subscription updates to gRPC streaming response
@@ -1231,10 +4195,13 @@ const fn is_terminal_status(status: TransactionStatus) -> bool {
     )
 }
 
-/// Helper function to send timeout notification to grPC client
+/// Helper function to send timeout notification to grPC client, carrying the poll count
+/// of the last update relayed
 async fn send_timeout_notification(
     grpc_tx: &mpsc::Sender<Result<MonitorTransactionResponse, Status>>,
     signature: &str,
+    poll_count: u32,
+    rebroadcasts: &RebroadcastTracker,
 ) {
     let timeout_response = MonitorTransactionResponse {
         signature: signature.to_string(),
@@ -1244,7 +4211,15 @@ async fn send_timeout_notification(
         logs: vec![],
         compute_units_consumed: 0,
         current_commitment: CommitmentLevel::Unspecified.into(),
+        mechanism: MonitoringMechanism::Polling.into(),
+        poll_count,
+        rebroadcast_count: 0,
+        rebroadcast_state: RebroadcastState::Unspecified.into(),
+        instruction_failure: None,
+        inner_instructions: vec![],
+        call_tree: vec![],
     };
+    let timeout_response = with_rebroadcast_progress(timeout_response, rebroadcasts);
 
     // Best effort - ignore if client already disconnected
     if grpc_tx.send(Ok(timeout_response)).await.is_err() {
@@ -1255,6 +4230,79 @@ async fn send_timeout_notification(
     }
 }
 
+/// Stamps a monitoring update with the progress of the signature's rebroadcast loop, if any
+fn with_rebroadcast_progress(
+    mut response: MonitorTransactionResponse,
+    rebroadcasts: &RebroadcastTracker,
+) -> MonitorTransactionResponse {
+    if let Some(progress) = rebroadcasts.get(&response.signature) {
+        response.rebroadcast_count = progress.count;
+        response.rebroadcast_state = progress.state.into();
+    }
+    response
+}
+
+/// Adds execution details read back from the node to a final monitoring update: the
+/// failing instruction of a FAILED transaction and, if requested, the CPI tree of any
+/// final status. Best effort: a transaction the node cannot yet return at confirmed
+/// commitment is passed through unchanged.
+fn with_execution_details(
+    mut response: MonitorTransactionResponse,
+    rpc_client: &RpcClient,
+    include_inner_instructions: bool,
+) -> MonitorTransactionResponse {
+    let wants_inner_instructions = include_inner_instructions
+        && matches!(
+            response.status(),
+            TransactionStatus::Confirmed | TransactionStatus::Finalized | TransactionStatus::Failed
+        );
+    if response.status() != TransactionStatus::Failed && !wants_inner_instructions {
+        return response;
+    }
+    let Ok(signature) = Signature::from_str(&response.signature) else {
+        return response;
+    };
+    let Ok(confirmed_transaction) = rpc_client.get_transaction_with_config(
+        &signature,
+        RpcTransactionConfig {
+            encoding: Some(UiTransactionEncoding::Base64),
+            commitment: Some(CommitmentConfig::confirmed()),
+            max_supported_transaction_version: Some(0),
+        },
+    ) else {
+        return response;
+    };
+
+    let transaction = confirmed_transaction.transaction;
+    let (Some(versioned_transaction), Some(meta)) =
+        (transaction.transaction.decode(), transaction.meta)
+    else {
+        return response;
+    };
+    if let Some(error) = meta.err.as_ref() {
+        let program_ids = instruction_program_ids(
+            versioned_transaction.message.static_account_keys(),
+            versioned_transaction.message.instructions(),
+        );
+        let logs = Option::<Vec<String>>::from(meta.log_messages).unwrap_or_default();
+        response.instruction_failure = attribute_failure(error, &program_ids, &logs);
+    }
+    if wants_inner_instructions {
+        let loaded_addresses = Option::<UiLoadedAddresses>::from(meta.loaded_addresses);
+        let account_keys = resolved_account_keys(&versioned_transaction, loaded_addresses.as_ref());
+        response.inner_instructions =
+            Option::<Vec<UiInnerInstructions>>::from(meta.inner_instructions)
+                .map(|inner| inner_instructions_to_proto(&inner, &account_keys))
+                .unwrap_or_default();
+        let top_level = decode_compiled_instructions(
+            &account_keys,
+            versioned_transaction.message.instructions(),
+        );
+        response.call_tree = call_tree(&top_level, &response.inner_instructions);
+    }
+    response
+}
+
 /// Memory Safety:
 /// - No heap allocations in hot path (only stack-based message passing)
 /// - Clone operations are minimal (only for logging)
@@ -1264,6 +4312,9 @@ async fn bridge_websocket_to_grpc_stream(
     mut websocket_rx: tokio::sync::mpsc::UnboundedReceiver<MonitorTransactionResponse>,
     grpc_tx: mpsc::Sender<Result<MonitorTransactionResponse, Status>>,
     timeout_seconds: u32,
+    rebroadcasts: Arc<RebroadcastTracker>,
+    rpc_client: Arc<RpcClient>,
+    include_inner_instructions: bool,
 ) {
     debug!(
         signature = %signature,
@@ -1274,8 +4325,15 @@ async fn bridge_websocket_to_grpc_stream(
     let bridge_timeout = Duration::from_secs(u64::from(timeout_seconds) + 5); // Add 5s buffer
 
     // Use timeout to prevent indefinite hanging if WebSocket stops responding
+    let mut poll_count = 0;
     let bridge_result = timeout(bridge_timeout, async {
         while let Some(response) = websocket_rx.recv().await {
+            poll_count = response.poll_count;
+            let response = with_execution_details(
+                with_rebroadcast_progress(response, &rebroadcasts),
+                &rpc_client,
+                include_inner_instructions,
+            );
             debug!(
                 signature = %signature,
                 status = ?response.status(),
@@ -1326,6 +4384,6 @@ async fn bridge_websocket_to_grpc_stream(
             "⏰ Stream bridge timed out"
         );
         // Send timeout notification to client if channel is still open
-        send_timeout_notification(&grpc_tx, &signature).await;
+        send_timeout_notification(&grpc_tx, &signature, poll_count, &rebroadcasts).await;
     }
 }
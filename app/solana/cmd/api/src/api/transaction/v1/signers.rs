@@ -0,0 +1,200 @@
+use solana_sdk::{
+    hash::Hash,
+    instruction::Instruction,
+    message::{Message, MessageHeader},
+    pubkey::Pubkey,
+    signature::Signature,
+    transaction::Transaction as SolanaTransaction,
+};
+use std::collections::HashMap;
+use std::str::FromStr;
+
+use crate::api::common::solana_conversions::proto_instruction_to_sdk;
+use crate::api::transaction::v1::diagnostics::decode_data;
+use protochain_api::protochain::solana::transaction::v1::{
+    RequiredSigner, Transaction, TransactionState,
+};
+
+/// Lists the signers required by a message header, with the signatures present for them.
+///
+/// `account_keys` are the message's static keys, whose first `num_required_signatures`
+/// entries are the signers; `signatures` are matched to them by position, and a missing
+/// or default signature means the signer has not signed yet.
+pub fn signers_of(
+    header: &MessageHeader,
+    account_keys: &[Pubkey],
+    signatures: &[Signature],
+) -> Vec<RequiredSigner> {
+    let required = usize::from(header.num_required_signatures);
+    let writable_signers =
+        required.saturating_sub(usize::from(header.num_readonly_signed_accounts));
+
+    account_keys
+        .iter()
+        .take(required)
+        .enumerate()
+        .map(|(index, address)| {
+            let signature = signatures
+                .get(index)
+                .filter(|signature| **signature != Signature::default());
+            RequiredSigner {
+                address: address.to_string(),
+                signed: signature.is_some(),
+                fee_payer: index == 0,
+                writable: index < writable_signers,
+                signature: signature.map(ToString::to_string).unwrap_or_default(),
+            }
+        })
+        .collect()
+}
+
+/// Index of `signer` among the required signers of `message`, if it is one, for remote
+/// signers that return a signature rather than a keypair
+pub fn signer_index(message: &Message, signer: &Pubkey) -> Option<usize> {
+    message
+        .account_keys
+        .iter()
+        .take(usize::from(message.header.num_required_signatures))
+        .position(|key| key == signer)
+}
+
+/// Required signer address to whether it has signed, for `Transaction.signing_status`
+pub fn signing_status(signers: &[RequiredSigner]) -> HashMap<String, bool> {
+    signers
+        .iter()
+        .map(|signer| (signer.address.clone(), signer.signed))
+        .collect()
+}
+
+/// Derives the required signers of a transaction in any state.
+///
+/// Drafts are compiled locally with the fee payer from the transaction, else `fee_payer`;
+/// the blockhash does not affect the signer set, so a placeholder is used.
+pub fn required_signers(
+    transaction: &Transaction,
+    fee_payer: &str,
+) -> Result<Vec<RequiredSigner>, String> {
+    match transaction.state() {
+        TransactionState::Draft => {
+            let instructions = transaction
+                .instructions
+                .iter()
+                .enumerate()
+                .map(|(index, proto_ix)| {
+                    proto_instruction_to_sdk(proto_ix.clone())
+                        .map_err(|e| format!("Invalid instruction {index}: {e}"))
+                })
+                .collect::<Result<Vec<Instruction>, String>>()?;
+
+            let fee_payer = if transaction.fee_payer.is_empty() {
+                fee_payer
+            } else {
+                &transaction.fee_payer
+            };
+            if fee_payer.is_empty() {
+                return Err(
+                    "A DRAFT transaction requires a fee payer on the transaction or the request"
+                        .to_string(),
+                );
+            }
+            let fee_payer =
+                Pubkey::from_str(fee_payer).map_err(|e| format!("Invalid fee_payer: {e}"))?;
+
+            let message =
+                Message::new_with_blockhash(&instructions, Some(&fee_payer), &Hash::default());
+            Ok(signers_of(&message.header, &message.account_keys, &[]))
+        }
+        TransactionState::Compiled => {
+            let message: Message = decode_data(&transaction.data)?;
+            Ok(signers_of(&message.header, &message.account_keys, &[]))
+        }
+        TransactionState::PartiallySigned | TransactionState::FullySigned => {
+            let signed: SolanaTransaction = decode_data(&transaction.data)?;
+            Ok(signers_of(
+                &signed.message.header,
+                &signed.message.account_keys,
+                &signed.signatures,
+            ))
+        }
+        TransactionState::Unspecified => Err("Transaction state cannot be UNSPECIFIED".to_string()),
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+    use solana_sdk::{
+        instruction::AccountMeta,
+        signature::{Keypair, Signer},
+    };
+
+    /// A transfer co-signed by a read-only authority
+    fn instructions(payer: &Pubkey, authority: &Pubkey) -> Vec<Instruction> {
+        vec![
+            solana_sdk::system_instruction::transfer(payer, &Pubkey::new_unique(), 1),
+            Instruction::new_with_bytes(
+                Pubkey::new_unique(),
+                &[],
+                vec![AccountMeta::new_readonly(*authority, true)],
+            ),
+        ]
+    }
+
+    #[test]
+    fn test_draft_signers() {
+        let payer = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let transaction = Transaction {
+            instructions: instructions(&payer, &authority)
+                .into_iter()
+                .map(sdk_instruction_to_proto)
+                .collect(),
+            state: TransactionState::Draft.into(),
+            ..Default::default()
+        };
+
+        assert!(required_signers(&transaction, "").is_err());
+        let signers = required_signers(&transaction, &payer.to_string()).unwrap();
+
+        assert_eq!(signers.len(), 2);
+        assert_eq!(signers[0].address, payer.to_string());
+        assert!(signers[0].fee_payer && signers[0].writable);
+        assert_eq!(signers[1].address, authority.to_string());
+        assert!(!signers[1].fee_payer && !signers[1].writable);
+        assert!(signers.iter().all(|signer| !signer.signed));
+    }
+
+    #[test]
+    fn test_partially_signed_reports_outstanding_signers() {
+        let payer = Keypair::new();
+        let authority = Pubkey::new_unique();
+        let message = Message::new_with_blockhash(
+            &instructions(&payer.pubkey(), &authority),
+            Some(&payer.pubkey()),
+            &Hash::new_unique(),
+        );
+        let mut signed = SolanaTransaction::new_unsigned(message);
+        signed.partial_sign(&[&payer], signed.message.recent_blockhash);
+        let transaction = Transaction {
+            state: TransactionState::PartiallySigned.into(),
+            data: bs58::encode(bincode::serialize(&signed).unwrap()).into_string(),
+            ..Default::default()
+        };
+
+        let signers = required_signers(&transaction, "").unwrap();
+
+        assert!(signers[0].signed);
+        assert_eq!(signers[0].signature, signed.signatures[0].to_string());
+        assert!(!signers[1].signed);
+        assert!(signers[1].signature.is_empty());
+
+        let status = signing_status(&signers);
+        assert_eq!(status.get(&payer.pubkey().to_string()), Some(&true));
+        assert_eq!(status.get(&authority.to_string()), Some(&false));
+
+        assert_eq!(signer_index(&signed.message, &authority), Some(1));
+        assert_eq!(signer_index(&signed.message, &Pubkey::new_unique()), None);
+    }
+}
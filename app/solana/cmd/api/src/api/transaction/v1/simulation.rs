@@ -0,0 +1,386 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use sha2::{Digest, Sha256};
+use solana_account_decoder::UiAccount;
+use solana_sdk::{
+    account::Account, hash::Hash, instruction::Instruction, message::Message, pubkey::Pubkey,
+    transaction::Transaction as SolanaTransaction,
+};
+use solana_transaction_status::UiTransactionReturnData;
+use std::str::FromStr;
+
+use crate::api::common::solana_conversions::proto_instruction_to_sdk;
+use crate::api::transaction::v1::diagnostics::decode_data;
+use crate::service_providers::simulation_cache::SimulationCacheKey;
+use protochain_api::protochain::solana::transaction::v1::{
+    SimulateTransactionRequest, SimulatedAccount, SimulationReturnData, Transaction,
+    TransactionState,
+};
+
+/// Most accounts whose state a simulation may return (the RPC node's limit)
+pub const MAX_SIMULATED_ACCOUNTS: usize = 100;
+
+/// Signature and blockhash handling requested for a simulation
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct SimulationOptions {
+    /// Verify the transaction's signatures
+    pub sig_verify: bool,
+    /// Simulate against the latest blockhash instead of the transaction's own
+    pub replace_recent_blockhash: bool,
+}
+
+/// Builds the transaction to simulate from a transaction in any state.
+///
+/// Drafts are compiled locally (the fee payer comes from the transaction, else
+/// `fee_payer`) and, lacking a blockhash, need `replace_recent_blockhash`. Signature
+/// verification is only possible for fully signed transactions, and the node rejects it
+/// together with blockhash replacement since replacing the blockhash voids the signatures.
+pub fn simulation_transaction(
+    transaction: &Transaction,
+    fee_payer: &str,
+    options: SimulationOptions,
+) -> Result<SolanaTransaction, String> {
+    let state = transaction.state();
+    if options.sig_verify && options.replace_recent_blockhash {
+        return Err("sig_verify cannot be combined with replace_recent_blockhash".to_string());
+    }
+    if options.sig_verify && state != TransactionState::FullySigned {
+        return Err(format!("sig_verify requires a FULLY_SIGNED transaction, got {state:?}"));
+    }
+
+    match state {
+        TransactionState::Draft => {
+            let instructions = transaction
+                .instructions
+                .iter()
+                .enumerate()
+                .map(|(index, proto_ix)| {
+                    proto_instruction_to_sdk(proto_ix.clone())
+                        .map_err(|e| format!("Invalid instruction {index}: {e}"))
+                })
+                .collect::<Result<Vec<Instruction>, String>>()?;
+
+            let fee_payer = if transaction.fee_payer.is_empty() {
+                fee_payer
+            } else {
+                &transaction.fee_payer
+            };
+            if fee_payer.is_empty() {
+                return Err(
+                    "Simulating a DRAFT transaction requires a fee payer on the transaction or \
+                     the request"
+                        .to_string(),
+                );
+            }
+            let fee_payer =
+                Pubkey::from_str(fee_payer).map_err(|e| format!("Invalid fee_payer: {e}"))?;
+
+            let mut message = Message::new(&instructions, Some(&fee_payer));
+            if transaction.recent_blockhash.is_empty() {
+                if !options.replace_recent_blockhash {
+                    return Err(
+                        "DRAFT transaction has no recent_blockhash; set replace_recent_blockhash \
+                         to simulate it"
+                            .to_string(),
+                    );
+                }
+            } else {
+                message.recent_blockhash = Hash::from_str(&transaction.recent_blockhash)
+                    .map_err(|e| format!("Invalid recent_blockhash: {e}"))?;
+            }
+            Ok(SolanaTransaction::new_unsigned(message))
+        }
+        TransactionState::Compiled => {
+            Ok(SolanaTransaction::new_unsigned(decode_data::<Message>(&transaction.data)?))
+        }
+        TransactionState::PartiallySigned | TransactionState::FullySigned => {
+            decode_data(&transaction.data)
+        }
+        TransactionState::Unspecified => Err("Transaction state cannot be UNSPECIFIED".to_string()),
+    }
+}
+
+/// Parses the addresses a simulation should return account state for
+pub fn parse_account_addresses(addresses: &[String]) -> Result<Vec<Pubkey>, String> {
+    if addresses.len() > MAX_SIMULATED_ACCOUNTS {
+        return Err(format!("At most {MAX_SIMULATED_ACCOUNTS} account addresses can be simulated"));
+    }
+    addresses
+        .iter()
+        .map(|address| {
+            Pubkey::from_str(address).map_err(|e| format!("Invalid account address {address}: {e}"))
+        })
+        .collect()
+}
+
+/// Signed difference between two balances, saturating at the bounds of i64
+fn lamports_delta(pre: u64, post: u64) -> i64 {
+    let delta = i128::from(post) - i128::from(pre);
+    i64::try_from(delta).unwrap_or(if delta < 0 { i64::MIN } else { i64::MAX })
+}
+
+/// Pairs each requested address with its state before and after the simulation.
+///
+/// `pre` and `post` are in the order of `addresses`, as returned by the RPC node; a
+/// missing entry means the account did not exist at that point.
+pub fn simulated_accounts(
+    addresses: &[Pubkey],
+    pre: &[Option<Account>],
+    post: &[Option<UiAccount>],
+) -> Vec<SimulatedAccount> {
+    addresses
+        .iter()
+        .enumerate()
+        .map(|(index, address)| {
+            let pre = pre.get(index).and_then(Option::as_ref);
+            let post: Option<Account> = post
+                .get(index)
+                .and_then(Option::as_ref)
+                .and_then(UiAccount::decode);
+
+            let pre_lamports = pre.map_or(0, |account| account.lamports);
+            let lamports = post.as_ref().map_or(0, |account| account.lamports);
+            let mut simulated = SimulatedAccount {
+                address: address.to_string(),
+                existed: pre.is_some(),
+                pre_lamports,
+                exists: post.is_some(),
+                lamports,
+                lamports_delta: lamports_delta(pre_lamports, lamports),
+                ..Default::default()
+            };
+            if let Some(account) = post {
+                simulated.owner = account.owner.to_string();
+                simulated.data = account.data;
+                simulated.executable = account.executable;
+                simulated.rent_epoch = account.rent_epoch;
+            }
+            simulated
+        })
+        .collect()
+}
+
+/// Hex SHA-256 of a transaction's serialized message
+pub fn message_hash(transaction: &SolanaTransaction) -> String {
+    hex::encode(Sha256::digest(transaction.message_data()))
+}
+
+/// Builds the key a simulation result is cached under.
+///
+/// Besides the message hash and slot bucket, every option that changes the result is part
+/// of the key; signatures only matter when they are verified.
+pub fn simulation_cache_key(
+    req: &SimulateTransactionRequest,
+    transaction: &SolanaTransaction,
+    slot_bucket: u64,
+) -> SimulationCacheKey {
+    SimulationCacheKey {
+        message_hash: message_hash(transaction),
+        slot_bucket,
+        commitment_level: req.commitment_level,
+        sig_verify: req.sig_verify,
+        replace_recent_blockhash: req.replace_recent_blockhash,
+        include_inner_instructions: req.include_inner_instructions,
+        min_context_slot: req.min_context_slot,
+        account_addresses: req.account_addresses.clone(),
+        signatures: if req.sig_verify {
+            transaction
+                .signatures
+                .iter()
+                .map(ToString::to_string)
+                .collect()
+        } else {
+            Vec::new()
+        },
+    }
+}
+
+/// Converts the return data reported by a simulation, which nodes encode as base64
+pub fn return_data_to_proto(return_data: &UiTransactionReturnData) -> SimulationReturnData {
+    SimulationReturnData {
+        program_id: return_data.program_id.clone(),
+        data: STANDARD.decode(&return_data.data.0).unwrap_or_default(),
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::solana_conversions::sdk_instruction_to_proto;
+    use solana_account_decoder::UiAccountEncoding;
+    use solana_transaction_status::UiReturnDataEncoding;
+
+    fn account(lamports: u64, data: Vec<u8>) -> Account {
+        Account {
+            lamports,
+            data,
+            owner: solana_sdk::system_program::id(),
+            executable: false,
+            rent_epoch: 0,
+        }
+    }
+
+    fn ui_account(account: &Account) -> UiAccount {
+        UiAccount::encode(&Pubkey::new_unique(), account, UiAccountEncoding::Base64, None, None)
+    }
+
+    fn draft(fee_payer: &Pubkey, recent_blockhash: &str) -> Transaction {
+        let to = Pubkey::new_unique();
+        Transaction {
+            instructions: vec![sdk_instruction_to_proto(
+                solana_sdk::system_instruction::transfer(fee_payer, &to, 1),
+            )],
+            state: TransactionState::Draft.into(),
+            recent_blockhash: recent_blockhash.to_string(),
+            ..Default::default()
+        }
+    }
+
+    const REPLACE: SimulationOptions = SimulationOptions {
+        sig_verify: false,
+        replace_recent_blockhash: true,
+    };
+
+    #[test]
+    fn test_draft_needs_fee_payer_and_blockhash() {
+        let payer = Pubkey::new_unique();
+        let transaction = draft(&payer, "");
+
+        let simulated = simulation_transaction(&transaction, &payer.to_string(), REPLACE).unwrap();
+        assert_eq!(simulated.message.account_keys[0], payer);
+        assert_eq!(simulated.signatures.len(), 1);
+
+        assert!(simulation_transaction(&transaction, "", REPLACE).is_err());
+        assert!(simulation_transaction(
+            &transaction,
+            &payer.to_string(),
+            SimulationOptions::default()
+        )
+        .is_err());
+    }
+
+    #[test]
+    fn test_draft_keeps_its_blockhash() {
+        let payer = Pubkey::new_unique();
+        let blockhash = Hash::new_unique();
+        let transaction = draft(&payer, &blockhash.to_string());
+
+        let simulated =
+            simulation_transaction(&transaction, &payer.to_string(), SimulationOptions::default())
+                .unwrap();
+        assert_eq!(simulated.message.recent_blockhash, blockhash);
+    }
+
+    #[test]
+    fn test_sig_verify_rules() {
+        let payer = Pubkey::new_unique();
+        let transaction = draft(&payer, "");
+        let both = SimulationOptions {
+            sig_verify: true,
+            replace_recent_blockhash: true,
+        };
+        let sig_verify = SimulationOptions {
+            sig_verify: true,
+            replace_recent_blockhash: false,
+        };
+
+        assert!(simulation_transaction(&transaction, &payer.to_string(), both)
+            .unwrap_err()
+            .contains("cannot be combined"));
+        assert!(simulation_transaction(&transaction, &payer.to_string(), sig_verify)
+            .unwrap_err()
+            .contains("FULLY_SIGNED"));
+    }
+
+    #[test]
+    fn test_parse_account_addresses() {
+        let address = Pubkey::new_unique();
+        assert_eq!(parse_account_addresses(&[address.to_string()]).unwrap(), vec![address]);
+        assert!(parse_account_addresses(&["not-an-address".to_string()]).is_err());
+        assert!(parse_account_addresses(&vec![address.to_string(); MAX_SIMULATED_ACCOUNTS + 1])
+            .is_err());
+    }
+
+    #[test]
+    fn test_simulated_accounts_pair_pre_and_post_state() {
+        let payer = Pubkey::new_unique();
+        let created = Pubkey::new_unique();
+        let pre = vec![Some(account(10_000, vec![])), None];
+        let post = vec![
+            Some(ui_account(&account(7_500, vec![]))),
+            Some(ui_account(&account(2_000, vec![1, 2, 3]))),
+        ];
+
+        let accounts = simulated_accounts(&[payer, created], &pre, &post);
+
+        assert_eq!(accounts[0].address, payer.to_string());
+        assert!(accounts[0].existed && accounts[0].exists);
+        assert_eq!(accounts[0].lamports_delta, -2_500);
+        assert!(!accounts[1].existed);
+        assert_eq!(accounts[1].lamports_delta, 2_000);
+        assert_eq!(accounts[1].data, vec![1, 2, 3]);
+        assert_eq!(accounts[1].owner, solana_sdk::system_program::id().to_string());
+    }
+
+    #[test]
+    fn test_closed_account() {
+        let closed = Pubkey::new_unique();
+        let accounts = simulated_accounts(&[closed], &[Some(account(5, vec![]))], &[None]);
+
+        assert!(accounts[0].existed);
+        assert!(!accounts[0].exists);
+        assert_eq!(accounts[0].lamports_delta, -5);
+        assert!(accounts[0].owner.is_empty());
+    }
+
+    #[test]
+    fn test_lamports_delta_saturates() {
+        assert_eq!(lamports_delta(0, u64::MAX), i64::MAX);
+        assert_eq!(lamports_delta(u64::MAX, 0), i64::MIN);
+    }
+
+    #[test]
+    fn test_return_data() {
+        let return_data = UiTransactionReturnData {
+            program_id: "prog".to_string(),
+            data: (STANDARD.encode([7, 8]), UiReturnDataEncoding::Base64),
+        };
+        assert_eq!(return_data_to_proto(&return_data).data, vec![7, 8]);
+    }
+
+    #[test]
+    fn test_simulation_cache_key() {
+        let payer = Pubkey::new_unique();
+        let blockhash = Hash::new_unique().to_string();
+        let first = simulation_transaction(
+            &draft(&payer, &blockhash),
+            &payer.to_string(),
+            SimulationOptions::default(),
+        )
+        .unwrap();
+        let req = SimulateTransactionRequest {
+            use_cache: true,
+            ..Default::default()
+        };
+
+        let key = simulation_cache_key(&req, &first, 8);
+        assert_eq!(key.message_hash.len(), 64);
+        assert_eq!(key.slot_bucket, 8);
+        assert!(key.signatures.is_empty());
+        assert_eq!(key, simulation_cache_key(&req, &first, 8));
+
+        let other = simulation_transaction(
+            &draft(&payer, &Hash::new_unique().to_string()),
+            &payer.to_string(),
+            SimulationOptions::default(),
+        )
+        .unwrap();
+        assert_ne!(key.message_hash, message_hash(&other));
+
+        let verified = SimulateTransactionRequest {
+            sig_verify: true,
+            ..req
+        };
+        assert_eq!(simulation_cache_key(&verified, &first, 8).signatures.len(), 1);
+    }
+}
@@ -0,0 +1,305 @@
+use solana_sdk::{instruction::Instruction, pubkey::Pubkey};
+use std::ops::Range;
+
+use crate::api::transaction::v1::compute_budget::{with_compute_budget, MAX_COMPUTE_UNIT_LIMIT};
+use crate::api::transaction::v1::diagnostics::{
+    diagnose_instructions, MAX_ACCOUNT_LOCKS, MAX_TRANSACTION_SIZE,
+};
+
+/// Compute units assumed for an instruction without an estimate, the runtime's default
+/// per-instruction budget
+pub const DEFAULT_INSTRUCTION_COMPUTE_UNITS: u64 = 200_000;
+
+/// Limits and ordering hints for splitting an instruction list
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct SplitOptions {
+    /// Instruction ranges that must share a transaction
+    pub atomic_ranges: Vec<Range<usize>>,
+    /// `(instruction, dependency)` pairs where the dependency must confirm first
+    pub dependencies: Vec<(usize, usize)>,
+    /// Per-instruction compute estimates, where missing or zero selects the default
+    pub compute_units: Vec<u64>,
+    /// Per-transaction compute budget, where zero selects the runtime maximum
+    pub max_compute_units: u64,
+    /// Whether to leave room for the compute budget instructions added at compile time
+    pub reserve_compute_budget: bool,
+}
+
+/// One transaction of a split
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SplitBatch {
+    /// Contiguous run of instruction indices
+    pub instructions: Range<usize>,
+    /// Serialized size once signed
+    pub size_bytes: usize,
+    /// Sum of the instructions' compute estimates
+    pub compute_units: u64,
+    /// Earlier batches that must confirm before this one is sent
+    pub depends_on: Vec<usize>,
+}
+
+/// Splits `instructions` into the fewest transactions that fit the packet size, account
+/// lock and compute limits without reordering them.
+///
+/// Batches are packed greedily, which is optimal for contiguous runs since adding an
+/// instruction never makes a transaction smaller. A new batch is also started where an
+/// instruction depends on one already in the current batch.
+pub fn split_instructions(
+    instructions: &[Instruction],
+    fee_payer: &Pubkey,
+    options: &SplitOptions,
+) -> Result<Vec<SplitBatch>, String> {
+    if instructions.is_empty() {
+        return Err("At least one instruction is required".to_string());
+    }
+    let units = atomic_units(instructions.len(), &options.atomic_ranges)?;
+    validate_dependencies(instructions.len(), &units, &options.dependencies)?;
+
+    let max_compute_units = if options.max_compute_units == 0 {
+        u64::from(MAX_COMPUTE_UNIT_LIMIT)
+    } else {
+        options.max_compute_units
+    };
+    let measure = |range: Range<usize>| -> Option<SplitBatch> {
+        let compute_units: u64 = range
+            .clone()
+            .map(|index| {
+                options
+                    .compute_units
+                    .get(index)
+                    .copied()
+                    .filter(|units| *units > 0)
+                    .unwrap_or(DEFAULT_INSTRUCTION_COMPUTE_UNITS)
+            })
+            .sum();
+        let batch_instructions = if options.reserve_compute_budget {
+            with_compute_budget(&instructions[range.clone()], MAX_COMPUTE_UNIT_LIMIT, 1)
+        } else {
+            instructions[range.clone()].to_vec()
+        };
+        let report = diagnose_instructions(&batch_instructions, fee_payer);
+
+        let fits = report.serialized_size <= MAX_TRANSACTION_SIZE
+            && report.account_count <= MAX_ACCOUNT_LOCKS
+            && compute_units <= max_compute_units;
+        fits.then_some(SplitBatch {
+            instructions: range,
+            size_bytes: report.serialized_size,
+            compute_units,
+            depends_on: Vec::new(),
+        })
+    };
+
+    let mut batches: Vec<SplitBatch> = Vec::new();
+    let mut current: Option<SplitBatch> = None;
+    for unit in units {
+        if let Some(batch) = current.take() {
+            let start = batch.instructions.start;
+            let depends_on_current = options.dependencies.iter().any(|(index, dependency)| {
+                unit.contains(index) && batch.instructions.contains(dependency)
+            });
+            let extended = if depends_on_current {
+                None
+            } else {
+                measure(start..unit.end)
+            };
+            if let Some(extended) = extended {
+                current = Some(extended);
+                continue;
+            }
+            batches.push(batch);
+        }
+        current = Some(measure(unit.clone()).ok_or_else(|| {
+            format!("Instructions {}..{} do not fit in one transaction", unit.start, unit.end)
+        })?);
+    }
+    batches.extend(current);
+
+    for index in 0..batches.len() {
+        let mut depends_on: Vec<usize> = options
+            .dependencies
+            .iter()
+            .filter(|(instruction, _)| batches[index].instructions.contains(instruction))
+            .filter_map(|(_, dependency)| {
+                batches
+                    .iter()
+                    .position(|batch| batch.instructions.contains(dependency))
+            })
+            .filter(|batch| *batch != index)
+            .collect();
+        depends_on.sort_unstable();
+        depends_on.dedup();
+        batches[index].depends_on = depends_on;
+    }
+    Ok(batches)
+}
+
+/// Partitions `0..len` into the smallest contiguous units no atomic range crosses
+fn atomic_units(len: usize, atomic_ranges: &[Range<usize>]) -> Result<Vec<Range<usize>>, String> {
+    // joined[i] is true when instruction i must share a transaction with instruction i + 1
+    let mut joined = vec![false; len];
+    for range in atomic_ranges {
+        if range.start >= range.end || range.end > len {
+            return Err(format!(
+                "Atomic range {}..{} must be non-empty and within the {len} instructions",
+                range.start, range.end
+            ));
+        }
+        for flag in &mut joined[range.start..range.end - 1] {
+            *flag = true;
+        }
+    }
+
+    let mut units = Vec::new();
+    let mut start = 0;
+    for (index, joined) in joined.iter().enumerate() {
+        if !joined {
+            units.push(start..index + 1);
+            start = index + 1;
+        }
+    }
+    Ok(units)
+}
+
+/// Checks that every dependency points at an earlier instruction outside the dependent's
+/// atomic unit, since it must confirm in an earlier transaction
+fn validate_dependencies(
+    len: usize,
+    units: &[Range<usize>],
+    dependencies: &[(usize, usize)],
+) -> Result<(), String> {
+    for (index, dependency) in dependencies {
+        if *index >= len {
+            return Err(format!("Dependency references instruction {index}, out of range"));
+        }
+        if dependency >= index {
+            return Err(format!(
+                "Instruction {index} cannot depend on instruction {dependency}: dependencies \
+                 must come earlier"
+            ));
+        }
+        if units
+            .iter()
+            .any(|unit| unit.contains(index) && unit.contains(dependency))
+        {
+            return Err(format!(
+                "Instruction {index} depends on instruction {dependency} in the same atomic range"
+            ));
+        }
+    }
+    Ok(())
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::system_instruction;
+
+    /// Transfers to distinct recipients, each adding an account to the transaction
+    fn transfers(payer: &Pubkey, count: usize) -> Vec<Instruction> {
+        (0..count)
+            .map(|_| system_instruction::transfer(payer, &Pubkey::new_unique(), 1))
+            .collect()
+    }
+
+    #[test]
+    fn test_small_list_stays_in_one_transaction() {
+        let payer = Pubkey::new_unique();
+        let batches =
+            split_instructions(&transfers(&payer, 3), &payer, &SplitOptions::default()).unwrap();
+
+        assert_eq!(batches.len(), 1);
+        assert_eq!(batches[0].instructions, 0..3);
+        assert_eq!(batches[0].compute_units, 3 * DEFAULT_INSTRUCTION_COMPUTE_UNITS);
+        assert!(batches[0].size_bytes <= MAX_TRANSACTION_SIZE);
+    }
+
+    #[test]
+    fn test_splits_on_size_keeping_order() {
+        let payer = Pubkey::new_unique();
+        let instructions = transfers(&payer, 40);
+        let options = SplitOptions {
+            compute_units: vec![1; 40],
+            ..Default::default()
+        };
+        let batches = split_instructions(&instructions, &payer, &options).unwrap();
+
+        assert!(batches.len() > 1);
+        assert_eq!(batches[0].instructions.start, 0);
+        assert_eq!(batches.last().unwrap().instructions.end, 40);
+        for pair in batches.windows(2) {
+            assert_eq!(pair[0].instructions.end, pair[1].instructions.start);
+        }
+        assert!(batches
+            .iter()
+            .all(|batch| batch.size_bytes <= MAX_TRANSACTION_SIZE));
+    }
+
+    #[test]
+    fn test_splits_on_compute_budget() {
+        let payer = Pubkey::new_unique();
+        let options = SplitOptions {
+            compute_units: vec![600_000, 600_000, 600_000],
+            ..Default::default()
+        };
+        let batches = split_instructions(&transfers(&payer, 3), &payer, &options).unwrap();
+
+        assert_eq!(batches.len(), 2);
+        assert_eq!(batches[0].instructions, 0..2);
+        assert_eq!(batches[1].compute_units, 600_000);
+    }
+
+    #[test]
+    fn test_atomic_ranges_are_not_split() {
+        let payer = Pubkey::new_unique();
+        let options = SplitOptions {
+            atomic_ranges: vec![1..3],
+            compute_units: vec![600_000, 600_000, 600_000],
+            ..Default::default()
+        };
+        let batches = split_instructions(&transfers(&payer, 3), &payer, &options).unwrap();
+
+        assert_eq!(batches.len(), 2);
+        assert_eq!(batches[0].instructions, 0..1);
+        assert_eq!(batches[1].instructions, 1..3);
+    }
+
+    #[test]
+    fn test_dependencies_start_new_transactions() {
+        let payer = Pubkey::new_unique();
+        let options = SplitOptions {
+            dependencies: vec![(2, 0)],
+            ..Default::default()
+        };
+        let batches = split_instructions(&transfers(&payer, 4), &payer, &options).unwrap();
+
+        assert_eq!(batches.len(), 2);
+        assert_eq!(batches[0].instructions, 0..2);
+        assert_eq!(batches[1].instructions, 2..4);
+        assert_eq!(batches[1].depends_on, vec![0]);
+    }
+
+    #[test]
+    fn test_rejects_invalid_hints() {
+        let payer = Pubkey::new_unique();
+        let instructions = transfers(&payer, 3);
+        let forward = SplitOptions {
+            dependencies: vec![(0, 1)],
+            ..Default::default()
+        };
+        let out_of_range = SplitOptions {
+            atomic_ranges: vec![2..5],
+            ..Default::default()
+        };
+        let over_budget = SplitOptions {
+            compute_units: vec![2_000_000],
+            ..Default::default()
+        };
+
+        assert!(split_instructions(&instructions, &payer, &forward).is_err());
+        assert!(split_instructions(&instructions, &payer, &out_of_range).is_err());
+        assert!(split_instructions(&instructions, &payer, &over_budget).is_err());
+        assert!(split_instructions(&[], &payer, &SplitOptions::default()).is_err());
+    }
+}
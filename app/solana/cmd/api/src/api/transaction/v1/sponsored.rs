@@ -0,0 +1,100 @@
+use solana_sdk::{
+    instruction::Instruction,
+    pubkey::Pubkey,
+    signature::{Keypair, Signature, Signer},
+    transaction::Transaction as SolanaTransaction,
+};
+
+/// Whether any instruction names `sponsor` as its program or one of its accounts.
+///
+/// A sponsor may only pay fees: as an instruction account it could be made to sign a
+/// transfer out of its own balance.
+pub fn references_sponsor(instructions: &[Instruction], sponsor: &Pubkey) -> bool {
+    instructions.iter().any(|instruction| {
+        instruction.program_id == *sponsor
+            || instruction
+                .accounts
+                .iter()
+                .any(|account| account.pubkey == *sponsor)
+    })
+}
+
+/// Adds the sponsor's fee payer signature to a transaction every other signer has signed
+pub fn add_sponsor_signature(
+    transaction: &mut SolanaTransaction,
+    sponsor: &Keypair,
+) -> Result<(), String> {
+    if transaction.message.account_keys.first() != Some(&sponsor.pubkey()) {
+        return Err("The sponsor is not the transaction's fee payer".to_string());
+    }
+    if transaction
+        .signatures
+        .iter()
+        .skip(1)
+        .any(|signature| *signature == Signature::default())
+    {
+        return Err("Every signer other than the sponsor must sign first".to_string());
+    }
+
+    let signature = sponsor.sign_message(&transaction.message_data());
+    let Some(fee_payer_signature) = transaction.signatures.first_mut() else {
+        return Err("Transaction has no signature slots".to_string());
+    };
+    *fee_payer_signature = signature;
+    Ok(())
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::{hash::Hash, instruction::AccountMeta, message::Message};
+
+    fn co_signed(sponsor: &Pubkey, authority: &Pubkey) -> SolanaTransaction {
+        let instruction = Instruction::new_with_bytes(
+            Pubkey::new_unique(),
+            &[],
+            vec![AccountMeta::new_readonly(*authority, true)],
+        );
+        SolanaTransaction::new_unsigned(Message::new_with_blockhash(
+            &[instruction],
+            Some(sponsor),
+            &Hash::new_unique(),
+        ))
+    }
+
+    #[test]
+    fn test_references_sponsor() {
+        let sponsor = Pubkey::new_unique();
+        let transfer = solana_sdk::system_instruction::transfer(&sponsor, &Pubkey::new_unique(), 1);
+        let other = solana_sdk::system_instruction::transfer(
+            &Pubkey::new_unique(),
+            &Pubkey::new_unique(),
+            1,
+        );
+
+        assert!(references_sponsor(&[other.clone(), transfer], &sponsor));
+        assert!(!references_sponsor(&[other], &sponsor));
+    }
+
+    #[test]
+    fn test_sponsor_signs_last() {
+        let sponsor = Keypair::new();
+        let authority = Keypair::new();
+        let mut transaction = co_signed(&sponsor.pubkey(), &authority.pubkey());
+
+        assert!(add_sponsor_signature(&mut transaction, &sponsor).is_err());
+
+        let blockhash = transaction.message.recent_blockhash;
+        transaction.partial_sign(&[&authority], blockhash);
+        add_sponsor_signature(&mut transaction, &sponsor).unwrap();
+        assert!(transaction.verify().is_ok());
+    }
+
+    #[test]
+    fn test_rejects_other_fee_payer() {
+        let sponsor = Keypair::new();
+        let mut transaction = co_signed(&Pubkey::new_unique(), &Pubkey::new_unique());
+        assert!(add_sponsor_signature(&mut transaction, &sponsor).is_err());
+    }
+}
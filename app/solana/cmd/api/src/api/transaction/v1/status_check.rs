@@ -0,0 +1,144 @@
+use solana_client::rpc_client::RpcClient;
+use solana_sdk::{commitment_config::CommitmentConfig, hash::Hash, signature::Signature};
+use solana_transaction_status::{TransactionConfirmationStatus, TransactionStatus};
+
+use protochain_api::protochain::solana::transaction::v1::{
+    CheckTransactionStatusResponse, TransactionCheckResult,
+};
+
+/// Classifies a signature status reported by the node.
+///
+/// A transaction only seen at processed commitment may still be dropped with its fork,
+/// so it is reported as pending rather than landed.
+fn landed_result(status: &TransactionStatus) -> TransactionCheckResult {
+    if status.err.is_some() {
+        return TransactionCheckResult::Failed;
+    }
+    match status.confirmation_status() {
+        TransactionConfirmationStatus::Finalized => TransactionCheckResult::Finalized,
+        TransactionConfirmationStatus::Confirmed => TransactionCheckResult::Confirmed,
+        TransactionConfirmationStatus::Processed => TransactionCheckResult::StillPending,
+    }
+}
+
+/// Builds the response for a signature status, or for a signature the node has not seen
+/// given whether its blockhash is still valid
+fn check_response(
+    status: Option<&TransactionStatus>,
+    blockhash_valid: bool,
+) -> CheckTransactionStatusResponse {
+    status.map_or_else(
+        || CheckTransactionStatusResponse {
+            result: if blockhash_valid {
+                TransactionCheckResult::StillPending
+            } else {
+                TransactionCheckResult::ExpiredNotLanded
+            }
+            .into(),
+            blockhash_valid,
+            ..Default::default()
+        },
+        |status| CheckTransactionStatusResponse {
+            result: landed_result(status).into(),
+            slot: status.slot,
+            error_message: status
+                .err
+                .as_ref()
+                .map(ToString::to_string)
+                .unwrap_or_default(),
+            blockhash_valid,
+        },
+    )
+}
+
+/// Looks up a signature, searching the node's full status history
+fn signature_status(
+    rpc_client: &RpcClient,
+    signature: &Signature,
+) -> Result<Option<TransactionStatus>, String> {
+    rpc_client
+        .get_signature_statuses_with_history(&[*signature])
+        .map(|response| response.value.into_iter().next().flatten())
+        .map_err(|e| format!("Failed to get signature status: {e}"))
+}
+
+/// Resolves whether a submitted transaction landed, is still pending or can no longer land.
+///
+/// A signature the node has not seen is pending while its blockhash is valid. Once the
+/// blockhash has expired the transaction can never be processed, so the status is read
+/// again to rule out a landing between the two calls before reporting it as expired;
+/// an expired, unlanded transaction is safe to rebuild and resubmit.
+pub fn check_transaction_status(
+    rpc_client: &RpcClient,
+    signature: &Signature,
+    blockhash: &Hash,
+) -> Result<CheckTransactionStatusResponse, String> {
+    if let Some(status) = signature_status(rpc_client, signature)? {
+        let blockhash_valid = rpc_client
+            .is_blockhash_valid(blockhash, CommitmentConfig::processed())
+            .unwrap_or(false);
+        return Ok(check_response(Some(&status), blockhash_valid));
+    }
+
+    let blockhash_valid = rpc_client
+        .is_blockhash_valid(blockhash, CommitmentConfig::processed())
+        .map_err(|e| format!("Failed to check blockhash validity: {e}"))?;
+    if blockhash_valid {
+        return Ok(check_response(None, true));
+    }
+
+    let status = signature_status(rpc_client, signature)?;
+    Ok(check_response(status.as_ref(), false))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use solana_sdk::{instruction::InstructionError, transaction::TransactionError};
+
+    fn status(confirmation_status: TransactionConfirmationStatus) -> TransactionStatus {
+        TransactionStatus {
+            slot: 42,
+            confirmations: None,
+            status: Ok(()),
+            err: None,
+            confirmation_status: Some(confirmation_status),
+        }
+    }
+
+    #[test]
+    fn test_landed_statuses() {
+        let finalized =
+            check_response(Some(&status(TransactionConfirmationStatus::Finalized)), false);
+        assert_eq!(finalized.result(), TransactionCheckResult::Finalized);
+        assert_eq!(finalized.slot, 42);
+
+        let confirmed =
+            check_response(Some(&status(TransactionConfirmationStatus::Confirmed)), true);
+        assert_eq!(confirmed.result(), TransactionCheckResult::Confirmed);
+
+        let processed =
+            check_response(Some(&status(TransactionConfirmationStatus::Processed)), true);
+        assert_eq!(processed.result(), TransactionCheckResult::StillPending);
+    }
+
+    #[test]
+    fn test_failed_status() {
+        let error = TransactionError::InstructionError(0, InstructionError::Custom(1));
+        let failed = TransactionStatus {
+            status: Err(error.clone()),
+            err: Some(error),
+            ..status(TransactionConfirmationStatus::Confirmed)
+        };
+
+        let response = check_response(Some(&failed), true);
+        assert_eq!(response.result(), TransactionCheckResult::Failed);
+        assert!(!response.error_message.is_empty());
+    }
+
+    #[test]
+    fn test_unseen_signature() {
+        assert_eq!(check_response(None, true).result(), TransactionCheckResult::StillPending);
+        assert_eq!(check_response(None, false).result(), TransactionCheckResult::ExpiredNotLanded);
+    }
+}
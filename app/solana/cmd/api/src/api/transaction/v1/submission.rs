@@ -0,0 +1,293 @@
+use solana_client::rpc_client::RpcClient;
+use solana_client::rpc_config::RpcSendTransactionConfig;
+use solana_sdk::{
+    commitment_config::CommitmentConfig, transaction::Transaction as SolanaTransaction,
+};
+use solana_transaction_status::UiTransactionEncoding;
+use std::time::Duration;
+use tracing::{error, info, warn};
+
+use crate::api::transaction::v1::error_attribution::attribute_client_error;
+use crate::api::transaction::v1::error_builder;
+use crate::api::transaction::v1::service_impl::classify_submission_error;
+use crate::service_providers::unix_timestamp;
+use protochain_api::protochain::solana::transaction::v1::{
+    RetryPolicy, SubmissionAttempt, SubmissionResult, Transaction, TransactionError,
+};
+
+/// Default number of send attempts for a managed submission
+pub const DEFAULT_MAX_ATTEMPTS: u32 = 3;
+/// Upper bound on send attempts for a managed submission
+pub const MAX_ATTEMPTS: u32 = 10;
+/// Default delay between attempts of a managed submission
+pub const DEFAULT_BACKOFF_MS: u32 = 500;
+/// Upper bound on the delay between attempts
+const MAX_BACKOFF_MS: u32 = 30_000;
+/// Default number of times the RPC node itself resends a submitted transaction
+pub const DEFAULT_NODE_MAX_RETRIES: u32 = 3;
+
+/// How the RPC node handles each send of a submission
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct SendOptions {
+    /// Skip the node's preflight simulation
+    pub skip_preflight: bool,
+    /// Commitment the preflight simulation runs at
+    pub preflight_commitment: CommitmentConfig,
+    /// How many times the node resends the transaction before dropping it
+    pub max_retries: u32,
+}
+
+impl SendOptions {
+    /// Preflight at the submission's commitment with the node's default resends
+    pub const fn for_commitment(commitment: CommitmentConfig) -> Self {
+        Self {
+            skip_preflight: false,
+            preflight_commitment: commitment,
+            max_retries: DEFAULT_NODE_MAX_RETRIES,
+        }
+    }
+
+    /// Honors the `skip_preflight` setting of the transaction's config
+    pub fn with_transaction_config(mut self, transaction: &Transaction) -> Self {
+        self.skip_preflight |= transaction
+            .config
+            .as_ref()
+            .is_some_and(|config| config.skip_preflight);
+        self
+    }
+
+    /// The `sendTransaction` configuration for these options
+    fn send_config(self) -> RpcSendTransactionConfig {
+        RpcSendTransactionConfig {
+            skip_preflight: self.skip_preflight,
+            preflight_commitment: Some(self.preflight_commitment.commitment),
+            encoding: Some(UiTransactionEncoding::Base64),
+            max_retries: Some(self.max_retries as usize),
+            min_context_slot: None,
+        }
+    }
+}
+
+/// How many times a signed transaction is sent, and how long to wait between sends
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct RetrySchedule {
+    max_attempts: u32,
+    backoff: Duration,
+}
+
+impl RetrySchedule {
+    /// Schedule for unmanaged submissions: a single send, no retries
+    pub const fn single_attempt() -> Self {
+        Self {
+            max_attempts: 1,
+            backoff: Duration::ZERO,
+        }
+    }
+
+    /// Builds a schedule from a request policy, where zero values select the defaults
+    pub fn from_policy(policy: &RetryPolicy) -> Result<Self, String> {
+        let max_attempts = if policy.max_attempts == 0 {
+            DEFAULT_MAX_ATTEMPTS
+        } else {
+            policy.max_attempts
+        };
+        let backoff_ms = if policy.backoff_ms == 0 {
+            DEFAULT_BACKOFF_MS
+        } else {
+            policy.backoff_ms
+        };
+
+        if max_attempts > MAX_ATTEMPTS {
+            return Err(format!("Max attempts must not exceed {MAX_ATTEMPTS}"));
+        }
+        if backoff_ms > MAX_BACKOFF_MS {
+            return Err(format!("Backoff must not exceed {MAX_BACKOFF_MS} ms"));
+        }
+
+        Ok(Self {
+            max_attempts,
+            backoff: Duration::from_millis(u64::from(backoff_ms)),
+        })
+    }
+
+    /// Total number of sends allowed
+    pub const fn max_attempts(&self) -> u32 {
+        self.max_attempts
+    }
+}
+
+/// Result of sending a signed transaction under a retry schedule
+#[derive(Debug, Clone)]
+pub struct SubmissionOutcome {
+    /// Transaction signature (empty if no attempt reached the network)
+    pub signature: String,
+    /// Outcome of the final attempt
+    pub submission_result: SubmissionResult,
+    /// Structured error of the final attempt, if it failed
+    pub structured_error: Option<TransactionError>,
+    /// Every attempt made, in order
+    pub attempts: Vec<SubmissionAttempt>,
+}
+
+impl SubmissionOutcome {
+    /// Whether the final attempt was accepted by the network
+    pub fn succeeded(&self) -> bool {
+        self.submission_result == SubmissionResult::Submitted
+    }
+}
+
+/// Sends a fully signed transaction, resending on retryable failures per `schedule`.
+///
+/// Every attempt resends the same signed bytes, so the signature is stable and a
+/// transaction that did land on an earlier attempt cannot be processed twice.
+pub async fn submit_with_retries(
+    rpc_client: &RpcClient,
+    transaction: &SolanaTransaction,
+    options: SendOptions,
+    schedule: RetrySchedule,
+) -> SubmissionOutcome {
+    let mut attempts = Vec::new();
+
+    for attempt in 1..=schedule.max_attempts {
+        let attempted_at = unix_timestamp();
+        match rpc_client.send_transaction_with_config(transaction, options.send_config()) {
+            Ok(signature) => {
+                info!(
+                    signature = %signature,
+                    attempt,
+                    skip_preflight = options.skip_preflight,
+                    "✅ Transaction submitted successfully (asynchronously)"
+                );
+
+                attempts.push(SubmissionAttempt {
+                    attempt,
+                    submission_result: SubmissionResult::Submitted.into(),
+                    structured_error: None,
+                    attempted_at,
+                });
+                return SubmissionOutcome {
+                    signature: signature.to_string(),
+                    submission_result: SubmissionResult::Submitted,
+                    structured_error: None,
+                    attempts,
+                };
+            }
+            Err(e) => {
+                let classification = classify_submission_error(&e);
+
+                // Get current slot for blockhash resolution
+                let current_slot = rpc_client.get_slot().unwrap_or(0);
+
+                let mut structured_err = error_builder::build_structured_error(
+                    &e,
+                    classification,
+                    &transaction.message.recent_blockhash,
+                    current_slot,
+                );
+                structured_err.instruction_failure = attribute_client_error(
+                    &e,
+                    &transaction.message.account_keys,
+                    &transaction.message.instructions,
+                );
+
+                error!(
+                    error = %e,
+                    attempt,
+                    max_attempts = schedule.max_attempts,
+                    skip_preflight = options.skip_preflight,
+                    classification = ?classification,
+                    certainty = ?structured_err.certainty,
+                    retryable = structured_err.retryable,
+                    "Transaction submission failed"
+                );
+
+                let retryable = structured_err.retryable;
+                attempts.push(SubmissionAttempt {
+                    attempt,
+                    submission_result: classification.into(),
+                    structured_error: Some(structured_err.clone()),
+                    attempted_at,
+                });
+
+                if !retryable || attempt == schedule.max_attempts {
+                    return SubmissionOutcome {
+                        signature: String::new(),
+                        submission_result: classification,
+                        structured_error: Some(structured_err),
+                        attempts,
+                    };
+                }
+
+                warn!(
+                    attempt,
+                    backoff_ms = schedule.backoff.as_millis(),
+                    "Retrying transaction submission"
+                );
+                tokio::time::sleep(schedule.backoff).await;
+            }
+        }
+    }
+
+    // Unreachable for schedules with at least one attempt
+    SubmissionOutcome {
+        signature: String::new(),
+        submission_result: SubmissionResult::Unspecified,
+        structured_error: None,
+        attempts,
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_policy_defaults() {
+        let schedule = RetrySchedule::from_policy(&RetryPolicy::default()).unwrap();
+        assert_eq!(schedule.max_attempts(), DEFAULT_MAX_ATTEMPTS);
+        assert_eq!(schedule.backoff, Duration::from_millis(u64::from(DEFAULT_BACKOFF_MS)));
+    }
+
+    #[test]
+    fn test_policy_bounds() {
+        assert!(RetrySchedule::from_policy(&RetryPolicy {
+            max_attempts: MAX_ATTEMPTS + 1,
+            backoff_ms: 0,
+        })
+        .is_err());
+        assert!(RetrySchedule::from_policy(&RetryPolicy {
+            max_attempts: 0,
+            backoff_ms: 60_000,
+        })
+        .is_err());
+        assert!(RetrySchedule::from_policy(&RetryPolicy {
+            max_attempts: MAX_ATTEMPTS,
+            backoff_ms: 100,
+        })
+        .is_ok());
+    }
+
+    #[test]
+    fn test_single_attempt() {
+        assert_eq!(RetrySchedule::single_attempt().max_attempts(), 1);
+    }
+
+    #[test]
+    fn test_send_config_carries_preflight_options() {
+        let defaults = SendOptions::for_commitment(CommitmentConfig::finalized()).send_config();
+        assert!(!defaults.skip_preflight);
+        assert_eq!(defaults.preflight_commitment, Some(CommitmentConfig::finalized().commitment));
+        assert_eq!(defaults.max_retries, Some(3));
+
+        let custom = SendOptions {
+            skip_preflight: true,
+            preflight_commitment: CommitmentConfig::processed(),
+            max_retries: 0,
+        }
+        .send_config();
+        assert!(custom.skip_preflight);
+        assert_eq!(custom.preflight_commitment, Some(CommitmentConfig::processed().commitment));
+        assert_eq!(custom.max_retries, Some(0));
+    }
+}
@@ -12,14 +12,60 @@ pub struct TransactionV1API {
 impl TransactionV1API {
     /// Creates a new `TransactionV1API` instance with the provided service providers
     pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
-        // Extract the specific dependencies (RPC client and WebSocket manager) from service providers
+        // Extract the dependencies this service needs from service providers
         let rpc_client = service_providers.solana_clients.get_rpc_client();
         let websocket_manager = service_providers.websocket_manager.clone();
+        let dead_letters = Arc::clone(&service_providers.dead_letters);
+        let key_vault = Arc::clone(&service_providers.key_vault);
+        let idempotency = Arc::clone(&service_providers.idempotency);
+        let rebroadcasts = Arc::clone(&service_providers.rebroadcasts);
+        let submissions = Arc::clone(&service_providers.submissions);
+        let sponsorship = Arc::clone(&service_providers.sponsorship);
+        let auth = Arc::clone(&service_providers.auth);
+        let operations = Arc::clone(&service_providers.operations);
+        let feature_flags = Arc::clone(&service_providers.feature_flags);
+        let jito = Arc::clone(&service_providers.jito);
+        let rpc_limiter = Arc::clone(&service_providers.rpc_limiter);
+        let rpc_router = service_providers.solana_clients.get_rpc_router();
+        let admission = Arc::clone(&service_providers.admission);
+        let submission_tokens = Arc::clone(&service_providers.submission_tokens);
+        let transaction_queue = Arc::clone(&service_providers.transaction_queue);
+        let hardware_wallet = Arc::clone(&service_providers.hardware_wallet);
+        let kms = Arc::clone(&service_providers.kms);
+        let vault = Arc::clone(&service_providers.vault);
+        let keystore = Arc::clone(&service_providers.keystore);
+        let simulation_cache = Arc::clone(&service_providers.simulation_cache);
+        let message_catalog = Arc::clone(&service_providers.message_catalog);
+        let dry_run = service_providers.submission_dry_run();
+        let require_token = service_providers.submission_require_token();
 
         Self {
             transaction_service: Arc::new(TransactionServiceImpl::new(
                 rpc_client,
                 websocket_manager,
+                dead_letters,
+                key_vault,
+                idempotency,
+                rebroadcasts,
+                submissions,
+                sponsorship,
+                auth,
+                operations,
+                feature_flags,
+                jito,
+                rpc_limiter,
+                rpc_router,
+                admission,
+                submission_tokens,
+                transaction_queue,
+                hardware_wallet,
+                kms,
+                vault,
+                keystore,
+                simulation_cache,
+                message_catalog,
+                dry_run,
+                require_token,
             )),
         }
     }
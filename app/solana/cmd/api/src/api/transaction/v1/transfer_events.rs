@@ -0,0 +1,407 @@
+use protochain_api::protochain::solana::transaction::v1::{
+    decoded_instruction::Details, DecodedInstruction, InnerInstructions, TransferEvent,
+    TransferKind,
+};
+use solana_sdk::{pubkey, pubkey::Pubkey};
+use solana_transaction_status::{UiTransactionStatusMeta, UiTransactionTokenBalance};
+use std::collections::{HashMap, HashSet};
+use std::str::FromStr;
+
+use crate::api::transaction::v1::description::{format_amount, SOL_DECIMALS};
+
+/// Mint of wrapped SOL, which stands for native transfers in a mint filter
+pub const WRAPPED_SOL_MINT: Pubkey = pubkey!("So11111111111111111111111111111111111111112");
+/// Most addresses, and most mints, a transfer filter may name
+pub const MAX_FILTER_ENTRIES: usize = 100;
+
+/// Which transfers a MonitorTransfers stream reports
+#[derive(Debug, Clone)]
+pub struct TransferFilter {
+    addresses: HashSet<String>,
+    mints: HashSet<String>,
+    min_amount: u64,
+}
+
+impl TransferFilter {
+    /// Builds a filter from request fields, rejecting invalid addresses and a filter
+    /// that names neither addresses nor mints (it would match every transfer on chain)
+    pub fn from_request(
+        addresses: &[String],
+        mints: &[String],
+        min_amount: u64,
+    ) -> Result<Self, String> {
+        if addresses.is_empty() && mints.is_empty() {
+            return Err("At least one address or mint is required".to_string());
+        }
+        if addresses.len() > MAX_FILTER_ENTRIES || mints.len() > MAX_FILTER_ENTRIES {
+            return Err(format!(
+                "At most {MAX_FILTER_ENTRIES} addresses and {MAX_FILTER_ENTRIES} mints can be watched"
+            ));
+        }
+        for address in addresses {
+            Pubkey::from_str(address).map_err(|e| format!("Invalid address {address}: {e}"))?;
+        }
+        for mint in mints {
+            Pubkey::from_str(mint).map_err(|e| format!("Invalid mint {mint}: {e}"))?;
+        }
+
+        Ok(Self {
+            addresses: addresses.iter().cloned().collect(),
+            mints: mints.iter().cloned().collect(),
+            min_amount,
+        })
+    }
+
+    /// Whether `event` passes every filter given
+    pub fn matches(&self, event: &TransferEvent) -> bool {
+        if event.amount < self.min_amount {
+            return false;
+        }
+        if !self.mints.is_empty() {
+            let mint = if event.kind == TransferKind::Native as i32 {
+                WRAPPED_SOL_MINT.to_string()
+            } else {
+                event.mint.clone()
+            };
+            if !self.mints.contains(&mint) {
+                return false;
+            }
+        }
+        self.addresses.is_empty()
+            || [
+                &event.source,
+                &event.destination,
+                &event.source_owner,
+                &event.destination_owner,
+            ]
+            .into_iter()
+            .any(|address| !address.is_empty() && self.addresses.contains(address))
+    }
+}
+
+/// Mint, owner and decimals of a token account, as the node reported them
+#[derive(Debug, Clone, Default)]
+struct TokenAccountInfo {
+    mint: String,
+    owner: String,
+    decimals: u32,
+}
+
+/// Block a transaction was found in
+#[derive(Debug, Clone, Copy)]
+pub struct BlockContext {
+    /// Slot of the block
+    pub slot: u64,
+    /// Unix timestamp of the block (0 if unknown)
+    pub block_time: i64,
+}
+
+/// Every transfer a successful transaction made, in execution order: each top-level
+/// instruction followed by the transfers its inner instructions made.
+///
+/// `meta` supplies the mint, owner and decimals of the token accounts involved, which
+/// a plain `Transfer` instruction does not carry; `account_keys` must be the full account
+/// list its balances are indexed by (static keys, then loaded addresses).
+pub fn transaction_transfers(
+    signature: &str,
+    block: BlockContext,
+    account_keys: &[Pubkey],
+    top_level: &[DecodedInstruction],
+    inner_instructions: &[InnerInstructions],
+    meta: &UiTransactionStatusMeta,
+) -> Vec<TransferEvent> {
+    let token_accounts = token_accounts(account_keys, meta);
+    let mut events = Vec::new();
+    for instruction in top_level {
+        let base = TransferEvent {
+            signature: signature.to_string(),
+            slot: block.slot,
+            block_time: block.block_time,
+            instruction_index: instruction.index,
+            ..Default::default()
+        };
+        events.extend(transfer_event(instruction, base.clone(), &token_accounts));
+
+        for inner in inner_instructions
+            .iter()
+            .filter(|inner| inner.instruction_index == instruction.index)
+        {
+            for (position, invoked) in inner.instructions.iter().enumerate() {
+                let Some(invoked) = invoked.instruction.as_ref() else {
+                    continue;
+                };
+                let base = TransferEvent {
+                    inner_instruction_index: Some(u32::try_from(position).unwrap_or(u32::MAX)),
+                    ..base.clone()
+                };
+                events.extend(transfer_event(invoked, base, &token_accounts));
+            }
+        }
+    }
+    events
+}
+
+/// The transfer an instruction made, if it is a System or token transfer
+fn transfer_event(
+    instruction: &DecodedInstruction,
+    base: TransferEvent,
+    token_accounts: &HashMap<String, TokenAccountInfo>,
+) -> Option<TransferEvent> {
+    match instruction.details.as_ref()? {
+        Details::System(details)
+            if matches!(instruction.instruction_type.as_str(), "Transfer" | "TransferWithSeed") =>
+        {
+            Some(TransferEvent {
+                kind: TransferKind::Native.into(),
+                source: details.source.clone(),
+                destination: details.destination.clone(),
+                amount: details.lamports,
+                decimals: SOL_DECIMALS,
+                ui_amount: format_amount(details.lamports, SOL_DECIMALS),
+                ..base
+            })
+        }
+        Details::Token(details)
+            if matches!(instruction.instruction_type.as_str(), "Transfer" | "TransferChecked") =>
+        {
+            let source = token_accounts
+                .get(&details.source)
+                .cloned()
+                .unwrap_or_default();
+            let destination = token_accounts
+                .get(&details.destination)
+                .cloned()
+                .unwrap_or_default();
+            let mint = [&details.mint, &source.mint, &destination.mint]
+                .into_iter()
+                .find(|mint| !mint.is_empty())
+                .cloned()
+                .unwrap_or_default();
+            let decimals = details.decimals.unwrap_or(if source.mint.is_empty() {
+                destination.decimals
+            } else {
+                source.decimals
+            });
+            Some(TransferEvent {
+                kind: TransferKind::Token.into(),
+                source: details.source.clone(),
+                destination: details.destination.clone(),
+                source_owner: source.owner,
+                destination_owner: destination.owner,
+                authority: details.authority.clone(),
+                mint,
+                token_program: instruction.program_id.clone(),
+                amount: details.amount,
+                decimals,
+                ui_amount: format_amount(details.amount, decimals),
+                ..base
+            })
+        }
+        _ => None,
+    }
+}
+
+/// Token accounts the transaction touched, by address, from its pre and post balances
+fn token_accounts(
+    account_keys: &[Pubkey],
+    meta: &UiTransactionStatusMeta,
+) -> HashMap<String, TokenAccountInfo> {
+    let pre = Option::<Vec<UiTransactionTokenBalance>>::from(meta.pre_token_balances.clone())
+        .unwrap_or_default();
+    let post = Option::<Vec<UiTransactionTokenBalance>>::from(meta.post_token_balances.clone())
+        .unwrap_or_default();
+
+    pre.iter()
+        .chain(post.iter())
+        .filter_map(|balance| {
+            let address = account_keys.get(usize::from(balance.account_index))?;
+            Some((
+                address.to_string(),
+                TokenAccountInfo {
+                    mint: balance.mint.clone(),
+                    owner: Option::<String>::from(balance.owner.clone()).unwrap_or_default(),
+                    decimals: u32::from(balance.ui_token_amount.decimals),
+                },
+            ))
+        })
+        .collect()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::api::common::instruction_decoding::decode_instruction;
+    use protochain_api::protochain::solana::transaction::v1::InnerInstruction;
+    use serde_json::json;
+    use solana_sdk::system_instruction;
+
+    const BLOCK: BlockContext = BlockContext {
+        slot: 42,
+        block_time: 1_700_000_000,
+    };
+
+    fn meta(token_balances: serde_json::Value) -> UiTransactionStatusMeta {
+        serde_json::from_value(json!({
+            "err": null,
+            "status": { "Ok": null },
+            "fee": 5000,
+            "preBalances": [],
+            "postBalances": [],
+            "preTokenBalances": token_balances,
+            "postTokenBalances": [],
+        }))
+        .unwrap()
+    }
+
+    fn token_balance(account_index: u8, mint: &Pubkey, owner: &Pubkey) -> serde_json::Value {
+        json!({
+            "accountIndex": account_index,
+            "mint": mint.to_string(),
+            "owner": owner.to_string(),
+            "uiTokenAmount": { "amount": "0", "decimals": 6, "uiAmount": null, "uiAmountString": "0" },
+        })
+    }
+
+    fn decoded(
+        index: u32,
+        instruction: &solana_sdk::instruction::Instruction,
+    ) -> DecodedInstruction {
+        let accounts: Vec<Pubkey> = instruction
+            .accounts
+            .iter()
+            .map(|meta| meta.pubkey)
+            .collect();
+        decode_instruction(index, &instruction.program_id, &accounts, &instruction.data)
+    }
+
+    #[test]
+    fn test_native_transfer_event() {
+        let from = Pubkey::new_unique();
+        let to = Pubkey::new_unique();
+        let instruction = decoded(0, &system_instruction::transfer(&from, &to, 1_500_000_000));
+
+        let events =
+            transaction_transfers("sig", BLOCK, &[], &[instruction], &[], &meta(json!([])));
+
+        assert_eq!(events.len(), 1);
+        assert_eq!(events[0].kind, TransferKind::Native as i32);
+        assert_eq!(events[0].source, from.to_string());
+        assert_eq!(events[0].destination, to.to_string());
+        assert_eq!(events[0].amount, 1_500_000_000);
+        assert_eq!(events[0].ui_amount, "1.5");
+        assert_eq!(events[0].slot, 42);
+        assert!(events[0].inner_instruction_index.is_none());
+    }
+
+    #[test]
+    fn test_token_transfer_by_cpi_takes_mint_and_owners_from_balances() {
+        let source = Pubkey::new_unique();
+        let destination = Pubkey::new_unique();
+        let authority = Pubkey::new_unique();
+        let mint = Pubkey::new_unique();
+        let recipient = Pubkey::new_unique();
+        let program = Pubkey::new_unique();
+        #[allow(deprecated)]
+        let transfer = spl_token_2022::instruction::transfer(
+            &spl_token_2022::id(),
+            &source,
+            &destination,
+            &authority,
+            &[],
+            2_500_000,
+        )
+        .unwrap();
+        let top_level = DecodedInstruction {
+            index: 0,
+            program_id: program.to_string(),
+            ..Default::default()
+        };
+        let inner = InnerInstructions {
+            instruction_index: 0,
+            instructions: vec![InnerInstruction {
+                stack_height: 2,
+                instruction: Some(decoded(0, &transfer)),
+                parsed_json: String::new(),
+            }],
+        };
+        let account_keys = [authority, source, destination, program];
+        let meta = meta(json!([
+            token_balance(1, &mint, &authority),
+            token_balance(2, &mint, &recipient),
+        ]));
+
+        let events =
+            transaction_transfers("sig", BLOCK, &account_keys, &[top_level], &[inner], &meta);
+
+        assert_eq!(events.len(), 1);
+        let event = &events[0];
+        assert_eq!(event.kind, TransferKind::Token as i32);
+        assert_eq!(event.inner_instruction_index, Some(0));
+        assert_eq!(event.mint, mint.to_string());
+        assert_eq!(event.source_owner, authority.to_string());
+        assert_eq!(event.destination_owner, recipient.to_string());
+        assert_eq!(event.decimals, 6);
+        assert_eq!(event.ui_amount, "2.5");
+        assert_eq!(event.token_program, spl_token_2022::id().to_string());
+    }
+
+    #[test]
+    fn test_non_transfer_instructions_are_ignored() {
+        let account = Pubkey::new_unique();
+        let instruction = decoded(0, &system_instruction::allocate(&account, 128));
+        assert!(transaction_transfers("sig", BLOCK, &[], &[instruction], &[], &meta(json!([])))
+            .is_empty());
+    }
+
+    #[test]
+    fn test_filter_matches_owners_mints_and_amounts() {
+        let wallet = Pubkey::new_unique().to_string();
+        let mint = Pubkey::new_unique().to_string();
+        let event = TransferEvent {
+            kind: TransferKind::Token.into(),
+            source: Pubkey::new_unique().to_string(),
+            destination: Pubkey::new_unique().to_string(),
+            destination_owner: wallet.clone(),
+            mint: mint.clone(),
+            amount: 100,
+            ..Default::default()
+        };
+
+        let by_owner = TransferFilter::from_request(&[wallet.clone()], &[], 0).unwrap();
+        assert!(by_owner.matches(&event));
+        let by_mint = TransferFilter::from_request(&[], &[mint.clone()], 101).unwrap();
+        assert!(!by_mint.matches(&event));
+        let other_mint =
+            TransferFilter::from_request(&[wallet], &[Pubkey::new_unique().to_string()], 0)
+                .unwrap();
+        assert!(!other_mint.matches(&event));
+    }
+
+    #[test]
+    fn test_native_transfers_match_the_wrapped_sol_mint() {
+        let event = TransferEvent {
+            kind: TransferKind::Native.into(),
+            source: Pubkey::new_unique().to_string(),
+            destination: Pubkey::new_unique().to_string(),
+            amount: 1,
+            ..Default::default()
+        };
+        let wrapped =
+            TransferFilter::from_request(&[], &[WRAPPED_SOL_MINT.to_string()], 0).unwrap();
+        assert!(wrapped.matches(&event));
+        let token =
+            TransferFilter::from_request(&[], &[Pubkey::new_unique().to_string()], 0).unwrap();
+        assert!(!token.matches(&event));
+    }
+
+    #[test]
+    fn test_filter_rejects_empty_and_invalid_requests() {
+        assert!(TransferFilter::from_request(&[], &[], 0).is_err());
+        assert!(TransferFilter::from_request(&["not-a-key".to_string()], &[], 0).is_err());
+        let too_many: Vec<String> = (0..=MAX_FILTER_ENTRIES)
+            .map(|_| Pubkey::new_unique().to_string())
+            .collect();
+        assert!(TransferFilter::from_request(&too_many, &[], 0).is_err());
+    }
+}
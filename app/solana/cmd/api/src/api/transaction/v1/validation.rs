@@ -129,7 +129,7 @@ pub fn validate_operation_allowed_for_state(
     // for analysis purposes, so they are grouped together for simplicity
     match (state, operation) {
         // DRAFT state operations  
-        (TransactionState::Draft, "compile" | "add_instruction" | "remove_instruction") |
+        (TransactionState::Draft, "compile" | "add_instruction" | "remove_instruction" | "simulate") |
         // COMPILED/PARTIALLY_SIGNED state operations
         (TransactionState::Compiled | TransactionState::PartiallySigned, "sign" | "estimate" | "simulate") |
         // FULLY_SIGNED state operations
@@ -150,6 +150,7 @@ pub fn validate_operation_allowed_for_state(
 mod tests {
     use super::*;
     use protochain_api::protochain::solana::transaction::v1::*;
+    use std::collections::HashMap;
 
     #[test]
     fn test_valid_state_transitions() {
@@ -240,6 +241,7 @@ mod tests {
             signatures: vec![], // No signatures
             hash: String::new(),
             signature: String::new(),
+            signing_status: HashMap::new(),
         };
         assert!(validate_transaction_state_consistency(&valid_draft).is_ok());
 
@@ -254,6 +256,7 @@ mod tests {
             signatures: vec![],
             hash: String::new(),
             signature: String::new(),
+            signing_status: HashMap::new(),
         };
         assert!(validate_transaction_state_consistency(&invalid_draft_no_instructions).is_err());
 
@@ -268,6 +271,7 @@ mod tests {
             signatures: vec![],
             hash: String::new(),
             signature: String::new(),
+            signing_status: HashMap::new(),
         };
         assert!(validate_transaction_state_consistency(&invalid_draft_has_data).is_err());
     }
@@ -285,6 +289,7 @@ mod tests {
             signatures: vec![], // No signatures yet
             hash: String::new(),
             signature: String::new(),
+            signing_status: HashMap::new(),
         };
         assert!(validate_transaction_state_consistency(&valid_compiled).is_ok());
 
@@ -299,6 +304,7 @@ mod tests {
             signatures: vec![],
             hash: String::new(),
             signature: String::new(),
+            signing_status: HashMap::new(),
         };
         assert!(validate_transaction_state_consistency(&invalid_compiled_no_data).is_err());
     }
@@ -307,6 +313,7 @@ mod tests {
     fn test_operation_permissions() {
         // DRAFT operations
         assert!(validate_operation_allowed_for_state(TransactionState::Draft, "compile").is_ok());
+        assert!(validate_operation_allowed_for_state(TransactionState::Draft, "simulate").is_ok());
         assert!(validate_operation_allowed_for_state(TransactionState::Draft, "sign").is_err());
         assert!(validate_operation_allowed_for_state(TransactionState::Draft, "submit").is_err());
 
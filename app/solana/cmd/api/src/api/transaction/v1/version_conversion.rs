@@ -0,0 +1,323 @@
+use solana_sdk::address_lookup_table::state::AddressLookupTable;
+use solana_sdk::instruction::CompiledInstruction;
+use solana_sdk::message::{v0, Message, MessageHeader};
+use solana_sdk::pubkey::Pubkey;
+use solana_sdk::transaction::Transaction as SolanaTransaction;
+use std::collections::HashMap;
+
+use crate::api::transaction::v1::diagnostics::MAX_TRANSACTION_SIZE;
+use protochain_api::protochain::solana::transaction::v1::{
+    ConversionBlocker, ConversionBlockerKind,
+};
+
+/// Result of flattening a v0 message into a legacy one
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct Conversion {
+    /// The legacy message, if it fits
+    pub message: Option<Message>,
+    /// Why the message could not be converted (empty when it was)
+    pub blockers: Vec<ConversionBlocker>,
+    /// Accounts in the flattened message
+    pub account_count: usize,
+    /// Bytes of the unsigned legacy transaction (0 if it could not be built)
+    pub serialized_size: usize,
+}
+
+/// Decodes the addresses held by an address lookup table account
+pub fn lookup_table_addresses(data: &[u8]) -> Result<Vec<Pubkey>, String> {
+    AddressLookupTable::deserialize(data)
+        .map(|table| table.addresses.to_vec())
+        .map_err(|e| format!("Invalid address lookup table: {e}"))
+}
+
+fn blocker(
+    kind: ConversionBlockerKind,
+    address: Option<&Pubkey>,
+    message: String,
+) -> ConversionBlocker {
+    ConversionBlocker {
+        kind: kind.into(),
+        address: address.map(ToString::to_string).unwrap_or_default(),
+        message,
+    }
+}
+
+/// Resolves the accounts `message` loads from lookup tables, split into writable and
+/// read-only lists in the order the runtime appends them
+fn loaded_addresses(
+    message: &v0::Message,
+    tables: &HashMap<Pubkey, Vec<Pubkey>>,
+) -> Result<(Vec<Pubkey>, Vec<Pubkey>), Vec<ConversionBlocker>> {
+    let mut writable = Vec::new();
+    let mut readonly = Vec::new();
+    let mut blockers = Vec::new();
+
+    for lookup in &message.address_table_lookups {
+        let Some(addresses) = tables.get(&lookup.account_key) else {
+            blockers.push(blocker(
+                ConversionBlockerKind::LookupTableUnavailable,
+                Some(&lookup.account_key),
+                format!("Address lookup table {} could not be loaded", lookup.account_key),
+            ));
+            continue;
+        };
+        for (indexes, loaded) in [
+            (&lookup.writable_indexes, &mut writable),
+            (&lookup.readonly_indexes, &mut readonly),
+        ] {
+            for &index in indexes {
+                match addresses.get(usize::from(index)) {
+                    Some(address) => loaded.push(*address),
+                    None => blockers.push(blocker(
+                        ConversionBlockerKind::LookupIndexOutOfRange,
+                        Some(&lookup.account_key),
+                        format!(
+                            "Index {index} is beyond the {} addresses of lookup table {}",
+                            addresses.len(),
+                            lookup.account_key
+                        ),
+                    )),
+                }
+            }
+        }
+    }
+
+    if blockers.is_empty() {
+        Ok((writable, readonly))
+    } else {
+        Err(blockers)
+    }
+}
+
+/// Flattens a v0 message into a legacy message listing every account explicitly.
+///
+/// Loaded writable accounts are placed after the static writable non-signers and loaded
+/// read-only accounts last, keeping the legacy ordering signers, writable, read-only that
+/// the header describes. Instruction account indexes are remapped to match. The message
+/// content is unchanged, but its bytes are not, so signatures over the v0 message do not
+/// carry over.
+pub fn flatten_v0_message(
+    message: &v0::Message,
+    tables: &HashMap<Pubkey, Vec<Pubkey>>,
+) -> Conversion {
+    let (writable, readonly) = match loaded_addresses(message, tables) {
+        Ok(loaded) => loaded,
+        Err(blockers) => {
+            return Conversion {
+                blockers,
+                ..Default::default()
+            }
+        }
+    };
+
+    let static_count = message.account_keys.len();
+    let readonly_unsigned = usize::from(message.header.num_readonly_unsigned_accounts);
+    let static_writable_end = static_count.saturating_sub(readonly_unsigned);
+
+    let mut account_keys = Vec::with_capacity(static_count + writable.len() + readonly.len());
+    account_keys.extend_from_slice(&message.account_keys[..static_writable_end]);
+    account_keys.extend_from_slice(&writable);
+    account_keys.extend_from_slice(&message.account_keys[static_writable_end..]);
+    account_keys.extend_from_slice(&readonly);
+    let account_count = account_keys.len();
+
+    let Ok(readonly_unsigned) = u8::try_from(readonly_unsigned + readonly.len()) else {
+        return Conversion {
+            blockers: vec![blocker(
+                ConversionBlockerKind::TooLarge,
+                None,
+                format!("{account_count} accounts cannot be indexed by a legacy message"),
+            )],
+            account_count,
+            ..Default::default()
+        };
+    };
+
+    // v0 indexes static keys first, then loaded writable, then loaded read-only accounts
+    let remap = |index: u8| -> u8 {
+        let index = usize::from(index);
+        let remapped = if index < static_writable_end {
+            index
+        } else if index < static_count {
+            index + writable.len()
+        } else if index < static_count + writable.len() {
+            index - static_count + static_writable_end
+        } else {
+            index
+        };
+        u8::try_from(remapped).unwrap_or(u8::MAX)
+    };
+    let instructions = message
+        .instructions
+        .iter()
+        .map(|instruction| CompiledInstruction {
+            program_id_index: remap(instruction.program_id_index),
+            accounts: instruction
+                .accounts
+                .iter()
+                .map(|&index| remap(index))
+                .collect(),
+            data: instruction.data.clone(),
+        })
+        .collect();
+
+    let legacy = Message {
+        header: MessageHeader {
+            num_required_signatures: message.header.num_required_signatures,
+            num_readonly_signed_accounts: message.header.num_readonly_signed_accounts,
+            num_readonly_unsigned_accounts: readonly_unsigned,
+        },
+        account_keys,
+        recent_blockhash: message.recent_blockhash,
+        instructions,
+    };
+
+    let serialized_size =
+        bincode::serialized_size(&SolanaTransaction::new_unsigned(legacy.clone()))
+            .ok()
+            .and_then(|size| usize::try_from(size).ok())
+            .unwrap_or(usize::MAX);
+    if serialized_size > MAX_TRANSACTION_SIZE {
+        return Conversion {
+            blockers: vec![blocker(
+                ConversionBlockerKind::TooLarge,
+                None,
+                format!(
+                    "The legacy transaction would be {serialized_size} bytes with all \
+                     {account_count} accounts listed, over the {MAX_TRANSACTION_SIZE} byte limit"
+                ),
+            )],
+            account_count,
+            serialized_size,
+            ..Default::default()
+        };
+    }
+
+    Conversion {
+        message: Some(legacy),
+        blockers: Vec::new(),
+        account_count,
+        serialized_size,
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::hash::Hash;
+    use solana_sdk::instruction::{AccountMeta, Instruction};
+    use solana_sdk::message::v0::MessageAddressTableLookup;
+
+    #[test]
+    fn test_flattened_message_keeps_instruction_accounts() {
+        let payer = Pubkey::new_unique();
+        let program = Pubkey::new_unique();
+        let static_writable = Pubkey::new_unique();
+        let loaded_writable = Pubkey::new_unique();
+        let loaded_readonly = Pubkey::new_unique();
+        let table = Pubkey::new_unique();
+
+        // Static: payer (signer), static_writable, program (read-only);
+        // loaded: loaded_writable (index 3), loaded_readonly (index 4)
+        let message = v0::Message {
+            header: MessageHeader {
+                num_required_signatures: 1,
+                num_readonly_signed_accounts: 0,
+                num_readonly_unsigned_accounts: 1,
+            },
+            account_keys: vec![payer, static_writable, program],
+            recent_blockhash: Hash::new_unique(),
+            instructions: vec![CompiledInstruction::new_from_raw_parts(
+                2,
+                vec![1],
+                vec![0, 1, 3, 4],
+            )],
+            address_table_lookups: vec![MessageAddressTableLookup {
+                account_key: table,
+                writable_indexes: vec![0],
+                readonly_indexes: vec![1],
+            }],
+        };
+        let tables = HashMap::from([(table, vec![loaded_writable, loaded_readonly])]);
+
+        let conversion = flatten_v0_message(&message, &tables);
+        assert!(conversion.blockers.is_empty());
+        assert_eq!(conversion.account_count, 5);
+        let legacy = conversion.message.unwrap();
+
+        let expected = Message::new_with_blockhash(
+            &[Instruction::new_with_bytes(
+                program,
+                &[1],
+                vec![
+                    AccountMeta::new(payer, true),
+                    AccountMeta::new(static_writable, false),
+                    AccountMeta::new(loaded_writable, false),
+                    AccountMeta::new_readonly(loaded_readonly, false),
+                ],
+            )],
+            Some(&payer),
+            &message.recent_blockhash,
+        );
+        let instruction = &legacy.instructions[0];
+        assert_eq!(legacy.account_keys[usize::from(instruction.program_id_index)], program);
+        let accounts: Vec<Pubkey> = instruction
+            .accounts
+            .iter()
+            .map(|&index| legacy.account_keys[usize::from(index)])
+            .collect();
+        assert_eq!(accounts, vec![payer, static_writable, loaded_writable, loaded_readonly]);
+        assert_eq!(legacy.header, expected.header);
+    }
+
+    #[test]
+    fn test_missing_table_and_bad_index_block_conversion() {
+        let payer = Pubkey::new_unique();
+        let table = Pubkey::new_unique();
+        let message = v0::Message {
+            header: MessageHeader {
+                num_required_signatures: 1,
+                ..Default::default()
+            },
+            account_keys: vec![payer],
+            address_table_lookups: vec![MessageAddressTableLookup {
+                account_key: table,
+                writable_indexes: vec![5],
+                readonly_indexes: vec![],
+            }],
+            ..Default::default()
+        };
+
+        let missing = flatten_v0_message(&message, &HashMap::new());
+        assert_eq!(missing.blockers[0].kind(), ConversionBlockerKind::LookupTableUnavailable);
+
+        let short = flatten_v0_message(&message, &HashMap::from([(table, vec![payer])]));
+        assert_eq!(short.blockers[0].kind(), ConversionBlockerKind::LookupIndexOutOfRange);
+        assert!(short.message.is_none());
+    }
+
+    #[test]
+    fn test_oversized_transaction_is_blocked() {
+        let payer = Pubkey::new_unique();
+        let table = Pubkey::new_unique();
+        let addresses: Vec<Pubkey> = (0..60).map(|_| Pubkey::new_unique()).collect();
+        let message = v0::Message {
+            header: MessageHeader {
+                num_required_signatures: 1,
+                ..Default::default()
+            },
+            account_keys: vec![payer],
+            address_table_lookups: vec![MessageAddressTableLookup {
+                account_key: table,
+                writable_indexes: (0..60).collect(),
+                readonly_indexes: vec![],
+            }],
+            ..Default::default()
+        };
+
+        let conversion = flatten_v0_message(&message, &HashMap::from([(table, addresses)]));
+        assert_eq!(conversion.blockers[0].kind(), ConversionBlockerKind::TooLarge);
+        assert!(conversion.serialized_size > MAX_TRANSACTION_SIZE);
+    }
+}
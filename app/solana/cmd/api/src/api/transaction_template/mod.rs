@@ -0,0 +1,8 @@
+//! Transaction template services
+//!
+//! This module provides reusable, parameterised transactions:
+//! - Saving, listing and deleting templates
+//! - Placeholder validation on save
+//! - Instantiation into DRAFT transactions ready for compilation
+
+pub mod v1;
@@ -0,0 +1,14 @@
+//! Transaction template service v1 API and implementation
+//!
+//! This module contains the gRPC service definition and business logic
+//! for saving and instantiating transaction templates.
+
+/// Placeholder validation and substitution for templates
+pub mod placeholders;
+/// Core business logic implementation module for transaction templates
+pub mod service_impl;
+/// gRPC service wrapper module for transaction templates
+pub mod transaction_template_v1_api;
+
+pub use service_impl::TransactionTemplateServiceImpl;
+pub use transaction_template_v1_api::TransactionTemplateV1API;
@@ -0,0 +1,366 @@
+use solana_sdk::pubkey::Pubkey;
+use std::collections::{BTreeSet, HashMap, HashSet};
+use std::str::FromStr;
+
+use protochain_api::protochain::solana::transaction::v1::{Transaction, TransactionState};
+use protochain_api::protochain::solana::transaction_template::v1::{
+    TemplateParameter, TemplateParameterKind, TransactionTemplate,
+};
+
+use crate::api::common::amount_parsing::{parse_amount, MAX_AMOUNT_DECIMALS};
+
+/// Size of an amount written into instruction data
+const AMOUNT_LEN: usize = 8;
+
+/// Returns the placeholder name if `value` is written as `{{name}}`
+pub fn placeholder_name(value: &str) -> Option<&str> {
+    value
+        .strip_prefix("{{")
+        .and_then(|rest| rest.strip_suffix("}}"))
+        .map(str::trim)
+}
+
+/// Every account position of a transaction: fee payer, program ids and account keys
+fn account_fields(transaction: &mut Transaction) -> impl Iterator<Item = &mut String> {
+    std::iter::once(&mut transaction.fee_payer).chain(transaction.instructions.iter_mut().flat_map(
+        |instruction| {
+            std::iter::once(&mut instruction.program_id).chain(
+                instruction
+                    .accounts
+                    .iter_mut()
+                    .map(|account| &mut account.pubkey),
+            )
+        },
+    ))
+}
+
+/// Names of the account placeholders a transaction references
+fn referenced_accounts(transaction: &Transaction) -> BTreeSet<&str> {
+    std::iter::once(&transaction.fee_payer)
+        .chain(transaction.instructions.iter().flat_map(|instruction| {
+            std::iter::once(&instruction.program_id)
+                .chain(instruction.accounts.iter().map(|account| &account.pubkey))
+        }))
+        .map(String::as_str)
+        .filter_map(placeholder_name)
+        .collect()
+}
+
+/// Checks that a template's placeholders are declared, used and placed within its
+/// instructions, and that defaults are valid values
+pub fn validate_template(template: &TransactionTemplate) -> Result<(), String> {
+    let transaction = template
+        .transaction
+        .as_ref()
+        .ok_or("Template transaction is required")?;
+    if transaction.instructions.is_empty() {
+        return Err("Template transaction must have at least one instruction".to_string());
+    }
+    if !matches!(
+        TransactionState::try_from(transaction.state),
+        Ok(TransactionState::Draft | TransactionState::Unspecified)
+    ) {
+        return Err("Template transaction must be a DRAFT".to_string());
+    }
+
+    let mut names = HashSet::new();
+    for parameter in &template.parameters {
+        if parameter.name.is_empty() {
+            return Err("Parameter name is required".to_string());
+        }
+        if !names.insert(parameter.name.as_str()) {
+            return Err(format!("Parameter {} is declared more than once", parameter.name));
+        }
+        validate_parameter(parameter, transaction)?;
+    }
+
+    for name in referenced_accounts(transaction) {
+        let declared = template.parameters.iter().any(|parameter| {
+            parameter.name == name && parameter.kind() == TemplateParameterKind::Account
+        });
+        if !declared {
+            return Err(format!(
+                "Placeholder {{{{{name}}}}} is not declared as an account parameter"
+            ));
+        }
+    }
+    Ok(())
+}
+
+/// Checks a single parameter against the template transaction
+fn validate_parameter(
+    parameter: &TemplateParameter,
+    transaction: &Transaction,
+) -> Result<(), String> {
+    let name = &parameter.name;
+    match parameter.kind() {
+        TemplateParameterKind::Account => {
+            if !referenced_accounts(transaction).contains(name.as_str()) {
+                return Err(format!("Account parameter {name} is not used by the transaction"));
+            }
+        }
+        TemplateParameterKind::Amount => {
+            if parameter.decimals > MAX_AMOUNT_DECIMALS {
+                return Err(format!(
+                    "Amount parameter {name} decimals must be at most {MAX_AMOUNT_DECIMALS}"
+                ));
+            }
+            if parameter.placements.is_empty() {
+                return Err(format!("Amount parameter {name} has no placements"));
+            }
+            for placement in &parameter.placements {
+                let data_len = usize::try_from(placement.instruction_index)
+                    .ok()
+                    .and_then(|index| transaction.instructions.get(index))
+                    .map(|instruction| instruction.data.len())
+                    .ok_or_else(|| {
+                        format!(
+                            "Amount parameter {name} references instruction {}, out of range",
+                            placement.instruction_index
+                        )
+                    })?;
+                let fits = usize::try_from(placement.data_offset)
+                    .ok()
+                    .and_then(|offset| offset.checked_add(AMOUNT_LEN))
+                    .is_some_and(|end| end <= data_len);
+                if !fits {
+                    return Err(format!(
+                        "Amount parameter {name} offset {} does not fit instruction {}'s {data_len} data bytes",
+                        placement.data_offset, placement.instruction_index
+                    ));
+                }
+            }
+        }
+        TemplateParameterKind::Unspecified => {
+            return Err(format!("Parameter {name} kind is required"));
+        }
+    }
+
+    if !parameter.default_value.is_empty() {
+        parse_value(parameter, &parameter.default_value)
+            .map_err(|e| format!("Invalid default for parameter {name}: {e}"))?;
+    }
+    Ok(())
+}
+
+/// Value of a placeholder once parsed
+enum ParameterValue {
+    Account(Pubkey),
+    Amount(u64),
+}
+
+/// Parses a value supplied for a parameter
+fn parse_value(parameter: &TemplateParameter, value: &str) -> Result<ParameterValue, String> {
+    match parameter.kind() {
+        TemplateParameterKind::Account => Pubkey::from_str(value)
+            .map(ParameterValue::Account)
+            .map_err(|e| format!("invalid public key: {e}")),
+        TemplateParameterKind::Amount => parse_amount(value, parameter.decimals)
+            .map(ParameterValue::Amount)
+            .map_err(|e| e.message),
+        TemplateParameterKind::Unspecified => Err("parameter kind is unspecified".to_string()),
+    }
+}
+
+/// Fills every placeholder of a validated template and returns a DRAFT transaction.
+///
+/// Parameters without a supplied value fall back to their default; values for names the
+/// template does not declare are rejected so that typos are not silently ignored.
+pub fn instantiate(
+    template: &TransactionTemplate,
+    values: &HashMap<String, String>,
+) -> Result<Transaction, String> {
+    if let Some(unknown) = values
+        .keys()
+        .find(|name| !template.parameters.iter().any(|p| &p.name == *name))
+    {
+        return Err(format!("Template {} has no parameter {unknown}", template.name));
+    }
+
+    let mut transaction = template
+        .transaction
+        .clone()
+        .ok_or("Template transaction is required")?;
+    let mut accounts = HashMap::new();
+    for parameter in &template.parameters {
+        let value = values
+            .get(&parameter.name)
+            .filter(|value| !value.is_empty())
+            .unwrap_or(&parameter.default_value);
+        if value.is_empty() {
+            return Err(format!("Missing value for parameter {}", parameter.name));
+        }
+
+        match parse_value(parameter, value)
+            .map_err(|e| format!("Invalid value for parameter {}: {e}", parameter.name))?
+        {
+            ParameterValue::Account(pubkey) => {
+                accounts.insert(parameter.name.as_str(), pubkey.to_string());
+            }
+            ParameterValue::Amount(amount) => {
+                for placement in &parameter.placements {
+                    let data = usize::try_from(placement.instruction_index)
+                        .ok()
+                        .and_then(|index| transaction.instructions.get_mut(index))
+                        .map(|instruction| &mut instruction.data)
+                        .ok_or("Amount placement is out of range")?;
+                    let offset = usize::try_from(placement.data_offset)
+                        .map_err(|_| "Amount placement is out of range")?;
+                    data.get_mut(offset..offset + AMOUNT_LEN)
+                        .ok_or("Amount placement is out of range")?
+                        .copy_from_slice(&amount.to_le_bytes());
+                }
+            }
+        }
+    }
+
+    for field in account_fields(&mut transaction) {
+        if let Some(pubkey) = placeholder_name(field).and_then(|name| accounts.get(name)) {
+            field.clone_from(pubkey);
+        }
+    }
+    transaction.state = TransactionState::Draft.into();
+    Ok(transaction)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use protochain_api::protochain::solana::transaction::v1::{
+        SolanaAccountMeta, SolanaInstruction,
+    };
+    use protochain_api::protochain::solana::transaction_template::v1::AmountPlacement;
+    use solana_sdk::system_program;
+
+    /// Recurring SOL payment: system transfer (discriminator 2, then a u64 amount)
+    fn payment_template() -> TransactionTemplate {
+        let mut data = 2u32.to_le_bytes().to_vec();
+        data.extend_from_slice(&0u64.to_le_bytes());
+        TransactionTemplate {
+            name: "daily-payout".to_string(),
+            transaction: Some(Transaction {
+                instructions: vec![SolanaInstruction {
+                    program_id: system_program::id().to_string(),
+                    accounts: vec![
+                        SolanaAccountMeta {
+                            pubkey: "{{payer}}".to_string(),
+                            is_signer: true,
+                            is_writable: true,
+                        },
+                        SolanaAccountMeta {
+                            pubkey: "{{ recipient }}".to_string(),
+                            is_signer: false,
+                            is_writable: true,
+                        },
+                    ],
+                    data,
+                    description: String::new(),
+                }],
+                state: TransactionState::Draft.into(),
+                fee_payer: "{{payer}}".to_string(),
+                ..Default::default()
+            }),
+            parameters: vec![
+                TemplateParameter {
+                    name: "payer".to_string(),
+                    kind: TemplateParameterKind::Account.into(),
+                    ..Default::default()
+                },
+                TemplateParameter {
+                    name: "recipient".to_string(),
+                    kind: TemplateParameterKind::Account.into(),
+                    ..Default::default()
+                },
+                TemplateParameter {
+                    name: "amount".to_string(),
+                    kind: TemplateParameterKind::Amount.into(),
+                    decimals: 9,
+                    default_value: "0.5".to_string(),
+                    placements: vec![AmountPlacement {
+                        instruction_index: 0,
+                        data_offset: 4,
+                    }],
+                    ..Default::default()
+                },
+            ],
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_placeholder_name() {
+        assert_eq!(placeholder_name("{{payer}}"), Some("payer"));
+        assert_eq!(placeholder_name("{{ payer }}"), Some("payer"));
+        assert_eq!(placeholder_name(&system_program::id().to_string()), None);
+    }
+
+    #[test]
+    fn test_instantiate_fills_accounts_and_amounts() {
+        let template = payment_template();
+        validate_template(&template).unwrap();
+
+        let payer = Pubkey::new_unique();
+        let recipient = Pubkey::new_unique();
+        let values = HashMap::from([
+            ("payer".to_string(), payer.to_string()),
+            ("recipient".to_string(), recipient.to_string()),
+            ("amount".to_string(), "1.25".to_string()),
+        ]);
+        let transaction = instantiate(&template, &values).unwrap();
+
+        assert_eq!(transaction.fee_payer, payer.to_string());
+        let instruction = &transaction.instructions[0];
+        assert_eq!(instruction.accounts[0].pubkey, payer.to_string());
+        assert_eq!(instruction.accounts[1].pubkey, recipient.to_string());
+        assert_eq!(instruction.data[4..12], 1_250_000_000u64.to_le_bytes());
+    }
+
+    #[test]
+    fn test_instantiate_uses_defaults_and_rejects_missing_or_unknown() {
+        let template = payment_template();
+        let payer = Pubkey::new_unique().to_string();
+        let recipient = Pubkey::new_unique().to_string();
+
+        let values = HashMap::from([
+            ("payer".to_string(), payer.clone()),
+            ("recipient".to_string(), recipient),
+        ]);
+        let transaction = instantiate(&template, &values).unwrap();
+        assert_eq!(transaction.instructions[0].data[4..12], 500_000_000u64.to_le_bytes());
+
+        let missing = HashMap::from([("payer".to_string(), payer.clone())]);
+        assert!(instantiate(&template, &missing).is_err());
+
+        let mut unknown = values.clone();
+        unknown.insert("memo".to_string(), "hi".to_string());
+        assert!(instantiate(&template, &unknown).is_err());
+
+        let mut invalid = values;
+        invalid.insert("amount".to_string(), "1,5".to_string());
+        assert!(instantiate(&template, &invalid).is_err());
+    }
+
+    #[test]
+    fn test_validate_rejects_inconsistent_templates() {
+        let mut undeclared = payment_template();
+        undeclared.parameters.remove(1);
+        assert!(validate_template(&undeclared).is_err());
+
+        let mut unused = payment_template();
+        unused.parameters.push(TemplateParameter {
+            name: "mint".to_string(),
+            kind: TemplateParameterKind::Account.into(),
+            ..Default::default()
+        });
+        assert!(validate_template(&unused).is_err());
+
+        let mut out_of_range = payment_template();
+        out_of_range.parameters[2].placements[0].data_offset = 8;
+        assert!(validate_template(&out_of_range).is_err());
+
+        let mut bad_default = payment_template();
+        bad_default.parameters[2].default_value = "0.0000000001".to_string();
+        assert!(validate_template(&bad_default).is_err());
+    }
+}
@@ -0,0 +1,118 @@
+use std::sync::Arc;
+use tonic::{Request, Response, Status};
+use tracing::info;
+
+use protochain_api::protochain::solana::transaction_template::v1::{
+    service_server::Service as TransactionTemplateService, DeleteTemplateRequest,
+    DeleteTemplateResponse, GetTemplateRequest, GetTemplateResponse, InstantiateTemplateRequest,
+    InstantiateTemplateResponse, ListTemplatesRequest, ListTemplatesResponse, SaveTemplateRequest,
+    SaveTemplateResponse, TransactionTemplate,
+};
+
+use super::placeholders::{instantiate, validate_template};
+use crate::service_providers::templates::TemplateStore;
+
+#[derive(Clone)]
+/// Core business logic implementation for transaction template operations
+pub struct TransactionTemplateServiceImpl {
+    /// Shared template store
+    templates: Arc<TemplateStore>,
+}
+
+impl TransactionTemplateServiceImpl {
+    /// Creates a new `TransactionTemplateServiceImpl` instance with the provided store
+    pub const fn new(templates: Arc<TemplateStore>) -> Self {
+        Self { templates }
+    }
+
+    /// Looks up a template by name
+    #[allow(clippy::result_large_err)]
+    fn template(&self, name: &str) -> Result<TransactionTemplate, Status> {
+        if name.is_empty() {
+            return Err(Status::invalid_argument("name is required"));
+        }
+        self.templates
+            .get(name)
+            .ok_or_else(|| Status::not_found(format!("Template not found: {name}")))
+    }
+}
+
+#[tonic::async_trait]
+impl TransactionTemplateService for TransactionTemplateServiceImpl {
+    /// Validates and saves a template, replacing any template with the same name
+    async fn save_template(
+        &self,
+        request: Request<SaveTemplateRequest>,
+    ) -> Result<Response<SaveTemplateResponse>, Status> {
+        let template = request
+            .into_inner()
+            .template
+            .ok_or_else(|| Status::invalid_argument("template is required"))?;
+
+        validate_template(&template).map_err(Status::invalid_argument)?;
+        let template = self
+            .templates
+            .save(template)
+            .map_err(Status::invalid_argument)?;
+
+        info!(
+            name = %template.name,
+            parameters = template.parameters.len(),
+            "📝 Saved transaction template"
+        );
+        Ok(Response::new(SaveTemplateResponse {
+            template: Some(template),
+        }))
+    }
+
+    async fn get_template(
+        &self,
+        request: Request<GetTemplateRequest>,
+    ) -> Result<Response<GetTemplateResponse>, Status> {
+        let template = self.template(&request.into_inner().name)?;
+        Ok(Response::new(GetTemplateResponse {
+            template: Some(template),
+        }))
+    }
+
+    async fn list_templates(
+        &self,
+        _request: Request<ListTemplatesRequest>,
+    ) -> Result<Response<ListTemplatesResponse>, Status> {
+        Ok(Response::new(ListTemplatesResponse {
+            templates: self.templates.list(),
+        }))
+    }
+
+    async fn delete_template(
+        &self,
+        request: Request<DeleteTemplateRequest>,
+    ) -> Result<Response<DeleteTemplateResponse>, Status> {
+        let name = request.into_inner().name;
+        self.template(&name)?;
+        self.templates.remove(&name);
+
+        info!(name = %name, "🗑️ Deleted transaction template");
+        Ok(Response::new(DeleteTemplateResponse {}))
+    }
+
+    /// Fills a template's placeholders and returns a DRAFT transaction
+    async fn instantiate_template(
+        &self,
+        request: Request<InstantiateTemplateRequest>,
+    ) -> Result<Response<InstantiateTemplateResponse>, Status> {
+        let req = request.into_inner();
+        let template = self.template(&req.name)?;
+
+        let transaction = instantiate(&template, &req.values).map_err(Status::invalid_argument)?;
+
+        info!(
+            name = %template.name,
+            instructions = transaction.instructions.len(),
+            "🧩 Instantiated transaction template"
+        );
+        Ok(Response::new(InstantiateTemplateResponse {
+            transaction: Some(transaction),
+        }))
+    }
+}
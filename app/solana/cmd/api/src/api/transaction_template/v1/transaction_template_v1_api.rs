@@ -0,0 +1,21 @@
+use std::sync::Arc;
+
+use super::TransactionTemplateServiceImpl;
+use crate::service_providers::ServiceProviders;
+
+/// gRPC service wrapper for transaction template operations
+pub struct TransactionTemplateV1API {
+    /// Core transaction template service implementation
+    pub transaction_template_service: Arc<TransactionTemplateServiceImpl>,
+}
+
+impl TransactionTemplateV1API {
+    /// Creates a new `TransactionTemplateV1API` instance with the provided service providers
+    pub fn new(service_providers: &Arc<ServiceProviders>) -> Self {
+        Self {
+            transaction_template_service: Arc::new(TransactionTemplateServiceImpl::new(
+                Arc::clone(&service_providers.templates),
+            )),
+        }
+    }
+}
@@ -1,5 +1,6 @@
 use serde::{Deserialize, Serialize};
 use solana_client::rpc_client::RpcClient;
+use std::collections::BTreeMap;
 use std::path::PathBuf;
 
 /// Main application configuration structure
@@ -9,6 +10,75 @@ pub struct Config {
     pub solana: SolanaConfig,
     /// gRPC server configuration
     pub server: ServerConfig,
+    /// Feature flags guarding risky pathways
+    #[serde(default)]
+    pub feature_flags: FeatureFlagsConfig,
+    /// Credentials required by operator-only RPCs
+    #[serde(default)]
+    pub auth: AuthConfig,
+    /// Export of submission events to columnar analytics sinks
+    #[serde(default)]
+    pub event_export: EventExportConfig,
+    /// `FundNative` behaviour on clusters without airdrops
+    #[serde(default)]
+    pub funding: FundingConfig,
+    /// Server-side fee payer pool for sponsored transactions
+    #[serde(default)]
+    pub sponsorship: SponsorshipConfig,
+    /// Outbound webhook notifications
+    #[serde(default)]
+    pub webhooks: WebhookConfig,
+    /// Balance threshold rules notified through the webhook sink
+    #[serde(default)]
+    pub balance_alerts: BalanceAlertsConfig,
+    /// Jito block engine for bundle submission
+    #[serde(default)]
+    pub jito: JitoConfig,
+    /// Concurrency limits on outbound calls to the Solana RPC node
+    #[serde(default)]
+    pub rpc_limits: RpcLimitsConfig,
+    /// Transaction submission behaviour
+    #[serde(default)]
+    pub submission: SubmissionConfig,
+    /// Queueing of `SubmitTransaction` bursts
+    #[serde(default)]
+    pub admission: AdmissionConfig,
+    /// Server-side transaction queue
+    #[serde(default)]
+    pub queue: QueueConfig,
+    /// Secrets the key vault is seeded with
+    #[serde(default)]
+    pub key_vault: KeyVaultConfig,
+    /// Lifetimes of in-memory records and how often expired ones are collected
+    #[serde(default)]
+    pub retention: RetentionConfig,
+    /// Companion agent relaying signing requests to locally connected Ledger devices
+    #[serde(default)]
+    pub hardware_wallet: HardwareWalletConfig,
+    /// Cloud KMS keys transactions can be signed with
+    #[serde(default)]
+    pub kms: KmsConfig,
+    /// HashiCorp Vault transit engine transactions can be signed with
+    #[serde(default)]
+    pub vault: VaultConfig,
+    /// Automatic creation of missing associated token accounts for registered owners
+    #[serde(default)]
+    pub ata_watcher: AtaWatcherConfig,
+    /// Encrypted at-rest storage of generated and imported keys
+    #[serde(default)]
+    pub keystore: KeystoreConfig,
+    /// Fetching and caching of off-chain token metadata documents
+    #[serde(default)]
+    pub token_metadata: TokenMetadataConfig,
+    /// Short-lived caching of `SimulateTransaction` results
+    #[serde(default)]
+    pub simulation_cache: SimulationCacheConfig,
+    /// Locales human-readable response fields are rendered in
+    #[serde(default)]
+    pub localization: LocalizationConfig,
+    /// Where `ImportAccountSet` writes snapshots for a local test validator
+    #[serde(default)]
+    pub account_snapshots: AccountSnapshotConfig,
 }
 
 /// Solana RPC client configuration
@@ -22,6 +92,17 @@ pub struct SolanaConfig {
     pub retry_attempts: u32,
     /// Whether to perform health check on startup
     pub health_check_on_startup: bool,
+    /// Endpoint for reads at processed commitment, e.g. a fast local node (empty uses
+    /// `rpc_url`)
+    #[serde(default)]
+    pub processed_rpc_url: String,
+    /// Endpoint for reads at confirmed commitment (empty uses `rpc_url`)
+    #[serde(default)]
+    pub confirmed_rpc_url: String,
+    /// Endpoint for reads at finalized commitment, e.g. a highly reliable provider (empty
+    /// uses `rpc_url`)
+    #[serde(default)]
+    pub finalized_rpc_url: String,
 }
 
 /// gRPC server configuration
@@ -33,6 +114,445 @@ pub struct ServerConfig {
     pub port: u16,
 }
 
+/// Feature flag configuration
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct FeatureFlagsConfig {
+    /// Initial flag states keyed by flag name (e.g. `"v0_transactions": true`)
+    pub flags: BTreeMap<String, bool>,
+    /// Whether flags may be toggled at runtime through the admin service
+    pub allow_runtime_toggles: bool,
+}
+
+/// Authentication configuration
+///
+/// Operator-only RPCs (see `service_providers::auth`) require the `authorization: Bearer
+/// <admin_token>` request metadata. With no token configured they are refused. Callers
+/// spending a sponsorship budget or signing with KMS keys authenticate the same way with
+/// their own token.
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct AuthConfig {
+    /// Bearer token operators authenticate with; empty refuses every operator-only RPC
+    pub admin_token: String,
+    /// Bearer tokens keyed by the caller id they authenticate as (e.g. `"tenant-a": "..."`).
+    /// Only read from the config file so that tokens stay out of the environment.
+    pub caller_tokens: BTreeMap<String, String>,
+}
+
+/// Event export configuration
+///
+/// Submission events are buffered in memory and flushed to every configured columnar sink
+/// (see `service_providers::event_export`): Parquet files written to Cloud Storage or a
+/// local directory, and BigQuery streaming inserts. Each sink keeps its own backlog, so a
+/// failing sink does not hold back the others.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct EventExportConfig {
+    /// Seconds between flushes; 0 disables the export
+    pub flush_interval_seconds: u64,
+    /// Events a sink may have waiting before the oldest are dropped
+    pub max_buffered_events: usize,
+    /// Events written per Parquet file or BigQuery insert
+    pub max_batch_events: usize,
+    /// Parquet file sink
+    pub parquet: ParquetSinkConfig,
+    /// BigQuery streaming insert sink
+    pub bigquery: BigQuerySinkConfig,
+}
+
+/// Parquet file sink configuration
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct ParquetSinkConfig {
+    /// `gs://bucket/prefix` or a local directory files are written under; empty disables
+    /// the sink
+    pub destination: String,
+}
+
+/// BigQuery streaming insert sink configuration
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct BigQuerySinkConfig {
+    /// Project owning the dataset; empty disables the sink
+    pub project: String,
+    /// Dataset holding the table, which must exist
+    pub dataset: String,
+    /// Table events are inserted into, created with the export schema when missing
+    pub table: String,
+    /// BigQuery API endpoint override (for emulators)
+    pub endpoint: String,
+}
+
+/// Key vault configuration
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct KeyVaultConfig {
+    /// BIP39 mnemonics keyed by the name `SignWithMnemonic.mnemonic_ref` refers to them by.
+    /// Only read from the config file so that phrases stay out of the environment.
+    pub mnemonics: BTreeMap<String, String>,
+}
+
+/// Funding configuration
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct FundingConfig {
+    /// Key vault alias or public key of the treasury that funds accounts on clusters
+    /// without airdrops (e.g. mainnet-beta) and where an airdrop is refused; empty
+    /// disables treasury funding
+    pub treasury_key_ref: String,
+    /// Refuse the deprecated string amount of `FundNative` requests instead of accepting
+    /// it with a deprecation warning
+    pub reject_string_amounts: bool,
+}
+
+/// Sponsored fee payer configuration
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct SponsorshipConfig {
+    /// Key vault aliases or public keys of the sponsor fee payers; empty disables sponsorship
+    pub fee_payer_key_refs: Vec<String>,
+    /// Balance a sponsor must hold to be selected
+    pub min_balance_lamports: u64,
+    /// Fees each caller may have sponsored per budget window
+    pub caller_budget_lamports: u64,
+    /// Length of a caller's budget window
+    pub budget_window_seconds: u64,
+}
+
+/// Webhook sink configuration
+///
+/// Events are POSTed as JSON to `url` (see `service_providers::webhooks`). When a secret
+/// is set each body is signed with HMAC-SHA256 in the `X-Protochain-Signature` header.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct WebhookConfig {
+    /// Endpoint events are delivered to; empty disables webhooks
+    pub url: String,
+    /// Shared secret bodies are signed with (empty leaves them unsigned)
+    pub secret: String,
+    /// How long a single delivery attempt may take
+    pub timeout_seconds: u64,
+    /// Attempts per event before it is dropped
+    pub max_attempts: u32,
+}
+
+/// Balance threshold alert configuration
+///
+/// Checks the balances named by `rules` and sends a webhook when one drops below its
+/// threshold, and again when it recovers (see `service_providers::balance_alerts`).
+/// Rules require a webhook URL.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct BalanceAlertsConfig {
+    /// How often rule balances are checked; 0 disables the alerts
+    pub poll_interval_seconds: u64,
+    /// Threshold rules. Only read from the config file.
+    pub rules: Vec<BalanceRuleConfig>,
+}
+
+/// A balance that is alerted on when it drops below a threshold
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct BalanceRuleConfig {
+    /// Unique name identifying the rule in notifications
+    pub name: String,
+    /// Base58 address whose balance is checked: the account itself for SOL, the owning
+    /// wallet of the associated token account when `mint` is set
+    pub address: String,
+    /// Base58 mint for a token balance; empty checks the address's lamports
+    pub mint: String,
+    /// Threshold in lamports or token base units; balances strictly below it alert
+    pub below: String,
+}
+
+/// Jito block engine configuration
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct JitoConfig {
+    /// Block engine bundles endpoint (e.g. `https://mainnet.block-engine.jito.wtf/api/v1/bundles`);
+    /// empty disables bundle submission
+    pub block_engine_url: String,
+    /// Tip accounts a bundle may pay; empty asks the block engine via `getTipAccounts`
+    pub tip_accounts: Vec<String>,
+    /// Smallest total tip a bundle must carry
+    pub min_tip_lamports: u64,
+}
+
+/// Hardware wallet companion agent configuration
+///
+/// The agent runs next to the Ledger devices and speaks JSON-RPC over HTTP (see
+/// `service_providers::hardware_wallet`). Transactions the Ledger Solana app cannot display
+/// need blind signing, which is refused unless `allow_blind_signing` is set.
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct HardwareWalletConfig {
+    /// Agent endpoint (e.g. `http://127.0.0.1:9911`); empty disables hardware wallet signing
+    pub agent_url: String,
+    /// Permit signing transactions the device can only blind-sign
+    pub allow_blind_signing: bool,
+}
+
+/// Cloud KMS signing configuration
+///
+/// Each key is an ed25519 key in AWS KMS or GCP Cloud KMS that signs through the provider's
+/// API, so its private key never reaches this server (see `service_providers::kms`). AWS
+/// credentials are read from `AWS_ACCESS_KEY_ID`, `AWS_SECRET_ACCESS_KEY` and
+/// `AWS_SESSION_TOKEN`; GCP uses `GOOGLE_OAUTH_ACCESS_TOKEN` or the metadata server.
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct KmsConfig {
+    /// Keys keyed by the id `SignWithKMS.key_ids` refers to them by; empty disables KMS
+    /// signing. Only read from the config file, as each entry carries its access policy.
+    pub keys: BTreeMap<String, KmsKeyConfig>,
+    /// AWS KMS endpoint override, e.g. for a VPC endpoint (empty uses the key's region)
+    pub aws_endpoint: String,
+    /// GCP Cloud KMS endpoint override (empty uses `https://cloudkms.googleapis.com`)
+    pub gcp_endpoint: String,
+}
+
+/// A cloud KMS key and the policy on who may sign what with it
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct KmsKeyConfig {
+    /// `aws` or `gcp`
+    pub provider: String,
+    /// AWS key ARN, or GCP key version resource name
+    /// (`projects/.../locations/.../keyRings/.../cryptoKeys/.../cryptoKeyVersions/N`)
+    pub key_name: String,
+    /// Base58 public key of the KMS key
+    pub public_key: String,
+    /// Caller ids (see `auth.caller_tokens`) allowed to sign with the key; empty allows any
+    /// authenticated caller
+    pub allowed_callers: Vec<String>,
+    /// Programs a transaction signed with the key may invoke; empty allows any program
+    pub allowed_programs: Vec<String>,
+}
+
+/// HashiCorp Vault transit signing configuration
+///
+/// Authenticates with either a fixed token or AppRole credentials, never both (see
+/// `service_providers::vault`). Transit keys must be of type `ed25519`.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct VaultConfig {
+    /// Vault address (e.g. `https://vault.internal:8200`); empty disables Vault signing
+    pub address: String,
+    /// Vault token; set this or the AppRole credentials
+    pub token: String,
+    /// AppRole role id
+    pub role_id: String,
+    /// AppRole secret id
+    pub secret_id: String,
+    /// Vault Enterprise namespace (empty for the root namespace)
+    pub namespace: String,
+    /// Mount path of the transit secrets engine
+    pub transit_mount: String,
+    /// Transit key `SignWithVault` uses when a request names none
+    pub key_name: String,
+}
+
+/// Encrypted keystore configuration
+///
+/// Keys are encrypted with AES-256-GCM under a key-encryption key (KEK) that is either
+/// given directly or unwrapped through a cloud KMS at startup, never both (see
+/// `service_providers::keystore`).
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct KeystoreConfig {
+    /// Directory holding the encrypted key files; empty disables the keystore
+    pub directory: String,
+    /// Base64 32-byte KEK
+    pub kek: String,
+    /// `aws` or `gcp`: the KMS that unwraps `kek_ciphertext`
+    pub kek_kms_provider: String,
+    /// AWS key ARN or GCP crypto key resource name the KEK is wrapped with
+    pub kek_kms_key_name: String,
+    /// Base64 KEK ciphertext produced by the KMS key's encrypt operation
+    pub kek_ciphertext: String,
+    /// Refuse to return raw private keys from `GenerateNewKeyPair`, `ImportKeyPair` and
+    /// `ExportKeyPair`
+    pub required: bool,
+    /// Allow `ExportKeyPair` to return decrypted keys; off by default and ignored when
+    /// `required` is set. Every export attempt is recorded under the `keystore_audit` target.
+    pub export_enabled: bool,
+}
+
+/// Associated token account watcher configuration
+///
+/// Watches the associated token accounts of registered owners, under whichever token
+/// program owns each mint, and, when an inbound transfer targets one that does not exist,
+/// creates it with a sponsored fee payer (see `service_providers::ata_watcher`). Creation
+/// fees and rent are charged to the tenant's sponsorship budget.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct AtaWatcherConfig {
+    /// How often watched accounts are checked; 0 disables the watcher
+    pub poll_interval_seconds: u64,
+    /// Policies keyed by tenant, which is also the sponsorship caller id creations are
+    /// charged to. Only read from the config file.
+    pub tenants: BTreeMap<String, AtaWatchPolicyConfig>,
+}
+
+/// A tenant's registered owners and whether their missing accounts are created
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct AtaWatchPolicyConfig {
+    /// Create missing accounts on inbound transfers; when false attempts are only logged
+    pub auto_create: bool,
+    /// Base58 wallet addresses whose associated token accounts are watched
+    pub owners: Vec<String>,
+    /// Base58 mints watched for every owner
+    pub mints: Vec<String>,
+}
+
+/// Off-chain token metadata configuration
+///
+/// `GetTokenMetadata` follows a mint's metadata URI to its JSON document (see
+/// `service_providers::token_metadata`). Documents are fetched over HTTPS only, capped in
+/// size and cached; `ipfs://` and `ar://` URIs are resolved through the gateways below.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct TokenMetadataConfig {
+    /// Largest document that is read, in bytes
+    pub max_document_bytes: u64,
+    /// How long a single document fetch may take
+    pub fetch_timeout_seconds: u64,
+    /// How long fetched documents (and fetch failures) are cached; 0 disables caching
+    pub cache_ttl_seconds: u64,
+    /// Most documents held in the cache
+    pub max_cached_documents: usize,
+    /// Gateway `ipfs://<cid>/<path>` URIs are fetched through
+    pub ipfs_gateway: String,
+    /// Gateway `ar://<id>` URIs are fetched through
+    pub arweave_gateway: String,
+}
+
+/// `SimulateTransaction` result caching for requests that set `use_cache`
+///
+/// Results are keyed by message hash and the bucket of `slots_per_bucket` slots the
+/// request arrived in, and expire after `ttl_ms`; a TTL of 0 disables the cache.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct SimulationCacheConfig {
+    /// How long a result is reused, in milliseconds
+    pub ttl_ms: u64,
+    /// Slots sharing one cache bucket
+    pub slots_per_bucket: u64,
+    /// Most results held at once
+    pub max_entries: usize,
+}
+
+/// Localization of human-readable response fields
+///
+/// Callers name their preferred locales in `accept-language` request metadata (see
+/// `service_providers::localization`). Each `<locale>.json` file in `catalog_directory`
+/// is a flat object of message keys to templates; messages a locale lacks fall back to
+/// the default locale and then to the built-in English.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct LocalizationConfig {
+    /// Locale used when a request names none the catalog has
+    pub default_locale: String,
+    /// Directory of `<locale>.json` message catalogs (empty for English only)
+    pub catalog_directory: String,
+}
+
+/// Account snapshot configuration
+///
+/// `ImportAccountSet` writes each account of a snapshot as a JSON file in the format
+/// `solana-test-validator --account-dir` loads at startup. Imports are refused while no
+/// directory is configured, and whenever the server is not connected to a local validator.
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct AccountSnapshotConfig {
+    /// Account directory of the local test validator (empty disables `ImportAccountSet`)
+    pub import_directory: String,
+}
+
+/// Transaction submission configuration
+#[derive(Debug, Clone, Serialize, Deserialize, Default)]
+#[serde(default)]
+pub struct SubmissionConfig {
+    /// Treat every `SubmitTransaction` as a dry run: validate and simulate, never broadcast
+    /// (for CI environments)
+    pub dry_run: bool,
+    /// Reject every `SubmitTransaction` that does not present a token from
+    /// `MintSubmissionToken`
+    pub require_token: bool,
+}
+
+/// `SubmitTransaction` admission control
+///
+/// Requests beyond `max_in_flight` wait in arrival order, up to `max_queued` of them for
+/// at most `max_queue_ms` each, before being rejected as `OVERLOADED`.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct AdmissionConfig {
+    /// Submissions processed at once
+    pub max_in_flight: usize,
+    /// Submissions that may wait for a slot (0 rejects as soon as every slot is taken)
+    pub max_queued: usize,
+    /// How long a submission may wait for a slot
+    pub max_queue_ms: u64,
+}
+
+/// Server-side transaction queue
+///
+/// `EnqueueTransaction` is only served when `enabled`. Queued transactions are sent one
+/// at a time per fee payer, in enqueue order, and at most `max_tps` per second overall.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct QueueConfig {
+    /// Serve the queue RPCs
+    pub enabled: bool,
+    /// Sends per second across every fee payer
+    pub max_tps: u32,
+    /// Transactions that may wait to be sent before enqueueing is rejected
+    pub max_pending: usize,
+}
+
+/// Retention of in-memory records
+///
+/// Every `gc_interval_seconds` a background task purges records older than their TTL, so
+/// long-running deployments hold a bounded amount of state. A TTL of 0 keeps records until
+/// their store's size cap evicts them; an interval of 0 disables the background task.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct RetentionConfig {
+    /// Seconds between collection passes
+    pub gc_interval_seconds: u64,
+    /// How long submission records stay searchable
+    pub submission_ttl_seconds: i64,
+    /// How long an idempotency key replays its first submission
+    pub idempotency_ttl_seconds: i64,
+    /// How long a dead letter stays available for re-queueing after its last attempt
+    pub dead_letter_ttl_seconds: i64,
+    /// How long finished operations stay queryable
+    pub operation_retention_seconds: i64,
+}
+
+/// Outbound RPC concurrency limits
+///
+/// A call holds a permit for its class and one from the global pool while it runs. When
+/// either is exhausted it queues for up to `queue_timeout_ms` before being rejected.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(default)]
+pub struct RpcLimitsConfig {
+    /// Calls in flight to the node across all classes
+    pub max_concurrent: usize,
+    /// Blockhash fetches (transaction compilation, account funding)
+    pub max_concurrent_blockhash: usize,
+    /// Transaction simulations (simulation, estimation, compute budget sizing)
+    pub max_concurrent_simulation: usize,
+    /// Account and other chain state reads (rent, supply, epoch, program services)
+    pub max_concurrent_account_read: usize,
+    /// Transaction submissions
+    pub max_concurrent_submission: usize,
+    /// How long a call may queue for a permit
+    pub queue_timeout_ms: u64,
+}
+
 impl Default for SolanaConfig {
     fn default() -> Self {
         Self {
@@ -40,6 +560,9 @@ impl Default for SolanaConfig {
             timeout_seconds: 30,
             retry_attempts: 3,
             health_check_on_startup: true,
+            processed_rpc_url: String::new(),
+            confirmed_rpc_url: String::new(),
+            finalized_rpc_url: String::new(),
         }
     }
 }
@@ -53,6 +576,193 @@ impl Default for ServerConfig {
     }
 }
 
+impl Default for SponsorshipConfig {
+    fn default() -> Self {
+        Self {
+            fee_payer_key_refs: Vec::new(),
+            min_balance_lamports: 10_000_000, // 0.01 SOL
+            caller_budget_lamports: 100_000_000,
+            budget_window_seconds: 86_400,
+        }
+    }
+}
+
+impl Default for WebhookConfig {
+    fn default() -> Self {
+        Self {
+            url: String::new(),
+            secret: String::new(),
+            timeout_seconds: 10,
+            max_attempts: 3,
+        }
+    }
+}
+
+impl Default for BalanceAlertsConfig {
+    fn default() -> Self {
+        Self {
+            poll_interval_seconds: 60,
+            rules: Vec::new(),
+        }
+    }
+}
+
+impl Default for AtaWatcherConfig {
+    fn default() -> Self {
+        Self {
+            poll_interval_seconds: 30,
+            tenants: BTreeMap::new(),
+        }
+    }
+}
+
+impl Default for TokenMetadataConfig {
+    fn default() -> Self {
+        Self {
+            max_document_bytes: 256 * 1024,
+            fetch_timeout_seconds: 5,
+            cache_ttl_seconds: 600,
+            max_cached_documents: 10_000,
+            ipfs_gateway: "https://ipfs.io/ipfs/".to_string(),
+            arweave_gateway: "https://arweave.net/".to_string(),
+        }
+    }
+}
+
+impl Default for SimulationCacheConfig {
+    fn default() -> Self {
+        Self {
+            ttl_ms: 2_000,
+            slots_per_bucket: 4,
+            max_entries: 1_000,
+        }
+    }
+}
+
+impl Default for LocalizationConfig {
+    fn default() -> Self {
+        Self {
+            default_locale: "en".to_string(),
+            catalog_directory: String::new(),
+        }
+    }
+}
+
+impl Default for JitoConfig {
+    fn default() -> Self {
+        Self {
+            block_engine_url: String::new(),
+            tip_accounts: Vec::new(),
+            min_tip_lamports: 1_000, // Block engine minimum
+        }
+    }
+}
+
+impl Default for VaultConfig {
+    fn default() -> Self {
+        Self {
+            address: String::new(),
+            token: String::new(),
+            role_id: String::new(),
+            secret_id: String::new(),
+            namespace: String::new(),
+            transit_mount: "transit".to_string(),
+            key_name: String::new(),
+        }
+    }
+}
+
+impl Default for RpcLimitsConfig {
+    fn default() -> Self {
+        Self {
+            max_concurrent: 64,
+            max_concurrent_blockhash: 16,
+            max_concurrent_simulation: 16,
+            max_concurrent_account_read: 32,
+            max_concurrent_submission: 16,
+            queue_timeout_ms: 5_000,
+        }
+    }
+}
+
+impl Default for AdmissionConfig {
+    fn default() -> Self {
+        Self {
+            max_in_flight: 32,
+            max_queued: 256,
+            max_queue_ms: 2_000,
+        }
+    }
+}
+
+impl Default for QueueConfig {
+    fn default() -> Self {
+        Self {
+            enabled: false,
+            max_tps: 10,
+            max_pending: 10_000,
+        }
+    }
+}
+
+impl Default for RetentionConfig {
+    fn default() -> Self {
+        Self {
+            gc_interval_seconds: 60,
+            submission_ttl_seconds: 86_400,
+            idempotency_ttl_seconds: 3_600,
+            dead_letter_ttl_seconds: 604_800,
+            operation_retention_seconds: 3_600,
+        }
+    }
+}
+
+impl Default for FeatureFlagsConfig {
+    fn default() -> Self {
+        Self {
+            flags: BTreeMap::new(),
+            allow_runtime_toggles: false,
+        }
+    }
+}
+
+impl Default for EventExportConfig {
+    fn default() -> Self {
+        Self {
+            flush_interval_seconds: 60,
+            max_buffered_events: 100_000,
+            max_batch_events: 5_000,
+            parquet: ParquetSinkConfig::default(),
+            bigquery: BigQuerySinkConfig::default(),
+        }
+    }
+}
+
+/// Parses a `FEATURE_FLAGS` style override list such as `v0_transactions=true,jito_bundles=off`
+pub fn parse_feature_flag_overrides(value: &str) -> Result<Vec<(String, bool)>, String> {
+    value
+        .split(',')
+        .map(str::trim)
+        .filter(|entry| !entry.is_empty())
+        .map(|entry| {
+            let (name, state) = entry
+                .split_once('=')
+                .ok_or_else(|| format!("Feature flag override '{entry}' must be name=value"))?;
+            let enabled = match state.trim().to_lowercase().as_str() {
+                "true" | "on" | "1" => true,
+                "false" | "off" | "0" => false,
+                other => {
+                    return Err(format!(
+                        "Invalid value '{other}' for feature flag '{}'",
+                        name.trim()
+                    ))
+                }
+            };
+            Ok((name.trim().to_string(), enabled))
+        })
+        .collect()
+}
+
 /// Loads configuration with the following precedence:
 /// 1. Start with defaults
 /// 2. Load from config.json file (or --config specified file)
@@ -100,6 +810,21 @@ pub fn load_config() -> Result<Config, String> {
         println!("ℹ️  Override: SOLANA_RPC_URL = {}", config.solana.rpc_url);
     }
 
+    if let Ok(rpc_url) = std::env::var("SOLANA_PROCESSED_RPC_URL") {
+        config.solana.processed_rpc_url = rpc_url;
+        println!("ℹ️  Override: SOLANA_PROCESSED_RPC_URL = {}", config.solana.processed_rpc_url);
+    }
+
+    if let Ok(rpc_url) = std::env::var("SOLANA_CONFIRMED_RPC_URL") {
+        config.solana.confirmed_rpc_url = rpc_url;
+        println!("ℹ️  Override: SOLANA_CONFIRMED_RPC_URL = {}", config.solana.confirmed_rpc_url);
+    }
+
+    if let Ok(rpc_url) = std::env::var("SOLANA_FINALIZED_RPC_URL") {
+        config.solana.finalized_rpc_url = rpc_url;
+        println!("ℹ️  Override: SOLANA_FINALIZED_RPC_URL = {}", config.solana.finalized_rpc_url);
+    }
+
     if let Ok(port) = std::env::var("SERVER_PORT") {
         config.server.port = port
             .parse()
@@ -129,6 +854,428 @@ pub fn load_config() -> Result<Config, String> {
         );
     }
 
+    if let Ok(flags) = std::env::var("FEATURE_FLAGS") {
+        for (name, enabled) in parse_feature_flag_overrides(&flags)
+            .map_err(|e| format!("Invalid FEATURE_FLAGS environment variable: {e}"))?
+        {
+            println!("ℹ️  Override: feature flag {name} = {enabled}");
+            config.feature_flags.flags.insert(name, enabled);
+        }
+    }
+
+    if let Ok(allow) = std::env::var("FEATURE_FLAGS_ALLOW_RUNTIME_TOGGLES") {
+        config.feature_flags.allow_runtime_toggles = allow.to_lowercase() == "true";
+        println!(
+            "ℹ️  Override: FEATURE_FLAGS_ALLOW_RUNTIME_TOGGLES = {}",
+            config.feature_flags.allow_runtime_toggles
+        );
+    }
+
+    if let Ok(token) = std::env::var("ADMIN_API_TOKEN") {
+        config.auth.admin_token = token;
+        println!("ℹ️  Override: ADMIN_API_TOKEN = <redacted>");
+    }
+
+    if let Ok(interval) = std::env::var("EVENT_EXPORT_FLUSH_INTERVAL_SECONDS") {
+        config.event_export.flush_interval_seconds = interval.parse().map_err(|e| {
+            format!("Invalid EVENT_EXPORT_FLUSH_INTERVAL_SECONDS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: EVENT_EXPORT_FLUSH_INTERVAL_SECONDS = {}",
+            config.event_export.flush_interval_seconds
+        );
+    }
+
+    if let Ok(destination) = std::env::var("EVENT_EXPORT_PARQUET_DESTINATION") {
+        config.event_export.parquet.destination = destination;
+        println!(
+            "ℹ️  Override: EVENT_EXPORT_PARQUET_DESTINATION = {}",
+            config.event_export.parquet.destination
+        );
+    }
+
+    if let Ok(project) = std::env::var("EVENT_EXPORT_BIGQUERY_PROJECT") {
+        config.event_export.bigquery.project = project;
+        println!(
+            "ℹ️  Override: EVENT_EXPORT_BIGQUERY_PROJECT = {}",
+            config.event_export.bigquery.project
+        );
+    }
+
+    if let Ok(dataset) = std::env::var("EVENT_EXPORT_BIGQUERY_DATASET") {
+        config.event_export.bigquery.dataset = dataset;
+        println!(
+            "ℹ️  Override: EVENT_EXPORT_BIGQUERY_DATASET = {}",
+            config.event_export.bigquery.dataset
+        );
+    }
+
+    if let Ok(table) = std::env::var("EVENT_EXPORT_BIGQUERY_TABLE") {
+        config.event_export.bigquery.table = table;
+        println!(
+            "ℹ️  Override: EVENT_EXPORT_BIGQUERY_TABLE = {}",
+            config.event_export.bigquery.table
+        );
+    }
+
+    if let Ok(treasury_key_ref) = std::env::var("FUNDING_TREASURY_KEY_REF") {
+        config.funding.treasury_key_ref = treasury_key_ref;
+        println!("ℹ️  Override: FUNDING_TREASURY_KEY_REF = {}", config.funding.treasury_key_ref);
+    }
+
+    if let Ok(reject) = std::env::var("FUNDING_REJECT_STRING_AMOUNTS") {
+        config.funding.reject_string_amounts = reject.to_lowercase() == "true";
+        println!(
+            "ℹ️  Override: FUNDING_REJECT_STRING_AMOUNTS = {}",
+            config.funding.reject_string_amounts
+        );
+    }
+
+    if let Ok(key_refs) = std::env::var("SPONSORED_FEE_PAYER_KEY_REFS") {
+        config.sponsorship.fee_payer_key_refs = key_refs
+            .split(',')
+            .map(str::trim)
+            .filter(|key_ref| !key_ref.is_empty())
+            .map(ToString::to_string)
+            .collect();
+        println!(
+            "ℹ️  Override: SPONSORED_FEE_PAYER_KEY_REFS = {}",
+            config.sponsorship.fee_payer_key_refs.join(",")
+        );
+    }
+
+    if let Ok(budget) = std::env::var("SPONSORED_CALLER_BUDGET_LAMPORTS") {
+        config.sponsorship.caller_budget_lamports = budget.parse().map_err(|e| {
+            format!("Invalid SPONSORED_CALLER_BUDGET_LAMPORTS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: SPONSORED_CALLER_BUDGET_LAMPORTS = {}",
+            config.sponsorship.caller_budget_lamports
+        );
+    }
+
+    if let Ok(url) = std::env::var("WEBHOOK_URL") {
+        config.webhooks.url = url;
+        println!("ℹ️  Override: WEBHOOK_URL = {}", config.webhooks.url);
+    }
+
+    if let Ok(secret) = std::env::var("WEBHOOK_SECRET") {
+        config.webhooks.secret = secret;
+        println!("ℹ️  Override: WEBHOOK_SECRET = <redacted>");
+    }
+
+    if let Ok(interval) = std::env::var("BALANCE_ALERTS_POLL_INTERVAL_SECONDS") {
+        config.balance_alerts.poll_interval_seconds = interval.parse().map_err(|e| {
+            format!("Invalid BALANCE_ALERTS_POLL_INTERVAL_SECONDS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: BALANCE_ALERTS_POLL_INTERVAL_SECONDS = {}",
+            config.balance_alerts.poll_interval_seconds
+        );
+    }
+
+    if let Ok(block_engine_url) = std::env::var("JITO_BLOCK_ENGINE_URL") {
+        config.jito.block_engine_url = block_engine_url;
+        println!("ℹ️  Override: JITO_BLOCK_ENGINE_URL = {}", config.jito.block_engine_url);
+    }
+
+    if let Ok(agent_url) = std::env::var("HARDWARE_WALLET_AGENT_URL") {
+        config.hardware_wallet.agent_url = agent_url;
+        println!("ℹ️  Override: HARDWARE_WALLET_AGENT_URL = {}", config.hardware_wallet.agent_url);
+    }
+
+    if let Ok(allow_blind_signing) = std::env::var("HARDWARE_WALLET_ALLOW_BLIND_SIGNING") {
+        config.hardware_wallet.allow_blind_signing = allow_blind_signing.to_lowercase() == "true";
+        println!(
+            "ℹ️  Override: HARDWARE_WALLET_ALLOW_BLIND_SIGNING = {}",
+            config.hardware_wallet.allow_blind_signing
+        );
+    }
+
+    if let Ok(aws_endpoint) = std::env::var("KMS_AWS_ENDPOINT") {
+        config.kms.aws_endpoint = aws_endpoint;
+        println!("ℹ️  Override: KMS_AWS_ENDPOINT = {}", config.kms.aws_endpoint);
+    }
+
+    if let Ok(gcp_endpoint) = std::env::var("KMS_GCP_ENDPOINT") {
+        config.kms.gcp_endpoint = gcp_endpoint;
+        println!("ℹ️  Override: KMS_GCP_ENDPOINT = {}", config.kms.gcp_endpoint);
+    }
+
+    if let Ok(address) = std::env::var("VAULT_ADDR") {
+        config.vault.address = address;
+        println!("ℹ️  Override: VAULT_ADDR = {}", config.vault.address);
+    }
+
+    if let Ok(token) = std::env::var("VAULT_TOKEN") {
+        config.vault.token = token;
+        println!("ℹ️  Override: VAULT_TOKEN = <redacted>");
+    }
+
+    if let Ok(role_id) = std::env::var("VAULT_ROLE_ID") {
+        config.vault.role_id = role_id;
+        println!("ℹ️  Override: VAULT_ROLE_ID = {}", config.vault.role_id);
+    }
+
+    if let Ok(secret_id) = std::env::var("VAULT_SECRET_ID") {
+        config.vault.secret_id = secret_id;
+        println!("ℹ️  Override: VAULT_SECRET_ID = <redacted>");
+    }
+
+    if let Ok(namespace) = std::env::var("VAULT_NAMESPACE") {
+        config.vault.namespace = namespace;
+        println!("ℹ️  Override: VAULT_NAMESPACE = {}", config.vault.namespace);
+    }
+
+    if let Ok(transit_mount) = std::env::var("VAULT_TRANSIT_MOUNT") {
+        config.vault.transit_mount = transit_mount;
+        println!("ℹ️  Override: VAULT_TRANSIT_MOUNT = {}", config.vault.transit_mount);
+    }
+
+    if let Ok(key_name) = std::env::var("VAULT_KEY_NAME") {
+        config.vault.key_name = key_name;
+        println!("ℹ️  Override: VAULT_KEY_NAME = {}", config.vault.key_name);
+    }
+
+    if let Ok(directory) = std::env::var("KEYSTORE_DIR") {
+        config.keystore.directory = directory;
+        println!("ℹ️  Override: KEYSTORE_DIR = {}", config.keystore.directory);
+    }
+
+    if let Ok(kek) = std::env::var("KEYSTORE_KEK") {
+        config.keystore.kek = kek;
+        println!("ℹ️  Override: KEYSTORE_KEK = <redacted>");
+    }
+
+    if let Ok(provider) = std::env::var("KEYSTORE_KEK_KMS_PROVIDER") {
+        config.keystore.kek_kms_provider = provider;
+        println!("ℹ️  Override: KEYSTORE_KEK_KMS_PROVIDER = {}", config.keystore.kek_kms_provider);
+    }
+
+    if let Ok(key_name) = std::env::var("KEYSTORE_KEK_KMS_KEY_NAME") {
+        config.keystore.kek_kms_key_name = key_name;
+        println!("ℹ️  Override: KEYSTORE_KEK_KMS_KEY_NAME = {}", config.keystore.kek_kms_key_name);
+    }
+
+    if let Ok(ciphertext) = std::env::var("KEYSTORE_KEK_CIPHERTEXT") {
+        config.keystore.kek_ciphertext = ciphertext;
+        println!("ℹ️  Override: KEYSTORE_KEK_CIPHERTEXT = <set>");
+    }
+
+    if let Ok(required) = std::env::var("KEYSTORE_REQUIRED") {
+        config.keystore.required = required.to_lowercase() == "true";
+        println!("ℹ️  Override: KEYSTORE_REQUIRED = {}", config.keystore.required);
+    }
+
+    if let Ok(export_enabled) = std::env::var("KEYSTORE_EXPORT_ENABLED") {
+        config.keystore.export_enabled = export_enabled.to_lowercase() == "true";
+        println!("ℹ️  Override: KEYSTORE_EXPORT_ENABLED = {}", config.keystore.export_enabled);
+    }
+
+    if let Ok(interval) = std::env::var("ATA_WATCHER_POLL_INTERVAL_SECONDS") {
+        config.ata_watcher.poll_interval_seconds = interval.parse().map_err(|e| {
+            format!("Invalid ATA_WATCHER_POLL_INTERVAL_SECONDS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: ATA_WATCHER_POLL_INTERVAL_SECONDS = {}",
+            config.ata_watcher.poll_interval_seconds
+        );
+    }
+
+    if let Ok(max_bytes) = std::env::var("TOKEN_METADATA_MAX_DOCUMENT_BYTES") {
+        config.token_metadata.max_document_bytes = max_bytes.parse().map_err(|e| {
+            format!("Invalid TOKEN_METADATA_MAX_DOCUMENT_BYTES environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: TOKEN_METADATA_MAX_DOCUMENT_BYTES = {}",
+            config.token_metadata.max_document_bytes
+        );
+    }
+
+    if let Ok(ttl) = std::env::var("TOKEN_METADATA_CACHE_TTL_SECONDS") {
+        config.token_metadata.cache_ttl_seconds = ttl.parse().map_err(|e| {
+            format!("Invalid TOKEN_METADATA_CACHE_TTL_SECONDS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: TOKEN_METADATA_CACHE_TTL_SECONDS = {}",
+            config.token_metadata.cache_ttl_seconds
+        );
+    }
+
+    if let Ok(gateway) = std::env::var("TOKEN_METADATA_IPFS_GATEWAY") {
+        config.token_metadata.ipfs_gateway = gateway;
+        println!(
+            "ℹ️  Override: TOKEN_METADATA_IPFS_GATEWAY = {}",
+            config.token_metadata.ipfs_gateway
+        );
+    }
+
+    if let Ok(ttl) = std::env::var("SIMULATION_CACHE_TTL_MS") {
+        config.simulation_cache.ttl_ms = ttl
+            .parse()
+            .map_err(|e| format!("Invalid SIMULATION_CACHE_TTL_MS environment variable: {e}"))?;
+        println!("ℹ️  Override: SIMULATION_CACHE_TTL_MS = {}", config.simulation_cache.ttl_ms);
+    }
+
+    if let Ok(slots) = std::env::var("SIMULATION_CACHE_SLOTS_PER_BUCKET") {
+        config.simulation_cache.slots_per_bucket = slots.parse().map_err(|e| {
+            format!("Invalid SIMULATION_CACHE_SLOTS_PER_BUCKET environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: SIMULATION_CACHE_SLOTS_PER_BUCKET = {}",
+            config.simulation_cache.slots_per_bucket
+        );
+    }
+
+    if let Ok(locale) = std::env::var("LOCALIZATION_DEFAULT_LOCALE") {
+        config.localization.default_locale = locale;
+        println!(
+            "ℹ️  Override: LOCALIZATION_DEFAULT_LOCALE = {}",
+            config.localization.default_locale
+        );
+    }
+
+    if let Ok(directory) = std::env::var("LOCALIZATION_CATALOG_DIR") {
+        config.localization.catalog_directory = directory;
+        println!(
+            "ℹ️  Override: LOCALIZATION_CATALOG_DIR = {}",
+            config.localization.catalog_directory
+        );
+    }
+
+    if let Ok(directory) = std::env::var("ACCOUNT_SNAPSHOT_IMPORT_DIR") {
+        config.account_snapshots.import_directory = directory;
+        println!(
+            "ℹ️  Override: ACCOUNT_SNAPSHOT_IMPORT_DIR = {}",
+            config.account_snapshots.import_directory
+        );
+    }
+
+    if let Ok(min_tip) = std::env::var("JITO_MIN_TIP_LAMPORTS") {
+        config.jito.min_tip_lamports = min_tip
+            .parse()
+            .map_err(|e| format!("Invalid JITO_MIN_TIP_LAMPORTS environment variable: {e}"))?;
+        println!("ℹ️  Override: JITO_MIN_TIP_LAMPORTS = {}", config.jito.min_tip_lamports);
+    }
+
+    if let Ok(max_concurrent) = std::env::var("RPC_MAX_CONCURRENT") {
+        config.rpc_limits.max_concurrent = max_concurrent
+            .parse()
+            .map_err(|e| format!("Invalid RPC_MAX_CONCURRENT environment variable: {e}"))?;
+        println!("ℹ️  Override: RPC_MAX_CONCURRENT = {}", config.rpc_limits.max_concurrent);
+    }
+
+    if let Ok(queue_timeout) = std::env::var("RPC_QUEUE_TIMEOUT_MS") {
+        config.rpc_limits.queue_timeout_ms = queue_timeout
+            .parse()
+            .map_err(|e| format!("Invalid RPC_QUEUE_TIMEOUT_MS environment variable: {e}"))?;
+        println!("ℹ️  Override: RPC_QUEUE_TIMEOUT_MS = {}", config.rpc_limits.queue_timeout_ms);
+    }
+
+    if let Ok(max_in_flight) = std::env::var("ADMISSION_MAX_IN_FLIGHT") {
+        config.admission.max_in_flight = max_in_flight
+            .parse()
+            .map_err(|e| format!("Invalid ADMISSION_MAX_IN_FLIGHT environment variable: {e}"))?;
+        println!("ℹ️  Override: ADMISSION_MAX_IN_FLIGHT = {}", config.admission.max_in_flight);
+    }
+
+    if let Ok(max_queued) = std::env::var("ADMISSION_MAX_QUEUED") {
+        config.admission.max_queued = max_queued
+            .parse()
+            .map_err(|e| format!("Invalid ADMISSION_MAX_QUEUED environment variable: {e}"))?;
+        println!("ℹ️  Override: ADMISSION_MAX_QUEUED = {}", config.admission.max_queued);
+    }
+
+    if let Ok(max_queue_ms) = std::env::var("ADMISSION_MAX_QUEUE_MS") {
+        config.admission.max_queue_ms = max_queue_ms
+            .parse()
+            .map_err(|e| format!("Invalid ADMISSION_MAX_QUEUE_MS environment variable: {e}"))?;
+        println!("ℹ️  Override: ADMISSION_MAX_QUEUE_MS = {}", config.admission.max_queue_ms);
+    }
+
+    if let Ok(dry_run) = std::env::var("SUBMISSION_DRY_RUN") {
+        config.submission.dry_run = dry_run.to_lowercase() == "true";
+        println!("ℹ️  Override: SUBMISSION_DRY_RUN = {}", config.submission.dry_run);
+    }
+
+    if let Ok(require_token) = std::env::var("SUBMISSION_REQUIRE_TOKEN") {
+        config.submission.require_token = require_token.to_lowercase() == "true";
+        println!("ℹ️  Override: SUBMISSION_REQUIRE_TOKEN = {}", config.submission.require_token);
+    }
+
+    if let Ok(enabled) = std::env::var("QUEUE_ENABLED") {
+        config.queue.enabled = enabled.to_lowercase() == "true";
+        println!("ℹ️  Override: QUEUE_ENABLED = {}", config.queue.enabled);
+    }
+
+    if let Ok(max_tps) = std::env::var("QUEUE_MAX_TPS") {
+        config.queue.max_tps = max_tps
+            .parse()
+            .map_err(|e| format!("Invalid QUEUE_MAX_TPS environment variable: {e}"))?;
+        println!("ℹ️  Override: QUEUE_MAX_TPS = {}", config.queue.max_tps);
+    }
+
+    if let Ok(max_pending) = std::env::var("QUEUE_MAX_PENDING") {
+        config.queue.max_pending = max_pending
+            .parse()
+            .map_err(|e| format!("Invalid QUEUE_MAX_PENDING environment variable: {e}"))?;
+        println!("ℹ️  Override: QUEUE_MAX_PENDING = {}", config.queue.max_pending);
+    }
+
+    if let Ok(gc_interval_seconds) = std::env::var("RETENTION_GC_INTERVAL_SECONDS") {
+        config.retention.gc_interval_seconds = gc_interval_seconds.parse().map_err(|e| {
+            format!("Invalid RETENTION_GC_INTERVAL_SECONDS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: RETENTION_GC_INTERVAL_SECONDS = {}",
+            config.retention.gc_interval_seconds
+        );
+    }
+
+    if let Ok(submission_ttl_seconds) = std::env::var("RETENTION_SUBMISSION_TTL_SECONDS") {
+        config.retention.submission_ttl_seconds = submission_ttl_seconds.parse().map_err(|e| {
+            format!("Invalid RETENTION_SUBMISSION_TTL_SECONDS environment variable: {e}")
+        })?;
+        println!(
+            "ℹ️  Override: RETENTION_SUBMISSION_TTL_SECONDS = {}",
+            config.retention.submission_ttl_seconds
+        );
+    }
+
+    if let Ok(idempotency_ttl_seconds) = std::env::var("RETENTION_IDEMPOTENCY_TTL_SECONDS") {
+        config.retention.idempotency_ttl_seconds =
+            idempotency_ttl_seconds.parse().map_err(|e| {
+                format!("Invalid RETENTION_IDEMPOTENCY_TTL_SECONDS environment variable: {e}")
+            })?;
+        println!(
+            "ℹ️  Override: RETENTION_IDEMPOTENCY_TTL_SECONDS = {}",
+            config.retention.idempotency_ttl_seconds
+        );
+    }
+
+    if let Ok(dead_letter_ttl_seconds) = std::env::var("RETENTION_DEAD_LETTER_TTL_SECONDS") {
+        config.retention.dead_letter_ttl_seconds =
+            dead_letter_ttl_seconds.parse().map_err(|e| {
+                format!("Invalid RETENTION_DEAD_LETTER_TTL_SECONDS environment variable: {e}")
+            })?;
+        println!(
+            "ℹ️  Override: RETENTION_DEAD_LETTER_TTL_SECONDS = {}",
+            config.retention.dead_letter_ttl_seconds
+        );
+    }
+
+    if let Ok(operation_retention_seconds) = std::env::var("RETENTION_OPERATION_RETENTION_SECONDS")
+    {
+        config.retention.operation_retention_seconds =
+            operation_retention_seconds.parse().map_err(|e| {
+                format!("Invalid RETENTION_OPERATION_RETENTION_SECONDS environment variable: {e}")
+            })?;
+        println!(
+            "ℹ️  Override: RETENTION_OPERATION_RETENTION_SECONDS = {}",
+            config.retention.operation_retention_seconds
+        );
+    }
+
     Ok(config)
 }
 
@@ -166,6 +1313,71 @@ mod tests {
         assert!(config.solana.health_check_on_startup);
         assert_eq!(config.server.host, "127.0.0.1");
         assert_eq!(config.server.port, 50051);
+        assert!(config.feature_flags.flags.is_empty());
+        assert!(!config.feature_flags.allow_runtime_toggles);
+        assert!(config.auth.admin_token.is_empty());
+        assert!(config.auth.caller_tokens.is_empty());
+        assert_eq!(config.event_export.flush_interval_seconds, 60);
+        assert!(config.event_export.parquet.destination.is_empty());
+        assert!(config.event_export.bigquery.project.is_empty());
+        assert!(config.funding.treasury_key_ref.is_empty());
+        assert!(!config.funding.reject_string_amounts);
+        assert!(config.sponsorship.fee_payer_key_refs.is_empty());
+        assert!(config.webhooks.url.is_empty());
+        assert_eq!(config.webhooks.max_attempts, 3);
+        assert_eq!(config.balance_alerts.poll_interval_seconds, 60);
+        assert!(config.balance_alerts.rules.is_empty());
+        assert!(!config.queue.enabled);
+        assert_eq!(config.queue.max_tps, 10);
+        assert_eq!(config.retention.gc_interval_seconds, 60);
+        assert_eq!(config.retention.submission_ttl_seconds, 86_400);
+        assert!(config.hardware_wallet.agent_url.is_empty());
+        assert!(!config.hardware_wallet.allow_blind_signing);
+        assert!(config.kms.keys.is_empty());
+        assert!(config.vault.address.is_empty());
+        assert_eq!(config.vault.transit_mount, "transit");
+        assert_eq!(config.ata_watcher.poll_interval_seconds, 30);
+        assert!(config.ata_watcher.tenants.is_empty());
+        assert!(config.keystore.directory.is_empty());
+        assert!(!config.keystore.required);
+        assert!(!config.keystore.export_enabled);
+        assert_eq!(config.token_metadata.max_document_bytes, 256 * 1024);
+        assert_eq!(config.token_metadata.cache_ttl_seconds, 600);
+        assert_eq!(config.simulation_cache.ttl_ms, 2_000);
+        assert_eq!(config.simulation_cache.slots_per_bucket, 4);
+        assert_eq!(config.localization.default_locale, "en");
+        assert!(config.localization.catalog_directory.is_empty());
+        assert!(config.account_snapshots.import_directory.is_empty());
+    }
+
+    #[test]
+    fn test_config_without_feature_flags_section() {
+        let json = r#"{
+            "solana": {"rpc_url": "http://localhost:8899", "timeout_seconds": 30, "retry_attempts": 3, "health_check_on_startup": false},
+            "server": {"host": "127.0.0.1", "port": 50051}
+        }"#;
+
+        let config: Config = serde_json::from_str(json).unwrap();
+        assert!(config.feature_flags.flags.is_empty());
+        assert!(!config.feature_flags.allow_runtime_toggles);
+        assert!(config.solana.processed_rpc_url.is_empty());
+        assert!(config.solana.finalized_rpc_url.is_empty());
+    }
+
+    #[test]
+    fn test_parse_feature_flag_overrides() {
+        let overrides =
+            parse_feature_flag_overrides("v0_transactions=true, jito_bundles=off,").unwrap();
+        assert_eq!(
+            overrides,
+            vec![
+                ("v0_transactions".to_string(), true),
+                ("jito_bundles".to_string(), false)
+            ]
+        );
+
+        assert!(parse_feature_flag_overrides("v0_transactions").is_err());
+        assert!(parse_feature_flag_overrides("v0_transactions=maybe").is_err());
     }
 
     #[test]
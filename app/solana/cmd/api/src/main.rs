@@ -7,6 +7,7 @@
 //! - Account management (creation, funding, querying)
 //! - Transaction lifecycle management (compilation, signing, submission)
 //! - System program operations (transfers, account creation)
+//! - One-call builders for common transfers and account creation
 //! - Real-time transaction monitoring via WebSocket
 
 use anyhow::Result;
@@ -18,10 +19,25 @@ use tracing_subscriber::{fmt, prelude::*, EnvFilter};
 
 // Import the generated protobuf services
 use protochain_api::protochain::solana::account::v1::service_server::ServiceServer as AccountServiceServer;
+use protochain_api::protochain::solana::admin::v1::service_server::ServiceServer as AdminServiceServer;
+use protochain_api::protochain::solana::convenience::v1::service_server::ServiceServer as ConvenienceServiceServer;
+use protochain_api::protochain::solana::key_vault::v1::service_server::ServiceServer as KeyVaultServiceServer;
+use protochain_api::protochain::solana::operations::v1::service_server::ServiceServer as OperationsServiceServer;
+use protochain_api::protochain::solana::program::address_lookup_table::v1::service_server::ServiceServer as AddressLookupTableProgramServiceServer;
+use protochain_api::protochain::solana::program::ata::v1::service_server::ServiceServer as AtaProgramServiceServer;
+use protochain_api::protochain::solana::program::bpf_loader::v1::service_server::ServiceServer as BpfLoaderProgramServiceServer;
+use protochain_api::protochain::solana::program::compute_budget::v1::service_server::ServiceServer as ComputeBudgetProgramServiceServer;
+use protochain_api::protochain::solana::program::governance::v1::service_server::ServiceServer as GovernanceProgramServiceServer;
+use protochain_api::protochain::solana::program::memo::v1::service_server::ServiceServer as MemoProgramServiceServer;
+use protochain_api::protochain::solana::program::metadata::v1::service_server::ServiceServer as MetadataServiceServer;
+use protochain_api::protochain::solana::program::stake::v1::service_server::ServiceServer as StakeProgramServiceServer;
 use protochain_api::protochain::solana::program::system::v1::service_server::ServiceServer as SystemProgramServiceServer;
 use protochain_api::protochain::solana::program::token::v1::service_server::ServiceServer as TokenProgramServiceServer;
+use protochain_api::protochain::solana::program::vote::v1::service_server::ServiceServer as VoteProgramServiceServer;
 use protochain_api::protochain::solana::rpc_client::v1::service_server::ServiceServer as RpcClientServiceServer;
+use protochain_api::protochain::solana::staking::v1::service_server::ServiceServer as StakingServiceServer;
 use protochain_api::protochain::solana::transaction::v1::service_server::ServiceServer as TransactionServiceServer;
+use protochain_api::protochain::solana::transaction_template::v1::service_server::ServiceServer as TransactionTemplateServiceServer;
 
 // Import our application modules
 mod api;
@@ -143,7 +159,7 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
         address = %addr,
         "🌟 Starting Solana gRPC server"
     );
-    info!("📡 Services: Transaction v1, Account v1, System Program v1, Token Program v1, RPC Client v1");
+    info!("📡 Services: Transaction v1, Account v1, System Program v1, Token Program v1, RPC Client v1, Admin v1, Key Vault v1");
     info!("📋 Ready to accept connections!");
 
     // Start periodic cleanup task for WebSocket subscriptions
@@ -157,13 +173,141 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
         }
     });
 
+    // Start the checks of balance threshold rules, notified through the webhook sink
+    let balance_alert_providers = Arc::clone(&service_providers);
+    let balance_alert_task = service_providers.balance_alerts.is_enabled().then(|| {
+        tokio::spawn(async move {
+            let mut interval =
+                tokio::time::interval(balance_alert_providers.balance_alerts.interval());
+            debug!(
+                interval_seconds = balance_alert_providers.balance_alerts.interval().as_secs(),
+                "Started balance threshold checks"
+            );
+            loop {
+                interval.tick().await;
+                let providers = Arc::clone(&balance_alert_providers);
+                let checked = tokio::task::spawn_blocking(move || {
+                    api::account::v1::balance_watch::check(
+                        &providers.solana_clients.get_rpc_client(),
+                        &providers.balance_alerts,
+                    )
+                })
+                .await;
+                match checked {
+                    Ok(crossings) if !crossings.is_empty() => {
+                        api::account::v1::balance_watch::notify(
+                            &balance_alert_providers.balance_alerts,
+                            &balance_alert_providers.webhooks,
+                            crossings,
+                        )
+                        .await;
+                    }
+                    Ok(_) => {}
+                    Err(e) => error!(error = %e, "❌ Balance threshold check panicked"),
+                }
+            }
+        })
+    });
+
+    // Start the periodic flush of submission events to the Parquet and BigQuery sinks
+    let event_export_providers = Arc::clone(&service_providers);
+    let event_export_task = service_providers.event_export.is_enabled().then(|| {
+        tokio::spawn(async move {
+            let mut interval =
+                tokio::time::interval(event_export_providers.event_export.interval());
+            debug!(
+                interval_seconds = event_export_providers.event_export.interval().as_secs(),
+                "Started event export"
+            );
+            loop {
+                interval.tick().await;
+                let written = event_export_providers.event_export.flush().await;
+                if written > 0 {
+                    debug!(written, "📤 Exported submission events");
+                }
+            }
+        })
+    });
+
+    // Start periodic collection of expired records from the in-memory stores
+    let retention = service_providers.retention.clone();
+    let retention_task = (!retention.interval().is_zero()).then(|| {
+        tokio::spawn(async move {
+            let mut interval = tokio::time::interval(retention.interval());
+            debug!(
+                interval_seconds = retention.interval().as_secs(),
+                "Started store retention task"
+            );
+            loop {
+                interval.tick().await;
+                let purged = retention.collect();
+                if purged > 0 {
+                    debug!(purged, "🧹 Purged expired records from in-memory stores");
+                }
+            }
+        })
+    });
+
+    // Start the watcher creating missing associated token accounts on inbound transfers
+    let ata_watch_providers = Arc::clone(&service_providers);
+    let ata_watch_task = service_providers.ata_watcher.is_enabled().then(|| {
+        tokio::spawn(async move {
+            let mut interval = tokio::time::interval(ata_watch_providers.ata_watcher.interval());
+            debug!(
+                interval_seconds = ata_watch_providers.ata_watcher.interval().as_secs(),
+                "Started associated token account watcher"
+            );
+            loop {
+                interval.tick().await;
+                let providers = Arc::clone(&ata_watch_providers);
+                let swept = tokio::task::spawn_blocking(move || {
+                    api::account::v1::ata_watch::sweep(
+                        &providers.solana_clients.get_rpc_client(),
+                        &providers.key_vault,
+                        &providers.sponsorship,
+                        &providers.ata_watcher,
+                    )
+                })
+                .await;
+                match swept {
+                    Ok(created) if created > 0 => {
+                        debug!(created, "🪣 Created missing associated token accounts");
+                    }
+                    Ok(_) => {}
+                    Err(e) => error!(error = %e, "❌ Associated token account sweep panicked"),
+                }
+            }
+        })
+    });
+
     // Build and start the gRPC server with our service implementations
     // Clone the services from the Arc containers
     let transaction_service = (*api.transaction_v1.transaction_service).clone();
     let account_service = (*api.account_v1.account_service).clone();
     let system_program_service = (*api.program.system.v1.system_program_service).clone();
     let token_program_service = (*api.program.token.token_program_service).clone();
+    let ata_program_service = (*api.program.ata.ata_program_service).clone();
+    let memo_program_service = (*api.program.memo.memo_program_service).clone();
+    let compute_budget_program_service =
+        (*api.program.compute_budget.compute_budget_program_service).clone();
+    let stake_program_service = (*api.program.stake.stake_program_service).clone();
+    let address_lookup_table_program_service = (*api
+        .program
+        .address_lookup_table
+        .address_lookup_table_program_service)
+        .clone();
+    let vote_program_service = (*api.program.vote.vote_program_service).clone();
+    let metadata_service = (*api.program.metadata.metadata_service).clone();
+    let governance_program_service = (*api.program.governance.governance_program_service).clone();
+    let bpf_loader_program_service = (*api.program.bpf_loader.bpf_loader_program_service).clone();
     let rpc_client_service = (*api.rpc_client_v1.rpc_client_service).clone();
+    let admin_service = (*api.admin_v1.admin_service).clone();
+    let key_vault_service = (*api.key_vault_v1.key_vault_service).clone();
+    let convenience_service = (*api.convenience_v1.convenience_service).clone();
+    let transaction_template_service =
+        (*api.transaction_template_v1.transaction_template_service).clone();
+    let operations_service = (*api.operations_v1.operations_service).clone();
+    let staking_service = (*api.staking_v1.staking_service).clone();
 
     // Clone service providers for graceful shutdown
     let service_providers_shutdown = Arc::clone(&service_providers);
@@ -174,7 +318,24 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
         .add_service(AccountServiceServer::new(account_service))
         .add_service(SystemProgramServiceServer::new(system_program_service))
         .add_service(TokenProgramServiceServer::new(token_program_service))
+        .add_service(AtaProgramServiceServer::new(ata_program_service))
+        .add_service(MemoProgramServiceServer::new(memo_program_service))
+        .add_service(ComputeBudgetProgramServiceServer::new(compute_budget_program_service))
+        .add_service(StakeProgramServiceServer::new(stake_program_service))
+        .add_service(AddressLookupTableProgramServiceServer::new(
+            address_lookup_table_program_service,
+        ))
+        .add_service(VoteProgramServiceServer::new(vote_program_service))
+        .add_service(MetadataServiceServer::new(metadata_service))
+        .add_service(GovernanceProgramServiceServer::new(governance_program_service))
+        .add_service(BpfLoaderProgramServiceServer::new(bpf_loader_program_service))
         .add_service(RpcClientServiceServer::new(rpc_client_service))
+        .add_service(AdminServiceServer::new(admin_service))
+        .add_service(KeyVaultServiceServer::new(key_vault_service))
+        .add_service(ConvenienceServiceServer::new(convenience_service))
+        .add_service(TransactionTemplateServiceServer::new(transaction_template_service))
+        .add_service(OperationsServiceServer::new(operations_service))
+        .add_service(StakingServiceServer::new(staking_service))
         .serve(addr);
 
     // Wait for server or shutdown signal
@@ -191,6 +352,24 @@ async fn main() -> Result<(), Box<dyn std::error::Error>> {
             // Abort cleanup task
             cleanup_task.abort();
             debug!("WebSocket cleanup task aborted");
+            if let Some(balance_alert_task) = balance_alert_task {
+                balance_alert_task.abort();
+                debug!("Balance threshold checks aborted");
+            }
+            if let Some(event_export_task) = event_export_task {
+                event_export_task.abort();
+                // Write out what is still buffered rather than lose it
+                let written = service_providers_shutdown.event_export.flush().await;
+                debug!(written, "Event export flushed and stopped");
+            }
+            if let Some(retention_task) = retention_task {
+                retention_task.abort();
+                debug!("Store retention task aborted");
+            }
+            if let Some(ata_watch_task) = ata_watch_task {
+                ata_watch_task.abort();
+                debug!("Associated token account watcher aborted");
+            }
 
             // Shutdown WebSocket manager
             service_providers_shutdown.websocket_manager.shutdown();
@@ -0,0 +1,203 @@
+use std::sync::atomic::{AtomicU64, AtomicUsize, Ordering};
+use std::time::{Duration, Instant};
+use tokio::sync::{Semaphore, SemaphorePermit};
+use tokio::time::timeout;
+
+use crate::config::AdmissionConfig;
+
+/// Why a request was turned away
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Overloaded {
+    /// Every in-flight slot was taken and the wait queue was full
+    QueueFull,
+    /// The request waited the longest allowed time without a slot freeing up
+    QueueTimeout,
+}
+
+impl Overloaded {
+    /// Stable name used in error details
+    pub const fn name(self) -> &'static str {
+        match self {
+            Self::QueueFull => "queue_full",
+            Self::QueueTimeout => "queue_timeout",
+        }
+    }
+}
+
+/// Holds an in-flight slot until dropped
+#[derive(Debug)]
+pub struct Admission<'a> {
+    _slot: SemaphorePermit<'a>,
+    /// How long the request waited for its slot
+    pub queued_for: Duration,
+}
+
+/// Reserves a place in the wait queue until dropped, so cancelled waits are not leaked
+struct QueuePlace<'a>(&'a AtomicUsize);
+
+impl Drop for QueuePlace<'_> {
+    fn drop(&mut self) {
+        self.0.fetch_sub(1, Ordering::Relaxed);
+    }
+}
+
+/// Point-in-time usage of the admission queue
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct AdmissionStats {
+    /// Requests being processed
+    pub in_flight: usize,
+    /// Requests waiting for a slot
+    pub queued: usize,
+    /// Requests that had to wait since startup
+    pub delayed: u64,
+    /// Requests turned away since startup
+    pub rejected: u64,
+}
+
+/// Admission control for bursts of requests.
+///
+/// Up to `max_in_flight` requests are processed at once. Beyond that, requests wait in
+/// arrival order in a queue of at most `max_queued` for up to `max_queue_ms`, so a short
+/// burst is absorbed as added latency rather than errors. Only once either bound is
+/// exceeded is a request turned away.
+pub struct AdmissionController {
+    slots: Semaphore,
+    max_in_flight: usize,
+    max_queued: usize,
+    max_wait: Duration,
+    queued: AtomicUsize,
+    delayed: AtomicU64,
+    rejected: AtomicU64,
+}
+
+impl AdmissionController {
+    /// Builds the controller from configuration, rejecting a zero in-flight limit
+    pub fn from_config(config: &AdmissionConfig) -> Result<Self, String> {
+        if config.max_in_flight == 0 {
+            return Err("Admission in-flight limit must be at least 1".to_string());
+        }
+        Ok(Self {
+            slots: Semaphore::new(config.max_in_flight),
+            max_in_flight: config.max_in_flight,
+            max_queued: config.max_queued,
+            max_wait: Duration::from_millis(config.max_queue_ms),
+            queued: AtomicUsize::new(0),
+            delayed: AtomicU64::new(0),
+            rejected: AtomicU64::new(0),
+        })
+    }
+
+    /// Longest a request may wait for a slot
+    pub const fn max_wait(&self) -> Duration {
+        self.max_wait
+    }
+
+    /// Waits for an in-flight slot, failing when the queue is full or the wait times out
+    pub async fn admit(&self) -> Result<Admission<'_>, Overloaded> {
+        if let Ok(slot) = self.slots.try_acquire() {
+            return Ok(Admission {
+                _slot: slot,
+                queued_for: Duration::ZERO,
+            });
+        }
+
+        let started = Instant::now();
+        if self
+            .queued
+            .fetch_update(Ordering::Relaxed, Ordering::Relaxed, |queued| {
+                (queued < self.max_queued).then_some(queued + 1)
+            })
+            .is_err()
+        {
+            self.rejected.fetch_add(1, Ordering::Relaxed);
+            return Err(Overloaded::QueueFull);
+        }
+        let _place = QueuePlace(&self.queued);
+        self.delayed.fetch_add(1, Ordering::Relaxed);
+
+        if let Ok(Ok(slot)) = timeout(self.max_wait, self.slots.acquire()).await {
+            return Ok(Admission {
+                _slot: slot,
+                queued_for: started.elapsed(),
+            });
+        }
+        self.rejected.fetch_add(1, Ordering::Relaxed);
+        Err(Overloaded::QueueTimeout)
+    }
+
+    /// Current usage and counters since startup
+    pub fn stats(&self) -> AdmissionStats {
+        AdmissionStats {
+            in_flight: self
+                .max_in_flight
+                .saturating_sub(self.slots.available_permits()),
+            queued: self.queued.load(Ordering::Relaxed),
+            delayed: self.delayed.load(Ordering::Relaxed),
+            rejected: self.rejected.load(Ordering::Relaxed),
+        }
+    }
+}
+
+impl std::fmt::Debug for AdmissionController {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("AdmissionController")
+            .field("max_in_flight", &self.max_in_flight)
+            .field("max_queued", &self.max_queued)
+            .field("max_wait", &self.max_wait)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn controller(max_in_flight: usize, max_queued: usize) -> AdmissionController {
+        AdmissionController::from_config(&AdmissionConfig {
+            max_in_flight,
+            max_queued,
+            max_queue_ms: 20,
+        })
+        .unwrap()
+    }
+
+    #[test]
+    fn test_zero_in_flight_limit_is_rejected() {
+        let config = AdmissionConfig {
+            max_in_flight: 0,
+            ..Default::default()
+        };
+        assert!(AdmissionController::from_config(&config).is_err());
+    }
+
+    #[tokio::test]
+    async fn test_burst_queues_until_a_slot_frees() {
+        let controller = controller(1, 1);
+
+        let first = controller.admit().await.unwrap();
+        assert_eq!(first.queued_for, Duration::ZERO);
+
+        let (second, ()) = tokio::join!(controller.admit(), async {
+            tokio::time::sleep(Duration::from_millis(5)).await;
+            drop(first);
+        });
+        assert!(second.unwrap().queued_for > Duration::ZERO);
+
+        let stats = controller.stats();
+        assert_eq!((stats.delayed, stats.rejected, stats.queued), (1, 0, 0));
+    }
+
+    #[tokio::test]
+    async fn test_rejects_once_bounds_are_exceeded() {
+        let no_queue = controller(1, 0);
+        let _held = no_queue.admit().await.unwrap();
+        assert_eq!(no_queue.admit().await.unwrap_err(), Overloaded::QueueFull);
+
+        let short_queue = controller(1, 1);
+        let _held = short_queue.admit().await.unwrap();
+        assert_eq!(short_queue.admit().await.unwrap_err(), Overloaded::QueueTimeout);
+        assert_eq!(short_queue.stats().rejected, 1);
+        assert_eq!(short_queue.stats().queued, 0);
+    }
+}
@@ -0,0 +1,201 @@
+use dashmap::DashMap;
+use solana_sdk::pubkey::Pubkey;
+use std::str::FromStr;
+use std::time::Duration;
+
+use super::sponsorship::validate_caller_id;
+use super::unix_timestamp;
+use crate::config::AtaWatcherConfig;
+
+/// How long a watched account is left alone after a failed creation
+const RETRY_BACKOFF_SECONDS: i64 = 300;
+
+/// An owner and mint whose associated token account is watched
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct WatchedAccount {
+    /// Tenant that registered the owner and is charged for creations
+    pub tenant: String,
+    /// Wallet owning the associated token account
+    pub owner: Pubkey,
+    /// Mint of the associated token account
+    pub mint: Pubkey,
+    /// Whether the tenant's policy creates the account on inbound transfers
+    pub auto_create: bool,
+}
+
+#[derive(Debug, Clone, Copy)]
+enum WatchState {
+    /// The account exists, so there is nothing left to watch
+    Exists,
+    /// Creation failed; checks resume at this unix timestamp
+    RetryAfter(i64),
+}
+
+/// Registry of owners whose missing associated token accounts are created on demand.
+///
+/// Owners and mints are registered per tenant from configuration. The background sweep
+/// (`api::account::v1::ata_watch`) checks the accounts still `due` and, when an inbound
+/// transfer targets one that does not exist, creates it if the tenant's policy allows, so
+/// that deposits are not stranded by a missing account. Once an account exists it is no
+/// longer checked.
+pub struct AtaWatcher {
+    interval: Duration,
+    watches: Vec<WatchedAccount>,
+    states: DashMap<(Pubkey, Pubkey), WatchState>,
+}
+
+impl AtaWatcher {
+    /// Builds the registry from configuration, rejecting invalid tenants and addresses
+    pub fn from_config(config: &AtaWatcherConfig) -> Result<Self, String> {
+        let mut watches = Vec::new();
+        for (tenant, policy) in &config.tenants {
+            validate_caller_id(tenant).map_err(|e| format!("Invalid tenant {tenant:?}: {e}"))?;
+            let owners = parse_addresses(tenant, "owner", &policy.owners)?;
+            let mints = parse_addresses(tenant, "mint", &policy.mints)?;
+            for owner in &owners {
+                for mint in &mints {
+                    watches.push(WatchedAccount {
+                        tenant: tenant.clone(),
+                        owner: *owner,
+                        mint: *mint,
+                        auto_create: policy.auto_create,
+                    });
+                }
+            }
+        }
+
+        Ok(Self {
+            interval: Duration::from_secs(config.poll_interval_seconds),
+            watches,
+            states: DashMap::new(),
+        })
+    }
+
+    /// How often the background sweep runs (zero disables it)
+    pub const fn interval(&self) -> Duration {
+        self.interval
+    }
+
+    /// Whether the sweep has anything to watch
+    pub fn is_enabled(&self) -> bool {
+        !self.interval.is_zero() && !self.watches.is_empty()
+    }
+
+    /// Watched accounts not yet known to exist and not backing off after a failure
+    pub fn due(&self) -> Vec<WatchedAccount> {
+        let now = unix_timestamp();
+        self.watches
+            .iter()
+            .filter(|watch| match self.states.get(&(watch.owner, watch.mint)) {
+                Some(state) => match *state {
+                    WatchState::Exists => false,
+                    WatchState::RetryAfter(at) => now >= at,
+                },
+                None => true,
+            })
+            .cloned()
+            .collect()
+    }
+
+    /// Stops watching an account that exists
+    pub fn mark_exists(&self, watch: &WatchedAccount) {
+        self.states
+            .insert((watch.owner, watch.mint), WatchState::Exists);
+    }
+
+    /// Backs off from an account whose creation failed
+    pub fn defer(&self, watch: &WatchedAccount) {
+        self.states.insert(
+            (watch.owner, watch.mint),
+            WatchState::RetryAfter(unix_timestamp() + RETRY_BACKOFF_SECONDS),
+        );
+    }
+}
+
+impl std::fmt::Debug for AtaWatcher {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("AtaWatcher")
+            .field("interval", &self.interval)
+            .field("watches", &self.watches.len())
+            .finish_non_exhaustive()
+    }
+}
+
+fn parse_addresses(tenant: &str, field: &str, values: &[String]) -> Result<Vec<Pubkey>, String> {
+    values
+        .iter()
+        .map(|value| {
+            Pubkey::from_str(value)
+                .map_err(|e| format!("Invalid {field} {value} for tenant {tenant}: {e}"))
+        })
+        .collect()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::config::AtaWatchPolicyConfig;
+    use std::collections::BTreeMap;
+
+    fn config(tenants: &[(&str, bool, usize, usize)]) -> AtaWatcherConfig {
+        AtaWatcherConfig {
+            poll_interval_seconds: 30,
+            tenants: tenants
+                .iter()
+                .map(|(tenant, auto_create, owners, mints)| {
+                    (
+                        (*tenant).to_string(),
+                        AtaWatchPolicyConfig {
+                            auto_create: *auto_create,
+                            owners: (0..*owners)
+                                .map(|_| Pubkey::new_unique().to_string())
+                                .collect(),
+                            mints: (0..*mints)
+                                .map(|_| Pubkey::new_unique().to_string())
+                                .collect(),
+                        },
+                    )
+                })
+                .collect::<BTreeMap<_, _>>(),
+        }
+    }
+
+    #[test]
+    fn test_watches_every_owner_and_mint() {
+        let watcher =
+            AtaWatcher::from_config(&config(&[("acme", true, 2, 3), ("globex", false, 1, 1)]))
+                .unwrap();
+        assert!(watcher.is_enabled());
+
+        let due = watcher.due();
+        assert_eq!(due.len(), 7);
+        assert_eq!(due.iter().filter(|watch| watch.auto_create).count(), 6);
+        assert!(due
+            .iter()
+            .filter(|watch| !watch.auto_create)
+            .all(|watch| watch.tenant == "globex"));
+    }
+
+    #[test]
+    fn test_existing_and_deferred_accounts_are_not_due() {
+        let watcher = AtaWatcher::from_config(&config(&[("acme", true, 1, 3)])).unwrap();
+        let due = watcher.due();
+
+        watcher.mark_exists(&due[0]);
+        watcher.defer(&due[1]);
+        assert_eq!(watcher.due(), vec![due[2].clone()]);
+    }
+
+    #[test]
+    fn test_rejects_invalid_registrations() {
+        let mut invalid = config(&[("acme", true, 1, 1)]);
+        invalid.tenants.get_mut("acme").unwrap().mints = vec!["not-a-mint".to_string()];
+        assert!(AtaWatcher::from_config(&invalid).is_err());
+
+        assert!(AtaWatcher::from_config(&config(&[("has space", true, 1, 1)])).is_err());
+        assert!(!AtaWatcher::from_config(&AtaWatcherConfig::default())
+            .unwrap()
+            .is_enabled());
+    }
+}
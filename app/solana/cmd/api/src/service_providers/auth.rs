@@ -0,0 +1,214 @@
+use sha2::{Digest, Sha256};
+use std::collections::HashMap;
+use tonic::metadata::MetadataMap;
+use tonic::Status;
+
+use super::sponsorship::validate_caller_id;
+use crate::config::AuthConfig;
+
+/// Metadata key credentials are presented under, as `Bearer <token>`
+pub const AUTHORIZATION_HEADER: &str = "authorization";
+
+/// Shortest token accepted, so a placeholder cannot end up guarding production
+const MIN_TOKEN_LENGTH: usize = 16;
+
+/// Checks the credentials callers present in request metadata.
+///
+/// Operator-only RPCs require `authorization: Bearer <admin_token>`; RPCs that act on a
+/// caller's behalf take the caller id from the caller token presented the same way, never
+/// from the request body. Tokens are compared as SHA-256 digests, so how long a
+/// comparison takes says nothing about how close a guess was. Without a configured admin
+/// token every operator-only RPC is refused.
+pub struct Authenticator {
+    admin_token_digest: Option<[u8; 32]>,
+    callers: HashMap<[u8; 32], String>,
+}
+
+impl Authenticator {
+    /// Builds the authenticator from configuration, rejecting tokens that are too short,
+    /// invalid caller ids and tokens shared between identities
+    pub fn from_config(config: &AuthConfig) -> Result<Self, String> {
+        let admin_token_digest = if config.admin_token.is_empty() {
+            None
+        } else if config.admin_token.len() < MIN_TOKEN_LENGTH {
+            return Err(format!("admin_token must be at least {MIN_TOKEN_LENGTH} characters"));
+        } else {
+            Some(digest(&config.admin_token))
+        };
+
+        let mut callers = HashMap::new();
+        for (caller_id, token) in &config.caller_tokens {
+            validate_caller_id(caller_id)
+                .map_err(|e| format!("Invalid caller {caller_id:?}: {e}"))?;
+            if token.len() < MIN_TOKEN_LENGTH {
+                return Err(format!(
+                    "Token of caller {caller_id:?} must be at least {MIN_TOKEN_LENGTH} characters"
+                ));
+            }
+            let token_digest = digest(token);
+            if Some(token_digest) == admin_token_digest
+                || callers.insert(token_digest, caller_id.clone()).is_some()
+            {
+                return Err(format!("Token of caller {caller_id:?} is not unique"));
+            }
+        }
+
+        Ok(Self {
+            admin_token_digest,
+            callers,
+        })
+    }
+
+    /// Fails with `UNAUTHENTICATED` unless the request carries the admin token, or with
+    /// `PERMISSION_DENIED` when no admin token is configured
+    #[allow(clippy::result_large_err)]
+    pub fn require_admin(&self, metadata: &MetadataMap) -> Result<(), Status> {
+        let Some(expected) = &self.admin_token_digest else {
+            return Err(Status::permission_denied(
+                "Operator RPCs are disabled: no admin token is configured",
+            ));
+        };
+        let token = bearer_token(metadata).ok_or_else(|| {
+            Status::unauthenticated("Operator RPCs require authorization: Bearer <admin token>")
+        })?;
+        if digest(token) != *expected {
+            return Err(Status::unauthenticated("Invalid admin token"));
+        }
+        Ok(())
+    }
+
+    /// Returns the caller id the request's caller token authenticates, failing with
+    /// `UNAUTHENTICATED` if it carries no known caller token
+    #[allow(clippy::result_large_err)]
+    pub fn require_caller(&self, metadata: &MetadataMap) -> Result<String, Status> {
+        let token = bearer_token(metadata).ok_or_else(|| {
+            Status::unauthenticated("This RPC requires authorization: Bearer <caller token>")
+        })?;
+        self.callers
+            .get(&digest(token))
+            .cloned()
+            .ok_or_else(|| Status::unauthenticated("Invalid caller token"))
+    }
+}
+
+impl std::fmt::Debug for Authenticator {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("Authenticator")
+            .field("admin", &self.admin_token_digest.is_some())
+            .field("callers", &self.callers.len())
+            .finish_non_exhaustive()
+    }
+}
+
+/// The token of an `authorization: Bearer <token>` entry, if present
+fn bearer_token(metadata: &MetadataMap) -> Option<&str> {
+    metadata
+        .get(AUTHORIZATION_HEADER)?
+        .to_str()
+        .ok()?
+        .strip_prefix("Bearer ")
+        .map(str::trim)
+        .filter(|token| !token.is_empty())
+}
+
+fn digest(token: &str) -> [u8; 32] {
+    Sha256::digest(token.as_bytes()).into()
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use tonic::Code;
+
+    const ADMIN_TOKEN: &str = "operator-token-0123456789";
+
+    fn metadata(authorization: &str) -> MetadataMap {
+        let mut metadata = MetadataMap::new();
+        metadata.insert(AUTHORIZATION_HEADER, authorization.parse().unwrap());
+        metadata
+    }
+
+    fn authenticator(admin_token: &str) -> Authenticator {
+        Authenticator::from_config(&AuthConfig {
+            admin_token: admin_token.to_string(),
+            ..Default::default()
+        })
+        .unwrap()
+    }
+
+    #[test]
+    fn test_admin_token_is_required() {
+        let auth = authenticator(ADMIN_TOKEN);
+
+        assert!(auth
+            .require_admin(&metadata(&format!("Bearer {ADMIN_TOKEN}")))
+            .is_ok());
+        assert_eq!(
+            auth.require_admin(&MetadataMap::new()).unwrap_err().code(),
+            Code::Unauthenticated
+        );
+        assert_eq!(
+            auth.require_admin(&metadata("Bearer wrong-token"))
+                .unwrap_err()
+                .code(),
+            Code::Unauthenticated
+        );
+        assert_eq!(
+            auth.require_admin(&metadata(ADMIN_TOKEN))
+                .unwrap_err()
+                .code(),
+            Code::Unauthenticated
+        );
+    }
+
+    #[test]
+    fn test_operator_rpcs_are_refused_without_an_admin_token() {
+        let auth = authenticator("");
+        assert_eq!(
+            auth.require_admin(&metadata("Bearer anything"))
+                .unwrap_err()
+                .code(),
+            Code::PermissionDenied
+        );
+        assert!(Authenticator::from_config(&AuthConfig {
+            admin_token: "short".to_string(),
+            ..Default::default()
+        })
+        .is_err());
+    }
+
+    #[test]
+    fn test_caller_id_comes_from_the_caller_token() {
+        let auth = Authenticator::from_config(&AuthConfig {
+            admin_token: ADMIN_TOKEN.to_string(),
+            caller_tokens: [("tenant-a".to_string(), "tenant-a-token-0123456789".to_string())]
+                .into_iter()
+                .collect(),
+        })
+        .unwrap();
+
+        assert_eq!(
+            auth.require_caller(&metadata("Bearer tenant-a-token-0123456789"))
+                .unwrap(),
+            "tenant-a"
+        );
+        assert_eq!(
+            auth.require_caller(&metadata(&format!("Bearer {ADMIN_TOKEN}")))
+                .unwrap_err()
+                .code(),
+            Code::Unauthenticated
+        );
+        assert_eq!(
+            auth.require_caller(&MetadataMap::new()).unwrap_err().code(),
+            Code::Unauthenticated
+        );
+        assert!(Authenticator::from_config(&AuthConfig {
+            admin_token: ADMIN_TOKEN.to_string(),
+            caller_tokens: [("tenant-a".to_string(), ADMIN_TOKEN.to_string())]
+                .into_iter()
+                .collect(),
+        })
+        .is_err());
+    }
+}
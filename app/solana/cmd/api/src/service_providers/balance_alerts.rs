@@ -0,0 +1,221 @@
+use dashmap::DashMap;
+use serde::Serialize;
+use solana_sdk::pubkey::Pubkey;
+use std::collections::BTreeSet;
+use std::str::FromStr;
+use std::time::Duration;
+
+use crate::api::common::amount_parsing::parse_amount;
+use crate::config::BalanceAlertsConfig;
+
+/// Webhook event type sent when a balance drops below its rule's threshold
+pub const BELOW_THRESHOLD_EVENT: &str = "balance.below_threshold";
+/// Webhook event type sent when a balance that was below its threshold recovers
+pub const RECOVERED_EVENT: &str = "balance.recovered";
+
+/// A balance alerted on when it drops below a threshold
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct BalanceRule {
+    /// Unique name identifying the rule in notifications
+    pub name: String,
+    /// Account (SOL) or owning wallet (token) whose balance is checked
+    pub address: Pubkey,
+    /// Mint of a token balance; `None` checks lamports
+    pub mint: Option<Pubkey>,
+    /// Lamports or token base units; balances strictly below it alert
+    pub threshold: u64,
+}
+
+/// A rule's balance crossing its threshold, delivered as the webhook event's data
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+pub struct BalanceCrossing {
+    /// Name of the rule that crossed
+    pub rule: String,
+    /// Base58 address the rule checks
+    pub address: String,
+    /// Base58 mint of a token balance (empty for SOL)
+    pub mint: String,
+    /// Associated token account the token balance was read from (empty for SOL)
+    pub token_account: String,
+    /// Balance read, in lamports or token base units
+    pub balance: String,
+    /// Threshold of the rule
+    pub threshold: String,
+    /// Whether the balance is now below the threshold (false when it recovered)
+    pub below: bool,
+}
+
+impl BalanceCrossing {
+    /// Webhook event type of the crossing
+    pub const fn event_type(&self) -> &'static str {
+        if self.below {
+            BELOW_THRESHOLD_EVENT
+        } else {
+            RECOVERED_EVENT
+        }
+    }
+}
+
+/// Balance threshold rules and whether each balance was last seen below its threshold.
+///
+/// The background check (`api::account::v1::balance_watch`) reads every rule's balance
+/// and reports the crossings `observe` finds, which are delivered through the webhook
+/// sink. A crossing is only `acknowledge`d once delivered, so an undelivered one is
+/// reported again on the next check. Balances already above their threshold when first
+/// read raise nothing.
+pub struct BalanceAlerts {
+    interval: Duration,
+    rules: Vec<BalanceRule>,
+    below: DashMap<String, bool>,
+}
+
+impl BalanceAlerts {
+    /// Builds the rules from configuration, rejecting invalid and duplicate rules
+    pub fn from_config(config: &BalanceAlertsConfig) -> Result<Self, String> {
+        let mut names = BTreeSet::new();
+        let mut rules = Vec::with_capacity(config.rules.len());
+        for rule in &config.rules {
+            if rule.name.is_empty() {
+                return Err("Balance rule name is required".to_string());
+            }
+            if !names.insert(rule.name.as_str()) {
+                return Err(format!("Duplicate balance rule {:?}", rule.name));
+            }
+            let address = Pubkey::from_str(&rule.address)
+                .map_err(|e| format!("Invalid address for balance rule {:?}: {e}", rule.name))?;
+            let mint = (!rule.mint.is_empty())
+                .then(|| Pubkey::from_str(&rule.mint))
+                .transpose()
+                .map_err(|e| format!("Invalid mint for balance rule {:?}: {e}", rule.name))?;
+            let threshold = parse_amount(&rule.below, 0).map_err(|e| {
+                format!("Invalid threshold for balance rule {:?}: {}", rule.name, e.message)
+            })?;
+            rules.push(BalanceRule {
+                name: rule.name.clone(),
+                address,
+                mint,
+                threshold,
+            });
+        }
+
+        Ok(Self {
+            interval: Duration::from_secs(config.poll_interval_seconds),
+            rules,
+            below: DashMap::new(),
+        })
+    }
+
+    /// How often balances are checked (zero disables the check)
+    pub const fn interval(&self) -> Duration {
+        self.interval
+    }
+
+    /// Whether there are rules to check
+    pub fn has_rules(&self) -> bool {
+        !self.rules.is_empty()
+    }
+
+    /// Whether the background check runs
+    pub fn is_enabled(&self) -> bool {
+        !self.interval.is_zero() && self.has_rules()
+    }
+
+    /// The configured rules
+    pub fn rules(&self) -> &[BalanceRule] {
+        &self.rules
+    }
+
+    /// Compares `balance` against `rule`, returning whether it is below the threshold
+    /// when that differs from what was last acknowledged
+    pub fn observe(&self, rule: &BalanceRule, balance: u64) -> Option<bool> {
+        let below = balance < rule.threshold;
+        let previous = self.below.get(&rule.name).map(|state| *state);
+        match previous {
+            Some(previous) if previous == below => None,
+            // Nothing to report for a healthy balance seen for the first time
+            None if !below => {
+                self.acknowledge(rule, false);
+                None
+            }
+            _ => Some(below),
+        }
+    }
+
+    /// Records that a crossing of `rule` was delivered
+    pub fn acknowledge(&self, rule: &BalanceRule, below: bool) {
+        self.below.insert(rule.name.clone(), below);
+    }
+
+    /// Looks up a rule by name
+    pub fn rule(&self, name: &str) -> Option<&BalanceRule> {
+        self.rules.iter().find(|rule| rule.name == name)
+    }
+}
+
+impl std::fmt::Debug for BalanceAlerts {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("BalanceAlerts")
+            .field("interval", &self.interval)
+            .field("rules", &self.rules.len())
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::config::BalanceRuleConfig;
+
+    fn rule(name: &str, mint: &str, below: &str) -> BalanceRuleConfig {
+        BalanceRuleConfig {
+            name: name.to_string(),
+            address: Pubkey::new_unique().to_string(),
+            mint: mint.to_string(),
+            below: below.to_string(),
+        }
+    }
+
+    fn alerts(rules: Vec<BalanceRuleConfig>) -> Result<BalanceAlerts, String> {
+        BalanceAlerts::from_config(&BalanceAlertsConfig {
+            poll_interval_seconds: 60,
+            rules,
+        })
+    }
+
+    #[test]
+    fn test_reports_crossings_until_acknowledged() {
+        let alerts = alerts(vec![rule("treasury", "", "1000000000")]).unwrap();
+        let treasury = alerts.rule("treasury").unwrap().clone();
+        assert_eq!(treasury.mint, None);
+
+        // Healthy on first sight: nothing to report
+        assert_eq!(alerts.observe(&treasury, 5_000_000_000), None);
+        assert_eq!(alerts.observe(&treasury, 999_999_999), Some(true));
+        // Not yet delivered, so reported again
+        assert_eq!(alerts.observe(&treasury, 500_000_000), Some(true));
+        alerts.acknowledge(&treasury, true);
+        assert_eq!(alerts.observe(&treasury, 1), None);
+        assert_eq!(alerts.observe(&treasury, 1_000_000_000), Some(false));
+    }
+
+    #[test]
+    fn test_low_balance_on_first_sight_is_reported() {
+        let mint = Pubkey::new_unique().to_string();
+        let alerts = alerts(vec![rule("usdc-float", &mint, "25000000")]).unwrap();
+        let float = alerts.rule("usdc-float").unwrap().clone();
+        assert_eq!(float.mint.unwrap().to_string(), mint);
+
+        assert_eq!(alerts.observe(&float, 0), Some(true));
+    }
+
+    #[test]
+    fn test_rejects_invalid_rules() {
+        assert!(alerts(vec![rule("", "", "1")]).is_err());
+        assert!(alerts(vec![rule("a", "", "1"), rule("a", "", "2")]).is_err());
+        assert!(alerts(vec![rule("a", "not-a-mint", "1")]).is_err());
+        assert!(alerts(vec![rule("a", "", "1.5")]).is_err());
+        assert!(alerts(vec![rule("a", "", "-1")]).is_err());
+        assert!(!alerts(Vec::new()).unwrap().is_enabled());
+    }
+}
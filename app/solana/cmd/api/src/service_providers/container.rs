@@ -1,7 +1,35 @@
 use anyhow::Result;
 use std::sync::Arc;
+use std::time::Duration;
 
+use super::admission::AdmissionController;
+use super::ata_watcher::AtaWatcher;
+use super::auth::Authenticator;
+use super::balance_alerts::BalanceAlerts;
+use super::dead_letters::{DeadLetterStore, DEFAULT_MAX_DEAD_LETTERS};
+use super::event_export::EventExporter;
+use super::feature_flags::FeatureFlags;
+use super::hardware_wallet::HardwareWalletAgent;
+use super::idempotency::{IdempotencyCache, DEFAULT_MAX_IDEMPOTENCY_KEYS};
+use super::jito::JitoBlockEngine;
+use super::key_vault::KeyVault;
+use super::keystore::Keystore;
+use super::kms::KmsSigner;
+use super::localization::MessageCatalog;
+use super::operations::{OperationStore, DEFAULT_MAX_OPERATIONS};
+use super::rebroadcasts::RebroadcastTracker;
+use super::retention::{RetainedStore, StoreCollector};
+use super::rpc_limits::RpcLimiter;
+use super::simulation_cache::SimulationCache;
 use super::solana_clients::SolanaClientsServiceProviders;
+use super::sponsorship::SponsorPool;
+use super::submission_tokens::SubmissionTokenStore;
+use super::submissions::{SubmissionLog, DEFAULT_MAX_SUBMISSIONS};
+use super::templates::TemplateStore;
+use super::token_metadata::TokenMetadataFetcher;
+use super::transaction_queue::TransactionQueue;
+use super::vault::VaultSigner;
+use super::webhooks::WebhookSink;
 use crate::config::Config;
 use crate::websocket::{derive_websocket_url_from_rpc, WebSocketManager};
 
@@ -11,6 +39,60 @@ pub struct ServiceProviders {
     pub solana_clients: Arc<SolanaClientsServiceProviders>,
     /// WebSocket manager for real-time monitoring
     pub websocket_manager: Arc<WebSocketManager>,
+    /// Feature flags guarding risky pathways
+    pub feature_flags: Arc<FeatureFlags>,
+    /// Credentials required by operator-only RPCs
+    pub auth: Arc<Authenticator>,
+    /// Managed submissions that exhausted their retries
+    pub dead_letters: Arc<DeadLetterStore>,
+    /// Server-held signing keys
+    pub key_vault: Arc<KeyVault>,
+    /// Recorded results of idempotent submissions
+    pub idempotency: Arc<IdempotencyCache>,
+    /// Progress of rebroadcast loops started by submissions
+    pub rebroadcasts: Arc<RebroadcastTracker>,
+    /// Recent submissions and their tags
+    pub submissions: Arc<SubmissionLog>,
+    /// Buffered export of submission events to analytics sinks
+    pub event_export: Arc<EventExporter>,
+    /// Single-use tokens minted for untrusted submitters
+    pub submission_tokens: Arc<SubmissionTokenStore>,
+    /// Sponsored fee payer pool and per-caller budgets
+    pub sponsorship: Arc<SponsorPool>,
+    /// Saved transaction templates
+    pub templates: Arc<TemplateStore>,
+    /// Long-running orchestrations
+    pub operations: Arc<OperationStore>,
+    /// Outbound webhook notifications
+    pub webhooks: Arc<WebhookSink>,
+    /// Balance threshold rules notified through `webhooks`
+    pub balance_alerts: Arc<BalanceAlerts>,
+    /// Jito block engine for bundle submission
+    pub jito: Arc<JitoBlockEngine>,
+    /// Concurrency limits on outbound Solana RPC calls
+    pub rpc_limiter: Arc<RpcLimiter>,
+    /// Queueing of `SubmitTransaction` bursts
+    pub admission: Arc<AdmissionController>,
+    /// Signed transactions awaiting server-side dispatch
+    pub transaction_queue: Arc<TransactionQueue>,
+    /// Periodic purging of expired records from the stores above
+    pub retention: Arc<StoreCollector>,
+    /// Companion agent of locally connected Ledger devices
+    pub hardware_wallet: Arc<HardwareWalletAgent>,
+    /// Keys held in cloud KMS and their access policies
+    pub kms: Arc<KmsSigner>,
+    /// Transit keys held in HashiCorp Vault
+    pub vault: Arc<VaultSigner>,
+    /// Generated keys encrypted at rest
+    pub keystore: Arc<Keystore>,
+    /// Owners whose missing associated token accounts are created on inbound transfers
+    pub ata_watcher: Arc<AtaWatcher>,
+    /// Off-chain token metadata documents and their cache
+    pub token_metadata: Arc<TokenMetadataFetcher>,
+    /// Recent simulation results for callers that opt in to reuse
+    pub simulation_cache: Arc<SimulationCache>,
+    /// Human-readable messages in every configured locale
+    pub message_catalog: Arc<MessageCatalog>,
     config: Config, // Store config for network info and other services
 }
 
@@ -22,7 +104,7 @@ impl ServiceProviders {
             config.solana.rpc_url
         );
 
-        let solana_clients = Arc::new(SolanaClientsServiceProviders::new(&config.solana.rpc_url));
+        let solana_clients = Arc::new(SolanaClientsServiceProviders::new(&config.solana));
 
         // Derive WebSocket URL and create WebSocket manager
         let ws_url = derive_websocket_url_from_rpc(&config.solana.rpc_url)
@@ -38,13 +120,193 @@ impl ServiceProviders {
                 .map_err(|e| anyhow::anyhow!("Failed to create WebSocket manager: {}", e))?,
         );
 
+        let feature_flags = Arc::new(
+            FeatureFlags::from_config(&config.feature_flags)
+                .map_err(|e| anyhow::anyhow!("Invalid feature flag configuration: {}", e))?,
+        );
+
+        let auth = Arc::new(
+            Authenticator::from_config(&config.auth)
+                .map_err(|e| anyhow::anyhow!("Invalid auth configuration: {}", e))?,
+        );
+
+        let event_export = Arc::new(
+            EventExporter::from_config(&config.event_export)
+                .map_err(|e| anyhow::anyhow!("Invalid event export configuration: {}", e))?,
+        );
+
+        let webhooks = Arc::new(
+            WebhookSink::from_config(&config.webhooks)
+                .map_err(|e| anyhow::anyhow!("Invalid webhook configuration: {}", e))?,
+        );
+
+        let balance_alerts = Arc::new(
+            BalanceAlerts::from_config(&config.balance_alerts)
+                .map_err(|e| anyhow::anyhow!("Invalid balance alert configuration: {}", e))?,
+        );
+        if balance_alerts.has_rules() && !webhooks.is_configured() {
+            return Err(anyhow::anyhow!(
+                "Invalid balance alert configuration: rules require a webhook URL"
+            ));
+        }
+
+        let jito = Arc::new(
+            JitoBlockEngine::from_config(&config.jito)
+                .map_err(|e| anyhow::anyhow!("Invalid Jito configuration: {}", e))?,
+        );
+
+        let rpc_limiter = Arc::new(
+            RpcLimiter::from_config(&config.rpc_limits)
+                .map_err(|e| anyhow::anyhow!("Invalid RPC limits configuration: {}", e))?,
+        );
+
+        let admission = Arc::new(
+            AdmissionController::from_config(&config.admission)
+                .map_err(|e| anyhow::anyhow!("Invalid admission configuration: {}", e))?,
+        );
+
+        let key_vault = Arc::new(KeyVault::new());
+        for (name, phrase) in &config.key_vault.mnemonics {
+            key_vault
+                .store_mnemonic(name, phrase)
+                .map_err(|e| anyhow::anyhow!("Invalid key vault configuration: {}", e))?;
+        }
+
+        let kms = Arc::new(
+            KmsSigner::from_config(&config.kms)
+                .map_err(|e| anyhow::anyhow!("Invalid KMS configuration: {}", e))?,
+        );
+
+        let keystore = Arc::new(
+            Keystore::from_config(&config.keystore, &kms)
+                .await
+                .map_err(|e| anyhow::anyhow!("Invalid keystore configuration: {}", e))?,
+        );
+
+        let vault = Arc::new(
+            VaultSigner::from_config(&config.vault)
+                .map_err(|e| anyhow::anyhow!("Invalid Vault configuration: {}", e))?,
+        );
+
+        let ata_watcher = Arc::new(
+            AtaWatcher::from_config(&config.ata_watcher)
+                .map_err(|e| anyhow::anyhow!("Invalid ATA watcher configuration: {}", e))?,
+        );
+
+        let token_metadata = Arc::new(
+            TokenMetadataFetcher::from_config(&config.token_metadata)
+                .map_err(|e| anyhow::anyhow!("Invalid token metadata configuration: {}", e))?,
+        );
+
+        let simulation_cache = Arc::new(
+            SimulationCache::from_config(&config.simulation_cache)
+                .map_err(|e| anyhow::anyhow!("Invalid simulation cache configuration: {}", e))?,
+        );
+
+        let message_catalog = Arc::new(
+            MessageCatalog::from_config(&config.localization)
+                .map_err(|e| anyhow::anyhow!("Invalid localization configuration: {}", e))?,
+        );
+
+        let transaction_queue = Arc::new(
+            TransactionQueue::from_config(&config.queue)
+                .map_err(|e| anyhow::anyhow!("Invalid queue configuration: {}", e))?,
+        );
+
+        let retention_config = &config.retention;
+        let dead_letters = Arc::new(DeadLetterStore::new(
+            retention_config.dead_letter_ttl_seconds,
+            DEFAULT_MAX_DEAD_LETTERS,
+        ));
+        let idempotency = Arc::new(IdempotencyCache::new(
+            retention_config.idempotency_ttl_seconds,
+            DEFAULT_MAX_IDEMPOTENCY_KEYS,
+        ));
+        let rebroadcasts = Arc::new(RebroadcastTracker::default());
+        let submissions = Arc::new(
+            SubmissionLog::new(retention_config.submission_ttl_seconds, DEFAULT_MAX_SUBMISSIONS)
+                .with_export(Arc::clone(&event_export)),
+        );
+        let submission_tokens = Arc::new(SubmissionTokenStore::default());
+        let operations = Arc::new(OperationStore::new(
+            retention_config.operation_retention_seconds,
+            DEFAULT_MAX_OPERATIONS,
+        ));
+        let retained: Vec<Arc<dyn RetainedStore>> = vec![
+            submissions.clone(),
+            idempotency.clone(),
+            dead_letters.clone(),
+            operations.clone(),
+            rebroadcasts.clone(),
+            submission_tokens.clone(),
+            transaction_queue.clone(),
+            simulation_cache.clone(),
+        ];
+        let retention = Arc::new(StoreCollector::new(
+            Duration::from_secs(retention_config.gc_interval_seconds),
+            retained,
+        ));
+
         Ok(Self {
             solana_clients,
             websocket_manager,
+            feature_flags,
+            auth,
+            dead_letters,
+            key_vault,
+            idempotency,
+            rebroadcasts,
+            submissions,
+            event_export,
+            submission_tokens,
+            sponsorship: Arc::new(SponsorPool::from_config(&config.sponsorship)),
+            templates: Arc::new(TemplateStore::default()),
+            operations,
+            webhooks,
+            balance_alerts,
+            jito,
+            rpc_limiter,
+            admission,
+            transaction_queue,
+            retention,
+            hardware_wallet: Arc::new(HardwareWalletAgent::from_config(&config.hardware_wallet)),
+            kms,
+            vault,
+            keystore,
+            ata_watcher,
+            token_metadata,
+            simulation_cache,
+            message_catalog,
             config,
         })
     }
 
+    /// Returns the key reference of the funding treasury (empty if none is configured)
+    pub fn treasury_key_ref(&self) -> &str {
+        &self.config.funding.treasury_key_ref
+    }
+
+    /// Returns whether `FundNative` refuses the deprecated string amount
+    pub const fn funding_rejects_string_amounts(&self) -> bool {
+        self.config.funding.reject_string_amounts
+    }
+
+    /// Returns the local validator account directory snapshots are imported into (empty if
+    /// imports are disabled)
+    pub fn account_snapshot_import_directory(&self) -> &str {
+        &self.config.account_snapshots.import_directory
+    }
+
+    /// Returns whether every submission is forced to be a dry run
+    pub const fn submission_dry_run(&self) -> bool {
+        self.config.submission.dry_run
+    }
+
+    /// Returns whether every submission must present a submission token
+    pub const fn submission_require_token(&self) -> bool {
+        self.config.submission.require_token
+    }
+
     /// Returns network information string for logging/debugging
     pub fn get_network_info(&self) -> String {
         self.config.solana.rpc_url.clone()
@@ -0,0 +1,254 @@
+use dashmap::DashMap;
+use std::collections::HashMap;
+
+use protochain_api::protochain::solana::admin::v1::DeadLetter;
+use protochain_api::protochain::solana::transaction::v1::{
+    RetryPolicy, SubmissionAttempt, Transaction,
+};
+
+use super::unix_timestamp;
+
+/// Default number of dead letters retained before the oldest are evicted
+pub const DEFAULT_MAX_DEAD_LETTERS: usize = 1_000;
+/// Default time a dead letter is kept for re-queueing after its last attempt
+pub const DEFAULT_DEAD_LETTER_TTL_SECONDS: i64 = 604_800;
+
+/// In-memory store of managed submissions that failed after exhausting their retries.
+///
+/// Entries keep the fully signed transaction so operators can re-queue them once the
+/// underlying issue is fixed. The store is bounded; the oldest entry is evicted when full,
+/// and entries untouched for longer than the TTL are purged.
+pub struct DeadLetterStore {
+    entries: DashMap<String, DeadLetter>,
+    ttl_seconds: i64,
+    max_entries: usize,
+}
+
+impl DeadLetterStore {
+    /// Creates an empty store whose entries live `ttl_seconds` after their last attempt
+    /// (0 keeps them until evicted), holding at most `max_entries` dead letters
+    pub fn new(ttl_seconds: i64, max_entries: usize) -> Self {
+        Self {
+            entries: DashMap::new(),
+            ttl_seconds,
+            max_entries: max_entries.max(1),
+        }
+    }
+
+    /// Records a failed managed submission and returns its dead-letter id
+    pub fn insert(
+        &self,
+        transaction: Transaction,
+        commitment_level: i32,
+        retry_policy: Option<RetryPolicy>,
+        attempts: Vec<SubmissionAttempt>,
+        tags: HashMap<String, String>,
+    ) -> String {
+        if self.entries.len() >= self.max_entries {
+            self.evict_oldest();
+        }
+
+        let id = uuid::Uuid::new_v4().to_string();
+        let now = unix_timestamp();
+        self.entries.insert(
+            id.clone(),
+            DeadLetter {
+                id: id.clone(),
+                transaction: Some(transaction),
+                commitment_level,
+                retry_policy,
+                attempts,
+                created_at: now,
+                updated_at: now,
+                requeue_count: 0,
+                tags,
+            },
+        );
+        id
+    }
+
+    /// Returns a dead letter by id
+    pub fn get(&self, id: &str) -> Option<DeadLetter> {
+        self.entries.get(id).map(|entry| entry.clone())
+    }
+
+    /// Lists dead letters newest first, optionally filtered by fee payer
+    pub fn list(&self, fee_payer: Option<&str>) -> Vec<DeadLetter> {
+        let mut dead_letters: Vec<DeadLetter> = self
+            .entries
+            .iter()
+            .filter(|entry| {
+                fee_payer.map_or(true, |fee_payer| {
+                    entry
+                        .transaction
+                        .as_ref()
+                        .is_some_and(|transaction| transaction.fee_payer == fee_payer)
+                })
+            })
+            .map(|entry| entry.clone())
+            .collect();
+        dead_letters.sort_by(|a, b| b.created_at.cmp(&a.created_at).then(a.id.cmp(&b.id)));
+        dead_letters
+    }
+
+    /// Removes a dead letter, returning it if present
+    pub fn remove(&self, id: &str) -> Option<DeadLetter> {
+        self.entries.remove(id).map(|(_, dead_letter)| dead_letter)
+    }
+
+    /// Appends the attempts of a failed re-queue to an existing dead letter
+    pub fn record_failed_requeue(
+        &self,
+        id: &str,
+        attempts: Vec<SubmissionAttempt>,
+    ) -> Option<DeadLetter> {
+        let mut entry = self.entries.get_mut(id)?;
+        let offset = u32::try_from(entry.attempts.len()).unwrap_or(u32::MAX);
+        entry
+            .attempts
+            .extend(attempts.into_iter().map(|mut attempt| {
+                attempt.attempt = attempt.attempt.saturating_add(offset);
+                attempt
+            }));
+        entry.requeue_count += 1;
+        entry.updated_at = unix_timestamp();
+        Some(entry.clone())
+    }
+
+    /// Number of dead letters currently held
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Whether the store is empty
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Drops dead letters untouched for longer than the TTL, returning how many were dropped
+    pub fn purge_expired(&self) -> usize {
+        if self.ttl_seconds <= 0 {
+            return 0;
+        }
+        let cutoff = unix_timestamp() - self.ttl_seconds;
+        let before = self.entries.len();
+        self.entries
+            .retain(|_, dead_letter| dead_letter.updated_at > cutoff);
+        before.saturating_sub(self.entries.len())
+    }
+
+    fn evict_oldest(&self) {
+        let oldest = self
+            .entries
+            .iter()
+            .min_by_key(|entry| entry.created_at)
+            .map(|entry| entry.key().clone());
+        if let Some(id) = oldest {
+            self.entries.remove(&id);
+        }
+    }
+}
+
+impl Default for DeadLetterStore {
+    fn default() -> Self {
+        Self::new(DEFAULT_DEAD_LETTER_TTL_SECONDS, DEFAULT_MAX_DEAD_LETTERS)
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use protochain_api::protochain::solana::transaction::v1::SubmissionResult;
+
+    fn transaction(fee_payer: &str) -> Transaction {
+        Transaction {
+            fee_payer: fee_payer.to_string(),
+            ..Default::default()
+        }
+    }
+
+    fn failed_attempt(attempt: u32) -> SubmissionAttempt {
+        SubmissionAttempt {
+            attempt,
+            submission_result: SubmissionResult::FailedInsufficientFunds.into(),
+            structured_error: None,
+            attempted_at: unix_timestamp(),
+        }
+    }
+
+    #[test]
+    fn test_insert_and_get() {
+        let store = DeadLetterStore::default();
+        let id = store.insert(
+            transaction("payer"),
+            0,
+            None,
+            vec![failed_attempt(1), failed_attempt(2)],
+            HashMap::new(),
+        );
+
+        let dead_letter = store.get(&id).unwrap();
+        assert_eq!(dead_letter.id, id);
+        assert_eq!(dead_letter.attempts.len(), 2);
+        assert_eq!(dead_letter.requeue_count, 0);
+        assert_eq!(store.len(), 1);
+    }
+
+    #[test]
+    fn test_list_filters_by_fee_payer() {
+        let store = DeadLetterStore::default();
+        store.insert(transaction("alice"), 0, None, vec![failed_attempt(1)], HashMap::new());
+        store.insert(transaction("bob"), 0, None, vec![failed_attempt(1)], HashMap::new());
+
+        assert_eq!(store.list(None).len(), 2);
+        let alice = store.list(Some("alice"));
+        assert_eq!(alice.len(), 1);
+        assert_eq!(alice[0].transaction.as_ref().unwrap().fee_payer, "alice");
+    }
+
+    #[test]
+    fn test_failed_requeue_appends_history() {
+        let store = DeadLetterStore::default();
+        let id =
+            store.insert(transaction("payer"), 0, None, vec![failed_attempt(1)], HashMap::new());
+
+        let updated = store
+            .record_failed_requeue(&id, vec![failed_attempt(1), failed_attempt(2)])
+            .unwrap();
+        let numbers: Vec<u32> = updated.attempts.iter().map(|a| a.attempt).collect();
+        assert_eq!(numbers, vec![1, 2, 3]);
+        assert_eq!(updated.requeue_count, 1);
+        assert!(store.record_failed_requeue("missing", vec![]).is_none());
+    }
+
+    #[test]
+    fn test_store_is_bounded() {
+        let store = DeadLetterStore::new(DEFAULT_DEAD_LETTER_TTL_SECONDS, 2);
+        for _ in 0..3 {
+            store.insert(transaction("payer"), 0, None, vec![failed_attempt(1)], HashMap::new());
+        }
+        assert_eq!(store.len(), 2);
+    }
+
+    #[test]
+    fn test_purge_drops_stale_dead_letters() {
+        let store = DeadLetterStore::default();
+        let stale = store.insert(transaction("payer"), 0, None, vec![], HashMap::new());
+        let fresh = store.insert(transaction("payer"), 0, None, vec![], HashMap::new());
+        store.entries.get_mut(&stale).unwrap().updated_at = 1;
+
+        assert_eq!(store.purge_expired(), 1);
+        assert!(store.get(&stale).is_none());
+        assert!(store.get(&fresh).is_some());
+    }
+
+    #[test]
+    fn test_remove() {
+        let store = DeadLetterStore::default();
+        let id = store.insert(transaction("payer"), 0, None, vec![], HashMap::new());
+        assert!(store.remove(&id).is_some());
+        assert!(store.get(&id).is_none());
+        assert!(store.is_empty());
+    }
+}
@@ -0,0 +1,312 @@
+use serde::Deserialize;
+use serde_json::{json, Map, Value as Json};
+use std::sync::atomic::{AtomicBool, Ordering};
+
+use super::schema::{Column, ColumnType, ExportEvent, Value, COLUMNS};
+use crate::config::BigQuerySinkConfig;
+use crate::service_providers::gcp_auth::access_token;
+
+/// BigQuery API endpoint used unless overridden
+const DEFAULT_ENDPOINT: &str = "https://bigquery.googleapis.com";
+
+#[derive(Deserialize)]
+struct Table {
+    #[serde(default)]
+    schema: TableSchema,
+}
+
+#[derive(Deserialize, Default)]
+struct TableSchema {
+    #[serde(default)]
+    fields: Vec<Json>,
+}
+
+#[derive(Deserialize)]
+struct InsertAllResponse {
+    #[serde(default, rename = "insertErrors")]
+    insert_errors: Vec<Json>,
+}
+
+/// Streams events into a BigQuery table with `tabledata.insertAll`.
+///
+/// Before the first insert the table is created with the export schema, partitioned by
+/// day on `occurred_at`, or, if it exists, checked against the schema and extended with
+/// any columns it lacks. Each row's `insertId` is its event id, so BigQuery drops rows a
+/// retried insert repeats.
+pub struct BigQuerySink {
+    endpoint: String,
+    project: String,
+    dataset: String,
+    table: String,
+    http: reqwest::Client,
+    schema_ready: AtomicBool,
+}
+
+impl BigQuerySink {
+    /// Builds the sink from configuration, rejecting incomplete table references
+    pub fn from_config(config: &BigQuerySinkConfig, http: reqwest::Client) -> Result<Self, String> {
+        if config.dataset.is_empty() || config.table.is_empty() {
+            return Err("BigQuery export requires a dataset and a table".to_string());
+        }
+        Ok(Self {
+            endpoint: if config.endpoint.is_empty() {
+                DEFAULT_ENDPOINT.to_string()
+            } else {
+                config.endpoint.trim_end_matches('/').to_string()
+            },
+            project: config.project.clone(),
+            dataset: config.dataset.clone(),
+            table: config.table.clone(),
+            http,
+            schema_ready: AtomicBool::new(false),
+        })
+    }
+
+    /// Inserts `events`, preparing the table first if that has not succeeded yet
+    pub async fn write(&self, events: &[ExportEvent]) -> Result<String, String> {
+        let token = access_token(&self.http).await?;
+        if !self.schema_ready.load(Ordering::Acquire) {
+            self.ensure_schema(&token).await?;
+            self.schema_ready.store(true, Ordering::Release);
+        }
+
+        let rows: Vec<Json> = events
+            .iter()
+            .map(|event| json!({ "insertId": event.event_id, "json": row_json(event) }))
+            .collect();
+        let response: InsertAllResponse = self
+            .call(
+                self.http
+                    .post(format!("{}/insertAll", self.table_url()))
+                    .bearer_auth(&token)
+                    .json(&json!({ "rows": rows })),
+                "BigQuery insertAll",
+            )
+            .await?
+            .ok_or_else(|| "BigQuery table not found".to_string())?;
+        if let Some(error) = response.insert_errors.first() {
+            // A rejected row may have left the schema stale; check it again next time
+            self.schema_ready.store(false, Ordering::Release);
+            return Err(format!(
+                "BigQuery rejected {} of {} rows, first: {error}",
+                response.insert_errors.len(),
+                events.len()
+            ));
+        }
+        Ok(format!("{}.{}.{}", self.project, self.dataset, self.table))
+    }
+
+    /// Creates the table, or appends the schema's columns it lacks
+    async fn ensure_schema(&self, token: &str) -> Result<(), String> {
+        let existing: Option<Table> = self
+            .call(self.http.get(self.table_url()).bearer_auth(token), "BigQuery tables.get")
+            .await?;
+
+        let Some(existing) = existing else {
+            let tables_url = format!(
+                "{}/bigquery/v2/projects/{}/datasets/{}/tables",
+                self.endpoint, self.project, self.dataset
+            );
+            let body = json!({
+                "tableReference": {
+                    "projectId": self.project,
+                    "datasetId": self.dataset,
+                    "tableId": self.table,
+                },
+                "schema": { "fields": COLUMNS.iter().map(field).collect::<Vec<_>>() },
+                "timePartitioning": { "type": "DAY", "field": "occurred_at" },
+            });
+            self.call::<Json>(
+                self.http.post(tables_url).bearer_auth(token).json(&body),
+                "BigQuery tables.insert",
+            )
+            .await?;
+            return Ok(());
+        };
+
+        let missing = missing_columns(&existing.schema.fields)?;
+        if missing.is_empty() {
+            return Ok(());
+        }
+        let mut fields = existing.schema.fields;
+        fields.extend(missing.into_iter().map(|column| {
+            // BigQuery only lets nullable columns be added to an existing table
+            let mut added = field(column);
+            added["mode"] = json!("NULLABLE");
+            added
+        }));
+        self.call::<Json>(
+            self.http
+                .patch(self.table_url())
+                .bearer_auth(token)
+                .json(&json!({ "schema": { "fields": fields } })),
+            "BigQuery tables.patch",
+        )
+        .await?;
+        Ok(())
+    }
+
+    fn table_url(&self) -> String {
+        format!(
+            "{}/bigquery/v2/projects/{}/datasets/{}/tables/{}",
+            self.endpoint, self.project, self.dataset, self.table
+        )
+    }
+
+    /// Sends a BigQuery request, returning `None` for 404 and surfacing the error body
+    /// of other failures
+    async fn call<T: serde::de::DeserializeOwned>(
+        &self,
+        request: reqwest::RequestBuilder,
+        call: &str,
+    ) -> Result<Option<T>, String> {
+        let response = request
+            .send()
+            .await
+            .map_err(|e| format!("{call} failed: {e}"))?;
+        let status = response.status();
+        if status == reqwest::StatusCode::NOT_FOUND {
+            return Ok(None);
+        }
+        let body = response
+            .text()
+            .await
+            .map_err(|e| format!("{call} failed: {e}"))?;
+        if !status.is_success() {
+            return Err(format!("{call} failed with {status}: {body}"));
+        }
+        serde_json::from_str(&body)
+            .map(Some)
+            .map_err(|e| format!("{call} returned an invalid response: {e}"))
+    }
+}
+
+impl std::fmt::Debug for BigQuerySink {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("BigQuerySink")
+            .field("project", &self.project)
+            .field("dataset", &self.dataset)
+            .field("table", &self.table)
+            .finish_non_exhaustive()
+    }
+}
+
+/// BigQuery type of a column
+const fn bigquery_type(column_type: ColumnType) -> &'static str {
+    match column_type {
+        ColumnType::String => "STRING",
+        ColumnType::Int64 => "INTEGER",
+        ColumnType::Timestamp => "TIMESTAMP",
+        ColumnType::Json => "JSON",
+    }
+}
+
+/// BigQuery field definition of a column
+fn field(column: &Column) -> Json {
+    json!({
+        "name": column.name,
+        "type": bigquery_type(column.column_type),
+        "mode": if column.required { "REQUIRED" } else { "NULLABLE" },
+        "description": column.description,
+    })
+}
+
+/// Columns of the export schema missing from a table's `fields`. Fails when a column
+/// exists with another type, which only a new table can fix.
+fn missing_columns(fields: &[Json]) -> Result<Vec<&'static Column>, String> {
+    let mut missing = Vec::new();
+    for column in COLUMNS {
+        let existing = fields
+            .iter()
+            .find(|field| field["name"].as_str() == Some(column.name));
+        match existing {
+            None => missing.push(column),
+            Some(existing) => {
+                let expected = bigquery_type(column.column_type);
+                let actual = existing["type"].as_str().unwrap_or_default();
+                // The API reports INTEGER as INT64 for tables created through SQL
+                let matches = actual == expected || (expected == "INTEGER" && actual == "INT64");
+                if !matches {
+                    return Err(format!(
+                        "BigQuery column {} is {actual}, expected {expected}",
+                        column.name
+                    ));
+                }
+            }
+        }
+    }
+    Ok(missing)
+}
+
+/// The event as an `insertAll` row; timestamps are sent as seconds since the epoch
+fn row_json(event: &ExportEvent) -> Json {
+    let row: Map<String, Json> = COLUMNS
+        .iter()
+        .zip(event.row())
+        .filter_map(|(column, value)| {
+            let value = match value {
+                Value::String(value) => json!(value),
+                Value::Int64(value) => json!(value),
+                Value::Null => return None,
+            };
+            Some((column.name.to_string(), value))
+        })
+        .collect();
+    Json::Object(row)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use std::collections::BTreeMap;
+
+    #[test]
+    fn test_missing_columns_are_found_and_type_changes_rejected() {
+        let mut fields: Vec<Json> = COLUMNS.iter().map(field).collect();
+        assert!(missing_columns(&fields).unwrap().is_empty());
+
+        let tags = fields.pop().unwrap();
+        let missing = missing_columns(&fields).unwrap();
+        assert_eq!(missing.len(), 1);
+        assert_eq!(missing[0].name, "tags");
+
+        fields.push(json!({ "name": "tags", "type": "STRING" }));
+        assert!(missing_columns(&fields).is_err());
+        fields.pop();
+        fields.push(tags);
+        fields[1]["type"] = json!("INT64");
+        assert!(missing_columns(&fields).unwrap().is_empty());
+    }
+
+    #[test]
+    fn test_rows_omit_null_cells() {
+        let event = ExportEvent {
+            event_id: "e1".to_string(),
+            event_type: "submission",
+            occurred_at: 1_700_000_000,
+            signature: "5sig".to_string(),
+            fee_payer: "payer".to_string(),
+            submission_result: "SUBMISSION_RESULT_SUBMITTED".to_string(),
+            commitment_level: "COMMITMENT_LEVEL_CONFIRMED".to_string(),
+            attempt_count: 1,
+            dead_letter_id: None,
+            tags: BTreeMap::new(),
+        };
+        let row = row_json(&event);
+
+        assert_eq!(row["event_id"], json!("e1"));
+        assert_eq!(row["occurred_at"], json!(1_700_000_000));
+        assert_eq!(row["tags"], json!("{}"));
+        assert!(row.get("dead_letter_id").is_none());
+    }
+
+    #[test]
+    fn test_requires_a_table_reference() {
+        let config = BigQuerySinkConfig {
+            project: "analytics".to_string(),
+            ..Default::default()
+        };
+        assert!(BigQuerySink::from_config(&config, reqwest::Client::new()).is_err());
+    }
+}
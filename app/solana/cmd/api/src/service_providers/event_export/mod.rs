@@ -0,0 +1,291 @@
+//! Export of submission events to columnar analytics sinks
+//!
+//! Events are buffered in memory and flushed periodically to Parquet files (Cloud Storage
+//! or a local directory) and BigQuery streaming inserts, all sharing the versioned schema
+//! in `schema`.
+
+/// BigQuery streaming insert sink with table schema management
+pub mod bigquery;
+/// Parquet file sink
+pub mod parquet_file;
+/// Versioned column schema shared by every sink
+pub mod schema;
+
+use std::collections::VecDeque;
+use std::sync::Mutex;
+use std::time::Duration;
+use tracing::{debug, warn};
+
+use self::bigquery::BigQuerySink;
+use self::parquet_file::ParquetSink;
+use self::schema::ExportEvent;
+use crate::config::EventExportConfig;
+
+/// How long a single upload or insert may take
+const EXPORT_TIMEOUT_SECONDS: u64 = 60;
+
+/// A columnar sink events are flushed to
+#[derive(Debug)]
+enum Sink {
+    Parquet(ParquetSink),
+    BigQuery(BigQuerySink),
+}
+
+impl Sink {
+    const fn name(&self) -> &'static str {
+        match self {
+            Self::Parquet(_) => "parquet",
+            Self::BigQuery(_) => "bigquery",
+        }
+    }
+
+    async fn write(&self, events: &[ExportEvent]) -> Result<String, String> {
+        match self {
+            Self::Parquet(sink) => sink.write(events).await,
+            Self::BigQuery(sink) => sink.write(events).await,
+        }
+    }
+}
+
+/// A sink and the events waiting to be written to it
+#[derive(Debug)]
+struct Export {
+    sink: Sink,
+    backlog: Mutex<VecDeque<ExportEvent>>,
+}
+
+/// Buffers events and flushes them to the configured sinks.
+///
+/// Every sink keeps its own backlog, so one failing sink neither blocks nor duplicates
+/// writes to the others. A failed batch is put back at the front of its backlog and
+/// retried on the next flush; a backlog that grows past `max_buffered_events` drops its
+/// oldest events. The export is best-effort analytics, not a durable ledger: buffered
+/// events are lost on restart.
+pub struct EventExporter {
+    interval: Duration,
+    max_buffered_events: usize,
+    max_batch_events: usize,
+    exports: Vec<Export>,
+}
+
+impl EventExporter {
+    /// Builds the sinks from configuration, rejecting invalid destinations and tables
+    pub fn from_config(config: &EventExportConfig) -> Result<Self, String> {
+        if config.max_buffered_events == 0 || config.max_batch_events == 0 {
+            return Err("max_buffered_events and max_batch_events must be at least 1".to_string());
+        }
+        let http = reqwest::Client::builder()
+            .timeout(Duration::from_secs(EXPORT_TIMEOUT_SECONDS))
+            .build()
+            .map_err(|e| format!("Failed to build event export HTTP client: {e}"))?;
+
+        let mut sinks = Vec::new();
+        if !config.parquet.destination.is_empty() {
+            sinks.push(Sink::Parquet(ParquetSink::new(&config.parquet.destination, http.clone())?));
+        }
+        if !config.bigquery.project.is_empty() {
+            sinks.push(Sink::BigQuery(BigQuerySink::from_config(&config.bigquery, http)?));
+        }
+
+        Ok(Self {
+            interval: Duration::from_secs(config.flush_interval_seconds),
+            max_buffered_events: config.max_buffered_events,
+            max_batch_events: config.max_batch_events,
+            exports: sinks
+                .into_iter()
+                .map(|sink| Export {
+                    sink,
+                    backlog: Mutex::new(VecDeque::new()),
+                })
+                .collect(),
+        })
+    }
+
+    /// How often events are flushed (zero disables the export)
+    pub const fn interval(&self) -> Duration {
+        self.interval
+    }
+
+    /// Whether events are buffered and flushed
+    pub fn is_enabled(&self) -> bool {
+        !self.interval.is_zero() && !self.exports.is_empty()
+    }
+
+    /// Queues an event for every sink
+    pub fn record(&self, event: ExportEvent) {
+        if !self.is_enabled() {
+            return;
+        }
+        for export in &self.exports {
+            let mut backlog = export.backlog.lock().unwrap_or_else(|e| e.into_inner());
+            if backlog.len() >= self.max_buffered_events {
+                backlog.pop_front();
+            }
+            backlog.push_back(event.clone());
+        }
+    }
+
+    /// Events waiting for each sink, by sink name
+    pub fn backlogs(&self) -> Vec<(&'static str, usize)> {
+        self.exports
+            .iter()
+            .map(|export| {
+                let backlog = export.backlog.lock().unwrap_or_else(|e| e.into_inner());
+                (export.sink.name(), backlog.len())
+            })
+            .collect()
+    }
+
+    /// Writes every sink's backlog in batches, returning how many events were written.
+    /// A sink stops at its first failed batch, which is kept for the next flush.
+    pub async fn flush(&self) -> usize {
+        let mut written = 0;
+        for export in &self.exports {
+            loop {
+                let batch: Vec<ExportEvent> = {
+                    let mut backlog = export.backlog.lock().unwrap_or_else(|e| e.into_inner());
+                    let len = backlog.len().min(self.max_batch_events);
+                    backlog.drain(..len).collect()
+                };
+                if batch.is_empty() {
+                    break;
+                }
+                match export.sink.write(&batch).await {
+                    Ok(location) => {
+                        written += batch.len();
+                        debug!(
+                            sink = export.sink.name(),
+                            events = batch.len(),
+                            %location,
+                            "Exported events"
+                        );
+                    }
+                    Err(e) => {
+                        warn!(
+                            sink = export.sink.name(),
+                            events = batch.len(),
+                            error = %e,
+                            "Event export failed, retrying on the next flush"
+                        );
+                        let mut backlog = export.backlog.lock().unwrap_or_else(|e| e.into_inner());
+                        for event in batch.into_iter().rev() {
+                            backlog.push_front(event);
+                        }
+                        // Events recorded meanwhile may have pushed the backlog over its bound
+                        let excess = backlog.len().saturating_sub(self.max_buffered_events);
+                        backlog.drain(..excess);
+                        break;
+                    }
+                }
+            }
+        }
+        written
+    }
+}
+
+impl std::fmt::Debug for EventExporter {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("EventExporter")
+            .field("interval", &self.interval)
+            .field(
+                "sinks",
+                &self
+                    .exports
+                    .iter()
+                    .map(|e| e.sink.name())
+                    .collect::<Vec<_>>(),
+            )
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::config::{BigQuerySinkConfig, ParquetSinkConfig};
+    use std::collections::BTreeMap;
+
+    fn event(signature: &str) -> ExportEvent {
+        ExportEvent {
+            event_id: uuid::Uuid::new_v4().simple().to_string(),
+            event_type: schema::SUBMISSION_EVENT,
+            occurred_at: 1_700_000_000,
+            signature: signature.to_string(),
+            fee_payer: "payer".to_string(),
+            submission_result: "SUBMISSION_RESULT_SUBMITTED".to_string(),
+            commitment_level: "COMMITMENT_LEVEL_CONFIRMED".to_string(),
+            attempt_count: 1,
+            dead_letter_id: None,
+            tags: BTreeMap::new(),
+        }
+    }
+
+    fn exporter(destination: &str) -> EventExporter {
+        EventExporter::from_config(&EventExportConfig {
+            max_buffered_events: 3,
+            max_batch_events: 2,
+            parquet: ParquetSinkConfig {
+                destination: destination.to_string(),
+            },
+            ..Default::default()
+        })
+        .unwrap()
+    }
+
+    #[tokio::test]
+    async fn test_flush_writes_the_backlog_in_batches() {
+        let directory = std::env::temp_dir()
+            .join(format!("event-export-test-{}", uuid::Uuid::new_v4().simple()));
+        let exporter = exporter(directory.to_str().unwrap());
+        assert!(exporter.is_enabled());
+
+        for signature in ["a", "b", "c", "d"] {
+            exporter.record(event(signature));
+        }
+        // The oldest event was dropped to stay within max_buffered_events
+        assert_eq!(exporter.backlogs(), vec![("parquet", 3)]);
+        assert_eq!(exporter.flush().await, 3);
+        assert_eq!(exporter.backlogs(), vec![("parquet", 0)]);
+
+        let partition = std::fs::read_dir(&directory)
+            .unwrap()
+            .next()
+            .unwrap()
+            .unwrap();
+        assert_eq!(std::fs::read_dir(partition.path()).unwrap().count(), 2);
+        std::fs::remove_dir_all(directory).unwrap();
+    }
+
+    #[tokio::test]
+    async fn test_failed_batches_stay_queued() {
+        // A regular file where the directory should be makes every write fail
+        let blocker = std::env::temp_dir()
+            .join(format!("event-export-test-{}", uuid::Uuid::new_v4().simple()));
+        std::fs::write(&blocker, b"").unwrap();
+        let exporter = exporter(blocker.to_str().unwrap());
+
+        exporter.record(event("a"));
+        exporter.record(event("b"));
+        assert_eq!(exporter.flush().await, 0);
+        assert_eq!(exporter.backlogs(), vec![("parquet", 2)]);
+        std::fs::remove_file(blocker).unwrap();
+    }
+
+    #[test]
+    fn test_disabled_without_sinks() {
+        let exporter = EventExporter::from_config(&EventExportConfig::default()).unwrap();
+        assert!(!exporter.is_enabled());
+        exporter.record(event("a"));
+        assert!(exporter.backlogs().is_empty());
+
+        assert!(EventExporter::from_config(&EventExportConfig {
+            bigquery: BigQuerySinkConfig {
+                project: "analytics".to_string(),
+                ..Default::default()
+            },
+            ..Default::default()
+        })
+        .is_err());
+    }
+}
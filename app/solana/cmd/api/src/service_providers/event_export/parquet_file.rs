@@ -0,0 +1,279 @@
+use chrono::Utc;
+use parquet::basic::Compression;
+use parquet::data_type::{ByteArray, ByteArrayType, Int64Type};
+use parquet::file::properties::WriterProperties;
+use parquet::file::writer::SerializedFileWriter;
+use parquet::format::KeyValue;
+use parquet::schema::parser::parse_message_type;
+use std::path::PathBuf;
+use std::sync::Arc;
+
+use super::schema::{ColumnType, ExportEvent, Value, COLUMNS, SCHEMA_VERSION};
+use crate::service_providers::gcp_auth::access_token;
+
+/// Parquet file metadata key holding the export schema version
+pub const SCHEMA_VERSION_KEY: &str = "protochain.schema_version";
+/// Cloud Storage upload endpoint
+const GCS_UPLOAD_URL: &str = "https://storage.googleapis.com/upload/storage/v1/b";
+
+/// Where Parquet files are written
+#[derive(Debug, Clone, PartialEq, Eq)]
+enum Destination {
+    /// Objects in a Cloud Storage bucket, named under `prefix`
+    Gcs { bucket: String, prefix: String },
+    /// Files in a local directory
+    Directory(PathBuf),
+}
+
+/// Writes each batch of events as one Snappy-compressed Parquet file.
+///
+/// Files are named `dt=<YYYY-MM-DD>/events-<unix>-<uuid>.parquet` under the destination,
+/// so they can be queried as a date-partitioned external table.
+pub struct ParquetSink {
+    destination: Destination,
+    http: reqwest::Client,
+}
+
+impl ParquetSink {
+    /// Builds the sink for `destination`, a `gs://bucket/prefix` URI or a local directory
+    pub fn new(destination: &str, http: reqwest::Client) -> Result<Self, String> {
+        let destination = if let Some(location) = destination.strip_prefix("gs://") {
+            let (bucket, prefix) = location.split_once('/').unwrap_or((location, ""));
+            if bucket.is_empty() {
+                return Err(format!("Parquet destination {destination} names no bucket"));
+            }
+            Destination::Gcs {
+                bucket: bucket.to_string(),
+                prefix: prefix.trim_matches('/').to_string(),
+            }
+        } else if destination.contains("://") {
+            return Err(format!(
+                "Parquet destination must be gs://bucket/prefix or a local directory, got {destination}"
+            ));
+        } else {
+            Destination::Directory(PathBuf::from(destination))
+        };
+        Ok(Self { destination, http })
+    }
+
+    /// Writes `events` as one file, returning where it was written
+    pub async fn write(&self, events: &[ExportEvent]) -> Result<String, String> {
+        let file = encode(events)?;
+        let name = format!(
+            "dt={}/events-{}-{}.parquet",
+            Utc::now().format("%Y-%m-%d"),
+            Utc::now().timestamp(),
+            uuid::Uuid::new_v4().simple()
+        );
+
+        match &self.destination {
+            Destination::Gcs { bucket, prefix } => {
+                let object = if prefix.is_empty() {
+                    name
+                } else {
+                    format!("{prefix}/{name}")
+                };
+                let token = access_token(&self.http).await?;
+                let response = self
+                    .http
+                    .post(format!("{GCS_UPLOAD_URL}/{bucket}/o"))
+                    .query(&[("uploadType", "media"), ("name", object.as_str())])
+                    .bearer_auth(token)
+                    .header(reqwest::header::CONTENT_TYPE, "application/vnd.apache.parquet")
+                    .body(file)
+                    .send()
+                    .await
+                    .map_err(|e| format!("Cloud Storage upload failed: {e}"))?;
+                let status = response.status();
+                if !status.is_success() {
+                    let body = response.text().await.unwrap_or_default();
+                    return Err(format!("Cloud Storage upload failed with {status}: {body}"));
+                }
+                Ok(format!("gs://{bucket}/{object}"))
+            }
+            Destination::Directory(directory) => {
+                let path = directory.join(name);
+                if let Some(parent) = path.parent() {
+                    tokio::fs::create_dir_all(parent)
+                        .await
+                        .map_err(|e| format!("Failed to create {}: {e}", parent.display()))?;
+                }
+                tokio::fs::write(&path, file)
+                    .await
+                    .map_err(|e| format!("Failed to write {}: {e}", path.display()))?;
+                Ok(path.display().to_string())
+            }
+        }
+    }
+}
+
+impl std::fmt::Debug for ParquetSink {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("ParquetSink")
+            .field("destination", &self.destination)
+            .finish_non_exhaustive()
+    }
+}
+
+/// Parquet message type of the export schema
+fn message_type() -> String {
+    let fields: String = COLUMNS
+        .iter()
+        .map(|column| {
+            let repetition = if column.required {
+                "REQUIRED"
+            } else {
+                "OPTIONAL"
+            };
+            let physical = match column.column_type {
+                ColumnType::String | ColumnType::Json => "BYTE_ARRAY",
+                ColumnType::Int64 | ColumnType::Timestamp => "INT64",
+            };
+            let annotation = match column.column_type {
+                ColumnType::String => " (UTF8)",
+                ColumnType::Int64 => "",
+                ColumnType::Timestamp => " (TIMESTAMP_MILLIS)",
+                ColumnType::Json => " (JSON)",
+            };
+            format!("  {repetition} {physical} {}{annotation};\n", column.name)
+        })
+        .collect();
+    format!("message protochain_event {{\n{fields}}}")
+}
+
+/// Encodes `events` as a Parquet file with a single row group
+pub fn encode(events: &[ExportEvent]) -> Result<Vec<u8>, String> {
+    let parquet_error = |e: parquet::errors::ParquetError| format!("Parquet encoding failed: {e}");
+    let schema = Arc::new(parse_message_type(&message_type()).map_err(parquet_error)?);
+    let properties = Arc::new(
+        WriterProperties::builder()
+            .set_compression(Compression::SNAPPY)
+            .set_key_value_metadata(Some(vec![KeyValue::new(
+                SCHEMA_VERSION_KEY.to_string(),
+                SCHEMA_VERSION.to_string(),
+            )]))
+            .build(),
+    );
+    let rows: Vec<Vec<Value>> = events.iter().map(ExportEvent::row).collect();
+
+    let mut file = Vec::new();
+    let mut writer =
+        SerializedFileWriter::new(&mut file, schema, properties).map_err(parquet_error)?;
+    let mut row_group = writer.next_row_group().map_err(parquet_error)?;
+    for (index, column) in COLUMNS.iter().enumerate() {
+        let mut column_writer = row_group
+            .next_column()
+            .map_err(parquet_error)?
+            .ok_or_else(|| format!("Parquet schema has no column {}", column.name))?;
+        let cells: Vec<&Value> = rows.iter().filter_map(|row| row.get(index)).collect();
+        let definition_levels: Vec<i16> = cells
+            .iter()
+            .map(|cell| i16::from(**cell != Value::Null))
+            .collect();
+        let levels = (!column.required).then_some(definition_levels.as_slice());
+
+        match column.column_type {
+            ColumnType::Int64 | ColumnType::Timestamp => {
+                // Timestamps are stored in milliseconds
+                let scale = if column.column_type == ColumnType::Timestamp {
+                    1_000
+                } else {
+                    1
+                };
+                let values: Vec<i64> = cells
+                    .iter()
+                    .filter_map(|cell| match cell {
+                        Value::Int64(value) => Some(value.saturating_mul(scale)),
+                        _ => None,
+                    })
+                    .collect();
+                column_writer
+                    .typed::<Int64Type>()
+                    .write_batch(&values, levels, None)
+            }
+            ColumnType::String | ColumnType::Json => {
+                let values: Vec<ByteArray> = cells
+                    .iter()
+                    .filter_map(|cell| match cell {
+                        Value::String(value) => Some(ByteArray::from(value.as_str())),
+                        _ => None,
+                    })
+                    .collect();
+                column_writer
+                    .typed::<ByteArrayType>()
+                    .write_batch(&values, levels, None)
+            }
+        }
+        .map_err(parquet_error)?;
+        column_writer.close().map_err(parquet_error)?;
+    }
+    row_group.close().map_err(parquet_error)?;
+    writer.close().map_err(parquet_error)?;
+    Ok(file)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use parquet::file::footer::decode_metadata;
+    use std::collections::BTreeMap;
+
+    fn event(dead_letter_id: Option<&str>) -> ExportEvent {
+        ExportEvent {
+            event_id: uuid::Uuid::new_v4().simple().to_string(),
+            event_type: "submission",
+            occurred_at: 1_700_000_000,
+            signature: "5sig".to_string(),
+            fee_payer: "payer".to_string(),
+            submission_result: "SUBMISSION_RESULT_SUBMITTED".to_string(),
+            commitment_level: "COMMITMENT_LEVEL_CONFIRMED".to_string(),
+            attempt_count: 1,
+            dead_letter_id: dead_letter_id.map(ToString::to_string),
+            tags: BTreeMap::from([("order_id".to_string(), "A-17".to_string())]),
+        }
+    }
+
+    #[test]
+    fn test_encoded_files_carry_the_schema_and_rows() {
+        let file = encode(&[event(None), event(Some("dl-1"))]).unwrap();
+        assert!(file.starts_with(b"PAR1") && file.ends_with(b"PAR1"));
+        // The footer is the metadata, its little-endian length and the magic
+        let footer_len = file.len() - 8;
+        let metadata_len =
+            u32::from_le_bytes(file[footer_len..footer_len + 4].try_into().unwrap()) as usize;
+        let metadata = decode_metadata(&file[footer_len - metadata_len..footer_len]).unwrap();
+        let metadata = metadata.file_metadata();
+
+        assert_eq!(metadata.num_rows(), 2);
+        assert_eq!(metadata.schema_descr().num_columns(), COLUMNS.len());
+        assert_eq!(metadata.schema_descr().column(9).name(), "dead_letter_id");
+        let version = metadata
+            .key_value_metadata()
+            .unwrap()
+            .iter()
+            .find(|entry| entry.key == SCHEMA_VERSION_KEY)
+            .and_then(|entry| entry.value.clone());
+        assert_eq!(version, Some(SCHEMA_VERSION.to_string()));
+    }
+
+    #[test]
+    fn test_destinations() {
+        let http = reqwest::Client::new();
+        let gcs = ParquetSink::new("gs://analytics/protochain/events/", http.clone()).unwrap();
+        assert_eq!(
+            gcs.destination,
+            Destination::Gcs {
+                bucket: "analytics".to_string(),
+                prefix: "protochain/events".to_string(),
+            }
+        );
+        let local = ParquetSink::new("/var/lib/protochain/events", http.clone()).unwrap();
+        assert_eq!(
+            local.destination,
+            Destination::Directory(PathBuf::from("/var/lib/protochain/events"))
+        );
+        assert!(ParquetSink::new("s3://analytics", http.clone()).is_err());
+        assert!(ParquetSink::new("gs:///events", http).is_err());
+    }
+}
@@ -0,0 +1,198 @@
+use std::collections::BTreeMap;
+
+use protochain_api::protochain::solana::transaction::v1::SubmissionRecord;
+
+/// Version of the export schema, written with every row and into Parquet file metadata.
+///
+/// Columns are only ever appended, and appended columns are nullable, so older files and
+/// existing BigQuery tables stay readable under a newer version. Bump the version with
+/// every appended column.
+pub const SCHEMA_VERSION: i64 = 2;
+
+/// Type of an export column
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ColumnType {
+    /// UTF-8 text
+    String,
+    /// Signed 64-bit integer
+    Int64,
+    /// Unix timestamp, carried in seconds
+    Timestamp,
+    /// JSON document, carried as text
+    Json,
+}
+
+/// A column of the export schema
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct Column {
+    /// Column name
+    pub name: &'static str,
+    /// Column type
+    pub column_type: ColumnType,
+    /// Whether every row has a value
+    pub required: bool,
+    /// Column description, published to BigQuery
+    pub description: &'static str,
+}
+
+const fn column(
+    name: &'static str,
+    column_type: ColumnType,
+    required: bool,
+    description: &'static str,
+) -> Column {
+    Column {
+        name,
+        column_type,
+        required,
+        description,
+    }
+}
+
+/// Columns of the export schema, in order
+pub const COLUMNS: &[Column] = &[
+    column("event_id", ColumnType::String, true, "Unique event id, used to dedupe inserts"),
+    column("schema_version", ColumnType::Int64, true, "Export schema version of the row"),
+    column("event_type", ColumnType::String, true, "Kind of event, e.g. submission"),
+    column("occurred_at", ColumnType::Timestamp, true, "When the event happened"),
+    column("signature", ColumnType::String, true, "Transaction signature"),
+    column("fee_payer", ColumnType::String, true, "Fee payer of the transaction"),
+    column("submission_result", ColumnType::String, true, "Outcome of the submission"),
+    column("commitment_level", ColumnType::String, true, "Commitment level submitted at"),
+    column("attempt_count", ColumnType::Int64, true, "Send attempts made"),
+    column(
+        "dead_letter_id",
+        ColumnType::String,
+        false,
+        "Dead letter of a failed submission",
+    ),
+    // Appended in version 2
+    column("tags", ColumnType::Json, false, "Caller tags as a JSON object"),
+];
+
+/// Value of one cell of an exported row
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum Value {
+    /// Text, for `String` and `Json` columns
+    String(String),
+    /// Integer, for `Int64` and `Timestamp` columns
+    Int64(i64),
+    /// No value, for nullable columns only
+    Null,
+}
+
+/// Event type of submission events
+pub const SUBMISSION_EVENT: &str = "submission";
+
+/// An event exported to the columnar sinks
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ExportEvent {
+    /// Unique event id
+    pub event_id: String,
+    /// Kind of event
+    pub event_type: &'static str,
+    /// Unix timestamp (seconds) the event happened at
+    pub occurred_at: i64,
+    /// Transaction signature
+    pub signature: String,
+    /// Fee payer of the transaction
+    pub fee_payer: String,
+    /// Outcome of the submission, by enum name
+    pub submission_result: String,
+    /// Commitment level, by enum name
+    pub commitment_level: String,
+    /// Send attempts made
+    pub attempt_count: u32,
+    /// Dead letter of a failed submission
+    pub dead_letter_id: Option<String>,
+    /// Caller tags
+    pub tags: BTreeMap<String, String>,
+}
+
+impl ExportEvent {
+    /// Describes a recorded submission
+    pub fn from_submission(record: &SubmissionRecord) -> Self {
+        Self {
+            event_id: uuid::Uuid::new_v4().simple().to_string(),
+            event_type: SUBMISSION_EVENT,
+            occurred_at: record.submitted_at,
+            signature: record.signature.clone(),
+            fee_payer: record.fee_payer.clone(),
+            submission_result: record.submission_result().as_str_name().to_string(),
+            commitment_level: record.commitment_level().as_str_name().to_string(),
+            attempt_count: record.attempt_count,
+            dead_letter_id: (!record.dead_letter_id.is_empty())
+                .then(|| record.dead_letter_id.clone()),
+            tags: record
+                .tags
+                .iter()
+                .map(|(key, value)| (key.clone(), value.clone()))
+                .collect(),
+        }
+    }
+
+    /// The event's cells, in `COLUMNS` order
+    pub fn row(&self) -> Vec<Value> {
+        vec![
+            Value::String(self.event_id.clone()),
+            Value::Int64(SCHEMA_VERSION),
+            Value::String(self.event_type.to_string()),
+            Value::Int64(self.occurred_at),
+            Value::String(self.signature.clone()),
+            Value::String(self.fee_payer.clone()),
+            Value::String(self.submission_result.clone()),
+            Value::String(self.commitment_level.clone()),
+            Value::Int64(i64::from(self.attempt_count)),
+            self.dead_letter_id
+                .clone()
+                .map_or(Value::Null, Value::String),
+            Value::String(serde_json::to_string(&self.tags).unwrap_or_else(|_| "{}".to_string())),
+        ]
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use protochain_api::protochain::solana::r#type::v1::CommitmentLevel;
+    use protochain_api::protochain::solana::transaction::v1::SubmissionResult;
+    use std::collections::HashMap;
+
+    #[test]
+    fn test_rows_follow_the_schema() {
+        let record = SubmissionRecord {
+            signature: "5sig".to_string(),
+            tags: HashMap::from([("order_id".to_string(), "A-17".to_string())]),
+            fee_payer: "payer".to_string(),
+            submission_result: SubmissionResult::Submitted.into(),
+            commitment_level: CommitmentLevel::Confirmed.into(),
+            attempt_count: 2,
+            submitted_at: 1_700_000_000,
+            ..Default::default()
+        };
+        let row = ExportEvent::from_submission(&record).row();
+
+        assert_eq!(row.len(), COLUMNS.len());
+        for (column, value) in COLUMNS.iter().zip(&row) {
+            match (column.column_type, value) {
+                (_, Value::Null) => assert!(!column.required, "{} is required", column.name),
+                (ColumnType::String | ColumnType::Json, Value::String(_))
+                | (ColumnType::Int64 | ColumnType::Timestamp, Value::Int64(_)) => {}
+                _ => panic!("{} holds {value:?}", column.name),
+            }
+        }
+        assert_eq!(row[3], Value::Int64(1_700_000_000));
+        assert_eq!(row[6], Value::String("SUBMISSION_RESULT_SUBMITTED".to_string()));
+        assert_eq!(row[7], Value::String("COMMITMENT_LEVEL_CONFIRMED".to_string()));
+        assert_eq!(row[9], Value::Null);
+        assert_eq!(row[10], Value::String(r#"{"order_id":"A-17"}"#.to_string()));
+    }
+
+    #[test]
+    fn test_column_names_are_unique() {
+        let mut names: Vec<&str> = COLUMNS.iter().map(|column| column.name).collect();
+        names.sort_unstable();
+        names.dedup();
+        assert_eq!(names.len(), COLUMNS.len());
+    }
+}
@@ -0,0 +1,226 @@
+use dashmap::DashMap;
+use tonic::Status;
+
+use crate::config::FeatureFlagsConfig;
+
+/// Risky pathways that operators can switch on per environment
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, PartialOrd, Ord)]
+pub enum FeatureFlag {
+    /// Versioned (v0) transactions and address lookup table handling
+    V0Transactions,
+    /// Bundle submission through a Jito block engine
+    JitoBundles,
+    /// Remote signing through a cloud KMS
+    KmsSigning,
+}
+
+impl FeatureFlag {
+    /// Every known flag, in the order reported by `GetCapabilities`
+    pub const ALL: [Self; 3] = [Self::V0Transactions, Self::JitoBundles, Self::KmsSigning];
+
+    /// Stable name used in config files, environment overrides and the admin API
+    pub const fn name(self) -> &'static str {
+        match self {
+            Self::V0Transactions => "v0_transactions",
+            Self::JitoBundles => "jito_bundles",
+            Self::KmsSigning => "kms_signing",
+        }
+    }
+
+    /// Human-readable description of the guarded pathway
+    pub const fn description(self) -> &'static str {
+        match self {
+            Self::V0Transactions => "Compile, convert and submit versioned (v0) transactions",
+            Self::JitoBundles => "Submit atomic transaction bundles to a Jito block engine",
+            Self::KmsSigning => "Sign transactions with keys held in a cloud KMS",
+        }
+    }
+
+    /// Looks up a flag by its stable name
+    pub fn from_name(name: &str) -> Option<Self> {
+        Self::ALL.into_iter().find(|flag| flag.name() == name)
+    }
+}
+
+/// Which layer last set a flag's state
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum FlagSource {
+    /// Built-in default, not mentioned in config
+    Default,
+    /// Set by config file or environment at startup
+    Config,
+    /// Toggled at runtime through the admin service
+    Runtime,
+}
+
+/// Current state of a single feature flag
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct FlagState {
+    /// Whether the guarded pathway is enabled
+    pub enabled: bool,
+    /// Where the state came from
+    pub source: FlagSource,
+}
+
+/// Process-wide feature flag registry seeded from configuration.
+///
+/// All flags default to disabled so that new pathways have to be opted into
+/// explicitly per environment.
+pub struct FeatureFlags {
+    states: DashMap<FeatureFlag, FlagState>,
+    allow_runtime_toggles: bool,
+}
+
+impl FeatureFlags {
+    /// Builds the registry from configuration, rejecting unknown flag names
+    pub fn from_config(config: &FeatureFlagsConfig) -> Result<Self, String> {
+        let states = DashMap::new();
+        for flag in FeatureFlag::ALL {
+            states.insert(
+                flag,
+                FlagState {
+                    enabled: false,
+                    source: FlagSource::Default,
+                },
+            );
+        }
+
+        for (name, enabled) in &config.flags {
+            let flag = FeatureFlag::from_name(name)
+                .ok_or_else(|| format!("Unknown feature flag: {name}"))?;
+            states.insert(
+                flag,
+                FlagState {
+                    enabled: *enabled,
+                    source: FlagSource::Config,
+                },
+            );
+        }
+
+        Ok(Self {
+            states,
+            allow_runtime_toggles: config.allow_runtime_toggles,
+        })
+    }
+
+    /// Returns whether the given flag is currently enabled
+    pub fn is_enabled(&self, flag: FeatureFlag) -> bool {
+        self.state(flag).enabled
+    }
+
+    /// Returns the current state of the given flag
+    pub fn state(&self, flag: FeatureFlag) -> FlagState {
+        self.states.get(&flag).map_or(
+            FlagState {
+                enabled: false,
+                source: FlagSource::Default,
+            },
+            |state| *state,
+        )
+    }
+
+    /// Returns every known flag with its current state
+    pub fn snapshot(&self) -> Vec<(FeatureFlag, FlagState)> {
+        FeatureFlag::ALL
+            .into_iter()
+            .map(|flag| (flag, self.state(flag)))
+            .collect()
+    }
+
+    /// Toggles a flag at runtime, if runtime toggles are permitted
+    pub fn set(&self, flag: FeatureFlag, enabled: bool) -> Result<FlagState, String> {
+        if !self.allow_runtime_toggles {
+            return Err("Runtime feature flag toggles are disabled on this server".to_string());
+        }
+
+        let state = FlagState {
+            enabled,
+            source: FlagSource::Runtime,
+        };
+        self.states.insert(flag, state);
+        Ok(state)
+    }
+
+    /// Fails with `FAILED_PRECONDITION` when the flag guarding a pathway is disabled
+    #[allow(clippy::result_large_err)]
+    pub fn ensure_enabled(&self, flag: FeatureFlag) -> Result<(), Status> {
+        if self.is_enabled(flag) {
+            Ok(())
+        } else {
+            Err(Status::failed_precondition(format!(
+                "Feature '{}' is disabled on this server",
+                flag.name()
+            )))
+        }
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use std::collections::BTreeMap;
+
+    fn config(flags: &[(&str, bool)], allow_runtime_toggles: bool) -> FeatureFlagsConfig {
+        FeatureFlagsConfig {
+            flags: flags
+                .iter()
+                .map(|(name, enabled)| ((*name).to_string(), *enabled))
+                .collect::<BTreeMap<_, _>>(),
+            allow_runtime_toggles,
+        }
+    }
+
+    #[test]
+    fn test_flags_default_to_disabled() {
+        let flags = FeatureFlags::from_config(&config(&[], true)).unwrap();
+
+        for (flag, state) in flags.snapshot() {
+            assert!(!state.enabled, "{} should default to disabled", flag.name());
+            assert_eq!(state.source, FlagSource::Default);
+        }
+        assert!(flags.ensure_enabled(FeatureFlag::JitoBundles).is_err());
+    }
+
+    #[test]
+    fn test_config_enables_flag() {
+        let flags = FeatureFlags::from_config(&config(&[("v0_transactions", true)], true)).unwrap();
+
+        let state = flags.state(FeatureFlag::V0Transactions);
+        assert!(state.enabled);
+        assert_eq!(state.source, FlagSource::Config);
+        assert!(flags.ensure_enabled(FeatureFlag::V0Transactions).is_ok());
+        assert!(!flags.is_enabled(FeatureFlag::KmsSigning));
+    }
+
+    #[test]
+    fn test_unknown_flag_rejected() {
+        let result = FeatureFlags::from_config(&config(&[("warp_drive", true)], true));
+        assert!(result.is_err());
+    }
+
+    #[test]
+    fn test_runtime_toggle() {
+        let flags = FeatureFlags::from_config(&config(&[], true)).unwrap();
+
+        let state = flags.set(FeatureFlag::KmsSigning, true).unwrap();
+        assert!(state.enabled);
+        assert_eq!(state.source, FlagSource::Runtime);
+        assert!(flags.is_enabled(FeatureFlag::KmsSigning));
+    }
+
+    #[test]
+    fn test_runtime_toggle_disallowed() {
+        let flags = FeatureFlags::from_config(&config(&[], false)).unwrap();
+
+        assert!(flags.set(FeatureFlag::KmsSigning, true).is_err());
+        assert!(!flags.is_enabled(FeatureFlag::KmsSigning));
+    }
+
+    #[test]
+    fn test_flag_names_round_trip() {
+        for flag in FeatureFlag::ALL {
+            assert_eq!(FeatureFlag::from_name(flag.name()), Some(flag));
+        }
+    }
+}
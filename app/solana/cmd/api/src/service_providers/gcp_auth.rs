@@ -0,0 +1,34 @@
+use serde::Deserialize;
+
+/// Metadata server endpoint issuing tokens for the instance's service account
+const METADATA_TOKEN_URL: &str =
+    "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token";
+
+#[derive(Deserialize)]
+struct MetadataToken {
+    access_token: String,
+}
+
+/// Returns `GOOGLE_OAUTH_ACCESS_TOKEN` if set, otherwise a token for the instance's
+/// service account from the metadata server
+pub async fn access_token(http: &reqwest::Client) -> Result<String, String> {
+    if let Ok(token) = std::env::var("GOOGLE_OAUTH_ACCESS_TOKEN") {
+        return Ok(token);
+    }
+    let response = http
+        .get(METADATA_TOKEN_URL)
+        .header("Metadata-Flavor", "Google")
+        .send()
+        .await
+        .map_err(|e| format!("GCP metadata token request failed: {e}"))?;
+    let status = response.status();
+    if !status.is_success() {
+        let body = response.text().await.unwrap_or_default();
+        return Err(format!("GCP metadata token request failed with {status}: {body}"));
+    }
+    let token: MetadataToken = response
+        .json()
+        .await
+        .map_err(|e| format!("GCP metadata server returned an invalid token: {e}"))?;
+    Ok(token.access_token)
+}
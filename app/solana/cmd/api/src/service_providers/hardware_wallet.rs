@@ -0,0 +1,139 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use serde::Deserialize;
+use serde_json::json;
+use solana_client::rpc_client::RpcClient;
+use solana_rpc_client_api::request::RpcRequest;
+use solana_sdk::{pubkey::Pubkey, signature::Signature};
+use std::str::FromStr;
+
+use crate::config::HardwareWalletConfig;
+
+/// A Ledger device reported by the companion agent
+#[derive(Debug, Clone, PartialEq, Eq, Deserialize)]
+pub struct HardwareDevice {
+    /// Agent-assigned id, stable while the device stays connected
+    pub id: String,
+    /// Device model (e.g. `nanoS`, `nanoX`, `stax`)
+    pub model: String,
+    /// Version of the Solana app open on the device, empty if it is not open
+    pub app_version: String,
+    /// Whether blind signing is enabled in the Solana app's settings
+    pub blind_signing_enabled: bool,
+}
+
+/// JSON-RPC client for the companion agent that relays requests to Ledger devices.
+///
+/// The agent runs on the host the devices are plugged into and exposes three methods:
+/// `listDevices`, `getPublicKey` (`[device_id, derivation_path]`, returning a base58
+/// public key) and `signMessage` (`[device_id, derivation_path, base64_message]`,
+/// returning a base58 signature once the user approves on the device). Without a
+/// configured URL hardware wallet signing is disabled.
+pub struct HardwareWalletAgent {
+    client: Option<RpcClient>,
+    allow_blind_signing: bool,
+}
+
+impl HardwareWalletAgent {
+    /// Builds the client from configuration
+    pub fn from_config(config: &HardwareWalletConfig) -> Self {
+        Self {
+            client: (!config.agent_url.is_empty())
+                .then(|| RpcClient::new(config.agent_url.clone())),
+            allow_blind_signing: config.allow_blind_signing,
+        }
+    }
+
+    /// Whether an agent is configured
+    pub const fn is_configured(&self) -> bool {
+        self.client.is_some()
+    }
+
+    /// Whether transactions the device cannot display may be blind-signed
+    pub const fn allow_blind_signing(&self) -> bool {
+        self.allow_blind_signing
+    }
+
+    /// Lists the devices currently connected to the agent
+    pub fn list_devices(&self) -> Result<Vec<HardwareDevice>, String> {
+        self.send("listDevices", json!([]))
+    }
+
+    /// Returns a connected device by id
+    pub fn device(&self, device_id: &str) -> Result<HardwareDevice, String> {
+        self.list_devices()?
+            .into_iter()
+            .find(|device| device.id == device_id)
+            .ok_or_else(|| format!("Hardware wallet {device_id} is not connected"))
+    }
+
+    /// Returns the public key the device derives at `derivation_path`
+    pub fn public_key(&self, device_id: &str, derivation_path: &str) -> Result<Pubkey, String> {
+        let key: String = self.send("getPublicKey", json!([device_id, derivation_path]))?;
+        Pubkey::from_str(&key).map_err(|e| format!("Agent returned invalid public key: {e}"))
+    }
+
+    /// Has the device sign `message` with the key at `derivation_path`, blocking until the
+    /// user approves or rejects it on the device
+    pub fn sign_message(
+        &self,
+        device_id: &str,
+        derivation_path: &str,
+        message: &[u8],
+    ) -> Result<Signature, String> {
+        let signature: String = self
+            .send("signMessage", json!([device_id, derivation_path, STANDARD.encode(message)]))?;
+        Signature::from_str(&signature)
+            .map_err(|e| format!("Agent returned invalid signature: {e}"))
+    }
+
+    /// Calls an agent JSON-RPC method
+    fn send<T: serde::de::DeserializeOwned>(
+        &self,
+        method: &'static str,
+        params: serde_json::Value,
+    ) -> Result<T, String> {
+        let client = self
+            .client
+            .as_ref()
+            .ok_or_else(|| "No hardware wallet agent is configured".to_string())?;
+        client
+            .send(RpcRequest::Custom { method }, params)
+            .map_err(|e| format!("Hardware wallet agent {method} failed: {e}"))
+    }
+}
+
+impl std::fmt::Debug for HardwareWalletAgent {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("HardwareWalletAgent")
+            .field("configured", &self.is_configured())
+            .field("allow_blind_signing", &self.allow_blind_signing)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_unconfigured_agent_is_disabled() {
+        let agent = HardwareWalletAgent::from_config(&HardwareWalletConfig::default());
+        assert!(!agent.is_configured());
+        assert!(!agent.allow_blind_signing());
+        assert!(agent.list_devices().is_err());
+    }
+
+    #[test]
+    fn test_parses_devices() {
+        let devices: Vec<HardwareDevice> = serde_json::from_value(json!([{
+            "id": "0001:0004",
+            "model": "nanoX",
+            "app_version": "1.4.1",
+            "blind_signing_enabled": false
+        }]))
+        .unwrap();
+        assert_eq!(devices[0].model, "nanoX");
+        assert!(!devices[0].blind_signing_enabled);
+    }
+}
@@ -0,0 +1,302 @@
+use dashmap::mapref::entry::Entry;
+use dashmap::DashMap;
+use std::sync::Arc;
+
+use protochain_api::protochain::solana::transaction::v1::SubmitTransactionResponse;
+
+use super::unix_timestamp;
+
+/// Default lifetime of an idempotency key, comfortably longer than a blockhash is valid
+pub const DEFAULT_IDEMPOTENCY_TTL_SECONDS: i64 = 3_600;
+/// Default number of idempotency keys retained before the oldest are evicted
+pub const DEFAULT_MAX_IDEMPOTENCY_KEYS: usize = 10_000;
+/// Maximum length of a client-supplied idempotency key
+const MAX_KEY_LEN: usize = 128;
+
+/// A submission result recorded under an idempotency key
+#[derive(Debug, Clone, PartialEq)]
+pub struct CachedSubmission {
+    /// Response returned to the first caller
+    pub response: SubmitTransactionResponse,
+    /// First signature of the transaction that was submitted
+    pub fingerprint: String,
+    /// Unix timestamp of the first submission
+    pub submitted_at: i64,
+}
+
+#[derive(Debug, Clone)]
+enum KeyState {
+    /// A submission under this key is running
+    Pending { started_at: i64 },
+    /// A submission under this key finished and its response is cached
+    Completed(CachedSubmission),
+}
+
+impl KeyState {
+    const fn timestamp(&self) -> i64 {
+        match self {
+            Self::Pending { started_at } => *started_at,
+            Self::Completed(cached) => cached.submitted_at,
+        }
+    }
+}
+
+/// Outcome of reserving an idempotency key
+#[derive(Debug)]
+pub enum Reservation {
+    /// The key is new: submit, then record the result on the guard
+    Fresh(ReservationGuard),
+    /// The key was used before: return the cached result instead of submitting
+    Replay(CachedSubmission),
+    /// Another call holding the key is still submitting
+    InProgress,
+}
+
+/// Dedupe cache for `SubmitTransaction` calls carrying an idempotency key.
+///
+/// The first call with a key reserves it; concurrent calls see it in progress and later
+/// calls replay the recorded response, so a retried gRPC call can never submit a second,
+/// different transaction. Keys expire after a TTL and the cache is bounded.
+pub struct IdempotencyCache {
+    entries: DashMap<String, KeyState>,
+    ttl_seconds: i64,
+    max_entries: usize,
+}
+
+/// Validates a client-supplied idempotency key: printable ASCII, at most 128 characters
+pub fn validate_idempotency_key(key: &str) -> Result<(), String> {
+    if key.len() > MAX_KEY_LEN {
+        return Err(format!("Idempotency key must be at most {MAX_KEY_LEN} characters"));
+    }
+    if !key.chars().all(|c| c.is_ascii_graphic()) {
+        return Err("Idempotency key may only contain printable ASCII characters".to_string());
+    }
+    Ok(())
+}
+
+impl IdempotencyCache {
+    /// Creates an empty cache whose keys live `ttl_seconds`, holding at most `max_entries`
+    pub fn new(ttl_seconds: i64, max_entries: usize) -> Self {
+        Self {
+            entries: DashMap::new(),
+            ttl_seconds,
+            max_entries: max_entries.max(1),
+        }
+    }
+
+    /// Reserves `key` for a new submission, or reports why the caller must not submit
+    pub fn reserve(self: &Arc<Self>, key: &str) -> Result<Reservation, String> {
+        if key.is_empty() {
+            return Err("Idempotency key is required".to_string());
+        }
+        validate_idempotency_key(key)?;
+
+        let now = unix_timestamp();
+        if self.entries.len() >= self.max_entries {
+            self.evict(now);
+        }
+
+        match self.entries.entry(key.to_string()) {
+            Entry::Occupied(mut entry) => {
+                if now - entry.get().timestamp() >= self.ttl_seconds {
+                    entry.insert(KeyState::Pending { started_at: now });
+                    return Ok(Reservation::Fresh(self.guard(key)));
+                }
+                Ok(match entry.get() {
+                    KeyState::Pending { .. } => Reservation::InProgress,
+                    KeyState::Completed(cached) => Reservation::Replay(cached.clone()),
+                })
+            }
+            Entry::Vacant(entry) => {
+                entry.insert(KeyState::Pending { started_at: now });
+                Ok(Reservation::Fresh(self.guard(key)))
+            }
+        }
+    }
+
+    /// Number of keys currently held
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Whether the cache is empty
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Drops keys older than the TTL, returning how many were dropped
+    pub fn purge_expired(&self) -> usize {
+        let now = unix_timestamp();
+        let before = self.entries.len();
+        self.entries
+            .retain(|_, state| now - state.timestamp() < self.ttl_seconds);
+        before.saturating_sub(self.entries.len())
+    }
+
+    fn guard(self: &Arc<Self>, key: &str) -> ReservationGuard {
+        ReservationGuard {
+            cache: Arc::clone(self),
+            key: key.to_string(),
+            settled: false,
+        }
+    }
+
+    /// Drops expired keys, then the oldest key if the cache is still full
+    fn evict(&self, now: i64) {
+        self.entries
+            .retain(|_, state| now - state.timestamp() < self.ttl_seconds);
+        if self.entries.len() >= self.max_entries {
+            let oldest = self
+                .entries
+                .iter()
+                .min_by_key(|entry| entry.timestamp())
+                .map(|entry| entry.key().clone());
+            if let Some(key) = oldest {
+                self.entries.remove(&key);
+            }
+        }
+    }
+}
+
+impl Default for IdempotencyCache {
+    fn default() -> Self {
+        Self::new(DEFAULT_IDEMPOTENCY_TTL_SECONDS, DEFAULT_MAX_IDEMPOTENCY_KEYS)
+    }
+}
+
+/// Holds a reserved idempotency key until the submission settles.
+///
+/// Dropping the guard without settling it (e.g. the call was cancelled) releases the key,
+/// so an abandoned call never blocks its retries.
+#[derive(Debug)]
+pub struct ReservationGuard {
+    cache: Arc<IdempotencyCache>,
+    key: String,
+    settled: bool,
+}
+
+impl ReservationGuard {
+    /// Records the submission result so later calls with the key replay it
+    pub fn complete(mut self, response: &SubmitTransactionResponse, fingerprint: &str) {
+        self.cache.entries.insert(
+            self.key.clone(),
+            KeyState::Completed(CachedSubmission {
+                response: response.clone(),
+                fingerprint: fingerprint.to_string(),
+                submitted_at: unix_timestamp(),
+            }),
+        );
+        self.settled = true;
+    }
+
+    /// Releases the key without recording a result, so the call can be retried
+    pub fn release(self) {
+        drop(self);
+    }
+}
+
+impl Drop for ReservationGuard {
+    fn drop(&mut self) {
+        if !self.settled {
+            self.cache.entries.remove(&self.key);
+        }
+    }
+}
+
+impl std::fmt::Debug for IdempotencyCache {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("IdempotencyCache")
+            .field("entries", &self.entries.len())
+            .field("ttl_seconds", &self.ttl_seconds)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn response(signature: &str) -> SubmitTransactionResponse {
+        SubmitTransactionResponse {
+            signature: signature.to_string(),
+            ..Default::default()
+        }
+    }
+
+    fn fresh(reservation: Reservation) -> ReservationGuard {
+        match reservation {
+            Reservation::Fresh(guard) => guard,
+            other => panic!("expected a fresh reservation, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_replays_completed_submission() {
+        let cache = Arc::new(IdempotencyCache::default());
+        fresh(cache.reserve("order-1").unwrap()).complete(&response("sig"), "sig");
+
+        match cache.reserve("order-1").unwrap() {
+            Reservation::Replay(cached) => {
+                assert_eq!(cached.response.signature, "sig");
+                assert_eq!(cached.fingerprint, "sig");
+            }
+            other => panic!("expected a replay, got {other:?}"),
+        }
+    }
+
+    #[test]
+    fn test_concurrent_call_sees_in_progress() {
+        let cache = Arc::new(IdempotencyCache::default());
+        let _guard = fresh(cache.reserve("order-1").unwrap());
+
+        assert!(matches!(cache.reserve("order-1").unwrap(), Reservation::InProgress));
+    }
+
+    #[test]
+    fn test_released_or_dropped_guard_frees_key() {
+        let cache = Arc::new(IdempotencyCache::default());
+        fresh(cache.reserve("order-1").unwrap()).release();
+        assert!(cache.is_empty());
+
+        drop(fresh(cache.reserve("order-1").unwrap()));
+        assert!(matches!(cache.reserve("order-1").unwrap(), Reservation::Fresh(_)));
+    }
+
+    #[test]
+    fn test_expired_key_is_fresh_again() {
+        let cache = Arc::new(IdempotencyCache::new(0, 10));
+        fresh(cache.reserve("order-1").unwrap()).complete(&response("sig"), "sig");
+
+        assert!(matches!(cache.reserve("order-1").unwrap(), Reservation::Fresh(_)));
+    }
+
+    #[test]
+    fn test_purge_drops_expired_keys() {
+        let expiring = Arc::new(IdempotencyCache::new(0, 10));
+        fresh(expiring.reserve("order-1").unwrap()).complete(&response("sig"), "sig");
+        assert_eq!(expiring.purge_expired(), 1);
+        assert!(expiring.is_empty());
+
+        let cache = Arc::new(IdempotencyCache::default());
+        fresh(cache.reserve("order-1").unwrap()).complete(&response("sig"), "sig");
+        assert_eq!(cache.purge_expired(), 0);
+    }
+
+    #[test]
+    fn test_cache_is_bounded() {
+        let cache = Arc::new(IdempotencyCache::new(DEFAULT_IDEMPOTENCY_TTL_SECONDS, 2));
+        for key in ["a", "b", "c"] {
+            fresh(cache.reserve(key).unwrap()).complete(&response(key), key);
+        }
+        assert_eq!(cache.len(), 2);
+    }
+
+    #[test]
+    fn test_invalid_keys_rejected() {
+        let cache = Arc::new(IdempotencyCache::default());
+        assert!(cache.reserve("").is_err());
+        assert!(cache.reserve("has space").is_err());
+        assert!(cache.reserve(&"k".repeat(MAX_KEY_LEN + 1)).is_err());
+    }
+}
@@ -0,0 +1,201 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use serde::Deserialize;
+use serde_json::json;
+use solana_client::rpc_client::RpcClient;
+use solana_rpc_client_api::request::RpcRequest;
+use solana_sdk::{pubkey::Pubkey, transaction::Transaction as SolanaTransaction};
+use std::str::FromStr;
+
+use crate::config::JitoConfig;
+
+/// Bundle state reported by `getInflightBundleStatuses`, which covers the last five
+/// minutes of submissions
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Deserialize)]
+pub enum InflightBundleState {
+    /// Unknown to the block engine, or older than five minutes
+    Invalid,
+    /// Not yet landed or failed
+    Pending,
+    /// Every leader it was forwarded to rejected it
+    Failed,
+    /// Landed on chain
+    Landed,
+}
+
+/// In-flight status of one bundle
+#[derive(Debug, Clone, PartialEq, Eq, Deserialize)]
+pub struct InflightBundleStatus {
+    /// Bundle id returned by `sendBundle`
+    pub bundle_id: String,
+    /// Current state
+    pub status: InflightBundleState,
+    /// Slot the bundle landed in
+    pub landed_slot: Option<u64>,
+}
+
+/// JSON-RPC `{context, value}` envelope of the block engine's status methods
+#[derive(Debug, Deserialize)]
+struct StatusesResponse<T> {
+    value: Vec<Option<T>>,
+}
+
+/// JSON-RPC client for a Jito block engine.
+///
+/// The block engine speaks JSON-RPC over HTTP at `/api/v1/bundles`, so the standard RPC
+/// client is reused with custom methods. Without a configured URL bundles are disabled.
+pub struct JitoBlockEngine {
+    client: Option<RpcClient>,
+    tip_accounts: Vec<Pubkey>,
+    min_tip_lamports: u64,
+}
+
+impl JitoBlockEngine {
+    /// Builds the client from configuration, rejecting invalid tip accounts
+    pub fn from_config(config: &JitoConfig) -> Result<Self, String> {
+        let tip_accounts = config
+            .tip_accounts
+            .iter()
+            .map(|account| {
+                Pubkey::from_str(account).map_err(|e| format!("Invalid tip account {account}: {e}"))
+            })
+            .collect::<Result<Vec<Pubkey>, String>>()?;
+
+        Ok(Self {
+            client: (!config.block_engine_url.is_empty())
+                .then(|| RpcClient::new(config.block_engine_url.clone())),
+            tip_accounts,
+            min_tip_lamports: config.min_tip_lamports,
+        })
+    }
+
+    /// Whether a block engine is configured
+    pub const fn is_configured(&self) -> bool {
+        self.client.is_some()
+    }
+
+    /// Smallest total tip a bundle must carry
+    pub const fn min_tip_lamports(&self) -> u64 {
+        self.min_tip_lamports
+    }
+
+    /// Accounts a bundle may tip, from configuration or else the block engine
+    pub fn tip_accounts(&self) -> Result<Vec<Pubkey>, String> {
+        if !self.tip_accounts.is_empty() {
+            return Ok(self.tip_accounts.clone());
+        }
+        let accounts: Vec<String> = self.send("getTipAccounts", json!([]))?;
+        accounts
+            .iter()
+            .map(|account| {
+                Pubkey::from_str(account)
+                    .map_err(|e| format!("Block engine returned invalid tip account: {e}"))
+            })
+            .collect()
+    }
+
+    /// Submits signed transactions as one bundle, returning its id
+    pub fn send_bundle(&self, transactions: &[SolanaTransaction]) -> Result<String, String> {
+        let encoded = transactions
+            .iter()
+            .map(|transaction| {
+                bincode::serialize(transaction)
+                    .map(|bytes| STANDARD.encode(bytes))
+                    .map_err(|e| format!("Failed to serialize bundle transaction: {e}"))
+            })
+            .collect::<Result<Vec<String>, String>>()?;
+        self.send("sendBundle", json!([encoded, { "encoding": "base64" }]))
+    }
+
+    /// Returns the in-flight status of a bundle, `None` if the block engine does not know it
+    pub fn inflight_status(&self, bundle_id: &str) -> Result<Option<InflightBundleStatus>, String> {
+        let statuses: StatusesResponse<InflightBundleStatus> =
+            self.send("getInflightBundleStatuses", json!([[bundle_id]]))?;
+        Ok(statuses.value.into_iter().flatten().next())
+    }
+
+    /// Returns the signatures of a landed bundle's transactions, in bundle order
+    pub fn landed_signatures(&self, bundle_id: &str) -> Result<Vec<String>, String> {
+        #[derive(Deserialize)]
+        struct BundleStatus {
+            transactions: Vec<String>,
+        }
+
+        let statuses: StatusesResponse<BundleStatus> =
+            self.send("getBundleStatuses", json!([[bundle_id]]))?;
+        Ok(statuses
+            .value
+            .into_iter()
+            .flatten()
+            .next()
+            .map(|status| status.transactions)
+            .unwrap_or_default())
+    }
+
+    /// Calls a block engine JSON-RPC method
+    fn send<T: serde::de::DeserializeOwned>(
+        &self,
+        method: &'static str,
+        params: serde_json::Value,
+    ) -> Result<T, String> {
+        let client = self
+            .client
+            .as_ref()
+            .ok_or_else(|| "No Jito block engine is configured".to_string())?;
+        client
+            .send(RpcRequest::Custom { method }, params)
+            .map_err(|e| format!("Block engine {method} failed: {e}"))
+    }
+}
+
+impl std::fmt::Debug for JitoBlockEngine {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("JitoBlockEngine")
+            .field("configured", &self.is_configured())
+            .field("tip_accounts", &self.tip_accounts.len())
+            .field("min_tip_lamports", &self.min_tip_lamports)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_unconfigured_engine_is_disabled() {
+        let engine = JitoBlockEngine::from_config(&JitoConfig::default()).unwrap();
+        assert!(!engine.is_configured());
+        assert_eq!(engine.min_tip_lamports(), 1_000);
+        assert!(engine.send_bundle(&[]).is_err());
+    }
+
+    #[test]
+    fn test_configured_tip_accounts_are_validated() {
+        let account = Pubkey::new_unique();
+        let config = JitoConfig {
+            tip_accounts: vec![account.to_string()],
+            ..Default::default()
+        };
+        let engine = JitoBlockEngine::from_config(&config).unwrap();
+        assert_eq!(engine.tip_accounts().unwrap(), vec![account]);
+
+        let invalid = JitoConfig {
+            tip_accounts: vec!["not-a-key".to_string()],
+            ..Default::default()
+        };
+        assert!(JitoBlockEngine::from_config(&invalid).is_err());
+    }
+
+    #[test]
+    fn test_parses_inflight_statuses() {
+        let response: StatusesResponse<InflightBundleStatus> = serde_json::from_value(json!({
+            "context": { "slot": 280_999_028 },
+            "value": [{ "bundle_id": "b1", "status": "Landed", "landed_slot": 280_999_000 }]
+        }))
+        .unwrap();
+        let status = response.value.into_iter().flatten().next().unwrap();
+        assert_eq!(status.status, InflightBundleState::Landed);
+        assert_eq!(status.landed_slot, Some(280_999_000));
+    }
+}
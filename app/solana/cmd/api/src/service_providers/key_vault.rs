@@ -0,0 +1,380 @@
+use bip39::{ErrorKind, Language, Mnemonic};
+use dashmap::mapref::entry::Entry;
+use dashmap::DashMap;
+use solana_sdk::instruction::Instruction;
+use solana_sdk::pubkey::Pubkey;
+use solana_sdk::signature::{Keypair, Signer};
+use std::str::FromStr;
+use std::sync::{Arc, Mutex};
+
+use super::unix_timestamp;
+
+/// Maximum length of a key alias
+const MAX_ALIAS_LEN: usize = 64;
+/// Word counts of valid BIP39 mnemonics
+const MNEMONIC_WORD_COUNTS: [usize; 5] = [12, 15, 18, 21, 24];
+
+/// Current state of an alias in the vault
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct AliasState {
+    /// Stable alias
+    pub alias: String,
+    /// Key currently behind the alias
+    pub public_key: Pubkey,
+    /// Starts at 1, increments on every rotation
+    pub version: u32,
+    /// Unix timestamp the alias was created
+    pub created_at: i64,
+    /// Unix timestamp of the last rotation (0 if never rotated)
+    pub rotated_at: i64,
+    /// Previous keys for this alias, oldest first
+    pub retired: Vec<Pubkey>,
+}
+
+/// Audit record of a single rotation
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct RotationRecord {
+    /// Rotation identifier
+    pub id: String,
+    /// Rotated alias
+    pub alias: String,
+    /// Key retired by the rotation
+    pub previous: Pubkey,
+    /// Key now behind the alias
+    pub successor: Pubkey,
+    /// Alias version before the rotation
+    pub previous_version: u32,
+    /// Operator-supplied reason
+    pub reason: String,
+    /// Unix timestamp of the rotation
+    pub rotated_at: i64,
+    /// On-chain instructions generated to migrate from the previous key
+    pub migration_instructions: Vec<Instruction>,
+}
+
+/// In-memory vault of server-held signing keys addressed by alias.
+///
+/// References to a key go through its alias, so a rotation only has to swap the key
+/// behind the alias for every fee-payer and authority reference to follow. Retired keys
+/// stay in the vault so that migration transactions can still be signed with them.
+/// The vault also holds named BIP39 mnemonics that signing keys can be derived from.
+pub struct KeyVault {
+    keys: DashMap<Pubkey, Arc<Keypair>>,
+    aliases: DashMap<String, AliasState>,
+    rotations: Mutex<Vec<RotationRecord>>,
+    mnemonics: DashMap<String, String>,
+}
+
+/// Validates an alias: lowercase letters, digits, '-' and '_'
+pub fn validate_alias(alias: &str) -> Result<(), String> {
+    if alias.is_empty() {
+        return Err("Alias is required".to_string());
+    }
+    if alias.len() > MAX_ALIAS_LEN {
+        return Err(format!("Alias must be at most {MAX_ALIAS_LEN} characters"));
+    }
+    if !alias
+        .chars()
+        .all(|c| c.is_ascii_lowercase() || c.is_ascii_digit() || c == '-' || c == '_')
+    {
+        return Err("Alias may only contain lowercase letters, digits, '-' and '_'".to_string());
+    }
+    Ok(())
+}
+
+/// Normalizes a BIP39 mnemonic to lowercase words separated by single spaces, rejecting
+/// phrases with a word count no mnemonic has, a word outside the English wordlist or a bad
+/// checksum
+pub fn normalize_mnemonic(phrase: &str) -> Result<String, String> {
+    let words: Vec<String> = phrase.split_whitespace().map(str::to_lowercase).collect();
+    if !MNEMONIC_WORD_COUNTS.contains(&words.len()) {
+        return Err(format!("Mnemonic must have 12, 15, 18, 21 or 24 words, got {}", words.len()));
+    }
+    let normalized = words.join(" ");
+    Mnemonic::validate(&normalized, Language::English).map_err(|e| {
+        match e.downcast_ref::<ErrorKind>() {
+            Some(ErrorKind::InvalidWord) => {
+                "Mnemonic contains a word outside the BIP39 English wordlist".to_string()
+            }
+            Some(ErrorKind::InvalidChecksum) => "Mnemonic checksum is invalid".to_string(),
+            _ => format!("Invalid mnemonic: {e}"),
+        }
+    })?;
+    Ok(normalized)
+}
+
+impl KeyVault {
+    /// Creates an empty vault
+    pub fn new() -> Self {
+        Self {
+            keys: DashMap::new(),
+            aliases: DashMap::new(),
+            rotations: Mutex::new(Vec::new()),
+            mnemonics: DashMap::new(),
+        }
+    }
+
+    /// Holds a mnemonic under `name` so signing requests can reference it instead of
+    /// carrying the phrase
+    pub fn store_mnemonic(&self, name: &str, phrase: &str) -> Result<(), String> {
+        validate_alias(name)?;
+        self.mnemonics
+            .insert(name.to_string(), normalize_mnemonic(phrase)?);
+        Ok(())
+    }
+
+    /// Returns the mnemonic held under `name`
+    pub fn mnemonic(&self, name: &str) -> Option<String> {
+        self.mnemonics.get(name).map(|phrase| phrase.clone())
+    }
+
+    /// Generates a new key behind a new alias
+    pub fn create(&self, alias: &str) -> Result<AliasState, String> {
+        validate_alias(alias)?;
+
+        match self.aliases.entry(alias.to_string()) {
+            Entry::Occupied(_) => Err(format!("Alias already exists: {alias}")),
+            Entry::Vacant(entry) => {
+                let keypair = Keypair::new();
+                let public_key = keypair.pubkey();
+                self.keys.insert(public_key, Arc::new(keypair));
+
+                let state = AliasState {
+                    alias: alias.to_string(),
+                    public_key,
+                    version: 1,
+                    created_at: unix_timestamp(),
+                    rotated_at: 0,
+                    retired: Vec::new(),
+                };
+                entry.insert(state.clone());
+                Ok(state)
+            }
+        }
+    }
+
+    /// Returns the current state of an alias
+    pub fn get(&self, alias: &str) -> Option<AliasState> {
+        self.aliases.get(alias).map(|state| state.clone())
+    }
+
+    /// Lists every alias, ordered by alias
+    pub fn list(&self) -> Vec<AliasState> {
+        let mut states: Vec<AliasState> = self.aliases.iter().map(|state| state.clone()).collect();
+        states.sort_by(|a, b| a.alias.cmp(&b.alias));
+        states
+    }
+
+    /// Resolves a key reference: an alias (its current key) or a public key held by the vault
+    pub fn resolve(&self, key_ref: &str) -> Option<Arc<Keypair>> {
+        if let Some(state) = self.aliases.get(key_ref) {
+            return self.keys.get(&state.public_key).map(|key| Arc::clone(&key));
+        }
+        let public_key = Pubkey::from_str(key_ref).ok()?;
+        self.keys.get(&public_key).map(|key| Arc::clone(&key))
+    }
+
+    /// Creates a successor key and swaps it behind the alias.
+    ///
+    /// `migration` receives the previous and successor keys and builds the on-chain
+    /// migration; if it fails nothing changes. The swap and its audit record happen under
+    /// the rotation lock, so concurrent rotations are serialised and each one is recorded.
+    pub fn rotate<F>(
+        &self,
+        alias: &str,
+        reason: &str,
+        migration: F,
+    ) -> Result<(AliasState, RotationRecord), String>
+    where
+        F: FnOnce(&Pubkey, &Pubkey) -> Result<Vec<Instruction>, String>,
+    {
+        let mut rotations = self
+            .rotations
+            .lock()
+            .map_err(|_| "Key rotation log is unavailable".to_string())?;
+
+        let mut state = self
+            .aliases
+            .get_mut(alias)
+            .ok_or_else(|| format!("Alias not found: {alias}"))?;
+
+        let successor = Keypair::new();
+        let successor_key = successor.pubkey();
+        let migration_instructions = migration(&state.public_key, &successor_key)?;
+        self.keys.insert(successor_key, Arc::new(successor));
+
+        let record = RotationRecord {
+            id: uuid::Uuid::new_v4().to_string(),
+            alias: alias.to_string(),
+            previous: state.public_key,
+            successor: successor_key,
+            previous_version: state.version,
+            reason: reason.to_string(),
+            rotated_at: unix_timestamp(),
+            migration_instructions,
+        };
+
+        let previous = state.public_key;
+        state.retired.push(previous);
+        state.public_key = successor_key;
+        state.version += 1;
+        state.rotated_at = record.rotated_at;
+        let updated = state.clone();
+        drop(state);
+
+        rotations.push(record.clone());
+        Ok((updated, record))
+    }
+
+    /// Rotation audit trail, newest first, optionally for a single alias
+    pub fn rotations(&self, alias: Option<&str>) -> Vec<RotationRecord> {
+        self.rotations
+            .lock()
+            .map(|rotations| {
+                rotations
+                    .iter()
+                    .rev()
+                    .filter(|record| alias.map_or(true, |alias| record.alias == alias))
+                    .cloned()
+                    .collect()
+            })
+            .unwrap_or_default()
+    }
+}
+
+impl Default for KeyVault {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn no_migration(_: &Pubkey, _: &Pubkey) -> Result<Vec<Instruction>, String> {
+        Ok(Vec::new())
+    }
+
+    #[test]
+    fn test_create_and_resolve() {
+        let vault = KeyVault::new();
+        let state = vault.create("fee-payer").unwrap();
+
+        assert_eq!(state.version, 1);
+        assert_eq!(vault.resolve("fee-payer").unwrap().pubkey(), state.public_key);
+        assert_eq!(
+            vault
+                .resolve(&state.public_key.to_string())
+                .unwrap()
+                .pubkey(),
+            state.public_key
+        );
+        assert!(vault.resolve("unknown").is_none());
+    }
+
+    #[test]
+    fn test_duplicate_and_invalid_aliases_rejected() {
+        let vault = KeyVault::new();
+        vault.create("treasury").unwrap();
+
+        assert!(vault.create("treasury").is_err());
+        assert!(vault.create("").is_err());
+        assert!(vault.create("Has Spaces").is_err());
+        assert!(vault.create(&"a".repeat(MAX_ALIAS_LEN + 1)).is_err());
+    }
+
+    #[test]
+    fn test_stored_mnemonics_are_normalized() {
+        let vault = KeyVault::new();
+        let phrase = "Abandon  abandon abandon abandon abandon abandon\n\
+                      abandon abandon abandon abandon abandon about";
+        vault.store_mnemonic("wallet", phrase).unwrap();
+
+        assert_eq!(vault.mnemonic("wallet").unwrap(), format!("{}about", "abandon ".repeat(11)));
+        assert!(vault.mnemonic("unknown").is_none());
+        assert!(vault.store_mnemonic("short", "abandon about").is_err());
+        assert!(vault.store_mnemonic("Bad Name", phrase).is_err());
+    }
+
+    #[test]
+    fn test_mnemonics_are_checked_against_bip39() {
+        assert!(normalize_mnemonic(&format!("{}about", "abandon ".repeat(11))).is_ok());
+
+        // Every word is in the wordlist but the last one does not match the checksum
+        let bad_checksum = normalize_mnemonic(&"abandon ".repeat(12)).unwrap_err();
+        assert!(bad_checksum.contains("checksum"));
+
+        let unknown_word =
+            normalize_mnemonic(&format!("{}abandonn about", "abandon ".repeat(10))).unwrap_err();
+        assert!(unknown_word.contains("wordlist"));
+    }
+
+    #[test]
+    fn test_rotation_swaps_alias_and_keeps_retired_key() {
+        let vault = KeyVault::new();
+        let original = vault.create("treasury").unwrap();
+
+        let (rotated, record) = vault.rotate("treasury", "scheduled", no_migration).unwrap();
+
+        assert_eq!(rotated.version, 2);
+        assert_ne!(rotated.public_key, original.public_key);
+        assert_eq!(rotated.retired, vec![original.public_key]);
+        assert_eq!(record.previous, original.public_key);
+        assert_eq!(record.successor, rotated.public_key);
+
+        // Alias follows the successor, the retired key can still sign migrations
+        assert_eq!(vault.resolve("treasury").unwrap().pubkey(), rotated.public_key);
+        assert!(vault.resolve(&original.public_key.to_string()).is_some());
+    }
+
+    #[test]
+    fn test_rotation_audit_trail() {
+        let vault = KeyVault::new();
+        vault.create("a").unwrap();
+        vault.create("b").unwrap();
+        vault.rotate("a", "first", no_migration).unwrap();
+        vault.rotate("b", "second", no_migration).unwrap();
+        vault.rotate("a", "third", no_migration).unwrap();
+
+        let all = vault.rotations(None);
+        assert_eq!(all.len(), 3);
+        assert_eq!(all[0].reason, "third");
+
+        let only_a = vault.rotations(Some("a"));
+        assert_eq!(only_a.len(), 2);
+        assert_eq!(only_a[0].previous_version, 2);
+        assert!(vault.rotate("missing", "", no_migration).is_err());
+    }
+
+    #[test]
+    fn test_failed_migration_leaves_alias_untouched() {
+        let vault = KeyVault::new();
+        let original = vault.create("treasury").unwrap();
+
+        let result = vault.rotate("treasury", "", |_, _| Err("bad migration".to_string()));
+
+        assert!(result.is_err());
+        assert_eq!(vault.get("treasury").unwrap(), original);
+        assert!(vault.rotations(None).is_empty());
+    }
+
+    #[test]
+    fn test_migration_sees_previous_and_successor() {
+        let vault = KeyVault::new();
+        let original = vault.create("treasury").unwrap();
+
+        let (rotated, record) = vault
+            .rotate("treasury", "", |previous, successor| {
+                Ok(vec![solana_sdk::system_instruction::transfer(
+                    previous, successor, 1,
+                )])
+            })
+            .unwrap();
+
+        let transfer = &record.migration_instructions[0];
+        assert_eq!(transfer.accounts[0].pubkey, original.public_key);
+        assert_eq!(transfer.accounts[1].pubkey, rotated.public_key);
+    }
+}
@@ -0,0 +1,349 @@
+use aes_gcm::aead::{Aead, AeadCore, KeyInit, OsRng, Payload};
+use aes_gcm::{Aes256Gcm, Nonce};
+use base64::{engine::general_purpose::STANDARD, Engine};
+use serde::{Deserialize, Serialize};
+use solana_sdk::pubkey::Pubkey;
+use solana_sdk::signature::{Keypair, Signer};
+use std::io::Write;
+use std::path::PathBuf;
+use std::str::FromStr;
+use std::sync::Arc;
+
+use super::kms::{KmsProvider, KmsSigner};
+use crate::config::KeystoreConfig;
+
+/// Prefix of keystore handles, which tells them apart from key vault references
+pub const HANDLE_PREFIX: &str = "ks_";
+/// Target of the key export audit events, so they can be routed apart from the service logs
+pub const AUDIT_TARGET: &str = "keystore_audit";
+/// Version of the key file format
+const FILE_VERSION: u32 = 1;
+/// Length of the key-encryption key
+const KEK_LEN: usize = 32;
+
+/// An encrypted key file
+#[derive(Serialize, Deserialize)]
+struct KeyFile {
+    version: u32,
+    public_key: String,
+    nonce: String,
+    ciphertext: String,
+}
+
+/// Keys generated by `GenerateNewKeyPair`, encrypted at rest and addressed by handle.
+///
+/// Each key is a file in the configured directory holding its keypair sealed with
+/// AES-256-GCM under the key-encryption key (KEK), with the handle and public key bound in
+/// as associated data so that files cannot be swapped between handles. Keys are decrypted
+/// per signing request and never cached. The KEK comes from configuration or is unwrapped
+/// through a cloud KMS at startup; without a directory the keystore is disabled.
+pub struct Keystore {
+    directory: PathBuf,
+    cipher: Option<Aes256Gcm>,
+    required: bool,
+    export_enabled: bool,
+}
+
+impl Keystore {
+    /// Builds the keystore from configuration, unwrapping the KEK through `kms` when it
+    /// is KMS-wrapped
+    pub async fn from_config(config: &KeystoreConfig, kms: &KmsSigner) -> Result<Self, String> {
+        let wrapped = !config.kek_ciphertext.is_empty() || !config.kek_kms_key_name.is_empty();
+        if config.directory.is_empty() {
+            if config.required {
+                return Err(
+                    "Keystore mode is required but no keystore directory is set".to_string()
+                );
+            }
+            return Ok(Self {
+                directory: PathBuf::new(),
+                cipher: None,
+                required: false,
+                export_enabled: false,
+            });
+        }
+
+        let kek = match (config.kek.is_empty(), wrapped) {
+            (false, false) => STANDARD
+                .decode(&config.kek)
+                .map_err(|e| format!("Invalid keystore KEK: {e}"))?,
+            (true, true) => {
+                if config.kek_ciphertext.is_empty() || config.kek_kms_key_name.is_empty() {
+                    return Err(
+                        "A KMS-wrapped KEK needs both a KMS key name and a ciphertext".to_string()
+                    );
+                }
+                let provider = KmsProvider::parse(&config.kek_kms_provider)?;
+                let ciphertext = STANDARD
+                    .decode(&config.kek_ciphertext)
+                    .map_err(|e| format!("Invalid keystore KEK ciphertext: {e}"))?;
+                kms.decrypt(provider, &config.kek_kms_key_name, &ciphertext)
+                    .await
+                    .map_err(|e| format!("Failed to unwrap keystore KEK: {e}"))?
+            }
+            (false, true) => {
+                return Err("Set either a keystore KEK or a KMS-wrapped KEK, not both".to_string())
+            }
+            (true, false) => return Err("A keystore KEK is required".to_string()),
+        };
+        if kek.len() != KEK_LEN {
+            return Err(format!("Keystore KEK must be {KEK_LEN} bytes, got {}", kek.len()));
+        }
+
+        let directory = PathBuf::from(&config.directory);
+        std::fs::create_dir_all(&directory)
+            .map_err(|e| format!("Failed to create keystore directory: {e}"))?;
+        Ok(Self {
+            directory,
+            cipher: Some(
+                Aes256Gcm::new_from_slice(&kek)
+                    .map_err(|e| format!("Invalid keystore KEK: {e}"))?,
+            ),
+            required: config.required,
+            export_enabled: config.export_enabled,
+        })
+    }
+
+    /// Whether keys can be stored
+    pub const fn is_enabled(&self) -> bool {
+        self.cipher.is_some()
+    }
+
+    /// Whether keys must be stored rather than returned
+    pub const fn is_required(&self) -> bool {
+        self.required
+    }
+
+    /// Whether stored keys may be returned decrypted by `ExportKeyPair`
+    pub const fn is_export_enabled(&self) -> bool {
+        self.export_enabled && !self.required
+    }
+
+    /// Whether a key reference is a keystore handle
+    pub fn is_handle(key_ref: &str) -> bool {
+        key_ref
+            .strip_prefix(HANDLE_PREFIX)
+            .is_some_and(|id| id.len() == 32 && id.chars().all(|c| c.is_ascii_hexdigit()))
+    }
+
+    /// Encrypts `keypair` to a new key file, returning its handle
+    pub fn store(&self, keypair: &Keypair) -> Result<String, String> {
+        let cipher = self.cipher()?;
+        let handle = format!("{HANDLE_PREFIX}{}", uuid::Uuid::new_v4().simple());
+        let public_key = keypair.pubkey().to_string();
+        let nonce = Aes256Gcm::generate_nonce(&mut OsRng);
+        let ciphertext = cipher
+            .encrypt(
+                &nonce,
+                Payload {
+                    msg: &keypair.to_bytes(),
+                    aad: associated_data(&handle, &public_key).as_bytes(),
+                },
+            )
+            .map_err(|_| "Failed to encrypt key".to_string())?;
+        let file = serde_json::to_vec(&KeyFile {
+            version: FILE_VERSION,
+            public_key,
+            nonce: STANDARD.encode(nonce),
+            ciphertext: STANDARD.encode(ciphertext),
+        })
+        .map_err(|e| format!("Failed to encode key file: {e}"))?;
+
+        let mut options = std::fs::OpenOptions::new();
+        options.write(true).create_new(true);
+        #[cfg(unix)]
+        {
+            use std::os::unix::fs::OpenOptionsExt;
+            options.mode(0o600);
+        }
+        options
+            .open(self.path(&handle))
+            .and_then(|mut out| out.write_all(&file).and_then(|()| out.sync_all()))
+            .map_err(|e| format!("Failed to write key file: {e}"))?;
+        Ok(handle)
+    }
+
+    /// Decrypts the key behind `handle`, or `None` if there is no such key
+    pub fn load(&self, handle: &str) -> Result<Option<Arc<Keypair>>, String> {
+        let cipher = self.cipher()?;
+        if !Self::is_handle(handle) {
+            return Ok(None);
+        }
+        let contents = match std::fs::read(self.path(handle)) {
+            Ok(contents) => contents,
+            Err(e) if e.kind() == std::io::ErrorKind::NotFound => return Ok(None),
+            Err(e) => return Err(format!("Failed to read key file: {e}")),
+        };
+        let file: KeyFile =
+            serde_json::from_slice(&contents).map_err(|e| format!("Invalid key file: {e}"))?;
+        if file.version != FILE_VERSION {
+            return Err(format!("Unsupported key file version {}", file.version));
+        }
+
+        let nonce = STANDARD
+            .decode(&file.nonce)
+            .ok()
+            .filter(|nonce| nonce.len() == 12)
+            .ok_or_else(|| "Invalid key file nonce".to_string())?;
+        let ciphertext = STANDARD
+            .decode(&file.ciphertext)
+            .map_err(|e| format!("Invalid key file ciphertext: {e}"))?;
+        let plaintext = cipher
+            .decrypt(
+                Nonce::from_slice(&nonce),
+                Payload {
+                    msg: &ciphertext,
+                    aad: associated_data(handle, &file.public_key).as_bytes(),
+                },
+            )
+            .map_err(|_| format!("Key {handle} could not be decrypted with the keystore KEK"))?;
+        let keypair =
+            Keypair::from_bytes(&plaintext).map_err(|e| format!("Invalid stored key: {e}"))?;
+        if Pubkey::from_str(&file.public_key).ok() != Some(keypair.pubkey()) {
+            return Err(format!("Key {handle} does not match its public key"));
+        }
+        Ok(Some(Arc::new(keypair)))
+    }
+
+    fn cipher(&self) -> Result<&Aes256Gcm, String> {
+        self.cipher
+            .as_ref()
+            .ok_or_else(|| "No keystore is configured".to_string())
+    }
+
+    fn path(&self, handle: &str) -> PathBuf {
+        self.directory.join(format!("{handle}.json"))
+    }
+}
+
+impl std::fmt::Debug for Keystore {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("Keystore")
+            .field("directory", &self.directory)
+            .field("required", &self.required)
+            .field("export_enabled", &self.export_enabled)
+            .finish_non_exhaustive()
+    }
+}
+
+fn associated_data(handle: &str, public_key: &str) -> String {
+    format!("{handle}:{public_key}")
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use crate::config::KmsConfig;
+
+    async fn keystore(directory: &std::path::Path) -> Keystore {
+        let config = KeystoreConfig {
+            directory: directory.to_string_lossy().to_string(),
+            kek: STANDARD.encode([7u8; KEK_LEN]),
+            ..Default::default()
+        };
+        Keystore::from_config(&config, &KmsSigner::from_config(&KmsConfig::default()).unwrap())
+            .await
+            .unwrap()
+    }
+
+    fn temp_dir() -> PathBuf {
+        std::env::temp_dir().join(format!("keystore-test-{}", uuid::Uuid::new_v4().simple()))
+    }
+
+    #[tokio::test]
+    async fn test_stores_and_loads_keys() {
+        let directory = temp_dir();
+        let keystore = keystore(&directory).await;
+        let keypair = Keypair::new();
+
+        let handle = keystore.store(&keypair).unwrap();
+        assert!(Keystore::is_handle(&handle));
+        let contents = std::fs::read_to_string(directory.join(format!("{handle}.json"))).unwrap();
+        assert!(!contents.contains(&bs58::encode(keypair.to_bytes()).into_string()));
+
+        let loaded = keystore.load(&handle).unwrap().unwrap();
+        assert_eq!(loaded.pubkey(), keypair.pubkey());
+        assert!(keystore
+            .load(&format!("{HANDLE_PREFIX}{}", uuid::Uuid::new_v4().simple()))
+            .unwrap()
+            .is_none());
+        std::fs::remove_dir_all(directory).unwrap();
+    }
+
+    #[tokio::test]
+    async fn test_rejects_swapped_key_files() {
+        let directory = temp_dir();
+        let keystore = keystore(&directory).await;
+        let first = keystore.store(&Keypair::new()).unwrap();
+        let second = keystore.store(&Keypair::new()).unwrap();
+
+        std::fs::copy(
+            directory.join(format!("{first}.json")),
+            directory.join(format!("{second}.json")),
+        )
+        .unwrap();
+        assert!(keystore.load(&second).is_err());
+        std::fs::remove_dir_all(directory).unwrap();
+    }
+
+    #[tokio::test]
+    async fn test_kek_settings() {
+        let kms = KmsSigner::from_config(&KmsConfig::default()).unwrap();
+        let disabled = Keystore::from_config(&KeystoreConfig::default(), &kms)
+            .await
+            .unwrap();
+        assert!(!disabled.is_enabled());
+
+        let required_without_directory = KeystoreConfig {
+            required: true,
+            ..Default::default()
+        };
+        assert!(Keystore::from_config(&required_without_directory, &kms)
+            .await
+            .is_err());
+
+        let short_kek = KeystoreConfig {
+            directory: temp_dir().to_string_lossy().to_string(),
+            kek: STANDARD.encode([1u8; 16]),
+            ..Default::default()
+        };
+        assert!(Keystore::from_config(&short_kek, &kms).await.is_err());
+
+        let both = KeystoreConfig {
+            kek: STANDARD.encode([1u8; KEK_LEN]),
+            kek_kms_key_name: "arn:aws:kms:eu-west-1:123456789012:key/kek".to_string(),
+            ..short_kek
+        };
+        assert!(Keystore::from_config(&both, &kms).await.is_err());
+        assert!(!Keystore::is_handle("../../etc/passwd"));
+    }
+
+    #[tokio::test]
+    async fn test_export_is_opt_in() {
+        let kms = KmsSigner::from_config(&KmsConfig::default()).unwrap();
+        let directory = temp_dir();
+        assert!(!keystore(&directory).await.is_export_enabled());
+
+        let exporting = KeystoreConfig {
+            directory: directory.to_string_lossy().to_string(),
+            kek: STANDARD.encode([7u8; KEK_LEN]),
+            export_enabled: true,
+            ..Default::default()
+        };
+        assert!(Keystore::from_config(&exporting, &kms)
+            .await
+            .unwrap()
+            .is_export_enabled());
+
+        let required = KeystoreConfig {
+            required: true,
+            ..exporting
+        };
+        assert!(!Keystore::from_config(&required, &kms)
+            .await
+            .unwrap()
+            .is_export_enabled());
+        std::fs::remove_dir_all(directory).unwrap();
+    }
+}
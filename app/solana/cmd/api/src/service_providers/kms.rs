@@ -0,0 +1,583 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use chrono::{DateTime, Utc};
+use hmac::{Hmac, Mac};
+use serde::Deserialize;
+use serde_json::json;
+use sha2::{Digest, Sha256};
+use solana_sdk::{pubkey::Pubkey, signature::Signature};
+use std::collections::BTreeMap;
+use std::str::FromStr;
+use std::time::Duration;
+use tracing::{info, warn};
+
+use super::gcp_auth::access_token;
+use crate::config::{KmsConfig, KmsKeyConfig};
+
+/// Target of the audit events, so they can be routed apart from the service logs
+pub const AUDIT_TARGET: &str = "kms_audit";
+/// GCP Cloud KMS endpoint used unless overridden
+const DEFAULT_GCP_ENDPOINT: &str = "https://cloudkms.googleapis.com";
+/// How long a single KMS call may take
+const REQUEST_TIMEOUT: Duration = Duration::from_secs(10);
+
+/// Cloud provider holding a KMS key
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum KmsProvider {
+    /// AWS KMS (`ECC_NIST_EDWARDS25519` keys)
+    Aws,
+    /// GCP Cloud KMS (`EC_SIGN_ED25519` keys)
+    Gcp,
+}
+
+impl KmsProvider {
+    /// Parses the `provider` of a key configuration
+    pub fn parse(provider: &str) -> Result<Self, String> {
+        match provider {
+            "aws" => Ok(Self::Aws),
+            "gcp" => Ok(Self::Gcp),
+            other => Err(format!("Unknown KMS provider {other:?}, expected aws or gcp")),
+        }
+    }
+
+    /// Name used in configuration and audit events
+    pub const fn as_str(self) -> &'static str {
+        match self {
+            Self::Aws => "aws",
+            Self::Gcp => "gcp",
+        }
+    }
+}
+
+/// A KMS key and its access policy
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct KmsKey {
+    /// Id requests refer to the key by
+    pub id: String,
+    /// Provider holding the key
+    pub provider: KmsProvider,
+    /// AWS key ARN or GCP key version resource name
+    pub key_name: String,
+    /// Public key of the KMS key
+    pub public_key: Pubkey,
+    /// Authenticated caller ids allowed to sign with the key (empty allows any caller)
+    pub allowed_callers: Vec<String>,
+    /// Programs a signed transaction may invoke (empty allows any program)
+    pub allowed_programs: Vec<Pubkey>,
+}
+
+impl KmsKey {
+    /// Builds a key from its configuration
+    pub fn from_config(id: &str, config: &KmsKeyConfig) -> Result<Self, String> {
+        let provider = KmsProvider::parse(&config.provider)?;
+        if config.key_name.is_empty() {
+            return Err("A key name is required".to_string());
+        }
+        if provider == KmsProvider::Aws {
+            aws_region(&config.key_name)?;
+        }
+        let public_key = Pubkey::from_str(&config.public_key)
+            .map_err(|e| format!("Invalid public key {}: {e}", config.public_key))?;
+        let allowed_programs = config
+            .allowed_programs
+            .iter()
+            .map(|program| {
+                Pubkey::from_str(program).map_err(|e| format!("Invalid program {program}: {e}"))
+            })
+            .collect::<Result<Vec<Pubkey>, String>>()?;
+
+        Ok(Self {
+            id: id.to_string(),
+            provider,
+            key_name: config.key_name.clone(),
+            public_key,
+            allowed_callers: config.allowed_callers.clone(),
+            allowed_programs,
+        })
+    }
+
+    /// Checks the key's policy for `caller_id` signing a transaction that invokes `programs`
+    fn check_policy(&self, caller_id: &str, programs: &[Pubkey]) -> Result<(), String> {
+        if !self.allowed_callers.is_empty()
+            && !self
+                .allowed_callers
+                .iter()
+                .any(|allowed| allowed == caller_id)
+        {
+            return Err(format!("Caller {caller_id} may not sign with KMS key {}", self.id));
+        }
+        if !self.allowed_programs.is_empty() {
+            let denied: Vec<String> = programs
+                .iter()
+                .filter(|program| !self.allowed_programs.contains(program))
+                .map(ToString::to_string)
+                .collect();
+            if !denied.is_empty() {
+                return Err(format!(
+                    "KMS key {} may not sign transactions invoking {}",
+                    self.id,
+                    denied.join(", ")
+                ));
+            }
+        }
+        Ok(())
+    }
+
+    /// Applies the key's access policy, recording a denial in the audit log
+    pub fn authorize(
+        &self,
+        caller_id: &str,
+        programs: &[Pubkey],
+        message: &[u8],
+    ) -> Result<(), String> {
+        self.check_policy(caller_id, programs).map_err(|reason| {
+            warn!(
+                target: AUDIT_TARGET,
+                key_id = %self.id,
+                provider = self.provider.as_str(),
+                public_key = %self.public_key,
+                caller_id = %caller_id,
+                message_sha256 = %hex::encode(Sha256::digest(message)),
+                outcome = "denied",
+                reason = %reason,
+                "KMS signing denied by key policy"
+            );
+            reason
+        })
+    }
+}
+
+/// AWS credentials, read from the standard environment variables on every call so that
+/// rotated session credentials are picked up
+struct AwsCredentials {
+    access_key_id: String,
+    secret_access_key: String,
+    session_token: Option<String>,
+}
+
+impl AwsCredentials {
+    fn from_env() -> Result<Self, String> {
+        let var = |name: &str| {
+            std::env::var(name).map_err(|_| format!("{name} is not set for AWS KMS signing"))
+        };
+        Ok(Self {
+            access_key_id: var("AWS_ACCESS_KEY_ID")?,
+            secret_access_key: var("AWS_SECRET_ACCESS_KEY")?,
+            session_token: std::env::var("AWS_SESSION_TOKEN").ok(),
+        })
+    }
+}
+
+#[derive(Deserialize)]
+struct AwsSignResponse {
+    #[serde(rename = "Signature")]
+    signature: String,
+}
+
+#[derive(Deserialize)]
+struct AwsDecryptResponse {
+    #[serde(rename = "Plaintext")]
+    plaintext: String,
+}
+
+#[derive(Deserialize)]
+struct GcpSignResponse {
+    signature: String,
+}
+
+#[derive(Deserialize)]
+struct GcpDecryptResponse {
+    plaintext: String,
+}
+
+/// Signs with ed25519 keys held in AWS KMS or GCP Cloud KMS.
+///
+/// Signing goes through the provider's API (AWS `Sign` with `ED25519_SHA_512` over the raw
+/// message, GCP `asymmetricSign`), so private key material never reaches this server.
+/// Returned signatures are verified against the configured public key. Every policy
+/// decision and signing call is logged under the `kms_audit` target.
+pub struct KmsSigner {
+    keys: BTreeMap<String, KmsKey>,
+    http: reqwest::Client,
+    aws_endpoint: String,
+    gcp_endpoint: String,
+}
+
+impl KmsSigner {
+    /// Builds the signer from configuration, rejecting invalid keys
+    pub fn from_config(config: &KmsConfig) -> Result<Self, String> {
+        let keys = config
+            .keys
+            .iter()
+            .map(|(id, key)| {
+                KmsKey::from_config(id, key)
+                    .map(|key| (id.clone(), key))
+                    .map_err(|e| format!("KMS key {id}: {e}"))
+            })
+            .collect::<Result<BTreeMap<String, KmsKey>, String>>()?;
+        let http = reqwest::Client::builder()
+            .timeout(REQUEST_TIMEOUT)
+            .build()
+            .map_err(|e| format!("Failed to build KMS HTTP client: {e}"))?;
+
+        Ok(Self {
+            keys,
+            http,
+            aws_endpoint: config.aws_endpoint.trim_end_matches('/').to_string(),
+            gcp_endpoint: if config.gcp_endpoint.is_empty() {
+                DEFAULT_GCP_ENDPOINT.to_string()
+            } else {
+                config.gcp_endpoint.trim_end_matches('/').to_string()
+            },
+        })
+    }
+
+    /// Whether any KMS keys are configured
+    pub const fn is_configured(&self) -> bool {
+        !self.keys.is_empty()
+    }
+
+    /// Returns a configured key by id
+    pub fn key(&self, key_id: &str) -> Option<&KmsKey> {
+        self.keys.get(key_id)
+    }
+
+    /// Signs `message` with `key`, recording the outcome in the audit log.
+    ///
+    /// The caller must have passed `KmsKey::authorize` for the same message.
+    pub async fn sign(
+        &self,
+        key: &KmsKey,
+        caller_id: &str,
+        message: &[u8],
+    ) -> Result<Signature, String> {
+        let message_sha256 = hex::encode(Sha256::digest(message));
+        let result = match key.provider {
+            KmsProvider::Aws => self.aws_sign(key, message).await,
+            KmsProvider::Gcp => self.gcp_sign(key, message).await,
+        }
+        .and_then(|signature| {
+            if signature.verify(key.public_key.as_ref(), message) {
+                Ok(signature)
+            } else {
+                Err(format!("KMS key {} returned a signature that does not verify", key.id))
+            }
+        });
+
+        match &result {
+            Ok(signature) => info!(
+                target: AUDIT_TARGET,
+                key_id = %key.id,
+                provider = key.provider.as_str(),
+                public_key = %key.public_key,
+                caller_id = %caller_id,
+                message_sha256 = %message_sha256,
+                signature = %signature,
+                outcome = "signed",
+                "KMS signing completed"
+            ),
+            Err(reason) => warn!(
+                target: AUDIT_TARGET,
+                key_id = %key.id,
+                provider = key.provider.as_str(),
+                public_key = %key.public_key,
+                caller_id = %caller_id,
+                message_sha256 = %message_sha256,
+                outcome = "failed",
+                reason = %reason,
+                "KMS signing failed"
+            ),
+        }
+        result
+    }
+
+    /// Calls AWS KMS `Sign`
+    async fn aws_sign(&self, key: &KmsKey, message: &[u8]) -> Result<Signature, String> {
+        let body = json!({
+            "KeyId": key.key_name,
+            "Message": STANDARD.encode(message),
+            "MessageType": "RAW",
+            "SigningAlgorithm": "ED25519_SHA_512",
+        });
+        let response: AwsSignResponse = self
+            .aws_call("TrentService.Sign", &key.key_name, &body)
+            .await?;
+        decode_signature(&response.signature)
+    }
+
+    /// Calls an AWS KMS action on the key `key_arn`, authenticated with Signature Version 4
+    async fn aws_call<T: serde::de::DeserializeOwned>(
+        &self,
+        target: &str,
+        key_arn: &str,
+        body: &serde_json::Value,
+    ) -> Result<T, String> {
+        let region = aws_region(key_arn)?;
+        let endpoint = if self.aws_endpoint.is_empty() {
+            format!("https://kms.{region}.amazonaws.com")
+        } else {
+            self.aws_endpoint.clone()
+        };
+        let host = reqwest::Url::parse(&endpoint)
+            .ok()
+            .and_then(|url| {
+                url.host_str().map(|host| match url.port() {
+                    Some(port) => format!("{host}:{port}"),
+                    None => host.to_string(),
+                })
+            })
+            .ok_or_else(|| format!("Invalid AWS KMS endpoint {endpoint}"))?;
+
+        let body = body.to_string();
+        let credentials = AwsCredentials::from_env()?;
+        let headers = sigv4_headers(&credentials, region, &host, target, &body, Utc::now())?;
+
+        let mut request = self.http.post(&endpoint).body(body);
+        for (name, value) in headers {
+            request = request.header(name, value);
+        }
+        send(request, &format!("AWS KMS {target}")).await
+    }
+
+    /// Calls GCP Cloud KMS `asymmetricSign` on the key version
+    async fn gcp_sign(&self, key: &KmsKey, message: &[u8]) -> Result<Signature, String> {
+        let token = access_token(&self.http).await?;
+        let request = self
+            .http
+            .post(format!("{}/v1/{}:asymmetricSign", self.gcp_endpoint, key.key_name))
+            .bearer_auth(token)
+            .json(&json!({ "data": STANDARD.encode(message) }));
+        let response: GcpSignResponse = send(request, "GCP KMS asymmetricSign").await?;
+        decode_signature(&response.signature)
+    }
+
+    /// Decrypts `ciphertext` with the symmetric KMS key `key_name` (an AWS key ARN or a
+    /// GCP crypto key resource name), for unwrapping data keys. Unlike signing keys, these
+    /// keys need not be configured under `keys`.
+    pub async fn decrypt(
+        &self,
+        provider: KmsProvider,
+        key_name: &str,
+        ciphertext: &[u8],
+    ) -> Result<Vec<u8>, String> {
+        let plaintext = match provider {
+            KmsProvider::Aws => {
+                let body = json!({
+                    "KeyId": key_name,
+                    "CiphertextBlob": STANDARD.encode(ciphertext),
+                });
+                let response: AwsDecryptResponse = self
+                    .aws_call("TrentService.Decrypt", key_name, &body)
+                    .await?;
+                response.plaintext
+            }
+            KmsProvider::Gcp => {
+                let token = access_token(&self.http).await?;
+                let request = self
+                    .http
+                    .post(format!("{}/v1/{key_name}:decrypt", self.gcp_endpoint))
+                    .bearer_auth(token)
+                    .json(&json!({ "ciphertext": STANDARD.encode(ciphertext) }));
+                let response: GcpDecryptResponse = send(request, "GCP KMS decrypt").await?;
+                response.plaintext
+            }
+        };
+        STANDARD
+            .decode(plaintext)
+            .map_err(|e| format!("KMS returned an invalid plaintext: {e}"))
+    }
+}
+
+impl std::fmt::Debug for KmsSigner {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("KmsSigner")
+            .field("keys", &self.keys.keys().collect::<Vec<_>>())
+            .finish_non_exhaustive()
+    }
+}
+
+/// Sends a KMS request, surfacing the provider's error body on failure
+async fn send<T: serde::de::DeserializeOwned>(
+    request: reqwest::RequestBuilder,
+    call: &str,
+) -> Result<T, String> {
+    let response = request
+        .send()
+        .await
+        .map_err(|e| format!("{call} failed: {e}"))?;
+    let status = response.status();
+    let body = response
+        .text()
+        .await
+        .map_err(|e| format!("{call} failed: {e}"))?;
+    if !status.is_success() {
+        return Err(format!("{call} failed with {status}: {body}"));
+    }
+    serde_json::from_str(&body).map_err(|e| format!("{call} returned an invalid response: {e}"))
+}
+
+/// Decodes a base64 ed25519 signature returned by a KMS
+fn decode_signature(signature: &str) -> Result<Signature, String> {
+    let bytes = STANDARD
+        .decode(signature)
+        .map_err(|e| format!("KMS returned an invalid signature: {e}"))?;
+    Signature::try_from(bytes.as_slice())
+        .map_err(|_| format!("KMS returned a {} byte signature, expected 64", bytes.len()))
+}
+
+/// Region of an AWS key ARN (`arn:aws:kms:<region>:<account>:key/<id>`)
+fn aws_region(key_arn: &str) -> Result<&str, String> {
+    match key_arn.split(':').collect::<Vec<_>>().as_slice() {
+        ["arn", _, "kms", region, _, resource] if !region.is_empty() && !resource.is_empty() => {
+            Ok(*region)
+        }
+        _ => Err(format!("AWS key name must be a key ARN, got {key_arn}")),
+    }
+}
+
+fn hmac_sha256(key: &[u8], data: &str) -> Result<Vec<u8>, String> {
+    let mut mac =
+        Hmac::<Sha256>::new_from_slice(key).map_err(|e| format!("Invalid HMAC key: {e}"))?;
+    mac.update(data.as_bytes());
+    Ok(mac.finalize().into_bytes().to_vec())
+}
+
+/// Headers of a Signature Version 4 signed AWS KMS request for the action `target`
+fn sigv4_headers(
+    credentials: &AwsCredentials,
+    region: &str,
+    host: &str,
+    target: &str,
+    body: &str,
+    now: DateTime<Utc>,
+) -> Result<Vec<(&'static str, String)>, String> {
+    let amz_date = now.format("%Y%m%dT%H%M%SZ").to_string();
+    let date = now.format("%Y%m%d").to_string();
+    let mut headers = vec![
+        ("content-type", "application/x-amz-json-1.1".to_string()),
+        ("host", host.to_string()),
+        ("x-amz-date", amz_date.clone()),
+    ];
+    if let Some(token) = &credentials.session_token {
+        headers.push(("x-amz-security-token", token.clone()));
+    }
+    headers.push(("x-amz-target", target.to_string()));
+
+    let signed_headers = headers
+        .iter()
+        .map(|(name, _)| *name)
+        .collect::<Vec<_>>()
+        .join(";");
+    let canonical_headers: String = headers
+        .iter()
+        .map(|(name, value)| format!("{name}:{value}\n"))
+        .collect();
+    let canonical_request = format!(
+        "POST\n/\n\n{canonical_headers}\n{signed_headers}\n{}",
+        hex::encode(Sha256::digest(body.as_bytes()))
+    );
+    let scope = format!("{date}/{region}/kms/aws4_request");
+    let string_to_sign = format!(
+        "AWS4-HMAC-SHA256\n{amz_date}\n{scope}\n{}",
+        hex::encode(Sha256::digest(canonical_request.as_bytes()))
+    );
+
+    let mut signing_key = format!("AWS4{}", credentials.secret_access_key).into_bytes();
+    for part in [date.as_str(), region, "kms", "aws4_request"] {
+        signing_key = hmac_sha256(&signing_key, part)?;
+    }
+    let signature = hex::encode(hmac_sha256(&signing_key, &string_to_sign)?);
+
+    headers.push((
+        "authorization",
+        format!(
+            "AWS4-HMAC-SHA256 Credential={}/{scope}, SignedHeaders={signed_headers}, Signature={signature}",
+            credentials.access_key_id
+        ),
+    ));
+    Ok(headers)
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use chrono::TimeZone;
+
+    fn key_config(provider: &str, key_name: &str) -> KmsKeyConfig {
+        KmsKeyConfig {
+            provider: provider.to_string(),
+            key_name: key_name.to_string(),
+            public_key: Pubkey::new_unique().to_string(),
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_key_configuration_is_validated() {
+        let arn = "arn:aws:kms:eu-west-1:111122223333:key/1234abcd";
+        assert!(KmsKey::from_config("treasury", &key_config("aws", arn)).is_ok());
+        assert!(KmsKey::from_config("treasury", &key_config("aws", "1234abcd")).is_err());
+        assert!(KmsKey::from_config("treasury", &key_config("azure", arn)).is_err());
+        assert!(KmsKey::from_config("treasury", &key_config("gcp", "")).is_err());
+        assert_eq!(aws_region(arn).unwrap(), "eu-west-1");
+    }
+
+    #[test]
+    fn test_policy_limits_callers_and_programs() {
+        let allowed_program = Pubkey::new_unique();
+        let mut config = key_config(
+            "gcp",
+            "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1",
+        );
+        config.allowed_callers = vec!["payments".to_string()];
+        config.allowed_programs = vec![allowed_program.to_string()];
+        let key = KmsKey::from_config("payments", &config).unwrap();
+
+        assert!(key.check_policy("payments", &[allowed_program]).is_ok());
+        assert!(key.check_policy("reporting", &[allowed_program]).is_err());
+        assert!(key
+            .check_policy("payments", &[allowed_program, Pubkey::new_unique()])
+            .is_err());
+    }
+
+    #[test]
+    fn test_sigv4_headers_sign_the_request() {
+        let credentials = AwsCredentials {
+            access_key_id: "AKIDEXAMPLE".to_string(),
+            secret_access_key: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY".to_string(),
+            session_token: None,
+        };
+        let now = Utc.with_ymd_and_hms(2026, 1, 2, 3, 4, 5).unwrap();
+        let headers = sigv4_headers(
+            &credentials,
+            "eu-west-1",
+            "kms.eu-west-1.amazonaws.com",
+            "TrentService.Sign",
+            "{}",
+            now,
+        )
+        .unwrap();
+
+        let authorization = &headers
+            .iter()
+            .find(|(name, _)| *name == "authorization")
+            .unwrap()
+            .1;
+        assert!(authorization.starts_with(
+            "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260102/eu-west-1/kms/aws4_request, \
+             SignedHeaders=content-type;host;x-amz-date;x-amz-target, Signature="
+        ));
+        assert_eq!(
+            headers,
+            sigv4_headers(
+                &credentials,
+                "eu-west-1",
+                "kms.eu-west-1.amazonaws.com",
+                "TrentService.Sign",
+                "{}",
+                now,
+            )
+            .unwrap()
+        );
+    }
+}
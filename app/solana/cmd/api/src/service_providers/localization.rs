@@ -0,0 +1,452 @@
+use std::collections::{HashMap, HashSet};
+use std::fmt::Display;
+use std::path::Path;
+use tonic::metadata::{MetadataMap, MetadataValue};
+use tonic::Response;
+
+use crate::config::LocalizationConfig;
+
+/// Request metadata naming the caller's preferred locales, in HTTP `Accept-Language` form
+pub const ACCEPT_LANGUAGE_HEADER: &str = "accept-language";
+
+/// Response metadata naming the locale human-readable fields were rendered in
+pub const CONTENT_LANGUAGE_HEADER: &str = "content-language";
+
+/// Locale of the built-in messages
+pub const BUILTIN_LOCALE: &str = "en";
+
+/// The built-in English messages. Every key a catalog may translate is listed here, with
+/// the `{placeholders}` its translations may use.
+const BUILTIN_MESSAGES: &[(&str, &str)] = &[
+    // Amounts embedded in the descriptions below
+    ("amount.sol", "{amount} SOL"),
+    ("amount.token", "{amount} of mint {mint}"),
+    ("amount.token_base_units", "{amount} base units of mint {mint}"),
+    ("amount.base_units", "{amount} base units"),
+    // DescribeTransaction instruction summaries
+    ("describe.system.transfer", "Transfer {amount} from {source} to {destination}"),
+    (
+        "describe.system.create_account",
+        "Create account {account} with {amount} and {space} bytes owned by {owner}, paid by {payer}",
+    ),
+    (
+        "describe.system.withdraw_nonce",
+        "Withdraw {amount} from nonce account {nonce_account} to {destination}",
+    ),
+    ("describe.system.advance_nonce", "Advance nonce account {nonce_account}"),
+    (
+        "describe.system.initialize_nonce",
+        "Initialize nonce account {nonce_account} with authority {authority}",
+    ),
+    (
+        "describe.system.authorize_nonce",
+        "Set the authority of nonce account {nonce_account} to {authority}",
+    ),
+    ("describe.system.assign", "Assign account {account} to {owner}"),
+    ("describe.system.allocate", "Allocate {space} bytes for account {account}"),
+    ("describe.token.transfer", "Transfer {amount} from {source} to {destination}"),
+    ("describe.token.mint_to", "Mint {amount} to {destination}"),
+    ("describe.token.burn", "Burn {amount} from {source}"),
+    ("describe.token.approve", "Allow {delegate} to spend {amount} from {source}"),
+    ("describe.token.revoke", "Revoke the delegate of token account {account}"),
+    (
+        "describe.token.close_account",
+        "Close token account {account}, sending its rent to {destination}",
+    ),
+    ("describe.token.freeze_account", "Freeze token account {account}"),
+    ("describe.token.thaw_account", "Thaw token account {account}"),
+    (
+        "describe.token.initialize_mint",
+        "Initialize mint {mint} with {decimals} decimals and mint authority {authority}",
+    ),
+    (
+        "describe.token.initialize_account",
+        "Initialize token account {account} for mint {mint} owned by {owner}",
+    ),
+    ("describe.token.remove_authority", "Remove an authority of {account}"),
+    ("describe.token.set_authority", "Change an authority of {account} to {authority}"),
+    ("describe.token.sync_native", "Sync the SOL balance of wrapped SOL account {account}"),
+    (
+        "describe.associated_token.create",
+        "Create associated token account {account} for {wallet} and mint {mint}, paid by {payer}",
+    ),
+    ("describe.compute_budget.set_compute_unit_limit", "Set the compute unit limit to {units}"),
+    (
+        "describe.compute_budget.set_compute_unit_price",
+        "Set the priority fee to {price} micro-lamports per compute unit",
+    ),
+    ("describe.compute_budget.request_heap_frame", "Request a {bytes}-byte heap"),
+    (
+        "describe.compute_budget.set_loaded_accounts_data_size_limit",
+        "Limit loaded account data to {bytes} bytes",
+    ),
+    ("describe.memo", "Memo: \"{text}\""),
+    ("describe.call", "Call {program}"),
+    ("describe.action", "{action} ({program})"),
+    // System program instruction descriptions
+    (
+        "instruction.system.create",
+        "Create account: {new_account} (payer: {payer}, owner: {owner}, lamports: {lamports}, space: {space})",
+    ),
+    ("instruction.system.default_owner", "system program (default)"),
+    ("instruction.system.transfer", "Transfer {lamports} lamports from {from} to {to}"),
+    ("instruction.system.allocate", "Allocate {space} bytes for account {account}"),
+    ("instruction.system.assign", "Assign account {account} to program {owner}"),
+    (
+        "instruction.system.create_with_seed",
+        "Create account: {new_account} from base {base} and seed \"{seed}\" (payer: {payer}, owner: {owner}, lamports: {lamports}, space: {space})",
+    ),
+    (
+        "instruction.system.allocate_with_seed",
+        "Allocate {space} bytes for account {account} from base {base} and seed \"{seed}\" (owner: {owner})",
+    ),
+    (
+        "instruction.system.assign_with_seed",
+        "Assign account {account} from base {base} and seed \"{seed}\" to program {owner}",
+    ),
+    (
+        "instruction.system.transfer_with_seed",
+        "Transfer {lamports} lamports from {from} (base {base}, seed \"{seed}\") to {to}",
+    ),
+    // Structured submission errors; a key per code, e.g. transaction_error.INSUFFICIENT_FUNDS,
+    // may be translated to replace this generic message for that code
+    ("transaction_error", "Transaction submission failed: {detail}"),
+];
+
+/// Prefix of the per-code keys that may override `transaction_error`
+const TRANSACTION_ERROR_CODE_PREFIX: &str = "transaction_error.";
+
+/// Human-readable messages in every configured locale.
+///
+/// Machine-readable fields (codes, enum values, addresses, amounts) are never localized;
+/// only free-text fields such as instruction descriptions, transaction summaries and
+/// error messages are rendered from the catalog. Callers name the locales they want in
+/// `accept-language` request metadata; responses say which one was used in
+/// `content-language`. Messages missing from a locale fall back to the default locale
+/// and then to the built-in English.
+pub struct MessageCatalog {
+    default_locale: String,
+    locales: HashMap<String, HashMap<String, String>>,
+}
+
+impl MessageCatalog {
+    /// A catalog of the built-in English messages only
+    pub fn builtin() -> Self {
+        let english = BUILTIN_MESSAGES
+            .iter()
+            .map(|(key, template)| ((*key).to_string(), (*template).to_string()))
+            .collect();
+        Self {
+            default_locale: BUILTIN_LOCALE.to_string(),
+            locales: HashMap::from([(BUILTIN_LOCALE.to_string(), english)]),
+        }
+    }
+
+    /// Builds the catalog from configuration, loading one `<locale>.json` file of
+    /// key-to-template translations per locale from the catalog directory
+    pub fn from_config(config: &LocalizationConfig) -> Result<Self, String> {
+        let mut catalog = Self::builtin();
+
+        if !config.catalog_directory.is_empty() {
+            let entries = std::fs::read_dir(&config.catalog_directory).map_err(|e| {
+                format!("Failed to read catalog directory {}: {e}", config.catalog_directory)
+            })?;
+            for entry in entries {
+                let path = entry
+                    .map_err(|e| format!("Failed to read catalog entry: {e}"))?
+                    .path();
+                if path.extension().and_then(|ext| ext.to_str()) != Some("json") {
+                    continue;
+                }
+                let locale = path
+                    .file_stem()
+                    .and_then(|stem| stem.to_str())
+                    .map(normalize_locale)
+                    .ok_or_else(|| format!("Invalid catalog file name {}", path.display()))?;
+                let translations = read_translations(&path)?;
+                catalog.add_locale(&locale, translations)?;
+            }
+        }
+
+        let default_locale = normalize_locale(&config.default_locale);
+        if !default_locale.is_empty() {
+            if !catalog.locales.contains_key(&default_locale) {
+                return Err(format!("Default locale {default_locale} has no catalog"));
+            }
+            catalog.default_locale = default_locale;
+        }
+        Ok(catalog)
+    }
+
+    /// Adds (or extends) a locale's translations, rejecting unknown keys and templates that
+    /// use placeholders their English message does not provide
+    pub fn add_locale(
+        &mut self,
+        locale: &str,
+        translations: HashMap<String, String>,
+    ) -> Result<(), String> {
+        let locale = normalize_locale(locale);
+        for (key, template) in &translations {
+            let english_key = if key.starts_with(TRANSACTION_ERROR_CODE_PREFIX) {
+                "transaction_error"
+            } else {
+                key.as_str()
+            };
+            let english = BUILTIN_MESSAGES
+                .iter()
+                .find(|(builtin, _)| *builtin == english_key)
+                .map(|(_, english)| *english)
+                .ok_or_else(|| format!("Locale {locale}: unknown message key {key}"))?;
+            let allowed = placeholders(english);
+            if let Some(unknown) = placeholders(template).difference(&allowed).next() {
+                return Err(format!(
+                    "Locale {locale}: message {key} uses unknown placeholder {{{unknown}}}"
+                ));
+            }
+        }
+        self.locales.entry(locale).or_default().extend(translations);
+        Ok(())
+    }
+
+    /// Locales the catalog can render, sorted
+    pub fn locales(&self) -> Vec<String> {
+        let mut locales: Vec<String> = self.locales.keys().cloned().collect();
+        locales.sort();
+        locales
+    }
+
+    /// Picks the locale to answer in from an `Accept-Language` value: the highest-weighted
+    /// language with a catalog, matched exactly or by its primary subtag ("pt-BR" matches a
+    /// "pt" catalog), else the default locale
+    pub fn negotiate(&self, accept_language: Option<&str>) -> &str {
+        let Some(accept_language) = accept_language else {
+            return &self.default_locale;
+        };
+
+        let mut ranges: Vec<(String, f32)> = accept_language
+            .split(',')
+            .filter_map(|range| {
+                let mut parts = range.split(';');
+                let tag = normalize_locale(parts.next()?);
+                let weight = parts
+                    .find_map(|param| param.trim().strip_prefix("q="))
+                    .map_or(Some(1.0), |q| q.trim().parse::<f32>().ok())?;
+                (!tag.is_empty() && weight > 0.0).then_some((tag, weight))
+            })
+            .collect();
+        // Stable, so equally weighted languages keep the caller's order
+        ranges.sort_by(|a, b| b.1.total_cmp(&a.1));
+
+        for (tag, _) in &ranges {
+            if tag == "*" {
+                return &self.default_locale;
+            }
+            if let Some((locale, _)) = self.locales.get_key_value(tag) {
+                return locale;
+            }
+            let primary = tag.split('-').next().unwrap_or_default();
+            if let Some((locale, _)) = self.locales.get_key_value(primary) {
+                return locale;
+            }
+        }
+        &self.default_locale
+    }
+
+    /// Renders messages in the locale negotiated from a request's metadata
+    pub fn localizer(&self, metadata: &MetadataMap) -> Localizer<'_> {
+        let accept_language = metadata
+            .get(ACCEPT_LANGUAGE_HEADER)
+            .and_then(|value| value.to_str().ok());
+        self.localizer_for(self.negotiate(accept_language))
+    }
+
+    /// Renders messages in `locale`, falling back as usual for messages it lacks
+    pub fn localizer_for<'a>(&'a self, locale: &'a str) -> Localizer<'a> {
+        Localizer {
+            catalog: self,
+            locale,
+        }
+    }
+
+    /// The template of `key` in `locale`, falling back to the default locale and then to
+    /// the built-in English
+    fn template(&self, locale: &str, key: &str) -> Option<&str> {
+        [locale, self.default_locale.as_str(), BUILTIN_LOCALE]
+            .into_iter()
+            .find_map(|locale| self.locales.get(locale)?.get(key))
+            .map(String::as_str)
+    }
+}
+
+/// Renders catalog messages in one negotiated locale
+#[derive(Clone, Copy)]
+pub struct Localizer<'a> {
+    catalog: &'a MessageCatalog,
+    locale: &'a str,
+}
+
+impl Localizer<'_> {
+    /// The locale messages are rendered in
+    pub const fn locale(&self) -> &str {
+        self.locale
+    }
+
+    /// Renders the message `key`, substituting each `{name}` placeholder with its argument.
+    /// Unknown keys render as the key itself, so a missing message is visible but harmless.
+    pub fn text(&self, key: &str, args: &[(&str, &dyn Display)]) -> String {
+        let Some(template) = self.catalog.template(self.locale, key) else {
+            return key.to_string();
+        };
+        render(template, args)
+    }
+
+    /// Renders a structured submission error message for an error code, preferring a
+    /// translation specific to the code over the generic message
+    pub fn transaction_error(&self, code: &str, detail: &str) -> String {
+        let specific = format!("{TRANSACTION_ERROR_CODE_PREFIX}{code}");
+        let key = if self.catalog.template(self.locale, &specific).is_some() {
+            specific.as_str()
+        } else {
+            "transaction_error"
+        };
+        self.text(key, &[("detail", &detail)])
+    }
+
+    /// Reports the locale in a response's `content-language` metadata
+    pub fn set_content_language<T>(&self, response: &mut Response<T>) {
+        if let Ok(value) = MetadataValue::try_from(self.locale) {
+            response
+                .metadata_mut()
+                .insert(CONTENT_LANGUAGE_HEADER, value);
+        }
+    }
+}
+
+/// Lowercases a locale tag and uses `-` as its separator ("pt_BR" becomes "pt-br")
+fn normalize_locale(locale: &str) -> String {
+    locale.trim().replace('_', "-").to_lowercase()
+}
+
+/// Reads a flat JSON object of key-to-template translations
+fn read_translations(path: &Path) -> Result<HashMap<String, String>, String> {
+    let contents = std::fs::read_to_string(path)
+        .map_err(|e| format!("Failed to read catalog {}: {e}", path.display()))?;
+    serde_json::from_str(&contents).map_err(|e| format!("Invalid catalog {}: {e}", path.display()))
+}
+
+/// Names of the `{placeholders}` in a template
+fn placeholders(template: &str) -> HashSet<&str> {
+    let mut names = HashSet::new();
+    let mut rest = template;
+    while let Some(start) = rest.find('{') {
+        rest = &rest[start + 1..];
+        let Some(end) = rest.find('}') else {
+            break;
+        };
+        let name = &rest[..end];
+        if !name.is_empty() && name.chars().all(|c| c.is_ascii_lowercase() || c == '_') {
+            names.insert(name);
+            rest = &rest[end + 1..];
+        }
+    }
+    names
+}
+
+/// Substitutes each `{name}` placeholder of a template with its argument
+fn render(template: &str, args: &[(&str, &dyn Display)]) -> String {
+    args.iter()
+        .fold(template.to_string(), |text, (name, value)| {
+            text.replace(&format!("{{{name}}}"), &value.to_string())
+        })
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn catalog_with_german() -> MessageCatalog {
+        let mut catalog = MessageCatalog::builtin();
+        catalog
+            .add_locale(
+                "de",
+                HashMap::from([
+                    (
+                        "describe.system.transfer".to_string(),
+                        "Überweise {amount} von {source} an {destination}".to_string(),
+                    ),
+                    (
+                        "transaction_error.INSUFFICIENT_FUNDS".to_string(),
+                        "Unzureichendes Guthaben: {detail}".to_string(),
+                    ),
+                ]),
+            )
+            .unwrap();
+        catalog
+    }
+
+    #[test]
+    fn test_negotiation_honors_weights_and_primary_subtags() {
+        let catalog = catalog_with_german();
+        assert_eq!(catalog.negotiate(None), "en");
+        assert_eq!(catalog.negotiate(Some("de-CH, en;q=0.5")), "de");
+        assert_eq!(catalog.negotiate(Some("en;q=0.5, de;q=0.9")), "de");
+        assert_eq!(catalog.negotiate(Some("fr, de;q=0")), "en");
+        assert_eq!(catalog.negotiate(Some("fr, *;q=0.1")), "en");
+        assert_eq!(catalog.negotiate(Some("DE_de")), "de");
+    }
+
+    #[test]
+    fn test_messages_fall_back_to_english() {
+        let catalog = catalog_with_german();
+        let german = catalog.localizer_for("de");
+        let args: &[(&str, &dyn Display)] = &[
+            ("amount", &"1 SOL"),
+            ("source", &"A"),
+            ("destination", &"B"),
+        ];
+        assert_eq!(german.text("describe.system.transfer", args), "Überweise 1 SOL von A an B");
+        assert_eq!(
+            german.text("describe.memo", &[("text", &"hi")]),
+            "Memo: \"hi\"",
+            "untranslated messages are English"
+        );
+        assert_eq!(
+            catalog
+                .localizer_for("en")
+                .text("describe.system.transfer", args),
+            "Transfer 1 SOL from A to B"
+        );
+    }
+
+    #[test]
+    fn test_transaction_errors_prefer_code_specific_messages() {
+        let catalog = catalog_with_german();
+        let german = catalog.localizer_for("de");
+        assert_eq!(
+            german.transaction_error("INSUFFICIENT_FUNDS", "0x1"),
+            "Unzureichendes Guthaben: 0x1"
+        );
+        assert_eq!(
+            german.transaction_error("NETWORK_ERROR", "reset"),
+            "Transaction submission failed: reset"
+        );
+    }
+
+    #[test]
+    fn test_translations_are_validated() {
+        let mut catalog = MessageCatalog::builtin();
+        let unknown_key = HashMap::from([("nope".to_string(), "x".to_string())]);
+        assert!(catalog.add_locale("fr", unknown_key).is_err());
+        let unknown_placeholder =
+            HashMap::from([("describe.memo".to_string(), "Mémo : {texte}".to_string())]);
+        assert!(catalog.add_locale("fr", unknown_placeholder).is_err());
+        assert_eq!(catalog.locales(), vec!["en"]);
+    }
+
+    #[test]
+    fn test_builtin_messages_are_unique() {
+        let keys: HashSet<&str> = BUILTIN_MESSAGES.iter().map(|(key, _)| *key).collect();
+        assert_eq!(keys.len(), BUILTIN_MESSAGES.len());
+    }
+}
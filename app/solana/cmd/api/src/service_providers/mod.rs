@@ -1,6 +1,71 @@
+/// Admission control that queues bursts of submissions
+pub mod admission;
+/// Registered owners whose missing associated token accounts are created on demand
+pub mod ata_watcher;
+/// Credentials required by operator-only RPCs
+pub mod auth;
+/// Balance threshold rules notified through the webhook sink
+pub mod balance_alerts;
 /// Main service provider container
 pub mod container;
+/// Dead-letter store for failed managed submissions
+pub mod dead_letters;
+/// Export of submission events to columnar analytics sinks
+pub mod event_export;
+/// Config-driven feature flags with runtime toggles
+pub mod feature_flags;
+/// Access tokens for Google Cloud APIs
+pub mod gcp_auth;
+/// JSON-RPC client for the companion agent of locally connected Ledger devices
+pub mod hardware_wallet;
+/// Dedupe cache for idempotent transaction submission
+pub mod idempotency;
+/// JSON-RPC client for a Jito block engine
+pub mod jito;
+/// Server-held signing keys addressed by alias
+pub mod key_vault;
+/// Keys generated on request, encrypted at rest and addressed by handle
+pub mod keystore;
+/// Signing with ed25519 keys held in AWS KMS or GCP Cloud KMS
+pub mod kms;
+/// Message catalogs for human-readable response fields in the caller's locale
+pub mod localization;
+/// Status and cancellation of long-running orchestrations
+pub mod operations;
+/// Progress of post-submission rebroadcast loops
+pub mod rebroadcasts;
+/// Background collection of expired records from in-memory stores
+pub mod retention;
+/// Concurrency limits on outbound Solana RPC calls
+pub mod rpc_limits;
+/// Short-lived cache of simulation results keyed by message hash
+pub mod simulation_cache;
 /// Solana RPC client providers
 pub mod solana_clients;
+/// Server-held fee payer pool for sponsored transactions
+pub mod sponsorship;
+/// Single-use tokens pre-authorizing one submission
+pub mod submission_tokens;
+/// Tagged record of recent submissions
+pub mod submissions;
+/// Saved transaction templates
+pub mod templates;
+/// Fetching and caching of off-chain token metadata documents
+pub mod token_metadata;
+/// Server-side queue of signed transactions awaiting rate-limited dispatch
+pub mod transaction_queue;
+/// Signing with ed25519 keys held in HashiCorp Vault's transit engine
+pub mod vault;
+/// Delivery of signed event notifications to a webhook endpoint
+pub mod webhooks;
 
 pub use container::ServiceProviders;
+
+use std::time::{SystemTime, UNIX_EPOCH};
+
+/// Current unix time in seconds, used to timestamp records held by service providers
+pub fn unix_timestamp() -> i64 {
+    SystemTime::now()
+        .duration_since(UNIX_EPOCH)
+        .map_or(0, |elapsed| i64::try_from(elapsed.as_secs()).unwrap_or(i64::MAX))
+}
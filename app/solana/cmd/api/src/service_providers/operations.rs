@@ -0,0 +1,269 @@
+use dashmap::DashMap;
+use std::collections::HashMap;
+
+use protochain_api::protochain::solana::operations::v1::{Operation, OperationState};
+
+use super::unix_timestamp;
+
+/// How long a finished operation stays visible to `GetOperation`
+pub const DEFAULT_OPERATION_RETENTION_SECONDS: i64 = 3_600;
+/// Default number of operations tracked before finished ones are evicted
+pub const DEFAULT_MAX_OPERATIONS: usize = 10_000;
+
+/// Tracks long-running orchestrations by operation id.
+///
+/// The orchestration registers itself with `start`, reports progress as it goes and
+/// finishes with `complete`, `fail` or `cancelled`. Cancellation is cooperative:
+/// `request_cancel` only flags the operation, and the orchestration checks
+/// `is_cancel_requested` at its own checkpoints. Finished operations are kept for a
+/// retention period, then evicted.
+pub struct OperationStore {
+    operations: DashMap<String, Operation>,
+    retention_seconds: i64,
+    max_operations: usize,
+}
+
+impl OperationStore {
+    /// Creates an empty store that keeps finished operations for `retention_seconds`
+    pub fn new(retention_seconds: i64, max_operations: usize) -> Self {
+        Self {
+            operations: DashMap::new(),
+            retention_seconds,
+            max_operations: max_operations.max(1),
+        }
+    }
+
+    /// Registers a running operation of `kind` acting on `target` and returns its id
+    pub fn start(&self, kind: &str, target: &str, total_steps: u32) -> Result<String, String> {
+        let now = unix_timestamp();
+        if self.operations.len() >= self.max_operations {
+            self.evict(now);
+            if self.operations.len() >= self.max_operations {
+                return Err("Too many operations are running".to_string());
+            }
+        }
+
+        let id = uuid::Uuid::new_v4().to_string();
+        self.operations.insert(
+            id.clone(),
+            Operation {
+                id: id.clone(),
+                kind: kind.to_string(),
+                target: target.to_string(),
+                state: OperationState::Running.into(),
+                total_steps,
+                created_at: now,
+                updated_at: now,
+                ..Default::default()
+            },
+        );
+        Ok(id)
+    }
+
+    /// Records progress of a running operation
+    pub fn progress(&self, id: &str, completed_steps: u32, message: &str) {
+        self.update(id, |operation| {
+            operation.completed_steps = completed_steps;
+            operation.message = message.to_string();
+        });
+    }
+
+    /// Records a transaction sent by a running operation
+    pub fn record_signature(&self, id: &str, signature: &str) {
+        self.update(id, |operation| operation.signatures.push(signature.to_string()));
+    }
+
+    /// Finishes an operation successfully with kind-specific outputs
+    pub fn complete(&self, id: &str, message: &str, result: HashMap<String, String>) {
+        self.update(id, |operation| {
+            finish(operation, OperationState::Succeeded, message);
+            operation.result = result;
+        });
+    }
+
+    /// Finishes an operation with an error
+    pub fn fail(&self, id: &str, error: &str) {
+        self.update(id, |operation| {
+            finish(operation, OperationState::Failed, "");
+            operation.error = error.to_string();
+        });
+    }
+
+    /// Finishes an operation that stopped because cancellation was requested
+    pub fn cancelled(&self, id: &str) {
+        self.update(id, |operation| {
+            finish(operation, OperationState::Cancelled, "Cancelled");
+        });
+    }
+
+    /// Flags a running operation for cancellation, returning its state (`None` if unknown).
+    /// Finished operations are returned unchanged.
+    pub fn request_cancel(&self, id: &str) -> Option<Operation> {
+        let mut operation = self.operations.get_mut(id)?;
+        if !operation.done {
+            operation.cancel_requested = true;
+            operation.updated_at = unix_timestamp();
+        }
+        Some(operation.clone())
+    }
+
+    /// Whether cancellation of the operation was requested
+    pub fn is_cancel_requested(&self, id: &str) -> bool {
+        self.operations
+            .get(id)
+            .is_some_and(|operation| operation.cancel_requested)
+    }
+
+    /// Returns an operation by id
+    pub fn get(&self, id: &str) -> Option<Operation> {
+        self.operations.get(id).map(|operation| operation.clone())
+    }
+
+    /// Lists operations newest first, optionally filtered by kind and target
+    pub fn list(
+        &self,
+        kind: Option<&str>,
+        target: Option<&str>,
+        running_only: bool,
+    ) -> Vec<Operation> {
+        let mut operations: Vec<Operation> = self
+            .operations
+            .iter()
+            .filter(|operation| kind.map_or(true, |kind| operation.kind == kind))
+            .filter(|operation| target.map_or(true, |target| operation.target == target))
+            .filter(|operation| !(running_only && operation.done))
+            .map(|operation| operation.clone())
+            .collect();
+        operations.sort_by(|a, b| b.created_at.cmp(&a.created_at).then(a.id.cmp(&b.id)));
+        operations
+    }
+
+    /// Applies `change` to an operation that is still running
+    fn update<F: FnOnce(&mut Operation)>(&self, id: &str, change: F) {
+        if let Some(mut operation) = self.operations.get_mut(id) {
+            if !operation.done {
+                change(&mut operation);
+                operation.updated_at = unix_timestamp();
+            }
+        }
+    }
+
+    /// Number of operations currently tracked
+    pub fn len(&self) -> usize {
+        self.operations.len()
+    }
+
+    /// Whether no operations are tracked
+    pub fn is_empty(&self) -> bool {
+        self.operations.is_empty()
+    }
+
+    /// Drops finished operations older than the retention period, returning how many were
+    /// dropped
+    pub fn purge_expired(&self) -> usize {
+        let before = self.operations.len();
+        self.evict(unix_timestamp());
+        before.saturating_sub(self.operations.len())
+    }
+
+    /// Drops finished operations older than the retention period
+    fn evict(&self, now: i64) {
+        self.operations.retain(|_, operation| {
+            !operation.done || now - operation.updated_at < self.retention_seconds
+        });
+    }
+}
+
+/// Moves an operation into a terminal state
+fn finish(operation: &mut Operation, state: OperationState, message: &str) {
+    operation.state = state.into();
+    operation.done = true;
+    operation.cancel_requested = false;
+    if !message.is_empty() {
+        operation.message = message.to_string();
+    }
+}
+
+impl Default for OperationStore {
+    fn default() -> Self {
+        Self::new(DEFAULT_OPERATION_RETENTION_SECONDS, DEFAULT_MAX_OPERATIONS)
+    }
+}
+
+impl std::fmt::Debug for OperationStore {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("OperationStore")
+            .field("operations", &self.operations.len())
+            .field("retention_seconds", &self.retention_seconds)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_progress_until_complete() {
+        let store = OperationStore::default();
+        let id = store.start("program.deploy", "program", 3).unwrap();
+        store.record_signature(&id, "sig-1");
+        store.progress(&id, 1, "Wrote chunk 1");
+        store.complete(&id, "Deployed", HashMap::from([("slot".to_string(), "42".to_string())]));
+        store.progress(&id, 2, "Ignored once done");
+
+        let operation = store.get(&id).unwrap();
+        assert_eq!(operation.state(), OperationState::Succeeded);
+        assert!(operation.done);
+        assert_eq!(operation.completed_steps, 1);
+        assert_eq!(operation.message, "Deployed");
+        assert_eq!(operation.signatures, vec!["sig-1"]);
+        assert_eq!(operation.result["slot"], "42");
+    }
+
+    #[test]
+    fn test_cancellation_is_cooperative() {
+        let store = OperationStore::default();
+        let id = store.start("transaction.rebroadcast", "sig", 0).unwrap();
+        assert!(!store.is_cancel_requested(&id));
+
+        let requested = store.request_cancel(&id).unwrap();
+        assert!(requested.cancel_requested);
+        assert_eq!(requested.state(), OperationState::Running);
+        assert!(store.is_cancel_requested(&id));
+
+        store.cancelled(&id);
+        let operation = store.get(&id).unwrap();
+        assert_eq!(operation.state(), OperationState::Cancelled);
+        assert!(!operation.cancel_requested);
+        assert!(!store.request_cancel(&id).unwrap().cancel_requested);
+        assert!(store.request_cancel("unknown").is_none());
+    }
+
+    #[test]
+    fn test_list_filters() {
+        let store = OperationStore::default();
+        let deploy = store.start("program.deploy", "program", 0).unwrap();
+        store.start("transaction.rebroadcast", "sig", 0).unwrap();
+        store.fail(&deploy, "Buffer write failed");
+
+        assert_eq!(store.list(None, None, false).len(), 2);
+        assert_eq!(store.list(Some("program.deploy"), None, false).len(), 1);
+        assert_eq!(store.list(None, Some("sig"), false).len(), 1);
+        assert_eq!(store.list(None, None, true).len(), 1);
+        assert_eq!(store.get(&deploy).unwrap().error, "Buffer write failed");
+    }
+
+    #[test]
+    fn test_bounded_by_running_operations() {
+        let store = OperationStore::new(0, 2);
+        let first = store.start("kind", "a", 0).unwrap();
+        store.start("kind", "b", 0).unwrap();
+        assert!(store.start("kind", "c", 0).is_err());
+
+        store.cancelled(&first);
+        assert!(store.start("kind", "c", 0).is_ok());
+        assert!(store.get(&first).is_none());
+    }
+}
@@ -0,0 +1,183 @@
+use dashmap::mapref::entry::Entry;
+use dashmap::DashMap;
+
+use protochain_api::protochain::solana::transaction::v1::RebroadcastState;
+
+use super::unix_timestamp;
+
+/// How long a finished rebroadcast stays visible to `MonitorTransaction`
+pub const DEFAULT_REBROADCAST_RETENTION_SECONDS: i64 = 600;
+/// Default number of rebroadcasts tracked before finished ones are evicted
+pub const DEFAULT_MAX_REBROADCASTS: usize = 10_000;
+
+/// Progress of one rebroadcast loop
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct RebroadcastProgress {
+    /// Resends made so far (the original submission is not counted)
+    pub count: u32,
+    /// Whether the loop is running or why it stopped
+    pub state: RebroadcastState,
+    /// Unix timestamp of the last change
+    pub updated_at: i64,
+}
+
+/// Tracks rebroadcast loops by transaction signature.
+///
+/// `SubmitTransaction` registers a loop here and records every resend, and
+/// `MonitorTransaction` reads the progress to stamp its updates. At most one loop runs
+/// per signature. Finished loops are kept for a retention period, then evicted.
+pub struct RebroadcastTracker {
+    entries: DashMap<String, RebroadcastProgress>,
+    retention_seconds: i64,
+    max_entries: usize,
+}
+
+impl RebroadcastTracker {
+    /// Creates an empty tracker that keeps finished loops for `retention_seconds`
+    pub fn new(retention_seconds: i64, max_entries: usize) -> Self {
+        Self {
+            entries: DashMap::new(),
+            retention_seconds,
+            max_entries: max_entries.max(1),
+        }
+    }
+
+    /// Registers a loop for `signature`, returning false if one is already running
+    pub fn start(&self, signature: &str) -> Result<bool, String> {
+        let now = unix_timestamp();
+        if self.entries.len() >= self.max_entries {
+            self.evict(now);
+            if self.entries.len() >= self.max_entries {
+                return Err("Too many transactions are being rebroadcast".to_string());
+            }
+        }
+
+        let progress = RebroadcastProgress {
+            count: 0,
+            state: RebroadcastState::Active,
+            updated_at: now,
+        };
+        match self.entries.entry(signature.to_string()) {
+            Entry::Occupied(entry) if entry.get().state == RebroadcastState::Active => Ok(false),
+            Entry::Occupied(mut entry) => {
+                entry.insert(progress);
+                Ok(true)
+            }
+            Entry::Vacant(entry) => {
+                entry.insert(progress);
+                Ok(true)
+            }
+        }
+    }
+
+    /// Records one resend and returns the new count
+    pub fn record_resend(&self, signature: &str) -> u32 {
+        self.entries.get_mut(signature).map_or(0, |mut progress| {
+            progress.count = progress.count.saturating_add(1);
+            progress.updated_at = unix_timestamp();
+            progress.count
+        })
+    }
+
+    /// Marks the loop for `signature` as stopped with `state`
+    pub fn finish(&self, signature: &str, state: RebroadcastState) {
+        if let Some(mut progress) = self.entries.get_mut(signature) {
+            progress.state = state;
+            progress.updated_at = unix_timestamp();
+        }
+    }
+
+    /// Returns the progress of the loop for `signature`, if there is one
+    pub fn get(&self, signature: &str) -> Option<RebroadcastProgress> {
+        self.entries.get(signature).map(|progress| *progress)
+    }
+
+    /// Number of loops currently tracked
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Whether no loops are tracked
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Drops finished loops older than the retention period, returning how many were dropped
+    pub fn purge_expired(&self) -> usize {
+        let before = self.entries.len();
+        self.evict(unix_timestamp());
+        before.saturating_sub(self.entries.len())
+    }
+
+    /// Drops finished loops older than the retention period
+    fn evict(&self, now: i64) {
+        self.entries.retain(|_, progress| {
+            progress.state == RebroadcastState::Active
+                || now - progress.updated_at < self.retention_seconds
+        });
+    }
+}
+
+impl Default for RebroadcastTracker {
+    fn default() -> Self {
+        Self::new(DEFAULT_REBROADCAST_RETENTION_SECONDS, DEFAULT_MAX_REBROADCASTS)
+    }
+}
+
+impl std::fmt::Debug for RebroadcastTracker {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("RebroadcastTracker")
+            .field("entries", &self.entries.len())
+            .field("retention_seconds", &self.retention_seconds)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_counts_resends_until_finished() {
+        let tracker = RebroadcastTracker::default();
+        assert!(tracker.start("sig").unwrap());
+        assert_eq!(tracker.record_resend("sig"), 1);
+        assert_eq!(tracker.record_resend("sig"), 2);
+        tracker.finish("sig", RebroadcastState::Confirmed);
+
+        let progress = tracker.get("sig").unwrap();
+        assert_eq!(progress.count, 2);
+        assert_eq!(progress.state, RebroadcastState::Confirmed);
+    }
+
+    #[test]
+    fn test_one_active_loop_per_signature() {
+        let tracker = RebroadcastTracker::default();
+        assert!(tracker.start("sig").unwrap());
+        assert!(!tracker.start("sig").unwrap());
+
+        tracker.finish("sig", RebroadcastState::Expired);
+        assert!(tracker.start("sig").unwrap());
+        assert_eq!(tracker.get("sig").unwrap().count, 0);
+    }
+
+    #[test]
+    fn test_unknown_signature() {
+        let tracker = RebroadcastTracker::default();
+        assert_eq!(tracker.record_resend("sig"), 0);
+        assert!(tracker.get("sig").is_none());
+    }
+
+    #[test]
+    fn test_bounded_by_active_loops() {
+        let tracker = RebroadcastTracker::new(0, 2);
+        assert!(tracker.start("a").unwrap());
+        assert!(tracker.start("b").unwrap());
+        assert!(tracker.start("c").is_err());
+
+        tracker.finish("a", RebroadcastState::Confirmed);
+        assert!(tracker.start("c").unwrap());
+        assert!(tracker.get("a").is_none());
+    }
+}
@@ -0,0 +1,251 @@
+use dashmap::DashMap;
+use std::sync::Arc;
+use std::time::Duration;
+
+use super::dead_letters::DeadLetterStore;
+use super::idempotency::IdempotencyCache;
+use super::operations::OperationStore;
+use super::rebroadcasts::RebroadcastTracker;
+use super::simulation_cache::SimulationCache;
+use super::submission_tokens::SubmissionTokenStore;
+use super::submissions::SubmissionLog;
+use super::transaction_queue::TransactionQueue;
+
+/// An in-memory store whose records expire
+pub trait RetainedStore: Send + Sync {
+    /// Name the store is reported under
+    fn name(&self) -> &'static str;
+    /// Records currently held, including expired ones not yet purged
+    fn len(&self) -> usize;
+    /// Drops expired records, returning how many were dropped
+    fn purge_expired(&self) -> usize;
+}
+
+/// Size of one store and how many records collection has purged from it
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct StoreStats {
+    /// Name of the store
+    pub name: &'static str,
+    /// Records currently held
+    pub entries: usize,
+    /// Records purged since startup
+    pub purged: u64,
+}
+
+/// Periodically purges expired records from every in-memory store.
+///
+/// Stores already evict on write, but a store that stops being written to would keep its
+/// expired records forever; collection bounds them by age as well as by count.
+pub struct StoreCollector {
+    interval: Duration,
+    stores: Vec<Arc<dyn RetainedStore>>,
+    purged: DashMap<&'static str, u64>,
+}
+
+impl StoreCollector {
+    /// Creates a collector over `stores`, run every `interval` (zero disables the
+    /// background task)
+    pub fn new(interval: Duration, stores: Vec<Arc<dyn RetainedStore>>) -> Self {
+        Self {
+            interval,
+            stores,
+            purged: DashMap::new(),
+        }
+    }
+
+    /// How often the background task collects
+    pub const fn interval(&self) -> Duration {
+        self.interval
+    }
+
+    /// Purges expired records from every store, returning how many were dropped
+    pub fn collect(&self) -> usize {
+        self.stores
+            .iter()
+            .map(|store| {
+                let purged = store.purge_expired();
+                *self.purged.entry(store.name()).or_insert(0) +=
+                    u64::try_from(purged).unwrap_or(u64::MAX);
+                purged
+            })
+            .sum()
+    }
+
+    /// Current size of every store, in registration order
+    pub fn stats(&self) -> Vec<StoreStats> {
+        self.stores
+            .iter()
+            .map(|store| StoreStats {
+                name: store.name(),
+                entries: store.len(),
+                purged: self.purged.get(store.name()).map_or(0, |purged| *purged),
+            })
+            .collect()
+    }
+}
+
+impl std::fmt::Debug for StoreCollector {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("StoreCollector")
+            .field("interval", &self.interval)
+            .field("stores", &self.stores.len())
+            .finish_non_exhaustive()
+    }
+}
+
+impl RetainedStore for SubmissionLog {
+    fn name(&self) -> &'static str {
+        "submissions"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for IdempotencyCache {
+    fn name(&self) -> &'static str {
+        "idempotency_keys"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for DeadLetterStore {
+    fn name(&self) -> &'static str {
+        "dead_letters"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for OperationStore {
+    fn name(&self) -> &'static str {
+        "operations"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for RebroadcastTracker {
+    fn name(&self) -> &'static str {
+        "rebroadcasts"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for SubmissionTokenStore {
+    fn name(&self) -> &'static str {
+        "submission_tokens"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for TransactionQueue {
+    fn name(&self) -> &'static str {
+        "transaction_queue"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+impl RetainedStore for SimulationCache {
+    fn name(&self) -> &'static str {
+        "simulation_cache"
+    }
+
+    fn len(&self) -> usize {
+        Self::len(self)
+    }
+
+    fn purge_expired(&self) -> usize {
+        Self::purge_expired(self)
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use protochain_api::protochain::solana::transaction::v1::SubmissionRecord;
+
+    #[test]
+    fn test_collect_accumulates_purged_counts() {
+        let submissions = Arc::new(SubmissionLog::new(1, 10));
+        submissions.record(SubmissionRecord {
+            signature: "old".to_string(),
+            submitted_at: 1,
+            ..Default::default()
+        });
+        submissions.record(SubmissionRecord {
+            signature: "new".to_string(),
+            submitted_at: i64::MAX,
+            ..Default::default()
+        });
+        let tokens = Arc::new(SubmissionTokenStore::default());
+        tokens.mint("hash", 60);
+
+        let stores: Vec<Arc<dyn RetainedStore>> = vec![submissions, tokens];
+        let collector = StoreCollector::new(Duration::from_secs(60), stores);
+        assert_eq!(collector.collect(), 1);
+        assert_eq!(collector.collect(), 0);
+
+        assert_eq!(
+            collector.stats(),
+            vec![
+                StoreStats {
+                    name: "submissions",
+                    entries: 1,
+                    purged: 1,
+                },
+                StoreStats {
+                    name: "submission_tokens",
+                    entries: 1,
+                    purged: 0,
+                },
+            ]
+        );
+    }
+}
@@ -0,0 +1,286 @@
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::time::Duration;
+use tokio::sync::{Semaphore, SemaphorePermit};
+use tokio::time::timeout;
+
+use crate::config::RpcLimitsConfig;
+
+/// Classes of outbound RPC calls, each with its own concurrency limit
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum RpcCallClass {
+    /// `getLatestBlockhash`
+    Blockhash,
+    /// `simulateTransaction`
+    Simulation,
+    /// `getAccountInfo`, `getMultipleAccounts` and other reads of chain state
+    AccountRead,
+    /// `sendTransaction`
+    Submission,
+}
+
+impl RpcCallClass {
+    /// Every class, in the order reported by the admin service
+    pub const ALL: [Self; 4] = [
+        Self::Blockhash,
+        Self::Simulation,
+        Self::AccountRead,
+        Self::Submission,
+    ];
+
+    /// Stable name used in errors and the admin API
+    pub const fn name(self) -> &'static str {
+        match self {
+            Self::Blockhash => "blockhash",
+            Self::Simulation => "simulation",
+            Self::AccountRead => "account_read",
+            Self::Submission => "submission",
+        }
+    }
+
+    const fn index(self) -> usize {
+        self as usize
+    }
+}
+
+/// A bounded pool of permits with queueing counters
+struct Pool {
+    semaphore: Semaphore,
+    limit: usize,
+    queued: AtomicU64,
+    saturated: AtomicU64,
+    rejected: AtomicU64,
+}
+
+impl Pool {
+    fn new(name: &str, limit: usize) -> Result<Self, String> {
+        if limit == 0 {
+            return Err(format!("RPC concurrency limit for {name} must be at least 1"));
+        }
+        Ok(Self {
+            semaphore: Semaphore::new(limit),
+            limit,
+            queued: AtomicU64::new(0),
+            saturated: AtomicU64::new(0),
+            rejected: AtomicU64::new(0),
+        })
+    }
+
+    fn in_flight(&self) -> usize {
+        self.limit
+            .saturating_sub(self.semaphore.available_permits())
+    }
+}
+
+/// Counts a caller as queued until dropped, so cancelled waits are not leaked
+struct Queued<'a>(&'a AtomicU64);
+
+impl<'a> Queued<'a> {
+    fn enter(counter: &'a AtomicU64) -> Self {
+        counter.fetch_add(1, Ordering::Relaxed);
+        Self(counter)
+    }
+}
+
+impl Drop for Queued<'_> {
+    fn drop(&mut self) {
+        self.0.fetch_sub(1, Ordering::Relaxed);
+    }
+}
+
+/// Held for the duration of one outbound call
+#[derive(Debug)]
+pub struct RpcPermit<'a> {
+    _class: SemaphorePermit<'a>,
+    _global: SemaphorePermit<'a>,
+}
+
+/// Point-in-time usage of one limit
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct RpcLimitStats {
+    /// Class name, or `global` for the shared pool
+    pub name: &'static str,
+    /// Calls allowed in flight
+    pub limit: usize,
+    /// Calls currently in flight
+    pub in_flight: usize,
+    /// Calls currently waiting for a permit
+    pub queued: u64,
+    /// Calls that had to queue since startup
+    pub saturated: u64,
+    /// Calls rejected after queueing for too long since startup
+    pub rejected: u64,
+}
+
+/// Per-class and global concurrency limits on calls to the Solana RPC node.
+///
+/// A burst of requests queues here instead of opening unbounded connections to the node,
+/// so one hot method cannot exhaust the node's connection limits for every other caller.
+pub struct RpcLimiter {
+    global: Semaphore,
+    global_limit: usize,
+    pools: Vec<Pool>,
+    queue_timeout: Duration,
+}
+
+impl RpcLimiter {
+    /// Builds the limiter from configuration, rejecting zero limits
+    pub fn from_config(config: &RpcLimitsConfig) -> Result<Self, String> {
+        if config.max_concurrent == 0 {
+            return Err("Global RPC concurrency limit must be at least 1".to_string());
+        }
+        let pools = RpcCallClass::ALL
+            .into_iter()
+            .map(|class| {
+                let limit = match class {
+                    RpcCallClass::Blockhash => config.max_concurrent_blockhash,
+                    RpcCallClass::Simulation => config.max_concurrent_simulation,
+                    RpcCallClass::AccountRead => config.max_concurrent_account_read,
+                    RpcCallClass::Submission => config.max_concurrent_submission,
+                };
+                Pool::new(class.name(), limit)
+            })
+            .collect::<Result<Vec<Pool>, String>>()?;
+
+        Ok(Self {
+            global: Semaphore::new(config.max_concurrent),
+            global_limit: config.max_concurrent,
+            pools,
+            queue_timeout: Duration::from_millis(config.queue_timeout_ms),
+        })
+    }
+
+    /// Waits for a permit to make one call of `class`, failing once the queue timeout
+    /// elapses
+    pub async fn acquire(&self, class: RpcCallClass) -> Result<RpcPermit<'_>, String> {
+        let pool = &self.pools[class.index()];
+
+        if let Ok(class_permit) = pool.semaphore.try_acquire() {
+            if let Ok(global_permit) = self.global.try_acquire() {
+                return Ok(RpcPermit {
+                    _class: class_permit,
+                    _global: global_permit,
+                });
+            }
+        }
+
+        pool.saturated.fetch_add(1, Ordering::Relaxed);
+        let _queued = Queued::enter(&pool.queued);
+        let acquired = timeout(self.queue_timeout, async {
+            let class_permit = pool.semaphore.acquire().await.ok()?;
+            let global_permit = self.global.acquire().await.ok()?;
+            Some(RpcPermit {
+                _class: class_permit,
+                _global: global_permit,
+            })
+        })
+        .await;
+
+        if let Ok(Some(permit)) = acquired {
+            return Ok(permit);
+        }
+        pool.rejected.fetch_add(1, Ordering::Relaxed);
+        Err(format!(
+            "Too many concurrent {} calls to the Solana RPC node, retry later",
+            class.name()
+        ))
+    }
+
+    /// Usage of the global pool followed by each class
+    pub fn stats(&self) -> Vec<RpcLimitStats> {
+        let total = |counter: fn(&Pool) -> &AtomicU64| {
+            self.pools
+                .iter()
+                .map(|pool| counter(pool).load(Ordering::Relaxed))
+                .sum::<u64>()
+        };
+        let mut stats = vec![RpcLimitStats {
+            name: "global",
+            limit: self.global_limit,
+            in_flight: self
+                .global_limit
+                .saturating_sub(self.global.available_permits()),
+            queued: total(|pool| &pool.queued),
+            saturated: total(|pool| &pool.saturated),
+            rejected: total(|pool| &pool.rejected),
+        }];
+        stats.extend(
+            RpcCallClass::ALL
+                .into_iter()
+                .zip(&self.pools)
+                .map(|(class, pool)| RpcLimitStats {
+                    name: class.name(),
+                    limit: pool.limit,
+                    in_flight: pool.in_flight(),
+                    queued: pool.queued.load(Ordering::Relaxed),
+                    saturated: pool.saturated.load(Ordering::Relaxed),
+                    rejected: pool.rejected.load(Ordering::Relaxed),
+                }),
+        );
+        stats
+    }
+}
+
+impl std::fmt::Debug for RpcLimiter {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("RpcLimiter")
+            .field("global_limit", &self.global_limit)
+            .field("queue_timeout", &self.queue_timeout)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn limiter(max_concurrent: usize, per_class: usize) -> RpcLimiter {
+        RpcLimiter::from_config(&RpcLimitsConfig {
+            max_concurrent,
+            max_concurrent_blockhash: per_class,
+            max_concurrent_simulation: per_class,
+            max_concurrent_account_read: per_class,
+            max_concurrent_submission: per_class,
+            queue_timeout_ms: 20,
+        })
+        .unwrap()
+    }
+
+    #[test]
+    fn test_zero_limits_are_rejected() {
+        let config = RpcLimitsConfig {
+            max_concurrent_simulation: 0,
+            ..Default::default()
+        };
+        assert!(RpcLimiter::from_config(&config).is_err());
+    }
+
+    #[tokio::test]
+    async fn test_class_limit_queues_then_rejects() {
+        let limiter = limiter(8, 1);
+
+        let held = limiter.acquire(RpcCallClass::Simulation).await.unwrap();
+        assert!(limiter.acquire(RpcCallClass::Simulation).await.is_err());
+        // Other classes are unaffected
+        assert!(limiter.acquire(RpcCallClass::Blockhash).await.is_ok());
+
+        drop(held);
+        assert!(limiter.acquire(RpcCallClass::Simulation).await.is_ok());
+
+        let simulation = &limiter.stats()[2];
+        assert_eq!(simulation.name, "simulation");
+        assert_eq!((simulation.saturated, simulation.rejected), (1, 1));
+        assert_eq!((simulation.in_flight, simulation.queued), (0, 0));
+    }
+
+    #[tokio::test]
+    async fn test_global_limit_spans_classes() {
+        let limiter = limiter(1, 4);
+
+        let _held = limiter.acquire(RpcCallClass::AccountRead).await.unwrap();
+        assert!(limiter.acquire(RpcCallClass::Submission).await.is_err());
+
+        let global = &limiter.stats()[0];
+        assert_eq!((global.limit, global.in_flight, global.rejected), (1, 1, 1));
+    }
+}
@@ -0,0 +1,246 @@
+use dashmap::DashMap;
+use std::time::{Duration, Instant};
+
+use protochain_api::protochain::solana::transaction::v1::SimulateTransactionResponse;
+
+use crate::config::SimulationCacheConfig;
+
+/// What a cached simulation result is keyed by
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+pub struct SimulationCacheKey {
+    /// Hex SHA-256 of the serialized message
+    pub message_hash: String,
+    /// First slot of the bucket the request arrived in
+    pub slot_bucket: u64,
+    /// Requested commitment level
+    pub commitment_level: i32,
+    /// Whether signatures were verified
+    pub sig_verify: bool,
+    /// Whether the blockhash was replaced
+    pub replace_recent_blockhash: bool,
+    /// Whether inner instructions were requested
+    pub include_inner_instructions: bool,
+    /// Minimum context slot the simulation required
+    pub min_context_slot: u64,
+    /// Accounts whose state was requested, in request order
+    pub account_addresses: Vec<String>,
+    /// Signatures carried by the transaction, when they were verified
+    pub signatures: Vec<String>,
+}
+
+/// A simulation result held by the cache
+#[derive(Debug, Clone, PartialEq)]
+pub struct CachedSimulation {
+    /// Response returned to the caller that ran the simulation
+    pub response: SimulateTransactionResponse,
+    /// Slot the simulation ran at
+    pub simulated_slot: u64,
+    /// How long ago the simulation ran
+    pub age: Duration,
+}
+
+/// Short-lived cache of `SimulateTransaction` results for callers that opt in.
+///
+/// Template-based flows simulate the same compiled message repeatedly as a pre-submit
+/// guardrail. Results are keyed by the message hash and the bucket of slots the request
+/// arrived in, so a cached result never outlives a few slots of chain progress, and expire
+/// after the configured TTL regardless. A TTL of 0 disables the cache.
+pub struct SimulationCache {
+    ttl: Duration,
+    slots_per_bucket: u64,
+    max_entries: usize,
+    entries: DashMap<SimulationCacheKey, (Instant, u64, SimulateTransactionResponse)>,
+}
+
+impl SimulationCache {
+    /// Builds the cache from configuration
+    pub fn from_config(config: &SimulationCacheConfig) -> Result<Self, String> {
+        if config.slots_per_bucket == 0 {
+            return Err("Simulation cache slots per bucket must be at least 1".to_string());
+        }
+        Ok(Self {
+            ttl: Duration::from_millis(config.ttl_ms),
+            slots_per_bucket: config.slots_per_bucket,
+            max_entries: config.max_entries.max(1),
+            entries: DashMap::new(),
+        })
+    }
+
+    /// Whether results are cached at all
+    pub fn is_enabled(&self) -> bool {
+        !self.ttl.is_zero()
+    }
+
+    /// First slot of the bucket `slot` falls in
+    pub const fn slot_bucket(&self, slot: u64) -> u64 {
+        slot - slot % self.slots_per_bucket
+    }
+
+    /// Returns the result cached under `key`, unless it has expired
+    pub fn get(&self, key: &SimulationCacheKey) -> Option<CachedSimulation> {
+        let entry = self.entries.get(key)?;
+        let (cached_at, simulated_slot, response) = entry.value();
+        let age = cached_at.elapsed();
+        (age < self.ttl).then(|| CachedSimulation {
+            response: response.clone(),
+            simulated_slot: *simulated_slot,
+            age,
+        })
+    }
+
+    /// Caches `response`, simulated at `simulated_slot`, under `key`
+    pub fn insert(
+        &self,
+        key: SimulationCacheKey,
+        response: SimulateTransactionResponse,
+        simulated_slot: u64,
+    ) {
+        if !self.is_enabled() {
+            return;
+        }
+        if self.entries.len() >= self.max_entries {
+            self.purge_expired();
+            if self.entries.len() >= self.max_entries {
+                let oldest = self
+                    .entries
+                    .iter()
+                    .min_by_key(|entry| entry.value().0)
+                    .map(|entry| entry.key().clone());
+                if let Some(oldest) = oldest {
+                    self.entries.remove(&oldest);
+                }
+            }
+        }
+        self.entries
+            .insert(key, (Instant::now(), simulated_slot, response));
+    }
+
+    /// Number of results currently held
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Whether the cache is empty
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Drops results older than the TTL, returning how many were dropped
+    pub fn purge_expired(&self) -> usize {
+        let before = self.entries.len();
+        self.entries
+            .retain(|_, (cached_at, _, _)| cached_at.elapsed() < self.ttl);
+        before.saturating_sub(self.entries.len())
+    }
+}
+
+impl std::fmt::Debug for SimulationCache {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("SimulationCache")
+            .field("ttl", &self.ttl)
+            .field("slots_per_bucket", &self.slots_per_bucket)
+            .field("entries", &self.entries.len())
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn cache(ttl_ms: u64, max_entries: usize) -> SimulationCache {
+        SimulationCache::from_config(&SimulationCacheConfig {
+            ttl_ms,
+            slots_per_bucket: 4,
+            max_entries,
+        })
+        .unwrap()
+    }
+
+    fn key(message_hash: &str, slot_bucket: u64) -> SimulationCacheKey {
+        SimulationCacheKey {
+            message_hash: message_hash.to_string(),
+            slot_bucket,
+            commitment_level: 0,
+            sig_verify: false,
+            replace_recent_blockhash: false,
+            include_inner_instructions: false,
+            min_context_slot: 0,
+            account_addresses: Vec::new(),
+            signatures: Vec::new(),
+        }
+    }
+
+    fn response(units_consumed: u64) -> SimulateTransactionResponse {
+        SimulateTransactionResponse {
+            success: true,
+            units_consumed,
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_slot_bucket_rounds_down() {
+        let cache = cache(1_000, 10);
+        assert_eq!(cache.slot_bucket(0), 0);
+        assert_eq!(cache.slot_bucket(7), 4);
+        assert_eq!(cache.slot_bucket(8), 8);
+    }
+
+    #[test]
+    fn test_zero_slots_per_bucket_is_rejected() {
+        let config = SimulationCacheConfig {
+            slots_per_bucket: 0,
+            ..SimulationCacheConfig::default()
+        };
+        assert!(SimulationCache::from_config(&config).is_err());
+    }
+
+    #[test]
+    fn test_hit_requires_same_message_and_bucket() {
+        let cache = cache(60_000, 10);
+        cache.insert(key("aa", 4), response(150), 6);
+
+        let hit = cache.get(&key("aa", 4)).unwrap();
+        assert_eq!(hit.response.units_consumed, 150);
+        assert_eq!(hit.simulated_slot, 6);
+        assert!(cache.get(&key("aa", 8)).is_none());
+        assert!(cache.get(&key("bb", 4)).is_none());
+
+        let mut verified = key("aa", 4);
+        verified.sig_verify = true;
+        assert!(cache.get(&verified).is_none());
+    }
+
+    #[test]
+    fn test_disabled_cache_stores_nothing() {
+        let cache = cache(0, 10);
+        assert!(!cache.is_enabled());
+        cache.insert(key("aa", 4), response(150), 6);
+        assert!(cache.is_empty());
+        assert!(cache.get(&key("aa", 4)).is_none());
+    }
+
+    #[test]
+    fn test_expired_results_are_not_returned() {
+        let cache = cache(1, 10);
+        cache.insert(key("aa", 4), response(150), 6);
+        std::thread::sleep(Duration::from_millis(5));
+        assert!(cache.get(&key("aa", 4)).is_none());
+        assert_eq!(cache.purge_expired(), 1);
+        assert!(cache.is_empty());
+    }
+
+    #[test]
+    fn test_full_cache_evicts_oldest() {
+        let cache = cache(60_000, 2);
+        cache.insert(key("aa", 0), response(1), 0);
+        cache.insert(key("bb", 0), response(2), 0);
+        cache.insert(key("cc", 0), response(3), 0);
+
+        assert_eq!(cache.len(), 2);
+        assert!(cache.get(&key("aa", 0)).is_none());
+        assert!(cache.get(&key("cc", 0)).is_some());
+    }
+}
@@ -1,24 +1,104 @@
 use solana_client::rpc_client::RpcClient;
+use solana_sdk::commitment_config::CommitmentConfig;
 use std::sync::Arc;
 
+use crate::config::SolanaConfig;
+
+/// Routes reads to the RPC endpoint configured for their commitment tier.
+///
+/// Tiers without an endpoint of their own, and every write, use the primary endpoint.
+/// Processed reads can then go to a fast local node and finalized reads to a highly
+/// reliable provider without clients choosing between them.
+pub struct RpcRouter {
+    processed: Arc<RpcClient>,
+    confirmed: Arc<RpcClient>,
+    finalized: Arc<RpcClient>,
+}
+
+impl RpcRouter {
+    /// Builds the router from configuration, sharing the primary client with every tier
+    /// that has no endpoint of its own
+    pub fn from_config(config: &SolanaConfig, primary: &Arc<RpcClient>) -> Self {
+        let tier = |name: &str, rpc_url: &str| {
+            if rpc_url.is_empty() || rpc_url == config.rpc_url {
+                Arc::clone(primary)
+            } else {
+                println!("🔗 Routing {name} reads to RPC URL: {rpc_url}");
+                Arc::new(RpcClient::new(rpc_url.to_string()))
+            }
+        };
+        Self {
+            processed: tier("processed", &config.processed_rpc_url),
+            confirmed: tier("confirmed", &config.confirmed_rpc_url),
+            finalized: tier("finalized", &config.finalized_rpc_url),
+        }
+    }
+
+    /// The client for reads at `commitment`
+    pub fn for_commitment(&self, commitment: CommitmentConfig) -> &Arc<RpcClient> {
+        if commitment.is_finalized() {
+            &self.finalized
+        } else if commitment.is_processed() {
+            &self.processed
+        } else {
+            &self.confirmed
+        }
+    }
+}
+
 /// Service provider container for Solana client instances
 pub struct SolanaClientsServiceProviders {
     /// Shared RPC client for Solana blockchain interactions
     pub rpc_client: Arc<RpcClient>,
+    /// Per-commitment routing of reads
+    pub rpc_router: Arc<RpcRouter>,
 }
 
 impl SolanaClientsServiceProviders {
-    /// Creates a new `SolanaClientsServiceProviders` instance with the specified RPC URL
-    pub fn new(rpc_url: &str) -> Self {
-        println!("🔗 Initializing Solana RPC client with URL: {rpc_url}");
+    /// Creates a new `SolanaClientsServiceProviders` instance from the Solana configuration
+    pub fn new(config: &SolanaConfig) -> Self {
+        println!("🔗 Initializing Solana RPC client with URL: {}", config.rpc_url);
 
-        let rpc_client = Arc::new(RpcClient::new(rpc_url.to_string()));
+        let rpc_client = Arc::new(RpcClient::new(config.rpc_url.clone()));
+        let rpc_router = Arc::new(RpcRouter::from_config(config, &rpc_client));
 
-        Self { rpc_client }
+        Self {
+            rpc_client,
+            rpc_router,
+        }
     }
 
     /// Returns a cloned reference to the shared RPC client
     pub fn get_rpc_client(&self) -> Arc<RpcClient> {
         Arc::clone(&self.rpc_client)
     }
+
+    /// Returns a cloned reference to the per-commitment read router
+    pub fn get_rpc_router(&self) -> Arc<RpcRouter> {
+        Arc::clone(&self.rpc_router)
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_tiers_without_endpoints_share_the_primary_client() {
+        let config = SolanaConfig {
+            finalized_rpc_url: "http://reliable.example:8899".to_string(),
+            processed_rpc_url: "http://localhost:8899".to_string(),
+            ..Default::default()
+        };
+        let primary = Arc::new(RpcClient::new(config.rpc_url.clone()));
+        let router = RpcRouter::from_config(&config, &primary);
+
+        // Same URL as the primary endpoint
+        assert!(Arc::ptr_eq(router.for_commitment(CommitmentConfig::processed()), &primary));
+        assert!(Arc::ptr_eq(router.for_commitment(CommitmentConfig::confirmed()), &primary));
+
+        let finalized = router.for_commitment(CommitmentConfig::finalized());
+        assert!(!Arc::ptr_eq(finalized, &primary));
+        assert_eq!(finalized.url(), "http://reliable.example:8899");
+    }
 }
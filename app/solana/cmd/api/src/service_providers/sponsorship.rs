@@ -0,0 +1,352 @@
+use dashmap::DashMap;
+use solana_sdk::{pubkey::Pubkey, signature::Keypair, signer::Signer};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::Arc;
+
+use super::key_vault::KeyVault;
+use super::unix_timestamp;
+use crate::config::SponsorshipConfig;
+
+/// How long a sponsorship granted at compile time can be redeemed at submission, a little
+/// longer than a blockhash stays valid
+const GRANT_TTL_SECONDS: i64 = 300;
+/// Maximum length of a caller id
+const MAX_CALLER_ID_LEN: usize = 128;
+
+/// A compiled message the pool agreed to pay for
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct SponsorshipGrant {
+    /// Sponsor key compiled in as fee payer
+    pub fee_payer: Pubkey,
+    /// Fee quoted for the message in lamports
+    pub fee: u64,
+    /// Unix timestamp of the grant
+    pub granted_at: i64,
+}
+
+#[derive(Debug, Clone, Copy)]
+struct CallerSpend {
+    /// Fees charged in the current budget window
+    spent: u64,
+    /// Fees of outstanding grants, charged or refunded when each is redeemed, released or
+    /// expires
+    reserved: u64,
+    window_start: i64,
+}
+
+impl CallerSpend {
+    /// Starts a new budget window if the current one has ended, keeping reservations
+    fn roll_window(&mut self, now: i64, window_seconds: i64) {
+        if now - self.window_start >= window_seconds {
+            self.spent = 0;
+            self.window_start = now;
+        }
+    }
+}
+
+/// Pool of server-held fee payer keys that pay for callers' transactions.
+///
+/// `CompileTransaction` selects a funded sponsor as fee payer and grants the compiled
+/// message, reserving its fee from the caller's budget. `SubmitTransaction` redeems the
+/// grant by adding the sponsor's signature, so a sponsor only ever signs messages the
+/// service compiled for it, and charges the reserved fee once sent. Grants that are
+/// released or expire unredeemed refund their reservation.
+pub struct SponsorPool {
+    key_refs: Vec<String>,
+    min_balance_lamports: u64,
+    caller_budget_lamports: u64,
+    budget_window_seconds: i64,
+    next: AtomicUsize,
+    /// Caller and grant by compiled message hash
+    grants: DashMap<String, (String, SponsorshipGrant)>,
+    spend: DashMap<String, CallerSpend>,
+}
+
+/// Validates a caller id: 1-128 printable ASCII characters
+pub fn validate_caller_id(caller_id: &str) -> Result<(), String> {
+    if caller_id.is_empty() {
+        return Err("A caller id is required for sponsored transactions".to_string());
+    }
+    if caller_id.len() > MAX_CALLER_ID_LEN || !caller_id.chars().all(|c| c.is_ascii_graphic()) {
+        return Err(format!(
+            "Caller id must be at most {MAX_CALLER_ID_LEN} printable ASCII characters"
+        ));
+    }
+    Ok(())
+}
+
+impl SponsorPool {
+    /// Creates a pool from configuration
+    pub fn from_config(config: &SponsorshipConfig) -> Self {
+        Self {
+            key_refs: config.fee_payer_key_refs.clone(),
+            min_balance_lamports: config.min_balance_lamports,
+            caller_budget_lamports: config.caller_budget_lamports,
+            budget_window_seconds: i64::try_from(config.budget_window_seconds)
+                .unwrap_or(i64::MAX)
+                .max(1),
+            next: AtomicUsize::new(0),
+            grants: DashMap::new(),
+            spend: DashMap::new(),
+        }
+    }
+
+    /// Whether any sponsor keys are configured
+    pub fn is_enabled(&self) -> bool {
+        !self.key_refs.is_empty()
+    }
+
+    /// Picks a sponsor holding at least the minimum balance, rotating through the pool.
+    ///
+    /// `balance_of` returns a key's balance, or `None` if it could not be read.
+    pub fn select_fee_payer(
+        &self,
+        key_vault: &KeyVault,
+        balance_of: impl Fn(&Pubkey) -> Option<u64>,
+    ) -> Result<Arc<Keypair>, String> {
+        if !self.is_enabled() {
+            return Err("No sponsored fee payers are configured".to_string());
+        }
+
+        let start = self.next.fetch_add(1, Ordering::Relaxed);
+        (0..self.key_refs.len())
+            .map(|offset| &self.key_refs[(start + offset) % self.key_refs.len()])
+            .filter_map(|key_ref| key_vault.resolve(key_ref))
+            .find(|keypair| {
+                balance_of(&keypair.pubkey())
+                    .is_some_and(|balance| balance >= self.min_balance_lamports)
+            })
+            .ok_or_else(|| "No sponsored fee payer has sufficient balance".to_string())
+    }
+
+    /// Lamports the caller may still reserve in the current budget window
+    pub fn remaining_budget(&self, caller_id: &str) -> u64 {
+        let now = unix_timestamp();
+        self.expire_grants(now);
+        self.spend
+            .get(caller_id)
+            .map_or(self.caller_budget_lamports, |spend| {
+                let spent = if now - spend.window_start < self.budget_window_seconds {
+                    spend.spent
+                } else {
+                    0
+                };
+                self.caller_budget_lamports
+                    .saturating_sub(spent.saturating_add(spend.reserved))
+            })
+    }
+
+    /// Records that the pool will pay `grant.fee` for the message with `message_hash`,
+    /// reserving the fee from the caller's budget. Fails if the remaining budget does not
+    /// cover it; the check and the reservation happen under the caller's entry lock, so
+    /// concurrent grants cannot together overspend the budget.
+    pub fn grant(
+        &self,
+        caller_id: &str,
+        message_hash: &str,
+        grant: SponsorshipGrant,
+    ) -> Result<(), String> {
+        let now = unix_timestamp();
+        self.expire_grants(now);
+        // Compiling the same message again replaces its grant
+        self.release(message_hash);
+
+        {
+            let mut spend = self
+                .spend
+                .entry(caller_id.to_string())
+                .or_insert(CallerSpend {
+                    spent: 0,
+                    reserved: 0,
+                    window_start: now,
+                });
+            spend.roll_window(now, self.budget_window_seconds);
+            let remaining = self
+                .caller_budget_lamports
+                .saturating_sub(spend.spent.saturating_add(spend.reserved));
+            if grant.fee > remaining {
+                return Err(format!(
+                    "Sponsorship budget exceeded: fee {} lamports, {remaining} lamports remaining",
+                    grant.fee
+                ));
+            }
+            spend.reserved = spend.reserved.saturating_add(grant.fee);
+        }
+
+        // A concurrent grant of the same message may have been inserted meanwhile
+        if let Some((previous_caller, previous)) = self
+            .grants
+            .insert(message_hash.to_string(), (caller_id.to_string(), grant))
+        {
+            self.settle(&previous_caller, previous.fee, false);
+        }
+        Ok(())
+    }
+
+    /// Returns the caller and grant for a compiled message, if it was sponsored recently
+    pub fn get_grant(&self, message_hash: &str) -> Option<(String, SponsorshipGrant)> {
+        let now = unix_timestamp();
+        self.grants
+            .get(message_hash)
+            .filter(|entry| now - entry.1.granted_at < GRANT_TTL_SECONDS)
+            .map(|entry| entry.clone())
+    }
+
+    /// Charges a sent sponsored transaction to its caller and retires the grant
+    pub fn redeem(&self, message_hash: &str) {
+        if let Some((_, (caller_id, grant))) = self.grants.remove(message_hash) {
+            self.settle(&caller_id, grant.fee, true);
+        }
+    }
+
+    /// Retires the grant of a transaction that was not sent, refunding its reservation
+    pub fn release(&self, message_hash: &str) {
+        if let Some((_, (caller_id, grant))) = self.grants.remove(message_hash) {
+            self.settle(&caller_id, grant.fee, false);
+        }
+    }
+
+    /// Refunds the reservations of grants older than the grant TTL
+    fn expire_grants(&self, now: i64) {
+        let expired: Vec<String> = self
+            .grants
+            .iter()
+            .filter(|entry| now - entry.1.granted_at >= GRANT_TTL_SECONDS)
+            .map(|entry| entry.key().clone())
+            .collect();
+        for message_hash in expired {
+            if let Some((_, (caller_id, grant))) =
+                self.grants.remove_if(&message_hash, |_, (_, grant)| {
+                    now - grant.granted_at >= GRANT_TTL_SECONDS
+                })
+            {
+                self.settle(&caller_id, grant.fee, false);
+            }
+        }
+    }
+
+    /// Releases a reservation of `fee`, charging it to the caller's budget if `charge`
+    fn settle(&self, caller_id: &str, fee: u64, charge: bool) {
+        let now = unix_timestamp();
+        let Some(mut spend) = self.spend.get_mut(caller_id) else {
+            return;
+        };
+        spend.reserved = spend.reserved.saturating_sub(fee);
+        if charge {
+            spend.roll_window(now, self.budget_window_seconds);
+            spend.spent = spend.spent.saturating_add(fee);
+        }
+    }
+}
+
+impl std::fmt::Debug for SponsorPool {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("SponsorPool")
+            .field("key_refs", &self.key_refs)
+            .field("grants", &self.grants.len())
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn pool(key_refs: &[&str], caller_budget_lamports: u64) -> SponsorPool {
+        SponsorPool::from_config(&SponsorshipConfig {
+            fee_payer_key_refs: key_refs.iter().map(ToString::to_string).collect(),
+            min_balance_lamports: 1_000,
+            caller_budget_lamports,
+            budget_window_seconds: 3_600,
+        })
+    }
+
+    fn grant(fee: u64) -> SponsorshipGrant {
+        SponsorshipGrant {
+            fee_payer: Pubkey::new_unique(),
+            fee,
+            granted_at: unix_timestamp(),
+        }
+    }
+
+    #[test]
+    fn test_selects_funded_sponsor() {
+        let vault = KeyVault::new();
+        let poor = vault.create("poor").unwrap().public_key;
+        let rich = vault.create("rich").unwrap().public_key;
+        let pool = pool(&["poor", "missing", "rich"], 0);
+
+        for _ in 0..3 {
+            let selected = pool
+                .select_fee_payer(&vault, |key| Some(if *key == poor { 10 } else { 5_000 }))
+                .unwrap();
+            assert_eq!(selected.pubkey(), rich);
+        }
+        assert!(pool.select_fee_payer(&vault, |_| None).is_err());
+    }
+
+    #[test]
+    fn test_disabled_pool() {
+        let pool = pool(&[], 0);
+        assert!(!pool.is_enabled());
+        assert!(pool
+            .select_fee_payer(&KeyVault::new(), |_| Some(u64::MAX))
+            .is_err());
+    }
+
+    #[test]
+    fn test_budget_is_charged_on_redeem() {
+        let pool = pool(&["sponsor"], 10_000);
+        pool.grant("alice", "m1", grant(6_000)).unwrap();
+        assert_eq!(pool.remaining_budget("alice"), 4_000);
+        assert!(pool.get_grant("m1").is_some());
+
+        pool.redeem("m1");
+        assert_eq!(pool.remaining_budget("alice"), 4_000);
+        assert!(pool.get_grant("m1").is_none());
+        assert!(pool.grant("alice", "m2", grant(6_000)).is_err());
+        assert!(pool.grant("bob", "m3", grant(6_000)).is_ok());
+    }
+
+    #[test]
+    fn test_outstanding_grants_reserve_the_budget() {
+        let pool = pool(&["sponsor"], 10_000);
+        pool.grant("alice", "m1", grant(6_000)).unwrap();
+        assert!(pool.grant("alice", "m2", grant(6_000)).is_err());
+
+        // Granting the same message again replaces the first reservation
+        pool.grant("alice", "m1", grant(7_000)).unwrap();
+        assert_eq!(pool.remaining_budget("alice"), 3_000);
+    }
+
+    #[test]
+    fn test_released_grants_are_not_charged() {
+        let pool = pool(&["sponsor"], 10_000);
+        pool.grant("alice", "m1", grant(6_000)).unwrap();
+
+        pool.release("m1");
+        assert!(pool.get_grant("m1").is_none());
+        pool.redeem("m1");
+        assert_eq!(pool.remaining_budget("alice"), 10_000);
+    }
+
+    #[test]
+    fn test_expired_grants_are_refunded() {
+        let pool = pool(&["sponsor"], 10_000);
+        let mut stale = grant(6_000);
+        stale.granted_at -= GRANT_TTL_SECONDS;
+        pool.grant("alice", "m1", stale).unwrap();
+
+        assert_eq!(pool.remaining_budget("alice"), 10_000);
+        assert!(pool.get_grant("m1").is_none());
+        assert!(pool.grant("alice", "m2", grant(10_000)).is_ok());
+    }
+
+    #[test]
+    fn test_validate_caller_id() {
+        assert!(validate_caller_id("tenant-1").is_ok());
+        assert!(validate_caller_id("").is_err());
+        assert!(validate_caller_id("has space").is_err());
+    }
+}
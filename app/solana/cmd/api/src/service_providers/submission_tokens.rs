@@ -0,0 +1,151 @@
+use dashmap::DashMap;
+
+use super::unix_timestamp;
+
+/// Default lifetime of a submission token, comfortably longer than a blockhash is valid
+pub const DEFAULT_TOKEN_TTL_SECONDS: i64 = 120;
+/// Longest lifetime a submission token may be minted with
+pub const MAX_TOKEN_TTL_SECONDS: i64 = 3_600;
+/// Prefix identifying submission tokens in logs and client code
+const TOKEN_PREFIX: &str = "pst_";
+
+/// A token authorizing one submission of a compiled message
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct SubmissionToken {
+    /// Opaque bearer token handed to the submitting client
+    pub token: String,
+    /// Hash of the compiled message the token is bound to
+    pub message_hash: String,
+    /// Unix timestamp after which the token is rejected
+    pub expires_at: i64,
+}
+
+/// Resolves a requested token lifetime, where zero selects the default
+pub fn resolve_token_ttl(ttl_seconds: u32) -> Result<i64, String> {
+    match i64::from(ttl_seconds) {
+        0 => Ok(DEFAULT_TOKEN_TTL_SECONDS),
+        ttl if ttl > MAX_TOKEN_TTL_SECONDS => {
+            Err(format!("Token lifetime must not exceed {MAX_TOKEN_TTL_SECONDS} seconds"))
+        }
+        ttl => Ok(ttl),
+    }
+}
+
+/// Short-lived, single-use tokens that pre-authorize exactly one `SubmitTransaction`.
+///
+/// A backend mints a token for a compiled message and hands it to an untrusted client,
+/// which can then submit that message, and only that message, once. Tokens are removed
+/// when redeemed, so a used token is indistinguishable from one that never existed.
+#[derive(Debug, Default)]
+pub struct SubmissionTokenStore {
+    tokens: DashMap<String, SubmissionToken>,
+}
+
+impl SubmissionTokenStore {
+    /// Mints a token for the message with `message_hash`, living `ttl_seconds`
+    pub fn mint(&self, message_hash: &str, ttl_seconds: i64) -> SubmissionToken {
+        self.purge_expired();
+        let now = unix_timestamp();
+
+        let token = SubmissionToken {
+            token: format!("{TOKEN_PREFIX}{}", uuid::Uuid::new_v4().simple()),
+            message_hash: message_hash.to_string(),
+            expires_at: now.saturating_add(ttl_seconds),
+        };
+        self.tokens.insert(token.token.clone(), token.clone());
+        token
+    }
+
+    /// Drops expired tokens, returning how many were dropped
+    pub fn purge_expired(&self) -> usize {
+        let now = unix_timestamp();
+        let before = self.tokens.len();
+        self.tokens.retain(|_, token| token.expires_at > now);
+        before.saturating_sub(self.tokens.len())
+    }
+
+    /// Number of unspent tokens held, including expired ones not yet purged
+    pub fn len(&self) -> usize {
+        self.tokens.len()
+    }
+
+    /// Whether no tokens are held
+    pub fn is_empty(&self) -> bool {
+        self.tokens.is_empty()
+    }
+
+    /// Checks that `token` is live and bound to `message_hash`, without spending it
+    pub fn check(&self, token: &str, message_hash: &str) -> Result<(), String> {
+        let now = unix_timestamp();
+        let entry = self
+            .tokens
+            .get(token)
+            .filter(|entry| entry.expires_at > now)
+            .ok_or_else(|| "Submission token is invalid, expired or already used".to_string())?;
+        if entry.message_hash != message_hash {
+            return Err("Submission token is not bound to this transaction".to_string());
+        }
+        Ok(())
+    }
+
+    /// Spends `token` for the message with `message_hash`; it cannot be used again
+    pub fn redeem(&self, token: &str, message_hash: &str) -> Result<(), String> {
+        let now = unix_timestamp();
+        if self
+            .tokens
+            .remove_if(token, |_, entry| {
+                entry.expires_at > now && entry.message_hash == message_hash
+            })
+            .is_some()
+        {
+            return Ok(());
+        }
+        // Report why, leaving a token bound to another message unspent
+        self.check(token, message_hash)
+            .and(Err("Submission token is invalid, expired or already used".to_string()))
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_token_ttl_defaults_and_bounds() {
+        assert_eq!(resolve_token_ttl(0).unwrap(), DEFAULT_TOKEN_TTL_SECONDS);
+        assert_eq!(resolve_token_ttl(30).unwrap(), 30);
+        assert!(resolve_token_ttl(3_601).is_err());
+    }
+
+    #[test]
+    fn test_token_is_single_use() {
+        let store = SubmissionTokenStore::default();
+        let token = store.mint("hash", 60);
+        assert!(token.token.starts_with(TOKEN_PREFIX));
+
+        assert!(store.check(&token.token, "hash").is_ok());
+        assert!(store.redeem(&token.token, "hash").is_ok());
+        assert!(store.check(&token.token, "hash").is_err());
+        assert!(store.redeem(&token.token, "hash").is_err());
+    }
+
+    #[test]
+    fn test_token_is_bound_to_its_message() {
+        let store = SubmissionTokenStore::default();
+        let token = store.mint("hash", 60);
+
+        assert!(store.check(&token.token, "other").is_err());
+        assert!(store.redeem(&token.token, "other").is_err());
+        // A mismatched attempt does not spend the token
+        assert!(store.redeem(&token.token, "hash").is_ok());
+    }
+
+    #[test]
+    fn test_expired_tokens_are_rejected() {
+        let store = SubmissionTokenStore::default();
+        let token = store.mint("hash", 0);
+        assert!(store.check(&token.token, "hash").is_err());
+        assert!(store.redeem(&token.token, "hash").is_err());
+    }
+}
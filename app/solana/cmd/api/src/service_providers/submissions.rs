@@ -0,0 +1,302 @@
+use dashmap::DashMap;
+use std::collections::HashMap;
+use std::sync::Arc;
+
+use protochain_api::protochain::solana::transaction::v1::{SubmissionRecord, SubmissionResult};
+
+use super::event_export::schema::ExportEvent;
+use super::event_export::EventExporter;
+use super::unix_timestamp;
+
+/// Default number of submissions retained before the oldest are evicted
+pub const DEFAULT_MAX_SUBMISSIONS: usize = 10_000;
+/// Default time a submission stays searchable
+pub const DEFAULT_SUBMISSION_TTL_SECONDS: i64 = 86_400;
+/// Default number of records returned by a search
+pub const DEFAULT_SEARCH_LIMIT: u32 = 50;
+/// Maximum number of records returned by a search
+pub const MAX_SEARCH_LIMIT: u32 = 500;
+/// Maximum number of tags on one submission
+const MAX_TAGS: usize = 16;
+/// Maximum length of a tag key
+const MAX_TAG_KEY_LEN: usize = 64;
+/// Maximum length of a tag value in bytes
+const MAX_TAG_VALUE_LEN: usize = 256;
+
+/// Validates caller-supplied submission tags: at most 16, keys of 1-64 printable ASCII
+/// characters and values of at most 256 bytes
+pub fn validate_tags(tags: &HashMap<String, String>) -> Result<(), String> {
+    if tags.len() > MAX_TAGS {
+        return Err(format!("At most {MAX_TAGS} tags are allowed"));
+    }
+    for (key, value) in tags {
+        if key.is_empty() || key.len() > MAX_TAG_KEY_LEN {
+            return Err(format!("Tag keys must be 1-{MAX_TAG_KEY_LEN} characters"));
+        }
+        if !key.chars().all(|c| c.is_ascii_graphic()) {
+            return Err(format!("Tag key {key:?} may only contain printable ASCII characters"));
+        }
+        if value.len() > MAX_TAG_VALUE_LEN {
+            return Err(format!("Tag {key} value must be at most {MAX_TAG_VALUE_LEN} bytes"));
+        }
+    }
+    Ok(())
+}
+
+/// Criteria a submission must meet to be returned by a search
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct SubmissionFilter {
+    /// Tags the submission must all carry with the same values
+    pub tags: HashMap<String, String>,
+    /// Fee payer the submission must have, if set
+    pub fee_payer: Option<String>,
+    /// Outcome the submission must have, if set
+    pub submission_result: Option<SubmissionResult>,
+}
+
+impl SubmissionFilter {
+    fn matches(&self, record: &SubmissionRecord) -> bool {
+        self.tags
+            .iter()
+            .all(|(key, value)| record.tags.get(key) == Some(value))
+            && self
+                .fee_payer
+                .as_ref()
+                .map_or(true, |fee_payer| record.fee_payer == *fee_payer)
+            && self
+                .submission_result
+                .map_or(true, |result| record.submission_result() == result)
+    }
+}
+
+/// In-memory record of recent submissions and their tags, keyed by signature.
+///
+/// Lets callers find their transactions by their own identifiers (order or customer IDs)
+/// through `SearchSubmissions`. The store is bounded; the oldest record is evicted when
+/// full, and records older than the TTL are purged, so it covers recent activity only and
+/// is not a durable ledger; `with_export` additionally queues every record for the
+/// columnar event export.
+pub struct SubmissionLog {
+    entries: DashMap<String, SubmissionRecord>,
+    ttl_seconds: i64,
+    max_entries: usize,
+    export: Option<Arc<EventExporter>>,
+}
+
+impl SubmissionLog {
+    /// Creates an empty log whose records live `ttl_seconds` (0 keeps them until evicted),
+    /// holding at most `max_entries` submissions
+    pub fn new(ttl_seconds: i64, max_entries: usize) -> Self {
+        Self {
+            entries: DashMap::new(),
+            ttl_seconds,
+            max_entries: max_entries.max(1),
+            export: None,
+        }
+    }
+
+    /// Queues every recorded submission for export through `exporter`
+    pub fn with_export(mut self, exporter: Arc<EventExporter>) -> Self {
+        self.export = Some(exporter);
+        self
+    }
+
+    /// Records a submission, replacing any earlier record for the same signature
+    pub fn record(&self, record: SubmissionRecord) {
+        if let Some(exporter) = &self.export {
+            exporter.record(ExportEvent::from_submission(&record));
+        }
+        if !self.entries.contains_key(&record.signature) && self.entries.len() >= self.max_entries {
+            self.evict_oldest();
+        }
+        self.entries.insert(record.signature.clone(), record);
+    }
+
+    /// Returns the record for a signature
+    pub fn get(&self, signature: &str) -> Option<SubmissionRecord> {
+        self.entries.get(signature).map(|entry| entry.clone())
+    }
+
+    /// Returns up to `limit` matching submissions newest first, and whether more matched
+    pub fn search(&self, filter: &SubmissionFilter, limit: usize) -> (Vec<SubmissionRecord>, bool) {
+        let mut records: Vec<SubmissionRecord> = self
+            .entries
+            .iter()
+            .filter(|entry| filter.matches(entry))
+            .map(|entry| entry.clone())
+            .collect();
+        records.sort_by(|a, b| {
+            b.submitted_at
+                .cmp(&a.submitted_at)
+                .then(a.signature.cmp(&b.signature))
+        });
+        let truncated = records.len() > limit;
+        records.truncate(limit);
+        (records, truncated)
+    }
+
+    /// Number of submissions currently held
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Whether the log is empty
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Drops records older than the TTL, returning how many were dropped
+    pub fn purge_expired(&self) -> usize {
+        if self.ttl_seconds <= 0 {
+            return 0;
+        }
+        let cutoff = unix_timestamp() - self.ttl_seconds;
+        let before = self.entries.len();
+        self.entries
+            .retain(|_, record| record.submitted_at > cutoff);
+        before.saturating_sub(self.entries.len())
+    }
+
+    fn evict_oldest(&self) {
+        let oldest = self
+            .entries
+            .iter()
+            .min_by_key(|entry| entry.submitted_at)
+            .map(|entry| entry.key().clone());
+        if let Some(signature) = oldest {
+            self.entries.remove(&signature);
+        }
+    }
+}
+
+impl Default for SubmissionLog {
+    fn default() -> Self {
+        Self::new(DEFAULT_SUBMISSION_TTL_SECONDS, DEFAULT_MAX_SUBMISSIONS)
+    }
+}
+
+impl std::fmt::Debug for SubmissionLog {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("SubmissionLog")
+            .field("entries", &self.entries.len())
+            .field("ttl_seconds", &self.ttl_seconds)
+            .field("max_entries", &self.max_entries)
+            .finish()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn tags(pairs: &[(&str, &str)]) -> HashMap<String, String> {
+        pairs
+            .iter()
+            .map(|(key, value)| ((*key).to_string(), (*value).to_string()))
+            .collect()
+    }
+
+    fn record(signature: &str, submitted_at: i64, pairs: &[(&str, &str)]) -> SubmissionRecord {
+        SubmissionRecord {
+            signature: signature.to_string(),
+            tags: tags(pairs),
+            fee_payer: "payer".to_string(),
+            submission_result: SubmissionResult::Submitted.into(),
+            submitted_at,
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_search_matches_all_tags_newest_first() {
+        let log = SubmissionLog::default();
+        log.record(record("a", 1, &[("customer", "c1"), ("order", "o1")]));
+        log.record(record("b", 2, &[("customer", "c1"), ("order", "o2")]));
+        log.record(record("c", 3, &[("customer", "c2")]));
+
+        let filter = SubmissionFilter {
+            tags: tags(&[("customer", "c1")]),
+            ..Default::default()
+        };
+        let (records, truncated) = log.search(&filter, 10);
+        let signatures: Vec<&str> = records.iter().map(|r| r.signature.as_str()).collect();
+        assert_eq!(signatures, vec!["b", "a"]);
+        assert!(!truncated);
+
+        let filter = SubmissionFilter {
+            tags: tags(&[("customer", "c1"), ("order", "o1")]),
+            ..Default::default()
+        };
+        assert_eq!(log.search(&filter, 10).0[0].signature, "a");
+
+        let (records, truncated) = log.search(&SubmissionFilter::default(), 2);
+        assert_eq!(records.len(), 2);
+        assert!(truncated);
+    }
+
+    #[test]
+    fn test_search_by_result_and_fee_payer() {
+        let log = SubmissionLog::default();
+        log.record(record("a", 1, &[]));
+        log.record(SubmissionRecord {
+            submission_result: SubmissionResult::FailedNetworkError.into(),
+            fee_payer: "other".to_string(),
+            ..record("b", 2, &[])
+        });
+
+        let failed = SubmissionFilter {
+            submission_result: Some(SubmissionResult::FailedNetworkError),
+            ..Default::default()
+        };
+        assert_eq!(log.search(&failed, 10).0[0].signature, "b");
+
+        let payer = SubmissionFilter {
+            fee_payer: Some("payer".to_string()),
+            ..Default::default()
+        };
+        assert_eq!(log.search(&payer, 10).0[0].signature, "a");
+    }
+
+    #[test]
+    fn test_bounded_evicts_oldest() {
+        let log = SubmissionLog::new(DEFAULT_SUBMISSION_TTL_SECONDS, 2);
+        log.record(record("a", 1, &[]));
+        log.record(record("b", 2, &[]));
+        log.record(record("b", 3, &[]));
+        assert_eq!(log.len(), 2);
+
+        log.record(record("c", 4, &[]));
+        assert_eq!(log.len(), 2);
+        assert!(log.get("a").is_none());
+        assert_eq!(log.get("b").unwrap().submitted_at, 3);
+    }
+
+    #[test]
+    fn test_purge_drops_records_past_ttl() {
+        let log = SubmissionLog::default();
+        log.record(record("old", 1, &[]));
+        log.record(record("new", unix_timestamp(), &[]));
+
+        assert_eq!(log.purge_expired(), 1);
+        assert!(log.get("old").is_none());
+        assert!(log.get("new").is_some());
+
+        let unbounded = SubmissionLog::new(0, 10);
+        unbounded.record(record("old", 1, &[]));
+        assert_eq!(unbounded.purge_expired(), 0);
+    }
+
+    #[test]
+    fn test_validate_tags() {
+        assert!(validate_tags(&tags(&[("order", "o-1")])).is_ok());
+        assert!(validate_tags(&tags(&[("", "x")])).is_err());
+        assert!(validate_tags(&tags(&[("has space", "x")])).is_err());
+        assert!(validate_tags(&tags(&[("k", &"v".repeat(MAX_TAG_VALUE_LEN + 1))])).is_err());
+
+        let too_many: HashMap<String, String> = (0..=MAX_TAGS)
+            .map(|i| (format!("k{i}"), String::new()))
+            .collect();
+        assert!(validate_tags(&too_many).is_err());
+    }
+}
@@ -0,0 +1,121 @@
+use dashmap::DashMap;
+
+use protochain_api::protochain::solana::transaction_template::v1::TransactionTemplate;
+
+use super::unix_timestamp;
+
+/// Maximum length of a template name
+const MAX_NAME_LEN: usize = 64;
+
+/// In-memory store of transaction templates keyed by name.
+///
+/// Templates are validated by the template service before they are saved; the store only
+/// owns naming and timestamps.
+#[derive(Default)]
+pub struct TemplateStore {
+    templates: DashMap<String, TransactionTemplate>,
+}
+
+/// Validates a template name: lowercase letters, digits, '-' and '_'
+pub fn validate_template_name(name: &str) -> Result<(), String> {
+    if name.is_empty() {
+        return Err("Template name is required".to_string());
+    }
+    if name.len() > MAX_NAME_LEN {
+        return Err(format!("Template name must be at most {MAX_NAME_LEN} characters"));
+    }
+    if !name
+        .chars()
+        .all(|c| c.is_ascii_lowercase() || c.is_ascii_digit() || c == '-' || c == '_')
+    {
+        return Err(
+            "Template name may only contain lowercase letters, digits, '-' and '_'".to_string()
+        );
+    }
+    Ok(())
+}
+
+impl TemplateStore {
+    /// Saves a template, replacing any template with the same name and keeping its
+    /// creation time
+    pub fn save(&self, mut template: TransactionTemplate) -> Result<TransactionTemplate, String> {
+        validate_template_name(&template.name)?;
+
+        let now = unix_timestamp();
+        template.created_at = self
+            .templates
+            .get(&template.name)
+            .map_or(now, |existing| existing.created_at);
+        template.updated_at = now;
+        self.templates
+            .insert(template.name.clone(), template.clone());
+        Ok(template)
+    }
+
+    /// Returns a template by name
+    pub fn get(&self, name: &str) -> Option<TransactionTemplate> {
+        self.templates.get(name).map(|template| template.clone())
+    }
+
+    /// Lists every template, ordered by name
+    pub fn list(&self) -> Vec<TransactionTemplate> {
+        let mut templates: Vec<TransactionTemplate> = self
+            .templates
+            .iter()
+            .map(|template| template.clone())
+            .collect();
+        templates.sort_by(|a, b| a.name.cmp(&b.name));
+        templates
+    }
+
+    /// Removes a template, returning it if present
+    pub fn remove(&self, name: &str) -> Option<TransactionTemplate> {
+        self.templates.remove(name).map(|(_, template)| template)
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn template(name: &str) -> TransactionTemplate {
+        TransactionTemplate {
+            name: name.to_string(),
+            ..Default::default()
+        }
+    }
+
+    #[test]
+    fn test_save_keeps_created_at_on_replace() {
+        let store = TemplateStore::default();
+        let mut first = store.save(template("payroll")).unwrap();
+        first.created_at -= 100;
+        store.templates.insert(first.name.clone(), first.clone());
+
+        let replaced = store.save(template("payroll")).unwrap();
+        assert_eq!(replaced.created_at, first.created_at);
+        assert!(replaced.updated_at > replaced.created_at);
+        assert_eq!(store.list().len(), 1);
+    }
+
+    #[test]
+    fn test_list_is_ordered_and_remove() {
+        let store = TemplateStore::default();
+        store.save(template("mint-rewards")).unwrap();
+        store.save(template("daily-payout")).unwrap();
+
+        let names: Vec<String> = store.list().into_iter().map(|t| t.name).collect();
+        assert_eq!(names, vec!["daily-payout", "mint-rewards"]);
+        assert!(store.remove("daily-payout").is_some());
+        assert!(store.get("daily-payout").is_none());
+    }
+
+    #[test]
+    fn test_rejects_invalid_names() {
+        let store = TemplateStore::default();
+        assert!(store.save(template("")).is_err());
+        assert!(store.save(template("Daily Payout")).is_err());
+        assert!(store.save(template(&"a".repeat(MAX_NAME_LEN + 1))).is_err());
+    }
+}
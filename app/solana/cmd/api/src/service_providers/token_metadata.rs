@@ -0,0 +1,406 @@
+use dashmap::DashMap;
+use serde_json::Value;
+use std::net::IpAddr;
+use std::time::{Duration, Instant};
+
+use crate::config::TokenMetadataConfig;
+
+/// Most redirects followed while fetching a document
+const MAX_REDIRECTS: usize = 3;
+/// Most attributes kept from a document
+const MAX_ATTRIBUTES: usize = 100;
+
+/// A validated off-chain metadata document
+#[derive(Debug, Clone, Default, PartialEq, Eq)]
+pub struct OffChainMetadata {
+    /// Token name
+    pub name: String,
+    /// Token symbol
+    pub symbol: String,
+    /// Free-text description
+    pub description: String,
+    /// Image URL, rewritten to HTTPS through the configured gateways
+    pub image: String,
+    /// Project website
+    pub external_url: String,
+    /// Trait types and their values, with non-string values rendered as JSON
+    pub attributes: Vec<(String, String)>,
+}
+
+/// Why a document could not be used
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum MetadataFetchError {
+    /// The document could not be fetched
+    Fetch(String),
+    /// The URI or the document it points at is not valid metadata
+    Invalid(String),
+}
+
+impl std::fmt::Display for MetadataFetchError {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            Self::Fetch(message) | Self::Invalid(message) => f.write_str(message),
+        }
+    }
+}
+
+type FetchResult = Result<OffChainMetadata, MetadataFetchError>;
+
+/// Fetches and validates the off-chain JSON documents token metadata URIs point at.
+///
+/// Only HTTPS is fetched, with `ipfs://` and `ar://` URIs rewritten to the configured
+/// gateways; hosts that are loopback, private or link-local addresses are refused so that
+/// a mint's URI cannot reach internal services. Documents are read up to a size limit and
+/// both successes and failures are cached for the configured TTL, so a slow or broken host
+/// is not asked again on every request.
+pub struct TokenMetadataFetcher {
+    http: reqwest::Client,
+    max_document_bytes: u64,
+    cache_ttl: Duration,
+    max_cached_documents: usize,
+    ipfs_gateway: String,
+    arweave_gateway: String,
+    cache: DashMap<String, (Instant, FetchResult)>,
+}
+
+impl TokenMetadataFetcher {
+    /// Builds the fetcher from configuration, rejecting unusable gateways
+    pub fn from_config(config: &TokenMetadataConfig) -> Result<Self, String> {
+        for (name, gateway) in [
+            ("IPFS", &config.ipfs_gateway),
+            ("Arweave", &config.arweave_gateway),
+        ] {
+            if !gateway.starts_with("https://") {
+                return Err(format!("{name} gateway must be an https:// URL: {gateway}"));
+            }
+        }
+        let http = reqwest::Client::builder()
+            .timeout(Duration::from_secs(config.fetch_timeout_seconds))
+            .redirect(reqwest::redirect::Policy::custom(|attempt| {
+                if attempt.previous().len() >= MAX_REDIRECTS {
+                    attempt.error("too many redirects")
+                } else if let Err(e) = check_url(attempt.url()) {
+                    attempt.error(e)
+                } else {
+                    attempt.follow()
+                }
+            }))
+            .build()
+            .map_err(|e| format!("Failed to build metadata HTTP client: {e}"))?;
+
+        Ok(Self {
+            http,
+            max_document_bytes: config.max_document_bytes,
+            cache_ttl: Duration::from_secs(config.cache_ttl_seconds),
+            max_cached_documents: config.max_cached_documents,
+            ipfs_gateway: with_trailing_slash(&config.ipfs_gateway),
+            arweave_gateway: with_trailing_slash(&config.arweave_gateway),
+            cache: DashMap::new(),
+        })
+    }
+
+    /// Fetches the document at `uri`, from the cache when it was fetched recently
+    pub async fn fetch(&self, uri: &str) -> FetchResult {
+        if let Some(entry) = self.cache.get(uri) {
+            let (fetched_at, result) = entry.value();
+            if fetched_at.elapsed() < self.cache_ttl {
+                return result.clone();
+            }
+        }
+
+        let result = self.fetch_uncached(uri).await;
+        self.remember(uri, &result);
+        result
+    }
+
+    /// Rewrites a metadata or image URI to the HTTPS URL it is fetched from
+    pub fn resolve_uri(&self, uri: &str) -> Result<String, MetadataFetchError> {
+        let uri = uri.trim();
+        let resolved = if let Some(path) = uri.strip_prefix("ipfs://") {
+            format!("{}{}", self.ipfs_gateway, path.strip_prefix("ipfs/").unwrap_or(path))
+        } else if let Some(path) = uri.strip_prefix("ar://") {
+            format!("{}{path}", self.arweave_gateway)
+        } else {
+            uri.to_string()
+        };
+        let url = reqwest::Url::parse(&resolved)
+            .map_err(|e| MetadataFetchError::Invalid(format!("Invalid metadata URI {uri}: {e}")))?;
+        check_url(&url).map_err(MetadataFetchError::Invalid)?;
+        Ok(resolved)
+    }
+
+    async fn fetch_uncached(&self, uri: &str) -> FetchResult {
+        let url = self.resolve_uri(uri)?;
+        let mut response = self
+            .http
+            .get(&url)
+            .header(reqwest::header::ACCEPT, "application/json")
+            .send()
+            .await
+            .map_err(|e| MetadataFetchError::Fetch(format!("Failed to fetch {url}: {e}")))?;
+        if !response.status().is_success() {
+            return Err(MetadataFetchError::Fetch(format!(
+                "Fetching {url} returned HTTP {}",
+                response.status()
+            )));
+        }
+        if response
+            .content_length()
+            .is_some_and(|length| length > self.max_document_bytes)
+        {
+            return Err(self.too_large());
+        }
+
+        let mut body = Vec::new();
+        while let Some(chunk) = response
+            .chunk()
+            .await
+            .map_err(|e| MetadataFetchError::Fetch(format!("Failed to read {url}: {e}")))?
+        {
+            body.extend_from_slice(&chunk);
+            if body.len() as u64 > self.max_document_bytes {
+                return Err(self.too_large());
+            }
+        }
+        self.parse_document(&body)
+    }
+
+    /// Validates a document and normalizes it into `OffChainMetadata`
+    fn parse_document(&self, body: &[u8]) -> FetchResult {
+        let document: Value = serde_json::from_slice(body)
+            .map_err(|e| MetadataFetchError::Invalid(format!("Metadata is not JSON: {e}")))?;
+        let Some(fields) = document.as_object() else {
+            return Err(MetadataFetchError::Invalid("Metadata must be a JSON object".to_string()));
+        };
+        let text = |field: &str| -> Result<String, MetadataFetchError> {
+            match fields.get(field) {
+                None | Some(Value::Null) => Ok(String::new()),
+                Some(Value::String(value)) => Ok(value.trim().to_string()),
+                Some(_) => Err(MetadataFetchError::Invalid(format!(
+                    "Metadata field {field} must be a string"
+                ))),
+            }
+        };
+
+        let image = text("image")?;
+        let image = if image.is_empty() {
+            image
+        } else {
+            self.resolve_uri(&image)?
+        };
+        let attributes = match fields.get("attributes") {
+            None | Some(Value::Null) => Vec::new(),
+            Some(Value::Array(entries)) => entries
+                .iter()
+                .filter_map(Value::as_object)
+                .filter_map(|entry| {
+                    let trait_type = entry.get("trait_type")?.as_str()?.to_string();
+                    let value = match entry.get("value")? {
+                        Value::String(value) => value.clone(),
+                        value @ (Value::Number(_) | Value::Bool(_)) => value.to_string(),
+                        _ => return None,
+                    };
+                    Some((trait_type, value))
+                })
+                .take(MAX_ATTRIBUTES)
+                .collect(),
+            Some(_) => {
+                return Err(MetadataFetchError::Invalid(
+                    "Metadata field attributes must be an array".to_string(),
+                ))
+            }
+        };
+
+        Ok(OffChainMetadata {
+            name: text("name")?,
+            symbol: text("symbol")?,
+            description: text("description")?,
+            image,
+            external_url: text("external_url")?,
+            attributes,
+        })
+    }
+
+    fn too_large(&self) -> MetadataFetchError {
+        MetadataFetchError::Fetch(format!(
+            "Metadata document exceeds {} bytes",
+            self.max_document_bytes
+        ))
+    }
+
+    fn remember(&self, uri: &str, result: &FetchResult) {
+        if self.cache_ttl.is_zero() {
+            return;
+        }
+        if self.cache.len() >= self.max_cached_documents {
+            self.cache
+                .retain(|_, (fetched_at, _)| fetched_at.elapsed() < self.cache_ttl);
+            if self.cache.len() >= self.max_cached_documents {
+                return;
+            }
+        }
+        self.cache
+            .insert(uri.to_string(), (Instant::now(), result.clone()));
+    }
+}
+
+impl std::fmt::Debug for TokenMetadataFetcher {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("TokenMetadataFetcher")
+            .field("max_document_bytes", &self.max_document_bytes)
+            .field("cache_ttl", &self.cache_ttl)
+            .field("cached", &self.cache.len())
+            .finish_non_exhaustive()
+    }
+}
+
+/// Refuses URLs that are not HTTPS or that name an internal host
+fn check_url(url: &reqwest::Url) -> Result<(), String> {
+    if url.scheme() != "https" {
+        return Err(format!("Only https:// metadata URIs are fetched: {url}"));
+    }
+    let internal = match url.host_str() {
+        None => true,
+        Some(host) => match host.trim_start_matches('[').trim_end_matches(']').parse() {
+            Ok(ip) => is_internal(ip),
+            Err(_) => host.eq_ignore_ascii_case("localhost") || host.ends_with(".localhost"),
+        },
+    };
+    if internal {
+        return Err(format!("Metadata URI names an internal host: {url}"));
+    }
+    Ok(())
+}
+
+fn is_internal(ip: IpAddr) -> bool {
+    match ip {
+        IpAddr::V4(ip) => {
+            ip.is_loopback()
+                || ip.is_private()
+                || ip.is_link_local()
+                || ip.is_unspecified()
+                || ip.is_broadcast()
+        }
+        IpAddr::V6(ip) => {
+            ip.is_loopback()
+                || ip.is_unspecified()
+                // Unique local (fc00::/7) and link-local (fe80::/10) addresses
+                || (ip.segments()[0] & 0xfe00) == 0xfc00
+                || (ip.segments()[0] & 0xffc0) == 0xfe80
+                || ip.to_ipv4_mapped().is_some_and(|ip| is_internal(IpAddr::V4(ip)))
+        }
+    }
+}
+
+fn with_trailing_slash(url: &str) -> String {
+    if url.ends_with('/') {
+        url.to_string()
+    } else {
+        format!("{url}/")
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn fetcher() -> TokenMetadataFetcher {
+        TokenMetadataFetcher::from_config(&TokenMetadataConfig::default()).unwrap()
+    }
+
+    #[test]
+    fn test_resolves_gateway_uris() {
+        let fetcher = fetcher();
+        assert_eq!(
+            fetcher.resolve_uri("ipfs://bafy123/meta.json").unwrap(),
+            "https://ipfs.io/ipfs/bafy123/meta.json"
+        );
+        assert_eq!(
+            fetcher.resolve_uri("ipfs://ipfs/bafy123").unwrap(),
+            "https://ipfs.io/ipfs/bafy123"
+        );
+        assert_eq!(fetcher.resolve_uri("ar://tx123").unwrap(), "https://arweave.net/tx123");
+        assert_eq!(
+            fetcher.resolve_uri(" https://example.com/a.json ").unwrap(),
+            "https://example.com/a.json"
+        );
+    }
+
+    #[test]
+    fn test_refuses_insecure_and_internal_uris() {
+        let fetcher = fetcher();
+        for uri in [
+            "http://example.com/a.json",
+            "file:///etc/passwd",
+            "https://localhost/a.json",
+            "https://127.0.0.1/a.json",
+            "https://10.0.0.5/a.json",
+            "https://169.254.169.254/latest/meta-data",
+            "https://[::1]/a.json",
+            "https://[::ffff:192.168.0.1]/a.json",
+            "not a uri",
+        ] {
+            assert!(
+                matches!(fetcher.resolve_uri(uri), Err(MetadataFetchError::Invalid(_))),
+                "{uri} should be refused"
+            );
+        }
+    }
+
+    #[test]
+    fn test_normalizes_documents() {
+        let document = br#"{
+            "name": " Example ",
+            "symbol": "EX",
+            "image": "ipfs://bafyimage",
+            "attributes": [
+                {"trait_type": "tier", "value": "gold"},
+                {"trait_type": "level", "value": 3},
+                {"trait_type": "nested", "value": {"a": 1}},
+                "not an attribute"
+            ]
+        }"#;
+        let metadata = fetcher().parse_document(document).unwrap();
+        assert_eq!(metadata.name, "Example");
+        assert_eq!(metadata.image, "https://ipfs.io/ipfs/bafyimage");
+        assert_eq!(
+            metadata.attributes,
+            vec![
+                ("tier".to_string(), "gold".to_string()),
+                ("level".to_string(), "3".to_string())
+            ]
+        );
+    }
+
+    #[test]
+    fn test_rejects_invalid_documents() {
+        let fetcher = fetcher();
+        for document in [
+            &b"not json"[..],
+            br#"["an", "array"]"#,
+            br#"{"name": 42}"#,
+            br#"{"attributes": "gold"}"#,
+            br#"{"image": "http://example.com/i.png"}"#,
+        ] {
+            assert!(matches!(
+                fetcher.parse_document(document),
+                Err(MetadataFetchError::Invalid(_))
+            ));
+        }
+    }
+
+    #[test]
+    fn test_caches_results_until_full() {
+        let fetcher = TokenMetadataFetcher::from_config(&TokenMetadataConfig {
+            max_cached_documents: 1,
+            ..Default::default()
+        })
+        .unwrap();
+        let failure = Err(MetadataFetchError::Fetch("down".to_string()));
+        fetcher.remember("https://a.example/1", &failure);
+        fetcher.remember("https://a.example/2", &failure);
+        assert_eq!(fetcher.cache.len(), 1);
+        assert!(fetcher.cache.contains_key("https://a.example/1"));
+    }
+}
@@ -0,0 +1,385 @@
+use dashmap::DashMap;
+use std::collections::{HashMap, VecDeque};
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::Mutex;
+use std::time::{Duration, Instant};
+use tokio::sync::broadcast;
+
+use protochain_api::protochain::solana::transaction::v1::{
+    QueuedTransaction, QueuedTransactionState, RetryPolicy, Transaction,
+};
+
+use super::unix_timestamp;
+use crate::config::QueueConfig;
+
+/// How long a submitted or failed entry stays visible to `GetQueueStatus`
+pub const QUEUE_RETENTION_SECONDS: i64 = 3_600;
+/// State changes buffered for each `StreamQueueEvents` subscriber before it lags
+const EVENT_BUFFER: usize = 1_024;
+
+/// What the dispatcher needs to send a queued transaction
+#[derive(Debug, Clone)]
+pub struct QueuedJob {
+    /// Fully signed transaction
+    pub transaction: Transaction,
+    /// Commitment level for the submission
+    pub commitment_level: i32,
+    /// Resends of transient failures (the default schedule if absent)
+    pub retry_policy: Option<RetryPolicy>,
+}
+
+/// A transaction the caller must start a dispatcher for
+#[derive(Debug, Clone, PartialEq)]
+pub struct Enqueued {
+    /// The new queue entry
+    pub entry: QueuedTransaction,
+    /// Whether no dispatcher is running for the entry's fee payer yet
+    pub start_dispatcher: bool,
+}
+
+/// Point-in-time usage of the queue
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct QueueStats {
+    /// Transactions waiting to be sent
+    pub pending: usize,
+    /// Transactions being sent
+    pub dispatching: usize,
+    /// Fee payers with transactions waiting or being sent
+    pub fee_payers: usize,
+    /// Transactions submitted since startup
+    pub submitted: u64,
+    /// Transactions failed since startup
+    pub failed: u64,
+}
+
+/// Server-side queue of signed transactions awaiting dispatch.
+///
+/// Each fee payer has a lane of entry ids in enqueue order. A lane exists exactly while a
+/// dispatcher is draining it: `enqueue` reports when a new lane needs one, and `next`
+/// removes the lane once it is empty, so there is never more than one dispatcher per fee
+/// payer and its transactions are sent in order. Sends across all lanes share a single
+/// rate limit through `throttle`. Every state change is broadcast to subscribers.
+pub struct TransactionQueue {
+    enabled: bool,
+    max_tps: u32,
+    max_pending: usize,
+    send_interval: Duration,
+    lanes: Mutex<HashMap<String, VecDeque<String>>>,
+    jobs: DashMap<String, QueuedJob>,
+    entries: DashMap<String, QueuedTransaction>,
+    sequence: AtomicU64,
+    next_send: tokio::sync::Mutex<Instant>,
+    events: broadcast::Sender<QueuedTransaction>,
+    submitted: AtomicU64,
+    failed: AtomicU64,
+}
+
+impl TransactionQueue {
+    /// Builds the queue from configuration, rejecting a zero rate when it is enabled
+    pub fn from_config(config: &QueueConfig) -> Result<Self, String> {
+        if config.enabled && config.max_tps == 0 {
+            return Err("Queue rate must be at least 1 transaction per second".to_string());
+        }
+        let (events, _) = broadcast::channel(EVENT_BUFFER);
+        Ok(Self {
+            enabled: config.enabled,
+            max_tps: config.max_tps,
+            max_pending: config.max_pending,
+            send_interval: Duration::from_secs(1) / config.max_tps.max(1),
+            lanes: Mutex::new(HashMap::new()),
+            jobs: DashMap::new(),
+            entries: DashMap::new(),
+            sequence: AtomicU64::new(0),
+            next_send: tokio::sync::Mutex::new(Instant::now()),
+            events,
+            submitted: AtomicU64::new(0),
+            failed: AtomicU64::new(0),
+        })
+    }
+
+    /// Whether the queue RPCs are served
+    pub const fn is_enabled(&self) -> bool {
+        self.enabled
+    }
+
+    /// Configured send rate ceiling
+    pub const fn max_tps(&self) -> u32 {
+        self.max_tps
+    }
+
+    /// Queues `job` behind earlier transactions of its fee payer
+    pub fn enqueue(
+        &self,
+        job: QueuedJob,
+        signature: &str,
+        tags: HashMap<String, String>,
+    ) -> Result<Enqueued, String> {
+        self.purge_expired();
+        let now = unix_timestamp();
+
+        let mut lanes = self.lanes.lock().unwrap_or_else(|e| e.into_inner());
+        if self.jobs.len() >= self.max_pending {
+            return Err(format!(
+                "The queue already holds {} transactions waiting to be sent",
+                self.max_pending
+            ));
+        }
+
+        let fee_payer = job.transaction.fee_payer.clone();
+        let entry = QueuedTransaction {
+            id: uuid::Uuid::new_v4().to_string(),
+            signature: signature.to_string(),
+            fee_payer: fee_payer.clone(),
+            sequence: self.sequence.fetch_add(1, Ordering::Relaxed) + 1,
+            state: QueuedTransactionState::Pending.into(),
+            tags,
+            enqueued_at: now,
+            updated_at: now,
+            ..Default::default()
+        };
+        self.jobs.insert(entry.id.clone(), job);
+        self.entries.insert(entry.id.clone(), entry.clone());
+
+        let start_dispatcher = !lanes.contains_key(&fee_payer);
+        lanes
+            .entry(fee_payer)
+            .or_default()
+            .push_back(entry.id.clone());
+        drop(lanes);
+
+        let _ = self.events.send(entry.clone());
+        Ok(Enqueued {
+            entry,
+            start_dispatcher,
+        })
+    }
+
+    /// Takes the oldest waiting transaction of `fee_payer` and marks it dispatching, or
+    /// closes the lane when there is none (its dispatcher must then stop)
+    pub fn next(&self, fee_payer: &str) -> Option<(String, QueuedJob)> {
+        let mut lanes = self.lanes.lock().unwrap_or_else(|e| e.into_inner());
+        loop {
+            let Some(id) = lanes.get_mut(fee_payer).and_then(VecDeque::pop_front) else {
+                lanes.remove(fee_payer);
+                return None;
+            };
+            let Some(job) = self.jobs.get(&id).map(|job| job.clone()) else {
+                continue;
+            };
+            drop(lanes);
+
+            self.update(&id, |entry| {
+                entry.state = QueuedTransactionState::Dispatching.into();
+            });
+            return Some((id, job));
+        }
+    }
+
+    /// Waits until the queue-wide rate limit allows another send
+    pub async fn throttle(&self) {
+        let mut next_send = self.next_send.lock().await;
+        let now = Instant::now();
+        if *next_send > now {
+            tokio::time::sleep_until((*next_send).into()).await;
+        }
+        *next_send = Instant::now().max(*next_send) + self.send_interval;
+    }
+
+    /// Finishes a dispatched transaction in `state`, recording its outcome with `record`
+    pub fn finish(
+        &self,
+        id: &str,
+        state: QueuedTransactionState,
+        record: impl FnOnce(&mut QueuedTransaction),
+    ) {
+        self.jobs.remove(id);
+        match state {
+            QueuedTransactionState::Submitted => self.submitted.fetch_add(1, Ordering::Relaxed),
+            _ => self.failed.fetch_add(1, Ordering::Relaxed),
+        };
+        self.update(id, |entry| {
+            entry.state = state.into();
+            record(entry);
+        });
+    }
+
+    /// Returns a queue entry by id
+    pub fn get(&self, id: &str) -> Option<QueuedTransaction> {
+        self.entries.get(id).map(|entry| entry.clone())
+    }
+
+    /// Drops submitted and failed entries older than the retention period, returning how
+    /// many were dropped
+    pub fn purge_expired(&self) -> usize {
+        let cutoff = unix_timestamp() - QUEUE_RETENTION_SECONDS;
+        let before = self.entries.len();
+        self.entries
+            .retain(|_, entry| !is_finished(entry) || entry.updated_at > cutoff);
+        before.saturating_sub(self.entries.len())
+    }
+
+    /// Number of entries held, including finished ones within the retention period
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Whether no entries are held
+    pub fn is_empty(&self) -> bool {
+        self.entries.is_empty()
+    }
+
+    /// Subscribes to state changes of queue entries
+    pub fn subscribe(&self) -> broadcast::Receiver<QueuedTransaction> {
+        self.events.subscribe()
+    }
+
+    /// Current usage and counters since startup
+    pub fn stats(&self) -> QueueStats {
+        let lanes = self.lanes.lock().unwrap_or_else(|e| e.into_inner());
+        let pending: usize = lanes.values().map(VecDeque::len).sum();
+        QueueStats {
+            pending,
+            dispatching: self.jobs.len().saturating_sub(pending),
+            fee_payers: lanes.len(),
+            submitted: self.submitted.load(Ordering::Relaxed),
+            failed: self.failed.load(Ordering::Relaxed),
+        }
+    }
+
+    /// Applies `update` to an entry, stamps it and broadcasts the new state
+    fn update(&self, id: &str, update: impl FnOnce(&mut QueuedTransaction)) {
+        let Some(mut entry) = self.entries.get_mut(id) else {
+            return;
+        };
+        update(&mut entry);
+        entry.updated_at = unix_timestamp();
+        let _ = self.events.send(entry.clone());
+    }
+}
+
+/// Whether an entry has left the queue
+fn is_finished(entry: &QueuedTransaction) -> bool {
+    matches!(
+        entry.state(),
+        QueuedTransactionState::Submitted | QueuedTransactionState::Failed
+    )
+}
+
+impl std::fmt::Debug for TransactionQueue {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("TransactionQueue")
+            .field("enabled", &self.enabled)
+            .field("max_tps", &self.max_tps)
+            .field("max_pending", &self.max_pending)
+            .finish_non_exhaustive()
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    fn queue(max_tps: u32, max_pending: usize) -> TransactionQueue {
+        TransactionQueue::from_config(&QueueConfig {
+            enabled: true,
+            max_tps,
+            max_pending,
+        })
+        .unwrap()
+    }
+
+    fn job(fee_payer: &str) -> QueuedJob {
+        QueuedJob {
+            transaction: Transaction {
+                fee_payer: fee_payer.to_string(),
+                ..Default::default()
+            },
+            commitment_level: 0,
+            retry_policy: None,
+        }
+    }
+
+    #[test]
+    fn test_zero_rate_is_rejected_when_enabled() {
+        let config = QueueConfig {
+            enabled: true,
+            max_tps: 0,
+            ..Default::default()
+        };
+        assert!(TransactionQueue::from_config(&config).is_err());
+    }
+
+    #[test]
+    fn test_one_dispatcher_per_fee_payer_in_enqueue_order() {
+        let queue = queue(10, 10);
+        let first = queue
+            .enqueue(job("alice"), "sig-1", HashMap::new())
+            .unwrap();
+        let second = queue
+            .enqueue(job("alice"), "sig-2", HashMap::new())
+            .unwrap();
+        let other = queue.enqueue(job("bob"), "sig-3", HashMap::new()).unwrap();
+        assert!(first.start_dispatcher);
+        assert!(!second.start_dispatcher);
+        assert!(other.start_dispatcher);
+        assert!(first.entry.sequence < second.entry.sequence);
+
+        let (id, _) = queue.next("alice").unwrap();
+        assert_eq!(id, first.entry.id);
+        assert_eq!(queue.get(&id).unwrap().state(), QueuedTransactionState::Dispatching);
+        queue.finish(&id, QueuedTransactionState::Submitted, |_| {});
+
+        assert_eq!(queue.next("alice").unwrap().0, second.entry.id);
+        queue.finish(&second.entry.id, QueuedTransactionState::Failed, |entry| {
+            entry.dead_letter_id = "dl".to_string();
+        });
+        assert!(queue.next("alice").is_none());
+
+        // The lane closed, so the next transaction needs a new dispatcher
+        assert!(
+            queue
+                .enqueue(job("alice"), "sig-4", HashMap::new())
+                .unwrap()
+                .start_dispatcher
+        );
+
+        let stats = queue.stats();
+        assert_eq!((stats.pending, stats.fee_payers), (2, 2));
+        assert_eq!((stats.submitted, stats.failed), (1, 1));
+    }
+
+    #[test]
+    fn test_rejects_when_full() {
+        let queue = queue(10, 1);
+        queue
+            .enqueue(job("alice"), "sig-1", HashMap::new())
+            .unwrap();
+        assert!(queue.enqueue(job("bob"), "sig-2", HashMap::new()).is_err());
+    }
+
+    #[tokio::test]
+    async fn test_state_changes_are_broadcast() {
+        let queue = queue(10, 10);
+        let mut events = queue.subscribe();
+        let enqueued = queue
+            .enqueue(job("alice"), "sig-1", HashMap::new())
+            .unwrap();
+        queue.next("alice").unwrap();
+
+        assert_eq!(events.recv().await.unwrap().state(), QueuedTransactionState::Pending);
+        let dispatching = events.recv().await.unwrap();
+        assert_eq!(dispatching.id, enqueued.entry.id);
+        assert_eq!(dispatching.state(), QueuedTransactionState::Dispatching);
+    }
+
+    #[tokio::test]
+    async fn test_throttle_spaces_sends() {
+        let queue = queue(100, 10);
+        let started = Instant::now();
+        for _ in 0..3 {
+            queue.throttle().await;
+        }
+        assert!(started.elapsed() >= Duration::from_millis(20));
+    }
+}
@@ -0,0 +1,324 @@
+use base64::{engine::general_purpose::STANDARD, Engine};
+use serde::Deserialize;
+use serde_json::json;
+use solana_sdk::{pubkey::Pubkey, signature::Signature};
+use std::collections::HashMap;
+use std::sync::Mutex;
+use std::time::{Duration, Instant};
+
+use crate::config::VaultConfig;
+
+/// How long a single Vault call may take
+const REQUEST_TIMEOUT: Duration = Duration::from_secs(10);
+/// AppRole tokens are renewed this long before their lease runs out
+const TOKEN_RENEWAL_MARGIN: Duration = Duration::from_secs(30);
+
+/// How the server authenticates to Vault
+enum VaultAuth {
+    /// A fixed token
+    Token(String),
+    /// AppRole login, trading the role and secret ids for short-lived tokens
+    AppRole {
+        /// AppRole role id
+        role_id: String,
+        /// AppRole secret id
+        secret_id: String,
+    },
+}
+
+#[derive(Deserialize)]
+struct VaultResponse<T> {
+    data: T,
+}
+
+#[derive(Deserialize)]
+struct LoginResponse {
+    auth: LoginAuth,
+}
+
+#[derive(Deserialize)]
+struct LoginAuth {
+    client_token: String,
+    lease_duration: u64,
+}
+
+#[derive(Deserialize)]
+struct TransitKey {
+    #[serde(rename = "type")]
+    key_type: String,
+    latest_version: u32,
+    keys: HashMap<String, TransitKeyVersion>,
+}
+
+#[derive(Deserialize)]
+struct TransitKeyVersion {
+    #[serde(default)]
+    public_key: String,
+}
+
+#[derive(Deserialize)]
+struct TransitSignature {
+    signature: String,
+}
+
+/// Signs with ed25519 keys held in HashiCorp Vault's transit secrets engine.
+///
+/// Messages are sent to `<mount>/sign/<key>` and the private keys never leave Vault.
+/// Signatures are made by the latest version of a key and verified against its public
+/// key, which is read from `<mount>/keys/<key>` on every call so that rotations inside
+/// Vault are followed. Without a configured address Vault signing is disabled.
+pub struct VaultSigner {
+    http: reqwest::Client,
+    address: String,
+    namespace: String,
+    mount: String,
+    default_key: String,
+    auth: Option<VaultAuth>,
+    approle_token: Mutex<Option<(String, Instant)>>,
+}
+
+impl VaultSigner {
+    /// Builds the signer from configuration, rejecting incomplete authentication settings
+    pub fn from_config(config: &VaultConfig) -> Result<Self, String> {
+        let auth = if !config.token.is_empty() {
+            if !config.role_id.is_empty() || !config.secret_id.is_empty() {
+                return Err("Set either a Vault token or AppRole credentials, not both".to_string());
+            }
+            Some(VaultAuth::Token(config.token.clone()))
+        } else if config.role_id.is_empty() != config.secret_id.is_empty() {
+            return Err("AppRole login needs both a role id and a secret id".to_string());
+        } else if config.role_id.is_empty() {
+            None
+        } else {
+            Some(VaultAuth::AppRole {
+                role_id: config.role_id.clone(),
+                secret_id: config.secret_id.clone(),
+            })
+        };
+        if !config.address.is_empty() && auth.is_none() {
+            return Err("A Vault token or AppRole credentials are required".to_string());
+        }
+        let http = reqwest::Client::builder()
+            .timeout(REQUEST_TIMEOUT)
+            .build()
+            .map_err(|e| format!("Failed to build Vault HTTP client: {e}"))?;
+
+        Ok(Self {
+            http,
+            address: config.address.trim_end_matches('/').to_string(),
+            namespace: config.namespace.clone(),
+            mount: config.transit_mount.trim_matches('/').to_string(),
+            default_key: config.key_name.clone(),
+            auth,
+            approle_token: Mutex::new(None),
+        })
+    }
+
+    /// Whether a Vault server is configured
+    pub fn is_configured(&self) -> bool {
+        !self.address.is_empty()
+    }
+
+    /// Transit key used when a request names none (empty if there is no default)
+    pub fn default_key(&self) -> &str {
+        &self.default_key
+    }
+
+    /// Returns the public key of the latest version of the transit key `key_name`
+    pub async fn public_key(&self, key_name: &str) -> Result<Pubkey, String> {
+        let key: TransitKey = self
+            .call(reqwest::Method::GET, &format!("keys/{key_name}"), None)
+            .await?;
+        if key.key_type != "ed25519" {
+            return Err(format!(
+                "Vault key {key_name} is a {} key; Solana signing needs ed25519",
+                key.key_type
+            ));
+        }
+        let public_key = key
+            .keys
+            .get(&key.latest_version.to_string())
+            .map(|version| version.public_key.as_str())
+            .filter(|public_key| !public_key.is_empty())
+            .ok_or_else(|| format!("Vault key {key_name} has no public key"))?;
+        let bytes = STANDARD
+            .decode(public_key)
+            .map_err(|e| format!("Vault returned an invalid public key: {e}"))?;
+        Pubkey::try_from(bytes.as_slice())
+            .map_err(|_| format!("Vault returned a {} byte public key, expected 32", bytes.len()))
+    }
+
+    /// Has the transit key `key_name` sign `message`, verifying the result against
+    /// `public_key`
+    pub async fn sign(
+        &self,
+        key_name: &str,
+        public_key: &Pubkey,
+        message: &[u8],
+    ) -> Result<Signature, String> {
+        let signed: TransitSignature = self
+            .call(
+                reqwest::Method::POST,
+                &format!("sign/{key_name}"),
+                Some(json!({ "input": STANDARD.encode(message) })),
+            )
+            .await?;
+        let signature = parse_transit_signature(&signed.signature)?;
+        if !signature.verify(public_key.as_ref(), message) {
+            return Err(format!("Vault key {key_name} returned a signature that does not verify"));
+        }
+        Ok(signature)
+    }
+
+    /// Calls a transit endpoint under the configured mount
+    async fn call<T: serde::de::DeserializeOwned>(
+        &self,
+        method: reqwest::Method,
+        path: &str,
+        body: Option<serde_json::Value>,
+    ) -> Result<T, String> {
+        if !self.is_configured() {
+            return Err("No Vault server is configured".to_string());
+        }
+        let token = self.token().await?;
+        let url = format!("{}/v1/{}/{path}", self.address, self.mount);
+        let mut request = self.request(method, &url).header("X-Vault-Token", token);
+        if let Some(body) = body {
+            request = request.json(&body);
+        }
+        let response: VaultResponse<T> = send(request, path).await?;
+        Ok(response.data)
+    }
+
+    /// Returns a token for the configured authentication, logging in through AppRole
+    /// when there is no unexpired token
+    async fn token(&self) -> Result<String, String> {
+        let (role_id, secret_id) = match &self.auth {
+            Some(VaultAuth::Token(token)) => return Ok(token.clone()),
+            Some(VaultAuth::AppRole { role_id, secret_id }) => (role_id, secret_id),
+            None => return Err("No Vault credentials are configured".to_string()),
+        };
+        if let Some((token, expires)) = self.cached_token() {
+            if Instant::now() < expires {
+                return Ok(token);
+            }
+        }
+
+        let request = self
+            .request(reqwest::Method::POST, &format!("{}/v1/auth/approle/login", self.address))
+            .json(&json!({ "role_id": role_id, "secret_id": secret_id }));
+        let login: LoginResponse = send(request, "auth/approle/login").await?;
+        let expires = Instant::now()
+            + Duration::from_secs(login.auth.lease_duration).saturating_sub(TOKEN_RENEWAL_MARGIN);
+        if let Ok(mut cached) = self.approle_token.lock() {
+            *cached = Some((login.auth.client_token.clone(), expires));
+        }
+        Ok(login.auth.client_token)
+    }
+
+    fn cached_token(&self) -> Option<(String, Instant)> {
+        self.approle_token
+            .lock()
+            .ok()
+            .and_then(|cached| cached.clone())
+    }
+
+    /// Starts a request, scoped to the configured Vault namespace if there is one
+    fn request(&self, method: reqwest::Method, url: &str) -> reqwest::RequestBuilder {
+        let request = self.http.request(method, url);
+        if self.namespace.is_empty() {
+            request
+        } else {
+            request.header("X-Vault-Namespace", &self.namespace)
+        }
+    }
+}
+
+impl std::fmt::Debug for VaultSigner {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("VaultSigner")
+            .field("address", &self.address)
+            .field("mount", &self.mount)
+            .field("default_key", &self.default_key)
+            .finish_non_exhaustive()
+    }
+}
+
+/// Sends a Vault request, surfacing Vault's error list on failure
+async fn send<T: serde::de::DeserializeOwned>(
+    request: reqwest::RequestBuilder,
+    path: &str,
+) -> Result<T, String> {
+    let response = request
+        .send()
+        .await
+        .map_err(|e| format!("Vault {path} failed: {e}"))?;
+    let status = response.status();
+    let body = response
+        .text()
+        .await
+        .map_err(|e| format!("Vault {path} failed: {e}"))?;
+    if !status.is_success() {
+        return Err(format!("Vault {path} failed with {status}: {body}"));
+    }
+    serde_json::from_str(&body)
+        .map_err(|e| format!("Vault {path} returned an invalid response: {e}"))
+}
+
+/// Decodes a transit signature of the form `vault:v<version>:<base64>`
+fn parse_transit_signature(signature: &str) -> Result<Signature, String> {
+    let encoded = match signature.split(':').collect::<Vec<_>>().as_slice() {
+        ["vault", version, encoded] if version.starts_with('v') => *encoded,
+        _ => return Err(format!("Unexpected Vault signature format: {signature}")),
+    };
+    let bytes = STANDARD
+        .decode(encoded)
+        .map_err(|e| format!("Vault returned an invalid signature: {e}"))?;
+    Signature::try_from(bytes.as_slice())
+        .map_err(|_| format!("Vault returned a {} byte signature, expected 64", bytes.len()))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+    use solana_sdk::signature::{Keypair, Signer};
+
+    #[test]
+    fn test_authentication_settings() {
+        let disabled = VaultSigner::from_config(&VaultConfig::default()).unwrap();
+        assert!(!disabled.is_configured());
+
+        let approle = VaultConfig {
+            address: "https://vault.internal:8200".to_string(),
+            role_id: "role".to_string(),
+            secret_id: "secret".to_string(),
+            ..Default::default()
+        };
+        assert!(VaultSigner::from_config(&approle).unwrap().is_configured());
+
+        let missing_secret = VaultConfig {
+            secret_id: String::new(),
+            ..approle.clone()
+        };
+        assert!(VaultSigner::from_config(&missing_secret).is_err());
+
+        let no_credentials = VaultConfig {
+            role_id: String::new(),
+            secret_id: String::new(),
+            ..approle
+        };
+        assert!(VaultSigner::from_config(&no_credentials).is_err());
+    }
+
+    #[test]
+    fn test_parses_transit_signatures() {
+        let keypair = Keypair::new();
+        let signature = keypair.sign_message(b"message");
+        let encoded = format!("vault:v3:{}", STANDARD.encode(signature.as_ref()));
+
+        assert_eq!(parse_transit_signature(&encoded).unwrap(), signature);
+        assert!(parse_transit_signature(&STANDARD.encode(signature.as_ref())).is_err());
+        assert!(parse_transit_signature("vault:v1:AAAA").is_err());
+    }
+}
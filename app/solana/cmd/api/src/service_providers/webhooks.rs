@@ -0,0 +1,175 @@
+use hmac::{Hmac, Mac};
+use serde::Serialize;
+use sha2::Sha256;
+use std::time::Duration;
+use tracing::warn;
+
+use super::unix_timestamp;
+use crate::config::WebhookConfig;
+
+/// Header carrying the `sha256=<hex>` HMAC of the body when a secret is configured
+pub const SIGNATURE_HEADER: &str = "X-Protochain-Signature";
+
+/// Delay before the first retry of a failed delivery, doubled on each further attempt
+const RETRY_BASE_DELAY: Duration = Duration::from_millis(500);
+
+/// Envelope every webhook event is delivered in
+#[derive(Serialize)]
+struct Envelope<'a, T: Serialize> {
+    /// Unique per event, so receivers can drop retried duplicates
+    id: String,
+    /// Event type, e.g. `balance.below_threshold`
+    #[serde(rename = "type")]
+    event_type: &'a str,
+    /// Unix timestamp the event was raised at
+    created_at: i64,
+    data: &'a T,
+}
+
+/// Delivers events to the configured webhook endpoint.
+///
+/// Each event is POSTed as a JSON envelope. Failed deliveries (transport errors and non-2xx
+/// responses) are retried with exponential backoff up to `max_attempts` times; receivers
+/// should dedupe on the envelope `id`.
+pub struct WebhookSink {
+    url: String,
+    secret: String,
+    max_attempts: u32,
+    http: reqwest::Client,
+}
+
+impl WebhookSink {
+    /// Builds the sink from configuration, rejecting invalid endpoints
+    pub fn from_config(config: &WebhookConfig) -> Result<Self, String> {
+        if !config.url.is_empty()
+            && !config.url.starts_with("https://")
+            && !config.url.starts_with("http://")
+        {
+            return Err(format!("Webhook URL must be http(s), got {}", config.url));
+        }
+        if config.max_attempts == 0 {
+            return Err("max_attempts must be at least 1".to_string());
+        }
+        let http = reqwest::Client::builder()
+            .timeout(Duration::from_secs(config.timeout_seconds))
+            .build()
+            .map_err(|e| format!("Failed to build webhook HTTP client: {e}"))?;
+
+        Ok(Self {
+            url: config.url.clone(),
+            secret: config.secret.clone(),
+            max_attempts: config.max_attempts,
+            http,
+        })
+    }
+
+    /// Whether a webhook endpoint is configured
+    pub fn is_configured(&self) -> bool {
+        !self.url.is_empty()
+    }
+
+    /// Delivers an event of `event_type` carrying `data`, retrying failed attempts
+    pub async fn deliver<T: Serialize + Sync>(
+        &self,
+        event_type: &str,
+        data: &T,
+    ) -> Result<(), String> {
+        if !self.is_configured() {
+            return Err("No webhook URL is configured".to_string());
+        }
+        let body = serde_json::to_string(&Envelope {
+            id: uuid::Uuid::new_v4().simple().to_string(),
+            event_type,
+            created_at: unix_timestamp(),
+            data,
+        })
+        .map_err(|e| format!("Failed to encode webhook event: {e}"))?;
+
+        let mut delay = RETRY_BASE_DELAY;
+        let mut attempt = 1;
+        loop {
+            match self.post(&body).await {
+                Ok(()) => return Ok(()),
+                Err(e) if attempt >= self.max_attempts => {
+                    return Err(format!("Webhook delivery failed after {attempt} attempts: {e}"))
+                }
+                Err(e) => {
+                    warn!(event_type, attempt, error = %e, "Webhook delivery failed, retrying");
+                    tokio::time::sleep(delay).await;
+                    delay *= 2;
+                    attempt += 1;
+                }
+            }
+        }
+    }
+
+    /// Makes one delivery attempt
+    async fn post(&self, body: &str) -> Result<(), String> {
+        let mut request = self
+            .http
+            .post(&self.url)
+            .header(reqwest::header::CONTENT_TYPE, "application/json")
+            .body(body.to_string());
+        if !self.secret.is_empty() {
+            request = request.header(SIGNATURE_HEADER, signature(&self.secret, body)?);
+        }
+        let response = request.send().await.map_err(|e| e.to_string())?;
+        if !response.status().is_success() {
+            return Err(format!("Endpoint responded {}", response.status()));
+        }
+        Ok(())
+    }
+}
+
+impl std::fmt::Debug for WebhookSink {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        f.debug_struct("WebhookSink")
+            .field("url", &self.url)
+            .field("signed", &!self.secret.is_empty())
+            .field("max_attempts", &self.max_attempts)
+            .finish_non_exhaustive()
+    }
+}
+
+/// `sha256=<hex>` HMAC of `body` under `secret`
+fn signature(secret: &str, body: &str) -> Result<String, String> {
+    let mut mac = Hmac::<Sha256>::new_from_slice(secret.as_bytes())
+        .map_err(|e| format!("Invalid webhook secret: {e}"))?;
+    mac.update(body.as_bytes());
+    Ok(format!("sha256={}", hex::encode(mac.finalize().into_bytes())))
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_signature_is_hmac_sha256_of_body() {
+        // RFC 4231 test case 2
+        assert_eq!(
+            signature("Jefe", "what do ya want for nothing?").unwrap(),
+            "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+        );
+    }
+
+    #[test]
+    fn test_rejects_invalid_configuration() {
+        let config = WebhookConfig::default();
+        assert!(!WebhookSink::from_config(&config).unwrap().is_configured());
+
+        let with_url = |url: &str| WebhookConfig {
+            url: url.to_string(),
+            ..WebhookConfig::default()
+        };
+        assert!(WebhookSink::from_config(&with_url("https://hooks.example.com/solana"))
+            .unwrap()
+            .is_configured());
+        assert!(WebhookSink::from_config(&with_url("ftp://hooks.example.com")).is_err());
+        assert!(WebhookSink::from_config(&WebhookConfig {
+            max_attempts: 0,
+            ..with_url("https://hooks.example.com")
+        })
+        .is_err());
+    }
+}
@@ -1,13 +1,16 @@
 use dashmap::DashMap;
+use solana_account_decoder::{UiAccount, UiAccountEncoding};
 use solana_client::nonblocking::rpc_client::RpcClient;
-use solana_client::rpc_config::RpcSignatureSubscribeConfig;
+use solana_client::rpc_config::{RpcAccountInfoConfig, RpcSignatureSubscribeConfig};
 use solana_client::rpc_response::{
     ProcessedSignatureResult, ReceivedSignatureResult, Response, RpcSignatureResult,
 };
 use solana_pubsub_client::nonblocking::pubsub_client::PubsubClient;
 use solana_sdk::{
-    commitment_config::CommitmentConfig, signature::Signature, transaction::TransactionError,
+    account::Account, commitment_config::CommitmentConfig, pubkey::Pubkey, signature::Signature,
+    transaction::TransactionError,
 };
+use solana_transaction_status::TransactionStatus as TransactionStatusResult;
 use std::sync::Arc;
 use std::time::Duration;
 use tokio::sync::mpsc;
@@ -17,9 +20,11 @@ use tracing::{debug, info, warn};
 
 use protochain_api::protochain::solana::r#type::v1::CommitmentLevel;
 use protochain_api::protochain::solana::transaction::v1::{
-    MonitorTransactionResponse, TransactionStatus,
+    MonitorTransactionResponse, MonitoringMechanism, RebroadcastState, TransactionStatus,
 };
 
+use super::polling::PollingSchedule;
+
 /// Handle for managing a signature subscription
 #[derive(Debug)]
 struct SubscriptionHandle {
@@ -27,6 +32,19 @@ struct SubscriptionHandle {
     abort_handle: tokio::task::AbortHandle,
 }
 
+/// An account state observed by an account subscription
+#[derive(Debug, Clone)]
+pub struct AccountUpdate {
+    /// Slot the state was observed at
+    pub slot: u64,
+    /// The account, `None` while no account exists at the address
+    pub account: Option<Account>,
+    /// How the state was observed
+    pub mechanism: MonitoringMechanism,
+    /// RPC polls performed so far, including the initial read
+    pub poll_count: u32,
+}
+
 /// WebSocket manager for handling Solana signature subscriptions
 #[derive(Clone)]
 pub struct WebSocketManager {
@@ -111,28 +129,93 @@ impl WebSocketManager {
         )
     }
 
-    /// Creates a timeout response for real-time monitoring
-    fn create_realtime_timeout_response(signature_str: &str) -> MonitorTransactionResponse {
+    /// Creates a timeout response for real-time monitoring. The timeout is reported as
+    /// observed by polling, the fallback that ran until the deadline without a result.
+    fn create_realtime_timeout_response(
+        signature_str: &str,
+        poll_count: u32,
+    ) -> MonitorTransactionResponse {
+        Self::create_failure_response(
+            signature_str,
+            TransactionStatus::Timeout,
+            "Monitoring timeout reached".to_string(),
+            MonitoringMechanism::Polling,
+            poll_count,
+        )
+    }
+
+    /// Creates a synthetic response that was not observed on-chain (timeouts, setup
+    /// failures), reported under the mechanism that failed
+    fn create_failure_response(
+        signature_str: &str,
+        status: TransactionStatus,
+        error_message: String,
+        mechanism: MonitoringMechanism,
+        poll_count: u32,
+    ) -> MonitorTransactionResponse {
         MonitorTransactionResponse {
             signature: signature_str.to_string(),
-            status: TransactionStatus::Timeout.into(),
+            status: status.into(),
             slot: 0,
-            error_message: "Monitoring timeout reached".to_string(),
+            error_message,
             logs: vec![],
             compute_units_consumed: 0,
             current_commitment: CommitmentLevel::Unspecified.into(),
+            mechanism: mechanism.into(),
+            poll_count,
+            rebroadcast_count: 0,
+            rebroadcast_state: RebroadcastState::Unspecified.into(),
+            instruction_failure: None,
+            inner_instructions: vec![],
+            call_tree: vec![],
         }
     }
 
+    /// Creates a response from a status observed via `getSignatureStatuses`
+    fn create_polled_response(
+        signature_str: &str,
+        status: &TransactionStatusResult,
+        poll_count: u32,
+    ) -> (MonitorTransactionResponse, TransactionStatus) {
+        let (transaction_status, error_message) = Self::summarize_status(
+            status.err.as_ref(),
+            status.confirmations.map(|value| value as u64),
+        );
+
+        let response = MonitorTransactionResponse {
+            signature: signature_str.to_string(),
+            status: transaction_status.into(),
+            slot: status.slot,
+            error_message: error_message.unwrap_or_default(),
+            logs: Vec::new(), // RPC polling doesn't include logs
+            compute_units_consumed: 0,
+            current_commitment: Self::commitment_from_status(transaction_status).into(),
+            mechanism: MonitoringMechanism::Polling.into(),
+            poll_count,
+            rebroadcast_count: 0,
+            rebroadcast_state: RebroadcastState::Unspecified.into(),
+            instruction_failure: None,
+            inner_instructions: vec![],
+            call_tree: vec![],
+        };
+
+        (response, transaction_status)
+    }
+
     /// Handles a notification response and returns true if monitoring should stop
     fn handle_notification_response(
         notification: Response<RpcSignatureResult>,
         signature_str: &str,
         include_logs: bool,
+        poll_count: u32,
         sender: &mpsc::UnboundedSender<MonitorTransactionResponse>,
     ) -> bool {
-        let response =
-            Self::process_signature_notification(notification, signature_str, include_logs);
+        let response = Self::process_signature_notification(
+            notification,
+            signature_str,
+            include_logs,
+            poll_count,
+        );
         let response_status = response.status();
         let is_terminal = Self::is_terminal_status(response_status);
 
@@ -182,6 +265,7 @@ impl WebSocketManager {
         commitment_level: CommitmentLevel,
         include_logs: bool,
         timeout_seconds: Option<u32>,
+        polling: PollingSchedule,
     ) -> Result<mpsc::UnboundedReceiver<MonitorTransactionResponse>, Box<Status>> {
         // Validate signature format
         let parsed_signature = signature
@@ -199,6 +283,7 @@ impl WebSocketManager {
             commitment_level = ?commitment_level,
             include_logs = include_logs,
             timeout_seconds = ?timeout_seconds,
+            initial_poll_interval = ?polling.initial_interval(),
             "🔔 Creating signature subscription"
         );
 
@@ -217,6 +302,7 @@ impl WebSocketManager {
                 commitment,
                 include_logs,
                 timeout_duration,
+                polling,
                 tx_clone,
                 ws_url_clone,
                 rpc_client_clone,
@@ -249,6 +335,7 @@ impl WebSocketManager {
         commitment: CommitmentConfig,
         include_logs: bool,
         timeout: Duration,
+        polling: PollingSchedule,
         sender: mpsc::UnboundedSender<MonitorTransactionResponse>,
         ws_url: String,
         rpc_client: Arc<RpcClient>,
@@ -260,27 +347,14 @@ impl WebSocketManager {
 
         // CRITICAL FIX: Check current transaction status first
         // This prevents the race condition where transactions confirm before WebSocket subscription
+        // Only the fallback polls below are counted, not this initial check
+        let mut poll_count: u32 = 0;
         match rpc_client.get_signature_statuses(&[signature]).await {
             Ok(status_response) => {
                 if let Some(Some(status)) = status_response.value.first() {
                     // Transaction already has a final status - send it immediately
-                    let (transaction_status, error_message) = Self::summarize_status(
-                        status.err.as_ref(),
-                        status.confirmations.map(|value| value as u64),
-                    );
-
-                    let logs = Vec::new(); // Could fetch tx details for logs
-
-                    // Send the current status immediately
-                    let response = MonitorTransactionResponse {
-                        signature: signature_str.clone(),
-                        status: transaction_status.into(),
-                        slot: status.slot,
-                        error_message: error_message.unwrap_or_default(),
-                        logs,
-                        compute_units_consumed: 0,
-                        current_commitment: Self::commitment_from_status(transaction_status).into(),
-                    };
+                    let (response, transaction_status) =
+                        Self::create_polled_response(&signature_str, status, poll_count);
 
                     info!(
                         signature = %signature_str,
@@ -316,15 +390,13 @@ impl WebSocketManager {
                     error = %e,
                     "❌ Failed to create PubsubClient"
                 );
-                let _ = sender.send(MonitorTransactionResponse {
-                    signature: signature_str.clone(),
-                    status: TransactionStatus::Failed.into(),
-                    slot: 0,
-                    error_message: format!("WebSocket connection failed: {e}"),
-                    logs: vec![],
-                    compute_units_consumed: 0,
-                    current_commitment: CommitmentLevel::Unspecified.into(),
-                });
+                let _ = sender.send(Self::create_failure_response(
+                    &signature_str,
+                    TransactionStatus::Failed,
+                    format!("WebSocket connection failed: {e}"),
+                    MonitoringMechanism::Websocket,
+                    poll_count,
+                ));
                 return;
             }
         };
@@ -344,15 +416,13 @@ impl WebSocketManager {
                     error = %e,
                     "❌ Failed to create signature subscription"
                 );
-                let _ = sender.send(MonitorTransactionResponse {
-                    signature: signature_str.clone(),
-                    status: TransactionStatus::Failed.into(),
-                    slot: 0,
-                    error_message: format!("Signature subscription failed: {e}"),
-                    logs: vec![],
-                    compute_units_consumed: 0,
-                    current_commitment: CommitmentLevel::Unspecified.into(),
-                });
+                let _ = sender.send(Self::create_failure_response(
+                    &signature_str,
+                    TransactionStatus::Failed,
+                    format!("Signature subscription failed: {e}"),
+                    MonitoringMechanism::Websocket,
+                    poll_count,
+                ));
                 return;
             }
         };
@@ -366,9 +436,12 @@ impl WebSocketManager {
         let timeout_task = tokio::time::sleep(timeout);
         tokio::pin!(timeout_task);
 
-        // HYBRID APPROACH: Listen for WebSocket updates with RPC polling fallback
-        let mut poll_interval = tokio::time::interval(Duration::from_millis(200));
-        poll_interval.set_missed_tick_behavior(tokio::time::MissedTickBehavior::Skip);
+        // HYBRID APPROACH: Listen for WebSocket updates with adaptive RPC polling fallback.
+        // Polling starts fast right after submission and backs off towards the ceiling,
+        // keeping load on the RPC node low for transactions that take a while to land.
+        let mut poll_delay = polling.initial_interval();
+        let poll_timer = tokio::time::sleep(poll_delay);
+        tokio::pin!(poll_timer);
 
         loop {
             tokio::select! {
@@ -378,7 +451,7 @@ impl WebSocketManager {
                             signature = %signature_str,
                             "📡 Received WebSocket notification"
                         );
-                        if Self::handle_notification_response(response, &signature_str, include_logs, &sender) {
+                        if Self::handle_notification_response(response, &signature_str, include_logs, poll_count, &sender) {
                             break;
                         }
                     } else {
@@ -389,29 +462,20 @@ impl WebSocketManager {
                         break;
                     }
                 }
-                _ = poll_interval.tick() => {
+                () = &mut poll_timer => {
+                    poll_count = poll_count.saturating_add(1);
+
                     // Fallback: Poll RPC for status updates (for unreliable WebSocket environments)
                     if let Ok(status_response) = rpc_client.get_signature_statuses(&[signature]).await {
                         if let Some(Some(status)) = status_response.value.first() {
                             // Transaction status found via RPC polling
-                            let (transaction_status, error_message) = Self::summarize_status(
-                                status.err.as_ref(),
-                                status.confirmations.map(|value| value as u64),
-                            );
-
-                            let response = MonitorTransactionResponse {
-                                signature: signature_str.clone(),
-                                status: transaction_status.into(),
-                                slot: status.slot,
-                                error_message: error_message.unwrap_or_default(),
-                                logs: Vec::new(), // RPC polling doesn't include logs by default
-                                compute_units_consumed: 0,
-                                current_commitment: Self::commitment_from_status(transaction_status).into(),
-                            };
+                            let (response, transaction_status) =
+                                Self::create_polled_response(&signature_str, status, poll_count);
 
                             info!(
                                 signature = %signature_str,
                                 status = ?transaction_status,
+                                poll_count = poll_count,
                                 "✅ Transaction status found via RPC polling (WebSocket fallback)"
                             );
 
@@ -427,13 +491,17 @@ impl WebSocketManager {
                     } else {
                         // RPC polling failed, continue waiting
                     }
+
+                    poll_delay = polling.next_interval(poll_delay);
+                    poll_timer.as_mut().reset(tokio::time::Instant::now() + poll_delay);
                 }
                 () = &mut timeout_task => {
                     warn!(
                         signature = %signature_str,
+                        poll_count = poll_count,
                         "⏰ Timeout reached (both WebSocket and RPC polling failed)"
                     );
-                    let _ = sender.send(Self::create_realtime_timeout_response(&signature_str));
+                    let _ = sender.send(Self::create_realtime_timeout_response(&signature_str, poll_count));
                     break;
                 }
             }
@@ -450,6 +518,7 @@ impl WebSocketManager {
         notification: Response<RpcSignatureResult>,
         signature: &str,
         include_logs: bool,
+        poll_count: u32,
     ) -> MonitorTransactionResponse {
         let (status, commitment_level, error_message, logs, compute_units) = match notification
             .value
@@ -506,9 +575,262 @@ impl WebSocketManager {
             logs,
             compute_units_consumed: compute_units.unwrap_or(0),
             current_commitment: commitment_level.into(),
+            mechanism: MonitoringMechanism::Websocket.into(),
+            poll_count,
+            rebroadcast_count: 0,
+            rebroadcast_state: RebroadcastState::Unspecified.into(),
+            instruction_failure: None,
+            inner_instructions: vec![],
+            call_tree: vec![],
         }
     }
 
+    /// Subscribes to changes of an account, sending its current state first and then an
+    /// update whenever it changes.
+    ///
+    /// Uses the same hybrid approach as signature monitoring: an `accountSubscribe`
+    /// notification stream with `getAccountInfo` polling alongside. If the WebSocket
+    /// cannot be used, polling carries on alone. The subscription ends when the receiver
+    /// is dropped or `timeout` (if any) elapses.
+    pub fn subscribe_to_account(
+        &self,
+        address: Pubkey,
+        commitment: CommitmentConfig,
+        timeout: Option<Duration>,
+        polling: PollingSchedule,
+    ) -> mpsc::UnboundedReceiver<AccountUpdate> {
+        let (tx, rx) = mpsc::unbounded_channel();
+
+        info!(
+            address = %address,
+            commitment = ?commitment.commitment,
+            timeout = ?timeout,
+            initial_poll_interval = ?polling.initial_interval(),
+            "🔔 Creating account subscription"
+        );
+
+        tokio::spawn(Self::handle_account_subscription(
+            address,
+            commitment,
+            timeout,
+            polling,
+            tx,
+            self.ws_url.clone(),
+            Arc::clone(&self.rpc_client),
+        ));
+
+        rx
+    }
+
+    /// Handles an account subscription until the subscriber leaves or the timeout elapses
+    #[allow(clippy::cognitive_complexity)]
+    async fn handle_account_subscription(
+        address: Pubkey,
+        commitment: CommitmentConfig,
+        timeout: Option<Duration>,
+        polling: PollingSchedule,
+        sender: mpsc::UnboundedSender<AccountUpdate>,
+        ws_url: String,
+        rpc_client: Arc<RpcClient>,
+    ) {
+        let mut last: Option<(u64, Option<Account>)> = None;
+
+        // The initial read counts as the first poll and gives the subscriber a starting state
+        let mut poll_count: u32 = 1;
+        match rpc_client
+            .get_account_with_commitment(&address, commitment)
+            .await
+        {
+            Ok(response) => {
+                if let Some(update) = Self::record_account_state(
+                    &mut last,
+                    response.context.slot,
+                    response.value,
+                    MonitoringMechanism::Polling,
+                    poll_count,
+                ) {
+                    let _ = sender.send(update);
+                }
+            }
+            Err(e) => warn!(
+                address = %address,
+                error = %e,
+                "⚠️  Failed to read account, proceeding with subscription"
+            ),
+        }
+
+        let pubsub_client = match PubsubClient::new(&ws_url).await {
+            Ok(client) => Some(client),
+            Err(e) => {
+                warn!(
+                    address = %address,
+                    error = %e,
+                    "❌ Failed to create PubsubClient, monitoring account by polling only"
+                );
+                None
+            }
+        };
+        let config = RpcAccountInfoConfig {
+            encoding: Some(UiAccountEncoding::Base64),
+            data_slice: None,
+            commitment: Some(commitment),
+            min_context_slot: None,
+        };
+        let subscription = match &pubsub_client {
+            Some(client) => match client.account_subscribe(&address, Some(config)).await {
+                Ok(subscription) => Some(subscription),
+                Err(e) => {
+                    warn!(
+                        address = %address,
+                        error = %e,
+                        "❌ Failed to create account subscription, monitoring by polling only"
+                    );
+                    None
+                }
+            },
+            None => None,
+        };
+        let mut websocket_open = subscription.is_some();
+        let mut stream = match subscription {
+            Some((stream, _unsubscribe)) => stream,
+            None => futures_util::StreamExt::boxed(futures_util::stream::pending::<
+                Response<UiAccount>,
+            >()),
+        };
+
+        let timeout_task = tokio::time::sleep(timeout.unwrap_or_default());
+        tokio::pin!(timeout_task);
+
+        // Polling backs off while the account is quiet and returns to its initial pace after
+        // each change it observes
+        let mut poll_delay = polling.initial_interval();
+        let poll_timer = tokio::time::sleep(poll_delay);
+        tokio::pin!(poll_timer);
+
+        loop {
+            tokio::select! {
+                notification = stream.next(), if websocket_open => {
+                    let Some(notification) = notification else {
+                        debug!(address = %address, "🔚 Account WebSocket stream ended, continuing with polling");
+                        websocket_open = false;
+                        continue;
+                    };
+                    // Closed accounts are reported as empty system accounts
+                    let account = notification
+                        .value
+                        .decode::<Account>()
+                        .filter(|account| account.lamports > 0);
+                    let update = Self::record_account_state(
+                        &mut last,
+                        notification.context.slot,
+                        account,
+                        MonitoringMechanism::Websocket,
+                        poll_count,
+                    );
+                    if update.is_some_and(|update| sender.send(update).is_err()) {
+                        break; // Subscriber went away
+                    }
+                }
+                () = &mut poll_timer => {
+                    if sender.is_closed() {
+                        break;
+                    }
+                    poll_count = poll_count.saturating_add(1);
+
+                    let mut changed = false;
+                    match rpc_client.get_account_with_commitment(&address, commitment).await {
+                        Ok(response) => {
+                            let update = Self::record_account_state(
+                                &mut last,
+                                response.context.slot,
+                                response.value,
+                                MonitoringMechanism::Polling,
+                                poll_count,
+                            );
+                            changed = update.is_some();
+                            if update.is_some_and(|update| sender.send(update).is_err()) {
+                                break; // Subscriber went away
+                            }
+                        }
+                        Err(e) => debug!(address = %address, error = %e, "Account poll failed"),
+                    }
+
+                    poll_delay = if changed {
+                        polling.initial_interval()
+                    } else {
+                        polling.next_interval(poll_delay)
+                    };
+                    poll_timer.as_mut().reset(tokio::time::Instant::now() + poll_delay);
+                }
+                () = &mut timeout_task, if timeout.is_some() => {
+                    debug!(address = %address, poll_count = poll_count, "⏰ Account monitoring timeout reached");
+                    break;
+                }
+            }
+        }
+
+        debug!(address = %address, "🏁 Account subscription completed");
+    }
+
+    /// Records an observed account state, returning the update to send if it is new.
+    /// States from a slot older than the last one recorded, or equal to it, are ignored.
+    fn record_account_state(
+        last: &mut Option<(u64, Option<Account>)>,
+        slot: u64,
+        account: Option<Account>,
+        mechanism: MonitoringMechanism,
+        poll_count: u32,
+    ) -> Option<AccountUpdate> {
+        if let Some((last_slot, last_account)) = last.as_ref() {
+            if slot < *last_slot || account == *last_account {
+                return None;
+            }
+        }
+        *last = Some((slot, account.clone()));
+        Some(AccountUpdate {
+            slot,
+            account,
+            mechanism,
+            poll_count,
+        })
+    }
+
+    /// Subscribes to new slots, forwarding each slot number as the node reports it.
+    ///
+    /// The channel closes if the WebSocket connection cannot be made or drops, so callers
+    /// must keep a polling fallback. The subscription ends when the receiver is dropped.
+    pub fn subscribe_to_slots(&self) -> mpsc::UnboundedReceiver<u64> {
+        let (tx, rx) = mpsc::unbounded_channel();
+        let ws_url = self.ws_url.clone();
+
+        tokio::spawn(async move {
+            let pubsub_client = match PubsubClient::new(&ws_url).await {
+                Ok(client) => client,
+                Err(e) => {
+                    warn!(error = %e, "❌ Failed to create PubsubClient for slot subscription");
+                    return;
+                }
+            };
+            let (mut stream, _unsubscribe) = match pubsub_client.slot_subscribe().await {
+                Ok(subscription) => subscription,
+                Err(e) => {
+                    warn!(error = %e, "❌ Failed to create slot subscription");
+                    return;
+                }
+            };
+            debug!("✅ Slot subscription established");
+
+            while let Some(slot_info) = stream.next().await {
+                if tx.send(slot_info.slot).is_err() {
+                    break; // Subscriber went away
+                }
+            }
+            debug!("🔚 Slot subscription ended");
+        });
+
+        rx
+    }
+
     /// Converts proto `CommitmentLevel` to Solana `CommitmentConfig`
     const fn commitment_level_to_config(level: CommitmentLevel) -> CommitmentConfig {
         match level {
@@ -616,6 +938,47 @@ mod tests {
         assert!(derive_websocket_url_from_rpc("invalid://url").is_err());
     }
 
+    #[test]
+    fn test_account_states_are_sent_once_and_never_backwards() {
+        let account = Account::new(1_000, 0, &Pubkey::new_unique());
+        let mut last = None;
+
+        let first = WebSocketManager::record_account_state(
+            &mut last,
+            10,
+            Some(account.clone()),
+            MonitoringMechanism::Polling,
+            1,
+        );
+        assert_eq!(first.map(|update| update.slot), Some(10));
+        // The same state seen again, by either mechanism, is not resent
+        assert!(WebSocketManager::record_account_state(
+            &mut last,
+            11,
+            Some(account.clone()),
+            MonitoringMechanism::Websocket,
+            1,
+        )
+        .is_none());
+        // An older state arriving late is dropped
+        assert!(WebSocketManager::record_account_state(
+            &mut last,
+            9,
+            None,
+            MonitoringMechanism::Polling,
+            2,
+        )
+        .is_none());
+        let closed = WebSocketManager::record_account_state(
+            &mut last,
+            12,
+            None,
+            MonitoringMechanism::Websocket,
+            2,
+        );
+        assert!(closed.is_some_and(|update| update.account.is_none()));
+    }
+
     #[tokio::test]
     async fn test_websocket_manager_creation() {
         // Test WebSocket manager creation
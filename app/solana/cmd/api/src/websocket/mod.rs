@@ -1,4 +1,7 @@
 /// WebSocket connection manager for real-time transaction monitoring
 pub mod manager;
+/// Adaptive RPC polling schedule for the WebSocket fallback
+pub mod polling;
 
-pub use manager::{derive_websocket_url_from_rpc, WebSocketManager};
+pub use manager::{derive_websocket_url_from_rpc, AccountUpdate, WebSocketManager};
+pub use polling::PollingSchedule;
@@ -0,0 +1,150 @@
+use std::time::Duration;
+
+/// Default delay before the first fallback poll after monitoring starts
+pub const DEFAULT_INITIAL_POLL_INTERVAL_MS: u32 = 200;
+/// Default ceiling the poll interval decays towards
+pub const DEFAULT_MAX_POLL_INTERVAL_MS: u32 = 2_000;
+/// Default growth factor applied after each poll
+pub const DEFAULT_POLL_BACKOFF_FACTOR: f64 = 1.5;
+
+/// Lower bound for any poll interval, protecting the RPC node from hot loops
+const MIN_POLL_INTERVAL_MS: u32 = 50;
+/// Upper bound for any poll interval
+const MAX_POLL_INTERVAL_MS: u32 = 30_000;
+
+/// Adaptive RPC polling schedule used as the WebSocket fallback.
+///
+/// Polls quickly right after submission (when confirmation is most likely to be
+/// imminent) and backs off geometrically towards `max_interval` afterwards.
+#[derive(Debug, Clone, Copy, PartialEq)]
+pub struct PollingSchedule {
+    initial_interval: Duration,
+    max_interval: Duration,
+    backoff_factor: f64,
+}
+
+impl Default for PollingSchedule {
+    fn default() -> Self {
+        Self {
+            initial_interval: Duration::from_millis(u64::from(DEFAULT_INITIAL_POLL_INTERVAL_MS)),
+            max_interval: Duration::from_millis(u64::from(DEFAULT_MAX_POLL_INTERVAL_MS)),
+            backoff_factor: DEFAULT_POLL_BACKOFF_FACTOR,
+        }
+    }
+}
+
+impl PollingSchedule {
+    /// Builds a schedule from request parameters, where zero values select the defaults
+    pub fn from_request(
+        initial_interval_ms: u32,
+        max_interval_ms: u32,
+        backoff_factor: f64,
+    ) -> Result<Self, String> {
+        let initial_ms = if initial_interval_ms == 0 {
+            DEFAULT_INITIAL_POLL_INTERVAL_MS
+        } else {
+            initial_interval_ms
+        };
+        let max_ms = if max_interval_ms == 0 {
+            DEFAULT_MAX_POLL_INTERVAL_MS.max(initial_ms)
+        } else {
+            max_interval_ms
+        };
+        let factor = if backoff_factor == 0.0 {
+            DEFAULT_POLL_BACKOFF_FACTOR
+        } else {
+            backoff_factor
+        };
+
+        if !(MIN_POLL_INTERVAL_MS..=MAX_POLL_INTERVAL_MS).contains(&initial_ms) {
+            return Err(format!(
+                "Initial poll interval must be between {MIN_POLL_INTERVAL_MS} and {MAX_POLL_INTERVAL_MS} ms"
+            ));
+        }
+        if !(MIN_POLL_INTERVAL_MS..=MAX_POLL_INTERVAL_MS).contains(&max_ms) {
+            return Err(format!(
+                "Max poll interval must be between {MIN_POLL_INTERVAL_MS} and {MAX_POLL_INTERVAL_MS} ms"
+            ));
+        }
+        if max_ms < initial_ms {
+            return Err("Max poll interval must not be less than the initial interval".to_string());
+        }
+        if !(1.0..=10.0).contains(&factor) {
+            return Err("Poll backoff factor must be between 1.0 and 10.0".to_string());
+        }
+
+        Ok(Self {
+            initial_interval: Duration::from_millis(u64::from(initial_ms)),
+            max_interval: Duration::from_millis(u64::from(max_ms)),
+            backoff_factor: factor,
+        })
+    }
+
+    /// Delay before the first poll
+    pub const fn initial_interval(&self) -> Duration {
+        self.initial_interval
+    }
+
+    /// Delay to wait after a poll that used `current`
+    pub fn next_interval(&self, current: Duration) -> Duration {
+        current.mul_f64(self.backoff_factor).min(self.max_interval)
+    }
+}
+
+#[cfg(test)]
+#[allow(clippy::unwrap_used)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_defaults_from_zero_values() {
+        let schedule = PollingSchedule::from_request(0, 0, 0.0).unwrap();
+        assert_eq!(schedule, PollingSchedule::default());
+        assert_eq!(schedule.initial_interval(), Duration::from_millis(200));
+    }
+
+    #[test]
+    fn test_interval_decays_to_ceiling() {
+        let schedule = PollingSchedule::from_request(100, 400, 2.0).unwrap();
+
+        let mut interval = schedule.initial_interval();
+        let mut observed = vec![interval];
+        for _ in 0..4 {
+            interval = schedule.next_interval(interval);
+            observed.push(interval);
+        }
+
+        assert_eq!(
+            observed,
+            vec![
+                Duration::from_millis(100),
+                Duration::from_millis(200),
+                Duration::from_millis(400),
+                Duration::from_millis(400),
+                Duration::from_millis(400),
+            ]
+        );
+    }
+
+    #[test]
+    fn test_constant_interval_with_unit_factor() {
+        let schedule = PollingSchedule::from_request(250, 250, 1.0).unwrap();
+        let interval = schedule.initial_interval();
+        assert_eq!(schedule.next_interval(interval), interval);
+    }
+
+    #[test]
+    fn test_large_initial_interval_raises_default_max() {
+        let schedule = PollingSchedule::from_request(5_000, 0, 0.0).unwrap();
+        let interval = schedule.initial_interval();
+        assert_eq!(schedule.next_interval(interval), Duration::from_millis(5_000));
+    }
+
+    #[test]
+    fn test_invalid_schedules_rejected() {
+        assert!(PollingSchedule::from_request(10, 0, 0.0).is_err());
+        assert!(PollingSchedule::from_request(500, 100, 0.0).is_err());
+        assert!(PollingSchedule::from_request(0, 60_000, 0.0).is_err());
+        assert!(PollingSchedule::from_request(0, 0, 0.5).is_err());
+    }
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// splitMethod splits "package.Service.Method" or "package.Service/Method" into the
+// fully qualified service name and the method name
+func splitMethod(name string) (string, string, error) {
+	name = strings.TrimPrefix(name, "/")
+	index := strings.LastIndexAny(name, "./")
+	if index <= 0 || index == len(name)-1 {
+		return "", "", fmt.Errorf("method %q must be of the form package.Service.Method", name)
+	}
+	return name[:index], name[index+1:], nil
+}
+
+// decodeRequests parses the JSON input into request messages. Client-streaming methods
+// accept a JSON array, sent as one message per element; every other method takes a
+// single object.
+func decodeRequests(method protoreflect.MethodDescriptor, types *dynamicpb.Types, input []byte) ([]proto.Message, error) {
+	unmarshal := protojson.UnmarshalOptions{Resolver: types}
+	var documents []json.RawMessage
+	trimmed := bytes.TrimSpace(input)
+	if method.IsStreamingClient() && bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &documents); err != nil {
+			return nil, fmt.Errorf("invalid request array: %w", err)
+		}
+	} else {
+		documents = []json.RawMessage{trimmed}
+	}
+
+	requests := make([]proto.Message, 0, len(documents))
+	for i, document := range documents {
+		request := dynamicpb.NewMessage(method.Input())
+		if err := unmarshal.Unmarshal(document, request); err != nil {
+			return nil, fmt.Errorf("invalid %s (message %d): %w", method.Input().FullName(), i, err)
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// invoke calls method with the JSON input and writes every response as JSON to out,
+// one after another for server-streaming methods
+func invoke(
+	ctx context.Context,
+	conn grpc.ClientConnInterface,
+	files *protoregistry.Files,
+	method protoreflect.MethodDescriptor,
+	input []byte,
+	out io.Writer,
+) error {
+	types := dynamicpb.NewTypes(files)
+	requests, err := decodeRequests(method, types, input)
+	if err != nil {
+		return err
+	}
+
+	marshal := protojson.MarshalOptions{Multiline: true, Indent: "  ", Resolver: types}
+	write := func(response proto.Message) error {
+		encoded, err := marshal.Marshal(response)
+		if err != nil {
+			return fmt.Errorf("failed to encode response: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(encoded))
+		return err
+	}
+
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	if !method.IsStreamingClient() && !method.IsStreamingServer() {
+		response := dynamicpb.NewMessage(method.Output())
+		if err := conn.Invoke(ctx, fullMethod, requests[0], response); err != nil {
+			return err
+		}
+		return write(response)
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    string(method.Name()),
+		ServerStreams: method.IsStreamingServer(),
+		ClientStreams: method.IsStreamingClient(),
+	}, fullMethod)
+	if err != nil {
+		return err
+	}
+	for _, request := range requests {
+		if err := stream.SendMsg(request); err != nil {
+			return err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		response := dynamicpb.NewMessage(method.Output())
+		if err := stream.RecvMsg(response); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := write(response); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// reflectionClient resolves service descriptors from a server's reflection service
+type reflectionClient struct {
+	stream reflectionpb.ServerReflection_ServerReflectionInfoClient
+}
+
+// newReflectionClient opens a reflection stream on the given connection
+func newReflectionClient(ctx context.Context, conn grpc.ClientConnInterface) (*reflectionClient, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	}
+	return &reflectionClient{stream: stream}, nil
+}
+
+// close ends the reflection stream
+func (c *reflectionClient) close() {
+	_ = c.stream.CloseSend()
+}
+
+// send makes one reflection request and returns its response, failing on server errors
+func (c *reflectionClient) send(request *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if err := c.stream.Send(request); err != nil {
+		return nil, fmt.Errorf("reflection request failed: %w", err)
+	}
+	response, err := c.stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("reflection response failed: %w", err)
+	}
+	if errResponse := response.GetErrorResponse(); errResponse != nil {
+		return nil, fmt.Errorf("reflection error %d: %s", errResponse.GetErrorCode(), errResponse.GetErrorMessage())
+	}
+	return response, nil
+}
+
+// listServices returns the fully qualified names of the services the server exposes
+func (c *reflectionClient) listServices() ([]string, error) {
+	response, err := c.send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	services := make([]string, 0, len(response.GetListServicesResponse().GetService()))
+	for _, service := range response.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	return services, nil
+}
+
+// resolveFiles fetches the file defining symbol and, transitively, every file it imports
+func (c *reflectionClient) resolveFiles(symbol string) (*protoregistry.Files, error) {
+	response, err := c.send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	pending, err := addFiles(files, response)
+	if err != nil {
+		return nil, err
+	}
+	// Servers may omit dependencies they have already sent, so request missing ones by name
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if _, ok := files[name]; ok {
+			continue
+		}
+		response, err := c.send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+		})
+		if err != nil {
+			return nil, err
+		}
+		more, err := addFiles(files, response)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, more...)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+	registry, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build descriptors for %s: %w", symbol, err)
+	}
+	return registry, nil
+}
+
+// addFiles records the files in a reflection response and returns imports not yet known
+func addFiles(files map[string]*descriptorpb.FileDescriptorProto, response *reflectionpb.ServerReflectionResponse) ([]string, error) {
+	var missing []string
+	for _, encoded := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(encoded, file); err != nil {
+			return nil, fmt.Errorf("invalid file descriptor from server: %w", err)
+		}
+		files[file.GetName()] = file
+	}
+	for _, file := range files {
+		for _, dependency := range file.GetDependency() {
+			if _, ok := files[dependency]; !ok {
+				missing = append(missing, dependency)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// findService looks up a service by its fully qualified name
+func findService(files *protoregistry.Files, service string) (protoreflect.ServiceDescriptor, error) {
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", service, err)
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	return serviceDescriptor, nil
+}
+
+// findMethod looks up a method by its fully qualified service name and method name
+func findMethod(files *protoregistry.Files, service, method string) (protoreflect.MethodDescriptor, error) {
+	serviceDescriptor, err := findService(files, service)
+	if err != nil {
+		return nil, err
+	}
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(method))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("service %s has no method %s", service, method)
+	}
+	return methodDescriptor, nil
+}
@@ -36,6 +36,8 @@ type Executor struct {
 	ServiceName string
 	Tracer      trace.Tracer
 	Timeout     time.Duration
+	// CommitmentLevel is the default commitment from the selected profile, if any
+	CommitmentLevel string
 	// Future: Add validation, authentication, etc.
 }
 
@@ -66,6 +68,9 @@ func NewBaseGRPCClient[T any](
 	for _, opt := range opts {
 		opt(config)
 	}
+	if config.profileErr != nil {
+		return nil, config.profileErr
+	}
 
 	// Create gRPC connection
 	conn, err := createConnection(config)
@@ -81,9 +86,10 @@ func NewBaseGRPCClient[T any](
 
 	// Create executor with configured settings
 	executor := &Executor{
-		ServiceName: serviceName,
-		Tracer:      tracer,
-		Timeout:     config.Timeout,
+		ServiceName:     serviceName,
+		Tracer:          tracer,
+		Timeout:         config.Timeout,
+		CommitmentLevel: config.CommitmentLevel,
 	}
 
 	return &BaseGRPCClient[T]{
@@ -129,6 +135,13 @@ func (c *BaseGRPCClient[T]) Health() HealthStatus {
 	}
 }
 
+// ApplyDefaults fills in the settings a request leaves unset from the executor's
+// defaults: currently the profile's commitment level, for CommitmentLevel fields left
+// UNSPECIFIED. The request is modified in place.
+func (e *Executor) ApplyDefaults(request any) {
+	applyDefaultCommitment(request, e.CommitmentLevel)
+}
+
 // Execute provides consistent execution of RPC calls with tracing, timeout handling,
 // validation, and error handling. This ensures all RPC calls have the same behavior.
 func Execute[Req, Resp any](
@@ -154,6 +167,8 @@ func Execute[Req, Resp any](
 	// Future: Add request validation here
 	// Future: Add authentication here
 
+	executor.ApplyDefaults(request)
+
 	// Execute the RPC call
 	response, err := rpcCall(ctx)
 	if err != nil {
@@ -181,6 +196,11 @@ func createConnection(config *ServiceConfig) (*grpc.ClientConn, error) {
 		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(config.UnaryInterceptors...))
 	}
 
+	// Dial through a custom dialer, e.g. to an in-process server
+	if config.Dialer != nil {
+		dialOpts = append(dialOpts, grpc.WithContextDialer(config.Dialer))
+	}
+
 	// Add default call options
 	dialOpts = append(dialOpts, grpc.WithDefaultCallOptions())
 
@@ -2,8 +2,11 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"os"
 	"strings"
 
 	"runtime/debug"
@@ -13,13 +16,48 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 var _ GRPCServer = &GRPCServerImpl{}
 
+// DefaultInProcessBufferSize is the buffer size of the in-process listener
+// when ListenInProcess is given a size of 0
+const DefaultInProcessBufferSize = 1024 * 1024
+
 type GRPCServerImpl struct {
 	*grpc.Server
-	port int
+	port           int
+	unixSocketPath string
+	inProcess      *bufconn.Listener
+	profilingAddr  string
+	startProfiling ProfilingServer
+	profiling      *http.Server
+	streams        *StreamCounter
+}
+
+// GRPCServerOption is a functional option for configuring the listeners of a GRPCServerImpl
+type GRPCServerOption func(*GRPCServerImpl)
+
+// ListenOnUnixSocket additionally serves on a Unix domain socket at path, for sidecar
+// deployments that want to avoid TCP. A stale socket file left at path is replaced.
+func ListenOnUnixSocket(path string) GRPCServerOption {
+	return func(g *GRPCServerImpl) {
+		g.unixSocketPath = path
+	}
+}
+
+// ListenInProcess additionally serves on an in-memory bufconn listener with the
+// given buffer size (0 for DefaultInProcessBufferSize), so that tests and embedding
+// programs can run the real server without allocating a port. Connect through
+// DialInProcess or the WithInProcessServer client option.
+func ListenInProcess(bufferSize int) GRPCServerOption {
+	return func(g *GRPCServerImpl) {
+		if bufferSize <= 0 {
+			bufferSize = DefaultInProcessBufferSize
+		}
+		g.inProcess = bufconn.Listen(bufferSize)
+	}
 }
 
 type ServiceInterceptorCombo struct {
@@ -27,11 +65,23 @@ type ServiceInterceptorCombo struct {
 	Services     []GRPCService
 }
 
+// NewGRPCServerImpl constructs a server for the given services. It listens on TCP at port
+// unless port is 0 and another listener is configured through opts.
 func NewGRPCServerImpl(
 	port int,
 	enableGRPCReflection bool,
 	serviceInterceptorCombos []ServiceInterceptorCombo,
+	opts ...GRPCServerOption,
 ) (*GRPCServerImpl, error) {
+	// apply listener options first, as profiling adds an interceptor
+	grpcServerImpl := &GRPCServerImpl{
+		port:    port,
+		streams: NewStreamCounter(),
+	}
+	for _, opt := range opts {
+		opt(grpcServerImpl)
+	}
+
 	// Prepare list of default unary call interceptors (i.e. middleware).
 	// These will be applied to every incoming gRPC call.
 	interceptors := []grpc.UnaryServerInterceptor{
@@ -128,9 +178,10 @@ func NewGRPCServerImpl(
 		}
 	}
 
-	// construct server with the given interceptors
+	// construct server with the given interceptors, counting the streams in flight per method
 	server := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(grpcServerImpl.streams.Interceptor()),
 	)
 
 	// enable grpc reflection if requested
@@ -143,25 +194,59 @@ func NewGRPCServerImpl(
 		serviceProvider.RegisterWithGRPCServer(server)
 	}
 
-	// construct and return
-	return &GRPCServerImpl{
-		Server: server,
-		port:   port,
-	}, nil
+	grpcServerImpl.Server = server
+	return grpcServerImpl, nil
 }
 
 // StartServer implements GRPCServer.
+// It serves on every configured listener and blocks until serving stops on any of them.
 func (g *GRPCServerImpl) StartServer() error {
-	log.Debug().Msgf("starting gRPC server on port %d", g.port)
+	// prepare all configured listeners
+	listeners := make([]net.Listener, 0)
+	if g.port != 0 || (g.unixSocketPath == "" && g.inProcess == nil) {
+		log.Debug().Msgf("starting gRPC server on port %d", g.port)
+		lis, err := net.Listen("tcp", fmt.Sprintf("[::]:%d", g.port))
+		if err != nil {
+			return fmt.Errorf("error listening on port %d: %v", g.port, err)
+		}
+		listeners = append(listeners, lis)
+	}
+	if g.unixSocketPath != "" {
+		log.Debug().Msgf("starting gRPC server on unix socket %s", g.unixSocketPath)
+		if err := os.Remove(g.unixSocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			closeListeners(listeners)
+			return fmt.Errorf("error removing stale unix socket %s: %v", g.unixSocketPath, err)
+		}
+		lis, err := net.Listen("unix", g.unixSocketPath)
+		if err != nil {
+			closeListeners(listeners)
+			return fmt.Errorf("error listening on unix socket %s: %v", g.unixSocketPath, err)
+		}
+		listeners = append(listeners, lis)
+	}
+	if g.inProcess != nil {
+		log.Debug().Msg("starting gRPC server on in-process listener")
+		listeners = append(listeners, g.inProcess)
+	}
 
-	// prepare connection on the given server port
-	lis, err := net.Listen("tcp", fmt.Sprintf("[::]:%d", g.port))
-	if err != nil {
-		return fmt.Errorf("error listening on port %d: %v", g.port, err)
+	// start the profiling endpoints alongside, if requested
+	if g.profilingAddr != "" && g.startProfiling != nil {
+		profiling, err := g.startProfiling(g.profilingAddr, g.streams)
+		if err != nil {
+			closeListeners(listeners)
+			return fmt.Errorf("error starting profiling endpoints on %s: %v", g.profilingAddr, err)
+		}
+		g.profiling = profiling
 	}
 
-	// start the grpc server listening on the port
-	return g.Server.Serve(lis)
+	// start the grpc server on every listener, returning when the first one stops
+	serveErrs := make(chan error, len(listeners))
+	for _, lis := range listeners {
+		go func(lis net.Listener) {
+			serveErrs <- g.Server.Serve(lis)
+		}(lis)
+	}
+	return <-serveErrs
 }
 
 // StopServer implements GRPCServer.
@@ -170,5 +255,33 @@ func (g *GRPCServerImpl) StopServer() error {
 
 	g.Server.GracefulStop()
 
+	if g.profiling != nil {
+		if err := g.profiling.Close(); err != nil {
+			return fmt.Errorf("error stopping profiling endpoints: %v", err)
+		}
+	}
+
+	// the socket file outlives its listener, so clean it up
+	if g.unixSocketPath != "" {
+		if err := os.Remove(g.unixSocketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error removing unix socket %s: %v", g.unixSocketPath, err)
+		}
+	}
+
 	return nil
 }
+
+// DialInProcess opens a connection to the in-process listener. It has the signature of
+// a gRPC context dialer, so it can be passed to grpc.WithContextDialer.
+func (g *GRPCServerImpl) DialInProcess(ctx context.Context, _ string) (net.Conn, error) {
+	if g.inProcess == nil {
+		return nil, errors.New("gRPC server has no in-process listener")
+	}
+	return g.inProcess.DialContext(ctx)
+}
+
+func closeListeners(listeners []net.Listener) {
+	for _, lis := range listeners {
+		_ = lis.Close()
+	}
+}
@@ -0,0 +1,226 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StepFunc is the work of one pipeline step, reading and advancing the pipeline's state
+type StepFunc[S any] func(ctx context.Context, state *S) error
+
+// PipelineMiddleware wraps a step, e.g. to log, trace, retry or veto it. It receives the
+// name of the step it wraps so that it can act on particular steps only.
+type PipelineMiddleware[S any] func(step string, next StepFunc[S]) StepFunc[S]
+
+// PipelineHooks observe a pipeline's steps, e.g. to record metrics. Either may be nil.
+type PipelineHooks struct {
+	// OnStepStart is called before each step runs
+	OnStepStart func(pipeline, step string)
+	// OnStepEnd is called after each step with how long it took and the error it returned
+	OnStepEnd func(pipeline, step string, duration time.Duration, err error)
+}
+
+// PipelineOption is a functional option for configuring a Pipeline
+type PipelineOption[S any] func(*Pipeline[S])
+
+// WithPipelineMiddleware adds middleware around every step of the pipeline
+func WithPipelineMiddleware[S any](middleware ...PipelineMiddleware[S]) PipelineOption[S] {
+	return func(p *Pipeline[S]) {
+		p.middleware = append(p.middleware, middleware...)
+	}
+}
+
+// WithPipelineHooks sets the hooks observing the pipeline's steps
+func WithPipelineHooks[S any](hooks PipelineHooks) PipelineOption[S] {
+	return func(p *Pipeline[S]) {
+		p.hooks = hooks
+	}
+}
+
+// TracePipelineSteps returns middleware that runs each step in its own span, named
+// after the pipeline and step, using the global tracer provider
+func TracePipelineSteps[S any](pipeline string) PipelineMiddleware[S] {
+	tracer := otel.Tracer(pipeline)
+	return func(step string, next StepFunc[S]) StepFunc[S] {
+		return func(ctx context.Context, state *S) error {
+			ctx, span := tracer.Start(ctx, pipeline+"."+step,
+				trace.WithAttributes(
+					attribute.String("pipeline.name", pipeline),
+					attribute.String("pipeline.step", step),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, state)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// PipelineStepError reports the step a pipeline stopped at
+type PipelineStepError struct {
+	Pipeline string
+	Step     string
+	Err      error
+}
+
+func (e *PipelineStepError) Error() string {
+	return fmt.Sprintf("pipeline %s: step %s: %v", e.Pipeline, e.Step, e.Err)
+}
+
+func (e *PipelineStepError) Unwrap() error {
+	return e.Err
+}
+
+// pipelineStep is a named step of a Pipeline
+type pipelineStep[S any] struct {
+	name string
+	run  StepFunc[S]
+}
+
+// Pipeline runs named steps in order over a shared state, with middleware around each
+// step, so that teams can standardize a multi-call flow and still customize parts of it.
+//
+// Steps are registered with Step and can be inserted, replaced or removed by name, which
+// lets a default pipeline be adapted without rebuilding it. Middleware is applied in the
+// order added, the first added being outermost. A Pipeline is not safe to modify while
+// it runs; Run itself may be called concurrently with separate states.
+type Pipeline[S any] struct {
+	name       string
+	steps      []pipelineStep[S]
+	middleware []PipelineMiddleware[S]
+	hooks      PipelineHooks
+}
+
+// NewPipeline creates an empty named Pipeline
+func NewPipeline[S any](name string, opts ...PipelineOption[S]) *Pipeline[S] {
+	p := &Pipeline[S]{name: name}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Name returns the pipeline's name
+func (p *Pipeline[S]) Name() string {
+	return p.name
+}
+
+// Steps returns the names of the pipeline's steps in the order they run
+func (p *Pipeline[S]) Steps() []string {
+	names := make([]string, len(p.steps))
+	for i, step := range p.steps {
+		names[i] = step.name
+	}
+	return names
+}
+
+// Step appends a step. It panics if a step with the same name is already registered,
+// since pipelines are assembled at startup.
+func (p *Pipeline[S]) Step(name string, run StepFunc[S]) *Pipeline[S] {
+	if p.index(name) >= 0 {
+		panic(fmt.Sprintf("pipeline %s: duplicate step %s", p.name, name))
+	}
+	p.steps = append(p.steps, pipelineStep[S]{name: name, run: run})
+	return p
+}
+
+// Use adds middleware around every step
+func (p *Pipeline[S]) Use(middleware ...PipelineMiddleware[S]) *Pipeline[S] {
+	p.middleware = append(p.middleware, middleware...)
+	return p
+}
+
+// InsertBefore adds a step immediately before the step named before
+func (p *Pipeline[S]) InsertBefore(before, name string, run StepFunc[S]) error {
+	return p.insert(before, 0, name, run)
+}
+
+// InsertAfter adds a step immediately after the step named after
+func (p *Pipeline[S]) InsertAfter(after, name string, run StepFunc[S]) error {
+	return p.insert(after, 1, name, run)
+}
+
+// Replace swaps the work of the step named name, keeping its position
+func (p *Pipeline[S]) Replace(name string, run StepFunc[S]) error {
+	i := p.index(name)
+	if i < 0 {
+		return fmt.Errorf("pipeline %s has no step %s", p.name, name)
+	}
+	p.steps[i].run = run
+	return nil
+}
+
+// Remove drops the step named name
+func (p *Pipeline[S]) Remove(name string) error {
+	i := p.index(name)
+	if i < 0 {
+		return fmt.Errorf("pipeline %s has no step %s", p.name, name)
+	}
+	p.steps = append(p.steps[:i], p.steps[i+1:]...)
+	return nil
+}
+
+// Run runs each step in order over state, stopping at the first error, which is
+// returned as a *PipelineStepError. The context is checked before every step.
+func (p *Pipeline[S]) Run(ctx context.Context, state *S) error {
+	for _, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			return &PipelineStepError{Pipeline: p.name, Step: step.name, Err: err}
+		}
+
+		run := step.run
+		for i := len(p.middleware) - 1; i >= 0; i-- {
+			run = p.middleware[i](step.name, run)
+		}
+
+		if p.hooks.OnStepStart != nil {
+			p.hooks.OnStepStart(p.name, step.name)
+		}
+		started := time.Now()
+		err := run(ctx, state)
+		if p.hooks.OnStepEnd != nil {
+			p.hooks.OnStepEnd(p.name, step.name, time.Since(started), err)
+		}
+		if err != nil {
+			return &PipelineStepError{Pipeline: p.name, Step: step.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// index returns the position of the step named name, or -1
+func (p *Pipeline[S]) index(name string) int {
+	for i, step := range p.steps {
+		if step.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insert adds a step at offset from the step named anchor
+func (p *Pipeline[S]) insert(anchor string, offset int, name string, run StepFunc[S]) error {
+	i := p.index(anchor)
+	if i < 0 {
+		return fmt.Errorf("pipeline %s has no step %s", p.name, anchor)
+	}
+	if p.index(name) >= 0 {
+		return fmt.Errorf("pipeline %s already has a step %s", p.name, name)
+	}
+	at := i + offset
+	p.steps = append(p.steps, pipelineStep[S]{})
+	copy(p.steps[at+1:], p.steps[at:])
+	p.steps[at] = pipelineStep[S]{name: name, run: run}
+	return nil
+}
@@ -0,0 +1,154 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Standard profile names
+const (
+	ProfileDevnet  = "devnet"
+	ProfileTestnet = "testnet"
+	ProfileMainnet = "mainnet"
+	ProfileLocal   = "local"
+)
+
+// commitmentLevelEnum is the enum a profile's default commitment level applies to
+const commitmentLevelEnum protoreflect.FullName = "protochain.solana.type.v1.CommitmentLevel"
+
+// commitmentLevels maps the commitment levels a profile may name to CommitmentLevel values
+var commitmentLevels = map[string]protoreflect.Name{
+	"processed": "COMMITMENT_LEVEL_PROCESSED",
+	"confirmed": "COMMITMENT_LEVEL_CONFIRMED",
+	"finalized": "COMMITMENT_LEVEL_FINALIZED",
+}
+
+// Profile holds the connection settings for one environment. Cluster is informational;
+// CommitmentLevel ("processed", "confirmed", "finalized" or empty for none) is the
+// default applied to requests that leave their commitment level UNSPECIFIED.
+type Profile struct {
+	URL             string `json:"url"`
+	TLS             bool   `json:"tls"`
+	Cluster         string `json:"cluster"`
+	CommitmentLevel string `json:"commitmentLevel"`
+}
+
+// defaultLocalProfile is used for ProfileLocal when the profiles file does not define it,
+// matching WithDevelopmentDefaults
+var defaultLocalProfile = Profile{
+	URL:             "localhost:9090",
+	TLS:             false,
+	Cluster:         ProfileLocal,
+	CommitmentLevel: "confirmed",
+}
+
+// discoverProfiles returns the path of the profiles file:
+//
+// 1. PROTOCHAIN_API_PROFILES environment variable
+// 2. profiles.json in the credential discovery directory (see discoverCredentials)
+func discoverProfiles() string {
+	if path := os.Getenv("PROTOCHAIN_API_PROFILES"); path != "" {
+		return path
+	}
+	if dir := configDir(); dir != "" {
+		return filepath.Join(dir, "profiles.json")
+	}
+	return ""
+}
+
+// LoadProfile reads the named profile from the profiles file.
+//
+// The file is a JSON object keyed by profile name, for example:
+//
+//	{
+//	  "devnet": {"url": "devnet.api.example.com:443", "tls": true, "cluster": "devnet", "commitmentLevel": "confirmed"},
+//	  "local":  {"url": "localhost:9090", "tls": false, "cluster": "local", "commitmentLevel": "processed"}
+//	}
+//
+// ProfileLocal falls back to localhost:9090 without TLS when the file does not define it.
+func LoadProfile(name string) (Profile, error) {
+	path := discoverProfiles()
+	profiles := make(map[string]Profile)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &profiles); err != nil {
+				return Profile{}, fmt.Errorf("failed to parse profiles file %s: %w", path, err)
+			}
+		case errors.Is(err, os.ErrNotExist):
+		default:
+			return Profile{}, fmt.Errorf("failed to read profiles file %s: %w", path, err)
+		}
+	}
+
+	if profile, ok := profiles[name]; ok {
+		if profile.URL == "" {
+			return Profile{}, fmt.Errorf("profile %q in %s has no url", name, path)
+		}
+		if _, ok := commitmentLevels[profile.CommitmentLevel]; !ok && profile.CommitmentLevel != "" {
+			return Profile{}, fmt.Errorf(
+				"profile %q in %s has unknown commitmentLevel %q (want processed, confirmed or finalized)",
+				name, path, profile.CommitmentLevel,
+			)
+		}
+		return profile, nil
+	}
+	if name == ProfileLocal {
+		return defaultLocalProfile, nil
+	}
+	return Profile{}, fmt.Errorf("profile %q not found in profiles file %s", name, path)
+}
+
+// WithProfile applies the named environment profile (URL, TLS and default commitment
+// level) from the profiles file. Options given after it override individual settings. A
+// missing or invalid profile is reported when the client is constructed.
+func WithProfile(name string) ServiceOption {
+	return func(c *ServiceConfig) {
+		profile, err := LoadProfile(name)
+		if err != nil {
+			c.profileErr = err
+			return
+		}
+		c.URL = profile.URL
+		c.TLS = profile.TLS
+		c.CommitmentLevel = profile.CommitmentLevel
+	}
+}
+
+// applyDefaultCommitment sets every top-level CommitmentLevel field request leaves
+// UNSPECIFIED to level, if level names a commitment level
+func applyDefaultCommitment(request any, level string) {
+	name, ok := commitmentLevels[level]
+	if !ok {
+		return
+	}
+	message, ok := request.(proto.Message)
+	if !ok {
+		return
+	}
+	m := message.ProtoReflect()
+	if !m.IsValid() {
+		return
+	}
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.Kind() != protoreflect.EnumKind || field.Enum().FullName() != commitmentLevelEnum {
+			continue
+		}
+		if field.IsList() || m.Has(field) {
+			continue
+		}
+		if value := field.Enum().Values().ByName(name); value != nil {
+			m.Set(field, protoreflect.ValueOfEnum(value.Number()))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"net/http"
+	"runtime/metrics"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ProfilingServer starts profiling endpoints on addr, reporting the streams counted by
+// streams, and returns the server to close when the gRPC server stops (nil if none was
+// started). The profiling package provides the pprof one: it lives apart from this
+// package because importing net/http/pprof registers its handlers on
+// http.DefaultServeMux, which every program importing common would otherwise inherit.
+type ProfilingServer func(addr string, streams *StreamCounter) (*http.Server, error)
+
+// ServeProfiling serves the endpoints start provides on addr while the server runs. Use
+// profiling.Serve, which supplies the pprof, trace and runtime metrics endpoints.
+func ServeProfiling(addr string, start ProfilingServer) GRPCServerOption {
+	return func(g *GRPCServerImpl) {
+		g.profilingAddr = addr
+		g.startProfiling = start
+	}
+}
+
+// StreamCounter counts the server streams in flight per full method name. Each stream
+// holds a handler goroutine for its lifetime, so the counts show where the goroutines
+// of long-lived subscriptions such as transaction monitoring are.
+type StreamCounter struct {
+	mu     sync.Mutex
+	active map[string]int64
+}
+
+// NewStreamCounter constructs an empty StreamCounter
+func NewStreamCounter() *StreamCounter {
+	return &StreamCounter{active: make(map[string]int64)}
+}
+
+// Interceptor returns a stream server interceptor that counts each stream while its
+// handler runs
+func (c *StreamCounter) Interceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		c.add(info.FullMethod, 1)
+		defer c.add(info.FullMethod, -1)
+		return handler(srv, ss)
+	}
+}
+
+// Active returns the number of streams in flight per method, omitting idle methods
+func (c *StreamCounter) Active() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := make(map[string]int64, len(c.active))
+	for method, count := range c.active {
+		active[method] = count
+	}
+	return active
+}
+
+func (c *StreamCounter) add(method string, delta int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active[method] += delta
+	if c.active[method] <= 0 {
+		delete(c.active, method)
+	}
+}
+
+// RuntimeStats is a snapshot of the Go runtime and the streams in flight
+type RuntimeStats struct {
+	Goroutines       uint64           `json:"goroutines"`
+	GCCycles         uint64           `json:"gcCycles"`
+	HeapObjectsBytes uint64           `json:"heapObjectsBytes"`
+	HeapAllocsBytes  uint64           `json:"heapAllocsBytes"`
+	HeapGoalBytes    uint64           `json:"heapGoalBytes"`
+	StreamsByMethod  map[string]int64 `json:"streamsByMethod"`
+}
+
+// runtimeStatsSamples maps each runtime/metrics sample to the RuntimeStats field it fills
+var runtimeStatsSamples = []struct {
+	name  string
+	field func(*RuntimeStats) *uint64
+}{
+	{"/sched/goroutines:goroutines", func(s *RuntimeStats) *uint64 { return &s.Goroutines }},
+	{"/gc/cycles/total:gc-cycles", func(s *RuntimeStats) *uint64 { return &s.GCCycles }},
+	{"/memory/classes/heap/objects:bytes", func(s *RuntimeStats) *uint64 { return &s.HeapObjectsBytes }},
+	{"/gc/heap/allocs:bytes", func(s *RuntimeStats) *uint64 { return &s.HeapAllocsBytes }},
+	{"/gc/heap/goal:bytes", func(s *RuntimeStats) *uint64 { return &s.HeapGoalBytes }},
+}
+
+// ReadRuntimeStats samples the Go runtime and the streams counted by streams
+func ReadRuntimeStats(streams *StreamCounter) RuntimeStats {
+	samples := make([]metrics.Sample, len(runtimeStatsSamples))
+	for i, sample := range runtimeStatsSamples {
+		samples[i].Name = sample.name
+	}
+	metrics.Read(samples)
+
+	stats := RuntimeStats{StreamsByMethod: streams.Active()}
+	for i, sample := range samples {
+		if sample.Value.Kind() == metrics.KindUint64 {
+			*runtimeStatsSamples[i].field(&stats) = sample.Value.Uint64()
+		}
+	}
+	return stats
+}
@@ -0,0 +1,23 @@
+// Package profiling serves pprof, execution trace and runtime metrics endpoints alongside
+// a common.GRPCServerImpl.
+//
+// Importing this package imports net/http/pprof, which registers the pprof handlers on
+// http.DefaultServeMux: any HTTP server the program runs on the default mux exposes them
+// as well. The endpoints live here rather than in package common so that only programs
+// that opt into profiling take on that side effect. Builds with the protochain_hardened
+// tag do not link pprof at all.
+package profiling
+
+import "github.com/BRBussy/protochain/lib/go/common"
+
+// Serve serves pprof, execution trace and runtime metrics endpoints over HTTP on addr
+// (e.g. "127.0.0.1:6060") while the server runs:
+//
+//   - /debug/pprof/ and its profiles (heap, goroutine, profile, trace, ...)
+//   - /debug/metrics: common.RuntimeStats as JSON
+//
+// The endpoints expose process internals, so bind addr to a private interface. Builds
+// with the protochain_hardened tag compile the endpoints out and ignore this option.
+func Serve(addr string) common.GRPCServerOption {
+	return common.ServeProfiling(addr, startServer)
+}
@@ -0,0 +1,44 @@
+//go:build !protochain_hardened
+
+package profiling
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/BRBussy/protochain/lib/go/common"
+	"github.com/rs/zerolog/log"
+)
+
+// startServer serves the profiling endpoints described on Serve
+func startServer(addr string, streams *common.StreamCounter) (*http.Server, error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(common.ReadRuntimeStats(streams)); err != nil {
+			log.Ctx(r.Context()).Error().Err(err).Msg("error encoding runtime stats")
+		}
+	})
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		log.Debug().Msgf("serving profiling endpoints on %s", lis.Addr())
+		if err := server.Serve(lis); err != nil && err != http.ErrServerClosed {
+			log.Error().Err(err).Msg("profiling endpoints stopped")
+		}
+	}()
+	return server, nil
+}
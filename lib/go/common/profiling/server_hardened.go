@@ -0,0 +1,17 @@
+//go:build protochain_hardened
+
+package profiling
+
+import (
+	"net/http"
+
+	"github.com/BRBussy/protochain/lib/go/common"
+	"github.com/rs/zerolog/log"
+)
+
+// startServer is compiled out of hardened builds: pprof is not linked in and Serve only
+// logs that it was ignored
+func startServer(addr string, _ *common.StreamCounter) (*http.Server, error) {
+	log.Warn().Msgf("ignoring profiling endpoints on %s: not available in hardened builds", addr)
+	return nil, nil
+}
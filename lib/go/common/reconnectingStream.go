@@ -0,0 +1,247 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StreamReceiver is the receiving side of a server-streaming call. Every generated
+// grpc.ServerStreamingClient implements it.
+type StreamReceiver[Resp any] interface {
+	Recv() (Resp, error)
+}
+
+// StreamOpener opens a server-streaming call. resumeToken is "" for the first call and,
+// on every reconnect, the token of the last message delivered, which the opener carries
+// into the request (e.g. as a start slot) so the new stream continues where the old one
+// stopped.
+type StreamOpener[Resp any] func(ctx context.Context, resumeToken string) (StreamReceiver[Resp], error)
+
+// ReconnectConfig holds the reconnect policy of a ReconnectingStream
+type ReconnectConfig struct {
+	// InitialBackoff is the wait before the first reconnect, doubled on each consecutive failure
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between reconnects
+	MaxBackoff time.Duration
+	// MaxAttempts is how many consecutive reconnects may fail before giving up (0 is no limit)
+	MaxAttempts int
+	// Retryable reports whether a stream error is worth reconnecting after
+	Retryable func(error) bool
+	// OnReconnect is called before each reconnect with the attempt number and the error
+	// that ended the previous stream
+	OnReconnect func(attempt int, err error)
+}
+
+// ReconnectOption is a functional option for configuring a ReconnectingStream
+type ReconnectOption func(*ReconnectConfig)
+
+// WithReconnectBackoff sets the initial and maximum wait between reconnects
+func WithReconnectBackoff(initial, maxBackoff time.Duration) ReconnectOption {
+	return func(c *ReconnectConfig) {
+		c.InitialBackoff = initial
+		c.MaxBackoff = maxBackoff
+	}
+}
+
+// WithMaxReconnectAttempts limits how many consecutive reconnects may fail
+func WithMaxReconnectAttempts(attempts int) ReconnectOption {
+	return func(c *ReconnectConfig) {
+		c.MaxAttempts = attempts
+	}
+}
+
+// WithRetryable replaces the check deciding which stream errors are reconnected after
+func WithRetryable(retryable func(error) bool) ReconnectOption {
+	return func(c *ReconnectConfig) {
+		c.Retryable = retryable
+	}
+}
+
+// WithOnReconnect registers a callback invoked before each reconnect
+func WithOnReconnect(onReconnect func(attempt int, err error)) ReconnectOption {
+	return func(c *ReconnectConfig) {
+		c.OnReconnect = onReconnect
+	}
+}
+
+// IsRetryableStreamError reports whether err is a transient failure of a stream: the
+// server or a proxy in front of it went away, shed load or aborted the call
+func IsRetryableStreamError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReconnectMetrics counts what a ReconnectingStream has done so far
+type ReconnectMetrics struct {
+	// Messages is the number of messages delivered to the callback
+	Messages uint64
+	// Reconnects is the number of times the stream was re-opened after an error
+	Reconnects uint64
+	// ConsecutiveFailures is the number of reconnects since a message was last received
+	ConsecutiveFailures uint64
+}
+
+// ReconnectingStream wraps a server-streaming call with automatic reconnection, so
+// applications do not each write their own Recv loop.
+//
+// Messages are delivered to a callback in order. After a retryable error the stream is
+// re-opened with backoff, passing the resume token of the last delivered message to the
+// opener; whether a message can repeat across a reconnect depends on how the server
+// resumes from that token.
+//
+// The stream ends when the server closes it, the context is done, the callback returns
+// an error, or a non-retryable error or too many consecutive failures occur.
+type ReconnectingStream[Resp any] struct {
+	open        StreamOpener[Resp]
+	resumeToken func(Resp) string
+	config      ReconnectConfig
+
+	messages            atomic.Uint64
+	reconnects          atomic.Uint64
+	consecutiveFailures atomic.Uint64
+}
+
+// NewReconnectingStream creates a ReconnectingStream that opens its stream with open.
+// resumeToken extracts the token to resume after a message; it may be nil, or return ""
+// for messages that do not move the resume point.
+func NewReconnectingStream[Resp any](
+	open StreamOpener[Resp],
+	resumeToken func(Resp) string,
+	opts ...ReconnectOption,
+) *ReconnectingStream[Resp] {
+	// Apply default configuration
+	config := ReconnectConfig{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Retryable:      IsRetryableStreamError,
+	}
+
+	// Apply user options
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return &ReconnectingStream[Resp]{
+		open:        open,
+		resumeToken: resumeToken,
+		config:      config,
+	}
+}
+
+// Metrics returns the stream's counters; it is safe to call while Run is in progress
+func (s *ReconnectingStream[Resp]) Metrics() ReconnectMetrics {
+	return ReconnectMetrics{
+		Messages:            s.messages.Load(),
+		Reconnects:          s.reconnects.Load(),
+		ConsecutiveFailures: s.consecutiveFailures.Load(),
+	}
+}
+
+// Run opens the stream and delivers each message to onMessage until the stream ends. It
+// returns nil when the server closes the stream, and otherwise the error that ended it.
+func (s *ReconnectingStream[Resp]) Run(ctx context.Context, onMessage func(Resp) error) error {
+	token := ""
+	for {
+		err := s.receive(ctx, onMessage, &token)
+		if err == nil {
+			return nil
+		}
+		var callbackErr *streamCallbackError
+		if errors.As(err, &callbackErr) {
+			return callbackErr.err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if s.config.Retryable == nil || !s.config.Retryable(err) {
+			return err
+		}
+
+		attempt := s.consecutiveFailures.Add(1)
+		if s.config.MaxAttempts > 0 && attempt > uint64(s.config.MaxAttempts) {
+			return fmt.Errorf("stream failed after %d reconnect attempts: %w", s.config.MaxAttempts, err)
+		}
+		if s.config.OnReconnect != nil {
+			s.config.OnReconnect(int(attempt), err)
+		}
+
+		// Wait before reconnecting, unless the context is done first
+		timer := time.NewTimer(s.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		s.reconnects.Add(1)
+	}
+}
+
+// streamCallbackError marks an error returned by the message callback, which ends the
+// stream without a reconnect
+type streamCallbackError struct {
+	err error
+}
+
+func (e *streamCallbackError) Error() string {
+	return e.err.Error()
+}
+
+// receive runs one stream, opened from the resume token in token, to its end. It returns
+// nil if the server closed the stream and records the token of each delivered message.
+func (s *ReconnectingStream[Resp]) receive(
+	ctx context.Context,
+	onMessage func(Resp) error,
+	token *string,
+) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := s.open(streamCtx, *token)
+	if err != nil {
+		return err
+	}
+	for {
+		message, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		s.consecutiveFailures.Store(0)
+		s.messages.Add(1)
+		if err := onMessage(message); err != nil {
+			return &streamCallbackError{err: err}
+		}
+		if s.resumeToken != nil {
+			if next := s.resumeToken(message); next != "" {
+				*token = next
+			}
+		}
+	}
+}
+
+// backoff returns the wait before reconnect attempt, doubling from the initial backoff
+// up to the maximum
+func (s *ReconnectingStream[Resp]) backoff(attempt uint64) time.Duration {
+	wait := s.config.InitialBackoff
+	for i := uint64(1); i < attempt && wait < s.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if s.config.MaxBackoff > 0 && wait > s.config.MaxBackoff {
+		wait = s.config.MaxBackoff
+	}
+	return wait
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"context"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -16,7 +18,13 @@ type ServiceConfig struct {
 	Timeout           time.Duration
 	APIKey            string
 	CredentialsFile   string
+	CommitmentLevel   string
 	UnaryInterceptors []grpc.UnaryClientInterceptor
+	// Dialer replaces the network dial, e.g. to reach an in-process server
+	Dialer func(context.Context, string) (net.Conn, error)
+
+	// profileErr records a WithProfile failure, reported when the client is constructed
+	profileErr error
 }
 
 // ServiceOption is a functional option for configuring a gRPC service client
@@ -64,6 +72,31 @@ func WithUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) ServiceOption
 	}
 }
 
+// WithUnixSocket connects to a server listening on the Unix domain socket at path
+func WithUnixSocket(path string) ServiceOption {
+	return func(c *ServiceConfig) {
+		c.URL = "unix://" + path
+		c.TLS = false
+	}
+}
+
+// WithContextDialer replaces the network dial with dialer, which receives the URL
+func WithContextDialer(dialer func(context.Context, string) (net.Conn, error)) ServiceOption {
+	return func(c *ServiceConfig) {
+		c.Dialer = dialer
+	}
+}
+
+// WithInProcessServer connects to the in-process listener of server (see
+// ListenInProcess), without allocating a port
+func WithInProcessServer(server *GRPCServerImpl) ServiceOption {
+	return func(c *ServiceConfig) {
+		c.URL = "passthrough:///in-process"
+		c.TLS = false
+		c.Dialer = server.DialInProcess
+	}
+}
+
 // WithInsecure is a convenience option to disable TLS (for development)
 func WithInsecure() ServiceOption {
 	return WithTLS(false)
@@ -90,7 +123,23 @@ func discoverCredentials() string {
 	}
 
 	// Determine default path based on OS
-	var defaultPath string
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	defaultPath := filepath.Join(dir, "credentials.json")
+
+	// Check if the default path exists
+	if _, err := os.Stat(defaultPath); err == nil {
+		return defaultPath
+	}
+
+	return ""
+}
+
+// configDir returns the per-user protochain configuration directory, which holds the
+// credentials and profiles files, or "" if the home directory cannot be determined
+func configDir() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return ""
@@ -98,24 +147,17 @@ func discoverCredentials() string {
 
 	switch runtime.GOOS {
 	case "darwin": // macOS
-		defaultPath = filepath.Join(homeDir, "Library", "Application Support", "protochain", "credentials.json")
+		return filepath.Join(homeDir, "Library", "Application Support", "protochain")
 	case "windows":
-		defaultPath = filepath.Join(homeDir, "AppData", "Roaming", "protochain", "credentials.json")
+		return filepath.Join(homeDir, "AppData", "Roaming", "protochain")
 	default: // Linux and others
 		// Use XDG_CONFIG_HOME if set, otherwise fallback to ~/.config
 		configHome := os.Getenv("XDG_CONFIG_HOME")
 		if configHome == "" {
 			configHome = filepath.Join(homeDir, ".config")
 		}
-		defaultPath = filepath.Join(configHome, "protochain", "credentials.json")
-	}
-
-	// Check if the default path exists
-	if _, err := os.Stat(defaultPath); err == nil {
-		return defaultPath
+		return filepath.Join(configHome, "protochain")
 	}
-
-	return ""
 }
 
 // WithDefaultCredentials attempts to discover and use default credentials
@@ -3,6 +3,9 @@ package token_v1
 // TOKEN_2022_PROGRAM_ID is the public key of the Token 2022 Program
 const TOKEN_2022_PROGRAM_ID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
 
+// TOKEN_PROGRAM_ID is the public key of the legacy SPL Token Program
+const TOKEN_PROGRAM_ID = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+
 // MINT_ACCOUNT_LEN is the size in bytes of a mint account
 const MINT_ACCOUNT_LEN = 82
 
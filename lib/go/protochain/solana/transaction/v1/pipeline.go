@@ -0,0 +1,314 @@
+package transaction_v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/BRBussy/protochain/lib/go/common"
+)
+
+// Names of the steps of the default transaction pipelines, for use with the common.Pipeline
+// methods that insert, replace or remove steps by name
+const (
+	PipelineStepBuild    = "build"
+	PipelineStepPolicy   = "policy"
+	PipelineStepCompile  = "compile"
+	PipelineStepEstimate = "estimate"
+	PipelineStepSign     = "sign"
+	PipelineStepSubmit   = "submit"
+	PipelineStepMonitor  = "monitor"
+)
+
+// Names of the default transaction pipelines
+const (
+	PreparePipelineName = "transaction.prepare"
+	SubmitPipelineName  = "transaction.submit"
+)
+
+// ErrPipelineNoTransaction is returned by the build step when it has neither a Build
+// function nor a transaction already on the state
+var ErrPipelineNoTransaction = errors.New("pipeline has no transaction to build")
+
+// PipelineState carries one transaction through a transaction pipeline. Callers set the
+// request templates they need before Run; each step fills in the Transaction field of its
+// template, so only the remaining fields (signing method, commitment, tags, ...) need
+// setting. Nil templates are replaced with empty requests.
+type PipelineState struct {
+	// Transaction is the transaction being processed, advanced by each step
+	Transaction *Transaction
+
+	// CompileRequest is the template of the compile step's request
+	CompileRequest *CompileTransactionRequest
+	// EstimateRequest is the template of the estimate step's request
+	EstimateRequest *EstimateTransactionRequest
+	// SignRequest is the template of the sign step's request, holding the signing method
+	SignRequest *SignTransactionRequest
+	// SubmitRequest is the template of the submit step's request
+	SubmitRequest *SubmitTransactionRequest
+	// MonitorRequest is the template of the monitor step's request (signature is filled in)
+	MonitorRequest *MonitorTransactionRequest
+
+	// Estimate is the estimate step's result
+	Estimate *EstimateTransactionResponse
+	// Submission is the submit step's result
+	Submission *SubmitTransactionResponse
+	// Status is the last update received by the monitor step
+	Status *MonitorTransactionResponse
+}
+
+// PipelineConfig holds the caller-supplied parts of the default transaction pipelines
+type PipelineConfig struct {
+	// Build returns the draft transaction. If nil, the build step keeps the transaction
+	// already on the state.
+	Build func(ctx context.Context, state *PipelineState) (*Transaction, error)
+	// Policy vets the draft before it is compiled, e.g. against allowed programs or
+	// recipients. If nil, every draft is accepted.
+	Policy func(ctx context.Context, transaction *Transaction) error
+	// MaxFeeLamports caps the estimated fee of the compiled transaction (0 is no cap)
+	MaxFeeLamports uint64
+	// MaxComputeUnits caps the estimated compute units of the compiled transaction (0 is no cap)
+	MaxComputeUnits uint64
+}
+
+// EstimateCapError is returned by the estimate step when the estimate exceeds a cap
+type EstimateCapError struct {
+	// Field is the capped quantity: "fee_lamports" or "compute_units"
+	Field string
+	// Estimate is the estimated value
+	Estimate uint64
+	// Cap is the configured maximum
+	Cap uint64
+}
+
+func (e *EstimateCapError) Error() string {
+	return fmt.Sprintf("estimated %s %d exceeds cap %d", e.Field, e.Estimate, e.Cap)
+}
+
+// SubmissionError is returned by the submit step when the transaction was not sent
+type SubmissionError struct {
+	Result  SubmissionResult
+	Message string
+}
+
+func (e *SubmissionError) Error() string {
+	return fmt.Sprintf("submission %s: %s", e.Result, e.Message)
+}
+
+// MonitorError is returned by the monitor step when the transaction did not land
+type MonitorError struct {
+	Signature string
+	Status    TransactionStatus
+	Message   string
+}
+
+func (e *MonitorError) Error() string {
+	return fmt.Sprintf("transaction %s %s: %s", e.Signature, e.Status, e.Message)
+}
+
+// NewPreparePipeline creates a pipeline that builds, vets, compiles, estimates and signs
+// a transaction without submitting it, e.g. to hand it to another party:
+//
+//	build → policy → compile → estimate → sign
+func NewPreparePipeline(client ServiceClient, config PipelineConfig, opts ...common.PipelineOption[PipelineState]) *common.Pipeline[PipelineState] {
+	return addPrepareSteps(common.NewPipeline(PreparePipelineName, opts...), client, config)
+}
+
+// NewSubmitPipeline creates a pipeline that takes a transaction from draft to landed:
+//
+//	build → policy → compile → estimate → sign → submit → monitor
+//
+// Example:
+//
+//	pipeline := transaction_v1.NewSubmitPipeline(client, transaction_v1.PipelineConfig{
+//		Build:          buildTransfer,
+//		MaxFeeLamports: 50_000,
+//	}, common.WithPipelineMiddleware(common.TracePipelineSteps[transaction_v1.PipelineState](transaction_v1.SubmitPipelineName)))
+//
+//	state := &transaction_v1.PipelineState{SignRequest: signWithKeys}
+//	if err := pipeline.Run(ctx, state); err != nil {
+//		return err
+//	}
+func NewSubmitPipeline(client ServiceClient, config PipelineConfig, opts ...common.PipelineOption[PipelineState]) *common.Pipeline[PipelineState] {
+	return addPrepareSteps(common.NewPipeline(SubmitPipelineName, opts...), client, config).
+		Step(PipelineStepSubmit, SubmitStep(client)).
+		Step(PipelineStepMonitor, MonitorStep(client))
+}
+
+// addPrepareSteps appends the steps shared by the default pipelines
+func addPrepareSteps(pipeline *common.Pipeline[PipelineState], client ServiceClient, config PipelineConfig) *common.Pipeline[PipelineState] {
+	return pipeline.
+		Step(PipelineStepBuild, BuildStep(config.Build)).
+		Step(PipelineStepPolicy, PolicyStep(config.Policy)).
+		Step(PipelineStepCompile, CompileStep(client)).
+		Step(PipelineStepEstimate, EstimateStep(client, config.MaxFeeLamports, config.MaxComputeUnits)).
+		Step(PipelineStepSign, SignStep(client))
+}
+
+// BuildStep sets the state's transaction to the draft returned by build, or keeps the
+// transaction already on the state if build is nil
+func BuildStep(build func(ctx context.Context, state *PipelineState) (*Transaction, error)) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		if build != nil {
+			transaction, err := build(ctx, state)
+			if err != nil {
+				return err
+			}
+			state.Transaction = transaction
+		}
+		if state.Transaction == nil {
+			return ErrPipelineNoTransaction
+		}
+		return nil
+	}
+}
+
+// PolicyStep vets the state's transaction with policy, accepting everything if policy is nil
+func PolicyStep(policy func(ctx context.Context, transaction *Transaction) error) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		if policy == nil {
+			return nil
+		}
+		return policy(ctx, state.Transaction)
+	}
+}
+
+// CompileStep compiles the state's transaction with CompileTransaction
+func CompileStep(client ServiceClient) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		request := &CompileTransactionRequest{}
+		if state.CompileRequest != nil {
+			request = state.CompileRequest
+		}
+		request.Transaction = state.Transaction
+		if request.FeePayer == "" && !request.UseSponsoredFeePayer {
+			request.FeePayer = state.Transaction.GetFeePayer()
+		}
+
+		resp, err := client.CompileTransaction(ctx, request)
+		if err != nil {
+			return err
+		}
+		state.Transaction = resp.GetTransaction()
+		return nil
+	}
+}
+
+// EstimateStep estimates the state's transaction with EstimateTransaction and rejects it
+// with an *EstimateCapError if the fee or compute units exceed their caps (0 is no cap)
+func EstimateStep(client ServiceClient, maxFeeLamports, maxComputeUnits uint64) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		request := &EstimateTransactionRequest{}
+		if state.EstimateRequest != nil {
+			request = state.EstimateRequest
+		}
+		request.Transaction = state.Transaction
+
+		resp, err := client.EstimateTransaction(ctx, request)
+		if err != nil {
+			return err
+		}
+		state.Estimate = resp
+
+		if maxFeeLamports > 0 && resp.GetFeeLamports() > maxFeeLamports {
+			return &EstimateCapError{Field: "fee_lamports", Estimate: resp.GetFeeLamports(), Cap: maxFeeLamports}
+		}
+		if maxComputeUnits > 0 && resp.GetComputeUnits() > maxComputeUnits {
+			return &EstimateCapError{Field: "compute_units", Estimate: resp.GetComputeUnits(), Cap: maxComputeUnits}
+		}
+		return nil
+	}
+}
+
+// SignStep signs the state's transaction with SignTransaction, using the signing method
+// of the state's SignRequest
+func SignStep(client ServiceClient) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		if state.SignRequest == nil || state.SignRequest.GetSigningMethod() == nil {
+			return errors.New("pipeline state has no signing method")
+		}
+		state.SignRequest.Transaction = state.Transaction
+
+		resp, err := client.SignTransaction(ctx, state.SignRequest)
+		if err != nil {
+			return err
+		}
+		state.Transaction = resp.GetTransaction()
+		return nil
+	}
+}
+
+// SubmitStep submits the state's transaction with SubmitTransaction and returns a
+// *SubmissionError unless it was sent (or, for dry runs, simulated successfully)
+func SubmitStep(client ServiceClient) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		request := &SubmitTransactionRequest{}
+		if state.SubmitRequest != nil {
+			request = state.SubmitRequest
+		}
+		request.Transaction = state.Transaction
+
+		resp, err := client.SubmitTransaction(ctx, request)
+		if err != nil {
+			return err
+		}
+		state.Submission = resp
+
+		switch resp.GetSubmissionResult() {
+		case SubmissionResult_SUBMISSION_RESULT_SUBMITTED, SubmissionResult_SUBMISSION_RESULT_DRY_RUN:
+			return nil
+		default:
+			return &SubmissionError{Result: resp.GetSubmissionResult(), Message: resp.GetErrorMessage()}
+		}
+	}
+}
+
+// MonitorStep follows the submitted transaction with MonitorTransaction until the stream
+// ends, keeping the last update on the state. It returns a *MonitorError if the
+// transaction failed, was dropped or was not confirmed in time. Dry runs are not monitored.
+func MonitorStep(client ServiceClient) common.StepFunc[PipelineState] {
+	return func(ctx context.Context, state *PipelineState) error {
+		if state.Submission.GetDryRun() {
+			return nil
+		}
+
+		request := &MonitorTransactionRequest{}
+		if state.MonitorRequest != nil {
+			request = state.MonitorRequest
+		}
+		request.Signature = state.Submission.GetSignature()
+		if request.CommitmentLevel == 0 && state.SubmitRequest != nil {
+			request.CommitmentLevel = state.SubmitRequest.GetCommitmentLevel()
+		}
+
+		stream, err := client.MonitorTransaction(ctx, request)
+		if err != nil {
+			return err
+		}
+		for {
+			update, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			state.Status = update
+		}
+
+		switch state.Status.GetStatus() {
+		case TransactionStatus_TRANSACTION_STATUS_CONFIRMED, TransactionStatus_TRANSACTION_STATUS_FINALIZED:
+			return nil
+		case TransactionStatus_TRANSACTION_STATUS_PROCESSED:
+			if state.Status.GetCurrentCommitment() >= request.GetCommitmentLevel() {
+				return nil
+			}
+		}
+		return &MonitorError{
+			Signature: request.GetSignature(),
+			Status:    state.Status.GetStatus(),
+			Message:   state.Status.GetErrorMessage(),
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package solana_type_v1
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// InvalidAmountReason is the google.rpc.ErrorInfo reason the API attaches to rejected amounts
+const InvalidAmountReason = "INVALID_AMOUNT"
+
+// MaxAmountDecimals is the largest decimals context an amount may be expressed in
+const MaxAmountDecimals = 19
+
+// AmountViolation identifies which amount parsing rule was broken
+type AmountViolation string
+
+const (
+	AmountViolationEmpty                AmountViolation = "EMPTY"
+	AmountViolationInvalidCharacter     AmountViolation = "INVALID_CHARACTER"
+	AmountViolationDecimalNotAllowed    AmountViolation = "DECIMAL_NOT_ALLOWED"
+	AmountViolationMalformedDecimal     AmountViolation = "MALFORMED_DECIMAL"
+	AmountViolationTooManyDecimalPlaces AmountViolation = "TOO_MANY_DECIMAL_PLACES"
+	AmountViolationOverflow             AmountViolation = "OVERFLOW"
+)
+
+// AmountError describes a rejected amount string and where it went wrong
+type AmountError struct {
+	// Field is the request field holding the amount (empty for local parsing)
+	Field string
+	// Violation is the rule that was broken
+	Violation AmountViolation
+	// Position is the zero-based character position of the offending character
+	Position int
+	// Message is a human-readable description
+	Message string
+}
+
+func (e *AmountError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %s", InvalidAmountReason, e.Message)
+	}
+	return fmt.Sprintf("%s: invalid %s: %s", InvalidAmountReason, e.Field, e.Message)
+}
+
+var maxAmount = new(big.Int).SetUint64(^uint64(0))
+
+// FormatLamports formats a lamport amount as whole base units, e.g. for the deprecated
+// FundNativeRequest.Amount string. New requests should set the typed Lamports or
+// NativeAmount field instead.
+func FormatLamports(lamports uint64) string {
+	return strconv.FormatUint(lamports, 10)
+}
+
+// FormatAmount formats a value in base units for a request amount with the given decimals
+// context, e.g. 1500000000 with 9 decimals becomes "1.5". The output never depends on locale.
+func FormatAmount(baseUnits *big.Int, decimals uint32) (string, error) {
+	if baseUnits == nil {
+		return "", errors.New("amount is required")
+	}
+	if decimals > MaxAmountDecimals {
+		return "", fmt.Errorf("decimals must not exceed %d", MaxAmountDecimals)
+	}
+	if baseUnits.Sign() < 0 {
+		return "", errors.New("amount must not be negative")
+	}
+	if baseUnits.Cmp(maxAmount) > 0 {
+		return "", errors.New("amount does not fit in 64 bits")
+	}
+
+	digits := baseUnits.Text(10)
+	if decimals == 0 {
+		return digits, nil
+	}
+	if pad := int(decimals) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	whole := digits[:len(digits)-int(decimals)]
+	fraction := strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if fraction == "" {
+		return whole, nil
+	}
+	return whole + "." + fraction, nil
+}
+
+// FormatDecimal formats a decimal value in whole units (e.g. 1.5 SOL) for a request amount
+// with the given decimals context. Values with more precision than decimals are rejected
+// rather than rounded.
+func FormatDecimal(value *big.Rat, decimals uint32) (string, error) {
+	if value == nil {
+		return "", errors.New("amount is required")
+	}
+	if decimals > MaxAmountDecimals {
+		return "", fmt.Errorf("decimals must not exceed %d", MaxAmountDecimals)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(scale))
+	if !scaled.IsInt() {
+		return "", fmt.Errorf("amount %s has more than %d decimal places", value.RatString(), decimals)
+	}
+	return FormatAmount(scaled.Num(), decimals)
+}
+
+// ParseAmount applies the API's amount parsing rules locally and returns the value in base
+// units, so that amounts can be validated before a request is sent.
+func ParseAmount(amount string, decimals uint32) (uint64, error) {
+	if amount == "" {
+		return 0, &AmountError{Violation: AmountViolationEmpty, Message: "amount is required"}
+	}
+	if decimals > MaxAmountDecimals {
+		return 0, &AmountError{
+			Violation: AmountViolationOverflow,
+			Message:   fmt.Sprintf("decimals must not exceed %d", MaxAmountDecimals),
+		}
+	}
+
+	decimalPoint := -1
+	for position, c := range []rune(amount) {
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && decimals == 0:
+			return 0, &AmountError{
+				Violation: AmountViolationDecimalNotAllowed,
+				Position:  position,
+				Message: fmt.Sprintf(
+					"decimal point at position %d requires a decimals context; send whole base units or set decimals",
+					position,
+				),
+			}
+		case c == '.' && decimalPoint >= 0:
+			return 0, &AmountError{
+				Violation: AmountViolationMalformedDecimal,
+				Position:  position,
+				Message:   fmt.Sprintf("second decimal point at position %d", position),
+			}
+		case c == '.':
+			decimalPoint = position
+		default:
+			return 0, &AmountError{
+				Violation: AmountViolationInvalidCharacter,
+				Position:  position,
+				Message: fmt.Sprintf(
+					"invalid character %q at position %d; only ASCII digits and a single '.' are allowed",
+					c, position,
+				),
+			}
+		}
+	}
+
+	// Every character is now ASCII, so byte offsets equal character positions
+	whole, fraction := amount, ""
+	if decimalPoint >= 0 {
+		whole, fraction = amount[:decimalPoint], amount[decimalPoint+1:]
+		if whole == "" || fraction == "" {
+			return 0, &AmountError{
+				Violation: AmountViolationMalformedDecimal,
+				Position:  decimalPoint,
+				Message:   fmt.Sprintf("decimal point at position %d must have digits on both sides", decimalPoint),
+			}
+		}
+	}
+	if len(fraction) > int(decimals) {
+		position := len(whole) + 1 + int(decimals)
+		return 0, &AmountError{
+			Violation: AmountViolationTooManyDecimalPlaces,
+			Position:  position,
+			Message:   fmt.Sprintf("at most %d decimal places are allowed, extra digit at position %d", decimals, position),
+		}
+	}
+
+	value, err := strconv.ParseUint(whole+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, &AmountError{
+			Violation: AmountViolationOverflow,
+			Message:   fmt.Sprintf("amount %s does not fit in 64 bits", amount),
+		}
+	}
+	return value, nil
+}
+
+// AmountErrorFromStatus extracts the amount error details from a gRPC error returned by the
+// API. It returns false if err is not an INVALID_AMOUNT error.
+func AmountErrorFromStatus(err error) (*AmountError, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+
+	var amountErr *AmountError
+	descriptions := make(map[string]string)
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if detail.GetReason() != InvalidAmountReason {
+				continue
+			}
+			metadata := detail.GetMetadata()
+			position, _ := strconv.Atoi(metadata["position"])
+			amountErr = &AmountError{
+				Field:     metadata["field"],
+				Violation: AmountViolation(metadata["violation"]),
+				Position:  position,
+				Message:   st.Message(),
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				descriptions[violation.GetField()] = violation.GetDescription()
+			}
+		}
+	}
+	if amountErr == nil {
+		return nil, false
+	}
+	if description, ok := descriptions[amountErr.Field]; ok {
+		amountErr.Message = description
+	}
+	return amountErr, true
+}
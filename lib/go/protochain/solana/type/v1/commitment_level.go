@@ -0,0 +1,19 @@
+package solana_type_v1
+
+// CommitmentLevelValue dereferences an optional commitment level. Commitment fields are
+// plain values, with UNSPECIFIED selecting the service default, so nil becomes
+// UNSPECIFIED. The generated Enum method converts the other way.
+func CommitmentLevelValue(level *CommitmentLevel) CommitmentLevel {
+	if level == nil {
+		return CommitmentLevel_COMMITMENT_LEVEL_UNSPECIFIED
+	}
+	return *level
+}
+
+// CommitmentLevelOr returns level, or fallback if level is UNSPECIFIED
+func CommitmentLevelOr(level, fallback CommitmentLevel) CommitmentLevel {
+	if level == CommitmentLevel_COMMITMENT_LEVEL_UNSPECIFIED {
+		return fallback
+	}
+	return level
+}
@@ -16,6 +16,46 @@ pub mod protochain {
             }
         }
         pub mod program {
+            pub mod address_lookup_table {
+                pub mod v1 {
+                    include!("protochain.solana.program.address_lookup_table.v1.rs");
+                }
+            }
+            pub mod ata {
+                pub mod v1 {
+                    include!("protochain.solana.program.ata.v1.rs");
+                }
+            }
+            pub mod bpf_loader {
+                pub mod v1 {
+                    include!("protochain.solana.program.bpf_loader.v1.rs");
+                }
+            }
+            pub mod compute_budget {
+                pub mod v1 {
+                    include!("protochain.solana.program.compute_budget.v1.rs");
+                }
+            }
+            pub mod governance {
+                pub mod v1 {
+                    include!("protochain.solana.program.governance.v1.rs");
+                }
+            }
+            pub mod memo {
+                pub mod v1 {
+                    include!("protochain.solana.program.memo.v1.rs");
+                }
+            }
+            pub mod metadata {
+                pub mod v1 {
+                    include!("protochain.solana.program.metadata.v1.rs");
+                }
+            }
+            pub mod stake {
+                pub mod v1 {
+                    include!("protochain.solana.program.stake.v1.rs");
+                }
+            }
             pub mod system {
                 pub mod v1 {
                     include!("protochain.solana.program.system.v1.rs");
@@ -26,6 +66,11 @@ pub mod protochain {
                     include!("protochain.solana.program.token.v1.rs");
                 }
             }
+            pub mod vote {
+                pub mod v1 {
+                    include!("protochain.solana.program.vote.v1.rs");
+                }
+            }
         }
         pub mod r#type {
             pub mod v1 {
@@ -37,6 +82,36 @@ pub mod protochain {
                 include!("protochain.solana.rpc_client.v1.rs");
             }
         }
+        pub mod admin {
+            pub mod v1 {
+                include!("protochain.solana.admin.v1.rs");
+            }
+        }
+        pub mod key_vault {
+            pub mod v1 {
+                include!("protochain.solana.key_vault.v1.rs");
+            }
+        }
+        pub mod convenience {
+            pub mod v1 {
+                include!("protochain.solana.convenience.v1.rs");
+            }
+        }
+        pub mod transaction_template {
+            pub mod v1 {
+                include!("protochain.solana.transaction_template.v1.rs");
+            }
+        }
+        pub mod operations {
+            pub mod v1 {
+                include!("protochain.solana.operations.v1.rs");
+            }
+        }
+        pub mod staking {
+            pub mod v1 {
+                include!("protochain.solana.staking.v1.rs");
+            }
+        }
     }
 }
 
@@ -0,0 +1,38 @@
+import { create } from '@bufbuild/protobuf';
+
+import {
+  FundNativeRequestSchema,
+  type FundNativeRequest,
+} from './protochain/solana/account/v1/service_pb';
+import type { Amount } from './protochain/solana/type/v1/amount_pb';
+import type { CommitmentLevel } from './protochain/solana/type/v1/commitment_level_pb';
+
+/**
+ * Builds a FundNativeRequest with a typed amount: lamports as a bigint, or an Amount in
+ * lamports with 9 decimals. Use this instead of the deprecated string `amount`, which
+ * servers accept with a deprecation warning and will eventually refuse.
+ */
+export function fundNativeRequest(
+  address: string,
+  lamports: bigint,
+  commitmentLevel?: CommitmentLevel,
+): FundNativeRequest;
+export function fundNativeRequest(
+  address: string,
+  amount: Amount,
+  commitmentLevel?: CommitmentLevel,
+): FundNativeRequest;
+export function fundNativeRequest(
+  address: string,
+  amount: bigint | Amount,
+  commitmentLevel?: CommitmentLevel,
+): FundNativeRequest {
+  return create(FundNativeRequestSchema, {
+    address,
+    commitmentLevel,
+    typedAmount:
+      typeof amount === 'bigint'
+        ? { case: 'lamports', value: amount }
+        : { case: 'nativeAmount', value: amount },
+  });
+}
@@ -13,30 +13,209 @@ export const SDK_NAME = 'Protochain TypeScript SDK';
 export { Service as AccountService } from './protochain/solana/account/v1/service_pb';
 export type {
   GetAccountRequest,
+  GetAccountsRequest,
+  GetAccountsResponse,
+  AccountEntry,
+  GetPortfolioRequest,
+  GetPortfolioResponse,
+  GetBalanceRequest,
+  GetBalanceResponse,
+  GetTokenBalancesRequest,
+  GetTokenBalancesResponse,
+  NativeBalance,
+  TokenHolding,
+  GetAccountDataRequest,
+  GetAccountDataResponse,
+  AccountDataHeader,
+  AccountDataChunk,
+  AccountDataTrailer,
+  MonitorAccountRequest,
+  MonitorAccountResponse,
+  WaitForAccountRequest,
+  WaitForAccountResponse,
   GenerateNewKeyPairRequest,
   GenerateNewKeyPairResponse,
+  ImportKeyPairRequest,
+  MnemonicSecretKey,
+  ImportKeyPairResponse,
+  ExportKeyPairRequest,
+  ExportKeyPairResponse,
   FundNativeRequest,
   FundNativeResponse,
+  DeriveProgramAddressRequest,
+  ProgramAddressSeed,
+  DeriveProgramAddressResponse,
+  DeriveAssociatedTokenAddressRequest,
+  DeriveAssociatedTokenAddressResponse,
+  ExportAccountSetRequest,
+  ExportAccountSetResponse,
+  AccountSnapshot,
+  ImportAccountSetRequest,
+  ImportAccountSetResponse,
 } from './protochain/solana/account/v1/service_pb';
+export { SecretKeyFormat, WaitForAccountEvent } from './protochain/solana/account/v1/service_pb';
+export { fundNativeRequest } from './funding';
 
 // Transaction Service
 export { Service as TransactionService } from './protochain/solana/transaction/v1/service_pb';
 export type {
   CompileTransactionRequest,
   CompileTransactionResponse,
+  InstructionPosition,
+  SponsorshipQuote,
+  AutoComputeBudget,
   EstimateTransactionRequest,
   EstimateTransactionResponse,
   SimulateTransactionRequest,
   SimulateTransactionResponse,
+  InstructionComputeUsage,
+  SimulatedAccount,
+  SimulationCacheInfo,
+  SimulationReturnData,
   SignTransactionRequest,
   SignTransactionResponse,
+  SignWithStoredKeys,
+  SignWithMnemonic,
+  SignWithHardwareWallet,
+  SignWithKMS,
+  SignWithVault,
+  ListHardwareWalletsRequest,
+  ListHardwareWalletsResponse,
+  HardwareWallet,
+  GetRequiredSignersRequest,
+  GetRequiredSignersResponse,
+  RequiredSigner,
+  ValidateTransactionRequest,
+  ValidateTransactionResponse,
+  ConvertTransactionVersionRequest,
+  ConvertTransactionVersionResponse,
+  ConversionBlocker,
+  CompareTransactionsRequest,
+  CompareTransactionsResponse,
+  DescribeTransactionRequest,
+  DescribeTransactionResponse,
+  DescribedInstruction,
+  InstructionAccount,
+  DescribedAccount,
+  LamportMovement,
+  TokenMovement,
+  SplitInstructionsRequest,
+  SplitInstructionsResponse,
+  InstructionRange,
+  InstructionDependency,
+  SplitTransaction,
+  ExportTransactionBundleRequest,
+  ExportTransactionBundleResponse,
+  ImportTransactionBundleRequest,
+  ImportTransactionBundleResponse,
+  GetPriorityFeeEstimateRequest,
+  GetPriorityFeeEstimateResponse,
   SubmitTransactionRequest,
   SubmitTransactionResponse,
+  MintSubmissionTokenRequest,
+  MintSubmissionTokenResponse,
+  RetryPolicy,
+  RebroadcastPolicy,
+  SubmissionAttempt,
+  SubmissionRecord,
+  SearchSubmissionsRequest,
+  SearchSubmissionsResponse,
+  EnqueueTransactionRequest,
+  EnqueueTransactionResponse,
+  QueuedTransaction,
+  GetQueueStatusRequest,
+  GetQueueStatusResponse,
+  StreamQueueEventsRequest,
+  StreamQueueEventsResponse,
   GetTransactionRequest,
   GetTransactionResponse,
+  GetTransactionHistoryRequest,
+  GetTransactionHistoryResponse,
+  TransactionHistoryEntry,
+  BalanceChange,
+  TokenBalanceChange,
   MonitorTransactionRequest,
   MonitorTransactionResponse,
+  PollingConfig,
+  CheckTransactionStatusRequest,
+  CheckTransactionStatusResponse,
+  MonitorTransfersRequest,
+  MonitorTransfersResponse,
+  TransferEvent,
+  SubmitBundleRequest,
+  SubmitBundleResponse,
+  MonitorBundleRequest,
+  MonitorBundleResponse,
+  BundleTransactionStatus,
 } from './protochain/solana/transaction/v1/service_pb';
+export { TransferKind } from './protochain/solana/transaction/v1/service_pb';
+export { InstructionOrigin } from './protochain/solana/transaction/v1/service_pb';
+
+// Key Vault Service
+export { Service as KeyVaultService } from './protochain/solana/key_vault/v1/service_pb';
+export type {
+  CreateKeyRequest,
+  CreateKeyResponse,
+  GetKeyRequest,
+  GetKeyResponse,
+  ListKeysRequest,
+  ListKeysResponse,
+  RotateKeyRequest,
+  RotateKeyResponse,
+  ListKeyRotationsRequest,
+  ListKeyRotationsResponse,
+} from './protochain/solana/key_vault/v1/service_pb';
+
+// Convenience Service
+export { Service as ConvenienceService } from './protochain/solana/convenience/v1/service_pb';
+export type {
+  BuildSolTransferRequest,
+  BuildTokenTransferRequest,
+  BuildAccountCreateRequest,
+  BuildTransactionResponse,
+  DistributeTokensRequest,
+  DistributionRecipient,
+  DistributeTokensResponse,
+  DistributionPlan,
+  DistributionRecipientResult,
+  DistributionSummary,
+} from './protochain/solana/convenience/v1/service_pb';
+export { DistributionRecipientStatus } from './protochain/solana/convenience/v1/service_pb';
+
+// Transaction Template Service
+export { Service as TransactionTemplateService } from './protochain/solana/transaction_template/v1/service_pb';
+export type {
+  SaveTemplateRequest,
+  SaveTemplateResponse,
+  GetTemplateRequest,
+  GetTemplateResponse,
+  ListTemplatesRequest,
+  ListTemplatesResponse,
+  DeleteTemplateRequest,
+  DeleteTemplateResponse,
+  InstantiateTemplateRequest,
+  InstantiateTemplateResponse,
+} from './protochain/solana/transaction_template/v1/service_pb';
+
+// Operations Service
+export { Service as OperationsService } from './protochain/solana/operations/v1/service_pb';
+export type {
+  GetOperationRequest,
+  GetOperationResponse,
+  ListOperationsRequest,
+  ListOperationsResponse,
+  CancelOperationRequest,
+  CancelOperationResponse,
+} from './protochain/solana/operations/v1/service_pb';
+
+// Staking Service
+export { Service as StakingService } from './protochain/solana/staking/v1/service_pb';
+export type {
+  StakeToValidatorRequest,
+  StakeToValidatorResponse,
+  UnstakeAndWithdrawRequest,
+  UnstakeAndWithdrawResponse,
+} from './protochain/solana/staking/v1/service_pb';
 
 // RPC Client Service
 export { Service as RPCClientService } from './protochain/solana/rpc_client/v1/service_pb';
@@ -45,6 +224,25 @@ export type {
   GetMinimumBalanceForRentExemptionResponse,
 } from './protochain/solana/rpc_client/v1/service_pb';
 
+// Admin Service
+export { Service as AdminService } from './protochain/solana/admin/v1/service_pb';
+export type {
+  GetCapabilitiesRequest,
+  GetCapabilitiesResponse,
+  SetFeatureFlagRequest,
+  SetFeatureFlagResponse,
+  ListDeadLettersRequest,
+  ListDeadLettersResponse,
+  GetDeadLetterRequest,
+  GetDeadLetterResponse,
+  RequeueDeadLetterRequest,
+  RequeueDeadLetterResponse,
+  GetRpcLimitsRequest,
+  GetRpcLimitsResponse,
+  GetStoreStatsRequest,
+  GetStoreStatsResponse,
+} from './protochain/solana/admin/v1/service_pb';
+
 // System Program Service (returns SolanaInstruction for all methods)
 export { Service as SystemProgramService } from './protochain/solana/program/system/v1/service_pb';
 export type {
@@ -61,6 +259,11 @@ export type {
   InitializeNonceAccountRequest,
   AuthorizeNonceAccountRequest,
   UpgradeNonceAccountRequest,
+  CreateNonceAccountRequest,
+  CreateNonceAccountResponse,
+  ParseNonceAccountRequest,
+  ParseNonceAccountResponse,
+  NonceAccountInfo,
 } from './protochain/solana/program/system/v1/service_pb';
 
 // Token Program Service
@@ -72,14 +275,196 @@ export type {
   GetCurrentMinRentForTokenAccountResponse,
   ParseMintRequest,
   ParseMintResponse,
+  GetTokenMetadataRequest,
+  GetTokenMetadataResponse,
+  TokenMetadata,
+  OffChainTokenMetadata,
+  TokenMetadataAttribute,
+  TransferRequest as TokenTransferRequest,
+  TransferResponse as TokenTransferResponse,
+  TransferCheckedRequest,
+  TransferCheckedResponse,
+  BuildTokenTransferRequest as TokenBuildTokenTransferRequest,
+  BuildTokenTransferResponse as TokenBuildTokenTransferResponse,
+  BurnRequest,
+  BurnResponse,
+  ApproveRequest,
+  ApproveResponse,
+  RevokeRequest,
+  RevokeResponse,
+  CloseAccountRequest,
+  CloseAccountResponse,
+  FreezeAccountRequest,
+  FreezeAccountResponse,
+  ThawAccountRequest,
+  ThawAccountResponse,
+  SetAuthorityRequest as TokenSetAuthorityRequest,
+  SetAuthorityResponse as TokenSetAuthorityResponse,
+  TokenAuthorityType,
+  ParseHoldingAccountRequest,
+  ParseHoldingAccountResponse,
+  HoldingAccountInfo,
+  HoldingAccountState,
+  HoldingAccountExtension,
+  TransferFeeAmountState,
+  MemoTransferState,
+  CpiGuardState,
+  TransferHookAccountState,
+  InterestBearingMintConfig,
+  InterestBearingMintInfo,
+  HoldingAccountExtensionType,
+  CalculateTokenAccountSizeRequest,
+  CalculateTokenAccountSizeResponse,
+  CreateAssociatedHoldingAccountRequest,
+  CreateAssociatedHoldingAccountResponse,
+  GetMintSupplyRequest,
+  GetMintSupplyResponse,
+  ListLargestHoldersRequest,
+  ListLargestHoldersResponse,
+  LargestHolder,
+} from './protochain/solana/program/token/v1/service_pb';
+export {
+  TokenMetadataSource,
+  OffChainMetadataStatus,
 } from './protochain/solana/program/token/v1/service_pb';
 
+// Associated Token Account Program Service (request names prefixed to avoid clashes)
+export { Service as AtaProgramService } from './protochain/solana/program/ata/v1/service_pb';
+export type {
+  CreateRequest as AtaCreateRequest,
+  CreateIdempotentRequest as AtaCreateIdempotentRequest,
+  RecoverNestedRequest as AtaRecoverNestedRequest,
+  DeriveAddressRequest as AtaDeriveAddressRequest,
+  DeriveAddressResponse as AtaDeriveAddressResponse,
+} from './protochain/solana/program/ata/v1/service_pb';
+
+// Memo Program Service
+export { Service as MemoProgramService } from './protochain/solana/program/memo/v1/service_pb';
+export type { MemoRequest } from './protochain/solana/program/memo/v1/service_pb';
+
+// Compute Budget Program Service
+export { Service as ComputeBudgetProgramService } from './protochain/solana/program/compute_budget/v1/service_pb';
+
+// Stake Program Service (names prefixed where they would clash)
+export { Service as StakeProgramService } from './protochain/solana/program/stake/v1/service_pb';
+export type {
+  CreateStakeAccountRequest,
+  CreateStakeAccountResponse,
+  DelegateStakeRequest,
+  DeactivateRequest as StakeDeactivateRequest,
+  WithdrawRequest as StakeWithdrawRequest,
+  SplitRequest as StakeSplitRequest,
+  SplitResponse as StakeSplitResponse,
+  MergeRequest as StakeMergeRequest,
+  AuthorizeRequest as StakeAuthorizeRequest,
+  Lockup as StakeLockup,
+  Delegation as StakeDelegation,
+  ParseStakeAccountRequest,
+  ParseStakeAccountResponse,
+  StakeAccountInfo,
+} from './protochain/solana/program/stake/v1/service_pb';
+export {
+  StakeAuthorize,
+  StakeState,
+  DelegationPhase as StakeDelegationPhase,
+} from './protochain/solana/program/stake/v1/service_pb';
+
+// Address Lookup Table Program Service
+export { Service as AddressLookupTableProgramService } from './protochain/solana/program/address_lookup_table/v1/service_pb';
+export type {
+  CreateLookupTableRequest,
+  CreateLookupTableResponse,
+  ExtendLookupTableRequest,
+  FreezeLookupTableRequest,
+  DeactivateLookupTableRequest,
+  CloseLookupTableRequest,
+  ParseLookupTableRequest,
+  ParseLookupTableResponse,
+  LookupTableInfo,
+} from './protochain/solana/program/address_lookup_table/v1/service_pb';
+
+// Vote Program Service
+export { Service as VoteProgramService } from './protochain/solana/program/vote/v1/service_pb';
+export type {
+  ParseVoteAccountRequest,
+  ParseVoteAccountResponse,
+  VoteAccountInfo,
+  EpochCredits,
+  ListVoteAccountsRequest,
+  ListVoteAccountsResponse,
+  ValidatorVoteAccount,
+} from './protochain/solana/program/vote/v1/service_pb';
+
+// Token Metadata Service (names prefixed where they would clash with the token service)
+export { Service as MetadataService } from './protochain/solana/program/metadata/v1/service_pb';
+export type {
+  GetTokenMetadataRequest as MetadataGetTokenMetadataRequest,
+  GetTokenMetadataResponse as MetadataGetTokenMetadataResponse,
+  TokenMetadataInfo,
+  InitializeMetadataPointerRequest,
+  UpdateMetadataPointerRequest,
+  InitializeMetadataRequest,
+  UpdateMetadataFieldRequest,
+  RemoveMetadataKeyRequest,
+  UpdateMetadataAuthorityRequest,
+} from './protochain/solana/program/metadata/v1/service_pb';
+export { MetadataField } from './protochain/solana/program/metadata/v1/service_pb';
+
+// SPL Governance Service
+export { Service as GovernanceService } from './protochain/solana/program/governance/v1/service_pb';
+export type {
+  ParseRealmRequest,
+  ParseRealmResponse,
+  RealmInfo,
+  ParseTokenOwnerRecordRequest,
+  ParseTokenOwnerRecordResponse,
+  TokenOwnerRecordInfo,
+  ParseProposalRequest,
+  ParseProposalResponse,
+  ProposalInfo,
+  ProposalOption,
+  VoteThreshold,
+  ListProposalsRequest,
+  ListProposalsResponse,
+} from './protochain/solana/program/governance/v1/service_pb';
+export {
+  MaxVoterWeightSource,
+  ProposalState,
+  VoteType,
+  OptionVoteResult,
+  VoteThresholdType,
+} from './protochain/solana/program/governance/v1/service_pb';
+
+// Upgradeable BPF Loader Service
+export { Service as BpfLoaderService } from './protochain/solana/program/bpf_loader/v1/service_pb';
+export type {
+  InitializeBufferRequest,
+  InitializeBufferResponse,
+  WriteRequest as BpfLoaderWriteRequest,
+  WriteResponse as BpfLoaderWriteResponse,
+  DeployWithMaxDataLenRequest,
+  DeployWithMaxDataLenResponse,
+  UpgradeRequest as BpfLoaderUpgradeRequest,
+  SetAuthorityRequest as BpfLoaderSetAuthorityRequest,
+  CloseRequest as BpfLoaderCloseRequest,
+  DeployProgramRequest,
+  DeployProgramResponse,
+} from './protochain/solana/program/bpf_loader/v1/service_pb';
+export { LoaderAccountKind, DeployStage } from './protochain/solana/program/bpf_loader/v1/service_pb';
+export type {
+  SetComputeUnitLimitRequest,
+  SetComputeUnitPriceRequest,
+  SetLoadedAccountsDataSizeLimitRequest,
+  RequestHeapFrameRequest,
+} from './protochain/solana/program/compute_budget/v1/service_pb';
+
 // =============================================================================
 // CORE TYPES
 // =============================================================================
 
 // Account types
 export type { Account as AccountSchema } from './protochain/solana/account/v1/account_pb';
+export { AccountDataEncoding } from './protochain/solana/account/v1/account_pb';
 
 // Transaction types
 export type {
@@ -93,10 +478,68 @@ export type {
   SolanaAccountMeta,
 } from './protochain/solana/transaction/v1/instruction_pb';
 
+// Decoded instruction types
+export type {
+  DecodedInstruction,
+  SystemInstructionDetails,
+  TokenInstructionDetails,
+  AssociatedTokenInstructionDetails,
+  ComputeBudgetInstructionDetails,
+  MemoInstructionDetails,
+} from './protochain/solana/transaction/v1/decoded_instruction_pb';
+export { ProgramKind } from './protochain/solana/transaction/v1/decoded_instruction_pb';
+
+// Inner instruction (CPI) types
+export type {
+  InnerInstructions,
+  InnerInstruction,
+  InvocationNode,
+} from './protochain/solana/transaction/v1/inner_instruction_pb';
+
+// Transaction comparison types
+export type {
+  AccountChange,
+  InstructionChange,
+} from './protochain/solana/transaction/v1/comparison_pb';
+export {
+  AccountChangeKind,
+  InstructionChangeKind,
+} from './protochain/solana/transaction/v1/comparison_pb';
+
+// Transaction diagnostic types
+export type { TransactionDiagnostic } from './protochain/solana/transaction/v1/diagnostic_pb';
+export {
+  DiagnosticCode,
+  DiagnosticSeverity,
+} from './protochain/solana/transaction/v1/diagnostic_pb';
+
+// Admin types
+export type { FeatureFlag } from './protochain/solana/admin/v1/feature_flag_pb';
+export { FeatureFlagSource } from './protochain/solana/admin/v1/feature_flag_pb';
+export type { DeadLetter } from './protochain/solana/admin/v1/dead_letter_pb';
+export type { RpcLimit } from './protochain/solana/admin/v1/rpc_limit_pb';
+export type { StoreStats } from './protochain/solana/admin/v1/store_stats_pb';
+
+// Key vault types
+export type { VaultKey, KeyRotation } from './protochain/solana/key_vault/v1/key_pb';
+
+// Transaction template types
+export type {
+  TransactionTemplate,
+  TemplateParameter,
+  AmountPlacement,
+} from './protochain/solana/transaction_template/v1/template_pb';
+export { TemplateParameterKind } from './protochain/solana/transaction_template/v1/template_pb';
+
+// Operation types
+export type { Operation } from './protochain/solana/operations/v1/operation_pb';
+export { OperationState } from './protochain/solana/operations/v1/operation_pb';
+
 // Common types
 export type { KeyPair } from './protochain/solana/type/v1/keypair_pb';
 
 export type { CommitmentLevel } from './protochain/solana/type/v1/commitment_level_pb';
+export type { Amount } from './protochain/solana/type/v1/amount_pb';
 
 // =============================================================================
 // RE-EXPORTS FOR CONNECT USAGE
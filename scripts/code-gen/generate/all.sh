@@ -28,7 +28,8 @@ if [ ! -d "${PROJECT_ROOT}/lib/proto" ]; then
 fi
 
 echo "🔍 Validating protobuf definitions..."
-if ! buf lint "${PROJECT_ROOT}/lib/proto"; then
+# buf lint plus the protochain rules (see tool/protocheck)
+if ! (cd "${PROJECT_ROOT}" && go run ./tool/protocheck/cmd/protocheck lint); then
     echo "❌ Protobuf linting failed"
     exit 1
 fi
@@ -43,11 +44,20 @@ if ! buf generate "lib/proto" --template "lib/_code_gen/buf.gen.yaml"; then
     exit 1
 fi
 
+echo ""
+echo "🔨 Compiling generated example programs..."
+if ! (cd "${PROJECT_ROOT}/lib/go" && go build ./examples/...); then
+    echo "❌ Example programs no longer compile against the generated SDK"
+    exit 1
+fi
+echo "✅ Example programs compile"
+
 echo ""
 echo "✅ All code generation complete!"
 echo ""
 echo "Generated files:"
 echo "  • Go:         lib/go/protochain/"
+echo "  • Examples:   lib/go/examples/"
 echo "  • Rust:       lib/rust/src/"
 echo "  • TypeScript: lib/ts/src/"
 echo ""
\ No newline at end of file
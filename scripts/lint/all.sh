@@ -44,6 +44,7 @@ run_lint_script() {
 run_lint_script "ts.sh"
 run_lint_script "go.sh" 
 run_lint_script "rs.sh"
+run_lint_script "proto.sh"
 
 # Final report
 echo -e "${BLUE}========================================${NC}"
@@ -0,0 +1,39 @@
+#!/bin/bash
+
+# Protochain Proto Linting Script
+# Runs buf lint, the protochain rules and buf breaking through tool/protocheck
+# Requires buf to be pre-installed
+
+set -e
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(cd "${SCRIPT_DIR}/../.." && pwd)"
+
+# Color codes
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+BLUE='\033[0;34m'
+NC='\033[0m'
+
+echo -e "${BLUE}[Proto Linting]${NC}"
+
+cd "${PROJECT_ROOT}"
+
+# Check if buf is available
+if ! command -v buf &> /dev/null; then
+    echo -e "${RED}✗ buf is required but not installed${NC}"
+    echo -e "${RED}Please install buf from https://docs.buf.build/installation${NC}"
+    exit 1
+fi
+
+# Breaking changes are checked against main unless PROTOCHECK_AGAINST says otherwise
+AGAINST="${PROTOCHECK_AGAINST:-.git#branch=main}"
+
+echo -e "${YELLOW}Running protocheck against ${AGAINST}...${NC}"
+if go run ./tool/protocheck/cmd/protocheck check -against "${AGAINST}"; then
+    echo -e "${GREEN}✓ All proto linting passed${NC}"
+else
+    echo -e "${RED}✗ Proto linting failed${NC}"
+    exit 1
+fi
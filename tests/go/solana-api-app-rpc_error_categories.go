@@ -77,41 +77,17 @@ func (suite *ErrorCategoriesTestSuite) createFundedTestAccount(fundingAmount str
 	privateKey = keyResp.KeyPair.PrivateKey
 
 	// Fund the account
-	fundResp, err := suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
-		Address: address,
-		Amount:  fundingAmount,
+	_, err = suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
+		Address:          address,
+		Amount:           fundingAmount,
+		CommitmentLevel:  type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		WaitUntilVisible: true,
 	})
 	suite.Require().NoError(err, "Should fund account")
 
-	// Wait for account visibility
-	suite.waitForAccountVisible(fundResp.GetSignature(), address)
-
 	return address, privateKey
 }
 
-// Helper function to wait for account to become visible on blockchain
-func (suite *ErrorCategoriesTestSuite) waitForAccountVisible(signature, address string) {
-	if signature != "" {
-		suite.monitorTransactionToCompletion(signature)
-	}
-
-	for attempt := 1; attempt <= 15; attempt++ {
-		_, err := suite.accountService.GetAccount(suite.ctx, &account_v1.GetAccountRequest{
-			Address:         address,
-			CommitmentLevel: type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
-		})
-		if err == nil {
-			return // Account is now visible
-		}
-
-		if attempt < 15 {
-			time.Sleep(200 * time.Millisecond)
-		}
-	}
-
-	suite.FailNow("Account failed to become visible after funding")
-}
-
 // Helper function to create a basic transfer instruction
 func (suite *ErrorCategoriesTestSuite) createTransferInstruction(fromAddress, toAddress string, amount uint64) *transaction_v1.SolanaInstruction {
 	return &transaction_v1.SolanaInstruction{
@@ -1097,6 +1097,7 @@ func (suite *StreamingE2ETestSuite) Test_10_PreStreamingValidation() {
 	statusSequence := []transaction_v1.TransactionStatus{}
 	wsNotifications := 0
 	rpcNotifications := 0
+	lastPollCount := uint32(0)
 
 	suite.T().Log("🔍 Monitoring transaction status updates with detailed tracking...")
 
@@ -1110,18 +1111,26 @@ func (suite *StreamingE2ETestSuite) Test_10_PreStreamingValidation() {
 		elapsed := time.Since(startTime)
 		statusSequence = append(statusSequence, resp.Status)
 
-		// Try to infer if this came from WebSocket or RPC polling
-		// (we can enhance backend logging to be more specific)
-		suite.T().Logf("📊 [+%dms] Status: %s, Slot: %d, Logs: %d entries",
-			elapsed.Milliseconds(), resp.Status, resp.GetSlot(), len(resp.GetLogs()))
+		suite.T().Logf("📊 [+%dms] Status: %s, Slot: %d, Logs: %d entries, Mechanism: %s, Polls: %d",
+			elapsed.Milliseconds(), resp.Status, resp.GetSlot(), len(resp.GetLogs()),
+			resp.GetMechanism(), resp.GetPollCount())
 
-		// Count different types of notifications based on timing patterns
-		// Fast responses (< 100ms) are likely WebSocket, slower ones are RPC polling
-		if elapsed < 100*time.Millisecond {
+		// The backend reports which mechanism observed each update
+		switch resp.GetMechanism() {
+		case transaction_v1.MonitoringMechanism_MONITORING_MECHANISM_WEBSOCKET:
 			wsNotifications++
-		} else {
+		case transaction_v1.MonitoringMechanism_MONITORING_MECHANISM_POLLING:
 			rpcNotifications++
+		default:
+			suite.Require().Fail("Status update should report its monitoring mechanism")
 		}
+		suite.Require().GreaterOrEqual(resp.GetPollCount(), lastPollCount,
+			"poll count should never decrease")
+		if resp.GetMechanism() == transaction_v1.MonitoringMechanism_MONITORING_MECHANISM_POLLING && len(statusSequence) > 1 {
+			suite.Require().GreaterOrEqual(resp.GetPollCount(), uint32(1),
+				"a later polled update should follow at least one fallback poll")
+		}
+		lastPollCount = resp.GetPollCount()
 
 		// Check for terminal status
 		if resp.Status == transaction_v1.TransactionStatus_TRANSACTION_STATUS_CONFIRMED ||
@@ -1147,8 +1156,8 @@ func (suite *StreamingE2ETestSuite) Test_10_PreStreamingValidation() {
 
 	// Log the complete sequence for analysis
 	suite.T().Logf("📈 Status sequence: %v", statusSequence)
-	suite.T().Logf("⚡ Potential WebSocket notifications (< 100ms): %d", wsNotifications)
-	suite.T().Logf("🔄 Potential RPC polling notifications (≥ 100ms): %d", rpcNotifications)
+	suite.T().Logf("⚡ WebSocket notifications: %d", wsNotifications)
+	suite.T().Logf("🔄 RPC polling notifications: %d", rpcNotifications)
 
 	// Validate final status is success
 	finalStatus := statusSequence[len(statusSequence)-1]
@@ -1157,11 +1166,10 @@ func (suite *StreamingE2ETestSuite) Test_10_PreStreamingValidation() {
 			finalStatus == transaction_v1.TransactionStatus_TRANSACTION_STATUS_FINALIZED,
 		"Final status should be CONFIRMED or FINALIZED")
 
-	// If we got very fast notifications, it's likely WebSocket worked
 	if wsNotifications > 0 {
-		suite.T().Logf("🎉 SUCCESS: Detected %d fast notifications - WebSocket likely working!", wsNotifications)
+		suite.T().Logf("🎉 SUCCESS: Received %d WebSocket notifications!", wsNotifications)
 	} else {
-		suite.T().Logf("⚠️  WARNING: No fast notifications detected - may be relying on RPC polling fallback")
+		suite.T().Logf("⚠️  WARNING: No WebSocket notifications received - relied on RPC polling fallback")
 		suite.T().Log("   This could indicate WebSocket issues with local test validator")
 		suite.T().Log("   But the hybrid approach ensures functionality regardless!")
 	}
@@ -2,7 +2,6 @@ package apitest
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"io"
@@ -73,16 +72,15 @@ func (suite *TokenProgramE2ETestSuite) Test_01_InitialiseMint() {
 	suite.Require().NoError(err, "Should generate payer keypair")
 
 	// Fund payer account
-	fundResp, err := suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
-		Address: payKeyResp.KeyPair.PublicKey,
-		Amount:  "5000000000", // 5 SOL
+	_, err = suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
+		Address:          payKeyResp.KeyPair.PublicKey,
+		Amount:           "5000000000", // 5 SOL
+		CommitmentLevel:  type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		WaitUntilVisible: true,
 	})
 	suite.Require().NoError(err, "Should fund payer account")
 	suite.T().Logf("  Funded payer account: %s", payKeyResp.KeyPair.PublicKey)
 
-	// Wait for payer account to be funded
-	suite.waitForAccountVisible(fundResp.GetSignature(), payKeyResp.KeyPair.PublicKey)
-
 	// Generate mint account keypair
 	mintKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
 	suite.Require().NoError(err, "Should generate mint keypair")
@@ -247,16 +245,15 @@ func (suite *TokenProgramE2ETestSuite) Test_03_Token_e2e() {
 	suite.Require().NoError(err, "Should generate payer keypair")
 
 	// Fund payer account
-	fundResp, err := suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
-		Address: payKeyResp.KeyPair.PublicKey,
-		Amount:  "5000000000", // 5 SOL
+	_, err = suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
+		Address:          payKeyResp.KeyPair.PublicKey,
+		Amount:           "5000000000", // 5 SOL
+		CommitmentLevel:  type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		WaitUntilVisible: true,
 	})
 	suite.Require().NoError(err, "Should fund payer account")
 	suite.T().Logf("  Funded payer account: %s", payKeyResp.KeyPair.PublicKey)
 
-	// Wait for payer account to be funded
-	suite.waitForAccountVisible(fundResp.GetSignature(), payKeyResp.KeyPair.PublicKey)
-
 	// Generate mint account keypair
 	mintKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
 	suite.Require().NoError(err, "Should generate mint keypair")
@@ -392,8 +389,11 @@ func (suite *TokenProgramE2ETestSuite) Test_03_Token_e2e() {
 	suite.Require().NotNil(holdingAccount, "Holding account should exist")
 	suite.Assert().Equal(token_v1.TOKEN_2022_PROGRAM_ID, holdingAccount.Owner, "Holding account should be owned by Token 2022 program")
 	suite.Require().NotEmpty(holdingAccount.Data, "Holding account should have data")
-	decodedData := decodeAccountDataBytes(suite, holdingAccount.Data)
-	suite.Assert().Equal(memoAccountSpace, len(decodedData), "Holding account data length should match memo-enabled space")
+	suite.Assert().Equal(account_v1.AccountDataEncoding_ACCOUNT_DATA_ENCODING_BASE64, holdingAccount.Encoding, "Account data should be raw bytes by default")
+	suite.Assert().Equal(memoAccountSpace, len(holdingAccount.Data), "Holding account data length should match memo-enabled space")
+	suite.Assert().Equal(uint64(memoAccountSpace), holdingAccount.Space, "Holding account space should match memo-enabled space")
+	suite.Assert().Equal(memoLamports, holdingAccount.RentExemptMinimum, "Holding account should be funded with exactly the rent-exempt minimum")
+	suite.Assert().True(holdingAccount.IsRentExempt, "Holding account should be rent exempt")
 
 	// BUILD INSTRUCTION to mint tokens into the holding account
 	mintAmount := "1000000" // 1 token with 6 decimals
@@ -454,8 +454,29 @@ func (suite *TokenProgramE2ETestSuite) Test_03_Token_e2e() {
 	suite.Require().NoError(err, "Should get holding account after minting")
 	suite.Assert().Equal(token_v1.TOKEN_2022_PROGRAM_ID, holdingAccountAfterMint.Owner, "Holding account should still be owned by Token 2022 program")
 	suite.Require().NotEmpty(holdingAccountAfterMint.Data, "Holding account should have updated data after minting")
-	memoDecodedData := decodeAccountDataBytes(suite, holdingAccountAfterMint.Data)
-	suite.Assert().Equal(memoAccountSpace, len(memoDecodedData), "Holding account data length should remain memo-enabled size")
+	suite.Assert().Equal(memoAccountSpace, len(holdingAccountAfterMint.Data), "Holding account data length should remain memo-enabled size")
+
+	// The same account can be read as the node's parsed JSON representation
+	parsedHoldingAccount, err := suite.accountService.GetAccount(suite.ctx, &account_v1.GetAccountRequest{
+		Address:         holdingAccKeyResp.KeyPair.PublicKey,
+		CommitmentLevel: type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		Encoding:        account_v1.AccountDataEncoding_ACCOUNT_DATA_ENCODING_JSON_PARSED,
+	})
+	suite.Require().NoError(err, "Should get parsed holding account")
+	suite.Require().Equal(account_v1.AccountDataEncoding_ACCOUNT_DATA_ENCODING_JSON_PARSED, parsedHoldingAccount.Encoding, "Token accounts should be parsed by the node")
+	var parsedHolding struct {
+		Parsed struct {
+			Info struct {
+				Mint        string `json:"mint"`
+				TokenAmount struct {
+					Amount string `json:"amount"`
+				} `json:"tokenAmount"`
+			} `json:"info"`
+		} `json:"parsed"`
+	}
+	suite.Require().NoError(json.Unmarshal(parsedHoldingAccount.Data, &parsedHolding), "Parsed account data should be JSON")
+	suite.Assert().Equal(mintKeyResp.KeyPair.PublicKey, parsedHolding.Parsed.Info.Mint, "Parsed holding should reference the mint")
+	suite.Assert().Equal(mintAmount, parsedHolding.Parsed.Info.TokenAmount.Amount, "Parsed holding should hold the minted amount")
 
 	// Verify mint supply has increased
 	var parsedMintAfterMinting *token_v1.ParseMintResponse
@@ -495,6 +516,333 @@ func (suite *TokenProgramE2ETestSuite) Test_03_Token_e2e() {
 	suite.T().Logf("   solana confirm %s --url http://localhost:8899", submittedMintTx.Signature)
 }
 
+// Test_04_CreateAssociatedHoldingAccount creates a wallet's associated holding account without a holding account keypair
+func (suite *TokenProgramE2ETestSuite) Test_04_CreateAssociatedHoldingAccount() {
+	suite.T().Log("🎯 Testing Associated Holding Account Creation")
+
+	// Generate and fund payer account, which also owns the holding account
+	payKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate payer keypair")
+	_, err = suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
+		Address:          payKeyResp.KeyPair.PublicKey,
+		Amount:           "5000000000", // 5 SOL
+		CommitmentLevel:  type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		WaitUntilVisible: true,
+	})
+	suite.Require().NoError(err, "Should fund payer account")
+
+	// Generate mint account keypair
+	mintKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate mint keypair")
+
+	createMintResp, err := suite.tokenProgramService.CreateMint(suite.ctx, &token_v1.CreateMintRequest{
+		Payer:               payKeyResp.KeyPair.PublicKey,
+		NewAccount:          mintKeyResp.KeyPair.PublicKey,
+		MintPubKey:          mintKeyResp.KeyPair.PublicKey,
+		MintAuthorityPubKey: payKeyResp.KeyPair.PublicKey,
+		Decimals:            6,
+	})
+	suite.Require().NoError(err, "Should create mint instruction bundle")
+
+	// Derive and create the associated holding account
+	createAtaResp, err := suite.tokenProgramService.CreateAssociatedHoldingAccount(suite.ctx, &token_v1.CreateAssociatedHoldingAccountRequest{
+		Payer:       payKeyResp.KeyPair.PublicKey,
+		OwnerPubKey: payKeyResp.KeyPair.PublicKey,
+		MintPubKey:  mintKeyResp.KeyPair.PublicKey,
+	})
+	suite.Require().NoError(err, "Should create associated holding account instruction bundle")
+	suite.Require().NotEmpty(createAtaResp.HoldingAccountPubKey, "Should return the derived holding account address")
+	suite.T().Logf("  Derived associated holding account: %s", createAtaResp.HoldingAccountPubKey)
+
+	mintAmount := "2500000" // 2.5 tokens with 6 decimals
+	mintInstr, err := suite.tokenProgramService.Mint(suite.ctx, &token_v1.MintRequest{
+		MintPubKey:               mintKeyResp.KeyPair.PublicKey,
+		DestinationAccountPubKey: createAtaResp.HoldingAccountPubKey,
+		MintAuthorityPubKey:      payKeyResp.KeyPair.PublicKey,
+		Amount:                   mintAmount,
+		Decimals:                 6,
+	})
+	suite.Require().NoError(err, "Should create mint instruction")
+
+	// Mint, holding account and minting in one transaction
+	tx := &transaction_v1.Transaction{
+		State: transaction_v1.TransactionState_TRANSACTION_STATE_DRAFT,
+	}
+	tx.Instructions = append(tx.Instructions, createMintResp.Instructions...)
+	tx.Instructions = append(tx.Instructions, createAtaResp.Instructions...)
+	tx.Instructions = append(tx.Instructions, mintInstr.Instruction)
+
+	compiledTx, err := suite.transactionService.CompileTransaction(suite.ctx, &transaction_v1.CompileTransactionRequest{
+		Transaction: tx,
+		FeePayer:    payKeyResp.KeyPair.PublicKey,
+	})
+	suite.Require().NoError(err, "Should compile transaction")
+
+	// Only the payer and mint sign; the associated holding account has no keypair
+	signedTx, err := suite.transactionService.SignTransaction(suite.ctx, &transaction_v1.SignTransactionRequest{
+		Transaction: compiledTx.Transaction,
+		SigningMethod: &transaction_v1.SignTransactionRequest_PrivateKeys{
+			PrivateKeys: &transaction_v1.SignWithPrivateKeys{
+				PrivateKeys: []string{
+					payKeyResp.KeyPair.PrivateKey,
+					mintKeyResp.KeyPair.PrivateKey,
+				},
+			},
+		},
+	})
+	suite.Require().NoError(err, "Should sign transaction")
+
+	submittedTx, err := suite.transactionService.SubmitTransaction(suite.ctx, &transaction_v1.SubmitTransactionRequest{
+		Transaction: signedTx.Transaction,
+	})
+	suite.Require().NoError(err, "Should submit transaction")
+	suite.T().Logf("  Transaction submitted: %s", submittedTx.Signature)
+
+	suite.monitorTransactionToCompletion(submittedTx.Signature)
+	suite.waitForAccountVisible(submittedTx.Signature, createAtaResp.HoldingAccountPubKey)
+
+	parsedHolding, err := suite.tokenProgramService.ParseHoldingAccount(suite.ctx, &token_v1.ParseHoldingAccountRequest{
+		AccountAddress: createAtaResp.HoldingAccountPubKey,
+	})
+	suite.Require().NoError(err, "Should parse associated holding account")
+	suite.Require().NotNil(parsedHolding.Account, "Parsed holding account should not be nil")
+	suite.Assert().Equal(mintKeyResp.KeyPair.PublicKey, parsedHolding.Account.MintPubKey, "Holding account should hold the mint")
+	suite.Assert().Equal(payKeyResp.KeyPair.PublicKey, parsedHolding.Account.OwnerPubKey, "Holding account should be owned by the wallet")
+	suite.Assert().Equal(mintAmount, parsedHolding.Account.Amount, "Holding account should hold the minted amount")
+
+	// Creating it again is a no-op thanks to the idempotent instruction
+	againResp, err := suite.tokenProgramService.CreateAssociatedHoldingAccount(suite.ctx, &token_v1.CreateAssociatedHoldingAccountRequest{
+		Payer:       payKeyResp.KeyPair.PublicKey,
+		OwnerPubKey: payKeyResp.KeyPair.PublicKey,
+		MintPubKey:  mintKeyResp.KeyPair.PublicKey,
+	})
+	suite.Require().NoError(err, "Should build the create bundle again")
+	suite.Assert().Equal(createAtaResp.HoldingAccountPubKey, againResp.HoldingAccountPubKey, "Derived address should be deterministic")
+
+	suite.T().Logf("✅ Associated holding account %s created and funded with %s tokens", createAtaResp.HoldingAccountPubKey, mintAmount)
+}
+
+// Test_05_LegacyTokenProgram tests minting into an associated holding account under legacy SPL Token
+func (suite *TokenProgramE2ETestSuite) Test_05_LegacyTokenProgram() {
+	suite.T().Log("🎯 Testing Legacy SPL Token Mint and Holding Account")
+
+	// Generate and fund payer account, which also owns the holding account
+	payKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate payer keypair")
+	_, err = suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
+		Address:          payKeyResp.KeyPair.PublicKey,
+		Amount:           "5000000000", // 5 SOL
+		CommitmentLevel:  type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		WaitUntilVisible: true,
+	})
+	suite.Require().NoError(err, "Should fund payer account")
+
+	// Generate mint account keypair
+	mintKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate mint keypair")
+
+	// Extensions are rejected for legacy mints
+	_, err = suite.tokenProgramService.CreateMint(suite.ctx, &token_v1.CreateMintRequest{
+		Payer:               payKeyResp.KeyPair.PublicKey,
+		NewAccount:          mintKeyResp.KeyPair.PublicKey,
+		MintPubKey:          mintKeyResp.KeyPair.PublicKey,
+		MintAuthorityPubKey: payKeyResp.KeyPair.PublicKey,
+		Decimals:            6,
+		NonTransferable:     true,
+		TokenProgramId:      token_v1.TOKEN_PROGRAM_ID,
+	})
+	suite.Require().Error(err, "Should reject Token-2022 extensions on a legacy mint")
+
+	createMintResp, err := suite.tokenProgramService.CreateMint(suite.ctx, &token_v1.CreateMintRequest{
+		Payer:               payKeyResp.KeyPair.PublicKey,
+		NewAccount:          mintKeyResp.KeyPair.PublicKey,
+		MintPubKey:          mintKeyResp.KeyPair.PublicKey,
+		MintAuthorityPubKey: payKeyResp.KeyPair.PublicKey,
+		Decimals:            6,
+		TokenProgramId:      token_v1.TOKEN_PROGRAM_ID,
+	})
+	suite.Require().NoError(err, "Should create legacy mint instruction bundle")
+
+	createAtaResp, err := suite.tokenProgramService.CreateAssociatedHoldingAccount(suite.ctx, &token_v1.CreateAssociatedHoldingAccountRequest{
+		Payer:          payKeyResp.KeyPair.PublicKey,
+		OwnerPubKey:    payKeyResp.KeyPair.PublicKey,
+		MintPubKey:     mintKeyResp.KeyPair.PublicKey,
+		TokenProgramId: token_v1.TOKEN_PROGRAM_ID,
+	})
+	suite.Require().NoError(err, "Should create legacy associated holding account instruction bundle")
+
+	mintAmount := "1000000" // 1 token with 6 decimals
+	mintInstr, err := suite.tokenProgramService.Mint(suite.ctx, &token_v1.MintRequest{
+		MintPubKey:               mintKeyResp.KeyPair.PublicKey,
+		DestinationAccountPubKey: createAtaResp.HoldingAccountPubKey,
+		MintAuthorityPubKey:      payKeyResp.KeyPair.PublicKey,
+		Amount:                   mintAmount,
+		Decimals:                 6,
+		TokenProgramId:           token_v1.TOKEN_PROGRAM_ID,
+	})
+	suite.Require().NoError(err, "Should create legacy mint instruction")
+
+	tx := &transaction_v1.Transaction{
+		State: transaction_v1.TransactionState_TRANSACTION_STATE_DRAFT,
+	}
+	tx.Instructions = append(tx.Instructions, createMintResp.Instructions...)
+	tx.Instructions = append(tx.Instructions, createAtaResp.Instructions...)
+	tx.Instructions = append(tx.Instructions, mintInstr.Instruction)
+
+	compiledTx, err := suite.transactionService.CompileTransaction(suite.ctx, &transaction_v1.CompileTransactionRequest{
+		Transaction: tx,
+		FeePayer:    payKeyResp.KeyPair.PublicKey,
+	})
+	suite.Require().NoError(err, "Should compile transaction")
+
+	signedTx, err := suite.transactionService.SignTransaction(suite.ctx, &transaction_v1.SignTransactionRequest{
+		Transaction: compiledTx.Transaction,
+		SigningMethod: &transaction_v1.SignTransactionRequest_PrivateKeys{
+			PrivateKeys: &transaction_v1.SignWithPrivateKeys{
+				PrivateKeys: []string{
+					payKeyResp.KeyPair.PrivateKey,
+					mintKeyResp.KeyPair.PrivateKey,
+				},
+			},
+		},
+	})
+	suite.Require().NoError(err, "Should sign transaction")
+
+	submittedTx, err := suite.transactionService.SubmitTransaction(suite.ctx, &transaction_v1.SubmitTransactionRequest{
+		Transaction: signedTx.Transaction,
+	})
+	suite.Require().NoError(err, "Should submit transaction")
+	suite.T().Logf("  Transaction submitted: %s", submittedTx.Signature)
+
+	suite.monitorTransactionToCompletion(submittedTx.Signature)
+	suite.waitForAccountVisible(submittedTx.Signature, createAtaResp.HoldingAccountPubKey)
+
+	parsedMint, err := suite.tokenProgramService.ParseMint(suite.ctx, &token_v1.ParseMintRequest{
+		AccountAddress: mintKeyResp.KeyPair.PublicKey,
+	})
+	suite.Require().NoError(err, "Should parse legacy mint account")
+	suite.Assert().Equal(token_v1.TOKEN_PROGRAM_ID, parsedMint.Mint.TokenProgramId, "Mint should be owned by SPL Token")
+	suite.Assert().Equal(mintAmount, parsedMint.Mint.Supply, "Supply should match the minted amount")
+
+	parsedHolding, err := suite.tokenProgramService.ParseHoldingAccount(suite.ctx, &token_v1.ParseHoldingAccountRequest{
+		AccountAddress: createAtaResp.HoldingAccountPubKey,
+	})
+	suite.Require().NoError(err, "Should parse legacy holding account")
+	suite.Assert().Equal(token_v1.TOKEN_PROGRAM_ID, parsedHolding.Account.TokenProgramId, "Holding account should be owned by SPL Token")
+	suite.Assert().Equal(mintAmount, parsedHolding.Account.Amount, "Holding account should hold the minted amount")
+	suite.Assert().Empty(parsedHolding.Account.Extensions, "Legacy holding accounts have no extensions")
+
+	suite.T().Logf("✅ Legacy SPL Token mint %s minted %s into %s", mintKeyResp.KeyPair.PublicKey, mintAmount, createAtaResp.HoldingAccountPubKey)
+}
+
+// Test_06_BuildTokenTransfer tests a wallet-to-wallet transfer that creates the recipient's account
+func (suite *TokenProgramE2ETestSuite) Test_06_BuildTokenTransfer() {
+	suite.T().Log("🎯 Testing Token Transfer Between Wallets")
+
+	// Generate and fund the sender, which also pays and holds the mint authority
+	senderKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate sender keypair")
+	_, err = suite.accountService.FundNative(suite.ctx, &account_v1.FundNativeRequest{
+		Address:          senderKeyResp.KeyPair.PublicKey,
+		Amount:           "5000000000", // 5 SOL
+		CommitmentLevel:  type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		WaitUntilVisible: true,
+	})
+	suite.Require().NoError(err, "Should fund sender account")
+
+	recipientKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate recipient keypair")
+	mintKeyResp, err := suite.accountService.GenerateNewKeyPair(suite.ctx, &account_v1.GenerateNewKeyPairRequest{})
+	suite.Require().NoError(err, "Should generate mint keypair")
+
+	submit := func(instructions []*transaction_v1.SolanaInstruction, privateKeys ...string) string {
+		compiledTx, err := suite.transactionService.CompileTransaction(suite.ctx, &transaction_v1.CompileTransactionRequest{
+			Transaction: &transaction_v1.Transaction{
+				Instructions: instructions,
+				State:        transaction_v1.TransactionState_TRANSACTION_STATE_DRAFT,
+			},
+			FeePayer: senderKeyResp.KeyPair.PublicKey,
+		})
+		suite.Require().NoError(err, "Should compile transaction")
+		signedTx, err := suite.transactionService.SignTransaction(suite.ctx, &transaction_v1.SignTransactionRequest{
+			Transaction: compiledTx.Transaction,
+			SigningMethod: &transaction_v1.SignTransactionRequest_PrivateKeys{
+				PrivateKeys: &transaction_v1.SignWithPrivateKeys{PrivateKeys: privateKeys},
+			},
+		})
+		suite.Require().NoError(err, "Should sign transaction")
+		submittedTx, err := suite.transactionService.SubmitTransaction(suite.ctx, &transaction_v1.SubmitTransactionRequest{
+			Transaction: signedTx.Transaction,
+		})
+		suite.Require().NoError(err, "Should submit transaction")
+		suite.monitorTransactionToCompletion(submittedTx.Signature)
+		return submittedTx.Signature
+	}
+
+	// Mint 10 tokens into the sender's associated holding account
+	createMintResp, err := suite.tokenProgramService.CreateMint(suite.ctx, &token_v1.CreateMintRequest{
+		Payer:               senderKeyResp.KeyPair.PublicKey,
+		NewAccount:          mintKeyResp.KeyPair.PublicKey,
+		MintPubKey:          mintKeyResp.KeyPair.PublicKey,
+		MintAuthorityPubKey: senderKeyResp.KeyPair.PublicKey,
+		Decimals:            6,
+	})
+	suite.Require().NoError(err, "Should create mint instruction bundle")
+	createAtaResp, err := suite.tokenProgramService.CreateAssociatedHoldingAccount(suite.ctx, &token_v1.CreateAssociatedHoldingAccountRequest{
+		Payer:       senderKeyResp.KeyPair.PublicKey,
+		OwnerPubKey: senderKeyResp.KeyPair.PublicKey,
+		MintPubKey:  mintKeyResp.KeyPair.PublicKey,
+	})
+	suite.Require().NoError(err, "Should create sender holding account instruction bundle")
+	mintInstr, err := suite.tokenProgramService.Mint(suite.ctx, &token_v1.MintRequest{
+		MintPubKey:               mintKeyResp.KeyPair.PublicKey,
+		DestinationAccountPubKey: createAtaResp.HoldingAccountPubKey,
+		MintAuthorityPubKey:      senderKeyResp.KeyPair.PublicKey,
+		Amount:                   "10000000",
+		Decimals:                 6,
+	})
+	suite.Require().NoError(err, "Should create mint instruction")
+	setup := append(append(createMintResp.Instructions, createAtaResp.Instructions...), mintInstr.Instruction)
+	signature := submit(setup, senderKeyResp.KeyPair.PrivateKey, mintKeyResp.KeyPair.PrivateKey)
+	suite.waitForAccountVisible(signature, createAtaResp.HoldingAccountPubKey)
+
+	// The recipient has no holding account yet, so the transfer creates it
+	transferResp, err := suite.tokenProgramService.BuildTokenTransfer(suite.ctx, &token_v1.BuildTokenTransferRequest{
+		SenderPubKey:    senderKeyResp.KeyPair.PublicKey,
+		RecipientPubKey: recipientKeyResp.KeyPair.PublicKey,
+		MintPubKey:      mintKeyResp.KeyPair.PublicKey,
+		Amount:          "2500000",
+		Memo:            "invoice 42",
+	})
+	suite.Require().NoError(err, "Should build token transfer")
+	suite.Assert().True(transferResp.CreatesDestinationAccount, "Should create the recipient's holding account")
+	suite.Assert().Len(transferResp.Instructions, 3, "Should create, memo and transfer")
+	suite.Assert().Equal(createAtaResp.HoldingAccountPubKey, transferResp.SourceAccountPubKey, "Should debit the sender's holding account")
+
+	signature = submit(transferResp.Instructions, senderKeyResp.KeyPair.PrivateKey)
+	suite.waitForAccountVisible(signature, transferResp.DestinationAccountPubKey)
+
+	parsedHolding, err := suite.tokenProgramService.ParseHoldingAccount(suite.ctx, &token_v1.ParseHoldingAccountRequest{
+		AccountAddress: transferResp.DestinationAccountPubKey,
+	})
+	suite.Require().NoError(err, "Should parse recipient holding account")
+	suite.Assert().Equal(recipientKeyResp.KeyPair.PublicKey, parsedHolding.Account.OwnerPubKey, "Holding account should be owned by the recipient")
+	suite.Assert().Equal("2500000", parsedHolding.Account.Amount, "Recipient should hold the transferred amount")
+
+	// Once it exists, only the transfer is built
+	againResp, err := suite.tokenProgramService.BuildTokenTransfer(suite.ctx, &token_v1.BuildTokenTransferRequest{
+		SenderPubKey:    senderKeyResp.KeyPair.PublicKey,
+		RecipientPubKey: recipientKeyResp.KeyPair.PublicKey,
+		MintPubKey:      mintKeyResp.KeyPair.PublicKey,
+		Amount:          "1000000",
+	})
+	suite.Require().NoError(err, "Should build token transfer again")
+	suite.Assert().False(againResp.CreatesDestinationAccount, "Should not recreate the recipient's holding account")
+	suite.Assert().Len(againResp.Instructions, 1, "Should only transfer")
+
+	suite.T().Logf("✅ Transferred 2.5 tokens to %s, creating %s", recipientKeyResp.KeyPair.PublicKey, transferResp.DestinationAccountPubKey)
+}
+
 // Helper function to wait for account visibility
 func (suite *TokenProgramE2ETestSuite) waitForAccountVisible(signature, address string) {
 	if signature != "" {
@@ -502,20 +850,27 @@ func (suite *TokenProgramE2ETestSuite) waitForAccountVisible(signature, address
 	}
 
 	suite.T().Logf("  Waiting for account %s to become visible...", address)
-	for attempt := 1; attempt <= 10; attempt++ {
-		_, err := suite.accountService.GetAccount(suite.ctx, &account_v1.GetAccountRequest{
-			Address:         address,
-			CommitmentLevel: type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
-		})
-		if err == nil {
-			suite.T().Logf("  Account visible after %d attempts", attempt)
-			return
+	stream, err := suite.accountService.WaitForAccount(suite.ctx, &account_v1.WaitForAccountRequest{
+		Address:         address,
+		CommitmentLevel: type_v1.CommitmentLevel_COMMITMENT_LEVEL_CONFIRMED,
+		TimeoutSeconds:  10,
+	})
+	suite.Require().NoError(err, "Must create wait stream for account: %s", address)
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
 		}
-		if attempt < 10 {
-			time.Sleep(200 * time.Millisecond)
+		suite.Require().NoError(err, "Wait stream failed for account: %s", address)
+
+		switch resp.Event {
+		case account_v1.WaitForAccountEvent_WAIT_FOR_ACCOUNT_EVENT_READY:
+			suite.T().Logf("  Account visible at slot %d after %d polls", resp.Slot, resp.PollCount)
+		case account_v1.WaitForAccountEvent_WAIT_FOR_ACCOUNT_EVENT_TIMEOUT:
+			suite.T().Logf("  Account may still be processing...")
 		}
 	}
-	suite.T().Logf("  Account may still be processing...")
 }
 
 // Helper function to monitor transaction to completion
@@ -567,31 +922,6 @@ func (suite *TokenProgramE2ETestSuite) monitorTransactionToCompletion(signature
 	suite.Require().True(confirmed, "Transaction %s must reach CONFIRMED or FINALIZED status", signature)
 }
 
-func decodeAccountDataBytes(s *TokenProgramE2ETestSuite, raw string) []byte {
-	var numericPayload []int
-	if err := json.Unmarshal([]byte(raw), &numericPayload); err == nil && len(numericPayload) > 0 {
-		bytes := make([]byte, len(numericPayload))
-		for i, v := range numericPayload {
-			s.Require().GreaterOrEqual(v, 0, "account data byte values must be non-negative")
-			s.Require().LessOrEqual(v, 255, "account data byte values must be within byte range")
-			bytes[i] = byte(v)
-		}
-		return bytes
-	}
-
-	var tuplePayload []any
-	if err := json.Unmarshal([]byte(raw), &tuplePayload); err == nil && len(tuplePayload) == 2 {
-		if encoded, ok := tuplePayload[0].(string); ok {
-			decoded, err := base64.StdEncoding.DecodeString(encoded)
-			s.Require().NoError(err, "Should decode base64 account payload")
-			return decoded
-		}
-	}
-
-	s.Require().Failf("decodeAccountDataBytes", "Unsupported account data format: %s", raw)
-	return nil
-}
-
 func TestTokenProgramE2ESuite(t *testing.T) {
 	suite.Run(t, new(TokenProgramE2ETestSuite))
 }
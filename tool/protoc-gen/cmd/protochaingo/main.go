@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 
 	"google.golang.org/protobuf/compiler/protogen"
@@ -9,7 +10,14 @@ import (
 )
 
 func main() {
-	protogen.Options{}.Run(func(p *protogen.Plugin) error {
+	// examples=true generates the example programs instead of the service files
+	var flags flag.FlagSet
+	examples := flags.Bool("examples", false, "generate the end-to-end example programs")
+
+	protogen.Options{ParamFunc: flags.Set}.Run(func(p *protogen.Plugin) error {
+		if *examples {
+			return generate.Examples(p)
+		}
 		return Generate(p)
 	})
 }
@@ -21,6 +29,11 @@ func Generate(p *protogen.Plugin) error {
 			continue
 		}
 
+		// generate the commitment level helpers of the file's messages
+		if err := generate.CommitmentHelpers(p, f); err != nil {
+			return fmt.Errorf("error generating commitment helpers: %w", err)
+		}
+
 		// if the file contains services then perform service related code generation
 		if len(f.Services) != 0 {
 			// confirm that file contains no more than 1 service
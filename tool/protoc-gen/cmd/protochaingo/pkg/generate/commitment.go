@@ -0,0 +1,95 @@
+package generate
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CommitmentLevelEnum is the enum the commitment helpers are generated for
+const CommitmentLevelEnum protoreflect.FullName = "protochain.solana.type.v1.CommitmentLevel"
+
+// CommitmentHelpers generates chainable setters for the CommitmentLevel fields of a file's
+// messages, one taking a value and one taking a pointer, so code written against either
+// form compiles against the plain value fields every service uses
+func CommitmentHelpers(p *protogen.Plugin, f *protogen.File) error {
+	type commitmentField struct {
+		message *protogen.Message
+		field   *protogen.Field
+	}
+
+	var fields []commitmentField
+	var collect func(messages []*protogen.Message) error
+	collect = func(messages []*protogen.Message) error {
+		for _, message := range messages {
+			if message.Desc.IsMapEntry() {
+				continue
+			}
+			for _, field := range message.Fields {
+				if field.Enum == nil || field.Enum.Desc.FullName() != CommitmentLevelEnum {
+					continue
+				}
+				if field.Desc.HasPresence() || field.Oneof != nil {
+					return fmt.Errorf("commitment field '%s' must not be optional or in a oneof", field.Desc.FullName())
+				}
+				if field.Desc.IsList() {
+					continue
+				}
+				fields = append(fields, commitmentField{message: message, field: field})
+			}
+			if err := collect(message.Messages); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(f.Messages); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// generate a new go file for the helpers
+	g := p.NewGeneratedFile(
+		generateFilename(f.Desc.Path(), "_commitment"),
+		f.GoImportPath,
+	)
+
+	// add header
+	g.P("// Code generated by protoc-gen-passivgo. DO NOT EDIT.")
+	g.P("// source: ", f.Desc.Path())
+	g.P("package ", f.GoPackageName)
+	g.P()
+
+	for i, cf := range fields {
+		message := cf.message.GoIdent.GoName
+		field := cf.field.GoName
+		level := g.QualifiedGoIdent(cf.field.Enum.GoIdent)
+		valueOf := g.QualifiedGoIdent(protogen.GoIdent{
+			GoName:       "CommitmentLevelValue",
+			GoImportPath: cf.field.Enum.GoIdent.GoImportPath,
+		})
+
+		g.P("// Set", field, " sets ", field, " and returns the message for chaining")
+		g.P("func (x *", message, ") Set", field, "(level ", level, ") *", message, " {")
+		g.P("\tx.", field, " = level")
+		g.P("\treturn x")
+		g.P("}")
+		g.P()
+		g.P("// Set", field, "From sets ", field, " from an optional level, leaving it UNSPECIFIED")
+		g.P("// (the service default) when level is nil, and returns the message for chaining")
+		g.P("func (x *", message, ") Set", field, "From(level *", level, ") *", message, " {")
+		g.P("\tx.", field, " = ", valueOf, "(level)")
+		g.P("\treturn x")
+		g.P("}")
+
+		// add space between messages' helpers (but not after the last)
+		if i != len(fields)-1 {
+			g.P()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,423 @@
+package generate
+
+// fundAndTransferTemplate funds a new account and transfers SOL out of it
+const fundAndTransferTemplate = `
+// Command fund_and_transfer funds a new account and transfers SOL from it to a second
+// new account, waiting for both transactions to confirm.
+//
+// It walks the full transaction lifecycle: build an instruction, compile it into a
+// transaction, sign, submit and monitor. Run it against a local validator and backend:
+//
+//	go run ./examples/fund_and_transfer --url localhost:50051
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	{{protoImports}}
+)
+
+func main() {
+	url := flag.String("url", "localhost:50051", "protochain API address (host:port)")
+	lamports := flag.Uint64("lamports", 1_000_000, "lamports to transfer")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	conn, err := grpc.NewClient(*url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	accounts := {{client "account.v1.Service"}}(conn)
+	system := {{client "program.system.v1.Service"}}(conn)
+	transactions := {{client "transaction.v1.Service"}}(conn)
+
+	payer, err := accounts.{{rpc "account.v1.Service" "GenerateNewKeyPair"}}(ctx, &{{type "account.v1.GenerateNewKeyPairRequest"}}{})
+	if err != nil {
+		log.Fatalf("failed to generate payer: %v", err)
+	}
+	recipient, err := accounts.{{rpc "account.v1.Service" "GenerateNewKeyPair"}}(ctx, &{{type "account.v1.GenerateNewKeyPairRequest"}}{})
+	if err != nil {
+		log.Fatalf("failed to generate recipient: %v", err)
+	}
+	fmt.Printf("payer:     %s\nrecipient: %s\n", payer.KeyPair.PublicKey, recipient.KeyPair.PublicKey)
+
+	// Fund the payer with 1 SOL to cover the transfer and its fee
+	funding, err := accounts.{{rpc "account.v1.Service" "FundNative"}}(ctx, &{{type "account.v1.FundNativeRequest"}}{
+		Address:         payer.KeyPair.PublicKey,
+		TypedAmount:     &{{type "account.v1.FundNativeRequest.lamports"}}{Lamports: 1000000000},
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}},
+	})
+	if err != nil {
+		log.Fatalf("failed to fund payer: %v", err)
+	}
+	if err := awaitConfirmation(ctx, transactions, funding.Signature); err != nil {
+		log.Fatalf("funding did not confirm: %v", err)
+	}
+	fmt.Printf("funded:    %s\n", funding.Signature)
+
+	transfer, err := system.{{rpc "program.system.v1.Service" "Transfer"}}(ctx, &{{type "program.system.v1.TransferRequest"}}{
+		From:     payer.KeyPair.PublicKey,
+		To:       recipient.KeyPair.PublicKey,
+		Lamports: *lamports,
+	})
+	if err != nil {
+		log.Fatalf("failed to build transfer: %v", err)
+	}
+
+	compiled, err := transactions.{{rpc "transaction.v1.Service" "CompileTransaction"}}(ctx, &{{type "transaction.v1.CompileTransactionRequest"}}{
+		Transaction: &{{type "transaction.v1.Transaction"}}{
+			Instructions: []*{{type "transaction.v1.SolanaInstruction"}}{transfer},
+			State:        {{enum "transaction.v1.TransactionState" "TRANSACTION_STATE_DRAFT"}},
+		},
+		FeePayer: payer.KeyPair.PublicKey,
+	})
+	if err != nil {
+		log.Fatalf("failed to compile: %v", err)
+	}
+
+	signed, err := transactions.{{rpc "transaction.v1.Service" "SignTransaction"}}(ctx, &{{type "transaction.v1.SignTransactionRequest"}}{
+		Transaction: compiled.Transaction,
+		SigningMethod: &{{type "transaction.v1.SignTransactionRequest.private_keys"}}{
+			PrivateKeys: &{{type "transaction.v1.SignWithPrivateKeys"}}{
+				PrivateKeys: []string{payer.KeyPair.PrivateKey},
+			},
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed to sign: %v", err)
+	}
+
+	submitted, err := transactions.{{rpc "transaction.v1.Service" "SubmitTransaction"}}(ctx, &{{type "transaction.v1.SubmitTransactionRequest"}}{
+		Transaction:     signed.Transaction,
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}},
+	})
+	if err != nil {
+		log.Fatalf("failed to submit: %v", err)
+	}
+	if submitted.SubmissionResult != {{enum "transaction.v1.SubmissionResult" "SUBMISSION_RESULT_SUBMITTED"}} {
+		log.Fatalf("submission %s: %s", submitted.SubmissionResult, submitted.ErrorMessage)
+	}
+	if err := awaitConfirmation(ctx, transactions, submitted.Signature); err != nil {
+		log.Fatalf("transfer did not confirm: %v", err)
+	}
+	fmt.Printf("transfer:  %s (%d lamports)\n", submitted.Signature, *lamports)
+}
+
+// awaitConfirmation streams a transaction's status until it is confirmed, failing if it
+// fails, is dropped or monitoring times out
+func awaitConfirmation(ctx context.Context, transactions {{clientType "transaction.v1.Service"}}, signature string) error {
+	stream, err := transactions.{{rpc "transaction.v1.Service" "MonitorTransaction"}}(ctx, &{{type "transaction.v1.MonitorTransactionRequest"}}{
+		Signature:       signature,
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}},
+		TimeoutSeconds:  60,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("monitoring ended before %s confirmed", signature)
+		}
+		if err != nil {
+			return err
+		}
+		switch update.Status {
+		case {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_CONFIRMED"}}, {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_FINALIZED"}}:
+			return nil
+		case {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_FAILED"}}, {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_DROPPED"}}, {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_TIMEOUT"}}:
+			return fmt.Errorf("transaction %s: %s %s", signature, update.Status, update.ErrorMessage)
+		}
+	}
+}
+`
+
+// issueTokenTemplate creates a mint and a holding account and mints an initial supply
+const issueTokenTemplate = `
+// Command issue_token issues a new token: it creates a mint and a holding account owned
+// by a new, funded account and mints an initial supply into it, in one transaction.
+//
+// Run it against a local validator and backend:
+//
+//	go run ./examples/issue_token --url localhost:50051 --decimals 2 --supply 100000
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	{{protoImports}}
+)
+
+func main() {
+	url := flag.String("url", "localhost:50051", "protochain API address (host:port)")
+	decimals := flag.Uint("decimals", 2, "decimals of the new mint")
+	supply := flag.String("supply", "100000", "initial supply to mint, in base units")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	conn, err := grpc.NewClient(*url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	accounts := {{client "account.v1.Service"}}(conn)
+	tokens := {{client "program.token.v1.Service"}}(conn)
+	transactions := {{client "transaction.v1.Service"}}(conn)
+
+	// The issuer pays for everything and is the mint authority; the mint and holding
+	// accounts are new accounts, so their keys sign their creation
+	var keys [3]*{{type "account.v1.GenerateNewKeyPairResponse"}}
+	for i := range keys {
+		keys[i], err = accounts.{{rpc "account.v1.Service" "GenerateNewKeyPair"}}(ctx, &{{type "account.v1.GenerateNewKeyPairRequest"}}{})
+		if err != nil {
+			log.Fatalf("failed to generate key pair: %v", err)
+		}
+	}
+	issuer, mint, holding := keys[0].KeyPair, keys[1].KeyPair, keys[2].KeyPair
+
+	funding, err := accounts.{{rpc "account.v1.Service" "FundNative"}}(ctx, &{{type "account.v1.FundNativeRequest"}}{
+		Address:         issuer.PublicKey,
+		TypedAmount:     &{{type "account.v1.FundNativeRequest.lamports"}}{Lamports: 1000000000},
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}},
+	})
+	if err != nil {
+		log.Fatalf("failed to fund issuer: %v", err)
+	}
+	if err := awaitConfirmation(ctx, transactions, funding.Signature); err != nil {
+		log.Fatalf("funding did not confirm: %v", err)
+	}
+
+	createMint, err := tokens.{{rpc "program.token.v1.Service" "CreateMint"}}(ctx, &{{type "program.token.v1.CreateMintRequest"}}{
+		Payer:                 issuer.PublicKey,
+		NewAccount:            mint.PublicKey,
+		MintPubKey:            mint.PublicKey,
+		MintAuthorityPubKey:   issuer.PublicKey,
+		FreezeAuthorityPubKey: issuer.PublicKey,
+		Decimals:              uint32(*decimals),
+	})
+	if err != nil {
+		log.Fatalf("failed to build mint creation: %v", err)
+	}
+	createHolding, err := tokens.{{rpc "program.token.v1.Service" "CreateHoldingAccount"}}(ctx, &{{type "program.token.v1.CreateHoldingAccountRequest"}}{
+		Payer:                issuer.PublicKey,
+		NewAccount:           holding.PublicKey,
+		HoldingAccountPubKey: holding.PublicKey,
+		MintPubKey:           mint.PublicKey,
+		OwnerPubKey:          issuer.PublicKey,
+	})
+	if err != nil {
+		log.Fatalf("failed to build holding account creation: %v", err)
+	}
+	mintTo, err := tokens.{{rpc "program.token.v1.Service" "Mint"}}(ctx, &{{type "program.token.v1.MintRequest"}}{
+		MintPubKey:               mint.PublicKey,
+		DestinationAccountPubKey: holding.PublicKey,
+		MintAuthorityPubKey:      issuer.PublicKey,
+		Amount:                   *supply,
+		Decimals:                 uint32(*decimals),
+	})
+	if err != nil {
+		log.Fatalf("failed to build mint instruction: %v", err)
+	}
+
+	instructions := append(createMint.Instructions, createHolding.Instructions...)
+	instructions = append(instructions, mintTo.Instruction)
+
+	compiled, err := transactions.{{rpc "transaction.v1.Service" "CompileTransaction"}}(ctx, &{{type "transaction.v1.CompileTransactionRequest"}}{
+		Transaction: &{{type "transaction.v1.Transaction"}}{
+			Instructions: instructions,
+			State:        {{enum "transaction.v1.TransactionState" "TRANSACTION_STATE_DRAFT"}},
+		},
+		FeePayer: issuer.PublicKey,
+	})
+	if err != nil {
+		log.Fatalf("failed to compile: %v", err)
+	}
+
+	signed, err := transactions.{{rpc "transaction.v1.Service" "SignTransaction"}}(ctx, &{{type "transaction.v1.SignTransactionRequest"}}{
+		Transaction: compiled.Transaction,
+		SigningMethod: &{{type "transaction.v1.SignTransactionRequest.private_keys"}}{
+			PrivateKeys: &{{type "transaction.v1.SignWithPrivateKeys"}}{
+				PrivateKeys: []string{issuer.PrivateKey, mint.PrivateKey, holding.PrivateKey},
+			},
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed to sign: %v", err)
+	}
+
+	submitted, err := transactions.{{rpc "transaction.v1.Service" "SubmitTransaction"}}(ctx, &{{type "transaction.v1.SubmitTransactionRequest"}}{
+		Transaction:     signed.Transaction,
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}},
+	})
+	if err != nil {
+		log.Fatalf("failed to submit: %v", err)
+	}
+	if submitted.SubmissionResult != {{enum "transaction.v1.SubmissionResult" "SUBMISSION_RESULT_SUBMITTED"}} {
+		log.Fatalf("submission %s: %s", submitted.SubmissionResult, submitted.ErrorMessage)
+	}
+	if err := awaitConfirmation(ctx, transactions, submitted.Signature); err != nil {
+		log.Fatalf("issuance did not confirm: %v", err)
+	}
+
+	parsed, err := tokens.{{rpc "program.token.v1.Service" "ParseMint"}}(ctx, &{{type "program.token.v1.ParseMintRequest"}}{
+		AccountAddress: mint.PublicKey,
+	})
+	if err != nil {
+		log.Fatalf("failed to read mint: %v", err)
+	}
+	fmt.Printf("mint:      %s\n", mint.PublicKey)
+	fmt.Printf("holding:   %s\n", holding.PublicKey)
+	fmt.Printf("issued:    %s (supply %s)\n", submitted.Signature, parsed.Mint.Supply)
+}
+
+// awaitConfirmation streams a transaction's status until it is confirmed, failing if it
+// fails, is dropped or monitoring times out
+func awaitConfirmation(ctx context.Context, transactions {{clientType "transaction.v1.Service"}}, signature string) error {
+	stream, err := transactions.{{rpc "transaction.v1.Service" "MonitorTransaction"}}(ctx, &{{type "transaction.v1.MonitorTransactionRequest"}}{
+		Signature:       signature,
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}},
+		TimeoutSeconds:  60,
+	})
+	if err != nil {
+		return err
+	}
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return fmt.Errorf("monitoring ended before %s confirmed", signature)
+		}
+		if err != nil {
+			return err
+		}
+		switch update.Status {
+		case {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_CONFIRMED"}}, {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_FINALIZED"}}:
+			return nil
+		case {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_FAILED"}}, {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_DROPPED"}}, {{enum "transaction.v1.TransactionStatus" "TRANSACTION_STATUS_TIMEOUT"}}:
+			return fmt.Errorf("transaction %s: %s %s", signature, update.Status, update.ErrorMessage)
+		}
+	}
+}
+`
+
+// monitorAndWebhookTemplate relays a transaction's status updates to a webhook
+const monitorAndWebhookTemplate = `
+// Command monitor_and_webhook monitors a transaction until it is finalized, fails or
+// monitoring times out, and POSTs every status update to a webhook as protobuf JSON.
+//
+//	go run ./examples/monitor_and_webhook --url localhost:50051 \
+//	  --signature <signature> --webhook https://example.com/hooks/solana
+//
+// A failed delivery is logged and monitoring carries on; the next update supersedes it.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	{{protoImports}}
+)
+
+func main() {
+	url := flag.String("url", "localhost:50051", "protochain API address (host:port)")
+	signature := flag.String("signature", "", "signature of the transaction to monitor")
+	webhook := flag.String("webhook", "", "URL status updates are POSTed to")
+	timeout := flag.Duration("timeout", 2*time.Minute, "how long to monitor for")
+	flag.Parse()
+	if *signature == "" || *webhook == "" {
+		flag.Usage()
+		log.Fatal("--signature and --webhook are required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(*url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	transactions := {{client "transaction.v1.Service"}}(conn)
+	stream, err := transactions.{{rpc "transaction.v1.Service" "MonitorTransaction"}}(ctx, &{{type "transaction.v1.MonitorTransactionRequest"}}{
+		Signature:       *signature,
+		CommitmentLevel: {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_FINALIZED"}},
+		IncludeLogs:     true,
+		TimeoutSeconds:  uint32(timeout.Seconds()),
+	})
+	if err != nil {
+		log.Fatalf("failed to start monitoring: %v", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatalf("monitoring failed: %v", err)
+		}
+		log.Printf("%s at slot %d", update.Status, update.Slot)
+
+		if err := deliver(ctx, client, *webhook, update); err != nil {
+			log.Printf("webhook delivery failed: %v", err)
+		}
+	}
+}
+
+// deliver POSTs one status update to the webhook
+func deliver(ctx context.Context, client *http.Client, webhook string, update *{{type "transaction.v1.MonitorTransactionResponse"}}) error {
+	body, err := protojson.Marshal(update)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded %s", response.Status)
+	}
+	return nil
+}
+`
@@ -0,0 +1,221 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// protoPrefix is prepended to the proto names used in example templates
+const protoPrefix = "protochain.solana."
+
+// example is a runnable program generated into lib/go/examples/<name>
+type example struct {
+	name     string
+	template string
+}
+
+// examples are the end-to-end example programs, in generation order
+var examples = []example{
+	{name: "fund_and_transfer", template: fundAndTransferTemplate},
+	{name: "issue_token", template: issueTokenTemplate},
+	{name: "monitor_and_webhook", template: monitorAndWebhookTemplate},
+}
+
+// Examples generates the end-to-end example programs.
+//
+// Templates refer to services, RPCs, messages and enum values by proto name, resolved
+// against the descriptors being generated, so an example that uses a renamed or removed
+// API fails generation rather than going stale. Field names are written as Go
+// identifiers and are checked when the examples are compiled.
+func Examples(p *protogen.Plugin) error {
+	index := newProtoIndex(p.Files)
+	for _, e := range examples {
+		if err := e.generate(p, index); err != nil {
+			return fmt.Errorf("error generating example '%s': %w", e.name, err)
+		}
+	}
+	return nil
+}
+
+// generate renders the example's template into its main package.
+//
+// Generated packages are imported under their go_package names (account_v1 and so on)
+// rather than protogen's path-derived ones, so the examples read like hand-written code.
+// The template is rendered twice: once to collect the packages it uses and again to
+// write their imports with {{protoImports}}.
+func (e example) generate(p *protogen.Plugin, index *protoIndex) error {
+	g := p.NewGeneratedFile(
+		"examples/"+e.name+"/main.passivgo.go",
+		ExamplesPkg+protogen.GoImportPath("/"+e.name),
+	)
+
+	imports := make(map[protogen.GoImportPath]protogen.GoPackageName)
+	tmpl, err := template.New(e.name).Funcs(index.funcs(imports)).Parse(e.template)
+	if err != nil {
+		return fmt.Errorf("error parsing template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, nil); err != nil {
+		return fmt.Errorf("error rendering template: %w", err)
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, nil); err != nil {
+		return fmt.Errorf("error rendering template: %w", err)
+	}
+
+	g.P("// Code generated by protoc-gen-passivgo. DO NOT EDIT.")
+	g.P()
+	g.P(strings.TrimSpace(body.String()))
+	return nil
+}
+
+// protoIndex looks up the services, messages and enums of every file in a request by
+// full proto name
+type protoIndex struct {
+	services map[protoreflect.FullName]*protogen.Service
+	files    map[protoreflect.FullName]*protogen.File
+	packages map[protogen.GoImportPath]protogen.GoPackageName
+	messages map[protoreflect.FullName]*protogen.Message
+	enums    map[protoreflect.FullName]*protogen.Enum
+}
+
+// newProtoIndex indexes files, including nested messages and enums
+func newProtoIndex(files []*protogen.File) *protoIndex {
+	index := &protoIndex{
+		services: make(map[protoreflect.FullName]*protogen.Service),
+		files:    make(map[protoreflect.FullName]*protogen.File),
+		packages: make(map[protogen.GoImportPath]protogen.GoPackageName),
+		messages: make(map[protoreflect.FullName]*protogen.Message),
+		enums:    make(map[protoreflect.FullName]*protogen.Enum),
+	}
+	for _, f := range files {
+		index.packages[f.GoImportPath] = f.GoPackageName
+		for _, svc := range f.Services {
+			index.services[svc.Desc.FullName()] = svc
+			index.files[svc.Desc.FullName()] = f
+		}
+		for _, enum := range f.Enums {
+			index.enums[enum.Desc.FullName()] = enum
+		}
+		index.addMessages(f.Messages)
+	}
+	return index
+}
+
+// addMessages indexes messages and everything nested in them
+func (i *protoIndex) addMessages(messages []*protogen.Message) {
+	for _, message := range messages {
+		i.messages[message.Desc.FullName()] = message
+		for _, enum := range message.Enums {
+			i.enums[enum.Desc.FullName()] = enum
+		}
+		i.addMessages(message.Messages)
+	}
+}
+
+// service resolves a service by its name relative to protoPrefix
+func (i *protoIndex) service(name string) (*protogen.Service, *protogen.File, error) {
+	fullName := protoreflect.FullName(protoPrefix + name)
+	svc, ok := i.services[fullName]
+	if !ok {
+		return nil, nil, fmt.Errorf("service '%s' not found", fullName)
+	}
+	return svc, i.files[fullName], nil
+}
+
+// qualify renders ident as package.Name, recording the package in imports
+func (i *protoIndex) qualify(imports map[protogen.GoImportPath]protogen.GoPackageName, ident protogen.GoIdent) string {
+	name := i.packages[ident.GoImportPath]
+	imports[ident.GoImportPath] = name
+	return string(name) + "." + ident.GoName
+}
+
+// funcs returns the template functions, which record the packages they reference in
+// imports
+func (i *protoIndex) funcs(imports map[protogen.GoImportPath]protogen.GoPackageName) template.FuncMap {
+	return template.FuncMap{
+		// protoImports is the import specs of every generated package the template uses
+		"protoImports": func() string {
+			paths := make([]string, 0, len(imports))
+			for path := range imports {
+				paths = append(paths, string(path))
+			}
+			sort.Strings(paths)
+
+			specs := make([]string, 0, len(paths))
+			for _, path := range paths {
+				specs = append(specs, fmt.Sprintf("%s %q", imports[protogen.GoImportPath(path)], path))
+			}
+			return strings.Join(specs, "\n\t")
+		},
+
+		// client is the constructor of a service's gRPC client, e.g. {{client "account.v1.Service"}}
+		"client": func(name string) (string, error) {
+			svc, f, err := i.service(name)
+			if err != nil {
+				return "", err
+			}
+			return i.qualify(imports, f.GoImportPath.Ident("New"+svc.GoName+"Client")), nil
+		},
+
+		// clientType is a service's gRPC client interface, e.g. {{clientType "account.v1.Service"}}
+		"clientType": func(name string) (string, error) {
+			svc, f, err := i.service(name)
+			if err != nil {
+				return "", err
+			}
+			return i.qualify(imports, f.GoImportPath.Ident(svc.GoName+"Client")), nil
+		},
+
+		// rpc is a method of a service, e.g. {{rpc "account.v1.Service" "FundNative"}}
+		"rpc": func(name, method string) (string, error) {
+			svc, _, err := i.service(name)
+			if err != nil {
+				return "", err
+			}
+			for _, m := range svc.Methods {
+				if string(m.Desc.Name()) == method {
+					return m.GoName, nil
+				}
+			}
+			return "", fmt.Errorf("method '%s' not found on service '%s'", method, svc.Desc.FullName())
+		},
+
+		// type is a message, or the wrapper of a oneof member when the last element names
+		// a field, e.g. {{type "transaction.v1.SignTransactionRequest.private_keys"}}
+		"type": func(name string) (string, error) {
+			fullName := protoreflect.FullName(protoPrefix + name)
+			if message, ok := i.messages[fullName]; ok {
+				return i.qualify(imports, message.GoIdent), nil
+			}
+			if message, ok := i.messages[fullName.Parent()]; ok {
+				for _, field := range message.Fields {
+					if field.Desc.Name() == fullName.Name() && field.Oneof != nil {
+						return i.qualify(imports, field.GoIdent), nil
+					}
+				}
+			}
+			return "", fmt.Errorf("message or oneof member '%s' not found", fullName)
+		},
+
+		// enum is an enum value, e.g. {{enum "type.v1.CommitmentLevel" "COMMITMENT_LEVEL_CONFIRMED"}}
+		"enum": func(name, value string) (string, error) {
+			fullName := protoreflect.FullName(protoPrefix + name)
+			enum, ok := i.enums[fullName]
+			if !ok {
+				return "", fmt.Errorf("enum '%s' not found", fullName)
+			}
+			for _, v := range enum.Values {
+				if string(v.Desc.Name()) == value {
+					return i.qualify(imports, v.GoIdent), nil
+				}
+			}
+			return "", fmt.Errorf("value '%s' not found on enum '%s'", value, fullName)
+		},
+	}
+}
@@ -15,4 +15,7 @@ const (
 
 	// Protochain packages
 	APIPkg = protogen.GoImportPath("github.com/BRBussy/protochain/lib/go/common")
+
+	// ExamplesPkg is the parent of the generated example programs
+	ExamplesPkg = protogen.GoImportPath("github.com/BRBussy/protochain/lib/go/examples")
 )
@@ -103,6 +103,13 @@ func Service(p *protogen.Plugin, f *protogen.File, svc *protogen.Service) error
 	g.P("//\t}")
 	g.P("//\tdefer service.Close()")
 	g.P("//")
+	g.P("//\t// Create for an environment defined in profiles.json alongside credentials.json")
+	g.P("//\tservice, err := ", constructorName, "(api.WithProfile(\"devnet\"))")
+	g.P("//\tif err != nil {")
+	g.P("//\t\tlog.Fatal(err)")
+	g.P("//\t}")
+	g.P("//\tdefer service.Close()")
+	g.P("//")
 	g.P("//\t// Create with custom configuration")
 	g.P("//\tservice, err := ", constructorName, "(")
 	g.P("//\t\tapi.WithURL(\"api.example.com:443\"),")
@@ -141,6 +148,7 @@ func Service(p *protogen.Plugin, f *protogen.File, svc *protogen.Service) error
 			g.P("// ", method.GoName, " executes the ", method.GoName, " server streaming RPC method.")
 			g.P("// For streaming methods, this delegates directly to the underlying gRPC client.")
 			g.P("func (s *", serviceStructName, ") ", method.GoName, "(ctx ", ContextPkg.Ident("Context"), ", request *", method.Input.GoIdent, ", stream ", GRPCPkg.Ident("ServerStreamingServer"), "[", method.Output.GoIdent, "]) error {")
+			g.P("\ts.Executor().ApplyDefaults(request)")
 			g.P("\t// For streaming methods, delegate directly to the gRPC client stream")
 			g.P("\tclientStream, err := s.GrpcClient().", method.GoName, "(ctx, request)")
 			g.P("\tif err != nil {")
@@ -0,0 +1,5 @@
+module github.com/BRBussy/protochain/tool/protocheck/cmd/protocheck
+
+go 1.24.3
+
+require google.golang.org/protobuf v1.36.8
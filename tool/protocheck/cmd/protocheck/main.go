@@ -0,0 +1,88 @@
+// protocheck gates changes to the protochain API surface before code generation.
+//
+// It wraps buf lint and buf breaking, and adds protochain-specific rules buf does not
+// know about (see pkg/rules). Run it from the repository root, where buf.yaml lives:
+//
+//	go run ./tool/protocheck/cmd/protocheck lint
+//	go run ./tool/protocheck/cmd/protocheck breaking -against '.git#branch=main'
+//	go run ./tool/protocheck/cmd/protocheck check
+//	go run ./tool/protocheck/cmd/protocheck rules
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/BRBussy/protochain/tool/protocheck/cmd/protocheck/pkg/buf"
+	"github.com/BRBussy/protochain/tool/protocheck/cmd/protocheck/pkg/rules"
+)
+
+const usage = `Usage: protocheck <command> [flags]
+
+Commands:
+  lint      Run buf lint and the protochain rules
+  breaking  Run buf breaking against a previous version of the protos
+  check     Run lint and breaking
+  rules     List the protochain rules
+
+Flags:
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	dir := flags.String("dir", ".", "directory containing buf.yaml")
+	against := flags.String("against", ".git#branch=main", "buf input to check for breaking changes against")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[2:])
+
+	var err error
+	switch command {
+	case "lint":
+		err = lint(*dir)
+	case "breaking":
+		err = buf.Breaking(*dir, *against)
+	case "check":
+		err = errors.Join(lint(*dir), buf.Breaking(*dir, *against))
+	case "rules":
+		for _, rule := range rules.All {
+			fmt.Printf("%-28s %s\n", rule.Name, rule.Description)
+		}
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// lint runs buf lint, then the protochain rules, reporting the findings of both
+func lint(dir string) error {
+	bufErr := buf.Lint(dir)
+
+	files, err := buf.Files(dir)
+	if err != nil {
+		return errors.Join(bufErr, err)
+	}
+	violations := rules.Check(files)
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	if len(violations) != 0 {
+		return errors.Join(bufErr, fmt.Errorf("%d protochain rule violation(s)", len(violations)))
+	}
+	return bufErr
+}
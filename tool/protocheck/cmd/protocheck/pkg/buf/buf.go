@@ -0,0 +1,63 @@
+package buf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Binary is the buf executable, looked up on PATH
+const Binary = "buf"
+
+// Lint runs buf lint with the rules of the buf.yaml in dir, streaming its findings
+func Lint(dir string) error {
+	return run(dir, "lint")
+}
+
+// Breaking runs buf breaking against the given input (e.g. ".git#branch=main"),
+// streaming its findings
+func Breaking(dir, against string) error {
+	return run(dir, "breaking", "--against", against)
+}
+
+// Files builds the module in dir and returns its resolved file descriptors, including
+// imports, so rules can follow field types across files
+func Files(dir string) (*protoregistry.Files, error) {
+	cmd := exec.Command(Binary, "build", "--output", "-#format=binpb")
+	cmd.Dir = dir
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("buf build failed: %w", err)
+	}
+
+	// a buf image is wire compatible with a FileDescriptorSet; buf-specific fields are dropped
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(stdout.Bytes(), &set); err != nil {
+		return nil, fmt.Errorf("error decoding buf image: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving file descriptors: %w", err)
+	}
+	return files, nil
+}
+
+// run invokes buf in dir with its output attached to ours
+func run(dir string, args ...string) error {
+	cmd := exec.Command(Binary, args...)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("buf %s failed: %w", args[0], err)
+	}
+	return nil
+}
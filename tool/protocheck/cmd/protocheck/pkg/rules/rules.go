@@ -0,0 +1,187 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// PackagePrefix selects the files the rules apply to; imports such as the well-known
+	// types are skipped
+	PackagePrefix = "protochain."
+
+	// CommitmentLevelEnum is the one type commitment fields may have
+	CommitmentLevelEnum protoreflect.FullName = "protochain.solana.type.v1.CommitmentLevel"
+)
+
+// Violation is a rule broken at a location in a proto file
+type Violation struct {
+	Rule    string
+	File    string
+	Line    int
+	Message string
+}
+
+// String formats the violation like buf does, so both can be read the same way
+func (v Violation) String() string {
+	return fmt.Sprintf("%s:%d:%s (%s)", v.File, v.Line, v.Message, v.Rule)
+}
+
+// Rule checks one protochain convention in a file
+type Rule struct {
+	Name        string
+	Description string
+	Check       func(f protoreflect.FileDescriptor) []Violation
+}
+
+// All are the protochain-specific rules, on top of buf's STANDARD lint rules
+var All = []Rule{
+	{
+		Name:        "RPC_REQUEST_NAMES",
+		Description: "rpc Foo takes FooRequest (streams included); responses may be shared",
+		Check:       checkRequestNames,
+	},
+	{
+		Name:        "COMMITMENT_FIELDS",
+		Description: "commitment fields are non-optional CommitmentLevel named commitment_level or *_commitment",
+		Check:       checkCommitmentFields,
+	},
+	{
+		Name:        "ENUM_ZERO_VALUE_UNSPECIFIED",
+		Description: "the zero value of enum Foo is FOO_UNSPECIFIED",
+		Check:       checkEnumZeroValues,
+	},
+}
+
+// Check runs every rule over the protochain files in files, returning the violations
+// sorted by location
+func Check(files *protoregistry.Files) []Violation {
+	var violations []Violation
+	files.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+		if !strings.HasPrefix(string(f.Package()), PackagePrefix) {
+			return true
+		}
+		for _, rule := range All {
+			violations = append(violations, rule.Check(f)...)
+		}
+		return true
+	})
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Line < violations[j].Line
+	})
+	return violations
+}
+
+// checkRequestNames checks request names only: program services return the shared
+// SolanaInstruction and builders a common response, which buf.yaml allows by excepting
+// RPC_RESPONSE_STANDARD_NAME
+func checkRequestNames(f protoreflect.FileDescriptor) []Violation {
+	var violations []Violation
+	services := f.Services()
+	for i := 0; i < services.Len(); i++ {
+		methods := services.Get(i).Methods()
+		for j := 0; j < methods.Len(); j++ {
+			method := methods.Get(j)
+			if want := method.Name() + "Request"; method.Input().Name() != want {
+				violations = append(violations, violation(f, method, "RPC_REQUEST_NAMES",
+					fmt.Sprintf("RPC %q takes %q, want %q", method.Name(), method.Input().Name(), want)))
+			}
+		}
+	}
+	return violations
+}
+
+func checkCommitmentFields(f protoreflect.FileDescriptor) []Violation {
+	var violations []Violation
+	forEachMessage(f.Messages(), func(message protoreflect.MessageDescriptor) {
+		fields := message.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			name := string(field.Name())
+			isCommitmentLevel := field.Enum() != nil && field.Enum().FullName() == CommitmentLevelEnum
+			namedCommitment := strings.Contains(name, "commitment")
+
+			switch {
+			case namedCommitment && !isCommitmentLevel:
+				violations = append(violations, violation(f, field, "COMMITMENT_FIELDS",
+					fmt.Sprintf("Field %q must be of type %s", field.FullName(), CommitmentLevelEnum)))
+			case isCommitmentLevel && name != "commitment_level" && !strings.HasSuffix(name, "_commitment"):
+				violations = append(violations, violation(f, field, "COMMITMENT_FIELDS",
+					fmt.Sprintf("CommitmentLevel field %q must be named commitment_level or end in _commitment", field.FullName())))
+			case isCommitmentLevel && field.HasPresence():
+				// UNSPECIFIED already marks an unset commitment; optional would make it a
+				// pointer in Go for this field only
+				violations = append(violations, violation(f, field, "COMMITMENT_FIELDS",
+					fmt.Sprintf("CommitmentLevel field %q must not be optional", field.FullName())))
+			}
+		}
+	})
+	return violations
+}
+
+func checkEnumZeroValues(f protoreflect.FileDescriptor) []Violation {
+	var violations []Violation
+	check := func(enum protoreflect.EnumDescriptor) {
+		want := protoreflect.Name(screamingSnake(string(enum.Name())) + "_UNSPECIFIED")
+		zero := enum.Values().ByNumber(0)
+		if zero == nil || zero.Name() != want {
+			violations = append(violations, violation(f, enum, "ENUM_ZERO_VALUE_UNSPECIFIED",
+				fmt.Sprintf("Enum %q must have zero value %q", enum.FullName(), want)))
+		}
+	}
+
+	enums := f.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		check(enums.Get(i))
+	}
+	forEachMessage(f.Messages(), func(message protoreflect.MessageDescriptor) {
+		nested := message.Enums()
+		for i := 0; i < nested.Len(); i++ {
+			check(nested.Get(i))
+		}
+	})
+	return violations
+}
+
+// forEachMessage calls fn for every message in messages, including nested messages
+func forEachMessage(messages protoreflect.MessageDescriptors, fn func(protoreflect.MessageDescriptor)) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		if message.IsMapEntry() {
+			continue
+		}
+		fn(message)
+		forEachMessage(message.Messages(), fn)
+	}
+}
+
+// screamingSnake converts an enum name such as CommitmentLevel to COMMITMENT_LEVEL
+func screamingSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// violation locates a descriptor in its file; lines are 1-based like buf's output
+func violation(f protoreflect.FileDescriptor, d protoreflect.Descriptor, rule, message string) Violation {
+	location := f.SourceLocations().ByDescriptor(d)
+	return Violation{
+		Rule:    rule,
+		File:    f.Path(),
+		Line:    location.StartLine + 1,
+		Message: message,
+	}
+}